@@ -6,7 +6,11 @@
 
 import { ErrorDebuggingMCPServer } from './server/mcp-server.js';
 import { ConfigManager } from './utils/config-manager.js';
-import { Logger } from './utils/logger.js';
+import { Logger, parseLogLevel } from './utils/logger.js';
+import { DEFAULT_SHUTDOWN_GRACE_MS } from './languages/language-handler-manager.js';
+
+/** Time beyond the shutdown grace period before the process exits regardless */
+const FORCE_EXIT_MARGIN_MS = 5000;
 
 async function main(): Promise<void> {
   try {
@@ -14,11 +18,14 @@ async function main(): Promise<void> {
     const configManager = new ConfigManager();
     const config = await configManager.loadConfig();
     
-    // Initialize logger - completely disable logging for MCP mode to avoid protocol interference
-    const logger = new Logger(config.server.logLevel, {
-      enableConsole: false, // Always disable console logging to avoid MCP protocol interference
-      enableFile: false,    // Disable file logging too for now
-      logFile: undefined
+    // Initialize logger. stdout carries the MCP protocol, so logs go to a file or,
+    // when explicitly requested, to stderr. The environment overrides the config.
+    const logLevel = parseLogLevel(process.env['ERROR_DEBUGGING_LOG_LEVEL']) || config.server.logLevel;
+    const logFile = process.env['ERROR_DEBUGGING_LOG_FILE'] || config.server.logFile;
+    const logger = new Logger(logLevel, {
+      enableConsole: process.env['ERROR_DEBUGGING_LOG_STDERR'] === '1',
+      enableFile: logFile !== undefined,
+      logFile
     });
 
     // Only log to stderr in development mode with TTY
@@ -42,18 +49,28 @@ async function main(): Promise<void> {
       logger.info('Server stopped');
     });
 
-    // Handle process signals
-    process.on('SIGINT', async () => {
-      logger.info('Received SIGINT, shutting down gracefully...');
-      await server.stop();
-      process.exit(0);
-    });
+    // Stop once, on a signal or when the client closes stdin, and never hang on exit
+    let shuttingDown = false;
+    const shutdown = async (reason: string): Promise<void> => {
+      if (shuttingDown) {
+        return;
+      }
+      shuttingDown = true;
+      logger.info(`${reason}, shutting down gracefully...`);
 
-    process.on('SIGTERM', async () => {
-      logger.info('Received SIGTERM, shutting down gracefully...');
-      await server.stop();
+      const graceMs = config.server.shutdownGraceMs ?? DEFAULT_SHUTDOWN_GRACE_MS;
+      setTimeout(() => process.exit(1), graceMs + FORCE_EXIT_MARGIN_MS).unref();
+      try {
+        await server.stop();
+      } catch (error) {
+        logger.error('Shutdown failed:', error);
+      }
       process.exit(0);
-    });
+    };
+
+    process.on('SIGINT', () => void shutdown('Received SIGINT'));
+    process.on('SIGTERM', () => void shutdown('Received SIGTERM'));
+    process.stdin.on('end', () => void shutdown('Client disconnected'));
 
     process.on('uncaughtException', (error) => {
       logger.error('Uncaught exception:', error);
@@ -0,0 +1,140 @@
+/**
+ * Content-addressed cache for language handler results
+ */
+
+import { createHash } from 'crypto';
+import type { LanguageError } from '../types/languages.js';
+import { deepClone } from '../utils/helpers.js';
+
+export interface AnalysisCacheEntry {
+  contentHash: string;
+  mtimeMs: number;
+  fingerprint: string;
+  errors: LanguageError[];
+  cachedAt: Date;
+}
+
+export interface AnalysisCacheStats {
+  hits: number;
+  misses: number;
+  entries: number;
+}
+
+/**
+ * Results keyed by file. Analyses run concurrently with invalidations, so a
+ * result is stored with the generation of its file at the time the analysis
+ * started, and dropped if the file was invalidated or the cache cleared since.
+ */
+export class AnalysisCache {
+  private entries = new Map<string, AnalysisCacheEntry>();
+  private hits = 0;
+  private misses = 0;
+  /** Counter behind every generation; bumped by each invalidation and clear */
+  private version = 0;
+  private clearedAt = 0;
+  private invalidatedAt = new Map<string, number>();
+
+  /**
+   * Compute the SHA-256 digest used to key file contents
+   */
+  static hashContent(content: string): string {
+    return createHash('sha256').update(content).digest('hex');
+  }
+
+  /**
+   * Compute a fingerprint for the settings a result was produced under.
+   * Any change to the options or environment yields a different fingerprint.
+   */
+  static fingerprint(settings: unknown, env: NodeJS.ProcessEnv = process.env): string {
+    const sortedEnv = Object.keys(env)
+      .sort()
+      .map(key => `${key}=${env[key] ?? ''}`);
+
+    return createHash('sha256')
+      .update(JSON.stringify(settings))
+      .update('\0')
+      .update(sortedEnv.join('\0'))
+      .digest('hex');
+  }
+
+  /**
+   * Look up the cached result for a file, counting hits and misses
+   */
+  get(filePath: string, contentHash: string, mtimeMs: number, fingerprint: string): LanguageError[] | undefined {
+    const entry = this.entries.get(filePath);
+
+    if (
+      entry &&
+      entry.contentHash === contentHash &&
+      entry.mtimeMs === mtimeMs &&
+      entry.fingerprint === fingerprint
+    ) {
+      this.hits++;
+      return deepClone(entry.errors);
+    }
+
+    this.misses++;
+    return undefined;
+  }
+
+  /**
+   * The generation of a file's entry, to pass to `set` for a result computed from now on
+   */
+  generation(filePath: string): number {
+    return Math.max(this.clearedAt, this.invalidatedAt.get(filePath) ?? 0);
+  }
+
+  /**
+   * Store a result. With `generation`, a result whose file was invalidated while
+   * it was computed is dropped, and false returned.
+   */
+  set(
+    filePath: string,
+    contentHash: string,
+    mtimeMs: number,
+    fingerprint: string,
+    errors: LanguageError[],
+    generation?: number
+  ): boolean {
+    if (generation !== undefined && generation !== this.generation(filePath)) {
+      return false;
+    }
+    this.entries.set(filePath, {
+      contentHash,
+      mtimeMs,
+      fingerprint,
+      errors: deepClone(errors),
+      cachedAt: new Date()
+    });
+    return true;
+  }
+
+  invalidate(filePath: string): void {
+    this.entries.delete(filePath);
+    this.invalidatedAt.set(filePath, ++this.version);
+  }
+
+  clear(): void {
+    this.entries.clear();
+    this.clearedAt = ++this.version;
+    this.invalidatedAt.clear();
+    this.hits = 0;
+    this.misses = 0;
+  }
+
+  /**
+   * Zero the hit and miss counters, keeping the cached results
+   */
+  resetStats(): void {
+    this.hits = 0;
+    this.misses = 0;
+  }
+
+  getStats(): AnalysisCacheStats {
+    return {
+      hits: this.hits,
+      misses: this.misses,
+      entries: this.entries.size
+    };
+  }
+}
@@ -3,6 +3,11 @@
  */
 
 import { EventEmitter } from 'events';
+import { spawn } from 'child_process';
+import { promises as fs } from 'fs';
+import { constants as osConstants } from 'os';
+import { basename } from 'path';
+import { StringDecoder } from 'string_decoder';
 import type {
   LanguageHandler,
   DetectionOptions,
@@ -11,18 +16,58 @@ import type {
   LanguageDebugCapabilities,
   LanguageDebugConfig,
   LanguageDebugSession,
-  PerformanceAnalysis
+  PerformanceAnalysis,
+  LanguageId,
+  ToolchainInfo
 } from '../types/languages.js';
-import { SupportedLanguage } from '../types/languages.js';
 import { Logger } from '../utils/logger.js';
+import { cancellationError, currentSignal, isDetectorTimeout } from '../utils/cancellation.js';
+import { ToolFailedError, ToolNotFoundError, isToolNotFoundError } from '../utils/errors.js';
+import { currentDetectorOptions, mergeDetectorOptions } from '../utils/workspace-config.js';
+import { parseDetectorEnv, redactEnv } from '../utils/env.js';
+import { toolchainCache } from '../utils/toolchain-cache.js';
+import { currentRawOutputRecorder } from '../utils/raw-output.js';
+import { currentCommandPlan } from '../utils/command-plan.js';
+import { analysisStats } from '../utils/analysis-stats.js';
+
+export interface CommandOptions {
+  cwd?: string;
+  env?: NodeJS.ProcessEnv;
+  signal?: AbortSignal;
+  /** Kill the tool once stdout and stderr together exceed this many bytes */
+  maxOutputBytes?: number;
+}
+
+/** `source` of diagnostics describing a failed tool rather than a problem in the file */
+export const TOOLCHAIN_SOURCE = 'toolchain';
+
+/** Longest tool output quoted in a toolchain diagnostic */
+const MAX_TOOLCHAIN_OUTPUT = 4000;
+
+/** Command that prints the version of a handler's main tool */
+export interface ToolchainProbe {
+  command: string;
+  args: string[];
+}
+
+export interface CommandResult {
+  stdout: string;
+  stderr: string;
+  /** -1 when the process was killed at a detector deadline */
+  exitCode: number;
+  /** Set when a detector deadline cut the run short; output is whatever was captured */
+  timedOut?: boolean;
+  /** Set when the tool was killed for exceeding `maxOutputBytes`; output stops at the cap */
+  truncated?: boolean;
+}
 
 export abstract class BaseLanguageHandler extends EventEmitter implements LanguageHandler {
   protected logger: Logger;
   protected isInitialized = false;
 
   constructor(
-    public readonly language: SupportedLanguage,
-    protected options: Record<string, unknown> = {},
+    public readonly language: LanguageId,
+    private readonly baseOptions: Record<string, unknown> = {},
     logger?: Logger
   ) {
     super();
@@ -32,6 +77,20 @@ export abstract class BaseLanguageHandler extends EventEmitter implements Langua
     });
   }
 
+  /**
+   * Handler options, with those of an enclosing workspace config layered on top
+   */
+  protected get options(): Record<string, unknown> {
+    return mergeDetectorOptions(this.baseOptions, currentDetectorOptions());
+  }
+
+  /**
+   * Handler options as the server set them, which no workspace config can change
+   */
+  protected get serverOptions(): Record<string, unknown> {
+    return this.baseOptions;
+  }
+
   /**
    * Initialize the language handler
    */
@@ -84,6 +143,71 @@ export abstract class BaseLanguageHandler extends EventEmitter implements Langua
     }
   }
 
+  /**
+   * Probe the main tool for its version without initializing the handler
+   */
+  async getToolchainInfo(): Promise<ToolchainInfo> {
+    const probe = this.getToolchainProbe();
+    if (!probe) {
+      const available = await this.isAvailable();
+      return { available, tool: this.language, ...(!available && { reason: 'language tools not available' }) };
+    }
+
+    const tool = basename(probe.command);
+    const commandLine = [probe.command, ...probe.args].join(' ');
+    try {
+      // Versions only change when the tool is reinstalled, so one probe per TTL is enough
+      const version = await toolchainCache.get(`version ${commandLine}`, async () => {
+        const result = await this.runCommand(probe.command, probe.args);
+        // Some tools (older javac, python2) print their version on stderr
+        const output = (result.stdout.trim() || result.stderr.trim()).split('\n')[0]?.trim() || '';
+        if (result.exitCode !== 0) {
+          throw new ToolFailedError(
+            probe.command,
+            result.exitCode,
+            result.stderr,
+            `${commandLine} exited with code ${result.exitCode}${output ? `: ${output}` : ''}`
+          );
+        }
+        return output;
+      });
+      return { available: true, tool, ...(version && { version }) };
+    } catch (error) {
+      return {
+        available: false,
+        tool,
+        reason: isToolNotFoundError(error)
+          ? `${probe.command} not found on PATH`
+          : error instanceof Error ? error.message : String(error)
+      };
+    }
+  }
+
+  /**
+   * Absolute path of an executable on PATH, or undefined when it is not installed.
+   * Found paths are cached process-wide; misses are looked up again next time.
+   */
+  protected async findExecutable(name: string): Promise<string | undefined> {
+    try {
+      return await toolchainCache.get(`which ${name}`, async () => {
+        const result = await this.runCommand('which', [name]);
+        if (result.exitCode !== 0 || !result.stdout.trim()) {
+          throw new ToolNotFoundError(name);
+        }
+        return result.stdout.trim();
+      });
+    } catch {
+      return undefined;
+    }
+  }
+
+  /**
+   * Version command of the handler's main tool; none by default
+   */
+  protected getToolchainProbe(): ToolchainProbe | undefined {
+    return undefined;
+  }
+
   /**
    * Get language-specific file extensions
    */
@@ -199,7 +323,15 @@ export abstract class BaseLanguageHandler extends EventEmitter implements Langua
    */
   isFileSupported(filePath: string): boolean {
     const extensions = this.getFileExtensions();
-    return extensions.some(ext => filePath.endsWith(ext));
+    return extensions.some(ext => filePath.endsWith(ext)) ||
+      this.getFileNames().includes(basename(filePath));
+  }
+
+  /**
+   * Exact file names supported regardless of extension; none by default
+   */
+  getFileNames(): string[] {
+    return [];
   }
 
   /**
@@ -250,4 +382,205 @@ export abstract class BaseLanguageHandler extends EventEmitter implements Langua
 
     return error;
   }
+
+  /**
+   * Check whether a file on disk still holds exactly the given source,
+   * in which case project-wide tools see what the caller sees
+   */
+  protected async isUnmodifiedOnDisk(filePath: string, source: string): Promise<boolean> {
+    try {
+      return await fs.readFile(filePath, 'utf-8') === source;
+    } catch {
+      return false;
+    }
+  }
+
+  /**
+   * Report a tool that failed without output the handler could parse (for example
+   * `go: cannot find module`), so a broken toolchain is not mistaken for a clean file
+   */
+  protected createToolchainError(tool: string, result: CommandResult, filePath: string): LanguageError {
+    const output = result.stderr.trim() || result.stdout.trim() || `exited with code ${result.exitCode}`;
+    const message = output.length > MAX_TOOLCHAIN_OUTPUT
+      ? `${output.slice(0, MAX_TOOLCHAIN_OUTPUT)}\n... (truncated)`
+      : output;
+
+    const error = this.createError(`${tool} failed: ${message}`, filePath, 1, 1, 'error', 'toolchain');
+    error.source = TOOLCHAIN_SOURCE;
+    return error;
+  }
+
+  /**
+   * Whether a run exited non-zero without producing any parseable diagnostics.
+   * Runs cut short by a detector deadline are reported by the manager instead.
+   */
+  protected isUnparsedFailure(result: CommandResult, parsed: readonly unknown[]): boolean {
+    return result.exitCode !== 0 && !result.timedOut && parsed.length === 0;
+  }
+
+  /**
+   * Environment of a tool once the detector's `env` option is layered on, where
+   * undefined inherits the server's. Handlers override it to set variables that
+   * no `env` may change.
+   */
+  protected commandEnv(env: NodeJS.ProcessEnv | undefined): NodeJS.ProcessEnv | undefined {
+    return env;
+  }
+
+  /**
+   * Run a tool and collect its output.
+   * When a signal is given (or inherited from `runWithSignal`), aborting it kills the
+   * tool's whole process group and rejects with a cancellation error once it has exited.
+   * With `maxOutputBytes` the tool is killed the same way once its output passes the cap.
+   * A tool killed by a signal from elsewhere rejects with a `ToolFailedError`, so its
+   * cut-off output is not taken for a clean run.
+   * Inside `planCommands` nothing is spawned: the command is recorded and reported
+   * as a clean run without output.
+   */
+  protected async runCommand(command: string, args: string[], options: CommandOptions = {}): Promise<CommandResult> {
+    const signal = options.signal ?? currentSignal();
+    if (signal?.aborted) {
+      // Past a detector deadline later steps yield nothing, so earlier output can still be reported
+      if (isDetectorTimeout(signal)) {
+        return { stdout: '', stderr: '', exitCode: -1, timedOut: true };
+      }
+      throw cancellationError(signal);
+    }
+
+    const startedAt = Date.now();
+    // Variables from the detector's `env` option reach every tool it runs
+    const injected = parseDetectorEnv(this.options['env']);
+    const hasInjected = Object.keys(injected).length > 0;
+    this.logger.debug(`Running ${command}`, {
+      commandLine: [command, ...args].join(' '),
+      cwd: options.cwd || process.cwd(),
+      ...(hasInjected && { env: redactEnv(injected) })
+    });
+
+    const env = this.commandEnv(hasInjected ? { ...(options.env ?? process.env), ...injected } : options.env);
+    const plan = currentCommandPlan();
+    if (plan) {
+      plan.record(this.language, command, args, { ...(options.cwd && { cwd: options.cwd }), ...(env && { env }) });
+      return { stdout: '', stderr: '', exitCode: 0 };
+    }
+
+    const limit = options.maxOutputBytes;
+    const recorder = currentRawOutputRecorder();
+    analysisStats.recordSubprocess();
+    return new Promise((resolve, reject) => {
+      const child = spawn(command, args, {
+        stdio: 'pipe',
+        cwd: options.cwd,
+        env,
+        // A separate process group lets cancellation reach grandchildren (e.g. compilers)
+        detached: (signal !== undefined || limit !== undefined) && process.platform !== 'win32'
+      });
+      let stdout = '';
+      let stderr = '';
+      let outputBytes = 0;
+      let truncated = false;
+
+      const onAbort = () => {
+        try {
+          if (child.pid !== undefined && process.platform !== 'win32') {
+            process.kill(-child.pid, 'SIGKILL');
+          } else {
+            child.kill('SIGKILL');
+          }
+        } catch {
+          // Process already exited
+        }
+      };
+      signal?.addEventListener('abort', onAbort, { once: true });
+
+      // Each stream keeps its own decoder so a character split across chunks survives
+      const stdoutDecoder = new StringDecoder('utf8');
+      const stderrDecoder = new StringDecoder('utf8');
+
+      // Returns the part of a chunk that still fits under the output cap
+      const capture = (data: Buffer, decoder: StringDecoder): string => {
+        if (limit === undefined) {
+          return decoder.write(data);
+        }
+        if (truncated) {
+          return '';
+        }
+        const room = limit - outputBytes;
+        outputBytes += data.length;
+        if (data.length <= room) {
+          return decoder.write(data);
+        }
+        truncated = true;
+        onAbort();
+        // Cut before the character that straddles the cap, not through it
+        let cut = Math.max(0, room);
+        while (cut > 0 && ((data[cut] ?? 0) & 0xc0) === 0x80) {
+          cut--;
+        }
+        return decoder.write(data.subarray(0, cut));
+      };
+
+      child.stdout?.on('data', (data: Buffer) => {
+        stdout += capture(data, stdoutDecoder);
+      });
+
+      child.stderr?.on('data', (data: Buffer) => {
+        stderr += capture(data, stderrDecoder);
+      });
+
+      const finish = (result: CommandResult) => {
+        recorder?.record(command, args, result);
+        resolve(result);
+      };
+
+      child.on('close', (code, killSignal) => {
+        signal?.removeEventListener('abort', onAbort);
+        // A truncated stream drops its unfinished character rather than emitting U+FFFD
+        if (!truncated) {
+          stdout += stdoutDecoder.end();
+          stderr += stderrDecoder.end();
+        }
+        this.logger.debug(`${command} exited with ${code ?? killSignal}`, {
+          commandLine: [command, ...args].join(' '),
+          durationMs: Date.now() - startedAt,
+          stderr
+        });
+        if (isDetectorTimeout(signal)) {
+          finish({ stdout, stderr, exitCode: -1, timedOut: true, ...(truncated && { truncated }) });
+          return;
+        }
+        if (signal?.aborted) {
+          reject(cancellationError(signal));
+          return;
+        }
+        if (truncated) {
+          finish({ stdout, stderr, exitCode: -1, truncated: true });
+          return;
+        }
+        if (code === null) {
+          // Killed from outside, e.g. by the OOM killer, so its output is no report on the code
+          const exitCode = 128 + (killSignal ? osConstants.signals[killSignal] : 0);
+          reject(new ToolFailedError(
+            command,
+            exitCode,
+            stderr,
+            `${command} was killed by ${killSignal}${stderr.trim() ? `: ${stderr.trim()}` : ''}`
+          ));
+          return;
+        }
+        finish({
+          stdout,
+          stderr,
+          exitCode: code
+        });
+      });
+
+      child.on('error', (error: NodeJS.ErrnoException) => {
+        signal?.removeEventListener('abort', onAbort);
+        reject(error.code === 'ENOENT'
+          ? new ToolNotFoundError(command)
+          : new ToolFailedError(command, -1, '', `${command} could not be started: ${error.message}`, { cause: error }));
+      });
+    });
+  }
 }
@@ -0,0 +1,192 @@
+/**
+ * Background warm-up after startup: each Go module is built with
+ * `go build ./...` so the build cache is warm, then its files are analyzed with
+ * the settings analyze calls use by default, so the first of those calls finds
+ * its results cached when nothing changed in between
+ */
+
+import { promises as fs } from 'fs';
+import { join, resolve } from 'path';
+import type { LanguageHandlerManager } from './language-handler-manager.js';
+import type { WarmupConfig } from '../types/config.js';
+import type { DetectionOptions } from '../types/languages.js';
+import { SupportedLanguage } from '../types/languages.js';
+import { Logger } from '../utils/logger.js';
+import { AnalysisCanceledError, isCancellationError, throwIfAborted } from '../utils/cancellation.js';
+import { isNoFilesError } from '../utils/errors.js';
+
+export type WarmupState = 'disabled' | 'idle' | 'running' | 'completed' | 'canceled' | 'failed';
+
+export type ModuleWarmupState = 'pending' | 'building' | 'analyzing' | 'warm' | 'failed' | 'canceled';
+
+export interface ModuleWarmupStatus {
+  path: string;
+  state: ModuleWarmupState;
+  /** Whether every package built; packages that did not are left for the analyses to report */
+  built?: boolean;
+  /** Diagnostics the analysis of the module found */
+  diagnostics?: number;
+  error?: string;
+  durationMs?: number;
+}
+
+export interface WarmupStatus {
+  state: WarmupState;
+  startedAt?: string;
+  completedAt?: string;
+  /** Why the warm-up as a whole failed */
+  error?: string;
+  modules: ModuleWarmupStatus[];
+}
+
+/**
+ * Options of the warm-up analyses. The cache is keyed by them, so they are
+ * those of list-errors and detect-errors calls that only pass a path.
+ */
+export const WARMUP_ANALYSIS_OPTIONS: Readonly<DetectionOptions> = { enableLinting: true, includeWarnings: true };
+
+/** Directories `./...` leaves out, and dependency trees */
+function isSkippedDirectory(name: string): boolean {
+  return name.startsWith('.') || name.startsWith('_') || ['testdata', 'vendor', 'node_modules'].includes(name);
+}
+
+/**
+ * Directories at or below the roots that hold a go.mod, sorted. Symbolic links
+ * are not followed.
+ */
+export async function findGoModules(roots: string[], signal?: AbortSignal): Promise<string[]> {
+  const modules = new Set<string>();
+
+  const walk = async (dir: string): Promise<void> => {
+    throwIfAborted(signal);
+    const entries = await fs.readdir(dir, { withFileTypes: true }).catch(() => []);
+    if (entries.some(entry => entry.isFile() && entry.name === 'go.mod')) {
+      modules.add(dir);
+    }
+    for (const entry of entries) {
+      if (entry.isDirectory() && !isSkippedDirectory(entry.name)) {
+        await walk(join(dir, entry.name));
+      }
+    }
+  };
+
+  for (const root of roots) {
+    await walk(resolve(root));
+  }
+  return Array.from(modules).sort();
+}
+
+/**
+ * Warms the Go build cache and the analysis cache in the background, one module
+ * at a time. Startup does not wait for it, and `cancel` stops the running build
+ * or analysis. Results that a file change or cache clear overtook are not kept.
+ */
+export class CacheWarmer {
+  private status: WarmupStatus;
+  private controller: AbortController | undefined;
+  private running: Promise<void> | undefined;
+  private logger: Logger;
+
+  constructor(
+    private languageHandlerManager: LanguageHandlerManager,
+    private config: WarmupConfig = {},
+    logger?: Logger
+  ) {
+    this.logger = logger || new Logger('info', { logFile: undefined });
+    this.status = { state: config.enabled ? 'idle' : 'disabled', modules: [] };
+  }
+
+  /**
+   * Start warming unless it is disabled or was started before. Returns at once;
+   * progress is reported by `getStatus`.
+   */
+  start(): void {
+    if (!this.config.enabled || this.running) {
+      return;
+    }
+
+    const controller = new AbortController();
+    this.controller = controller;
+    this.status = { state: 'running', startedAt: new Date().toISOString(), modules: [] };
+    this.running = this.warm(controller.signal).catch(error => {
+      this.status.state = isCancellationError(error) ? 'canceled' : 'failed';
+      if (this.status.state === 'failed') {
+        this.status.error = error instanceof Error ? error.message : String(error);
+        this.logger.warn('Cache warm-up failed', { error: this.status.error });
+      }
+    }).finally(() => {
+      this.status.completedAt = new Date().toISOString();
+    });
+  }
+
+  /**
+   * Stop warming; resolves once the running build or analysis has stopped
+   */
+  async cancel(): Promise<void> {
+    if (this.controller && !this.controller.signal.aborted) {
+      this.controller.abort(new AnalysisCanceledError('Cache warm-up was canceled'));
+    }
+    await this.running;
+  }
+
+  /**
+   * State of the warm-up and of each module found
+   */
+  getStatus(): WarmupStatus {
+    return { ...this.status, modules: this.status.modules.map(module => ({ ...module })) };
+  }
+
+  private async warm(signal: AbortSignal): Promise<void> {
+    const manager = this.languageHandlerManager;
+    if (!manager.getHandler(SupportedLanguage.GO) || !manager.isDetectorEnabled(SupportedLanguage.GO)) {
+      throw new Error('The go detector is not available');
+    }
+
+    const configured = this.config.paths && this.config.paths.length > 0
+      ? this.config.paths
+      : manager.getWorkspaceRoots().list();
+    const paths = await findGoModules(configured.length > 0 ? configured : [process.cwd()], signal);
+    this.status.modules = paths.map(path => ({ path, state: 'pending' }));
+    this.logger.info(`Warming the caches of ${paths.length} Go modules`);
+
+    for (const module of this.status.modules) {
+      if (signal.aborted) {
+        module.state = 'canceled';
+        continue;
+      }
+      await this.warmModule(module, signal);
+    }
+
+    this.status.state = this.status.modules.some(module => module.state === 'canceled') ? 'canceled' : 'completed';
+    this.logger.info(`Cache warm-up ${this.status.state}`);
+  }
+
+  private async warmModule(module: ModuleWarmupStatus, signal: AbortSignal): Promise<void> {
+    const startedAt = Date.now();
+    try {
+      module.state = 'building';
+      module.built = await this.languageHandlerManager.warmBuildCache(module.path, SupportedLanguage.GO, signal);
+
+      module.state = 'analyzing';
+      const errors = await this.languageHandlerManager.analyzePath(module.path, { ...WARMUP_ANALYSIS_OPTIONS, signal })
+        .catch(error => {
+          if (isNoFilesError(error)) {
+            return [];
+          }
+          throw error;
+        });
+      module.diagnostics = errors.length;
+      module.state = 'warm';
+    } catch (error) {
+      if (isCancellationError(error)) {
+        module.state = 'canceled';
+      } else {
+        module.state = 'failed';
+        module.error = error instanceof Error ? error.message : String(error);
+        this.logger.warn(`Failed to warm the caches of ${module.path}`, { error: module.error });
+      }
+    } finally {
+      module.durationMs = Date.now() - startedAt;
+    }
+  }
+}
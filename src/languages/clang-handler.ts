@@ -0,0 +1,527 @@
+/**
+ * C/C++ language handler implementation backed by clang diagnostics
+ */
+
+import { promises as fs } from 'fs';
+import { tmpdir } from 'os';
+import { basename, dirname, extname, isAbsolute, join, resolve } from 'path';
+import { BaseLanguageHandler, type ToolchainProbe } from './base-language-handler.js';
+import type {
+  ColumnEncoding,
+  DetectionOptions,
+  LanguageError,
+  StackFrame,
+  LanguageDebugCapabilities,
+  LanguageDebugConfig,
+  LanguageDebugSession,
+  PerformanceAnalysis
+} from '../types/languages.js';
+import { SupportedLanguage } from '../types/languages.js';
+import { Logger } from '../utils/logger.js';
+import { isCancellationError } from '../utils/cancellation.js';
+import { ToolNotFoundError, isToolNotFoundError } from '../utils/errors.js';
+import { findUpwards } from '../utils/workspace-roots.js';
+
+export interface ClangNote {
+  file: string;
+  line: number;
+  column: number;
+  message: string;
+}
+
+export interface ClangDiagnostic {
+  file: string;
+  line: number;
+  column: number;
+  level: 'fatal error' | 'error' | 'warning' | 'remark' | 'note';
+  message: string;
+  /** Warning flag such as `-Wunused-variable`, when clang names one */
+  flag?: string;
+  notes: ClangNote[];
+}
+
+/**
+ * One entry of a `compile_commands.json` compilation database
+ */
+export interface CompileCommand {
+  directory: string;
+  file: string;
+  arguments?: string[];
+  command?: string;
+}
+
+const CLANG_LINE = /^(.+?):(\d+):(\d+): (fatal error|error|warning|remark|note): (.*)$/;
+const FLAG_SUFFIX = /\s+\[(?:[^\]]*,)?(-W[\w+=-]+)\]$/;
+
+const C_EXTENSIONS = ['.c'];
+const CPP_EXTENSIONS = ['.cc', '.cpp', '.cxx', '.c++', '.h', '.hh', '.hpp', '.hxx'];
+
+/** Flags that take their value as the next argument */
+const PAIRED_FLAGS = new Set(['-I', '-D', '-U', '-isystem', '-iquote', '-idirafter', '-include', '-x']);
+/** Flag prefixes that affect how the file parses and are safe to reuse with -fsyntax-only */
+const KEPT_PREFIXES = ['-I', '-D', '-U', '-isystem', '-iquote', '-idirafter', '-include', '-std=', '-x', '-f', '-W', '-m', '--target=', '--sysroot=', '-nostdinc'];
+
+/**
+ * Parse clang's `file:line:col: level: message` output.
+ * Notes are attached to the diagnostic they follow; relative file names are
+ * resolved against the directory clang ran in.
+ */
+export function parseClangOutput(output: string, cwd: string): ClangDiagnostic[] {
+  const diagnostics: ClangDiagnostic[] = [];
+  let current: ClangDiagnostic | undefined;
+
+  for (const line of output.split('\n')) {
+    const match = line.trimEnd().match(CLANG_LINE);
+    if (!match) {
+      continue;
+    }
+
+    const name = match[1] || '';
+    const file = isAbsolute(name) ? name : resolve(cwd, name);
+    const lineNumber = parseInt(match[2] || '1');
+    const column = parseInt(match[3] || '1');
+    const level = (match[4] || 'error') as ClangDiagnostic['level'];
+    let message = match[5] || 'Unknown error';
+
+    if (level === 'note' && current) {
+      current.notes.push({ file, line: lineNumber, column, message });
+      continue;
+    }
+
+    const flag = message.match(FLAG_SUFFIX);
+    if (flag) {
+      message = message.slice(0, flag.index);
+    }
+
+    current = { file, line: lineNumber, column, level, message, notes: [] };
+    if (flag?.[1]) {
+      current.flag = flag[1];
+    }
+    diagnostics.push(current);
+  }
+
+  return diagnostics;
+}
+
+/**
+ * Split a shell command line as written in `compile_commands.json`
+ */
+export function splitCommandLine(command: string): string[] {
+  const args: string[] = [];
+  let currentArg = '';
+  let quote: string | undefined;
+  let pending = false;
+
+  for (let i = 0; i < command.length; i++) {
+    const char = command[i]!;
+
+    if (quote) {
+      if (char === quote) {
+        quote = undefined;
+      } else if (char === '\\' && quote === '"' && i + 1 < command.length) {
+        currentArg += command[++i];
+      } else {
+        currentArg += char;
+      }
+    } else if (char === '"' || char === "'") {
+      quote = char;
+      pending = true;
+    } else if (char === '\\' && i + 1 < command.length) {
+      currentArg += command[++i];
+      pending = true;
+    } else if (/\s/.test(char)) {
+      if (pending || currentArg) {
+        args.push(currentArg);
+      }
+      currentArg = '';
+      pending = false;
+    } else {
+      currentArg += char;
+    }
+  }
+
+  if (pending || currentArg) {
+    args.push(currentArg);
+  }
+  return args;
+}
+
+/**
+ * Keep the include paths, defines and language flags of a compile command,
+ * dropping the compiler, outputs and input files
+ */
+export function extractCompileFlags(entry: CompileCommand): string[] {
+  const args = entry.arguments || splitCommandLine(entry.command || '');
+  const flags: string[] = [];
+
+  for (let i = 1; i < args.length; i++) {
+    const arg = args[i]!;
+
+    if (arg === '-o' || arg === '-MF' || arg === '-MT' || arg === '-MQ') {
+      i++;
+      continue;
+    }
+
+    if (PAIRED_FLAGS.has(arg)) {
+      const value = args[i + 1];
+      if (value !== undefined) {
+        flags.push(arg, value);
+      }
+      i++;
+      continue;
+    }
+
+    if (arg === '-c' || arg.startsWith('-M') || !KEPT_PREFIXES.some(prefix => arg.startsWith(prefix))) {
+      continue;
+    }
+    flags.push(arg);
+  }
+
+  return flags;
+}
+
+export class ClangHandler extends BaseLanguageHandler {
+  private clangPath: string | undefined;
+  private clangxxPath: string | undefined;
+
+  constructor(options: Record<string, unknown> = {}, logger?: Logger) {
+    super(SupportedLanguage.CPP, options, logger);
+  }
+
+  getFileExtensions(): string[] {
+    return [...C_EXTENSIONS, ...CPP_EXTENSIONS];
+  }
+
+  /** clang counts bytes from the start of the line buffer, BOM included */
+  getColumnEncoding(): ColumnEncoding {
+    return { unit: 'byte', includesBom: true };
+  }
+
+  getConfigFiles(): string[] {
+    return [
+      'compile_commands.json',
+      'compile_flags.txt',
+      'CMakeLists.txt',
+      '.clang-tidy',
+      '.clang-format'
+    ];
+  }
+
+  protected async doInitialize(): Promise<void> {
+    this.clangxxPath = await this.findExecutable('clang++');
+    this.clangPath = await this.findExecutable('clang');
+
+    if (!this.clangxxPath && !this.clangPath) {
+      throw new ToolNotFoundError('clang', 'clang not found on PATH. Please install clang to use C/C++ error detection.');
+    }
+
+    this.logger.info('C/C++ handler initialized', {
+      clangPath: this.clangPath,
+      clangxxPath: this.clangxxPath
+    });
+  }
+
+  protected async doDispose(): Promise<void> {
+    this.clangPath = undefined;
+    this.clangxxPath = undefined;
+  }
+
+  protected getToolchainProbe(): ToolchainProbe {
+    return { command: this.clangxxPath || this.clangPath || 'clang++', args: ['--version'] };
+  }
+
+  protected async checkAvailability(): Promise<boolean> {
+    try {
+      const compiler = this.clangxxPath || this.clangPath || 'clang++';
+      const result = await this.runCommand(compiler, ['--version']);
+      return result.exitCode === 0;
+    } catch {
+      return false;
+    }
+  }
+
+  async detectErrors(source: string, options?: DetectionOptions): Promise<LanguageError[]> {
+    const filePath = options?.filePath;
+
+    // Use the project's compile flags when the file is on disk unchanged and listed in a database
+    const entry = filePath && await this.isUnmodifiedOnDisk(filePath, source)
+      ? await this.findCompileCommand(filePath, options?.workspaceRoot)
+      : undefined;
+
+    if (filePath && entry) {
+      try {
+        return await this.checkCompiledFile(entry, filePath);
+      } catch (error) {
+        if (isToolNotFoundError(error) || isCancellationError(error)) {
+          throw error;
+        }
+        this.logger.debug('Compilation database check failed, falling back to syntax check', error);
+      }
+    }
+
+    return this.validateSyntax(source, filePath);
+  }
+
+  protected async validateSyntax(source: string, filePath = 'temp.cpp'): Promise<LanguageError[]> {
+    const extension = extname(filePath) || '.cpp';
+    const tempDir = await fs.mkdtemp(join(tmpdir(), 'clang-syntax-check-'));
+    const tempFile = join(tempDir, `input${extension}`);
+
+    try {
+      await fs.writeFile(tempFile, source);
+
+      // Best effort: headers next to the original file still resolve
+      const includes = isAbsolute(filePath) ? ['-I', dirname(filePath)] : [];
+      const compiler = this.compilerFor(filePath);
+      const result = await this.runCommand(compiler, [
+        ...this.baseFlags(),
+        ...includes,
+        tempFile
+      ], { cwd: tempDir });
+
+      const diagnostics = parseClangOutput(result.stderr, tempDir);
+      if (this.isUnparsedFailure(result, diagnostics)) {
+        return [this.createToolchainError(basename(compiler), result, filePath)];
+      }
+
+      const target = resolve(tempFile);
+      return this.convertDiagnostics(diagnostics, file =>
+        file === target ? filePath : undefined
+      );
+    } catch (error) {
+      if (isToolNotFoundError(error) || isCancellationError(error)) {
+        throw error;
+      }
+      return [this.createError(
+        `Syntax validation failed: ${error instanceof Error ? error.message : 'Unknown error'}`,
+        filePath,
+        1,
+        1,
+        'error'
+      )];
+    } finally {
+      await fs.rm(tempDir, { recursive: true, force: true }).catch(() => {});
+    }
+  }
+
+  /**
+   * Find the compilation database entry for a file, looking in each parent
+   * directory and its `build/` subdirectory. The nearest database wins even when
+   * it does not list the file.
+   */
+  private async findCompileCommand(filePath: string, workspaceRoot?: string): Promise<CompileCommand | undefined> {
+    const target = resolve(filePath);
+    const database = await findUpwards(target, ['compile_commands.json', join('build', 'compile_commands.json')], workspaceRoot);
+    if (!database) {
+      return undefined;
+    }
+
+    const entries = await this.readCompileCommands(database);
+    return entries?.find(item => resolve(item.directory || dirname(database), item.file) === target);
+  }
+
+  private async readCompileCommands(path: string): Promise<CompileCommand[] | undefined> {
+    try {
+      const parsed = JSON.parse(await fs.readFile(path, 'utf-8'));
+      return Array.isArray(parsed) ? parsed as CompileCommand[] : undefined;
+    } catch {
+      return undefined;
+    }
+  }
+
+  /**
+   * Run clang on the file in place with the include paths and defines from its compile command
+   */
+  private async checkCompiledFile(entry: CompileCommand, filePath: string): Promise<LanguageError[]> {
+    const target = resolve(filePath);
+    const cwd = entry.directory || dirname(target);
+
+    const compiler = this.compilerFor(filePath);
+    const result = await this.runCommand(compiler, [
+      ...this.baseFlags(),
+      ...extractCompileFlags(entry),
+      target
+    ], { cwd });
+
+    const diagnostics = parseClangOutput(result.stderr, cwd);
+    if (this.isUnparsedFailure(result, diagnostics)) {
+      return [this.createToolchainError(basename(compiler), result, filePath)];
+    }
+
+    return this.convertDiagnostics(diagnostics, file =>
+      file === target ? filePath : undefined
+    );
+  }
+
+  private baseFlags(): string[] {
+    return [
+      '-fsyntax-only',
+      '-fno-caret-diagnostics',
+      '-fno-color-diagnostics',
+      '-fdiagnostics-show-option'
+    ];
+  }
+
+  private compilerFor(filePath: string): string {
+    const isC = C_EXTENSIONS.includes(extname(filePath).toLowerCase());
+    if (isC) {
+      return this.clangPath || this.clangxxPath!;
+    }
+    return this.clangxxPath || this.clangPath!;
+  }
+
+  /**
+   * Keep diagnostics reported against the checked file; notes become related information
+   */
+  private convertDiagnostics(
+    diagnostics: ClangDiagnostic[],
+    resolveFile: (file: string) => string | undefined
+  ): LanguageError[] {
+    const errors: LanguageError[] = [];
+
+    for (const diagnostic of diagnostics) {
+      const file = resolveFile(diagnostic.file);
+      if (!file) {
+        continue;
+      }
+
+      const error = this.createError(
+        diagnostic.message,
+        file,
+        diagnostic.line,
+        diagnostic.column,
+        this.mapClangSeverity(diagnostic.level),
+        diagnostic.flag
+      );
+      error.relatedInformation = diagnostic.notes.map(note => ({
+        location: {
+          file: this.normalizePath(resolveFile(note.file) || note.file),
+          line: note.line,
+          column: note.column
+        },
+        message: `note: ${note.message}`
+      }));
+      errors.push(error);
+    }
+
+    return errors;
+  }
+
+  private mapClangSeverity(level: ClangDiagnostic['level']): 'error' | 'warning' | 'info' | 'hint' {
+    switch (level) {
+      case 'fatal error':
+      case 'error': return 'error';
+      case 'warning': return 'warning';
+      case 'note': return 'info';
+      case 'remark': return 'hint';
+      default: return 'error';
+    }
+  }
+
+  parseStackTrace(stackTrace: string): StackFrame[] {
+    const frames: StackFrame[] = [];
+    const lines = stackTrace.split('\n');
+
+    for (const line of lines) {
+      // Sanitizer / gdb format: #0 0x4005d4 in main /src/main.cpp:10:5
+      const match = line.match(/#\d+\s+(?:0x[0-9a-f]+\s+in\s+)?(.+?)\s+(?:at\s+)?(\S+?):(\d+)(?::(\d+))?\s*$/);
+      if (match) {
+        frames.push({
+          function: match[1] || '<unknown>',
+          file: match[2] || '<unknown>',
+          line: parseInt(match[3] || '1'),
+          column: parseInt(match[4] || '1')
+        });
+      }
+    }
+
+    return frames;
+  }
+
+  getDebugCapabilities(): LanguageDebugCapabilities {
+    return {
+      supportsBreakpoints: true,
+      supportsConditionalBreakpoints: true,
+      supportsStepInto: true,
+      supportsStepOver: true,
+      supportsStepOut: true,
+      supportsVariableInspection: true,
+      supportsWatchExpressions: true,
+      supportsHotReload: false,
+      supportsRemoteDebugging: true,
+      // Legacy properties for backward compatibility
+      breakpoints: true,
+      stepDebugging: true,
+      variableInspection: true,
+      callStackInspection: true,
+      conditionalBreakpoints: true,
+      hotReload: false,
+      profiling: true,
+      memoryInspection: true
+    };
+  }
+
+  async createDebugSession(_config: LanguageDebugConfig): Promise<LanguageDebugSession> {
+    // This would integrate with lldb or gdb
+    throw new Error('Debug session creation not implemented yet');
+  }
+
+  async analyzePerformance(source: string): Promise<PerformanceAnalysis> {
+    const complexity = this.calculateComplexity(source);
+    return {
+      complexity,
+      suggestions: this.getPerformanceSuggestions(source),
+      metrics: {
+        linesOfCode: source.split('\n').length,
+        cyclomaticComplexity: complexity
+      }
+    };
+  }
+
+  protected getErrorPatterns(): RegExp[] {
+    return [
+      /error: (.+)/,
+      /warning: (.+)/,
+      /note: (.+)/,
+      /use of undeclared identifier/,
+      /no matching function for call to/,
+      /expected ';'/
+    ];
+  }
+
+  private calculateComplexity(source: string): number {
+    const patterns = [
+      /\bif\b/g,
+      /\bfor\b/g,
+      /\bwhile\b/g,
+      /\bcase\b/g,
+      /\bcatch\b/g,
+      /&&/g,
+      /\|\|/g
+    ];
+
+    let complexity = 1;
+    for (const pattern of patterns) {
+      const matches = source.match(pattern);
+      if (matches) {
+        complexity += matches.length;
+      }
+    }
+
+    return complexity;
+  }
+
+  private getPerformanceSuggestions(source: string): string[] {
+    const suggestions: string[] = [];
+
+    if (/\bstd::endl\b/.test(source)) {
+      suggestions.push("Prefer '\\n' over std::endl to avoid flushing the stream on every line");
+    }
+
+    if (source.includes('push_back(') && !source.includes('reserve(')) {
+      suggestions.push('Consider calling reserve() when the final vector size is known');
+    }
+
+    return suggestions;
+  }
+}
@@ -0,0 +1,221 @@
+/**
+ * Detector for tools defined entirely in the workspace config: a command, the
+ * extensions it checks and a regex that turns its output lines into diagnostics
+ */
+
+import { dirname, relative, resolve } from 'path';
+import { BaseLanguageHandler, type CommandResult } from './base-language-handler.js';
+import type {
+  DetectionOptions,
+  LanguageError,
+  StackFrame,
+  LanguageDebugCapabilities,
+  LanguageDebugConfig,
+  LanguageDebugSession,
+  PerformanceAnalysis
+} from '../types/languages.js';
+import { Logger } from '../utils/logger.js';
+import { compileCommandPattern, type CommandDetectorConfig } from '../utils/workspace-config.js';
+
+/** How long a command may run when its definition sets no `timeoutMs` */
+export const DEFAULT_COMMAND_TIMEOUT_MS = 30_000;
+
+const SEVERITY_WORDS: ReadonlyArray<[RegExp, LanguageError['severity']]> = [
+  [/^(?:e|err|error|fatal|critical|high)$/i, 'error'],
+  [/^(?:w|warn|warning|medium)$/i, 'warning'],
+  [/^(?:i|info|information|note|low)$/i, 'info'],
+  [/^(?:h|hint|style|suggestion)$/i, 'hint']
+];
+
+/**
+ * Map a tool's severity word to a diagnostic severity
+ */
+export function parseCommandSeverity(
+  text: string | undefined,
+  fallback: LanguageError['severity']
+): LanguageError['severity'] {
+  const word = text?.trim();
+  return (word && SEVERITY_WORDS.find(([pattern]) => pattern.test(word))?.[1]) || fallback;
+}
+
+/**
+ * Split a command string on whitespace, keeping quoted arguments together.
+ * No shell is involved, so pipes and variables are passed through literally.
+ */
+export function splitCommandTemplate(command: string): string[] {
+  const args: string[] = [];
+  for (const match of command.matchAll(/"((?:[^"\\]|\\.)*)"|'([^']*)'|(\S+)/g)) {
+    args.push(match[1] !== undefined ? match[1].replace(/\\(.)/g, '$1') : match[2] ?? match[3]!);
+  }
+  return args;
+}
+
+/**
+ * The program a command definition starts, as written in the config
+ */
+export function commandProgram(definition: CommandDetectorConfig): string | undefined {
+  return Array.isArray(definition.command) ? definition.command[0] : splitCommandTemplate(definition.command)[0];
+}
+
+export class GenericCommandDetector extends BaseLanguageHandler {
+  readonly timeoutMs: number;
+  private readonly template: string[];
+  private readonly extensions: string[];
+  private readonly pattern: RegExp;
+
+  /**
+   * @param name Key of the definition in `commands`; diagnostics carry it as `source`
+   * @param cwd Directory the command runs in, normally the workspace root
+   */
+  constructor(
+    name: string,
+    private readonly definition: CommandDetectorConfig,
+    private readonly cwd: string,
+    logger?: Logger
+  ) {
+    super(name, definition.env ? { env: definition.env } : {}, logger);
+    this.template = Array.isArray(definition.command) ? definition.command : splitCommandTemplate(definition.command);
+    this.extensions = definition.extensions.map(ext => ext.startsWith('.') ? ext : `.${ext}`);
+    this.pattern = compileCommandPattern(definition.pattern);
+    this.timeoutMs = definition.timeoutMs ?? DEFAULT_COMMAND_TIMEOUT_MS;
+  }
+
+  getFileExtensions(): string[] {
+    return this.extensions;
+  }
+
+  getConfigFiles(): string[] {
+    return [];
+  }
+
+  protected async doInitialize(): Promise<void> {
+    // Nothing to discover; the command is found on PATH when it runs
+  }
+
+  protected async doDispose(): Promise<void> {
+    // No long-lived processes
+  }
+
+  protected async checkAvailability(): Promise<boolean> {
+    const [command] = this.template;
+    return command !== undefined && (command.includes('/') || await this.findExecutable(command) !== undefined);
+  }
+
+  /**
+   * Run the command on the file as saved on disk. A non-zero exit is what linters
+   * do when they find something, so it only counts as a failure when no output
+   * line matched the pattern.
+   */
+  async detectErrors(_source: string, options?: DetectionOptions): Promise<LanguageError[]> {
+    if (!options?.filePath) {
+      return [];
+    }
+
+    const filePath = resolve(options.filePath);
+    const [command, ...args] = this.expandTemplate(filePath);
+    const result = await this.runCommand(command!, args, { cwd: this.cwd });
+
+    const errors = this.parseOutput(result, filePath);
+    if (this.isUnparsedFailure(result, errors)) {
+      return [this.createToolchainError(this.language, result, filePath)];
+    }
+    return errors;
+  }
+
+  /**
+   * Substitute the file into the template, appending it when no argument names it
+   */
+  private expandTemplate(filePath: string): string[] {
+    const values: Record<string, string> = {
+      file: filePath,
+      relativeFile: relative(this.cwd, filePath) || filePath,
+      dir: dirname(filePath)
+    };
+    const expanded = this.template.map(arg => arg.replace(/\{(file|relativeFile|dir)\}/g, (_, key: string) => values[key]!));
+    const namesFile = this.template.some(arg => /\{(?:file|relativeFile)\}/.test(arg));
+    return namesFile ? expanded : [...expanded, filePath];
+  }
+
+  /**
+   * Diagnostics for the analyzed file from every matching output line. Lines that
+   * name another file are dropped, as that file gets its own analysis.
+   */
+  private parseOutput(result: CommandResult, filePath: string): LanguageError[] {
+    const fallback = this.definition.defaultSeverity || 'warning';
+    const errors: LanguageError[] = [];
+
+    for (const line of `${result.stdout}\n${result.stderr}`.split('\n')) {
+      const groups = this.pattern.exec(line.replace(/\r$/, ''))?.groups;
+      if (!groups || !groups['message']?.trim()) {
+        continue;
+      }
+
+      if (groups['file'] && resolve(this.cwd, groups['file'].trim()) !== filePath) {
+        continue;
+      }
+
+      const code = groups['code']?.trim();
+      errors.push({
+        message: groups['message'].trim(),
+        severity: parseCommandSeverity(groups['severity'], fallback),
+        location: {
+          file: this.normalizePath(filePath),
+          line: Math.max(1, parseInt(groups['line'] || '1') || 1),
+          column: Math.max(1, parseInt(groups['col'] || '1') || 1)
+        },
+        source: this.language,
+        ...(code && { code }),
+        relatedInformation: []
+      });
+    }
+
+    return errors;
+  }
+
+  parseStackTrace(_stackTrace: string): StackFrame[] {
+    return [];
+  }
+
+  getDebugCapabilities(): LanguageDebugCapabilities {
+    return {
+      supportsBreakpoints: false,
+      supportsConditionalBreakpoints: false,
+      supportsStepInto: false,
+      supportsStepOver: false,
+      supportsStepOut: false,
+      supportsVariableInspection: false,
+      supportsWatchExpressions: false,
+      supportsHotReload: false,
+      supportsRemoteDebugging: false,
+      // Legacy properties for backward compatibility
+      breakpoints: false,
+      stepDebugging: false,
+      variableInspection: false,
+      callStackInspection: false,
+      conditionalBreakpoints: false,
+      hotReload: false,
+      profiling: false,
+      memoryInspection: false
+    };
+  }
+
+  async createDebugSession(_config: LanguageDebugConfig): Promise<LanguageDebugSession> {
+    throw new Error(`Debugging is not supported for the ${this.language} command detector`);
+  }
+
+  async analyzePerformance(source: string): Promise<PerformanceAnalysis> {
+    return {
+      complexity: 1,
+      suggestions: [],
+      metrics: { linesOfCode: source.split('\n').length, cyclomaticComplexity: 1 }
+    };
+  }
+
+  protected async validateSyntax(_source: string): Promise<LanguageError[]> {
+    return [];
+  }
+
+  protected getErrorPatterns(): RegExp[] {
+    return [this.pattern];
+  }
+}
@@ -0,0 +1,243 @@
+/**
+ * Go build context and build constraint evaluation
+ */
+
+import { basename } from 'path';
+
+export interface GoBuildContext {
+  tags?: string[];
+  goos?: string;
+  goarch?: string;
+  cgoEnabled?: boolean;
+}
+
+export interface ResolvedGoBuildContext {
+  tags: string[];
+  goos: string;
+  goarch: string;
+  cgoEnabled: boolean;
+}
+
+export interface BuildConstraintResult {
+  satisfied: boolean;
+  constraint?: string;
+  reason?: string;
+}
+
+export const KNOWN_GOOS = Object.freeze([
+  'aix', 'android', 'darwin', 'dragonfly', 'freebsd', 'hurd', 'illumos', 'ios', 'js',
+  'linux', 'nacl', 'netbsd', 'openbsd', 'plan9', 'solaris', 'wasip1', 'windows', 'zos',
+]);
+
+export const KNOWN_GOARCH = Object.freeze([
+  '386', 'amd64', 'amd64p32', 'arm', 'arm64', 'arm64be', 'armbe', 'loong64', 'mips',
+  'mips64', 'mips64le', 'mips64p32', 'mips64p32le', 'mipsle', 'ppc', 'ppc64', 'ppc64le',
+  'riscv', 'riscv64', 's390', 's390x', 'sparc', 'sparc64', 'wasm',
+]);
+
+const UNIX_GOOS = new Set([
+  'aix', 'android', 'darwin', 'dragonfly', 'freebsd', 'hurd', 'illumos', 'ios',
+  'linux', 'netbsd', 'openbsd', 'solaris',
+]);
+
+/**
+ * Map the Node.js platform/arch onto Go's GOOS/GOARCH names
+ */
+export function hostGoBuildContext(): ResolvedGoBuildContext {
+  const goosMap: Record<string, string> = { win32: 'windows', sunos: 'solaris' };
+  const goarchMap: Record<string, string> = { x64: 'amd64', ia32: '386', x32: '386' };
+
+  return {
+    tags: [],
+    goos: goosMap[process.platform] || process.platform,
+    goarch: goarchMap[process.arch] || process.arch,
+    cgoEnabled: true,
+  };
+}
+
+/**
+ * Collect the tags that satisfy constraints under a context
+ */
+function satisfiedTags(context: ResolvedGoBuildContext): Set<string> {
+  const tags = new Set<string>([context.goos, context.goarch, 'gc', ...context.tags]);
+
+  if (UNIX_GOOS.has(context.goos)) {
+    tags.add('unix');
+  }
+  // android implies linux, ios implies darwin, illumos implies solaris
+  if (context.goos === 'android') tags.add('linux');
+  if (context.goos === 'ios') tags.add('darwin');
+  if (context.goos === 'illumos') tags.add('solaris');
+  if (context.cgoEnabled) {
+    tags.add('cgo');
+  }
+
+  return tags;
+}
+
+function isTagSatisfied(tag: string, tags: Set<string>): boolean {
+  // Release tags (go1.21 etc.) are assumed satisfied by the installed toolchain
+  if (/^go1\.\d+$/.test(tag)) {
+    return true;
+  }
+  return tags.has(tag);
+}
+
+/**
+ * Evaluate a `//go:build` expression such as `linux && (amd64 || arm64) && !cgo`
+ */
+export function evaluateBuildExpression(expression: string, context: ResolvedGoBuildContext): boolean {
+  const tokens = expression.match(/&&|\|\||!|\(|\)|[\w.]+/g) || [];
+  const tags = satisfiedTags(context);
+  let position = 0;
+
+  const parseOr = (): boolean => {
+    let value = parseAnd();
+    while (tokens[position] === '||') {
+      position++;
+      const right = parseAnd();
+      value = value || right;
+    }
+    return value;
+  };
+
+  const parseAnd = (): boolean => {
+    let value = parseNot();
+    while (tokens[position] === '&&') {
+      position++;
+      const right = parseNot();
+      value = value && right;
+    }
+    return value;
+  };
+
+  const parseNot = (): boolean => {
+    if (tokens[position] === '!') {
+      position++;
+      return !parseNot();
+    }
+    if (tokens[position] === '(') {
+      position++;
+      const value = parseOr();
+      if (tokens[position] !== ')') {
+        throw new Error(`Invalid build constraint: ${expression}`);
+      }
+      position++;
+      return value;
+    }
+    const tag = tokens[position];
+    if (!tag || tag === ')' || tag === '&&' || tag === '||') {
+      throw new Error(`Invalid build constraint: ${expression}`);
+    }
+    position++;
+    return isTagSatisfied(tag, tags);
+  };
+
+  const result = parseOr();
+  if (position !== tokens.length) {
+    throw new Error(`Invalid build constraint: ${expression}`);
+  }
+  return result;
+}
+
+/**
+ * Evaluate legacy `// +build` lines: spaces are OR, commas are AND, lines are ANDed
+ */
+function evaluateLegacyBuildLines(lines: string[], context: ResolvedGoBuildContext): boolean {
+  const tags = satisfiedTags(context);
+
+  return lines.every(line =>
+    line.split(/\s+/).filter(Boolean).some(option =>
+      option.split(',').every(term =>
+        term.startsWith('!') ? !isTagSatisfied(term.slice(1), tags) : isTagSatisfied(term, tags)
+      )
+    )
+  );
+}
+
+/**
+ * Check the `_GOOS`, `_GOARCH` and `_GOOS_GOARCH` file name suffixes
+ */
+export function matchesFileNameConstraints(filePath: string, context: ResolvedGoBuildContext): boolean {
+  const name = basename(filePath).replace(/\.go$/, '').replace(/_test$/, '');
+  const parts = name.split('_');
+
+  if (parts.length < 2) {
+    return true;
+  }
+
+  const last = parts[parts.length - 1] || '';
+  const secondLast = parts.length > 2 ? parts[parts.length - 2] || '' : '';
+
+  if (KNOWN_GOARCH.includes(last)) {
+    if (KNOWN_GOOS.includes(secondLast)) {
+      return last === context.goarch && satisfiedTags(context).has(secondLast);
+    }
+    return last === context.goarch;
+  }
+
+  if (KNOWN_GOOS.includes(last)) {
+    return satisfiedTags(context).has(last);
+  }
+
+  return true;
+}
+
+/**
+ * Check whether a Go source file would be included in a build under the given context
+ */
+export function checkBuildConstraints(
+  source: string,
+  filePath: string,
+  context: ResolvedGoBuildContext
+): BuildConstraintResult {
+  if (!matchesFileNameConstraints(filePath, context)) {
+    return {
+      satisfied: false,
+      reason: `file name suffix does not match GOOS=${context.goos} GOARCH=${context.goarch}`,
+    };
+  }
+
+  const legacyLines: string[] = [];
+
+  // Constraints must appear before the package clause
+  for (const rawLine of source.split('\n')) {
+    const line = rawLine.trim();
+
+    if (line.startsWith('package ')) {
+      break;
+    }
+
+    const goBuild = line.match(/^\/\/go:build\s+(.+)$/);
+    if (goBuild && goBuild[1]) {
+      const constraint = goBuild[1].trim();
+      try {
+        const satisfied = evaluateBuildExpression(constraint, context);
+        return satisfied ? { satisfied, constraint } : {
+          satisfied,
+          constraint,
+          reason: `//go:build ${constraint} is not satisfied for GOOS=${context.goos} GOARCH=${context.goarch}`,
+        };
+      } catch {
+        // Leave malformed constraints for the compiler to report
+        return { satisfied: true, constraint };
+      }
+    }
+
+    const legacy = line.match(/^\/\/\s*\+build\s+(.+)$/);
+    if (legacy && legacy[1]) {
+      legacyLines.push(legacy[1].trim());
+    }
+  }
+
+  if (legacyLines.length > 0 && !evaluateLegacyBuildLines(legacyLines, context)) {
+    const constraint = legacyLines.join(' / ');
+    return {
+      satisfied: false,
+      constraint,
+      reason: `// +build ${constraint} is not satisfied for GOOS=${context.goos} GOARCH=${context.goarch}`,
+    };
+  }
+
+  return { satisfied: true };
+}
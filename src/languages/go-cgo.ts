@@ -0,0 +1,305 @@
+/**
+ * cgo build failures: C compiler and linker output of a Go build, reported at the
+ * Go source the C code came from
+ */
+
+import { isAbsolute, join, resolve } from 'path';
+import type { LanguageError } from '../types/languages.js';
+import { formatMessage } from '../utils/messages.js';
+
+/** `analyzer` of diagnostics from the C toolchain of a cgo build */
+export const GO_CGO_ANALYZER = 'cgo';
+
+export interface CgoPosition {
+  line: number;
+  column: number;
+  endColumn: number;
+}
+
+export interface CgoParseOptions {
+  /** Name of the analyzed file in build output, such as `main.go` */
+  buildFile: string;
+  /** Path the diagnostics are reported against */
+  filePath: string;
+  /** Package directory the build ran in, to match absolute paths */
+  buildDir?: string;
+  /** Content of the analyzed file, used to find `import "C"` and the C names it uses */
+  source?: string;
+}
+
+export interface CgoParseResult {
+  errors: LanguageError[];
+  /** The output without the C toolchain lines, for the Go error parser */
+  remaining: string;
+}
+
+// ./main.go:6:9: error: unknown type name 'foo' / cgo-gcc-prolog:52:33: warning: ...
+const C_DIAGNOSTIC = /^(.+?):(\d+)(?::(\d+))?: (fatal error|error|warning|note): (.*)$/;
+// In file included from /usr/include/a.h:5, / from ./main.go:3:
+const INCLUDED_FROM = /^(?:In file included from|\s+from) (.+?):(\d+)(?::\d+)?[:,]$/;
+// cgo-gcc-prolog: In function '_cgo_5c1a2b_Cfunc_add':
+const IN_FUNCTION = /^(.+?): In function [‘'`"]?([^’'`":]+)[’'`"]?:$/;
+// The line after a diagnostic holds the code, then a caret; gcc prefixes both with ` 6 |`
+const SOURCE_CONTEXT = /^\s*\d*\s*\|/;
+const C_SUMMARY = /^(?:compilation terminated\.|\d+ (?:warnings?|errors?)(?: and \d+ (?:warnings?|errors?))? generated\.|cc1\w*: .+)$/;
+const LINKER = /^(?:\S*[\\/])?ld(?:\.\w+)?(?:\.exe)?: (.+)$/;
+const LINKER_SUMMARY = /^(?:(?:\S*[\\/])?(?:collect2|clang(?:-\d+)?|gcc|cc)(?:\.exe)?: error: (?:ld returned|linker command failed)|\S*[\\/]link(?:\.exe)?: running \S+ failed)/;
+// /tmp/go-build/cgo-gcc-prolog:52: undefined reference to `add'
+const UNDEFINED_REFERENCE = /undefined reference to [`'‘"](\w+)[’'"]/;
+const UNDEFINED_SYMBOLS = /^Undefined symbols for architecture \S+:$/;
+const MISSING_COMPILER = /^(?:cgo: )?(?:C compiler "(\S+)" not found|exec: "(\S+)": executable file not found)/;
+
+/** Files cgo generates into the build's work directory, or names its #line directives give */
+const GENERATED_FILE = /^\$WORK[\\/]|(?:^|[\\/])(?:cgo-(?:gcc|builtin)-prolog|cgo-generated-wrapper|_cgo_\w+\.[ch]|\w+\.cgo\d\.c)$/;
+/** C function wrappers generated for `C.name`, e.g. `_cgo_5c1a2b_Cfunc_add` */
+const CFUNC_WRAPPER = /_Cfunc_(\w+)$/;
+
+function byteColumn(lineText: string, index: number): number {
+  return Buffer.byteLength(lineText.slice(0, index), 'utf-8') + 1;
+}
+
+/**
+ * The `C` of the `import "C"` that introduces the preamble
+ */
+export function locateCgoImport(source: string): CgoPosition | undefined {
+  const lines = source.split('\n');
+  for (let index = 0; index < lines.length; index++) {
+    const text = lines[index]!.replace(/\r$/, '');
+    const match = text.match(/^(\s*import\s+)"C"/) || text.match(/^(\s*)"C"\s*(?:\/\/.*)?$/);
+    if (match) {
+      const start = match[1]!.length;
+      return { line: index + 1, column: byteColumn(text, start), endColumn: byteColumn(text, start + 3) };
+    }
+  }
+  return undefined;
+}
+
+/**
+ * The first use of `C.name` in Go code, where a failure in the code cgo
+ * generates for it is best reported
+ */
+export function locateCgoReference(source: string, name: string): CgoPosition | undefined {
+  const pattern = new RegExp(`(?<![\\w.])C\\.${name}(?!\\w)`);
+  const lines = source.split('\n');
+  for (let index = 0; index < lines.length; index++) {
+    const text = lines[index]!.replace(/\r$/, '');
+    // The preamble is C, where C.name does not occur
+    if (/^\s*(?:\/\/|\/\*|\*)/.test(text)) {
+      continue;
+    }
+    const match = pattern.exec(text);
+    if (match) {
+      return {
+        line: index + 1,
+        column: byteColumn(text, match.index),
+        endColumn: byteColumn(text, match.index + match[0].length)
+      };
+    }
+  }
+  return undefined;
+}
+
+/**
+ * Map a C compiler column on a preamble line to a column of the Go line. cgo
+ * hands the compiler the comment text with its `//` or `/*` markers removed and
+ * a `#line` directive per line, so lines already match and only the markers
+ * shift columns.
+ */
+export function mapPreambleColumn(lineText: string, column: number): number {
+  const marker = lineText.match(/^(\s*)(\/\/|\/\*)/);
+  return marker ? column + byteColumn(lineText, marker[1]!.length + 2) - 1 : column;
+}
+
+type FileKind = 'own' | 'other' | 'generated' | 'header';
+
+interface Reported {
+  file: string;
+  line: number;
+}
+
+/**
+ * Parse the C toolchain output of a failed cgo build. The compiler already
+ * applies the `#line` directives cgo emits, so problems in the preamble arrive
+ * at `.go` positions; these are kept for the analyzed file. Problems in headers
+ * are reported at the `#include` that pulled them in. Problems in code cgo
+ * generates, and link failures, are reported at the `C.name` use they concern,
+ * or at `import "C"` when that is unknown.
+ */
+export function parseCgoErrors(output: string, options: CgoParseOptions): CgoParseResult {
+  const lines = output.split('\n').map(line => line.replace(/\r$/, ''));
+  const sourceLines = options.source?.split('\n').map(line => line.replace(/\r$/, ''));
+  const importC = options.source ? locateCgoImport(options.source) : undefined;
+  const errors: LanguageError[] = [];
+  const remaining: string[] = [];
+  const seen = new Set<string>();
+
+  let includes: Reported[] = [];
+  let cFunction: string | undefined;
+  let current: LanguageError | undefined;
+  let inCOutput = false;
+
+  const classify = (file: string): FileKind => {
+    const name = file.replace(/^\.[\\/]/, '');
+    if (name === options.buildFile ||
+      (options.buildDir && isAbsolute(file) && resolve(file) === join(options.buildDir, options.buildFile))) {
+      return 'own';
+    }
+    if (GENERATED_FILE.test(file)) {
+      return 'generated';
+    }
+    // Other Go files of the package are reported when they are analyzed
+    return name.endsWith('.go') ? 'other' : 'header';
+  };
+
+  // Where a problem without a position in the analyzed file goes
+  const fallback = (name?: string): CgoPosition | undefined =>
+    (name && options.source ? locateCgoReference(options.source, name) : undefined) || importC;
+
+  const push = (error: LanguageError) => {
+    const key = `${error.location.line}:${error.location.column}:${error.message}`;
+    current = undefined;
+    if (!seen.has(key)) {
+      seen.add(key);
+      errors.push(error);
+      current = error;
+    }
+  };
+
+  const create = (
+    message: string,
+    position: CgoPosition | { line: number; column: number },
+    severity: LanguageError['severity'],
+    code: string,
+    suggestedFix?: string
+  ): LanguageError => ({
+    message,
+    severity,
+    location: {
+      file: options.filePath,
+      line: position.line,
+      column: position.column,
+      ...('endColumn' in position && position.endColumn > position.column && { endLine: position.line, endColumn: position.endColumn })
+    },
+    code,
+    source: 'go',
+    analyzer: GO_CGO_ANALYZER,
+    relatedInformation: [],
+    ...(suggestedFix && { suggestedFix })
+  });
+
+  const compilerDiagnostic = (match: RegExpMatchArray) => {
+    const [, file, lineText, columnText, kind, rawText] = match;
+    const chain = includes;
+    includes = [];
+    const flag = rawText!.match(/\s*\[(-W[^\]]+)\]$/);
+    const text = flag ? rawText!.slice(0, flag.index) : rawText!;
+    const line = parseInt(lineText!);
+    const column = columnText ? parseInt(columnText) : 1;
+    let fileKind = classify(file!);
+
+    if (kind === 'note') {
+      if (current && fileKind !== 'generated') {
+        current.relatedInformation!.push({
+          location: {
+            file: fileKind === 'own' ? options.filePath : options.buildDir && !isAbsolute(file!) ? resolve(options.buildDir, file!) : file!,
+            line,
+            column
+          },
+          message: text
+        });
+      }
+      return;
+    }
+
+    const severity = kind === 'warning' ? 'warning' : 'error';
+    const code = flag?.[1] || 'cgo-compile';
+
+    // A header problem belongs to the file whose #include started the chain. C
+    // files of the package compile on their own; other headers come from cgo code.
+    let included: Reported | undefined;
+    if (fileKind === 'header') {
+      included = chain[chain.length - 1];
+      fileKind = included ? classify(included.file) : isAbsolute(file!) ? 'generated' : 'other';
+    }
+
+    if (fileKind === 'own' && included) {
+      const includeLine = sourceLines?.[included.line - 1];
+      const hash = includeLine?.indexOf('#') ?? -1;
+      push(create(`${file}:${line}:${column}: ${text}`, {
+        line: included.line,
+        column: includeLine && hash >= 0 ? byteColumn(includeLine, hash) : 1
+      }, severity, code));
+    } else if (fileKind === 'own') {
+      const preambleLine = sourceLines?.[line - 1];
+      push(create(text, { line, column: preambleLine ? mapPreambleColumn(preambleLine, column) : column }, severity, code));
+    } else if (fileKind === 'generated') {
+      const name = cFunction?.match(CFUNC_WRAPPER)?.[1];
+      const position = fallback(name);
+      if (position) {
+        const message = name
+          ? formatMessage('goCgo.generatedFor', { message: text, name })
+          : formatMessage('goCgo.generatedPreamble', { message: text });
+        push(create(message, position, severity, code));
+      }
+    } else {
+      current = undefined;
+    }
+  };
+
+  for (let index = 0; index < lines.length; index++) {
+    const line = lines[index]!;
+    let match: RegExpMatchArray | null;
+
+    if ((match = line.match(INCLUDED_FROM))) {
+      includes.push({ file: match[1]!, line: parseInt(match[2]!) });
+      inCOutput = true;
+    } else if ((match = line.match(IN_FUNCTION))) {
+      cFunction = match[2];
+      inCOutput = true;
+    } else if ((match = line.match(C_DIAGNOSTIC))) {
+      compilerDiagnostic(match);
+      inCOutput = true;
+    } else if (C_SUMMARY.test(line) || LINKER_SUMMARY.test(line) || (inCOutput && SOURCE_CONTEXT.test(line))) {
+      // Context and totals of diagnostics already parsed
+    } else if ((match = line.match(MISSING_COMPILER))) {
+      const compiler = match[1] || match[2]!;
+      push(create(line.replace(/^cgo: /, ''), importC || { line: 1, column: 1 }, 'error', 'cgo-toolchain',
+        formatMessage('goCgo.compilerFix', { compiler })));
+    } else if (UNDEFINED_SYMBOLS.test(line)) {
+      // ld64 lists the symbols on indented lines: "_add", referenced from:
+      for (; index + 1 < lines.length && /^\s+\S/.test(lines[index + 1]!); index++) {
+        const symbol = lines[index + 1]!.match(/^\s+"_?(\w+)", referenced from:/)?.[1];
+        const position = symbol ? fallback(symbol) : undefined;
+        if (symbol && position) {
+          push(create(`undefined symbol: ${symbol}`, position, 'error', 'cgo-link', linkFix(symbol)));
+        }
+      }
+    } else if ((match = line.match(UNDEFINED_REFERENCE))) {
+      const symbol = match[1]!;
+      const position = fallback(symbol);
+      if (position) {
+        push(create(`undefined reference to ${symbol}`, position, 'error', 'cgo-link', linkFix(symbol)));
+      }
+    } else if ((match = line.match(LINKER))) {
+      // ld: $WORK/b001/_x002.o: in function `main': / ld: cannot find -lfoo: No such file or directory
+      const text = match[1]!.replace(/^(?!cannot find)\S+?:(?:\d+:)? /, '');
+      const position = fallback();
+      if (position && !/^(?:warning: |symbol\(s\) not found|in function )/.test(text)) {
+        push(create(text, position, 'error', 'cgo-link', linkFix()));
+      }
+    } else if (inCOutput && line.trim() && !/^(?:# |\S+?:\d+(?::\d+)?: |cgo: )/.test(line)) {
+      // Source lines and carets clang prints after a diagnostic
+    } else {
+      inCOutput = false;
+      cFunction = undefined;
+      includes = [];
+      remaining.push(line);
+    }
+  }
+
+  return { errors, remaining: remaining.join('\n') };
+}
+
+function linkFix(symbol?: string): string {
+  return symbol ? formatMessage('goCgo.symbolFix', { symbol }) : formatMessage('goCgo.libraryFix');
+}
@@ -0,0 +1,304 @@
+/**
+ * Staleness check of `go generate` output: allowed generators of a file are
+ * re-run in a copy of its module, and generated files that come out different
+ * from the ones on disk are reported at the directive that writes them
+ */
+
+import { promises as fs } from 'fs';
+import { tmpdir } from 'os';
+import { basename, dirname, join, relative, resolve, sep } from 'path';
+import type { LanguageError, RelatedInformation } from '../types/languages.js';
+import type { CommandResult } from './base-language-handler.js';
+import { findUpwards } from '../utils/workspace-roots.js';
+import { formatMessage } from '../utils/messages.js';
+
+/** `analyzer` of generate check diagnostics */
+export const GO_GENERATE_ANALYZER = 'generate';
+
+export interface GoGenerateCheckOptions {
+  /** Re-run the allowed generators of files saved inside a module (default `false`, since it executes them) */
+  enabled?: boolean;
+  /**
+   * Generators that may run, each the leading words of a directive after
+   * `-command` shorthands are expanded, such as `stringer` or
+   * `go run golang.org/x/tools/cmd/stringer`. Other directives are skipped.
+   */
+  allow?: string[];
+}
+
+export interface GoGenerateDirective {
+  line: number;
+  /** The directive as written, without trailing whitespace */
+  text: string;
+  /** Its words, with quoted strings evaluated and a shorthand expanded; variables are left as written */
+  words: string[];
+  /** The `-command` directives above it, which must run with it for their shorthands to be defined */
+  shorthands: string[];
+}
+
+/** Directories left out of the module copy */
+const SKIPPED_DIRECTORIES = new Set(['.git', 'node_modules']);
+
+/** Longest generator output quoted in a diagnostic */
+const MAX_FAILURE_OUTPUT = 2000;
+
+function isDirective(line: string): boolean {
+  return line.startsWith('//go:generate ') || line.startsWith('//go:generate\t');
+}
+
+/** The generator command of a directive as written */
+function commandText(directive: GoGenerateDirective): string {
+  return directive.text.slice('//go:generate '.length).trim();
+}
+
+function unquote(word: string): string {
+  try {
+    return JSON.parse(word) as string;
+  } catch {
+    return word.slice(1, -1);
+  }
+}
+
+/**
+ * Split a directive into words the way go generate does: at spaces and tabs,
+ * with a double-quoted Go string as one word
+ */
+function splitDirective(text: string): string[] {
+  const body = text.slice('//go:generate '.length);
+  return (body.match(/"(?:[^"\\]|\\.)*"|[^ \t]+/g) || [])
+    .map(word => (word.startsWith('"') && word.endsWith('"') && word.length > 1 ? unquote(word) : word));
+}
+
+/**
+ * The `//go:generate` directives of a Go file, in order. `-command` directives
+ * define shorthands and are not returned themselves.
+ */
+export function parseGoGenerateDirectives(source: string): GoGenerateDirective[] {
+  const directives: GoGenerateDirective[] = [];
+  const commands = new Map<string, string[]>();
+  const shorthands: string[] = [];
+
+  source.split('\n').forEach((raw, index) => {
+    if (!isDirective(raw)) {
+      return;
+    }
+    const text = raw.trimEnd();
+    const words = splitDirective(text);
+    if (words[0] === '-command') {
+      if (words.length > 2) {
+        commands.set(words[1]!, words.slice(2));
+        shorthands.push(text);
+      }
+      return;
+    }
+
+    const command = words[0] !== undefined ? commands.get(words[0]) : undefined;
+    directives.push({
+      line: index + 1,
+      text,
+      words: command ? [...command, ...words.slice(1)] : words,
+      shorthands: [...shorthands]
+    });
+  });
+
+  return directives;
+}
+
+/**
+ * Whether a directive's generator is on the allowlist: some entry's words are
+ * the first words of the directive
+ */
+export function isAllowedGenerator(directive: GoGenerateDirective, allow: string[]): boolean {
+  return allow.some(entry => {
+    const words = entry.trim().split(/\s+/).filter(Boolean);
+    return words.length > 0 &&
+      words.length <= directive.words.length &&
+      words.every((word, index) => directive.words[index] === word);
+  });
+}
+
+function quoteMeta(text: string): string {
+  return text.replace(/[\\.+*?()|[\]{}^$]/g, '\\$&');
+}
+
+/**
+ * `go generate -run` pattern selecting just this directive and the shorthands it may use
+ */
+export function goGenerateRunPattern(directive: GoGenerateDirective): string {
+  return `^(?:${[...directive.shorthands, directive.text].map(quoteMeta).join('|')})$`;
+}
+
+/** Size, mtime and inode of each file below a directory, by path relative to it */
+async function snapshot(root: string): Promise<Map<string, string>> {
+  const files = new Map<string, string>();
+
+  const walk = async (dir: string): Promise<void> => {
+    const entries = await fs.readdir(dir, { withFileTypes: true }).catch(() => []);
+    for (const entry of entries) {
+      const path = join(dir, entry.name);
+      if (entry.isDirectory()) {
+        await walk(path);
+      } else if (entry.isFile()) {
+        const stats = await fs.stat(path).catch(() => undefined);
+        if (stats) {
+          files.set(relative(root, path), `${stats.size}:${stats.mtimeMs}:${stats.ino}`);
+        }
+      }
+    }
+  };
+
+  await walk(root);
+  return files;
+}
+
+/** 1-based line of the first difference between two texts */
+function firstDifference(a: string, b: string): number {
+  const left = a.split('\n');
+  const right = b.split('\n');
+  let line = 0;
+  while (line < left.length && line < right.length && left[line] === right[line]) {
+    line++;
+  }
+  return line + 1;
+}
+
+function directiveError(
+  filePath: string,
+  directive: GoGenerateDirective,
+  message: string,
+  code: string,
+  suggestedFix?: string,
+  relatedInformation?: RelatedInformation[]
+): LanguageError {
+  return {
+    message,
+    severity: 'warning',
+    location: {
+      file: filePath,
+      line: directive.line,
+      column: 1,
+      endLine: directive.line,
+      endColumn: [...directive.text].length + 1
+    },
+    code,
+    source: 'go',
+    analyzer: GO_GENERATE_ANALYZER,
+    ...(suggestedFix && { suggestedFix }),
+    ...(relatedInformation && { relatedInformation })
+  };
+}
+
+function failureDetail(result: CommandResult): string {
+  if (result.timedOut) {
+    return formatMessage('goGenerate.timedOut');
+  }
+  const output = (result.stderr.trim() || result.stdout.trim()).slice(0, MAX_FAILURE_OUTPUT);
+  return output || formatMessage('goGenerate.exitStatus', { status: result.exitCode });
+}
+
+/** Runs `go generate` with the given arguments in a directory */
+export type GoGenerateCommandRunner = (args: string[], cwd: string) => Promise<CommandResult>;
+
+/**
+ * Generated files of a Go file on disk that no longer match its generators.
+ * Each allowed directive runs on its own, so a generator that fails is told
+ * apart from one whose output changed:
+ * - `stale-generated-file`: re-running the directive writes a file that differs
+ *   from the one in the module, or that the module lacks
+ * - `generator-failed`: the directive failed, so its output was not checked
+ *
+ * The module is copied to a temporary directory first, so the checkout is
+ * never written to.
+ */
+export class GoGenerateChecker {
+  constructor(private run: GoGenerateCommandRunner) {}
+
+  async check(
+    filePath: string,
+    source: string,
+    options: GoGenerateCheckOptions = {},
+    workspaceRoot?: string
+  ): Promise<LanguageError[]> {
+    const fullPath = resolve(filePath);
+    const directives = parseGoGenerateDirectives(source)
+      .filter(directive => isAllowedGenerator(directive, options.allow || []));
+    if (directives.length === 0) {
+      return [];
+    }
+    const moduleFile = await findUpwards(fullPath, ['go.mod'], workspaceRoot);
+    if (!moduleFile) {
+      return [];
+    }
+
+    const moduleRoot = dirname(moduleFile);
+    const sandbox = await fs.mkdtemp(join(tmpdir(), 'go-generate-'));
+    try {
+      await fs.cp(moduleRoot, sandbox, {
+        recursive: true,
+        // Relative links stay inside the copy instead of pointing back into the module
+        verbatimSymlinks: true,
+        filter: path => path === moduleRoot || !SKIPPED_DIRECTORIES.has(basename(path))
+      });
+      const packageDir = join(sandbox, relative(moduleRoot, dirname(fullPath)));
+      const fileArg = relative(moduleRoot, fullPath).split(sep).join('/');
+
+      const errors: LanguageError[] = [];
+      let before = await snapshot(sandbox);
+      for (const directive of directives) {
+        const result = await this.run(['-run', goGenerateRunPattern(directive), basename(fullPath)], packageDir);
+        // A failed run may still have written files; they are not held against the next directive
+        const after = await snapshot(sandbox);
+        if (result.exitCode !== 0 || result.timedOut) {
+          errors.push(directiveError(
+            fullPath,
+            directive,
+            formatMessage('goGenerate.failed', { command: commandText(directive), detail: failureDetail(result) }),
+            'generator-failed'
+          ));
+        } else {
+          errors.push(...await this.findStaleFiles(fullPath, fileArg, directive, moduleRoot, sandbox, before, after));
+        }
+        before = after;
+      }
+      return errors;
+    } finally {
+      await fs.rm(sandbox, { recursive: true, force: true });
+    }
+  }
+
+  private async findStaleFiles(
+    filePath: string,
+    fileArg: string,
+    directive: GoGenerateDirective,
+    moduleRoot: string,
+    sandbox: string,
+    before: Map<string, string>,
+    after: Map<string, string>
+  ): Promise<LanguageError[]> {
+    const errors: LanguageError[] = [];
+    const suggestedFix = formatMessage('goGenerate.fix', { file: fileArg });
+
+    for (const [path, state] of Array.from(after).sort(([a], [b]) => a.localeCompare(b))) {
+      if (before.get(path) === state) {
+        continue;
+      }
+      const generated = await fs.readFile(join(sandbox, path), 'utf-8');
+      const committedPath = join(moduleRoot, path);
+      const committed = await fs.readFile(committedPath, 'utf-8').catch(() => undefined);
+      const name = path.split(sep).join('/');
+
+      if (committed === undefined) {
+        errors.push(directiveError(filePath, directive,
+          formatMessage('goGenerate.missing', { file: name, command: commandText(directive) }),
+          'stale-generated-file', suggestedFix));
+      } else if (committed !== generated) {
+        errors.push(directiveError(filePath, directive,
+          formatMessage('goGenerate.outdated', { file: name, command: commandText(directive) }),
+          'stale-generated-file', suggestedFix,
+          [{ location: { file: committedPath, line: firstDifference(committed, generated), column: 1 }, message: formatMessage('goGenerate.firstDifference') }]));
+      }
+    }
+
+    return errors;
+  }
+}
@@ -2,25 +2,118 @@
  * Go language handler implementation
  */
 
-import { spawn } from 'child_process';
 import { promises as fs } from 'fs';
-import { BaseLanguageHandler } from './base-language-handler.js';
+import { devNull, tmpdir } from 'os';
+import { basename, dirname, join, resolve } from 'path';
+import {
+  BaseLanguageHandler,
+  TOOLCHAIN_SOURCE,
+  type CommandOptions,
+  type CommandResult,
+  type ToolchainProbe
+} from './base-language-handler.js';
 import type {
+  ColumnEncoding,
   DetectionOptions,
   LanguageError,
   StackFrame,
   LanguageDebugCapabilities,
   LanguageDebugConfig,
   LanguageDebugSession,
-  PerformanceAnalysis
+  PackageDetectionOptions,
+  PerformanceAnalysis,
+  RunOptions,
+  RunResult
 } from '../types/languages.js';
 import { SupportedLanguage } from '../types/languages.js';
 import { Logger } from '../utils/logger.js';
+import {
+  checkBuildConstraints,
+  hostGoBuildContext,
+  type GoBuildContext,
+  type ResolvedGoBuildContext
+} from './go-build-context.js';
+import { findUpwards } from '../utils/workspace-roots.js';
+import { DetectorTimeoutError, anySignal, currentSignal, isCancellationError } from '../utils/cancellation.js';
+import { backoffDelay, delay, resolveRetryOptions } from '../utils/retry.js';
+import type { ToolchainRetryConfig } from '../types/config.js';
+import { ToolFailedError, ToolNotFoundError, isToolNotFoundError } from '../utils/errors.js';
+import { toolchainCache } from '../utils/toolchain-cache.js';
+import { currentCommandPlan } from '../utils/command-plan.js';
+import { parseDetectorEnv } from '../utils/env.js';
+import { GoplsClient, GoplsStartError, type GoplsOptions } from './gopls-client.js';
+import { goPanicToError, parseGoPanic } from './go-panic.js';
+import { GoTestRunner, type GoTestOptions } from './go-test-runner.js';
+import { applyInferredEnd, applyRecoveredRange } from './go-range.js';
+import { goPackageSurface } from './go-surface.js';
+import { parseGoModuleErrors } from './go-module.js';
+import { goOfflineVariables, markOfflineBlocked, parseOfflineImportErrors } from './go-offline.js';
+import { parseCgoErrors } from './go-cgo.js';
+import { GO_PARSER_PROGRAM, needsParserFallback, parseGoParserOutput } from './go-parser.js';
+import { GO_IMPORTS_PROGRAM, GoImportChecker, type GoImportCheckOptions } from './go-imports.js';
+import { GoGenerateChecker, type GoGenerateCheckOptions } from './go-generate.js';
+import {
+  GO_LIST_PACKAGE_FORMAT,
+  parseGoListPatterns,
+  parseGoPackageBuildOutput,
+  parseGoPackageVetOutput
+} from './go-packages.js';
+
+/** A `./file.go:line:col:` position at the start of a line of go output */
+const GO_POSITION = /^\.\/.+?:\d+(?::\d+)?: /m;
+
+/** Helper programs built from go/parser, by the name of their binary */
+const GO_PARSER_HELPER = 'go-parser-check';
+const GO_IMPORTS_HELPER = 'go-import-check';
+
+/** Network and module proxy failures that usually clear up on a second try */
+const TRANSIENT_GO_FAILURES = [
+  /connection reset by peer/i,
+  /connection refused/i,
+  /i\/o timeout/i,
+  /TLS handshake timeout/i,
+  /temporary failure in name resolution/i,
+  /unexpected EOF/,
+  /\b(?:410 Gone|429 Too Many Requests|502 Bad Gateway|503 Service Unavailable|504 Gateway Timeout)\b/
+];
+
+/**
+ * Whether a failed go command is worth retrying: it hit a network or proxy error
+ * and reported nothing about the code itself
+ */
+export function isTransientGoFailure(result: CommandResult): boolean {
+  return result.exitCode !== 0 &&
+    !result.timedOut &&
+    !GO_POSITION.test(result.stderr) &&
+    TRANSIENT_GO_FAILURES.some(pattern => pattern.test(result.stderr));
+}
+
+/**
+ * `go vet` settings, read from the handler's `vet` option
+ */
+export interface GoVetOptions {
+  enabled?: boolean;
+  analyzers?: string[];
+  disabledAnalyzers?: string[];
+  vettool?: string;
+}
 
 export class GoHandler extends BaseLanguageHandler {
   private goPath: string | undefined;
   private golintPath: string | undefined;
   private govetPath: string | undefined;
+  private hostContext: ResolvedGoBuildContext = hostGoBuildContext();
+  /** Running gopls servers by workspace folder */
+  private goplsClients = new Map<string, GoplsClient>();
+  /** Set once gopls failed to start, so later files go straight to go build */
+  private goplsUnavailable = false;
+  private testRunner: GoTestRunner | undefined;
+  private importChecker: GoImportChecker | undefined;
+  private generateChecker: GoGenerateChecker | undefined;
+  /** In-flight go commands by package directory and arguments */
+  private packageRuns = new Map<string, { startedAt: number; result: Promise<CommandResult> }>();
+  /** Paths of the go/parser helper binaries by name, each built on first use */
+  private helpers = new Map<string, Promise<string>>();
 
   constructor(options: Record<string, unknown> = {}, logger?: Logger) {
     super(SupportedLanguage.GO, options, logger);
@@ -30,6 +123,11 @@ export class GoHandler extends BaseLanguageHandler {
     return ['.go'];
   }
 
+  /** The go command counts UTF-8 bytes, a leading BOM included; gopls diagnostics carry their own unit */
+  getColumnEncoding(): ColumnEncoding {
+    return { unit: 'byte', includesBom: true };
+  }
+
   getConfigFiles(): string[] {
     return [
       'go.mod',
@@ -40,6 +138,14 @@ export class GoHandler extends BaseLanguageHandler {
     ];
   }
 
+  getFileNames(): string[] {
+    return ['go.mod'];
+  }
+
+  getPackageSurface(source: string): string {
+    return goPackageSurface(source);
+  }
+
   protected async doInitialize(): Promise<void> {
     // Find Go compiler
     this.goPath = await this.findExecutable('go');
@@ -51,19 +157,51 @@ export class GoHandler extends BaseLanguageHandler {
     this.golintPath = await this.findExecutable('golint');
     this.govetPath = await this.findExecutable('go');
 
+    // Resolve host build defaults from the toolchain itself
+    this.hostContext = await this.detectHostContext();
+
+    // Test binaries run here, so they are built for the host whatever the configured target
+    this.testRunner = new GoTestRunner(
+      ([command, ...args], cwd) => this.runGoCommand([command!, ...this.getBuildFlags(), ...args], { cwd })
+    );
+    this.importChecker = new GoImportChecker(
+      async moduleRoot => this.runCommand(await this.getHelper(GO_IMPORTS_HELPER, GO_IMPORTS_PROGRAM), [moduleRoot], { cwd: moduleRoot })
+    );
+    // Generators run here too, so like tests they see the host rather than the configured target
+    this.generateChecker = new GoGenerateChecker(
+      (args, cwd) => this.runGoCommand(['generate', ...this.getBuildFlags(), ...args], { cwd })
+    );
+
     this.logger.info('Go handler initialized', {
       goPath: this.goPath,
       golintPath: this.golintPath,
-      govetPath: this.govetPath
+      govetPath: this.govetPath,
+      buildContext: this.getBuildContext()
     });
   }
 
   protected async doDispose(): Promise<void> {
+    const clients = Array.from(this.goplsClients.values());
+    this.goplsClients.clear();
+    this.goplsUnavailable = false;
+    await Promise.allSettled(clients.map(client => client.shutdown()));
+
+    const helpers = Array.from(this.helpers.values());
+    this.helpers.clear();
+    await Promise.allSettled(helpers.map(helper => helper.then(binary => fs.rm(dirname(binary), { recursive: true, force: true }))));
+
+    this.testRunner = undefined;
+    this.importChecker = undefined;
+    this.generateChecker = undefined;
     this.goPath = undefined;
     this.golintPath = undefined;
     this.govetPath = undefined;
   }
 
+  protected getToolchainProbe(): ToolchainProbe {
+    return { command: this.goPath || 'go', args: ['version'] };
+  }
+
   protected async checkAvailability(): Promise<boolean> {
     try {
       const result = await this.runCommand(this.goPath!, ['version']);
@@ -75,51 +213,682 @@ export class GoHandler extends BaseLanguageHandler {
 
   async detectErrors(source: string, options?: DetectionOptions): Promise<LanguageError[]> {
     const errors: LanguageError[] = [];
+    const filePath = options?.filePath || 'temp.go';
+
+    if (options?.filePath && basename(options.filePath) === 'go.mod') {
+      return this.detectModuleErrors(source, options.filePath);
+    }
+
+    // Files excluded by build constraints would only produce spurious type errors
+    const constraints = checkBuildConstraints(source, filePath, this.getBuildContext());
+    if (!constraints.satisfied) {
+      return [this.createError(
+        `File excluded by build constraints: ${constraints.reason}`,
+        filePath,
+        1,
+        1,
+        'info',
+        'build-constraints'
+      )];
+    }
+
+    if (options?.filePath && this.getGoplsOptions().enabled && !this.goplsUnavailable) {
+      const goplsErrors = await this.detectWithGopls(source, options.filePath, options.workspaceRoot, options.signal);
+      if (goplsErrors) {
+        return [
+          ...goplsErrors,
+          ...await this.detectTestFailures(source, options.filePath, options.workspaceRoot),
+          ...await this.detectImportIssues(source, options.filePath, options.workspaceRoot),
+          ...await this.detectStaleGeneratedFiles(source, options.filePath, options.workspaceRoot)
+        ];
+      }
+    }
+
+    // Files of a module on disk are checked in place so imports resolve against its go.mod
+    const packageDir = options?.filePath
+      ? await this.findModulePackage(options.filePath, source, options.workspaceRoot)
+      : undefined;
 
     // Syntax validation using Go compiler
-    const syntaxErrors = await this.validateSyntax(source);
+    const syntaxErrors = await this.validateSyntax(source, filePath, packageDir);
     errors.push(...syntaxErrors);
 
     // Go vet analysis
-    if (options?.enableLinting !== false) {
-      const vetErrors = await this.runGoVet(source, options?.filePath || 'temp.go');
+    if (options?.enableLinting !== false && this.getVetOptions().enabled !== false) {
+      const vetErrors = await this.runGoVet(source, filePath, packageDir);
       errors.push(...vetErrors);
 
       // Golint if available
       if (this.golintPath) {
-        const lintErrors = await this.runGolint(source, options?.filePath || 'temp.go');
+        const lintErrors = await this.runGolint(source, filePath);
         errors.push(...lintErrors);
       }
     }
 
+    // The compiler and vet report where a problem starts; the end comes from the source
+    for (const error of errors) {
+      if (error.source !== TOOLCHAIN_SOURCE) {
+        applyInferredEnd(error, source);
+      }
+    }
+
+    if (options?.filePath) {
+      errors.push(...await this.detectTestFailures(source, options.filePath, options.workspaceRoot));
+      errors.push(...await this.detectImportIssues(source, options.filePath, options.workspaceRoot));
+      errors.push(...await this.detectStaleGeneratedFiles(source, options.filePath, options.workspaceRoot));
+    }
+
     return errors;
   }
 
-  protected async validateSyntax(source: string): Promise<LanguageError[]> {
+  /** Whether tests run comes only from the server's options: a repository must not be able to run its own code */
+  private getTestOptions(): GoTestOptions {
+    const tests = (this.options['tests'] || {}) as GoTestOptions;
+    const server = (this.serverOptions['tests'] || {}) as GoTestOptions;
+    return { ...tests, enabled: server.enabled === true };
+  }
+
+  /**
+   * Failing tests of the file's module that point into the file. Opt-in, since it
+   * runs the tests; unsaved buffers are skipped as the tests would not see them.
+   */
+  private async detectTestFailures(source: string, filePath: string, workspaceRoot?: string): Promise<LanguageError[]> {
+    const tests = this.getTestOptions();
+    if (!tests.enabled || !this.testRunner || !await this.isUnmodifiedOnDisk(filePath, source)) {
+      return [];
+    }
+
     try {
-      const tempDir = `/tmp/go-syntax-check-${Date.now()}`;
-      const tempFile = `${tempDir}/main.go`;
-      
-      await fs.mkdir(tempDir, { recursive: true });
-      await fs.writeFile(tempFile, source);
-      
+      return await this.testRunner.check(filePath, tests, workspaceRoot);
+    } catch (error) {
+      if (isCancellationError(error)) {
+        throw error;
+      }
+      this.logger.warn('go test execution failed', error);
+      return [];
+    }
+  }
+
+  private getImportCheckOptions(): GoImportCheckOptions {
+    return (this.options['imports'] || {}) as GoImportCheckOptions;
+  }
+
+  /**
+   * Advisory diagnostics for the file's imports, judged against every file of
+   * its module. Opt-in; unsaved buffers are skipped as the module walk reads
+   * the files on disk.
+   */
+  private async detectImportIssues(source: string, filePath: string, workspaceRoot?: string): Promise<LanguageError[]> {
+    const imports = this.getImportCheckOptions();
+    if (!imports.enabled || !this.importChecker || !await this.isUnmodifiedOnDisk(filePath, source)) {
+      return [];
+    }
+
+    try {
+      return await this.importChecker.check(filePath, imports, workspaceRoot);
+    } catch (error) {
+      if (isCancellationError(error)) {
+        throw error;
+      }
+      this.logger.warn('Go import check failed', error);
+      return [];
+    }
+  }
+
+  /** Only from the server's options: a repository must not be able to run its own generators */
+  private getGenerateCheckOptions(): GoGenerateCheckOptions {
+    return (this.serverOptions['generate'] || {}) as GoGenerateCheckOptions;
+  }
+
+  /**
+   * Generated files of the file's directives that re-running their generators
+   * would change. Opt-in and limited to allowlisted generators, since it runs
+   * them; unsaved buffers are skipped as generators read the files on disk.
+   */
+  private async detectStaleGeneratedFiles(source: string, filePath: string, workspaceRoot?: string): Promise<LanguageError[]> {
+    const generate = this.getGenerateCheckOptions();
+    if (!generate.enabled || !this.generateChecker || !await this.isUnmodifiedOnDisk(filePath, source)) {
+      return [];
+    }
+
+    try {
+      return await this.generateChecker.check(filePath, source, generate, workspaceRoot);
+    } catch (error) {
+      if (isCancellationError(error)) {
+        throw error;
+      }
+      this.logger.warn('go generate check failed', error);
+      return [];
+    }
+  }
+
+  /**
+   * Whether gopls runs, and which binary with which flags, only comes from the
+   * server's options, since gopls runs the repository's go toolchain
+   */
+  private getGoplsOptions(): GoplsOptions {
+    const { enabled, path, args } = (this.serverOptions['gopls'] || {}) as GoplsOptions;
+    const { diagnosticsTimeoutMs, settleMs } = (this.options['gopls'] || {}) as GoplsOptions;
+    const detectorEnv = parseDetectorEnv(this.options['env']);
+    const env = this.isOffline()
+      ? { ...detectorEnv, ...goOfflineVariables(detectorEnv['GOFLAGS'] ?? process.env['GOFLAGS']) }
+      : detectorEnv;
+    return {
+      enabled: enabled === true,
+      ...(path && { path }),
+      ...(args && { args }),
+      ...(diagnosticsTimeoutMs !== undefined && { diagnosticsTimeoutMs }),
+      ...(settleMs !== undefined && { settleMs }),
+      ...(Object.keys(env).length > 0 && { env })
+    };
+  }
+
+  /**
+   * Collect diagnostics from a gopls server for the file's module, starting one on
+   * first use. gopls type-checks and runs its analyzers, so this replaces both the
+   * build and `go vet`. Returns undefined when gopls cannot be started, so the caller
+   * falls back to the command line tools.
+   */
+  private async detectWithGopls(
+    source: string,
+    filePath: string,
+    workspaceRoot?: string,
+    signal?: AbortSignal
+  ): Promise<LanguageError[] | undefined> {
+    const fullPath = resolve(filePath);
+    const moduleFile = await findUpwards(fullPath, ['go.mod', 'go.work'], workspaceRoot);
+    const rootDir = moduleFile ? dirname(moduleFile) : workspaceRoot || dirname(fullPath);
+
+    // Offline analysis gets a server of its own, started with the offline environment
+    const key = JSON.stringify([rootDir, this.isOffline()]);
+    const plan = currentCommandPlan();
+    if (plan) {
+      // The diagnostics come from the server, so there is nothing else to plan
+      if (!this.goplsClients.has(key)) {
+        const gopls = this.getGoplsOptions();
+        plan.record(this.language, gopls.path || 'gopls', [...(gopls.args || []), 'serve'], {
+          cwd: rootDir,
+          ...(gopls.env && { env: { ...process.env, ...gopls.env } }),
+          persistent: true
+        });
+      }
+      return [];
+    }
+    let client = this.goplsClients.get(key);
+    if (!client) {
+      client = new GoplsClient(rootDir, this.getGoplsOptions(), this.logger);
+      this.goplsClients.set(key, client);
+    }
+
+    try {
+      return await client.diagnose(fullPath, source, signal ?? currentSignal());
+    } catch (error) {
+      if (!isToolNotFoundError(error) && !(error instanceof GoplsStartError)) {
+        throw error;
+      }
+      this.goplsClients.delete(key);
+      this.goplsUnavailable = true;
+      this.logger.warn(`gopls unavailable, falling back to go build: ${error instanceof Error ? error.message : String(error)}`, { rootDir });
+      return undefined;
+    }
+  }
+
+  /**
+   * Build the package holding `target` (a directory or a file in it), or its test
+   * binary, and execute it. Build errors are returned without running anything; a
+   * run that panics gets a diagnostic at the top frame inside the workspace.
+   */
+  async runAndDetect(target: string, options: RunOptions): Promise<RunResult> {
+    if (!this.goPath) {
+      throw new ToolNotFoundError('go');
+    }
+
+    const fullPath = resolve(target);
+    const packageDir = (await fs.stat(fullPath)).isDirectory() ? fullPath : dirname(fullPath);
+    const workspaceRoot = options.workspaceRoot ? resolve(options.workspaceRoot) : packageDir;
+    const tempDir = await fs.mkdtemp(join(tmpdir(), 'go-run-'));
+    const startedAt = Date.now();
+
+    try {
+      const binary = join(tempDir, options.mode === 'test' ? 'pkg.test' : 'prog');
+      const buildArgs = options.mode === 'test'
+        ? ['test', '-c', ...this.getBuildFlags(), '-o', binary, '.']
+        : ['build', ...this.getBuildFlags(), '-o', binary, '.'];
+      // The binary runs here, so it is built for the host whatever the configured target
+      const build = await this.runGoCommand(buildArgs, { cwd: packageDir, ...(options.signal && { signal: options.signal }) });
+
+      if (build.exitCode !== 0) {
+        return {
+          ran: false,
+          exitCode: build.exitCode,
+          timedOut: build.timedOut === true,
+          truncated: false,
+          durationMs: Date.now() - startedAt,
+          stdout: build.stdout,
+          stderr: build.stderr,
+          errors: this.parseBuildFailure(build, packageDir)
+        };
+      }
+
+      // `go test -c` succeeds without writing a binary when the package has no tests
+      if (!await fs.access(binary).then(() => true, () => false)) {
+        return {
+          ran: false,
+          exitCode: build.exitCode,
+          timedOut: false,
+          truncated: false,
+          durationMs: Date.now() - startedAt,
+          stdout: build.stdout,
+          stderr: build.stderr,
+          errors: [this.createError('no test files to run', packageDir, 1, 1, 'info', 'no-test-files')]
+        };
+      }
+
+      const deadline = new AbortController();
+      const timer = setTimeout(() => deadline.abort(new DetectorTimeoutError('go run', options.timeoutMs)), options.timeoutMs);
+      let run: CommandResult;
+      try {
+        run = await this.runCommand(binary, options.args || [], {
+          cwd: packageDir,
+          signal: anySignal([options.signal, deadline.signal])!,
+          maxOutputBytes: options.maxOutputBytes
+        });
+      } finally {
+        clearTimeout(timer);
+      }
+
+      const errors: LanguageError[] = [];
+      const panic = parseGoPanic(run.stderr);
+      if (panic) {
+        errors.push(goPanicToError(panic, fullPath, workspaceRoot, await this.findGoroot()));
+      }
+      if (run.timedOut) {
+        errors.push(this.createError(`program timed out after ${options.timeoutMs}ms`, fullPath, 1, 1, 'error', 'timeout'));
+      } else if (run.truncated) {
+        errors.push(this.createError(
+          `program killed after writing more than ${options.maxOutputBytes} bytes of output`,
+          fullPath,
+          1,
+          1,
+          'error',
+          'output-limit'
+        ));
+      }
+
+      return {
+        ran: true,
+        exitCode: run.exitCode,
+        timedOut: run.timedOut === true,
+        truncated: run.truncated === true,
+        durationMs: Date.now() - startedAt,
+        stdout: run.stdout,
+        stderr: run.stderr,
+        errors
+      };
+    } finally {
+      await fs.rm(tempDir, { recursive: true, force: true }).catch(() => {});
+    }
+  }
+
+  /**
+   * Diagnostics for every file named in a failed package build
+   */
+  private parseBuildFailure(result: CommandResult, packageDir: string): LanguageError[] {
+    const files = new Set(Array.from(result.stderr.matchAll(/^\.\/(.+?):\d+:\d+: /gm), match => match[1]!));
+    const errors = Array.from(files).flatMap(file =>
+      this.parseGoErrors(result.stderr, join(packageDir, file), file, packageDir)
+    );
+    return errors.length > 0 ? errors : [this.createToolchainError('go build', result, packageDir)];
+  }
+
+  /**
+   * Build and vet every package matched by the patterns, which are passed as they
+   * are to `go build` and `go vet` in `options.dir`. Patterns are checked with
+   * `go list` first, so one that matches nothing is an error rather than an
+   * empty result.
+   */
+  async detectPackageErrors(patterns: string[], options: PackageDetectionOptions): Promise<LanguageError[]> {
+    if (!this.goPath) {
+      throw new ToolNotFoundError('go');
+    }
+
+    const flag = patterns.find(pattern => pattern.startsWith('-'));
+    if (patterns.length === 0 || flag !== undefined) {
+      throw new Error(flag !== undefined
+        ? `Invalid Go package pattern ${flag}: patterns cannot start with -`
+        : 'At least one Go package pattern is required');
+    }
+
+    const command = { cwd: options.dir, env: this.getBuildEnv(), ...(options.signal && { signal: options.signal }) };
+    const list = await this.runGoCommand(['list', '-e', ...this.getBuildFlags(), '-f', GO_LIST_PACKAGE_FORMAT, ...patterns], command);
+    const { packages, problems } = parseGoListPatterns(list.stdout, list.stderr);
+    if (problems.length > 0 || packages.length === 0) {
+      throw new Error(problems.length > 0
+        ? `Invalid Go package pattern: ${problems.join('; ')}`
+        : `Invalid Go package pattern: ${list.stderr.trim() || `${patterns.join(' ')} matched no packages`}`);
+    }
+
+    const build = await this.runGoCommand(['build', ...this.getBuildFlags(), '-o', devNull, ...patterns], command);
+    const errors = parseGoPackageBuildOutput(build.stderr, options.dir).map(error => markOfflineBlocked(error));
+    if (this.isUnparsedFailure(build, errors)) {
+      errors.push(this.createToolchainError('go build', build, options.dir));
+    }
+
+    if (options.enableLinting !== false && this.getVetOptions().enabled !== false) {
+      const vet = await this.runGoCommand(['vet', ...this.getBuildFlags(), ...this.getVetFlags(), ...patterns], command);
+      errors.push(...parseGoPackageVetOutput(`${vet.stdout}\n${vet.stderr}`, options.dir));
+    }
+
+    return errors;
+  }
+
+  /**
+   * Compile every package of the module at `dir`, discarding the results, so the
+   * build cache holds them when files are analyzed. Packages that fail to build
+   * are left for the analyses to report.
+   */
+  async warmBuildCache(dir: string, options: { signal?: AbortSignal } = {}): Promise<boolean> {
+    if (!this.goPath) {
+      throw new ToolNotFoundError('go');
+    }
+
+    const result = await this.runGoCommand(['build', ...this.getBuildFlags(), '-o', devNull, './...'], {
+      cwd: dir,
+      env: this.getBuildEnv(),
+      ...(options.signal && { signal: options.signal })
+    });
+    if (result.exitCode !== 0) {
+      this.logger.debug(`go build ./... failed in ${dir} while warming the build cache`, { stderr: result.stderr.trim() });
+    }
+    return result.exitCode === 0;
+  }
+
+  /**
+   * GOROOT of the toolchain, used to tell standard library frames from user code
+   */
+  private async findGoroot(): Promise<string | undefined> {
+    try {
+      return (await this.goEnv(['GOROOT'])).trim() || undefined;
+    } catch {
+      return undefined;
+    }
+  }
+
+  /**
+   * `go env` output for some variables, cached with the other toolchain probes
+   */
+  private goEnv(names: string[]): Promise<string> {
+    return toolchainCache.get(`${this.goPath} env ${names.join(' ')}`, async () => {
+      const result = await this.runCommand(this.goPath!, ['env', ...names]);
+      if (result.exitCode !== 0) {
+        throw new ToolFailedError('go env', result.exitCode, result.stderr);
+      }
+      return result.stdout;
+    });
+  }
+
+  /**
+   * Get the effective build context, falling back to host defaults
+   */
+  getBuildContext(): ResolvedGoBuildContext {
+    const configured = (this.options['buildContext'] || {}) as GoBuildContext;
+
+    return {
+      tags: configured.tags || this.hostContext.tags,
+      goos: configured.goos || this.hostContext.goos,
+      goarch: configured.goarch || this.hostContext.goarch,
+      cgoEnabled: configured.cgoEnabled ?? this.hostContext.cgoEnabled
+    };
+  }
+
+  private getBuildEnv(): NodeJS.ProcessEnv {
+    const context = this.getBuildContext();
+
+    return {
+      ...process.env,
+      GOOS: context.goos,
+      GOARCH: context.goarch,
+      CGO_ENABLED: context.cgoEnabled ? '1' : '0'
+    };
+  }
+
+  /**
+   * Whether the `offline` option is set: go commands may not download modules or
+   * toolchains, nor update go.mod and go.sum
+   */
+  private isOffline(): boolean {
+    return this.options['offline'] === true;
+  }
+
+  /**
+   * In offline mode every tool runs with downloads and go.mod updates disabled,
+   * whatever the detector's `env` option sets
+   */
+  protected commandEnv(env: NodeJS.ProcessEnv | undefined): NodeJS.ProcessEnv | undefined {
+    if (!this.isOffline()) {
+      return env;
+    }
+    const base = env ?? process.env;
+    return { ...base, ...goOfflineVariables(base['GOFLAGS']) };
+  }
+
+  private getBuildFlags(): string[] {
+    const { tags } = this.getBuildContext();
+    return tags.length > 0 ? ['-tags', tags.join(',')] : [];
+  }
+
+  private getVetOptions(): GoVetOptions {
+    return (this.options['vet'] || {}) as GoVetOptions;
+  }
+
+  /**
+   * Build vet flags: listing analyzers runs only those, disabling turns individual ones off.
+   * The vettool only comes from the server's options, since vet executes it.
+   */
+  private getVetFlags(): string[] {
+    const vet = this.getVetOptions();
+    const { vettool } = (this.serverOptions['vet'] || {}) as GoVetOptions;
+    const flags: string[] = ['-json'];
+
+    if (vettool) {
+      flags.push(`-vettool=${vettool}`);
+    }
+    for (const analyzer of vet.analyzers || []) {
+      flags.push(`-${analyzer}`);
+    }
+    for (const analyzer of vet.disabledAnalyzers || []) {
+      flags.push(`-${analyzer}=false`);
+    }
+
+    return flags;
+  }
+
+  private async detectHostContext(): Promise<ResolvedGoBuildContext> {
+    const fallback = hostGoBuildContext();
+
+    try {
+      const stdout = await this.goEnv(['GOOS', 'GOARCH', 'CGO_ENABLED']);
+      const [goos, goarch, cgoEnabled] = stdout.split('\n').map(value => value.trim());
+
+      return {
+        tags: [],
+        goos: goos || fallback.goos,
+        goarch: goarch || fallback.goarch,
+        cgoEnabled: cgoEnabled ? cgoEnabled === '1' : fallback.cgoEnabled
+      };
+    } catch {
+      return fallback;
+    }
+  }
+
+  /**
+   * Check a go.mod file: `go list -m all` resolves every requirement and
+   * `go mod verify` checks downloaded modules against go.sum. An edited go.mod is
+   * checked in a temp directory next to a copy of its go.sum.
+   */
+  private async detectModuleErrors(source: string, filePath: string): Promise<LanguageError[]> {
+    const onDisk = await this.isUnmodifiedOnDisk(filePath, source);
+    const moduleDir = onDisk ? dirname(resolve(filePath)) : await fs.mkdtemp(join(tmpdir(), 'go-mod-check-'));
+
+    try {
+      if (!onDisk) {
+        await fs.writeFile(join(moduleDir, 'go.mod'), source);
+        await fs.copyFile(join(dirname(resolve(filePath)), 'go.sum'), join(moduleDir, 'go.sum')).catch(() => {});
+      }
+
+      const env = this.getBuildEnv();
+      const runs = [
+        { tool: 'go list -m', result: await this.runGoCommand(['list', '-m', 'all'], { cwd: moduleDir, env }) },
+        { tool: 'go mod verify', result: await this.runGoCommand(['mod', 'verify'], { cwd: moduleDir, env }) }
+      ];
+
+      const errors = parseGoModuleErrors(runs.map(run => `${run.result.stderr}\n${run.result.stdout}`).join('\n'), this.normalizePath(filePath), source);
+      const failed = runs.find(run => this.isUnparsedFailure(run.result, errors));
+      return failed ? [this.createToolchainError(failed.tool, failed.result, filePath)] : errors;
+    } catch (error) {
+      return [this.createError(
+        `Module check failed: ${error instanceof Error ? error.message : 'Unknown error'}`,
+        filePath,
+        1,
+        1,
+        'error'
+      )];
+    } finally {
+      if (!onDisk) {
+        await fs.rm(moduleDir, { recursive: true, force: true }).catch(() => {});
+      }
+    }
+  }
+
+  /**
+   * Module errors of a package build, reported on the module's go.mod
+   */
+  private async parseBuildModuleErrors(stderr: string, filePath: string): Promise<LanguageError[]> {
+    const goModPath = await findUpwards(filePath, ['go.mod']);
+    if (!goModPath) {
+      return [];
+    }
+    const content = await fs.readFile(goModPath, 'utf-8').catch(() => undefined);
+    return content === undefined ? [] : parseGoModuleErrors(stderr, this.normalizePath(goModPath), content);
+  }
+
+  /**
+   * Copy source into a throwaway module and run a go command there.
+   * The directory is removed even when the command fails or is canceled.
+   */
+  private async runInTempModule(prefix: string, source: string, args: string[]): Promise<CommandResult> {
+    const tempDir = await fs.mkdtemp(join(tmpdir(), prefix));
+
+    try {
+      await fs.writeFile(join(tempDir, 'main.go'), source);
+
       // Initialize go module
       await this.runCommand(this.goPath!, ['mod', 'init', 'temp'], { cwd: tempDir });
-      
-      const result = await this.runCommand(this.goPath!, ['build', '.'], { cwd: tempDir });
 
-      // Cleanup
+      return await this.runGoCommand(args, {
+        cwd: tempDir,
+        env: this.getBuildEnv()
+      });
+    } finally {
       await fs.rm(tempDir, { recursive: true, force: true }).catch(() => {});
+    }
+  }
+
+  /**
+   * Find the package directory of a file inside a Go module on disk, no higher than
+   * the workspace root. Edited buffers and test files are checked in a temp module.
+   */
+  private async findModulePackage(filePath: string, source: string, workspaceRoot?: string): Promise<string | undefined> {
+    // `go build` skips _test.go files, so they keep the single-file check
+    if (filePath.endsWith('_test.go') || !await this.isUnmodifiedOnDisk(filePath, source)) {
+      return undefined;
+    }
+
+    const moduleFile = await findUpwards(filePath, ['go.mod'], workspaceRoot);
+    return moduleFile ? dirname(resolve(filePath)) : undefined;
+  }
+
+  /**
+   * Run a go command in a package directory of a real module. Files of the same
+   * package asking for the same command while it runs share its result, so a
+   * package analyzed file by file in parallel is built once. A file saved after
+   * the shared run started gets a run of its own.
+   */
+  private async runInPackage(packageDir: string, args: string[], filePath?: string): Promise<CommandResult> {
+    const { goos, goarch, cgoEnabled } = this.getBuildContext();
+    const key = JSON.stringify([packageDir, args, goos, goarch, cgoEnabled, this.isOffline()]);
+    // A planned run neither reuses a real one nor is reused
+    if (currentCommandPlan()) {
+      return this.runGoCommand(args, { cwd: packageDir, env: this.getBuildEnv() });
+    }
+    const shared = this.packageRuns.get(key);
+    if (shared && filePath) {
+      const stats = await fs.stat(filePath).catch(() => undefined);
+      if (stats && stats.mtimeMs < shared.startedAt) {
+        return shared.result;
+      }
+    }
+
+    const run = {
+      startedAt: Date.now(),
+      result: this.runGoCommand(args, { cwd: packageDir, env: this.getBuildEnv() })
+    };
+    this.packageRuns.set(key, run);
+    run.result
+      .catch(() => undefined)
+      .then(() => {
+        if (this.packageRuns.get(key) === run) {
+          this.packageRuns.delete(key);
+        }
+      });
+    return run.result;
+  }
+
+  /**
+   * Run a go command, retrying with exponential backoff while it fails on the
+   * network (typically a module download). Compile errors are never retried.
+   */
+  private async runGoCommand(args: string[], options: CommandOptions): Promise<CommandResult> {
+    const retry = resolveRetryOptions(this.options['retry'] as ToolchainRetryConfig | undefined);
+    let result = await this.runCommand(this.goPath!, args, options);
+
+    for (let attempt = 1; attempt <= retry.retries && isTransientGoFailure(result); attempt++) {
+      const waitMs = backoffDelay(attempt, retry);
+      this.logger.warn(`go ${args[0]} failed with a transient error, retrying in ${waitMs}ms (attempt ${attempt} of ${retry.retries})`, {
+        cwd: options.cwd,
+        stderr: result.stderr.trim()
+      });
+      await delay(waitMs, options.signal ?? currentSignal());
+      result = await this.runCommand(this.goPath!, args, options);
+    }
+
+    return result;
+  }
+
+  protected async validateSyntax(source: string, filePath = 'temp.go', packageDir?: string): Promise<LanguageError[]> {
+    try {
+      const result = packageDir
+        ? await this.runInPackage(packageDir, ['build', ...this.getBuildFlags(), '-o', devNull, '.'], filePath)
+        : await this.runInTempModule('go-syntax-check-', source, ['build', ...this.getBuildFlags(), '.']);
 
       if (result.exitCode === 0) {
         return [];
       }
 
-      return this.parseGoErrors(result.stderr, 'temp.go');
+      const errors = [
+        ...this.parseGoErrors(result.stderr, filePath, packageDir ? basename(filePath) : undefined, packageDir, source),
+        ...parseOfflineImportErrors(result.stderr, packageDir ? basename(filePath) : 'main.go', this.normalizePath(filePath), source),
+        ...packageDir ? await this.parseBuildModuleErrors(result.stderr, filePath) : []
+      ];
+      // Errors in sibling files are not a toolchain failure, just not ours to report
+      if (this.isUnparsedFailure(result, errors) && !GO_POSITION.test(result.stderr)) {
+        return this.withParserFallback([this.createToolchainError('go build', result, filePath)], source, filePath, packageDir);
+      }
+      return this.withParserFallback(errors, source, filePath, packageDir);
     } catch (error) {
       return [this.createError(
         `Syntax validation failed: ${error instanceof Error ? error.message : 'Unknown error'}`,
-        'temp.go',
+        filePath,
         1,
         1,
         'error'
@@ -127,23 +896,121 @@ export class GoHandler extends BaseLanguageHandler {
     }
   }
 
-  private async runGoVet(source: string, filePath: string): Promise<LanguageError[]> {
+  /**
+   * With the `parserFallback` option, replace the syntax errors of a build that
+   * stopped early with what go/parser and go/types find in the file: every
+   * syntax error, or every type error once the file parses. The build's other
+   * diagnostics are kept, and so are its own results if the fallback finds nothing.
+   */
+  private async withParserFallback(
+    errors: LanguageError[],
+    source: string,
+    filePath: string,
+    packageDir?: string
+  ): Promise<LanguageError[]> {
+    if (!this.options['parserFallback'] || !needsParserFallback(errors)) {
+      return errors;
+    }
+
+    const parsed = await this.runParserFallback(source, filePath, packageDir);
+    if (parsed.length === 0) {
+      return errors;
+    }
+    return [
+      ...errors.filter(error => error.code !== 'toolchain' && !/^syntax error\b/.test(error.message)),
+      ...parsed
+    ];
+  }
+
+  private async runParserFallback(source: string, filePath: string, packageDir?: string): Promise<LanguageError[]> {
     try {
-      const tempDir = `/tmp/go-vet-check-${Date.now()}`;
-      const tempFile = `${tempDir}/main.go`;
-      
-      await fs.mkdir(tempDir, { recursive: true });
-      await fs.writeFile(tempFile, source);
-      
-      // Initialize go module
-      await this.runCommand(this.goPath!, ['mod', 'init', 'temp'], { cwd: tempDir });
-      
-      const result = await this.runCommand(this.goPath!, ['vet', '.'], { cwd: tempDir });
+      const helper = await this.getHelper(GO_PARSER_HELPER, GO_PARSER_PROGRAM);
+      const { tags } = this.getBuildContext();
+      const args = tags.length > 0 ? ['-tags', tags.join(',')] : [];
 
-      // Cleanup
-      await fs.rm(tempDir, { recursive: true, force: true }).catch(() => {});
+      if (packageDir) {
+        const result = await this.runCommand(helper, [...args, '.', basename(filePath)], { cwd: packageDir, env: this.getBuildEnv() });
+        return parseGoParserOutput(result.stdout, this.normalizePath(filePath));
+      }
 
-      return this.parseGoVetOutput(result.stderr, filePath);
+      const tempDir = await fs.mkdtemp(join(tmpdir(), 'go-parser-check-'));
+      try {
+        await fs.writeFile(join(tempDir, 'main.go'), source);
+        const result = await this.runCommand(helper, [...args, '.', 'main.go'], { cwd: tempDir, env: this.getBuildEnv() });
+        return parseGoParserOutput(result.stdout, this.normalizePath(filePath));
+      } finally {
+        await fs.rm(tempDir, { recursive: true, force: true }).catch(() => {});
+      }
+    } catch (error) {
+      if (isCancellationError(error)) {
+        throw error;
+      }
+      this.logger.debug('Go parser fallback failed', error);
+      return [];
+    }
+  }
+
+  /**
+   * Path of a helper binary, built on first use and kept until dispose. A failed
+   * build is tried again next time, and a build made for a plan, which never
+   * happened, is not kept.
+   */
+  private getHelper(name: string, program: string): Promise<string> {
+    if (currentCommandPlan()) {
+      return this.buildHelper(name, program);
+    }
+    let helper = this.helpers.get(name);
+    if (!helper) {
+      const built = this.buildHelper(name, program);
+      built.catch(() => {
+        if (this.helpers.get(name) === built) {
+          this.helpers.delete(name);
+        }
+      });
+      this.helpers.set(name, built);
+      helper = built;
+    }
+    return helper;
+  }
+
+  /**
+   * Build a helper program into a temp directory that lives until dispose
+   */
+  private async buildHelper(name: string, program: string): Promise<string> {
+    const dir = await fs.mkdtemp(join(tmpdir(), `${name}-`));
+    try {
+      await fs.writeFile(join(dir, 'main.go'), program);
+      await fs.writeFile(join(dir, 'go.mod'), `module ${name.replace(/-/g, '')}\n\ngo 1.18\n`);
+      const binary = join(dir, process.platform === 'win32' ? `${name}.exe` : name);
+      // Built for the host, since it runs here whatever the configured target; the
+      // workspace's GOFLAGS and go.work have no business in this module
+      const result = await this.runCommand(this.goPath!, ['build', '-o', binary, '.'], {
+        cwd: dir,
+        env: { ...process.env, GOFLAGS: '', GOWORK: 'off' }
+      });
+      if (result.exitCode !== 0) {
+        throw new ToolFailedError('go build', result.exitCode, result.stderr, `go build of the ${name} helper failed: ${result.stderr.trim()}`);
+      }
+      if (currentCommandPlan()) {
+        // Nothing was built, so there is nothing to keep
+        await fs.rm(dir, { recursive: true, force: true }).catch(() => {});
+      }
+      return binary;
+    } catch (error) {
+      await fs.rm(dir, { recursive: true, force: true }).catch(() => {});
+      throw error;
+    }
+  }
+
+  private async runGoVet(source: string, filePath: string, packageDir?: string): Promise<LanguageError[]> {
+    try {
+      const args = ['vet', ...this.getBuildFlags(), ...this.getVetFlags(), '.'];
+      const result = packageDir
+        ? await this.runInPackage(packageDir, args, filePath)
+        : await this.runInTempModule('go-vet-check-', source, args);
+
+      // `-json` findings go to stdout, text findings and type errors to stderr
+      return this.parseGoVetOutput(`${result.stdout}\n${result.stderr}`, filePath, packageDir ? basename(filePath) : undefined, source);
     } catch (error) {
       this.logger.debug('Go vet execution failed', error);
       return [];
@@ -155,64 +1022,215 @@ export class GoHandler extends BaseLanguageHandler {
       return [];
     }
 
+    const tempDir = await fs.mkdtemp(join(tmpdir(), 'golint-check-'));
+
     try {
-      const tempFile = `/tmp/golint-check-${Date.now()}.go`;
+      const tempFile = join(tempDir, 'main.go');
       await fs.writeFile(tempFile, source);
 
       const result = await this.runCommand(this.golintPath, [tempFile]);
 
-      // Cleanup
-      await fs.unlink(tempFile).catch(() => {});
-
       return this.parseGolintOutput(result.stdout, filePath);
     } catch (error) {
       this.logger.debug('Golint execution failed', error);
       return [];
+    } finally {
+      await fs.rm(tempDir, { recursive: true, force: true }).catch(() => {});
     }
   }
 
-  private parseGoErrors(stderr: string, filePath: string): LanguageError[] {
-    const errors: LanguageError[] = [];
-    const lines = stderr.split('\n');
+  /**
+   * Parse `go build` output, keeping errors reported against `buildFile`: the copy
+   * in a temp module is `main.go`, other files of a real package are dropped.
+   * Errors without a column get a range recovered from `source`. C compiler and
+   * linker output of cgo builds is parsed separately first.
+   */
+  private parseGoErrors(
+    stderr: string,
+    filePath: string,
+    buildFile = 'main.go',
+    buildDir?: string,
+    source?: string
+  ): LanguageError[] {
+    const cgo = parseCgoErrors(stderr, {
+      buildFile,
+      filePath: this.normalizePath(filePath),
+      ...(buildDir && { buildDir }),
+      ...(source !== undefined && { source })
+    });
+    const errors: LanguageError[] = cgo.errors;
+    const lines = cgo.remaining.split('\n');
+    let current: LanguageError | undefined;
 
     for (const line of lines) {
-      // Parse Go compiler errors: ./main.go:5:2: expected declaration, found 'IDENT' foo
-      const match = line.match(/\.\/(.+):(\d+):(\d+): (.+)/);
-      if (match) {
+      // The toolchain refuses to build when every file is excluded by constraints
+      if (line.includes('build constraints exclude all Go files')) {
         errors.push(this.createError(
+          `File excluded by build constraints: ${line.trim()}`,
+          filePath,
+          1,
+          1,
+          'info',
+          'build-constraints'
+        ));
+        current = undefined;
+        continue;
+      }
+
+      // Indented lines continue the previous error: `\t./main.go:3:6: other declaration of x`
+      if (current && /^\s+\S/.test(line)) {
+        const related = line.trim().match(/^(?:\.\/)?(.+?):(\d+)(?::(\d+))?: (.+)/);
+        if (related) {
+          current.relatedInformation!.push({
+            location: {
+              file: this.resolveBuildFile(related[1] || '', filePath, buildFile, buildDir),
+              line: parseInt(related[2] || '1'),
+              column: parseInt(related[3] || '1')
+            },
+            message: related[4] || ''
+          });
+        } else {
+          // Type mismatch details such as `have (int)` / `want (string)`
+          current.message += `\n${line.trim()}`;
+        }
+        continue;
+      }
+
+      // Parse Go compiler errors: ./main.go:5:2: expected declaration, found 'IDENT' foo
+      const match = line.match(/^\.\/(.+?):(\d+)(?::(\d+))?: (.+)/);
+      if (match && match[1] !== buildFile) {
+        // Belongs to another file of the package
+        current = undefined;
+      } else if (match) {
+        current = this.createError(
           match[4] || 'Unknown error',
           filePath,
           parseInt(match[2] || '1'),
           parseInt(match[3] || '1'),
           'error'
-        ));
+        );
+        if (match[3] === undefined) {
+          applyRecoveredRange(current, source);
+        }
+        errors.push(current);
+      } else {
+        current = undefined;
       }
     }
 
     return errors;
   }
 
-  private parseGoVetOutput(stderr: string, filePath: string): LanguageError[] {
+  /**
+   * Map a file named in build output back to the analyzed file. Other names are
+   * resolved against the package directory, or kept as reported for temp modules.
+   */
+  private resolveBuildFile(fileName: string, filePath: string, buildFile: string, buildDir?: string): string {
+    if (fileName === buildFile) {
+      return this.normalizePath(filePath);
+    }
+    return this.normalizePath(buildDir ? resolve(buildDir, fileName) : fileName);
+  }
+
+  private parseGoVetOutput(output: string, filePath: string, buildFile = 'main.go', source?: string): LanguageError[] {
     const errors: LanguageError[] = [];
-    const lines = stderr.split('\n');
+    let jsonBlock: string[] = [];
 
-    for (const line of lines) {
-      // Parse go vet output: ./main.go:5:2: unreachable code
-      const match = line.match(/\.\/(.+):(\d+):(\d+): (.+)/);
-      if (match) {
-        errors.push(this.createError(
-          match[4] || 'Unknown warning',
-          filePath,
-          parseInt(match[2] || '1'),
-          parseInt(match[3] || '1'),
-          'warning'
-        ));
+    for (const line of output.split('\n')) {
+      // `go vet -json` prints one JSON object per package, delimited by unindented braces
+      if (jsonBlock.length > 0 || line === '{') {
+        jsonBlock.push(line);
+        if (line === '}') {
+          errors.push(...this.parseGoVetJson(jsonBlock.join('\n'), filePath, buildFile, source));
+          jsonBlock = [];
+        }
+        continue;
+      }
+
+      const error = this.parseGoVetLine(line, filePath, buildFile, source);
+      if (error) {
+        errors.push(error);
       }
     }
 
     return errors;
   }
 
+  /**
+   * Parse `{"pkg": {"analyzer": [{"posn": "file:line:col", "message": "..."}]}}`
+   */
+  private parseGoVetJson(json: string, filePath: string, buildFile: string, source?: string): LanguageError[] {
+    const errors: LanguageError[] = [];
+
+    try {
+      const packages = JSON.parse(json) as Record<string, Record<string, unknown>>;
+
+      for (const analyzers of Object.values(packages)) {
+        for (const [analyzer, findings] of Object.entries(analyzers)) {
+          if (!Array.isArray(findings)) {
+            // Package-level failures (e.g. type errors) are reported by the compiler pass
+            continue;
+          }
+
+          for (const finding of findings as Array<{ posn?: string; message?: string }>) {
+            const position = (finding.posn || '').match(/^(.*?):(\d+)(?::(\d+))?$/);
+            if (position && basename(position[1] || '') !== buildFile) {
+              continue;
+            }
+            const error = this.createVetError(
+              finding.message || 'Unknown warning',
+              filePath,
+              parseInt(position?.[2] || '1'),
+              parseInt(position?.[3] || '1'),
+              analyzer
+            );
+            errors.push(position && position[3] === undefined ? applyRecoveredRange(error, source) : error);
+          }
+        }
+      }
+    } catch (error) {
+      this.logger.debug('Failed to parse go vet JSON output', error);
+    }
+
+    return errors;
+  }
+
+  private parseGoVetLine(line: string, filePath: string, buildFile = 'main.go', source?: string): LanguageError | undefined {
+    // Type errors are echoed as `vet: ...` but already come from the compiler pass
+    if (line.startsWith('vet: ')) {
+      return undefined;
+    }
+
+    // Parse go vet output: ./main.go:5:2: [printf] fmt.Printf format %d has arg of wrong type
+    const match = line.match(/\.\/(.+?):(\d+)(?::(\d+))?: (?:\[([\w-]+)\] )?(.+)/);
+    if (!match || match[1] !== buildFile) {
+      return undefined;
+    }
+
+    const error = this.createVetError(
+      match[5] || 'Unknown warning',
+      filePath,
+      parseInt(match[2] || '1'),
+      parseInt(match[3] || '1'),
+      match[4]
+    );
+    return match[3] === undefined ? applyRecoveredRange(error, source) : error;
+  }
+
+  private createVetError(
+    message: string,
+    filePath: string,
+    line: number,
+    column: number,
+    analyzer?: string
+  ): LanguageError {
+    const error = this.createError(message, filePath, line, column, 'warning', analyzer || 'vet');
+    if (analyzer) {
+      error.analyzer = analyzer;
+    }
+    return error;
+  }
+
   private parseGolintOutput(stdout: string, filePath: string): LanguageError[] {
     const errors: LanguageError[] = [];
     const lines = stdout.split('\n');
@@ -307,48 +1325,6 @@ export class GoHandler extends BaseLanguageHandler {
     ];
   }
 
-  private async findExecutable(name: string): Promise<string | undefined> {
-    try {
-      const result = await this.runCommand('which', [name]);
-      return result.exitCode === 0 ? result.stdout.trim() : undefined;
-    } catch {
-      return undefined;
-    }
-  }
-
-  private async runCommand(command: string, args: string[], options?: { cwd?: string }): Promise<{
-    stdout: string;
-    stderr: string;
-    exitCode: number;
-  }> {
-    return new Promise((resolve, reject) => {
-      const child = spawn(command, args, { 
-        stdio: 'pipe',
-        cwd: options?.cwd
-      });
-      let stdout = '';
-      let stderr = '';
-
-      child.stdout?.on('data', (data) => {
-        stdout += data.toString();
-      });
-
-      child.stderr?.on('data', (data) => {
-        stderr += data.toString();
-      });
-
-      child.on('close', (code) => {
-        resolve({
-          stdout,
-          stderr,
-          exitCode: code || 0
-        });
-      });
-
-      child.on('error', reject);
-    });
-  }
-
   private calculateComplexity(source: string): number {
     const patterns = [
       /\bif\b/g,
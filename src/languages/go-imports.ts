@@ -0,0 +1,464 @@
+/**
+ * Module-wide check of Go imports: packages imported twice in a file, blank
+ * imports a named import already covers, aliases that repeat the package name,
+ * and import paths the files of a module import under different names
+ */
+
+import { promises as fs } from 'fs';
+import { dirname, join, relative, resolve } from 'path';
+import type { LanguageError, RelatedInformation } from '../types/languages.js';
+import type { CommandResult } from './base-language-handler.js';
+import { parseGoModDirectives } from './go-module.js';
+import { findUpwards } from '../utils/workspace-roots.js';
+import { ToolFailedError } from '../utils/errors.js';
+import { currentCommandPlan } from '../utils/command-plan.js';
+import { formatMessage } from '../utils/messages.js';
+
+/** `analyzer` of import check diagnostics */
+export const GO_IMPORTS_ANALYZER = 'imports';
+
+export interface GoImportCheckOptions {
+  /** Run the check on files saved inside a module (default `false`) */
+  enabled?: boolean;
+  /** How long a finished module walk serves later files in milliseconds (default 2000) */
+  reuseWindowMs?: number;
+}
+
+/**
+ * Helper program built with the local toolchain on first use. It walks the
+ * module rooted at its argument, skipping vendor, testdata, hidden directories
+ * and nested modules, parses the imports of every .go file with go/parser and
+ * prints one JSON object per file that parses. Build constraints are ignored,
+ * since every file counts towards the module's conventions.
+ */
+export const GO_IMPORTS_PROGRAM = `package main
+
+import (
+	"encoding/json"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type importSpec struct {
+	Path      string \`json:"path"\`
+	Name      string \`json:"name,omitempty"\`
+	Line      int    \`json:"line"\`
+	Column    int    \`json:"column"\`
+	EndLine   int    \`json:"endLine"\`
+	EndColumn int    \`json:"endColumn"\`
+}
+
+type fileImports struct {
+	File      string       \`json:"file"\`
+	Package   string       \`json:"package"\`
+	Generated bool         \`json:"generated,omitempty"\`
+	Imports   []importSpec \`json:"imports"\`
+}
+
+var generated = regexp.MustCompile(\`^// Code generated .* DO NOT EDIT\\.$\`)
+
+func main() {
+	if len(os.Args) != 2 {
+		os.Stderr.WriteString("usage: go-import-check dir\\n")
+		os.Exit(2)
+	}
+	root := os.Args[1]
+	out := json.NewEncoder(os.Stdout)
+	fset := token.NewFileSet()
+
+	filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		name := entry.Name()
+		if entry.IsDir() {
+			if path == root {
+				return nil
+			}
+			if name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(name, ".go") || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+			return nil
+		}
+		// Files that do not parse are left to the compiler
+		file, err := parser.ParseFile(fset, path, nil, parser.ImportsOnly|parser.ParseComments)
+		if err != nil {
+			return nil
+		}
+
+		rel, _ := filepath.Rel(root, path)
+		result := fileImports{File: filepath.ToSlash(rel), Package: file.Name.Name, Imports: []importSpec{}}
+		for _, group := range file.Comments {
+			if group.Pos() > file.Package {
+				break
+			}
+			for _, comment := range group.List {
+				if generated.MatchString(comment.Text) {
+					result.Generated = true
+				}
+			}
+		}
+		for _, spec := range file.Imports {
+			importPath, _ := strconv.Unquote(spec.Path.Value)
+			start, end := fset.Position(spec.Pos()), fset.Position(spec.End())
+			imported := importSpec{Path: importPath, Line: start.Line, Column: start.Column, EndLine: end.Line, EndColumn: end.Column}
+			if spec.Name != nil {
+				imported.Name = spec.Name.Name
+			}
+			result.Imports = append(result.Imports, imported)
+		}
+		out.Encode(result)
+		return nil
+	})
+}
+`;
+
+export interface GoImportSpec {
+  path: string;
+  /** Alias, `_` or `.`, when the import has one */
+  name?: string;
+  line: number;
+  column: number;
+  endLine: number;
+  endColumn: number;
+}
+
+export interface GoFileImports {
+  /** Absolute path of the file */
+  file: string;
+  package: string;
+  /** The file says it is generated, so it is neither reported nor taken as a convention */
+  generated: boolean;
+  imports: GoImportSpec[];
+}
+
+/**
+ * Turn the helper's JSON lines into the imports of each file, with paths made
+ * absolute against the module root
+ */
+export function parseGoImportsOutput(stdout: string, moduleRoot: string): GoFileImports[] {
+  const files: GoFileImports[] = [];
+
+  for (const line of stdout.split('\n')) {
+    if (!line.trim()) {
+      continue;
+    }
+    let entry: { file?: string; package?: string; generated?: boolean; imports?: GoImportSpec[] };
+    try {
+      entry = JSON.parse(line);
+    } catch {
+      continue;
+    }
+    if (!entry.file || !entry.package) {
+      continue;
+    }
+    files.push({
+      file: join(moduleRoot, entry.file),
+      package: entry.package,
+      generated: entry.generated === true,
+      imports: (entry.imports || []).filter(spec => typeof spec.path === 'string' && spec.path !== '')
+    });
+  }
+
+  return files;
+}
+
+/**
+ * The name an import path's package is known by: the package clause of a
+ * package of the module, or the last element of a standard library path
+ * (`math/rand/v2` is `rand`). Undefined for other modules' packages, whose
+ * name cannot be told from the path alone.
+ */
+function packageNames(files: GoFileImports[], moduleRoot: string, modulePath: string): (path: string) => string | undefined {
+  const byPath = new Map<string, string>();
+  for (const file of files) {
+    // External test packages (`store_test`) are not what importers get
+    if (file.package.endsWith('_test')) {
+      continue;
+    }
+    const dir = relative(moduleRoot, file.file).split(/[\\/]/).slice(0, -1).join('/');
+    const path = dir ? `${modulePath}/${dir}` : modulePath;
+    if (modulePath && !byPath.has(path)) {
+      byPath.set(path, file.package);
+    }
+  }
+
+  return path => {
+    const known = byPath.get(path);
+    if (known || path.split('/')[0]!.includes('.')) {
+      return known;
+    }
+    const elements = path.split('/');
+    const last = elements[elements.length - 1]!;
+    return /^v\d+$/.test(last) && elements.length > 1 ? elements[elements.length - 2] : last;
+  };
+}
+
+/** How a file refers to an imported package: by an alias, or by its package name */
+interface Spelling {
+  alias?: string;
+  spec: GoImportSpec;
+  file: GoFileImports;
+}
+
+const describeSpelling = (alias: string | undefined) =>
+  (alias ? formatMessage('goImports.alias', { alias }) : formatMessage('goImports.noAlias'));
+
+function hint(
+  file: string,
+  spec: GoImportSpec,
+  message: string,
+  code: string,
+  suggestedFix?: string,
+  relatedInformation: RelatedInformation[] = []
+): LanguageError {
+  return {
+    message,
+    severity: 'hint',
+    location: {
+      file,
+      line: spec.line,
+      column: spec.column,
+      endLine: spec.endLine,
+      endColumn: spec.endColumn
+    },
+    code,
+    source: 'go',
+    analyzer: GO_IMPORTS_ANALYZER,
+    ...(suggestedFix && { suggestedFix }),
+    relatedInformation
+  };
+}
+
+/**
+ * Advisory diagnostics for the imports of a module, at the import they concern:
+ * - `duplicate-import`: a package a file already imports is imported again
+ *   under another name
+ * - `redundant-blank-import`: a blank import of a package the file also imports
+ *   by name, whose init already runs
+ * - `redundant-import-alias`: an alias that is the package's own name
+ * - `inconsistent-import-alias`: files of the module import a path under
+ *   different names, and this file differs from the most common one
+ *
+ * A suggested fix is given where the change is unambiguous: the other files
+ * agree on one name, and taking it clashes with no other import of the file.
+ */
+export function findImportIssues(files: GoFileImports[], moduleRoot: string, modulePath: string): LanguageError[] {
+  const errors: LanguageError[] = [];
+  const packageName = packageNames(files, moduleRoot, modulePath);
+  const reported = files.filter(file => !file.generated);
+  // The name each file refers to a path by, an alias equal to the package name counting as none
+  const spellings = new Map<string, Spelling[]>();
+
+  for (const file of reported) {
+    const seen = new Map<string, GoImportSpec>();
+    const blank: GoImportSpec[] = [];
+
+    for (const spec of file.imports) {
+      if (spec.name === '_') {
+        blank.push(spec);
+        continue;
+      }
+      if (spec.name === '.') {
+        continue;
+      }
+      const known = packageName(spec.path);
+      if (spec.name && spec.name === known) {
+        errors.push(hint(file.file, spec,
+          formatMessage('goImports.redundantAlias', { alias: spec.name, path: spec.path }),
+          'redundant-import-alias', formatMessage('goImports.redundantAliasFix')));
+      }
+
+      const first = seen.get(spec.path);
+      // Two imports under one name do not compile, which the compiler reports
+      if (first && (first.name || known) !== (spec.name || known)) {
+        const firstName = first.name || known;
+        errors.push(hint(file.file, spec,
+          formatMessage('goImports.duplicate', { path: spec.path, line: first.line }),
+          'duplicate-import',
+          firstName && (spec.name || known)
+            ? formatMessage('goImports.duplicateFix', { name: firstName, current: (spec.name || known)! })
+            : undefined,
+          [{
+            location: { file: file.file, line: first.line, column: first.column },
+            message: formatMessage('goImports.importedHere', { path: spec.path })
+          }]));
+      }
+      if (first) {
+        continue;
+      }
+      seen.set(spec.path, spec);
+
+      const alias = spec.name && spec.name !== known ? spec.name : undefined;
+      const list = spellings.get(spec.path) || [];
+      list.push({ ...(alias && { alias }), spec, file });
+      spellings.set(spec.path, list);
+    }
+
+    for (const spec of blank) {
+      const named = seen.get(spec.path);
+      if (named) {
+        errors.push(hint(file.file, spec,
+          formatMessage('goImports.redundantBlank', { path: spec.path, line: named.line }),
+          'redundant-blank-import', formatMessage('goImports.redundantBlankFix'),
+          [{
+            location: { file: file.file, line: named.line, column: named.column },
+            message: formatMessage('goImports.importedHere', { path: spec.path })
+          }]));
+      }
+    }
+  }
+
+  for (const [path, list] of spellings) {
+    const counts = new Map<string, Spelling[]>();
+    for (const spelling of list) {
+      const key = spelling.alias ?? '';
+      counts.set(key, [...(counts.get(key) || []), spelling]);
+    }
+    if (counts.size < 2) {
+      continue;
+    }
+
+    const ranked = Array.from(counts.entries()).sort((a, b) => b[1].length - a[1].length);
+    const [topKey, top] = ranked[0]!;
+    const convention = top.length > ranked[1]![1].length ? topKey : undefined;
+
+    for (const spelling of list) {
+      const key = spelling.alias ?? '';
+      if (key === convention) {
+        continue;
+      }
+      const others = ranked.filter(([other]) => other !== key);
+      const elsewhere = others
+        .map(([other, users]) => formatMessage('goImports.spellingFiles', { spelling: describeSpelling(other || undefined), count: users.length }))
+        .join(formatMessage('goImports.separator'));
+      const example = (convention !== undefined ? top : others[0]![1])[0]!;
+      const target = convention || packageName(path);
+      const current = spelling.alias || packageName(path);
+      // Taking the module's name must not clash with another import of the file
+      const clashes = target !== undefined && spelling.file.imports.some(spec =>
+        spec !== spelling.spec && spec.path !== path && (spec.name || packageName(spec.path)) === target);
+
+      errors.push(hint(spelling.file.file, spelling.spec,
+        formatMessage('goImports.inconsistent', { path, spelling: describeSpelling(spelling.alias), elsewhere }),
+        'inconsistent-import-alias',
+        convention !== undefined && target && current && !clashes
+          ? formatMessage('goImports.inconsistentFix', { spelling: describeSpelling(convention || undefined), name: target, current })
+          : undefined,
+        [{
+          location: { file: example.file.file, line: example.spec.line, column: example.spec.column },
+          message: formatMessage('goImports.importedAs', { path, spelling: describeSpelling(example.alias) })
+        }]));
+    }
+  }
+
+  return errors;
+}
+
+/** Runs the helper binary on a module root */
+export type GoImportCommandRunner = (moduleRoot: string) => Promise<CommandResult>;
+
+interface ModuleWalk {
+  startedAt: number;
+  completedAt?: number;
+  errors: Promise<Map<string, LanguageError[]>>;
+}
+
+/**
+ * Import diagnostics of files on disk. Every file of a module shares one walk
+ * of the module while it runs and for a short while after, as long as the file
+ * has not changed since; files outside a module get none.
+ */
+export class GoImportChecker {
+  private walks = new Map<string, ModuleWalk>();
+
+  constructor(private run: GoImportCommandRunner) {}
+
+  async check(filePath: string, options: GoImportCheckOptions = {}, workspaceRoot?: string): Promise<LanguageError[]> {
+    const fullPath = resolve(filePath);
+    const moduleFile = await findUpwards(fullPath, ['go.mod'], workspaceRoot);
+    if (!moduleFile) {
+      return [];
+    }
+
+    const walk = await this.getWalk(dirname(moduleFile), fullPath, options);
+    return (await walk.errors).get(fullPath) || [];
+  }
+
+  /**
+   * Forget finished walks so the next check walks the module again
+   */
+  invalidate(): void {
+    this.walks.clear();
+  }
+
+  private async getWalk(moduleRoot: string, filePath: string, options: GoImportCheckOptions): Promise<ModuleWalk> {
+    // A planned walk neither reuses a real one nor is reused
+    if (currentCommandPlan()) {
+      return { startedAt: Date.now(), errors: this.walkModule(moduleRoot) };
+    }
+    const existing = this.walks.get(moduleRoot);
+    if (existing && await this.canReuse(existing, filePath, options)) {
+      return existing;
+    }
+
+    const walk: ModuleWalk = { startedAt: Date.now(), errors: this.walkModule(moduleRoot) };
+    this.walks.set(moduleRoot, walk);
+
+    walk.errors
+      .then(() => {
+        walk.completedAt = Date.now();
+      })
+      .catch(() => {
+        // Failed walks are not reused
+        if (this.walks.get(moduleRoot) === walk) {
+          this.walks.delete(moduleRoot);
+        }
+      });
+
+    return walk;
+  }
+
+  private async canReuse(walk: ModuleWalk, filePath: string, options: GoImportCheckOptions): Promise<boolean> {
+    if (walk.completedAt === undefined) {
+      return true;
+    }
+    if (Date.now() - walk.completedAt > (options.reuseWindowMs ?? 2000)) {
+      return false;
+    }
+    try {
+      return (await fs.stat(filePath)).mtimeMs < walk.startedAt;
+    } catch {
+      return false;
+    }
+  }
+
+  private async walkModule(moduleRoot: string): Promise<Map<string, LanguageError[]>> {
+    const goMod = await fs.readFile(join(moduleRoot, 'go.mod'), 'utf-8').catch(() => '');
+    const modulePath = parseGoModDirectives(goMod).find(directive => directive.verb === 'module')?.path || '';
+    const result = await this.run(moduleRoot);
+    if (result.exitCode !== 0) {
+      throw new ToolFailedError('go-import-check', result.exitCode, result.stderr);
+    }
+
+    const byFile = new Map<string, LanguageError[]>();
+    for (const error of findImportIssues(parseGoImportsOutput(result.stdout, moduleRoot), moduleRoot, modulePath)) {
+      const list = byFile.get(error.location.file) || [];
+      list.push(error);
+      byFile.set(error.location.file, list);
+    }
+    return byFile;
+  }
+}
@@ -0,0 +1,323 @@
+/**
+ * go.mod diagnostics: module resolution failures of the go command, reported at
+ * the go.mod directive that causes them
+ */
+
+import type { LanguageError } from '../types/languages.js';
+import { OFFLINE_BLOCKED_CODE } from './go-offline.js';
+import { formatMessage } from '../utils/messages.js';
+
+/** `analyzer` of go.mod diagnostics */
+export const GO_MODULE_ANALYZER = 'modules';
+
+export interface GoModPosition {
+  line: number;
+  column: number;
+  endColumn: number;
+}
+
+export interface GoModDirective extends GoModPosition {
+  verb: string;
+  path: string;
+}
+
+function byteColumn(text: string, index: number): number {
+  return Buffer.byteLength(text.slice(0, index), 'utf-8') + 1;
+}
+
+/**
+ * Module paths named by the directives of a go.mod file, with their positions.
+ * Both single-line directives and `require ( ... )` blocks are read.
+ */
+export function parseGoModDirectives(content: string): GoModDirective[] {
+  const directives: GoModDirective[] = [];
+  let block: string | undefined;
+
+  content.split('\n').forEach((rawLine, index) => {
+    const text = rawLine.replace(/\r$/, '');
+    const code = text.replace(/\/\/.*$/, '');
+    const trimmed = code.trim();
+
+    if (block) {
+      if (trimmed === ')') {
+        block = undefined;
+        return;
+      }
+    } else {
+      const opening = trimmed.match(/^(module|require|replace|exclude|retract|tool|godebug)\s*\($/);
+      if (opening) {
+        block = opening[1];
+        return;
+      }
+    }
+
+    const match = block
+      ? code.match(/^(\s*)("?[^\s"]+"?)/)
+      : code.match(/^(\s*(module|require|replace|exclude)\s+)("?[^\s"]+"?)/);
+    if (!match) {
+      return;
+    }
+
+    const verb = block || match[2]!;
+    const token = block ? match[2]! : match[3]!;
+    const start = match[1]!.length;
+    directives.push({
+      verb,
+      path: token.replace(/"/g, ''),
+      line: index + 1,
+      column: byteColumn(text, start),
+      endColumn: byteColumn(text, start + token.length)
+    });
+  });
+
+  return directives;
+}
+
+/**
+ * The directive for a module, or for the module providing a package: its require
+ * line first, then a replace or exclude line. Falls back to the `module` line.
+ */
+export function locateGoModModule(content: string, modulePath?: string): GoModDirective | undefined {
+  const directives = parseGoModDirectives(content);
+  const moduleLine = directives.find(directive => directive.verb === 'module');
+  if (!modulePath) {
+    return moduleLine;
+  }
+
+  // The longest module path that is the package path or a prefix of it
+  const provides = (directive: GoModDirective) =>
+    modulePath === directive.path || modulePath.startsWith(`${directive.path}/`);
+  for (const verb of ['require', 'replace', 'exclude']) {
+    const candidates = directives
+      .filter(directive => directive.verb === verb && provides(directive))
+      .sort((a, b) => b.path.length - a.path.length);
+    if (candidates[0]) {
+      return candidates[0];
+    }
+  }
+  return moduleLine;
+}
+
+interface ModuleProblem {
+  message: string;
+  code: string;
+  /** Module or package the problem is about, used to find its directive */
+  modulePath?: string;
+  /** Given the module path of the directive found for `modulePath`, if any */
+  suggestedFix?: (module?: string) => string;
+  position?: GoModPosition;
+}
+
+function commandFix(commands: string[], fallback: string): string {
+  const unique = Array.from(new Set(commands.length > 0 ? commands : [fallback]));
+  return formatMessage('goModule.runCommands', {
+    commands: unique.map(command => `\`${command}\``).join(formatMessage('goModule.or'))
+  });
+}
+
+/**
+ * Recognize one module problem from a line of go command output. `hints` are the
+ * commands go suggests on the indented lines that follow, such as `go mod download x`.
+ */
+function recognize(line: string, hints: string[], details: string[]): ModuleProblem | undefined {
+  const text = line.replace(/^go: /, '').replace(/^.*?\.go:\d+(?::\d+)?: /, '').trim();
+  let match: RegExpMatchArray | null;
+
+  if ((match = text.match(/^go\.mod:(\d+)(?::(\d+))?: (.+)$/)) || (match = text.match(/^.*[\\/]go\.mod:(\d+)(?::(\d+))?: (.+)$/))) {
+    const lineNumber = parseInt(match[1]!);
+    const column = match[2] ? parseInt(match[2]) : 1;
+    return {
+      message: match[3]!,
+      code: 'go-mod-syntax',
+      position: { line: lineNumber, column, endColumn: column }
+    };
+  }
+
+  if ((match = text.match(/^missing go\.sum entry for module providing package (\S+)/))) {
+    return {
+      message: text.replace(/;\s*to add:?$/, ''),
+      code: 'missing-go-sum',
+      modulePath: match[1]!,
+      suggestedFix: module => module
+        ? formatMessage('goModule.goSumModuleFix', { module })
+        : formatMessage('goModule.goSumFix')
+    };
+  }
+
+  // go: example.com/x@v1.2.3: missing go.sum entry for go.mod file; to add it:
+  if ((match = text.match(/^(?:(\S+?)@\S+?: )?missing go\.sum entry for go\.mod file/))) {
+    const modulePath = match[1] || hints.map(hint => hint.match(/^go mod download (\S+?)(?:@\S+)?$/)?.[1]).find(Boolean);
+    return {
+      message: text.replace(/;\s*to add it:?$/, ''),
+      code: 'missing-go-sum',
+      ...(modulePath && { modulePath }),
+      suggestedFix: () => formatMessage('goModule.goSumGoModFix', { run: commandFix(hints, 'go mod download') })
+    };
+  }
+
+  if (/^updates to go\.mod needed/.test(text)) {
+    return {
+      message: formatMessage('goModule.tidy'),
+      code: 'go-mod-tidy',
+      suggestedFix: () => commandFix(hints, 'go mod tidy')
+    };
+  }
+
+  if (/^inconsistent vendoring/.test(text)) {
+    return {
+      message: [formatMessage('goModule.vendoring'), ...details].join('\n'),
+      code: 'inconsistent-vendoring',
+      suggestedFix: () => formatMessage('goModule.vendoringFix')
+    };
+  }
+
+  if ((match = text.match(/^module (\S+) found \(([^)]+)\), but does not contain package (\S+)/))) {
+    const [, modulePath, , packagePath] = match;
+    return {
+      message: text,
+      code: 'missing-package',
+      modulePath: modulePath!,
+      suggestedFix: () => formatMessage('goModule.missingPackageFix', { module: modulePath!, package: packagePath! })
+    };
+  }
+
+  // verifying example.com/x@v1.2.3: checksum mismatch / verifying example.com/x@v1.2.3/go.mod: checksum mismatch
+  if ((match = text.match(/^verifying (\S+?)@(\S+?)(?:\/go\.mod)?: checksum mismatch/))) {
+    const [, modulePath, version] = match;
+    return {
+      message: [text, ...details].join('\n'),
+      code: 'checksum-mismatch',
+      modulePath: modulePath!,
+      suggestedFix: () => formatMessage('goModule.checksumMismatchFix', { module: modulePath!, version: version! })
+    };
+  }
+
+  // go mod verify: example.com/x v1.2.3: dir has been modified (/root/go/pkg/mod/...)
+  if ((match = text.match(/^(\S+) (v\S+): (?:dir|zip) has been modified/))) {
+    return {
+      message: text,
+      code: 'module-modified',
+      modulePath: match[1]!,
+      suggestedFix: () => formatMessage('goModule.modifiedFix')
+    };
+  }
+
+  // example.com/a@v1.2.0 requires example.com/b@v2.0.0, but v1.9.0 is requested
+  if ((match = text.match(/^(\S+?)@(\S+) requires (\S+?)@(\S+?), but (\S+) is requested/))) {
+    const [, , , dependency, version] = match;
+    return {
+      message: text,
+      code: 'version-conflict',
+      modulePath: dependency!,
+      suggestedFix: () => formatMessage('goModule.versionConflictFix', { module: dependency!, version: version! })
+    };
+  }
+
+  // example.com/a@v1.2.0 requires example.com/b@v0.9.9: invalid version: ..., reported at the requirement on a
+  if ((match = text.match(/^(\S+?)@(\S+) requires (\S+?)@(\S+?): (.+)$/))) {
+    const [, modulePath, , dependency] = match;
+    return {
+      message: [text, ...details].join('\n'),
+      code: 'invalid-dependency',
+      modulePath: modulePath!,
+      suggestedFix: () => formatMessage('goModule.invalidDependencyFix', { module: modulePath!, dependency: dependency! })
+    };
+  }
+
+  // example.com/x@v1.2.3: module lookup disabled by GOPROXY=off
+  if ((match = text.match(/^(\S+?)@(\S+?): (?:module|import) lookup disabled by (GOPROXY=off|-mod=readonly)/))) {
+    const [, modulePath, version, setting] = match;
+    return {
+      message: formatMessage('goModule.offlineBlocked', { module: modulePath!, version: version!, setting: setting! }),
+      code: OFFLINE_BLOCKED_CODE,
+      modulePath: modulePath!,
+      suggestedFix: () => formatMessage('goModule.offlineBlockedFix', { module: modulePath!, version: version! })
+    };
+  }
+
+  // example.com/x@v1.2.3: invalid version: unknown revision v1.2.3 / reading ...: 404 Not Found
+  if ((match = text.match(/^(\S+?)@(\S+?): (.+)$/))) {
+    const reason = [match[3]!, ...details].join('\n');
+    const invalid = /invalid version|unknown revision|no matching versions/.test(reason);
+    const [, modulePath, version] = match;
+    return {
+      message: [text, ...details].join('\n'),
+      code: invalid ? 'invalid-version' : 'module-fetch',
+      modulePath: modulePath!,
+      suggestedFix: () => invalid
+        ? formatMessage('goModule.invalidVersionFix', { module: modulePath! })
+        : formatMessage('goModule.fetchFix', { module: modulePath!, version: version! })
+    };
+  }
+
+  return undefined;
+}
+
+/**
+ * Turn the module errors in go command output (go build, go list -m, go mod
+ * verify) into diagnostics on go.mod. Each one is placed on the require,
+ * replace or exclude line of the module involved, found by scanning the file for
+ * the module path, and carries the command go suggests as its fix.
+ */
+export function parseGoModuleErrors(output: string, goModPath: string, goModContent: string): LanguageError[] {
+  const lines = output.split('\n').map(line => line.replace(/\r$/, ''));
+  const errors: LanguageError[] = [];
+  const seen = new Set<string>();
+
+  for (let index = 0; index < lines.length; index++) {
+    const line = lines[index]!;
+    if (!line.trim() || /^\s/.test(line)) {
+      continue;
+    }
+
+    // Indented lines that follow hold suggested commands or details
+    const hints: string[] = [];
+    const details: string[] = [];
+    for (let next = index + 1; next < lines.length && /^\s+\S/.test(lines[next]!); next++) {
+      const detail = lines[next]!.trim();
+      (/^go (?:get|mod|work) /.test(detail) ? hints : details).push(detail);
+    }
+
+    // `go: example.com/a@v1 requires` continues with the failing dependency on the next line
+    let text = line;
+    if (/ requires$/.test(line) && details[0]) {
+      text = `${line} ${details.shift()}`;
+    }
+    // `go: example.com/svc imports` chains end with the failing import and its error
+    if (/ imports$/.test(line) && details.length > 0) {
+      text = details.pop()!.replace(/^\S+: /, '');
+    }
+
+    const problem = recognize(text, hints, details);
+    if (!problem) {
+      continue;
+    }
+
+    const directive = problem.position ? undefined : locateGoModModule(goModContent, problem.modulePath);
+    const position = problem.position || directive || { line: 1, column: 1, endColumn: 1 };
+    const suggestedFix = problem.suggestedFix?.(directive && directive.verb !== 'module' ? directive.path : undefined);
+    const key = `${position.line}:${problem.code}:${problem.message}`;
+    if (seen.has(key)) {
+      continue;
+    }
+    seen.add(key);
+
+    errors.push({
+      message: problem.message,
+      severity: 'error',
+      location: {
+        file: goModPath,
+        line: position.line,
+        column: position.column,
+        ...(position.endColumn > position.column && { endLine: position.line, endColumn: position.endColumn })
+      },
+      code: problem.code,
+      source: 'go',
+      analyzer: GO_MODULE_ANALYZER,
+      relatedInformation: [],
+      ...(suggestedFix && { suggestedFix })
+    });
+  }
+
+  return errors;
+}
@@ -0,0 +1,92 @@
+/**
+ * Offline mode for the go command: nothing is downloaded, not even a newer
+ * toolchain, and go.mod and go.sum are never rewritten
+ */
+
+import type { LanguageError } from '../types/languages.js';
+import { formatMessage } from '../utils/messages.js';
+
+/** `code` of diagnostics for analysis that offline mode kept from downloading a module */
+export const OFFLINE_BLOCKED_CODE = 'offline-blocked';
+
+/** Resolution errors go prints when GOPROXY=off or -mod=readonly stopped it */
+const LOOKUP_DISABLED = /(?:module|import) lookup disabled by (?:GOPROXY=off|-mod=readonly)/;
+
+/** `main.go:3:8: <message>`, with or without the leading `./` */
+const POSITIONED_LINE = /^(?:\.\/)?(.+?\.go):(\d+):(\d+): (.+)$/;
+
+/**
+ * Variables that put the go command in offline mode: `GOPROXY=off`,
+ * `GOTOOLCHAIN=local` and `-mod=readonly` in place of any other `-mod` flag of
+ * `goflags`. A `-mod=vendor` is kept, since vendored builds neither download
+ * nor write either.
+ */
+export function goOfflineVariables(goflags = ''): Record<string, string> {
+  const flags = goflags.split(/\s+/).filter(Boolean);
+
+  return {
+    GOPROXY: 'off',
+    GOTOOLCHAIN: 'local',
+    GOFLAGS: flags.includes('-mod=vendor')
+      ? flags.join(' ')
+      : [...flags.filter(flag => !flag.startsWith('-mod=')), '-mod=readonly'].join(' ')
+  };
+}
+
+/**
+ * Explain a build error that offline mode caused: the go command needed a
+ * module it may not download. Other errors are returned unchanged.
+ */
+export function markOfflineBlocked(error: LanguageError, importPath?: string): LanguageError {
+  if (!LOOKUP_DISABLED.test(error.message)) {
+    return error;
+  }
+  return {
+    ...error,
+    message: importPath
+      ? formatMessage('goOffline.import', { import: importPath, message: error.message })
+      : formatMessage('goOffline.anyImport', { message: error.message }),
+    code: OFFLINE_BLOCKED_CODE,
+    suggestedFix: formatMessage('goOffline.fix')
+  };
+}
+
+/**
+ * Diagnostics on the imports of `buildFile` that offline mode kept from
+ * resolving, e.g. `main.go:3:8: module lookup disabled by GOPROXY=off`. The
+ * import path is read from the source at the reported position.
+ */
+export function parseOfflineImportErrors(
+  stderr: string,
+  buildFile: string,
+  filePath: string,
+  source?: string
+): LanguageError[] {
+  const errors: LanguageError[] = [];
+  const lines = source?.split('\n');
+
+  for (const line of stderr.split('\n')) {
+    const match = line.replace(/\r$/, '').match(POSITIONED_LINE);
+    if (!match || match[1] !== buildFile || !LOOKUP_DISABLED.test(match[4]!)) {
+      continue;
+    }
+
+    const lineNumber = parseInt(match[2]!);
+    const column = parseInt(match[3]!);
+    const importPath = lines?.[lineNumber - 1]?.slice(column - 1).match(/^"([^"]+)"/)?.[1];
+    errors.push(markOfflineBlocked({
+      message: match[4]!,
+      severity: 'error',
+      location: {
+        file: filePath,
+        line: lineNumber,
+        column,
+        ...(importPath && { endLine: lineNumber, endColumn: column + importPath.length + 2 })
+      },
+      source: 'go',
+      relatedInformation: []
+    }, importPath));
+  }
+
+  return errors;
+}
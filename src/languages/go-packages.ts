@@ -0,0 +1,163 @@
+/**
+ * Go package patterns (`./internal/...`, `example.com/mod/pkg`): validation of
+ * what they match, and the go build and go vet output for every matched package
+ */
+
+import { isAbsolute, resolve } from 'path';
+import type { LanguageError } from '../types/languages.js';
+
+/** `go list -e` template: import path, directory and error of each match */
+export const GO_LIST_PACKAGE_FORMAT = '{{.ImportPath}}\t{{.Dir}}\t{{if .Error}}{{.Error.Err}}{{end}}';
+
+export interface GoPackageMatch {
+  importPath: string;
+  dir: string;
+}
+
+export interface GoPatternResolution {
+  packages: GoPackageMatch[];
+  /** One line per pattern that matched nothing or could not be resolved */
+  problems: string[];
+}
+
+/**
+ * Read `go list -e -f GO_LIST_PACKAGE_FORMAT` output. A pattern that names no
+ * directory, matches no packages, points at a directory without Go files or
+ * cannot be downloaded is a problem; a package that merely fails to compile is
+ * a match, and its errors come from the build.
+ */
+export function parseGoListPatterns(stdout: string, stderr: string): GoPatternResolution {
+  const packages: GoPackageMatch[] = [];
+  const problems: string[] = [];
+
+  for (const line of stdout.split('\n')) {
+    if (!line.trim()) {
+      continue;
+    }
+    const [importPath = '', dir = '', error = ''] = line.replace(/\r$/, '').split('\t');
+    if (error && (!dir || /^no Go files in /.test(error))) {
+      problems.push(importPath && !error.includes(importPath) ? `${importPath}: ${error}` : error);
+    } else {
+      packages.push({ importPath, dir });
+    }
+  }
+
+  // go: warning: "./empty/..." matched no packages
+  for (const match of stderr.matchAll(/^go: warning: "(.+)" matched no packages$/gm)) {
+    problems.push(`${match[1]}: matched no packages`);
+  }
+
+  return { packages, problems };
+}
+
+function resolveReported(file: string, dir: string): string {
+  return isAbsolute(file) ? file : resolve(dir, file);
+}
+
+/**
+ * Parse `go build` output for several packages. Paths are printed relative to
+ * the directory the command ran in (`./main.go`, `internal/store/store.go`) and
+ * come back absolute.
+ */
+export function parseGoPackageBuildOutput(stderr: string, dir: string): LanguageError[] {
+  const errors: LanguageError[] = [];
+  let current: LanguageError | undefined;
+
+  for (const line of stderr.split('\n').map(text => text.replace(/\r$/, ''))) {
+    // Indented lines continue the previous error, as positions or details
+    if (current && /^\s+\S/.test(line)) {
+      const related = line.trim().match(/^(.+?\.go):(\d+)(?::(\d+))?: (.+)$/);
+      if (related) {
+        current.relatedInformation!.push({
+          location: {
+            file: resolveReported(related[1]!, dir),
+            line: parseInt(related[2]!),
+            column: parseInt(related[3] || '1')
+          },
+          message: related[4]!
+        });
+      } else {
+        current.message += `\n${line.trim()}`;
+      }
+      continue;
+    }
+
+    const match = line.match(/^(.+?\.go):(\d+)(?::(\d+))?: (.+)$/);
+    if (!match) {
+      current = undefined;
+      continue;
+    }
+
+    current = {
+      message: match[4]!,
+      severity: 'error',
+      location: {
+        file: resolveReported(match[1]!, dir),
+        line: parseInt(match[2]!),
+        column: parseInt(match[3] || '1')
+      },
+      source: 'go',
+      relatedInformation: []
+    };
+    errors.push(current);
+  }
+
+  return errors;
+}
+
+/**
+ * Parse `go vet -json` output for several packages. Recent toolchains print the
+ * JSON on stdout and older ones on stderr, so both are read. Findings carry
+ * absolute positions; type errors, which vet only echoes, are left to the build.
+ */
+export function parseGoPackageVetOutput(output: string, dir: string): LanguageError[] {
+  const errors: LanguageError[] = [];
+  let block: string[] = [];
+
+  for (const line of output.split('\n').map(text => text.replace(/\r$/, ''))) {
+    if (block.length === 0 && line !== '{') {
+      continue;
+    }
+    block.push(line);
+    if (line !== '}') {
+      continue;
+    }
+
+    let packages: Record<string, Record<string, unknown>>;
+    try {
+      packages = JSON.parse(block.join('\n'));
+    } catch {
+      packages = {};
+    }
+    block = [];
+
+    for (const analyzers of Object.values(packages)) {
+      for (const [analyzer, findings] of Object.entries(analyzers)) {
+        if (!Array.isArray(findings)) {
+          continue;
+        }
+        for (const finding of findings as Array<{ posn?: string; message?: string }>) {
+          const position = (finding.posn || '').match(/^(.*?):(\d+)(?::(\d+))?$/);
+          if (!position) {
+            continue;
+          }
+          errors.push({
+            message: finding.message || 'Unknown warning',
+            severity: 'warning',
+            location: {
+              file: resolveReported(position[1]!, dir),
+              line: parseInt(position[2]!),
+              column: parseInt(position[3] || '1')
+            },
+            code: analyzer,
+            analyzer,
+            source: 'go',
+            relatedInformation: []
+          });
+        }
+      }
+    }
+  }
+
+  return errors;
+}
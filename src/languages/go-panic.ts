@@ -0,0 +1,129 @@
+/**
+ * Parsing of Go panic and fatal error traces printed by a crashing program
+ */
+
+import { isAbsolute, relative, resolve } from 'path';
+import type { LanguageError, RelatedInformation } from '../types/languages.js';
+
+export interface GoTraceFrame {
+  function: string;
+  file: string;
+  line: number;
+}
+
+export interface GoPanic {
+  /** Text after `panic: ` or `fatal error: `, with any `[recovered]` marker removed */
+  message: string;
+  kind: 'panic' | 'fatal error';
+  /** Goroutine that crashed, such as `1 [running]` */
+  goroutine?: string;
+  /** Its frames, innermost call first */
+  frames: GoTraceFrame[];
+}
+
+const PANIC_LINE = /^(panic|fatal error): (.*)$/;
+const GOROUTINE_LINE = /^goroutine (\d+ \[[^\]]+\]):$/;
+const FUNCTION_LINE = /^(\S.*)\((?:[^()]*|\.\.\.)\)$/;
+const LOCATION_LINE = /^\t(.+?):(\d+)(?: \+0x[0-9a-f]+)?$/;
+
+/**
+ * Find the first panic in a program's stderr and the stack of the goroutine that
+ * raised it. Returns undefined when the output holds no panic.
+ */
+export function parseGoPanic(output: string): GoPanic | undefined {
+  const lines = output.split(/\r?\n/);
+  const start = lines.findIndex(line => PANIC_LINE.test(line));
+  if (start < 0) {
+    return undefined;
+  }
+
+  const [, kind, text] = lines[start]!.match(PANIC_LINE)!;
+  const messageLines = [text!.replace(/ \[recovered\]$/, '')];
+  let index = start + 1;
+
+  // Messages can span lines (e.g. an error's own newlines) up to the goroutine header
+  for (; index < lines.length && !GOROUTINE_LINE.test(lines[index]!); index++) {
+    const line = lines[index]!;
+    // Re-panics print another `panic: ` line; the first one is the cause
+    if (line.trim() && !PANIC_LINE.test(line.trim()) && !line.startsWith('[signal ')) {
+      messageLines.push(line.trim());
+    }
+  }
+
+  const panic: GoPanic = {
+    message: messageLines.join('\n').trim(),
+    kind: kind === 'panic' ? 'panic' : 'fatal error',
+    frames: [],
+  };
+  if (index >= lines.length) {
+    return panic;
+  }
+
+  panic.goroutine = lines[index]!.match(GOROUTINE_LINE)![1]!;
+  for (index++; index + 1 < lines.length; index += 2) {
+    const fn = lines[index]!.match(FUNCTION_LINE);
+    const location = lines[index + 1]!.match(LOCATION_LINE);
+    if (!fn || !location) {
+      break;
+    }
+    panic.frames.push({ function: fn[1]!, file: location[1]!, line: parseInt(location[2]!, 10) });
+  }
+
+  return panic;
+}
+
+/**
+ * Whether a frame belongs to the Go runtime or standard library rather than user code
+ */
+export function isRuntimeFrame(frame: GoTraceFrame, goroot?: string): boolean {
+  if (/^(?:runtime|testing|reflect|syscall|internal\/[\w/]+)\./.test(frame.function)) {
+    return true;
+  }
+  if (goroot && isInside(resolve(goroot), frame.file)) {
+    return true;
+  }
+  return /\/go\/src\/(?:runtime|testing|reflect|internal)\//.test(frame.file);
+}
+
+function isInside(root: string, file: string): boolean {
+  const rel = relative(root, resolve(file));
+  return rel === '' || (!rel.startsWith('..') && !isAbsolute(rel));
+}
+
+/**
+ * The frame to report a panic at: the innermost one inside the workspace, else the
+ * innermost one outside the runtime
+ */
+export function findPanicFrame(panic: GoPanic, workspaceRoot?: string, goroot?: string): GoTraceFrame | undefined {
+  const userFrames = panic.frames.filter(frame => !isRuntimeFrame(frame, goroot));
+  return (workspaceRoot ? userFrames.find(frame => isInside(workspaceRoot, frame.file)) : undefined) ?? userFrames[0];
+}
+
+/**
+ * Turn a panic into a diagnostic at its top user-code frame. The rest of the user
+ * call stack is attached as related information.
+ */
+export function goPanicToError(
+  panic: GoPanic,
+  fallbackFile: string,
+  workspaceRoot?: string,
+  goroot?: string
+): LanguageError {
+  const frame = findPanicFrame(panic, workspaceRoot, goroot);
+  const relatedInformation: RelatedInformation[] = panic.frames
+    .filter(candidate => candidate !== frame && !isRuntimeFrame(candidate, goroot))
+    .map(candidate => ({
+      location: { file: candidate.file, line: candidate.line, column: 1 },
+      message: `in ${candidate.function}`,
+    }));
+
+  return {
+    message: `${panic.kind}: ${panic.message}${frame ? ` (in ${frame.function})` : ''}`,
+    severity: 'error',
+    location: { file: frame?.file || fallbackFile, line: frame?.line || 1, column: 1 },
+    code: panic.kind === 'panic' ? 'panic' : 'fatal-error',
+    source: 'go',
+    analyzer: 'runtime',
+    relatedInformation,
+  };
+}
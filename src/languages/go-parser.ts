@@ -0,0 +1,148 @@
+/**
+ * Fallback Go analysis with go/parser and go/types, for files too broken for
+ * `go build` to report more than its first few errors
+ */
+
+import type { LanguageError } from '../types/languages.js';
+
+/** `source` of diagnostics from the parser fallback */
+export const GO_PARSER_SOURCE = 'parser';
+
+/**
+ * Helper program built with the local toolchain on first use. It parses the file
+ * with parser.AllErrors and prints every scanner.Error; a file that parses is
+ * type-checked together with the other files of its package, as go build would,
+ * and every type error in the file is printed. One JSON object per line.
+ */
+export const GO_PARSER_PROGRAM = `package main
+
+import (
+	"encoding/json"
+	"flag"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type diagnostic struct {
+	Line    int    \`json:"line"\`
+	Column  int    \`json:"column"\`
+	Message string \`json:"message"\`
+	Kind    string \`json:"kind"\`
+}
+
+func main() {
+	tags := flag.String("tags", "", "comma-separated build tags")
+	flag.Parse()
+	if flag.NArg() != 2 {
+		os.Stderr.WriteString("usage: go-parser-check [-tags list] dir file\\n")
+		os.Exit(2)
+	}
+	dir, name := flag.Arg(0), flag.Arg(1)
+	out := json.NewEncoder(os.Stdout)
+	fset := token.NewFileSet()
+
+	target, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.AllErrors|parser.ParseComments)
+	if list, ok := err.(scanner.ErrorList); ok {
+		for _, e := range list {
+			out.Encode(diagnostic{e.Pos.Line, e.Pos.Column, e.Msg, "syntax"})
+		}
+		return
+	}
+	if err != nil {
+		os.Stderr.WriteString(err.Error() + "\\n")
+		os.Exit(1)
+	}
+
+	if *tags != "" {
+		build.Default.BuildTags = strings.Split(*tags, ",")
+	}
+	files := []*ast.File{target}
+	entries, _ := os.ReadDir(dir)
+	for _, entry := range entries {
+		other := entry.Name()
+		if other == name || entry.IsDir() || !strings.HasSuffix(other, ".go") || strings.HasSuffix(other, "_test.go") {
+			continue
+		}
+		if match, err := build.Default.MatchFile(dir, other); err != nil || !match {
+			continue
+		}
+		if file, _ := parser.ParseFile(fset, filepath.Join(dir, other), nil, 0); file != nil && file.Name.Name == target.Name.Name {
+			files = append(files, file)
+		}
+	}
+
+	targetFile := fset.File(target.Pos())
+	conf := types.Config{
+		Importer:    importer.ForCompiler(fset, "source", nil),
+		FakeImportC: true,
+		Error: func(err error) {
+			if e, ok := err.(types.Error); ok && e.Fset.File(e.Pos) == targetFile {
+				pos := e.Fset.Position(e.Pos)
+				out.Encode(diagnostic{pos.Line, pos.Column, e.Msg, "type"})
+			}
+		},
+	}
+	conf.Check(target.Name.Name, fset, files, nil)
+}
+`;
+
+/**
+ * Whether a failed build is worth a second look with the parser: the compiler
+ * stopped at syntax errors in the file, so its type errors were never reported
+ * and later syntax errors may be missing too, or the build failed without any
+ * diagnostic at all
+ */
+export function needsParserFallback(errors: readonly LanguageError[]): boolean {
+  return errors.some(error => error.code === 'toolchain' || /^syntax error\b/.test(error.message));
+}
+
+/**
+ * Turn the helper's JSON lines into diagnostics for the analyzed file, in
+ * position order. go/parser repeats some errors at the end of a truncated
+ * file, and those are reported once.
+ */
+export function parseGoParserOutput(stdout: string, filePath: string): LanguageError[] {
+  const errors: LanguageError[] = [];
+  const seen = new Set<string>();
+
+  for (const line of stdout.split('\n')) {
+    if (!line.trim()) {
+      continue;
+    }
+
+    let entry: { line?: number; column?: number; message?: string; kind?: string };
+    try {
+      entry = JSON.parse(line);
+    } catch {
+      continue;
+    }
+    const key = JSON.stringify([entry.line, entry.column, entry.message]);
+    if (!entry.message || seen.has(key)) {
+      continue;
+    }
+    seen.add(key);
+
+    errors.push({
+      message: entry.message,
+      severity: 'error',
+      location: {
+        file: filePath,
+        line: Math.max(1, entry.line || 1),
+        column: Math.max(1, entry.column || 1)
+      },
+      code: entry.kind === 'type' ? 'type-error' : 'syntax-error',
+      source: GO_PARSER_SOURCE,
+      relatedInformation: []
+    });
+  }
+
+  return errors.sort((a, b) => a.location.line - b.location.line || a.location.column - b.location.column);
+}
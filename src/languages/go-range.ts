@@ -0,0 +1,233 @@
+/**
+ * Range recovery for Go diagnostics: columns of those that only report a line,
+ * and end positions of those that only report where they start
+ */
+
+import type { LanguageError } from '../types/languages.js';
+
+/**
+ * What a message points at, most specific first. Each pattern captures the
+ * source text to look for on the reported line.
+ */
+const MESSAGE_TOKENS: RegExp[] = [
+  // undefined: foo / undefined: strings.Buildr
+  /^undefined: ([\w.]+)/,
+  // "os" imported and not used / "net/http" imported as h and not used
+  /^("[^"]+") imported (?:as \w+ )?and not used/,
+  // declared and not used: x (go1.20+) / x declared and not used / x declared but not used
+  /^declared and not used: (\w+)/,
+  /^(\w+) declared (?:and|but) not used/,
+  /^(\w+) redeclared in this block/,
+  // cannot use x (variable of type int) as string value in assignment
+  /^cannot use (.+?) \(/,
+  // syntax error: unexpected name foo, expected ... / unexpected keyword func / unexpected )
+  /^syntax error: unexpected (?:name |keyword |literal )?(\S+?),?(?: |$)/,
+  // missing function body / invalid operation: x + y (mismatched types ...)
+  /^invalid operation: (.+?) \(/,
+  // Anything quoted, e.g. expected 'IDENT', found 'foo'
+  /'([^']+)'\s*$/,
+  /`([^`]+)`/
+];
+
+/** `unexpected newline` and `unexpected EOF` point just past the end of the line */
+const END_OF_LINE_TOKENS = new Set(['newline', 'EOF']);
+
+export interface RecoveredRange {
+  column: number;
+  endColumn: number;
+}
+
+/**
+ * Go columns count bytes, so offsets into the line are converted with UTF-8 lengths
+ */
+function byteColumn(lineText: string, index: number): number {
+  return Buffer.byteLength(lineText.slice(0, index), 'utf-8') + 1;
+}
+
+function findToken(lineText: string, token: string): number {
+  if (/^\w+$/.test(token)) {
+    const match = new RegExp(`(?<![\\w.])${token}(?!\\w)`).exec(lineText);
+    return match ? match.index : -1;
+  }
+  return lineText.indexOf(token);
+}
+
+/**
+ * Guess the range a line-only Go message refers to from the text of that line.
+ * The identifier or token named in the message is searched for on the line;
+ * without one, the whole line is covered. Columns are 1-based bytes and
+ * `endColumn` is exclusive, like the ranges gopls reports.
+ */
+export function recoverGoRange(message: string, lineText: string): RecoveredRange {
+  const wholeLine = { column: 1, endColumn: byteColumn(lineText, lineText.length) };
+
+  for (const pattern of MESSAGE_TOKENS) {
+    const token = pattern.exec(message)?.[1];
+    if (!token) {
+      continue;
+    }
+    if (END_OF_LINE_TOKENS.has(token)) {
+      const end = byteColumn(lineText, lineText.trimEnd().length);
+      return { column: end, endColumn: end };
+    }
+
+    let index = findToken(lineText, token);
+    let found = token;
+    // A qualified name may only appear partly, e.g. the selector of a longer expression
+    if (index < 0 && token.includes('.')) {
+      found = token.slice(token.lastIndexOf('.') + 1);
+      index = found ? findToken(lineText, found) : -1;
+    }
+    if (index >= 0) {
+      return { column: byteColumn(lineText, index), endColumn: byteColumn(lineText, index + found.length) };
+    }
+  }
+
+  return wholeLine;
+}
+
+/**
+ * Fill in the column and end column of a diagnostic reported without a column,
+ * using the analyzed source when it is known, and flag the range as approximate
+ */
+export function applyRecoveredRange(error: LanguageError, source?: string): LanguageError {
+  const lineText = source?.split('\n')[error.location.line - 1]?.replace(/\r$/, '');
+  if (lineText !== undefined) {
+    const { column, endColumn } = recoverGoRange(error.message.split('\n')[0]!, lineText);
+    error.location.column = column;
+    error.location.endLine = error.location.line;
+    error.location.endColumn = endColumn;
+  } else {
+    error.location.column = 1;
+  }
+  error.approximateRange = true;
+  return error;
+}
+
+export interface GoToken {
+  /** Offsets into the line, in UTF-16 code units; `end` is exclusive */
+  start: number;
+  end: number;
+  text: string;
+}
+
+/** Operators and punctuation of the Go spec, longest first */
+const GO_OPERATORS = [
+  '<<=', '>>=', '&^=', '...',
+  '&&', '||', '<-', '++', '--', '==', '!=', '<=', '>=', ':=',
+  '+=', '-=', '*=', '/=', '%=', '&=', '|=', '^=', '<<', '>>', '&^'
+];
+
+const GO_IDENTIFIER = /[\p{L}_][\p{L}\p{Nd}_]*/uy;
+const GO_NUMBER = /(?:0[xX][\da-fA-F_]*(?:\.[\da-fA-F_]*)?(?:[pP][+-]?[\d_]+)?|0[bBoO][\da-fA-F_]*|(?:\d[\d_]*(?:\.[\d_]*)?|\.\d[\d_]*)(?:[eE][+-]?[\d_]+)?)i?/y;
+
+/**
+ * Split one line of Go source into tokens by the rules of go/scanner:
+ * identifiers, number literals, interpreted, raw and rune literals, comments,
+ * and the longest operator at each position. A literal or comment left open
+ * runs to the end of the line.
+ */
+export function tokenizeGoLine(lineText: string): GoToken[] {
+  const tokens: GoToken[] = [];
+  let index = 0;
+
+  const closeQuoted = (quote: string): number => {
+    for (let end = index + 1; end < lineText.length; end++) {
+      if (lineText[end] === '\\' && quote !== '`') {
+        end++;
+      } else if (lineText[end] === quote) {
+        return end + 1;
+      }
+    }
+    return lineText.length;
+  };
+
+  while (index < lineText.length) {
+    const char = lineText[index]!;
+    if (/\s/.test(char)) {
+      index++;
+      continue;
+    }
+
+    let end: number;
+    if (lineText.startsWith('//', index)) {
+      end = lineText.length;
+    } else if (lineText.startsWith('/*', index)) {
+      const close = lineText.indexOf('*/', index + 2);
+      end = close < 0 ? lineText.length : close + 2;
+    } else if (char === '"' || char === '\'' || char === '`') {
+      end = closeQuoted(char);
+    } else {
+      GO_IDENTIFIER.lastIndex = index;
+      GO_NUMBER.lastIndex = index;
+      const word = GO_IDENTIFIER.exec(lineText) || (/[\d.]/.test(char) ? GO_NUMBER.exec(lineText) : null);
+      const operator = GO_OPERATORS.find(candidate => lineText.startsWith(candidate, index));
+      end = word?.[0] ? index + word[0].length : index + (operator?.length ?? 1);
+    }
+
+    tokens.push({ start: index, end, text: lineText.slice(index, end) });
+    index = end;
+  }
+
+  return tokens;
+}
+
+/**
+ * End column of the text a Go diagnostic starting at `column` refers to. When
+ * the message names an identifier or expression that starts there, the range
+ * covers all of it; otherwise it covers the token at the start. A start that is
+ * not on a token gets a one-column range. Columns are 1-based bytes and the end
+ * is exclusive.
+ */
+export function inferGoEndColumn(message: string, lineText: string, column: number): number {
+  // Map the byte column to an offset into the line
+  let offset = 0;
+  for (let bytes = 1; offset < lineText.length && bytes < column;) {
+    const char = String.fromCodePoint(lineText.codePointAt(offset)!);
+    bytes += Buffer.byteLength(char, 'utf-8');
+    offset += char.length;
+  }
+
+  const tokens = tokenizeGoLine(lineText);
+  const first = tokens.findIndex(token => token.start <= offset && offset < token.end);
+  if (first < 0) {
+    return column + 1;
+  }
+
+  const named = MESSAGE_TOKENS.map(pattern => pattern.exec(message)?.[1]).find(Boolean);
+  if (named) {
+    // Expressions are printed with normalized spacing, so whitespace is ignored
+    const target = named.replace(/\s+/g, '');
+    let matched = '';
+    for (const token of tokens.slice(first)) {
+      matched += token.text.replace(/\s+/g, '');
+      if (matched === target) {
+        return byteColumn(lineText, token.end);
+      }
+      if (!target.startsWith(matched)) {
+        break;
+      }
+    }
+  }
+
+  return byteColumn(lineText, tokens[first]!.end);
+}
+
+/**
+ * Fill in the end of a diagnostic that only reports its start, from the line of
+ * the analyzed source it points at. Diagnostics that already have an end, and
+ * those pointing past the source, are left alone.
+ */
+export function applyInferredEnd(error: LanguageError, source: string): LanguageError {
+  if (error.location.endColumn !== undefined) {
+    return error;
+  }
+  const lineText = source.split('\n')[error.location.line - 1]?.replace(/\r$/, '');
+  if (lineText === undefined) {
+    return error;
+  }
+
+  error.location.endLine = error.location.line;
+  error.location.endColumn = inferGoEndColumn(error.message.split('\n')[0]!, lineText, error.location.column);
+  return error;
+}
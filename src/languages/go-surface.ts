@@ -0,0 +1,137 @@
+/**
+ * The part of a Go file other files of its package depend on, to tell edits
+ * that can change their diagnostics from edits that cannot
+ */
+
+/** Build constraints and other `//go:` directives change what the package holds */
+const DIRECTIVE = /^\/\/(?:go:|\s*\+build)/;
+
+const IDENTIFIER = /[\p{L}_][\p{L}\p{Nd}_]*/uy;
+const NUMBER = /\.?\d[\w.]*/y;
+
+/** Tokens after which a newline ends the statement, by the semicolon rule of the Go spec */
+const ENDS_STATEMENT = /^(?:[\p{L}_\d"'`.]|[)\]}])/u;
+
+/** Keywords that begin a top-level declaration */
+const DECLARATIONS = new Set(['func', 'var', 'const', 'type', 'import']);
+
+/**
+ * Split Go source into tokens: identifiers and keywords, number literals,
+ * string and rune literals (raw strings may span lines), kept directives, `;`
+ * for newlines that end a statement, and operators one character at a time.
+ * Other comments are dropped.
+ */
+function tokenizeGoSource(source: string): string[] {
+  const tokens: string[] = [];
+  let index = 0;
+
+  const push = (end: number) => {
+    tokens.push(source.slice(index, end));
+    index = end;
+  };
+  const closeQuoted = (quote: string): number => {
+    for (let end = index + 1; end < source.length; end++) {
+      if (source[end] === '\\' && quote !== '`') {
+        end++;
+      } else if (source[end] === quote || (quote !== '`' && source[end] === '\n')) {
+        return end + 1;
+      }
+    }
+    return source.length;
+  };
+
+  while (index < source.length) {
+    const char = source[index]!;
+    if (char === '\n') {
+      if (tokens.length > 0 && ENDS_STATEMENT.test(tokens[tokens.length - 1]!)) {
+        tokens.push(';');
+      }
+      index++;
+    } else if (/\s/.test(char)) {
+      index++;
+    } else if (source.startsWith('//', index)) {
+      const newline = source.indexOf('\n', index);
+      const end = newline < 0 ? source.length : newline;
+      if (DIRECTIVE.test(source.slice(index, end))) {
+        push(end);
+      } else {
+        index = end;
+      }
+    } else if (source.startsWith('/*', index)) {
+      const close = source.indexOf('*/', index + 2);
+      index = close < 0 ? source.length : close + 2;
+    } else if (char === '"' || char === '\'' || char === '`') {
+      push(closeQuoted(char));
+    } else {
+      IDENTIFIER.lastIndex = index;
+      NUMBER.lastIndex = index;
+      const word = IDENTIFIER.exec(source) || (/[\d.]/.test(char) ? NUMBER.exec(source) : null);
+      push(word?.[0] ? index + word[0].length : index + 1);
+    }
+  }
+
+  return tokens;
+}
+
+/**
+ * Everything of `source` that other files of the package can see: the package
+ * clause, imports, directives and top-level declarations, with the bodies of
+ * functions and methods left out. Two versions of a file with the same surface
+ * only differ inside function bodies, which the type checker never consults
+ * to check other files. Files that `import "C"` share their cgo preamble with
+ * the package, so their surface is the whole source.
+ */
+export function goPackageSurface(source: string): string {
+  if (/^\s*import\s*(?:\(\s*)?"C"/m.test(source)) {
+    return source;
+  }
+
+  const surface: string[] = [];
+  let inSignature = false;
+  // Parens and brackets of a signature, and braces of struct and interface types in it
+  let nesting = 0;
+  let typeBraces = 0;
+  let skippedBraces = 0;
+  let previous = '';
+
+  for (const token of tokenizeGoSource(source)) {
+    if (skippedBraces > 0) {
+      skippedBraces += token === '{' ? 1 : token === '}' ? -1 : 0;
+      if (skippedBraces === 0) {
+        surface.push('{}');
+        previous = '}';
+      }
+      continue;
+    }
+
+    if (token === 'func') {
+      inSignature = true;
+      nesting = 0;
+      typeBraces = 0;
+    } else if (inSignature) {
+      if (token === '(' || token === '[') {
+        nesting++;
+      } else if (token === ')' || token === ']') {
+        nesting--;
+      } else if (token === '{' && (previous === 'struct' || previous === 'interface')) {
+        typeBraces++;
+      } else if (token === '}' && typeBraces > 0) {
+        typeBraces--;
+      } else if (token === '{' && nesting === 0 && typeBraces === 0) {
+        // The body, whether of a declaration or of a function literal in an initializer
+        inSignature = false;
+        skippedBraces = 1;
+        previous = token;
+        continue;
+      } else if (nesting === 0 && typeBraces === 0 && (token === ';' || DECLARATIONS.has(token))) {
+        // A declaration without a body, implemented in assembly
+        inSignature = false;
+      }
+    }
+
+    surface.push(token);
+    previous = token;
+  }
+
+  return surface.join(' ');
+}
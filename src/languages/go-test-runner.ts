@@ -0,0 +1,472 @@
+/**
+ * Module-wide `go test -json ./...` runner that turns failing tests into diagnostics
+ */
+
+import { promises as fs } from 'fs';
+import { dirname, isAbsolute, join, resolve } from 'path';
+import type { LanguageError } from '../types/languages.js';
+import type { CommandResult } from './base-language-handler.js';
+import { findUpwards } from '../utils/workspace-roots.js';
+import { currentCommandPlan } from '../utils/command-plan.js';
+import { goPanicToError, parseGoPanic } from './go-panic.js';
+
+/** `source` of diagnostics produced by failing tests */
+export const TEST_FAILURE_SOURCE = 'test-failure';
+
+export type GoTestCommandRunner = (args: string[], cwd: string) => Promise<CommandResult>;
+
+/**
+ * `go test` settings, read from the Go handler's `tests` option
+ */
+export interface GoTestOptions {
+  /** Run the module's tests when its files are analyzed (default false, since it executes code) */
+  enabled?: boolean;
+  /** Value of `go test -timeout` (default 2m) */
+  timeout?: string;
+  /** Extra `go test` flags, such as `-short` or `-run=TestAPI` */
+  args?: string[];
+  /** How long a completed run is reused for other files, in milliseconds (default 2000) */
+  reuseWindowMs?: number;
+}
+
+/**
+ * One event of the `go test -json` stream (see `go doc test2json`)
+ */
+export interface GoTestEvent {
+  Action: string;
+  Package?: string;
+  /** Set on `build-output` / `build-fail` events instead of Package */
+  ImportPath?: string;
+  Test?: string;
+  Output?: string;
+  Elapsed?: number;
+}
+
+export interface GoTestFailure {
+  package: string;
+  test: string;
+  output: string[];
+}
+
+export interface GoTestReport {
+  /** Tests that reported `fail`, in stream order */
+  failures: GoTestFailure[];
+  /** Output of packages that failed outside any single test (timeouts, setup failures) */
+  packageFailures: Array<{ package: string; output: string[] }>;
+  /** Tests that started but never finished, by package; a timeout kills them mid-run */
+  unfinished: Map<string, string[]>;
+  /** Compiler output: non-JSON lines and `build-output` events */
+  buildOutput: string[];
+}
+
+const TERMINAL_ACTIONS = new Set(['pass', 'fail', 'skip']);
+
+/**
+ * Decode a `go test -json` stream. Lines that are not JSON events (build errors
+ * printed by older toolchains, stray writes to stdout) are kept as build output
+ * rather than aborting the parse.
+ */
+export function parseGoTestJson(stream: string): GoTestReport {
+  const report: GoTestReport = { failures: [], packageFailures: [], unfinished: new Map(), buildOutput: [] };
+  const outputs = new Map<string, string[]>();
+  const running = new Map<string, Set<string>>();
+
+  for (const line of stream.split(/\r?\n/)) {
+    const event = decodeEvent(line);
+    if (!event) {
+      if (line.trim()) {
+        report.buildOutput.push(line);
+      }
+      continue;
+    }
+
+    if (event.Action === 'build-output') {
+      report.buildOutput.push(...splitOutput(event.Output));
+      continue;
+    }
+
+    const pkg = event.Package || event.ImportPath || '';
+    const key = `${pkg}\u0000${event.Test || ''}`;
+    if (event.Action === 'output') {
+      const lines = outputs.get(key) || [];
+      lines.push(...splitOutput(event.Output));
+      outputs.set(key, lines);
+      continue;
+    }
+
+    if (event.Test) {
+      const tests = running.get(pkg) || new Set<string>();
+      running.set(pkg, tests);
+      if (event.Action === 'run') {
+        tests.add(event.Test);
+      } else if (TERMINAL_ACTIONS.has(event.Action)) {
+        tests.delete(event.Test);
+      }
+      if (event.Action === 'fail') {
+        report.failures.push({ package: pkg, test: event.Test, output: outputs.get(key) || [] });
+      }
+    } else if (event.Action === 'fail') {
+      report.packageFailures.push({ package: pkg, output: outputs.get(key) || [] });
+      const unfinished = Array.from(running.get(pkg) || []);
+      if (unfinished.length > 0) {
+        report.unfinished.set(pkg, unfinished);
+      }
+    }
+  }
+
+  return report;
+}
+
+function decodeEvent(line: string): GoTestEvent | undefined {
+  if (!line.startsWith('{')) {
+    return undefined;
+  }
+  try {
+    const event = JSON.parse(line) as GoTestEvent;
+    return typeof event.Action === 'string' ? event : undefined;
+  } catch {
+    return undefined;
+  }
+}
+
+function splitOutput(output: string | undefined): string[] {
+  return (output || '').replace(/\n$/, '').split('\n');
+}
+
+/** `    store_test.go:12: got 1, want 2` as printed by t.Errorf and friends */
+const ASSERTION_LINE = /^(\s+)([\w.\-+/]+\.go):(\d+): ?(.*)$/;
+/** `./store.go:3:2: undefined: x` or `internal/store/store_test.go:3:2: ...` */
+const BUILD_ERROR_LINE = /^(?:\.\/)?(.+?\.go):(\d+):(\d+): (.+)$/;
+
+/**
+ * Assertion messages in a failed test's output, with continuation lines joined
+ */
+export function parseTestAssertions(output: string[]): Array<{ file: string; line: number; message: string }> {
+  const assertions: Array<{ file: string; line: number; message: string }> = [];
+  let current: (typeof assertions)[number] | undefined;
+  let indent = 0;
+
+  for (const line of output) {
+    const match = line.match(ASSERTION_LINE);
+    if (match) {
+      current = { file: match[2]!, line: parseInt(match[3]!, 10), message: match[4]! };
+      indent = match[1]!.length;
+      assertions.push(current);
+    } else if (current && line.trim() && line.search(/\S/) > indent) {
+      // Multi-line messages are indented further than their first line
+      current.message += `\n${line.trim()}`;
+    } else {
+      current = undefined;
+    }
+  }
+
+  return assertions;
+}
+
+interface TestRun {
+  startedAt: number;
+  completedAt?: number;
+  errors: Promise<Map<string, LanguageError[]>>;
+}
+
+interface ModuleInfo {
+  root: string;
+  path: string;
+}
+
+export class GoTestRunner {
+  private runs = new Map<string, TestRun>();
+
+  constructor(private run: GoTestCommandRunner) {}
+
+  /**
+   * Test diagnostics for a file on disk. Every file of a module shares one
+   * `go test -json ./...` run; files outside a module get none.
+   */
+  async check(filePath: string, options: GoTestOptions = {}, workspaceRoot?: string): Promise<LanguageError[]> {
+    const fullPath = resolve(filePath);
+    const moduleFile = await findUpwards(fullPath, ['go.mod'], workspaceRoot);
+    if (!moduleFile) {
+      return [];
+    }
+
+    const run = await this.getRun(dirname(moduleFile), fullPath, options);
+    return (await run.errors).get(fullPath) || [];
+  }
+
+  /**
+   * Forget completed runs so the next check re-runs the tests
+   */
+  invalidate(): void {
+    this.runs.clear();
+  }
+
+  private async getRun(moduleRoot: string, filePath: string, options: GoTestOptions): Promise<TestRun> {
+    // A planned run neither reuses a real one nor is reused
+    if (currentCommandPlan()) {
+      return { startedAt: Date.now(), errors: this.runModule(moduleRoot, options) };
+    }
+    const existing = this.runs.get(moduleRoot);
+    if (existing && await this.canReuse(existing, filePath, options)) {
+      return existing;
+    }
+
+    const run: TestRun = { startedAt: Date.now(), errors: this.runModule(moduleRoot, options) };
+    this.runs.set(moduleRoot, run);
+
+    run.errors
+      .then(() => {
+        run.completedAt = Date.now();
+      })
+      .catch(() => {
+        // Failed runs are not reused
+        if (this.runs.get(moduleRoot) === run) {
+          this.runs.delete(moduleRoot);
+        }
+      });
+
+    return run;
+  }
+
+  private async canReuse(run: TestRun, filePath: string, options: GoTestOptions): Promise<boolean> {
+    if (run.completedAt === undefined) {
+      return true;
+    }
+    if (Date.now() - run.completedAt > (options.reuseWindowMs ?? 2000)) {
+      return false;
+    }
+    try {
+      return (await fs.stat(filePath)).mtimeMs < run.startedAt;
+    } catch {
+      return false;
+    }
+  }
+
+  private async runModule(moduleRoot: string, options: GoTestOptions): Promise<Map<string, LanguageError[]>> {
+    const module: ModuleInfo = { root: moduleRoot, path: await readModulePath(moduleRoot) };
+    const result = await this.run(
+      ['test', '-json', `-timeout=${options.timeout || '2m'}`, ...(options.args || []), './...'],
+      moduleRoot
+    );
+    const report = parseGoTestJson(`${result.stdout}\n${result.stderr}`);
+    const byFile = new Map<string, LanguageError[]>();
+    const add = (error: LanguageError) => {
+      const list = byFile.get(error.location.file) || [];
+      list.push(error);
+      byFile.set(error.location.file, list);
+    };
+
+    for (const error of buildErrors(report.buildOutput, moduleRoot)) {
+      add(error);
+    }
+
+    // A parent test fails with its subtests; only the tests that said why are reported
+    const failed = new Set(report.failures.map(failure => `${failure.package}\u0000${failure.test}`));
+    const panicked = new Set<string>();
+    for (const failure of report.failures) {
+      const hasFailingSubtest = Array.from(failed).some(key => key.startsWith(`${failure.package}\u0000${failure.test}/`));
+      const errors = await this.failureErrors(failure, module, hasFailingSubtest);
+      if (errors.some(error => error.code === 'panic')) {
+        panicked.add(failure.package);
+      }
+      errors.forEach(add);
+    }
+
+    for (const { package: pkg, output } of report.packageFailures) {
+      const failedTests = report.failures.filter(failure => failure.package === pkg).map(failure => failure.test);
+      const errors = await this.packageFailureErrors(pkg, output, {
+        unfinished: report.unfinished.get(pkg) || [],
+        failedTest: failedTests[failedTests.length - 1],
+        reportPanic: !panicked.has(pkg)
+      }, module);
+      errors.forEach(add);
+    }
+
+    return byFile;
+  }
+
+  private async failureErrors(failure: GoTestFailure, module: ModuleInfo, hasFailingSubtest: boolean): Promise<LanguageError[]> {
+    const packageDir = packageDirectory(failure.package, module);
+    if (!packageDir) {
+      return [];
+    }
+
+    const panic = parseGoPanic(failure.output.join('\n'));
+    if (panic) {
+      const error = goPanicToError(panic, await findTestFile(packageDir, failure.test) || packageDir, module.root);
+      return [{ ...error, message: `${failure.test}: ${error.message}`, source: TEST_FAILURE_SOURCE }];
+    }
+
+    const assertions = parseTestAssertions(failure.output);
+    if (assertions.length > 0) {
+      return assertions.map(assertion => testError(
+        `${failure.test}: ${assertion.message}`,
+        isAbsolute(assertion.file) ? assertion.file : join(packageDir, assertion.file),
+        assertion.line,
+        'test-failure',
+        failure.test
+      ));
+    }
+    if (hasFailingSubtest) {
+      return [];
+    }
+
+    // A bare t.Fail() or t.FailNow() leaves no location; point at the test itself
+    const location = await findTestFunction(packageDir, failure.test);
+    return location
+      ? [testError(`${failure.test} failed`, location.file, location.line, 'test-failure', failure.test)]
+      : [];
+  }
+
+  private async packageFailureErrors(
+    pkg: string,
+    output: string[],
+    context: { unfinished: string[]; failedTest: string | undefined; reportPanic: boolean },
+    module: ModuleInfo
+  ): Promise<LanguageError[]> {
+    const packageDir = packageDirectory(pkg, module);
+    if (!packageDir) {
+      return [];
+    }
+
+    const timeout = output.map(line => line.match(/^panic: test timed out after (\S+)/)).find(Boolean);
+    if (timeout) {
+      // Newer toolchains list the tests that were running; older ones leave them unfinished
+      const listed = output
+        .map(line => line.match(/^\s+(Test\S*) \(\S+\)$/)?.[1])
+        .filter((name): name is string => name !== undefined);
+      const tests = listed.length > 0 ? listed : context.unfinished;
+      const errors: LanguageError[] = [];
+      for (const test of tests.length > 0 ? tests : ['']) {
+        const location = test ? await findTestFunction(packageDir, test) : undefined;
+        errors.push(testError(
+          `${test || pkg} timed out after ${timeout[1]}`,
+          location?.file || await findTestFile(packageDir, '') || packageDir,
+          location?.line || 1,
+          'test-timeout',
+          test || undefined
+        ));
+      }
+      return errors;
+    }
+
+    // Older toolchains attribute a test's panic to the package rather than the test
+    const panic = context.reportPanic ? parseGoPanic(output.join('\n')) : undefined;
+    if (panic) {
+      const test = output.map(line => line.match(/^--- FAIL: (\S+)/)?.[1]).find(Boolean) || context.failedTest;
+      const error = goPanicToError(panic, await findTestFile(packageDir, test || '') || packageDir, module.root);
+      return [{ ...error, ...(test && { message: `${test}: ${error.message}` }), source: TEST_FAILURE_SOURCE }];
+    }
+
+    // `[build failed]` is covered by the compiler errors, `[setup failed]` (e.g. a bad import) is not
+    if (output.some(line => line.includes('[setup failed]'))) {
+      const details = output.filter(line => line.trim() && !/^(?:FAIL|ok)\s/.test(line));
+      const file = await findTestFile(packageDir, '') || packageDir;
+      return [testError(`${pkg}: ${details.join('\n') || 'test setup failed'}`, file, 1, 'test-setup', undefined)];
+    }
+    return [];
+  }
+}
+
+function testError(message: string, file: string, line: number, code: string, test: string | undefined): LanguageError {
+  return {
+    message: message.trim(),
+    severity: 'error',
+    location: { file, line: Math.max(1, line), column: 1 },
+    code,
+    source: TEST_FAILURE_SOURCE,
+    ...(test && { analyzer: test }),
+    relatedInformation: []
+  };
+}
+
+/**
+ * Compiler errors from building the test binaries, resolved against the module root
+ */
+function buildErrors(lines: string[], moduleRoot: string): LanguageError[] {
+  const errors: LanguageError[] = [];
+  let current: LanguageError | undefined;
+
+  for (const line of lines) {
+    const match = line.match(BUILD_ERROR_LINE);
+    if (match) {
+      current = {
+        message: match[4]!.trim(),
+        severity: 'error',
+        location: {
+          file: isAbsolute(match[1]!) ? match[1]! : join(moduleRoot, match[1]!),
+          line: parseInt(match[2]!, 10),
+          column: parseInt(match[3]!, 10)
+        },
+        source: 'go',
+        code: 'test-build',
+        relatedInformation: []
+      };
+      errors.push(current);
+    } else if (current && /^\s+\S/.test(line)) {
+      current.message += `\n${line.trim()}`;
+    } else {
+      current = undefined;
+    }
+  }
+
+  return errors;
+}
+
+async function readModulePath(moduleRoot: string): Promise<string> {
+  try {
+    const goMod = await fs.readFile(join(moduleRoot, 'go.mod'), 'utf-8');
+    return goMod.match(/^module\s+"?([^\s"]+)"?/m)?.[1] || '';
+  } catch {
+    return '';
+  }
+}
+
+/**
+ * Directory of a package of the module, from its import path
+ */
+function packageDirectory(importPath: string, module: ModuleInfo): string | undefined {
+  // Test binaries of a package are reported as `pkg [pkg.test]`
+  const pkg = importPath.replace(/ \[.*\]$/, '');
+  if (!module.path || pkg === module.path) {
+    return module.root;
+  }
+  return pkg.startsWith(`${module.path}/`) ? join(module.root, pkg.slice(module.path.length + 1)) : undefined;
+}
+
+async function testFiles(packageDir: string): Promise<string[]> {
+  try {
+    return (await fs.readdir(packageDir)).filter(name => name.endsWith('_test.go')).sort();
+  } catch {
+    return [];
+  }
+}
+
+/**
+ * Declaration of a top-level test function (subtests resolve to their parent)
+ */
+async function findTestFunction(packageDir: string, test: string): Promise<{ file: string; line: number } | undefined> {
+  const name = test.split('/')[0]!;
+  const declaration = new RegExp(`^func ${name.replace(/[.*+?^${}()|[\]\\]/g, '\\$&')}\\(`);
+
+  for (const file of await testFiles(packageDir)) {
+    const lines = (await fs.readFile(join(packageDir, file), 'utf-8').catch(() => '')).split('\n');
+    const index = lines.findIndex(line => declaration.test(line));
+    if (index >= 0) {
+      return { file: join(packageDir, file), line: index + 1 };
+    }
+  }
+  return undefined;
+}
+
+/**
+ * The file declaring a test, or the package's first test file
+ */
+async function findTestFile(packageDir: string, test: string): Promise<string | undefined> {
+  const location = test ? await findTestFunction(packageDir, test) : undefined;
+  if (location) {
+    return location.file;
+  }
+  const [first] = await testFiles(packageDir);
+  return first ? join(packageDir, first) : undefined;
+}
@@ -0,0 +1,477 @@
+/**
+ * Long-lived gopls language server, spoken to over LSP on stdio
+ */
+
+import { spawn, type ChildProcessWithoutNullStreams } from 'child_process';
+import { fileURLToPath, pathToFileURL } from 'url';
+import type { LanguageError, RelatedInformation } from '../types/languages.js';
+import { Logger } from '../utils/logger.js';
+import { ToolFailedError, ToolNotFoundError } from '../utils/errors.js';
+import { cancellationError, isDetectorTimeout } from '../utils/cancellation.js';
+import { analysisStats } from '../utils/analysis-stats.js';
+
+/**
+ * gopls settings, read from the Go handler's `gopls` option
+ */
+export interface GoplsOptions {
+  /** Use gopls instead of `go build` and `go vet` (default `false`) */
+  enabled?: boolean;
+  /** gopls executable (default `gopls` on PATH) */
+  path?: string;
+  /** Extra command line flags, such as `["-remote=auto"]` */
+  args?: string[];
+  /** Variables added to the server's environment, from the handler's `env` option */
+  env?: Record<string, string>;
+  /** Longest wait for a file's diagnostics in milliseconds (default 10000) */
+  diagnosticsTimeoutMs?: number;
+  /**
+   * gopls publishes parse errors first and type-checking and analysis results
+   * later; after each publish, wait this long for another (default 300)
+   */
+  settleMs?: number;
+}
+
+const DEFAULT_DIAGNOSTICS_TIMEOUT_MS = 10_000;
+const DEFAULT_SETTLE_MS = 300;
+const SHUTDOWN_TIMEOUT_MS = 2000;
+
+interface LspPosition {
+  line: number;
+  character: number;
+}
+
+interface LspRange {
+  start: LspPosition;
+  end: LspPosition;
+}
+
+/** An LSP `Diagnostic` as published by gopls */
+export interface LspDiagnostic {
+  range: LspRange;
+  /** 1 error, 2 warning, 3 information, 4 hint */
+  severity?: number;
+  code?: string | number;
+  /** `compiler`, `syntax` or the name of the analyzer that reported it */
+  source?: string;
+  message: string;
+  relatedInformation?: Array<{ location: { uri: string; range: LspRange }; message: string }>;
+}
+
+interface LspMessage {
+  id?: number | string;
+  method?: string;
+  params?: unknown;
+  result?: unknown;
+  error?: { code: number; message: string };
+}
+
+const LSP_SEVERITIES: Record<number, LanguageError['severity']> = {
+  1: 'error',
+  2: 'warning',
+  3: 'info',
+  4: 'hint',
+};
+
+function uriToPath(uri: string): string {
+  return uri.startsWith('file:') ? fileURLToPath(uri) : uri;
+}
+
+/**
+ * Translate an LSP diagnostic into ours. LSP positions are 0-based; ours are 1-based.
+ * Their characters are UTF-16 code units, unlike the byte columns of the go command.
+ */
+export function fromLspDiagnostic(diagnostic: LspDiagnostic, file: string): LanguageError {
+  const { start, end } = diagnostic.range;
+  const relatedInformation: RelatedInformation[] = (diagnostic.relatedInformation || []).map(info => ({
+    location: {
+      file: uriToPath(info.location.uri),
+      line: info.location.range.start.line + 1,
+      column: info.location.range.start.character + 1,
+    },
+    message: info.message,
+  }));
+
+  return {
+    message: diagnostic.message,
+    severity: LSP_SEVERITIES[diagnostic.severity ?? 1] || 'error',
+    location: {
+      file,
+      line: start.line + 1,
+      column: start.character + 1,
+      endLine: end.line + 1,
+      endColumn: end.character + 1,
+    },
+    ...(diagnostic.code !== undefined && { code: diagnostic.code }),
+    source: 'gopls',
+    ...(diagnostic.source && { analyzer: diagnostic.source }),
+    relatedInformation,
+    columnUnit: 'utf16',
+  };
+}
+
+/**
+ * Frame a JSON-RPC message with the LSP `Content-Length` header
+ */
+export function encodeLspMessage(message: object): string {
+  const body = JSON.stringify({ jsonrpc: '2.0', ...message });
+  return `Content-Length: ${Buffer.byteLength(body, 'utf-8')}\r\n\r\n${body}`;
+}
+
+/**
+ * Splits a byte stream into LSP messages, however the chunks are cut
+ */
+export class LspMessageReader {
+  private buffer = Buffer.alloc(0);
+
+  push(chunk: Buffer): LspMessage[] {
+    this.buffer = Buffer.concat([this.buffer, chunk]);
+    const messages: LspMessage[] = [];
+
+    for (;;) {
+      const headerEnd = this.buffer.indexOf('\r\n\r\n');
+      if (headerEnd < 0) {
+        break;
+      }
+
+      const length = this.buffer.subarray(0, headerEnd).toString('ascii').match(/Content-Length: *(\d+)/i);
+      if (!length) {
+        // Not a header we understand; skip it rather than stall
+        this.buffer = this.buffer.subarray(headerEnd + 4);
+        continue;
+      }
+
+      const bodyStart = headerEnd + 4;
+      const bodyEnd = bodyStart + Number(length[1]);
+      if (this.buffer.length < bodyEnd) {
+        break;
+      }
+
+      const body = this.buffer.subarray(bodyStart, bodyEnd).toString('utf-8');
+      this.buffer = this.buffer.subarray(bodyEnd);
+      try {
+        messages.push(JSON.parse(body) as LspMessage);
+      } catch {
+        // A malformed message is dropped; the stream stays in sync through its length
+      }
+    }
+
+    return messages;
+  }
+}
+
+/**
+ * Thrown when the gopls process cannot be started or initialized
+ */
+export class GoplsStartError extends ToolFailedError {
+  constructor(message: string, stderr = '', options?: ErrorOptions) {
+    super('gopls', -1, stderr, message, options);
+    this.name = 'GoplsStartError';
+  }
+}
+
+interface PendingRequest {
+  resolve: (result: unknown) => void;
+  reject: (error: Error) => void;
+}
+
+/**
+ * One gopls process for one workspace folder. Documents are opened on first use and
+ * updated with full-text changes afterwards; calls are serialized so each waits for
+ * the diagnostics of its own version.
+ */
+export class GoplsClient {
+  private child: ChildProcessWithoutNullStreams | undefined;
+  private started: Promise<void> | undefined;
+  private reader = new LspMessageReader();
+  private nextId = 1;
+  private pending = new Map<number | string, PendingRequest>();
+  private versions = new Map<string, number>();
+  private published = new Map<string, { version?: number; diagnostics: LspDiagnostic[] }>();
+  private listeners = new Set<(uri: string) => void>();
+  private queue: Promise<unknown> = Promise.resolve();
+  private stderr = '';
+  private stopping = false;
+
+  constructor(
+    private readonly rootDir: string,
+    private readonly options: GoplsOptions = {},
+    private readonly logger: Logger = new Logger('debug', { logFile: undefined, enableConsole: false })
+  ) {}
+
+  /**
+   * Whether the gopls process is running
+   */
+  isRunning(): boolean {
+    return this.child !== undefined;
+  }
+
+  /**
+   * Send a file's contents to gopls and collect the diagnostics it publishes for it
+   */
+  diagnose(filePath: string, source: string, signal?: AbortSignal): Promise<LanguageError[]> {
+    const run = this.queue.then(() => this.runDiagnose(filePath, source, signal));
+    this.queue = run.catch(() => {});
+    return run;
+  }
+
+  private async runDiagnose(filePath: string, source: string, signal?: AbortSignal): Promise<LanguageError[]> {
+    if (signal?.aborted && !isDetectorTimeout(signal)) {
+      throw cancellationError(signal);
+    }
+    await this.start();
+
+    const uri = pathToFileURL(filePath).href;
+    const version = (this.versions.get(uri) ?? 0) + 1;
+    this.published.delete(uri);
+    const diagnostics = this.waitForDiagnostics(uri, version, signal);
+
+    if (version === 1) {
+      this.notify('textDocument/didOpen', { textDocument: { uri, languageId: 'go', version, text: source } });
+    } else {
+      this.notify('textDocument/didChange', { textDocument: { uri, version }, contentChanges: [{ text: source }] });
+    }
+    this.versions.set(uri, version);
+
+    return (await diagnostics).map(diagnostic => fromLspDiagnostic(diagnostic, filePath));
+  }
+
+  /**
+   * Resolve once publishing for `uri` has gone quiet for the settle time, or at the
+   * overall deadline with whatever arrived. A detector deadline behaves the same way;
+   * any other abort rejects.
+   */
+  private waitForDiagnostics(uri: string, version: number, signal?: AbortSignal): Promise<LspDiagnostic[]> {
+    const timeoutMs = this.options.diagnosticsTimeoutMs ?? DEFAULT_DIAGNOSTICS_TIMEOUT_MS;
+    const settleMs = this.options.settleMs ?? DEFAULT_SETTLE_MS;
+
+    return new Promise<LspDiagnostic[]>((resolve, reject) => {
+      let settleTimer: NodeJS.Timeout | undefined;
+      const latest = () => {
+        const entry = this.published.get(uri);
+        return entry && (entry.version === undefined || entry.version >= version) ? entry.diagnostics : [];
+      };
+
+      const finish = (error?: Error) => {
+        clearTimeout(settleTimer);
+        clearTimeout(deadline);
+        this.listeners.delete(onPublish);
+        signal?.removeEventListener('abort', onAbort);
+        if (error) {
+          reject(error);
+        } else {
+          resolve(latest());
+        }
+      };
+
+      const onPublish = (publishedUri: string) => {
+        const entry = this.published.get(publishedUri);
+        // Publishes for an older version describe text we have since replaced
+        if (publishedUri !== uri || (entry?.version !== undefined && entry.version < version)) {
+          return;
+        }
+        clearTimeout(settleTimer);
+        settleTimer = setTimeout(() => finish(), settleMs);
+      };
+
+      const onAbort = () => {
+        if (signal && isDetectorTimeout(signal)) {
+          finish();
+        } else {
+          finish(signal ? cancellationError(signal) : new Error('gopls request aborted'));
+        }
+      };
+
+      const deadline = setTimeout(() => {
+        this.logger.warn(`gopls published no settled diagnostics for ${uriToPath(uri)} within ${timeoutMs}ms`);
+        finish();
+      }, timeoutMs);
+
+      this.listeners.add(onPublish);
+      signal?.addEventListener('abort', onAbort, { once: true });
+      if (signal?.aborted) {
+        onAbort();
+      }
+    });
+  }
+
+  private start(): Promise<void> {
+    if (!this.started) {
+      this.started = this.launch().catch(error => {
+        this.started = undefined;
+        this.child?.kill();
+        this.child = undefined;
+        if (error instanceof ToolNotFoundError) {
+          throw error;
+        }
+        throw new GoplsStartError(
+          `gopls failed to start: ${error instanceof Error ? error.message : String(error)}`,
+          this.stderr,
+          { cause: error }
+        );
+      });
+    }
+    return this.started;
+  }
+
+  private async launch(): Promise<void> {
+    const command = this.options.path || 'gopls';
+    analysisStats.recordSubprocess();
+    const child = spawn(command, [...(this.options.args || []), 'serve'], {
+      cwd: this.rootDir,
+      stdio: 'pipe',
+      ...(this.options.env && { env: { ...process.env, ...this.options.env } }),
+    });
+    this.child = child;
+    this.stderr = '';
+
+    const spawned = new Promise<void>((resolve, reject) => {
+      child.once('spawn', () => resolve());
+      child.once('error', (error: NodeJS.ErrnoException) => {
+        reject(error.code === 'ENOENT' ? new ToolNotFoundError(command) : error);
+      });
+    });
+
+    child.on('error', error => this.logger.debug('gopls process error', error));
+    // Writes to a server that just died fail with EPIPE; the exit handler reports it
+    child.stdin.on('error', () => {});
+    child.stdout.on('data', (chunk: Buffer) => {
+      for (const message of this.reader.push(chunk)) {
+        this.handleMessage(message);
+      }
+    });
+    child.stderr.on('data', (chunk: Buffer) => {
+      // Keep the tail for error messages; gopls logs freely
+      this.stderr = (this.stderr + chunk.toString()).slice(-4000);
+    });
+    child.once('exit', (code, signal) => this.onExit(child, code, signal));
+
+    await spawned;
+    const rootUri = pathToFileURL(this.rootDir).href;
+    await this.request('initialize', {
+      processId: process.pid,
+      rootUri,
+      workspaceFolders: [{ uri: rootUri, name: this.rootDir }],
+      capabilities: {
+        textDocument: {
+          synchronization: { dynamicRegistration: false },
+          publishDiagnostics: { relatedInformation: true, versionSupport: true },
+        },
+        workspace: { configuration: true, workspaceFolders: true },
+      },
+    });
+    this.notify('initialized', {});
+    this.logger.info('gopls started', { rootDir: this.rootDir, pid: child.pid });
+  }
+
+  private onExit(child: ChildProcessWithoutNullStreams, code: number | null, signal: NodeJS.Signals | null): void {
+    if (this.child !== child) {
+      return;
+    }
+
+    const reason = `gopls exited (${signal ? `signal ${signal}` : `code ${code}`})${this.stderr.trim() ? `: ${this.stderr.trim()}` : ''}`;
+    for (const pending of this.pending.values()) {
+      pending.reject(new Error(reason));
+    }
+    this.pending.clear();
+    this.child = undefined;
+    this.started = undefined;
+    // A restarted server knows nothing about our documents
+    this.versions.clear();
+    if (this.stopping) {
+      this.logger.debug(reason, { rootDir: this.rootDir });
+    } else {
+      this.logger.warn(reason, { rootDir: this.rootDir });
+    }
+  }
+
+  private handleMessage(message: LspMessage): void {
+    if (message.method === undefined && message.id !== undefined) {
+      const pending = this.pending.get(message.id);
+      if (pending) {
+        this.pending.delete(message.id);
+        if (message.error) {
+          pending.reject(new Error(`gopls: ${message.error.message}`));
+        } else {
+          pending.resolve(message.result);
+        }
+      }
+      return;
+    }
+
+    if (message.method === 'textDocument/publishDiagnostics') {
+      const params = message.params as { uri: string; version?: number; diagnostics: LspDiagnostic[] };
+      this.published.set(params.uri, {
+        ...(params.version !== undefined && { version: params.version }),
+        diagnostics: params.diagnostics || [],
+      });
+      for (const listener of this.listeners) {
+        listener(params.uri);
+      }
+      return;
+    }
+
+    // Requests from the server (configuration, progress, registrations) get default answers
+    if (message.id !== undefined) {
+      const result = message.method === 'workspace/configuration'
+        ? ((message.params as { items?: unknown[] })?.items || []).map(() => null)
+        : null;
+      this.write({ id: message.id, result });
+    }
+  }
+
+  private request(method: string, params: unknown, timeoutMs?: number): Promise<unknown> {
+    const id = this.nextId++;
+    return new Promise((resolve, reject) => {
+      const timer = timeoutMs !== undefined
+        ? setTimeout(() => {
+          this.pending.delete(id);
+          reject(new Error(`gopls did not answer ${method} within ${timeoutMs}ms`));
+        }, timeoutMs)
+        : undefined;
+      this.pending.set(id, {
+        resolve: result => {
+          clearTimeout(timer);
+          resolve(result);
+        },
+        reject: error => {
+          clearTimeout(timer);
+          reject(error);
+        },
+      });
+      this.write({ id, method, params });
+    });
+  }
+
+  private notify(method: string, params: unknown): void {
+    this.write({ method, params });
+  }
+
+  private write(message: object): void {
+    this.child?.stdin.write(encodeLspMessage(message));
+  }
+
+  /**
+   * Ask gopls to shut down, killing it if it does not exit in time
+   */
+  async shutdown(): Promise<void> {
+    const child = this.child;
+    if (!child) {
+      return;
+    }
+
+    this.stopping = true;
+    const exited = new Promise<void>(resolve => child.once('exit', () => resolve()));
+    try {
+      await this.request('shutdown', null, SHUTDOWN_TIMEOUT_MS);
+      this.notify('exit', null);
+    } catch (error) {
+      this.logger.debug('gopls shutdown request failed', error);
+    }
+
+    const killTimer = setTimeout(() => child.kill('SIGKILL'), SHUTDOWN_TIMEOUT_MS);
+    await exited;
+    clearTimeout(killTimer);
+    this.stopping = false;
+    this.published.clear();
+  }
+}
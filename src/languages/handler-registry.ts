@@ -0,0 +1,80 @@
+/**
+ * Registry of language handlers keyed by language, with file-based lookup
+ */
+
+import { basename } from 'path';
+import type { LanguageHandler, LanguageId } from '../types/languages.js';
+
+export interface HandlerRegistrationOptions {
+  /** Higher priorities run first; ties are ordered by language id */
+  priority?: number;
+}
+
+interface HandlerRegistration {
+  handler: LanguageHandler;
+  priority: number;
+}
+
+export class LanguageHandlerRegistry {
+  private registrations = new Map<LanguageId, HandlerRegistration>();
+
+  /**
+   * Register a handler, replacing any handler already registered for its language
+   */
+  register(handler: LanguageHandler, options: HandlerRegistrationOptions = {}): void {
+    this.registrations.set(handler.language, {
+      handler,
+      priority: options.priority ?? 0
+    });
+  }
+
+  unregister(language: LanguageId): LanguageHandler | undefined {
+    const registration = this.registrations.get(language);
+    this.registrations.delete(language);
+    return registration?.handler;
+  }
+
+  get(language: LanguageId): LanguageHandler | undefined {
+    return this.registrations.get(language)?.handler;
+  }
+
+  has(language: LanguageId): boolean {
+    return this.registrations.has(language);
+  }
+
+  get size(): number {
+    return this.registrations.size;
+  }
+
+  clear(): void {
+    this.registrations.clear();
+  }
+
+  /**
+   * Handlers in deterministic order: priority first, then language id.
+   * Registration order (which depends on async initialization) never matters.
+   */
+  handlers(): LanguageHandler[] {
+    return Array.from(this.registrations.values())
+      .sort((a, b) =>
+        b.priority - a.priority ||
+        (a.handler.language < b.handler.language ? -1 : a.handler.language > b.handler.language ? 1 : 0)
+      )
+      .map(registration => registration.handler);
+  }
+
+  languages(): LanguageId[] {
+    return this.handlers().map(handler => handler.language);
+  }
+
+  /**
+   * Every handler that claims the file, by extension or exact file name
+   */
+  handlersFor(filePath: string): LanguageHandler[] {
+    const fileName = basename(filePath);
+
+    return this.handlers().filter(handler =>
+      handler.isFileSupported(filePath) || (handler.getFileNames?.() || []).includes(fileName)
+    );
+  }
+}
@@ -3,23 +3,60 @@
  */
 
 export { BaseLanguageHandler } from './base-language-handler.js';
+export type { ToolchainProbe } from './base-language-handler.js';
 export { TypeScriptHandler } from './typescript-handler.js';
 export { JavaScriptHandler } from './javascript-handler.js';
 export { PythonHandler } from './python-handler.js';
-export { GoHandler } from './go-handler.js';
+export { GoHandler, isTransientGoFailure } from './go-handler.js';
 export { RustHandler } from './rust-handler.js';
 export { PHPHandler } from './php-handler.js';
+export { ClangHandler, extractCompileFlags, parseClangOutput, splitCommandLine } from './clang-handler.js';
+export type { ClangDiagnostic, ClangNote, CompileCommand } from './clang-handler.js';
+export { JavaHandler, findSourceRoot, parseJavacOutput } from './java-handler.js';
+export type { JavacDiagnostic, JavacOptions } from './java-handler.js';
+export { SHELLCHECK_SOURCE, ShellDetector, describeShellcheckFix, parseShellcheckOutput } from './shell-detector.js';
+export type { ShellcheckComment, ShellcheckReplacement } from './shell-detector.js';
+export {
+  DEFAULT_COMMAND_TIMEOUT_MS,
+  GenericCommandDetector,
+  parseCommandSeverity,
+  splitCommandTemplate
+} from './generic-command-detector.js';
 export { LanguageHandlerManager } from './language-handler-manager.js';
+export type { BatchAnalysis, BatchGroup, DetectorCapability } from './language-handler-manager.js';
+export { LanguageHandlerRegistry } from './handler-registry.js';
+export type { HandlerRegistrationOptions } from './handler-registry.js';
+export { AnalysisCache } from './analysis-cache.js';
+export type { AnalysisCacheEntry, AnalysisCacheStats } from './analysis-cache.js';
+export { CacheWarmer, WARMUP_ANALYSIS_OPTIONS, findGoModules } from './cache-warmer.js';
+export type { ModuleWarmupState, ModuleWarmupStatus, WarmupState, WarmupStatus } from './cache-warmer.js';
+export {
+  checkBuildConstraints,
+  evaluateBuildExpression,
+  hostGoBuildContext,
+  matchesFileNameConstraints
+} from './go-build-context.js';
+export type { GoBuildContext, ResolvedGoBuildContext, BuildConstraintResult } from './go-build-context.js';
+export {
+  TypeScriptProjectChecker,
+  mapTypeScriptSeverity,
+  parseTscOutput
+} from './typescript-project-checker.js';
+export type { TypeScriptDiagnostic, TypeScriptProjectCheckerOptions } from './typescript-project-checker.js';
+export { prepareSnippet, toSnippetLine } from './snippet.js';
+export type { PreparedSnippet, SnippetPosition, SnippetWrapper } from './snippet.js';
 
 // Re-export types
 export type {
   LanguageHandler,
   SupportedLanguage,
+  LanguageId,
   DetectionOptions,
   LanguageError,
   StackFrame,
   LanguageDebugCapabilities,
   LanguageDebugConfig,
   LanguageDebugSession,
-  PerformanceAnalysis
+  PerformanceAnalysis,
+  ToolchainInfo
 } from '../types/languages.js';
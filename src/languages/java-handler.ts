@@ -0,0 +1,498 @@
+/**
+ * Java language handler implementation backed by javac diagnostics
+ */
+
+import { promises as fs } from 'fs';
+import { tmpdir } from 'os';
+import { basename, delimiter, dirname, isAbsolute, join, resolve, sep } from 'path';
+import { BaseLanguageHandler, type CommandResult, type ToolchainProbe } from './base-language-handler.js';
+import type {
+  ColumnEncoding,
+  DetectionOptions,
+  LanguageError,
+  StackFrame,
+  LanguageDebugCapabilities,
+  LanguageDebugConfig,
+  LanguageDebugSession,
+  PerformanceAnalysis
+} from '../types/languages.js';
+import { SupportedLanguage } from '../types/languages.js';
+import { Logger } from '../utils/logger.js';
+import { isCancellationError } from '../utils/cancellation.js';
+import { ToolNotFoundError, isToolNotFoundError } from '../utils/errors.js';
+import { currentCommandPlan } from '../utils/command-plan.js';
+
+export interface JavacDiagnostic {
+  file: string;
+  line: number;
+  /** Recovered from the caret line; 1 when javac printed none */
+  column: number;
+  kind: 'error' | 'warning' | 'note';
+  message: string;
+  /** `-Xlint` category such as `rawtypes`, when javac names one */
+  lint?: string;
+}
+
+/**
+ * javac settings, read from the handler's `javac` option
+ */
+export interface JavacOptions {
+  /** Class path entries so references to libraries and other modules resolve */
+  classpath?: string[];
+  /** Passed as `--release` */
+  release?: string;
+}
+
+interface PackageResult {
+  diagnostics: JavacDiagnostic[];
+  /** Set when javac failed without reporting any file position */
+  failure?: CommandResult;
+}
+
+interface PackageRun {
+  startedAt: number;
+  completedAt?: number;
+  result: Promise<PackageResult>;
+}
+
+const JAVAC_LINE = /^(.+?\.java):(\d+): (error|warning|note): (.*)$/;
+const LINT_PREFIX = /^\[([\w-]+)\]\s+/;
+/** `3 errors` / `1 warning` totals printed at the end of a run */
+const TRAILER_LINE = /^\d+ (?:errors?|warnings?)$/;
+const CARET_LINE = /^\s*\^\s*$/;
+const PACKAGE_DECLARATION = /^\s*package\s+([\w.]+)\s*;/m;
+const PUBLIC_TYPE = /\bpublic\s+(?:(?:abstract|final|sealed|non-sealed|static|strictfp)\s+)*(?:class|interface|enum|record|@interface)\s+(\w+)/;
+
+/** How long a completed package run is reused for other files, in milliseconds */
+const PACKAGE_REUSE_WINDOW_MS = 2000;
+
+/**
+ * Parse javac's `file:line: kind: message` output.
+ * The column comes from the caret under the source line; indented detail lines
+ * after the caret (`symbol:`, `location:`) are appended to the message.
+ * Relative file names are resolved against the directory javac ran in.
+ */
+export function parseJavacOutput(output: string, cwd: string): JavacDiagnostic[] {
+  const diagnostics: JavacDiagnostic[] = [];
+  let current: JavacDiagnostic | undefined;
+  let afterCaret = false;
+
+  for (const rawLine of output.split('\n')) {
+    const line = rawLine.replace(/\r$/, '');
+    const match = line.match(JAVAC_LINE);
+
+    if (match) {
+      const name = match[1] || '';
+      let message = match[4] || 'Unknown error';
+      const lint = message.match(LINT_PREFIX);
+      if (lint) {
+        message = message.slice(lint[0].length);
+      }
+
+      current = {
+        file: isAbsolute(name) ? name : resolve(cwd, name),
+        line: parseInt(match[2] || '1'),
+        column: 1,
+        kind: (match[3] || 'error') as JavacDiagnostic['kind'],
+        message
+      };
+      if (lint?.[1]) {
+        current.lint = lint[1];
+      }
+      diagnostics.push(current);
+      afterCaret = false;
+    } else if (!current || TRAILER_LINE.test(line.trim())) {
+      continue;
+    } else if (!afterCaret && CARET_LINE.test(line)) {
+      current.column = line.indexOf('^') + 1;
+      afterCaret = true;
+    } else if (afterCaret && /^\s+\S/.test(line)) {
+      current.message += `\n${line.trim()}`;
+    } else if (afterCaret) {
+      current = undefined;
+    }
+  }
+
+  return diagnostics;
+}
+
+export class JavaHandler extends BaseLanguageHandler {
+  private javacPath: string | undefined;
+  private javaPath: string | undefined;
+  private packageRuns = new Map<string, PackageRun>();
+
+  constructor(options: Record<string, unknown> = {}, logger?: Logger) {
+    super(SupportedLanguage.JAVA, options, logger);
+  }
+
+  getFileExtensions(): string[] {
+    return ['.java'];
+  }
+
+  /** javac places its caret by UTF-16 char and reads a BOM as an ordinary character */
+  getColumnEncoding(): ColumnEncoding {
+    return { unit: 'utf16', includesBom: true };
+  }
+
+  getConfigFiles(): string[] {
+    return [
+      'pom.xml',
+      'build.gradle',
+      'build.gradle.kts',
+      'settings.gradle',
+      '.classpath'
+    ];
+  }
+
+  protected async doInitialize(): Promise<void> {
+    this.javacPath = await this.findExecutable('javac');
+    this.javaPath = await this.findExecutable('java');
+
+    if (!this.javacPath) {
+      throw new ToolNotFoundError('javac', 'javac not found on PATH. Please install a JDK to use Java error detection.');
+    }
+
+    this.logger.info('Java handler initialized', {
+      javacPath: this.javacPath,
+      javaPath: this.javaPath
+    });
+  }
+
+  protected async doDispose(): Promise<void> {
+    this.javacPath = undefined;
+    this.javaPath = undefined;
+    this.packageRuns.clear();
+  }
+
+  protected getToolchainProbe(): ToolchainProbe {
+    return { command: this.javacPath || 'javac', args: ['-version'] };
+  }
+
+  protected async checkAvailability(): Promise<boolean> {
+    try {
+      const result = await this.runCommand(this.javacPath || 'javac', ['-version']);
+      return result.exitCode === 0;
+    } catch {
+      return false;
+    }
+  }
+
+  async detectErrors(source: string, options?: DetectionOptions): Promise<LanguageError[]> {
+    const filePath = options?.filePath;
+
+    // Saved files are compiled together with the rest of their package
+    if (filePath && await this.isUnmodifiedOnDisk(filePath, source)) {
+      try {
+        return await this.checkPackageFile(filePath, source);
+      } catch (error) {
+        if (isToolNotFoundError(error) || isCancellationError(error)) {
+          throw error;
+        }
+        this.logger.debug('Package compilation failed, falling back to single file check', error);
+      }
+    }
+
+    return this.validateSyntax(source, filePath);
+  }
+
+  /**
+   * Get the configured javac settings
+   */
+  getJavacOptions(): JavacOptions {
+    return (this.options['javac'] || {}) as JavacOptions;
+  }
+
+  protected async validateSyntax(source: string, filePath?: string): Promise<LanguageError[]> {
+    // javac requires a public type to live in a file of the same name
+    const fileName = filePath ? basename(filePath) : `${source.match(PUBLIC_TYPE)?.[1] || 'Main'}.java`;
+    const reportedFile = filePath || fileName;
+    const tempDir = await fs.mkdtemp(join(tmpdir(), 'javac-syntax-check-'));
+    const tempFile = join(tempDir, fileName);
+
+    try {
+      await fs.writeFile(tempFile, source);
+
+      // Other sources of the original package still resolve through the source path
+      const sourceRoot = filePath && isAbsolute(filePath) ? findSourceRoot(filePath, source) : undefined;
+      const result = await this.runCommand(this.javacPath || 'javac',
+        this.javacArgs(join(tempDir, 'classes'), sourceRoot, [tempFile]),
+        { cwd: tempDir }
+      );
+
+      const diagnostics = parseJavacOutput(result.stderr + result.stdout, tempDir);
+      if (this.isUnparsedFailure(result, diagnostics)) {
+        return [this.createToolchainError('javac', result, reportedFile)];
+      }
+
+      const target = resolve(tempFile);
+      return this.convertDiagnostics(diagnostics.filter(diagnostic => diagnostic.file === target), reportedFile);
+    } catch (error) {
+      if (isToolNotFoundError(error) || isCancellationError(error)) {
+        throw error;
+      }
+      return [this.createError(
+        `Syntax validation failed: ${error instanceof Error ? error.message : 'Unknown error'}`,
+        reportedFile,
+        1,
+        1,
+        'error'
+      )];
+    } finally {
+      await fs.rm(tempDir, { recursive: true, force: true }).catch(() => {});
+    }
+  }
+
+  /**
+   * Get diagnostics for a saved file from one javac run over every `.java` file
+   * in its directory. Files of the same package analyzed around the same time share the run.
+   */
+  private async checkPackageFile(filePath: string, source: string): Promise<LanguageError[]> {
+    const target = resolve(filePath);
+    const packageDir = dirname(target);
+    const run = await this.getPackageRun(packageDir, target, findSourceRoot(target, source));
+    const { diagnostics, failure } = await run.result;
+
+    if (failure) {
+      return [this.createToolchainError('javac', failure, filePath)];
+    }
+
+    return this.convertDiagnostics(diagnostics.filter(diagnostic => diagnostic.file === target), filePath);
+  }
+
+  private async getPackageRun(packageDir: string, filePath: string, sourceRoot: string | undefined): Promise<PackageRun> {
+    // A planned run neither reuses a real one nor is reused
+    if (currentCommandPlan()) {
+      return { startedAt: Date.now(), result: this.compilePackage(packageDir, sourceRoot) };
+    }
+    const existing = this.packageRuns.get(packageDir);
+    if (existing && await this.canReuse(existing, filePath)) {
+      return existing;
+    }
+
+    const run: PackageRun = {
+      startedAt: Date.now(),
+      result: this.compilePackage(packageDir, sourceRoot)
+    };
+    this.packageRuns.set(packageDir, run);
+
+    run.result
+      .then(() => {
+        run.completedAt = Date.now();
+      })
+      .catch(() => {
+        // Failed runs are not reused
+        if (this.packageRuns.get(packageDir) === run) {
+          this.packageRuns.delete(packageDir);
+        }
+      });
+
+    return run;
+  }
+
+  private async canReuse(run: PackageRun, filePath: string): Promise<boolean> {
+    // An in-flight run already covers every file in the package
+    if (run.completedAt === undefined) {
+      return true;
+    }
+
+    if (Date.now() - run.completedAt > PACKAGE_REUSE_WINDOW_MS) {
+      return false;
+    }
+
+    // The file must not have changed since javac read it
+    try {
+      const stats = await fs.stat(filePath);
+      return stats.mtimeMs < run.startedAt;
+    } catch {
+      return false;
+    }
+  }
+
+  /**
+   * Compile every `.java` file of a package directory in one javac invocation
+   */
+  private async compilePackage(packageDir: string, sourceRoot: string | undefined): Promise<PackageResult> {
+    const entries = await fs.readdir(packageDir, { withFileTypes: true });
+    const files = entries
+      .filter(entry => entry.isFile() && entry.name.endsWith('.java'))
+      .map(entry => join(packageDir, entry.name))
+      .sort();
+
+    const outputDir = await fs.mkdtemp(join(tmpdir(), 'javac-package-'));
+    try {
+      const result = await this.runCommand(this.javacPath || 'javac',
+        this.javacArgs(outputDir, sourceRoot, files),
+        { cwd: packageDir }
+      );
+
+      const diagnostics = parseJavacOutput(result.stderr + result.stdout, packageDir);
+      return this.isUnparsedFailure(result, diagnostics)
+        ? { diagnostics, failure: result }
+        : { diagnostics };
+    } finally {
+      await fs.rm(outputDir, { recursive: true, force: true }).catch(() => {});
+    }
+  }
+
+  private javacArgs(outputDir: string, sourceRoot: string | undefined, files: string[]): string[] {
+    const { classpath = [], release } = this.getJavacOptions();
+    const args = ['-Xlint:all', '-proc:none', '-implicit:none', '-encoding', 'UTF-8', '-d', outputDir];
+
+    if (classpath.length > 0) {
+      args.push('-classpath', classpath.join(delimiter));
+    }
+    if (sourceRoot) {
+      args.push('-sourcepath', sourceRoot);
+    }
+    if (release) {
+      args.push('--release', release);
+    }
+
+    return [...args, ...files];
+  }
+
+  private convertDiagnostics(diagnostics: JavacDiagnostic[], filePath: string): LanguageError[] {
+    return diagnostics.map(diagnostic => this.createError(
+      diagnostic.message,
+      filePath,
+      diagnostic.line,
+      diagnostic.column,
+      this.mapJavacSeverity(diagnostic.kind),
+      diagnostic.lint
+    ));
+  }
+
+  private mapJavacSeverity(kind: JavacDiagnostic['kind']): 'error' | 'warning' | 'info' | 'hint' {
+    switch (kind) {
+      case 'error': return 'error';
+      case 'warning': return 'warning';
+      case 'note': return 'info';
+      default: return 'error';
+    }
+  }
+
+  parseStackTrace(stackTrace: string): StackFrame[] {
+    const frames: StackFrame[] = [];
+    const lines = stackTrace.split('\n');
+
+    for (const line of lines) {
+      // at com.example.Service.handle(Service.java:42)
+      const match = line.match(/^\s*at\s+(?:[^\s(]+\/)?([\w.$<>]+)\(([^:()]+\.java):(\d+)\)/);
+      if (match) {
+        frames.push({
+          function: match[1] || '<unknown>',
+          file: match[2] || '<unknown>',
+          line: parseInt(match[3] || '1'),
+          column: 1
+        });
+      }
+    }
+
+    return frames;
+  }
+
+  getDebugCapabilities(): LanguageDebugCapabilities {
+    return {
+      supportsBreakpoints: true,
+      supportsConditionalBreakpoints: true,
+      supportsStepInto: true,
+      supportsStepOver: true,
+      supportsStepOut: true,
+      supportsVariableInspection: true,
+      supportsWatchExpressions: true,
+      supportsHotReload: true,
+      supportsRemoteDebugging: true,
+      // Legacy properties for backward compatibility
+      breakpoints: true,
+      stepDebugging: true,
+      variableInspection: true,
+      callStackInspection: true,
+      conditionalBreakpoints: true,
+      hotReload: true,
+      profiling: true,
+      memoryInspection: true
+    };
+  }
+
+  async createDebugSession(_config: LanguageDebugConfig): Promise<LanguageDebugSession> {
+    // This would integrate with JDWP
+    throw new Error('Debug session creation not implemented yet');
+  }
+
+  async analyzePerformance(source: string): Promise<PerformanceAnalysis> {
+    const complexity = this.calculateComplexity(source);
+    return {
+      complexity,
+      suggestions: this.getPerformanceSuggestions(source),
+      metrics: {
+        linesOfCode: source.split('\n').length,
+        cyclomaticComplexity: complexity
+      }
+    };
+  }
+
+  protected getErrorPatterns(): RegExp[] {
+    return [
+      /error: (.+)/,
+      /warning: (.+)/,
+      /cannot find symbol/,
+      /incompatible types/,
+      /';' expected/
+    ];
+  }
+
+  private calculateComplexity(source: string): number {
+    const patterns = [
+      /\bif\b/g,
+      /\bfor\b/g,
+      /\bwhile\b/g,
+      /\bcase\b/g,
+      /\bcatch\b/g,
+      /&&/g,
+      /\|\|/g
+    ];
+
+    let complexity = 1;
+    for (const pattern of patterns) {
+      const matches = source.match(pattern);
+      if (matches) {
+        complexity += matches.length;
+      }
+    }
+
+    return complexity;
+  }
+
+  private getPerformanceSuggestions(source: string): string[] {
+    const suggestions: string[] = [];
+
+    if (/\bfor\b[^{]*\{[^}]*\+=\s*"/.test(source)) {
+      suggestions.push('Use a StringBuilder instead of concatenating strings in a loop');
+    }
+
+    if (/new\s+(?:Integer|Long|Double|Boolean)\s*\(/.test(source)) {
+      suggestions.push('Prefer valueOf() or autoboxing over boxed type constructors');
+    }
+
+    return suggestions;
+  }
+}
+
+/**
+ * Find the source root of a file from its `package` declaration, so other
+ * classes of the project resolve through `-sourcepath`. Returns undefined when
+ * the directory layout does not match the package.
+ */
+export function findSourceRoot(filePath: string, source: string): string | undefined {
+  const directory = dirname(resolve(filePath));
+  const packageName = source.match(PACKAGE_DECLARATION)?.[1];
+  if (!packageName) {
+    return directory;
+  }
+
+  const packagePath = packageName.split('.').join(sep);
+  if (!directory.endsWith(`${sep}${packagePath}`)) {
+    return undefined;
+  }
+  return directory.slice(0, directory.length - packagePath.length - 1) || sep;
+}
@@ -2,10 +2,10 @@
  * JavaScript language handler implementation
  */
 
-import { spawn } from 'child_process';
 import { promises as fs } from 'fs';
-import { BaseLanguageHandler } from './base-language-handler.js';
+import { BaseLanguageHandler, type ToolchainProbe } from './base-language-handler.js';
 import type {
+  ColumnEncoding,
   DetectionOptions,
   LanguageError,
   StackFrame,
@@ -16,10 +16,15 @@ import type {
 } from '../types/languages.js';
 import { SupportedLanguage } from '../types/languages.js';
 import { Logger } from '../utils/logger.js';
+import {
+  TypeScriptProjectChecker,
+  mapTypeScriptSeverity
+} from './typescript-project-checker.js';
 
 export class JavaScriptHandler extends BaseLanguageHandler {
   private eslintPath: string | undefined;
   private nodePath: string | undefined;
+  private projectChecker: TypeScriptProjectChecker | undefined;
 
   constructor(options: Record<string, unknown> = {}, logger?: Logger) {
     super(SupportedLanguage.JAVASCRIPT, options, logger);
@@ -29,6 +34,11 @@ export class JavaScriptHandler extends BaseLanguageHandler {
     return ['.js', '.jsx', '.mjs', '.cjs'];
   }
 
+  /** Columns are offsets into JavaScript strings, read with the BOM removed */
+  getColumnEncoding(): ColumnEncoding {
+    return { unit: 'utf16', includesBom: false };
+  }
+
   getConfigFiles(): string[] {
     return [
       '.eslintrc.js',
@@ -53,6 +63,15 @@ export class JavaScriptHandler extends BaseLanguageHandler {
       this.logger.warn('ESLint not found. Linting capabilities will be limited.');
     }
 
+    // tsc reports checkJs diagnostics for JavaScript files in TypeScript projects
+    const tscPath = await this.findExecutable('tsc');
+    if (tscPath) {
+      this.projectChecker = new TypeScriptProjectChecker(
+        tscPath,
+        (command, args, cwd) => this.runCommand(command, args, { cwd })
+      );
+    }
+
     this.logger.info('JavaScript handler initialized', {
       nodePath: this.nodePath,
       eslintPath: this.eslintPath
@@ -61,10 +80,15 @@ export class JavaScriptHandler extends BaseLanguageHandler {
 
   protected async doDispose(): Promise<void> {
     // Cleanup any resources
+    this.projectChecker = undefined;
     this.nodePath = undefined;
     this.eslintPath = undefined;
   }
 
+  protected getToolchainProbe(): ToolchainProbe {
+    return { command: this.nodePath || 'node', args: ['--version'] };
+  }
+
   protected async checkAvailability(): Promise<boolean> {
     try {
       const result = await this.runCommand('node', ['--version']);
@@ -81,6 +105,30 @@ export class JavaScriptHandler extends BaseLanguageHandler {
     const syntaxErrors = await this.validateSyntax(source);
     errors.push(...syntaxErrors);
 
+    // Type checking through the enclosing TypeScript project
+    const filePath = options?.filePath;
+    if (
+      syntaxErrors.length === 0 &&
+      filePath &&
+      this.projectChecker &&
+      options?.includeTypeChecking !== false &&
+      await this.isUnmodifiedOnDisk(filePath, source)
+    ) {
+      try {
+        const diagnostics = await this.projectChecker.check(filePath, options?.workspaceRoot);
+        errors.push(...diagnostics.map(diagnostic => this.createError(
+          diagnostic.message,
+          diagnostic.file,
+          diagnostic.line,
+          diagnostic.column,
+          mapTypeScriptSeverity(diagnostic.code, diagnostic.category),
+          diagnostic.code
+        )));
+      } catch (error) {
+        this.logger.debug('TypeScript project check failed', error);
+      }
+    }
+
     // ESLint validation if available
     if (this.eslintPath && options?.enableLinting !== false) {
       const lintErrors = await this.runESLint(source, options?.filePath || 'temp.js');
@@ -287,45 +335,6 @@ export class JavaScriptHandler extends BaseLanguageHandler {
     ];
   }
 
-  private async findExecutable(name: string): Promise<string | undefined> {
-    try {
-      const result = await this.runCommand('which', [name]);
-      return result.exitCode === 0 ? result.stdout.trim() : undefined;
-    } catch {
-      return undefined;
-    }
-  }
-
-  private async runCommand(command: string, args: string[]): Promise<{
-    stdout: string;
-    stderr: string;
-    exitCode: number;
-  }> {
-    return new Promise((resolve, reject) => {
-      const child = spawn(command, args, { stdio: 'pipe' });
-      let stdout = '';
-      let stderr = '';
-
-      child.stdout?.on('data', (data) => {
-        stdout += data.toString();
-      });
-
-      child.stderr?.on('data', (data) => {
-        stderr += data.toString();
-      });
-
-      child.on('close', (code) => {
-        resolve({
-          stdout,
-          stderr,
-          exitCode: code || 0
-        });
-      });
-
-      child.on('error', reject);
-    });
-  }
-
   private calculateComplexity(source: string): number {
     // Simple complexity calculation based on control structures
     const patterns = [
@@ -3,31 +3,270 @@
  */
 
 import { EventEmitter } from 'events';
+import { promises as fs } from 'fs';
+import { dirname, isAbsolute, join, relative, resolve } from 'path';
+import { AnalysisCache, type AnalysisCacheStats } from './analysis-cache.js';
+import { LanguageHandlerRegistry, type HandlerRegistrationOptions } from './handler-registry.js';
 import { TypeScriptHandler } from './typescript-handler.js';
 import { JavaScriptHandler } from './javascript-handler.js';
 import { PythonHandler } from './python-handler.js';
 import { GoHandler } from './go-handler.js';
 import { RustHandler } from './rust-handler.js';
 import { PHPHandler } from './php-handler.js';
+import { ClangHandler } from './clang-handler.js';
+import { JavaHandler } from './java-handler.js';
+import { ShellDetector } from './shell-detector.js';
+import { TOOLCHAIN_SOURCE } from './base-language-handler.js';
 import type {
+  ColumnEncoding,
   LanguageHandler,
   DetectionOptions,
-  LanguageError
+  LanguageError,
+  LanguageId,
+  RunResult,
+  ToolchainInfo
 } from '../types/languages.js';
+import type { ExecutionConfig, WorkspaceCommandsConfig } from '../types/config.js';
 import { SupportedLanguage } from '../types/languages.js';
 import { Logger } from '../utils/logger.js';
+import {
+  AnalysisCanceledError,
+  DetectorTimeoutError,
+  anySignal,
+  isCancellationError,
+  runWithSignal,
+  throwIfAborted
+} from '../utils/cancellation.js';
+import { NoFilesError, UnsupportedLanguageError, isToolNotFoundError } from '../utils/errors.js';
+import { applySuppressions, type SuppressionOptions } from '../utils/suppressions.js';
+import { WorkspaceRoots, findUpwards } from '../utils/workspace-roots.js';
+import { OverlayMirror, type Overlay } from '../utils/overlay.js';
+import { PathNormalizer, normalizeErrorPaths } from '../utils/paths.js';
+import { GenericCommandDetector, commandProgram } from './generic-command-detector.js';
+import { defaultConcurrency, mapWithConcurrency } from '../utils/worker-pool.js';
+import { toolchainCache, type ToolchainCacheStats } from '../utils/toolchain-cache.js';
+import { SingleFlight, type SingleFlightStats } from '../utils/singleflight.js';
+import { deepClone } from '../utils/helpers.js';
+import { currentRawOutputRecorder, redactTempPaths } from '../utils/raw-output.js';
+import { CommandPlan, currentCommandPlan, planCommands, type CommandPlanOptions, type PlannedCommand } from '../utils/command-plan.js';
+import { redactDetectorOptions } from '../utils/env.js';
+import { analysisStats } from '../utils/analysis-stats.js';
+import { formatMessage } from '../utils/messages.js';
+import {
+  detectUnsupportedEncoding,
+  isBinaryContent,
+  isOversized,
+  resolveMaxFileSize,
+  skippedFileDiagnostic
+} from '../utils/file-guard.js';
+import { DEFAULT_COLUMN_ENCODING, normalizeColumns } from '../utils/columns.js';
+import { compileSeverityRules, type SeverityRemap, type SeverityRule } from '../utils/severity-rules.js';
+import {
+  WorkspaceConfigLoader,
+  applyWorkspaceConfig,
+  isLanguageEnabled,
+  mergeDetectorOptions,
+  runWithDetectorOptions,
+  withoutServerOnlyOptions,
+  workspaceConfigDiagnostic,
+  type LoadedWorkspaceConfig
+} from '../utils/workspace-config.js';
+
+export const DEFAULT_DETECTOR_TIMEOUT_MS = 30_000;
+
+export const DEFAULT_RUN_TIMEOUT_MS = 30_000;
+export const DEFAULT_RUN_MAX_OUTPUT_BYTES = 1024 * 1024;
+
+/** How long `shutdown` waits for running analyses to finish after canceling them */
+export const DEFAULT_SHUTDOWN_GRACE_MS = 5000;
+
+/** How long a handler may keep running after its deadline to report partial output */
+const DETECTOR_TIMEOUT_GRACE_MS = 1000;
 
 export interface LanguageHandlerManagerConfig {
   enabledLanguages?: SupportedLanguage[];
   autoDetectLanguages?: boolean;
   defaultOptions?: Record<string, unknown>;
+  /** Inline `error-debugging:ignore` / `//nolint` handling */
+  suppressions?: SuppressionOptions;
+  /**
+   * Severity remapping by code, analyzer name or `/message regex/`; `off` drops the
+   * diagnostic. Workspace config files can override entries.
+   */
+  severityOverrides?: SeverityRemap;
+  /**
+   * Per-language analysis deadlines in milliseconds; the `default` key covers the
+   * rest (30s). 0 disables the deadline.
+   */
+  timeouts?: Record<string, number>;
+  /**
+   * Directories this session may analyze. Files are analyzed in the context of
+   * the root that contains them; paths outside every root are rejected.
+   */
+  workspaceRoots?: string[];
+  /** Files of a directory analyzed in parallel (default: the number of CPUs) */
+  concurrency?: number;
+  /** Whether and how long `runAndDetect` may execute programs */
+  execution?: ExecutionConfig;
+  /** How long toolchain paths and versions are trusted before being probed again */
+  toolchainCacheTtlMs?: number;
+  /**
+   * Shortest time between two analyses of the same file under the same settings;
+   * requests in between wait for their turn (default 0)
+   */
+  minAnalysisIntervalMs?: number;
+  /**
+   * Files larger than this many bytes are skipped with an info diagnostic
+   * (default 2 MiB, 0 for no limit); workspace config files can override it
+   */
+  maxFileSize?: number;
+  /**
+   * Analyze without network access or writes to go.mod and go.sum; workspace
+   * config files and single analyses can override it
+   */
+  offline?: boolean;
+  /**
+   * Whether the `commands` of workspace config files run, and which programs they
+   * may start. Off by default, since a cloned repository could run anything.
+   */
+  commands?: WorkspaceCommandsConfig;
   logger?: Logger;
 }
 
+/**
+ * Whether a command of a workspace config is run
+ */
+export interface WorkspaceCommandStatus {
+  name: string;
+  /** The program it starts, as written in the config */
+  program: string;
+  enabled: boolean;
+  /** Why it is skipped */
+  reason?: string;
+}
+
+/**
+ * What `runAndDetect` should execute
+ */
+export interface RunRequest {
+  /** Language whose handler builds and runs the target (default go) */
+  language?: LanguageId;
+  mode?: 'run' | 'test';
+  args?: string[];
+  /** Lowers the configured timeout; it can never raise it */
+  timeoutMs?: number;
+  signal?: AbortSignal;
+}
+
+/**
+ * How `analyzePackages` should check a set of package patterns
+ */
+export interface PackageAnalysisRequest {
+  /** Language whose handler resolves the patterns (default go) */
+  language?: LanguageId;
+  enableLinting?: boolean;
+  /** Overrides the workspace config's and the server's `offline` setting */
+  offline?: boolean;
+  signal?: AbortSignal;
+}
+
+/**
+ * Files of one batch that a detector checks together
+ */
+export interface BatchGroup {
+  language: LanguageId;
+  /** Nearest directory with one of the detector's config files, or the files' own directory */
+  directory: string;
+  files: string[];
+}
+
+/**
+ * A file an editor reported as changed, for `analyzeChange`
+ */
+export interface ChangeRequest {
+  /** Unsaved contents of the file; without it the file on disk is analyzed */
+  content?: string;
+  /** Return the diagnostics of every file of the package, not only the changed one */
+  allFiles?: boolean;
+}
+
+export interface ChangeAnalysis {
+  /** Directory whose files make up the changed file's package */
+  packageDir: string;
+  /** The other files of the package */
+  siblings: string[];
+  /** Whether the change can alter the diagnostics of the other files */
+  siblingsAffected: boolean;
+  /** Other files whose cached results were dropped because of the change */
+  invalidated: string[];
+  errors: LanguageError[];
+}
+
+/**
+ * What analyzing one file would do, from `planAnalysis`
+ */
+export interface FilePlan {
+  file: string;
+  /** Detectors selected for the file, in the order they would run */
+  detectors: LanguageId[];
+  /** Commands the detectors would execute, in order */
+  commands: PlannedCommand[];
+  /** Diagnostics reported without running a tool, such as for a skipped file */
+  diagnostics: LanguageError[];
+}
+
+export interface BatchAnalysis {
+  /** Errors of each requested path, in request order */
+  results: Array<{ path: string; errors: LanguageError[] }>;
+  groups: BatchGroup[];
+}
+
+/**
+ * What one detector can do in the current environment
+ */
+export interface DetectorCapability extends ToolchainInfo {
+  language: LanguageId;
+  /** Whether a handler is registered and serving requests */
+  registered: boolean;
+  /** False while the detector is switched off with `setDetectorEnabled` */
+  detectorEnabled: boolean;
+  extensions: string[];
+  fileNames: string[];
+  config: {
+    timeoutMs: number;
+    /** Handler options, including those of the workspace config when a path was given */
+    options: Record<string, unknown>;
+    /** False when the workspace config leaves the language out of `enabledLanguages` */
+    enabled: boolean;
+  };
+}
+
 export class LanguageHandlerManager extends EventEmitter {
-  private handlers = new Map<SupportedLanguage, LanguageHandler>();
+  private handlers = new LanguageHandlerRegistry();
   private logger: Logger;
   private config: LanguageHandlerManagerConfig;
+  private cache = new AnalysisCache();
+  private workspaceRoots: WorkspaceRoots;
+  /** Why enabled built-in languages have no registered handler */
+  private unavailableReasons = new Map<LanguageId, string>();
+  private workspaceConfigs = new WorkspaceConfigLoader();
+  private severityRules: SeverityRule[];
+  /**
+   * Detectors switched off at runtime. Replaced rather than mutated, so a call
+   * that took a reference keeps seeing the state it started with.
+   */
+  private disabledDetectors: ReadonlySet<LanguageId> = new Set();
+  /** Aborted by `shutdown`; every detection and run observes it */
+  private shutdownController = new AbortController();
+  private inFlight = new Set<Promise<unknown>>();
+  /** Detectors built from workspace config `commands`, by config file and definition */
+  private commandDetectors = new Map<string, GenericCommandDetector>();
+  /** Analyses of files on disk in flight, shared by identical requests */
+  private analyses: SingleFlight<LanguageError[]>;
+  /** Hash of the package surface of each changed file as last analyzed on disk */
+  private changeSurfaces = new Map<string, string>();
+  /** Hash of the package surface of files read as siblings, with the size and mtime it was read at */
+  private siblingSurfaces = new Map<string, { size: number; mtimeMs: number; hash: string }>();
 
   constructor(config: LanguageHandlerManagerConfig = {}) {
     super();
@@ -38,7 +277,10 @@ export class LanguageHandlerManager extends EventEmitter {
         SupportedLanguage.PYTHON,
         SupportedLanguage.GO,
         SupportedLanguage.RUST,
-        SupportedLanguage.PHP
+        SupportedLanguage.PHP,
+        SupportedLanguage.CPP,
+        SupportedLanguage.JAVA,
+        SupportedLanguage.SHELL
       ],
       autoDetectLanguages: true,
       ...config
@@ -47,6 +289,19 @@ export class LanguageHandlerManager extends EventEmitter {
       logFile: undefined,
       enableConsole: false // Default to disabled to avoid MCP protocol interference
     });
+    this.workspaceRoots = new WorkspaceRoots(config.workspaceRoots);
+    this.severityRules = compileSeverityRules(config.severityOverrides);
+    this.analyses = new SingleFlight(config.minAnalysisIntervalMs);
+    if (config.toolchainCacheTtlMs !== undefined) {
+      toolchainCache.setTtl(config.toolchainCacheTtlMs);
+    }
+  }
+
+  /**
+   * Get the configured workspace roots
+   */
+  getWorkspaceRoots(): WorkspaceRoots {
+    return this.workspaceRoots;
   }
 
   /**
@@ -77,24 +332,61 @@ export class LanguageHandlerManager extends EventEmitter {
       
       // Check if the language tools are available
       if (await handler.isAvailable()) {
-        await handler.initialize();
-        this.handlers.set(language, handler);
-        
-        // Forward events
-        handler.on('error', (error) => {
-          this.emit('handlerError', language, error);
-        });
-        
+        await this.addHandler(handler);
+        this.unavailableReasons.delete(language);
         this.logger.info(`Initialized ${language} handler`);
       } else {
+        this.unavailableReasons.set(language, `${language} tools not available`);
         this.logger.warn(`${language} tools not available, skipping handler initialization`);
       }
     } catch (error) {
+      this.unavailableReasons.set(language, error instanceof Error ? error.message : String(error));
       this.logger.error(`Failed to initialize ${language} handler`, error);
       this.emit('handlerError', language, error);
     }
   }
 
+  /**
+   * Register a custom handler alongside the built-in ones.
+   * A handler registered under an existing language replaces it.
+   */
+  async registerHandler(handler: LanguageHandler, options: HandlerRegistrationOptions = {}): Promise<void> {
+    const existing = this.handlers.get(handler.language);
+    if (existing && existing !== handler) {
+      await existing.dispose();
+    }
+
+    await this.addHandler(handler, options);
+    this.cache.clear();
+    this.logger.info(`Registered ${handler.language} handler`);
+    this.emit('handlerRegistered', handler.language);
+  }
+
+  /**
+   * Remove and dispose a handler
+   */
+  async unregisterHandler(language: LanguageId): Promise<boolean> {
+    const handler = this.handlers.unregister(language);
+    if (!handler) {
+      return false;
+    }
+
+    await handler.dispose();
+    this.cache.clear();
+    this.emit('handlerUnregistered', language);
+    return true;
+  }
+
+  private async addHandler(handler: LanguageHandler, options: HandlerRegistrationOptions = {}): Promise<void> {
+    await handler.initialize();
+    this.handlers.register(handler, options);
+
+    // Forward events
+    handler.on('error', (error) => {
+      this.emit('handlerError', handler.language, error);
+    });
+  }
+
   /**
    * Create a language handler instance
    */
@@ -114,40 +406,159 @@ export class LanguageHandlerManager extends EventEmitter {
         return new RustHandler(options, this.logger);
       case SupportedLanguage.PHP:
         return new PHPHandler(options, this.logger);
+      case SupportedLanguage.CPP:
+        return new ClangHandler(options, this.logger);
+      case SupportedLanguage.JAVA:
+        return new JavaHandler(options, this.logger);
+      case SupportedLanguage.SHELL:
+        return new ShellDetector(options, this.logger);
       default:
-        throw new Error(`Unsupported language: ${language}`);
+        throw new UnsupportedLanguageError(language);
     }
   }
 
   /**
    * Get a language handler by language
    */
-  getHandler(language: SupportedLanguage): LanguageHandler | undefined {
+  getHandler(language: LanguageId): LanguageHandler | undefined {
     return this.handlers.get(language);
   }
 
   /**
-   * Get all available handlers
+   * Get all available handlers, in registry order
    */
-  getAvailableHandlers(): Map<SupportedLanguage, LanguageHandler> {
-    return new Map(this.handlers);
+  getAvailableHandlers(): Map<LanguageId, LanguageHandler> {
+    return new Map(this.handlers.handlers().map(handler => [handler.language, handler]));
   }
 
   /**
-   * Detect language from file path
+   * Load the workspace config file that applies to a path
    */
-  detectLanguage(filePath: string): SupportedLanguage | undefined {
-    if (!this.config.autoDetectLanguages) {
+  async getWorkspaceConfig(targetPath: string): Promise<LoadedWorkspaceConfig> {
+    const fullPath = resolve(targetPath);
+    return this.workspaceConfigs.load(fullPath, this.workspaceRoots.requireRoot(fullPath));
+  }
+
+  /**
+   * Report, for every enabled and registered language, whether its toolchain is
+   * installed and which settings apply. Toolchains are probed in parallel and a
+   * probe that fails only marks its own language unavailable. With a path, the
+   * settings include those of the workspace config that applies to it.
+   */
+  async getCapabilities(targetPath?: string): Promise<DetectorCapability[]> {
+    const workspaceConfig = targetPath ? (await this.getWorkspaceConfig(targetPath)).config : {};
+    const registered = this.handlers.handlers();
+    const registeredLanguages = new Set(registered.map(handler => handler.language));
+    const missing = (this.config.enabledLanguages || []).filter(language => !registeredLanguages.has(language));
+    const candidates: Array<{ handler: LanguageHandler | undefined; language: LanguageId; registered: boolean }> = [
+      ...registered.map(handler => ({ handler, language: handler.language, registered: true })),
+      ...missing.map(language => ({ handler: this.tryCreateHandler(language), language, registered: false }))
+    ];
+
+    const disabled = this.disabledDetectors;
+    return Promise.all(candidates.map(async ({ handler, language, registered: isRegistered }) => {
+      // A disabled detector is not probed either
+      const toolchain: ToolchainInfo = disabled.has(language)
+        ? { available: false, tool: language, reason: `The ${language} detector is disabled` }
+        : await this.probeToolchain(handler, language);
+      const capability: DetectorCapability = {
+        language,
+        registered: isRegistered,
+        detectorEnabled: !disabled.has(language),
+        ...toolchain,
+        extensions: handler?.getFileExtensions() || [],
+        fileNames: handler?.getFileNames?.() || [],
+        config: {
+          timeoutMs: this.getDetectorTimeout(language),
+          options: redactDetectorOptions(mergeDetectorOptions(this.config.defaultOptions || {}, workspaceConfig.detectors?.[language])),
+          enabled: isLanguageEnabled(workspaceConfig, language)
+        }
+      };
+
+      // A toolchain can be installed while the handler still failed to start
+      const reason = this.unavailableReasons.get(language);
+      if (!isRegistered && capability.available && reason) {
+        capability.available = false;
+        capability.reason = reason;
+      }
+      return capability;
+    }));
+  }
+
+  /**
+   * Switch a detector on or off for analyses started from now on; calls already
+   * in flight finish with the state they started with. Returns whether the state
+   * changed. Languages that neither have a handler nor are built in are rejected.
+   */
+  setDetectorEnabled(language: LanguageId, enabled: boolean): boolean {
+    const known = this.handlers.get(language) !== undefined ||
+      (Object.values(SupportedLanguage) as string[]).includes(language);
+    if (!known) {
+      throw new UnsupportedLanguageError(language, `Unknown language: ${language}`);
+    }
+    if (this.disabledDetectors.has(language) === !enabled) {
+      return false;
+    }
+
+    const disabled = new Set(this.disabledDetectors);
+    if (enabled) {
+      disabled.delete(language);
+    } else {
+      disabled.add(language);
+    }
+    this.disabledDetectors = disabled;
+    this.logger.info(`${enabled ? 'Enabled' : 'Disabled'} the ${language} detector`);
+    this.emit('detectorToggled', language, enabled);
+    return true;
+  }
+
+  /**
+   * Whether a detector takes part in analyses, i.e. was not switched off at runtime
+   */
+  isDetectorEnabled(language: LanguageId): boolean {
+    return !this.disabledDetectors.has(language);
+  }
+
+  private tryCreateHandler(language: LanguageId): LanguageHandler | undefined {
+    try {
+      return this.createHandler(language as SupportedLanguage);
+    } catch {
       return undefined;
     }
+  }
+
+  private async probeToolchain(handler: LanguageHandler | undefined, language: LanguageId): Promise<ToolchainInfo> {
+    if (!handler) {
+      return { available: false, tool: language, reason: `Unsupported language: ${language}` };
+    }
 
-    for (const [language, handler] of this.handlers) {
-      if (handler.isFileSupported(filePath)) {
-        return language;
+    try {
+      if (handler.getToolchainInfo) {
+        return await handler.getToolchainInfo();
       }
+      const available = await handler.isAvailable();
+      return { available, tool: language, ...(!available && { reason: `${language} tools not available` }) };
+    } catch (error) {
+      return { available: false, tool: language, reason: error instanceof Error ? error.message : String(error) };
+    }
+  }
+
+  /**
+   * Detect language from file path
+   */
+  detectLanguage(filePath: string): LanguageId | undefined {
+    return this.detectLanguages(filePath)[0];
+  }
+
+  /**
+   * Detect every language whose handler claims the file, in registry order
+   */
+  detectLanguages(filePath: string): LanguageId[] {
+    if (!this.config.autoDetectLanguages) {
+      return [];
     }
 
-    return undefined;
+    return this.handlers.handlersFor(filePath).map(handler => handler.language);
   }
 
   /**
@@ -155,9 +566,12 @@ export class LanguageHandlerManager extends EventEmitter {
    */
   async detectErrors(
     source: string,
-    language?: SupportedLanguage,
+    language?: LanguageId,
     options?: DetectionOptions
   ): Promise<LanguageError[]> {
+    // Refuse new work after shutdown rather than report a clean file
+    throwIfAborted(this.shutdownController.signal);
+
     // Auto-detect language if not provided
     if (!language && options?.filePath) {
       language = this.detectLanguage(options.filePath);
@@ -167,6 +581,10 @@ export class LanguageHandlerManager extends EventEmitter {
       this.logger.warn('No language specified or detected for error detection');
       return [];
     }
+    if (this.disabledDetectors.has(language)) {
+      this.logger.debug(`Skipping detection: the ${language} detector is disabled`);
+      return [];
+    }
 
     const handler = this.handlers.get(language);
     if (!handler) {
@@ -174,21 +592,1057 @@ export class LanguageHandlerManager extends EventEmitter {
       return [];
     }
 
+    const workspaceRoot = options?.filePath ? this.workspaceRoots.requireRoot(options.filePath) : undefined;
+    const detectionOptions: DetectionOptions = { ...options, ...(workspaceRoot && { workspaceRoot }) };
+    analysisStats.recordAnalysis();
+    const workspaceConfig: LoadedWorkspaceConfig = options?.filePath
+      ? await this.workspaceConfigs.load(options.filePath, workspaceRoot)
+      : { config: {} };
+
     try {
-      const errors = await handler.detectErrors(source, options);
+      const { errors: detected } = await runWithDetectorOptions(
+        this.detectorOptions(language, workspaceConfig, options?.offline),
+        () => this.runDetection(handler, source, detectionOptions)
+      );
+      const configError = workspaceConfigDiagnostic(workspaceConfig);
+      const suppressed = [
+        ...applyWorkspaceConfig(
+          applySuppressions(
+            normalizeColumns(detected, source, this.columnEncoding(handler), options?.filePath),
+            source,
+            this.config.suppressions,
+            options?.filePath
+          ),
+          workspaceConfig.config,
+          this.severityRules
+        ),
+        ...(configError ? [configError] : [])
+      ];
+      const errors = options?.filePath
+        ? await normalizeErrorPaths(suppressed, this.createPathNormalizer(resolve(options.filePath), workspaceRoot))
+        : suppressed;
       this.emit('errorsDetected', language, errors);
       return errors;
     } catch (error) {
+      // Cancellation and missing toolchains are reported to the caller, not swallowed
+      if (isCancellationError(error) || isToolNotFoundError(error)) {
+        throw error;
+      }
       this.logger.error(`Error detection failed for ${language}`, error);
       this.emit('detectionError', language, error);
       return [];
     }
   }
 
+  /**
+   * Detect errors in a file on disk, reusing cached results for unchanged files.
+   * Entries are keyed by the SHA-256 of the file contents and its mtime, and are
+   * invalidated whenever the detection options or process environment change.
+   * Without an explicit language, every handler that claims the file and that the
+   * workspace config enables runs, and their results are concatenated in registry order.
+   */
+  async analyzeFile(
+    filePath: string,
+    language?: LanguageId,
+    options: DetectionOptions = {}
+  ): Promise<LanguageError[]> {
+    throwIfAborted(this.shutdownController.signal);
+    const fullPath = resolve(filePath);
+    return this.analyzeResolvedFile(fullPath, this.workspaceRoots.requireRoot(fullPath), language, options, {
+      cacheable: true,
+      disabled: this.disabledDetectors
+    });
+  }
+
+  private async analyzeResolvedFile(
+    fullPath: string,
+    workspaceRoot: string | undefined,
+    language: LanguageId | undefined,
+    options: DetectionOptions,
+    run: { cacheable: boolean; disabled: ReadonlySet<LanguageId> }
+  ): Promise<LanguageError[]> {
+    const { disabled } = run;
+    const cacheable = run.cacheable && !options.includeRaw;
+    if (language && disabled.has(language)) {
+      this.logger.debug(`Skipping ${fullPath}: the ${language} detector is disabled`);
+      return [];
+    }
+
+    const workspaceConfig = await this.workspaceConfigs.load(fullPath, workspaceRoot);
+    // An explicitly requested language runs even if the config file disables it
+    const languages = language
+      ? [language]
+      : this.detectLanguages(fullPath)
+        .filter(id => isLanguageEnabled(workspaceConfig.config, id) && !disabled.has(id));
+    const commands = this.getCommandDetectors(workspaceConfig, workspaceRoot)
+      .filter(detector => (language ? detector.language === language : !disabled.has(detector.language)) &&
+        detector.isFileSupported(fullPath));
+
+    if (languages.length === 0 && commands.length === 0) {
+      this.logger.warn(`No language detected for file: ${fullPath}`);
+      return [];
+    }
+
+    const handlers = [
+      ...languages
+        .map(id => this.handlers.get(id))
+        .filter((handler): handler is LanguageHandler => handler !== undefined),
+      ...commands
+    ];
+    if (handlers.length === 0) {
+      this.logger.warn(`No handler available for language: ${languages.join(', ')}`);
+      return [];
+    }
+    const plan = currentCommandPlan();
+    plan?.selectDetectors(handlers.map(handler => handler.language));
+
+    // Oversized and binary files never reach a detector; the rest of a directory is still analyzed
+    const stats = await fs.stat(fullPath);
+    const maxFileSize = resolveMaxFileSize(workspaceConfig.config.maxFileSize, this.config.maxFileSize);
+    if (isOversized(stats.size, maxFileSize)) {
+      this.logger.info(`Skipping ${fullPath}: ${stats.size} bytes is over the ${maxFileSize} byte limit`);
+      return [skippedFileDiagnostic(fullPath, { kind: 'too-large', size: stats.size, maxFileSize })];
+    }
+    const content = await fs.readFile(fullPath);
+    const encoding = detectUnsupportedEncoding(content);
+    if (encoding) {
+      this.logger.debug(`Skipping ${fullPath}: encoded as ${encoding}`);
+      return [skippedFileDiagnostic(fullPath, { kind: 'encoding', encoding })];
+    }
+    if (isBinaryContent(content)) {
+      this.logger.debug(`Skipping ${fullPath}: binary file`);
+      return [skippedFileDiagnostic(fullPath, { kind: 'binary' })];
+    }
+    const source = content.toString('utf-8');
+    if (!plan) {
+      analysisStats.recordAnalysis();
+    }
+
+    // Handlers look for go.mod, Cargo.toml, tsconfig.json... no higher than the owning root
+    const detectionOptions: DetectionOptions = {
+      ...options,
+      filePath: fullPath,
+      ...(workspaceRoot && { workspaceRoot })
+    };
+    // The abort signal does not affect results, so keep it out of the fingerprint
+    const cacheableOptions: DetectionOptions = { ...detectionOptions };
+    delete cacheableOptions.signal;
+    const contentHash = AnalysisCache.hashContent(source);
+    const packageInputs = cacheable ? await this.packageInputsDigest(fullPath, handlers, workspaceRoot) : undefined;
+    const fingerprint = AnalysisCache.fingerprint({
+      languages: handlers.map(handler => handler.language),
+      options: cacheableOptions,
+      defaultOptions: this.config.defaultOptions || {},
+      workspaceConfig,
+      packageInputs
+    });
+
+    // Taken before the handlers run, so an invalidation while they do keeps their result out
+    const generation = this.cache.generation(fullPath);
+    const cached = cacheable ? this.cache.get(fullPath, contentHash, stats.mtimeMs, fingerprint) : undefined;
+    if (cached) {
+      this.logger.debug(`Analysis cache hit for ${fullPath}`, { languages });
+      return cached;
+    }
+
+    // Identical requests made while this one runs share its result. The content
+    // hash and the cache generation are part of the key, so a request for a newer
+    // version, or made after the file's results were invalidated, gets its own run.
+    const detect = async (signal?: AbortSignal): Promise<LanguageError[]> => {
+      const { errors, failed } = await this.runHandlers(handlers, source, {
+        fullPath,
+        workspaceConfig,
+        options: { ...detectionOptions, ...(signal && { signal }) },
+        normalizer: this.createPathNormalizer(fullPath, workspaceRoot)
+      });
+      // Partial results are not cached so a failed or timed-out handler is retried next time
+      if (cacheable && !failed) {
+        this.cache.set(fullPath, contentHash, stats.mtimeMs, fingerprint, errors, generation);
+      }
+      return errors;
+    };
+    if (!cacheable) {
+      return detect(options.signal);
+    }
+    return deepClone(await this.analyses.run(
+      JSON.stringify([fullPath, contentHash, stats.mtimeMs, fingerprint, generation]),
+      options.signal,
+      detect,
+      JSON.stringify([fullPath, fingerprint])
+    ));
+  }
+
+  /**
+   * Digest of the other files a file's diagnostics depend on, for handlers that
+   * compile its package as a whole: the package surface of each file of its
+   * directory they support, and the manifests next to the nearest one, such as
+   * go.mod and go.sum. A body edit in a sibling keeps the digest, like in
+   * `analyzeChange`; manifests count by size and modification time.
+   */
+  private async packageInputsDigest(
+    fullPath: string,
+    handlers: LanguageHandler[],
+    workspaceRoot?: string
+  ): Promise<string | undefined> {
+    const packaged = handlers.filter(handler => handler.getPackageSurface);
+    if (packaged.length === 0) {
+      return undefined;
+    }
+
+    const directory = dirname(fullPath);
+    const entries = (await fs.readdir(directory).catch(() => [])).map(name => join(directory, name)).sort();
+    const inputs: unknown[] = [];
+    for (const handler of packaged) {
+      for (const file of entries.filter(entry => entry !== fullPath && handler.isFileSupported(entry))) {
+        inputs.push([file, await this.siblingSurface(handler, file)]);
+      }
+      const manifest = await findUpwards(fullPath, handler.getFileNames?.() || handler.getConfigFiles(), workspaceRoot);
+      for (const file of manifest ? handler.getConfigFiles().map(name => join(dirname(manifest), name)) : []) {
+        const stats = await fs.stat(file).catch(() => undefined);
+        inputs.push(stats?.isFile() ? [file, stats.size, stats.mtimeMs] : [file]);
+      }
+    }
+    return AnalysisCache.hashContent(JSON.stringify(inputs));
+  }
+
+  /**
+   * Hash of the package surface of a file on disk, read again only when its
+   * size or modification time changed
+   */
+  private async siblingSurface(handler: LanguageHandler, file: string): Promise<string | undefined> {
+    const stats = await fs.stat(file).catch(() => undefined);
+    if (!stats?.isFile()) {
+      this.siblingSurfaces.delete(file);
+      return undefined;
+    }
+    const known = this.siblingSurfaces.get(file);
+    if (known && known.size === stats.size && known.mtimeMs === stats.mtimeMs) {
+      return known.hash;
+    }
+
+    const source = await fs.readFile(file, 'utf-8').catch(() => undefined);
+    if (source === undefined) {
+      return undefined;
+    }
+    const hash = AnalysisCache.hashContent(handler.getPackageSurface!(source));
+    this.siblingSurfaces.set(file, { size: stats.size, mtimeMs: stats.mtimeMs, hash });
+    return hash;
+  }
+
+  /**
+   * Run each handler on a file and post-process its results. `failed` is set when
+   * a handler crashed or timed out, so the result is incomplete.
+   */
+  private async runHandlers(
+    handlers: LanguageHandler[],
+    source: string,
+    context: {
+      fullPath: string;
+      workspaceConfig: LoadedWorkspaceConfig;
+      options: DetectionOptions;
+      normalizer: PathNormalizer;
+    }
+  ): Promise<{ errors: LanguageError[]; failed: boolean }> {
+    const { fullPath, workspaceConfig, options, normalizer } = context;
+    const errors: LanguageError[] = [];
+    let failed = false;
+
+    for (const handler of handlers) {
+      try {
+        const run = await runWithDetectorOptions(
+          this.detectorOptions(handler.language, workspaceConfig, options.offline),
+          () => this.runDetection(handler, source, options)
+        );
+        const handlerErrors = await normalizeErrorPaths(
+          applyWorkspaceConfig(
+            applySuppressions(
+              normalizeColumns(run.errors, source, this.columnEncoding(handler), fullPath),
+              source,
+              this.config.suppressions,
+              fullPath
+            ),
+            workspaceConfig.config,
+            this.severityRules
+          ),
+          normalizer
+        );
+        if (run.timedOut) {
+          failed = true;
+        }
+        // A plan ran no tool, so it has no results to announce
+        if (!currentCommandPlan()) {
+          this.emit('errorsDetected', handler.language, handlerErrors);
+        }
+        errors.push(...handlerErrors);
+      } catch (error) {
+        // Cancellation and missing toolchains are reported to the caller, not swallowed
+        if (isCancellationError(error) || isToolNotFoundError(error)) {
+          throw error;
+        }
+        // One failing handler does not discard the others' results
+        failed = true;
+        this.logger.error(`Error detection failed for ${handler.language}`, error);
+        this.emit('detectionError', handler.language, error);
+        errors.push(this.createCrashError(`${handler.language} detector`, fullPath, error));
+      }
+    }
+
+    // A broken config file is reported alongside the results it was ignored for
+    const configError = workspaceConfigDiagnostic(workspaceConfig);
+    if (configError) {
+      errors.push(configError);
+    }
+
+    return { errors, failed };
+  }
+
+  /**
+   * Detect errors in a file or in every supported file below a directory. Files are
+   * analyzed by a bounded pool of workers and results are returned in file order.
+   * A file whose analysis crashes yields a toolchain diagnostic instead of failing
+   * the whole run. A directory without any supported file throws NoFilesError.
+   *
+   * An overlay replaces the contents of the given files, keyed by path relative to
+   * the target directory or absolute, without touching them on disk: the workspace
+   * is mirrored into a temporary directory with the overlay written in, analyzed
+   * there so compilers see the unsaved contents, and diagnostics are mapped back
+   * to the original paths. Overlay results are never cached.
+   */
+  async analyzePath(targetPath: string, options: DetectionOptions = {}, overlay?: Overlay): Promise<LanguageError[]> {
+    throwIfAborted(this.shutdownController.signal);
+    const fullPath = resolve(targetPath);
+    const workspaceRoot = this.workspaceRoots.requireRoot(fullPath);
+    if (overlay && Object.keys(overlay).length > 0) {
+      return this.analyzeWithOverlay(fullPath, workspaceRoot, options, overlay);
+    }
+
+    // Detectors toggled while the files are analyzed only affect later calls
+    const disabled = this.disabledDetectors;
+    const stats = await fs.stat(fullPath);
+    const files = stats.isDirectory() ? await this.findSupportedFiles(fullPath) : [fullPath];
+    if (files.length === 0) {
+      throw new NoFilesError(fullPath);
+    }
+    const results = await this.analyzeFiles(files, options, file =>
+      this.analyzeResolvedFile(file, this.workspaceRoots.requireRoot(file), undefined, options, { cacheable: true, disabled })
+    );
+    return results.flat();
+  }
+
+  /**
+   * Work out what analyzing a file, or every supported file below a directory,
+   * would do without running any tool: the detectors selected for each file and
+   * the commands they would execute. Files go through the same selection,
+   * workspace config and file guards as `analyzePath`; each detector then runs
+   * with its commands recorded instead of spawned and answered as clean runs
+   * without output, so fallbacks taken after a failing command are not planned.
+   * The analysis cache is ignored, and temporary directories become `<tmp>`.
+   */
+  async planAnalysis(
+    targetPath: string,
+    options: DetectionOptions = {},
+    planOptions: CommandPlanOptions = {}
+  ): Promise<FilePlan[]> {
+    throwIfAborted(this.shutdownController.signal);
+    const fullPath = resolve(targetPath);
+    this.workspaceRoots.requireRoot(fullPath);
+
+    const disabled = this.disabledDetectors;
+    const stats = await fs.stat(fullPath);
+    const files = stats.isDirectory() ? await this.findSupportedFiles(fullPath) : [fullPath];
+    if (files.length === 0) {
+      throw new NoFilesError(fullPath);
+    }
+
+    const roots = this.workspaceRoots.list();
+    const plans: FilePlan[] = [];
+    for (const file of files) {
+      const plan = new CommandPlan(planOptions);
+      const diagnostics = await planCommands(plan, () =>
+        this.analyzeResolvedFile(file, this.workspaceRoots.requireRoot(file), undefined, options, { cacheable: false, disabled })
+      );
+      plans.push({
+        file,
+        detectors: plan.getDetectors(),
+        commands: plan.getCommands().map(command => ({
+          ...command,
+          args: command.args.map(arg => redactTempPaths(arg, roots)),
+          cwd: redactTempPaths(command.cwd, roots)
+        })),
+        diagnostics
+      });
+    }
+    return plans;
+  }
+
+  /**
+   * Detect errors in several files or directories in one call, returning the
+   * errors of each path in input order. Files are grouped by detector and by the
+   * nearest directory holding one of its config files (go.mod, tsconfig.json,
+   * Cargo.toml...) and each group is started together, so detectors that check a
+   * whole module or package at once run once for the group instead of once per
+   * file. Every tool still runs in its own module's directory.
+   */
+  async analyzeBatch(paths: string[], options: DetectionOptions = {}): Promise<BatchAnalysis> {
+    throwIfAborted(this.shutdownController.signal);
+    const disabled = this.disabledDetectors;
+    const targets = paths.map(path => {
+      const fullPath = resolve(path);
+      this.workspaceRoots.requireRoot(fullPath);
+      return fullPath;
+    });
+    const filesByTarget = await Promise.all(targets.map(async fullPath => {
+      const stats = await fs.stat(fullPath);
+      return stats.isDirectory() ? this.findSupportedFiles(fullPath) : [fullPath];
+    }));
+
+    const groups = new Map<string, BatchGroup>();
+    const ungrouped: string[] = [];
+    for (const file of new Set(filesByTarget.flat())) {
+      const language = this.detectLanguages(file).find(id => !disabled.has(id));
+      const handler = language ? this.handlers.get(language) : undefined;
+      if (!language || !handler) {
+        ungrouped.push(file);
+        continue;
+      }
+
+      const marker = await findUpwards(file, handler.getConfigFiles(), this.workspaceRoots.requireRoot(file));
+      const directory = marker ? dirname(marker) : dirname(file);
+      const key = JSON.stringify([language, directory]);
+      const group = groups.get(key) || { language, directory, files: [] };
+      group.files.push(file);
+      groups.set(key, group);
+    }
+
+    // Files of a group are adjacent, so they run together and share project-wide checks
+    const ordered = [...Array.from(groups.values()).flatMap(group => group.files), ...ungrouped];
+    const results = await this.analyzeFiles(ordered, options, file =>
+      this.analyzeResolvedFile(file, this.workspaceRoots.requireRoot(file), undefined, options, { cacheable: true, disabled })
+    );
+    const byFile = new Map(ordered.map((file, index) => [file, results[index]!]));
+
+    return {
+      results: paths.map((path, index) => ({
+        path,
+        errors: filesByTarget[index]!.flatMap(file => byFile.get(file) || [])
+      })),
+      groups: Array.from(groups.values())
+    };
+  }
+
+  /**
+   * Analyze a file an editor reported as changed, scoped to its package: the
+   * files of the same language in its directory. Results of the other files are
+   * reused from the cache unless the change can alter them, which the handler
+   * decides by comparing the file's package surface before and after (any
+   * change counts for handlers without one). Saved changes are compared with
+   * the version this method last saw, and drop the cached results of affected
+   * files; unsaved `content` is compared with the file on disk and analyzed as
+   * an overlay, together with the affected files when all are requested.
+   */
+  async analyzeChange(filePath: string, request: ChangeRequest = {}, options: DetectionOptions = {}): Promise<ChangeAnalysis> {
+    throwIfAborted(this.shutdownController.signal);
+    const fullPath = resolve(filePath);
+    const workspaceRoot = this.workspaceRoots.requireRoot(fullPath);
+    const disabled = this.disabledDetectors;
+    const packageDir = dirname(fullPath);
+    const language = this.detectLanguages(fullPath).find(id => !disabled.has(id));
+    const handler = language ? this.handlers.get(language) : undefined;
+
+    const entries = await fs.readdir(packageDir, { withFileTypes: true });
+    const siblings = handler
+      ? entries
+        .filter(entry => entry.isFile())
+        .map(entry => join(packageDir, entry.name))
+        .filter(file => file !== fullPath && handler.isFileSupported(file))
+        .sort()
+      : [];
+
+    const surfaceHash = (source: string) =>
+      AnalysisCache.hashContent(handler?.getPackageSurface ? handler.getPackageSurface(source) : source);
+    const onDisk = await fs.readFile(fullPath, 'utf-8').catch(() => undefined);
+    const unsaved = request.content !== undefined;
+    const before = unsaved
+      ? (onDisk !== undefined ? surfaceHash(onDisk) : undefined)
+      : this.changeSurfaces.get(fullPath);
+    const current = unsaved ? request.content! : onDisk;
+    const after = current !== undefined ? surfaceHash(current) : undefined;
+    // A file seen for the first time may have changed in any way since its siblings were analyzed
+    const siblingsAffected = siblings.length > 0 && (before === undefined || before !== after);
+
+    const cached = (file: string) =>
+      this.analyzeResolvedFile(file, this.workspaceRoots.requireRoot(file), undefined, options, { cacheable: true, disabled });
+    let invalidated: string[] = [];
+    let errors: LanguageError[];
+    if (unsaved) {
+      const rerun = request.allFiles && siblingsAffected ? siblings : [];
+      errors = await this.analyzeWithOverlay(fullPath, workspaceRoot, options, { [fullPath]: request.content! }, rerun);
+      if (request.allFiles && !siblingsAffected) {
+        errors.push(...(await this.analyzeFiles(siblings, options, cached)).flat());
+      }
+    } else {
+      if (after !== undefined) {
+        this.changeSurfaces.set(fullPath, after);
+      } else {
+        this.changeSurfaces.delete(fullPath);
+      }
+      if (siblingsAffected) {
+        invalidated = siblings;
+        siblings.forEach(file => this.cache.invalidate(file));
+      }
+      // A deleted file has no diagnostics of its own left
+      const files = [...(onDisk !== undefined ? [fullPath] : []), ...(request.allFiles ? siblings : [])];
+      errors = (await this.analyzeFiles(files, options, cached)).flat();
+    }
+
+    return { packageDir, siblings, siblingsAffected, invalidated, errors };
+  }
+
+  /**
+   * Analyze `fullPath` with the overlay written into a mirror of the workspace,
+   * along with any files of `alsoAnalyze`
+   */
+  private async analyzeWithOverlay(
+    fullPath: string,
+    workspaceRoot: string | undefined,
+    options: DetectionOptions,
+    overlay: Overlay,
+    alsoAnalyze: string[] = []
+  ): Promise<LanguageError[]> {
+    const disabled = this.disabledDetectors;
+    const stats = await fs.stat(fullPath).catch(() => undefined);
+    const baseDir = stats?.isDirectory() ? fullPath : dirname(fullPath);
+    const files = new Map<string, string>();
+    for (const [path, content] of Object.entries(overlay)) {
+      if (typeof content !== 'string') {
+        throw new Error(`Overlay content for ${path} must be a string`);
+      }
+      const file = resolve(baseDir, path);
+      this.workspaceRoots.requireRoot(file);
+      files.set(file, content);
+    }
+
+    const mirrorRoot = workspaceRoot ?? await this.findOverlayRoot([baseDir, ...files.keys()]);
+    const mirror = await OverlayMirror.create(mirrorRoot, files);
+    const recorder = currentRawOutputRecorder();
+    const firstRun = recorder?.size ?? 0;
+    try {
+      const target = mirror.toMirror(fullPath);
+      const targetStats = await fs.stat(target);
+      const targets = [
+        ...(targetStats.isDirectory() ? await this.findSupportedFiles(target) : [target]),
+        ...alsoAnalyze.map(file => mirror.toMirror(file))
+      ];
+      const errors = await this.analyzeFiles(targets, options, file =>
+        this.analyzeResolvedFile(file, mirror.path, undefined, options, { cacheable: false, disabled })
+      );
+      return mirror.remapErrors(errors.flat());
+    } finally {
+      // Raw output names the mirror; point it at the workspace and hide other temp dirs
+      recorder?.rewrite(firstRun, text => redactTempPaths(mirror.remapText(text), [mirror.originalRoot]));
+      await mirror.dispose();
+    }
+  }
+
+  /**
+   * Without configured roots, mirror the enclosing git checkout, or else the
+   * closest directory containing the target and every overlay file
+   */
+  private async findOverlayRoot(paths: string[]): Promise<string> {
+    let common = paths[0]!;
+    for (const path of paths.slice(1)) {
+      while (relative(common, path).startsWith('..') || isAbsolute(relative(common, path))) {
+        common = dirname(common);
+      }
+    }
+    const git = await findUpwards(join(common, '.git'), ['.git']);
+    return git ? dirname(git) : common;
+  }
+
+  private async analyzeFiles(
+    files: string[],
+    options: DetectionOptions,
+    analyze: (file: string) => Promise<LanguageError[]>
+  ): Promise<LanguageError[][]> {
+    return mapWithConcurrency(files, this.getConcurrency(), async file => {
+      throwIfAborted(options.signal);
+      try {
+        return await analyze(file);
+      } catch (error) {
+        // Cancellation and missing toolchains are reported to the caller, not swallowed
+        if (isCancellationError(error) || isToolNotFoundError(error)) {
+          throw error;
+        }
+        this.logger.error(`Analysis failed for ${file}`, error);
+        return [this.createCrashError('analysis', file, error)];
+      }
+    });
+  }
+
+  /**
+   * Build and execute the program or tests at a path and report what it crashed
+   * with. Executing code must be enabled in the execution config; the run is
+   * always bounded by the configured timeout and output size.
+   */
+  async runAndDetect(targetPath: string, request: RunRequest = {}): Promise<RunResult> {
+    const execution = this.config.execution || {};
+    if (!execution.enabled) {
+      throw new Error('Running programs is disabled; set detection.execution.enabled to allow it');
+    }
+
+    const fullPath = resolve(targetPath);
+    const workspaceRoot = this.workspaceRoots.requireRoot(fullPath);
+    const language = request.language || SupportedLanguage.GO;
+    if (this.disabledDetectors.has(language)) {
+      throw new Error(`The ${language} detector is disabled`);
+    }
+    const handler = this.handlers.get(language);
+    if (!handler?.runAndDetect) {
+      throw new UnsupportedLanguageError(language, `Running programs is not supported for ${language}`);
+    }
+
+    const limit = execution.timeoutMs ?? DEFAULT_RUN_TIMEOUT_MS;
+    const timeoutMs = request.timeoutMs !== undefined && request.timeoutMs > 0 ? Math.min(request.timeoutMs, limit) : limit;
+    const result = await this.track(request.signal, signal => handler.runAndDetect!(fullPath, {
+      timeoutMs,
+      maxOutputBytes: execution.maxOutputBytes ?? DEFAULT_RUN_MAX_OUTPUT_BYTES,
+      ...(request.mode && { mode: request.mode }),
+      ...(request.args && { args: request.args }),
+      ...(workspaceRoot && { workspaceRoot }),
+      signal
+    }));
+    const errors = await normalizeErrorPaths(result.errors, this.createPathNormalizer(fullPath, workspaceRoot));
+    return { ...result, errors: await this.normalizeColumnsOnDisk(errors, handler) };
+  }
+
+  /**
+   * Check the packages matched by patterns such as `./...` or `./internal/store`,
+   * resolved in `directory` the way the go command resolves them. The handler's
+   * tools check every matched package in one invocation; suppressions, severity
+   * overrides and the workspace config apply per reported file, and paths come
+   * back absolute.
+   */
+  async analyzePackages(directory: string, patterns: string[], request: PackageAnalysisRequest = {}): Promise<LanguageError[]> {
+    const fullPath = resolve(directory);
+    const workspaceRoot = this.workspaceRoots.requireRoot(fullPath);
+    const language = request.language || SupportedLanguage.GO;
+    if (this.disabledDetectors.has(language)) {
+      throw new Error(`The ${language} detector is disabled`);
+    }
+    const handler = this.handlers.get(language);
+    if (!handler?.detectPackageErrors) {
+      throw new UnsupportedLanguageError(language, `Package patterns are not supported for ${language}`);
+    }
+
+    const workspaceConfig = await this.workspaceConfigs.load(fullPath, workspaceRoot);
+    analysisStats.recordAnalysis();
+    const detected = await this.timeDetector(language, () => this.track(request.signal, signal => runWithDetectorOptions(
+      this.detectorOptions(language, workspaceConfig, request.offline),
+      () => runWithSignal(signal, () => handler.detectPackageErrors!(patterns, {
+        dir: fullPath,
+        ...(request.enableLinting !== undefined && { enableLinting: request.enableLinting }),
+        ...(workspaceRoot && { workspaceRoot }),
+        signal
+      }))
+    )));
+
+    // Columns and suppression comments are read from each file the tools reported on
+    const byFile = new Map<string, LanguageError[]>();
+    for (const error of detected) {
+      byFile.set(error.location.file, [...byFile.get(error.location.file) || [], error]);
+    }
+    const errors: LanguageError[] = [];
+    for (const [file, fileErrors] of byFile) {
+      const source = await fs.readFile(file, 'utf-8').catch(() => '');
+      errors.push(...applySuppressions(
+        normalizeColumns(fileErrors, source, this.columnEncoding(handler), file),
+        source,
+        this.config.suppressions,
+        file
+      ));
+    }
+
+    const configError = workspaceConfigDiagnostic(workspaceConfig);
+    const result = await normalizeErrorPaths(
+      [...applyWorkspaceConfig(errors, workspaceConfig.config, this.severityRules), ...(configError ? [configError] : [])],
+      new PathNormalizer(Array.from(new Set([workspaceRoot, fullPath].filter((dir): dir is string => dir !== undefined))))
+    );
+    this.emit('errorsDetected', language, result);
+    return result;
+  }
+
+  /**
+   * Have a handler build everything below a directory so its tool's build cache
+   * is warm. Resolves false when some package failed to build, and when the
+   * handler is missing, disabled or has nothing to warm.
+   */
+  async warmBuildCache(directory: string, language: LanguageId = SupportedLanguage.GO, signal?: AbortSignal): Promise<boolean> {
+    const fullPath = resolve(directory);
+    const workspaceRoot = this.workspaceRoots.requireRoot(fullPath);
+    const handler = this.handlers.get(language);
+    if (this.disabledDetectors.has(language) || !handler?.warmBuildCache) {
+      return false;
+    }
+
+    const workspaceConfig = await this.workspaceConfigs.load(fullPath, workspaceRoot);
+    return this.track(signal, trackedSignal => runWithDetectorOptions(
+      this.detectorOptions(language, workspaceConfig),
+      () => runWithSignal(trackedSignal, () => handler.warmBuildCache!(fullPath, { signal: trackedSignal }))
+    ));
+  }
+
+  /**
+   * Run work that may spawn tools under the caller's signal and the shutdown
+   * signal, and keep it in the in-flight set until it settles
+   */
+  private track<T>(signal: AbortSignal | undefined, work: (signal: AbortSignal) => Promise<T>): Promise<T> {
+    const shutdown = this.shutdownController.signal;
+    throwIfAborted(shutdown);
+
+    const promise = work(anySignal([signal, shutdown])!);
+    this.inFlight.add(promise);
+    const settle = () => { this.inFlight.delete(promise); };
+    promise.then(settle, settle);
+    return promise;
+  }
+
+  /**
+   * Number of detections and runs that have not finished yet
+   */
+  getInFlightCount(): number {
+    return this.inFlight.size;
+  }
+
+  /**
+   * Shut down for good: refuse new analyses, cancel the running ones, which kills
+   * their tool processes, and wait up to `graceMs` for them to settle before the
+   * handlers (and their long-lived servers such as gopls) are disposed
+   */
+  async shutdown(graceMs = DEFAULT_SHUTDOWN_GRACE_MS): Promise<void> {
+    if (!this.shutdownController.signal.aborted) {
+      this.shutdownController.abort(new AnalysisCanceledError('Server is shutting down'));
+    }
+
+    const pending = Array.from(this.inFlight);
+    if (pending.length > 0) {
+      this.logger.info(`Waiting for ${pending.length} running analyses to stop`);
+      let graceTimer: NodeJS.Timeout | undefined;
+      const drained = await Promise.race([
+        Promise.allSettled(pending).then(() => true),
+        new Promise<boolean>(resolve => { graceTimer = setTimeout(() => resolve(false), graceMs); })
+      ]);
+      clearTimeout(graceTimer);
+      if (!drained) {
+        this.logger.warn(`${this.inFlight.size} analyses still running after ${graceMs}ms, disposing handlers anyway`);
+      }
+    }
+
+    await this.dispose();
+  }
+
+  /**
+   * Get the number of files analyzed in parallel
+   */
+  getConcurrency(): number {
+    return this.config.concurrency && this.config.concurrency > 0 ? this.config.concurrency : defaultConcurrency();
+  }
+
+  /**
+   * Handler options for one detection: those the workspace config sets for the
+   * language, less the ones only the server may set, plus `offline` from the
+   * call, the workspace config or the server config, whichever comes first
+   */
+  private detectorOptions(
+    language: LanguageId,
+    workspaceConfig: LoadedWorkspaceConfig,
+    offline?: boolean
+  ): Record<string, unknown> | undefined {
+    const detectors = withoutServerOnlyOptions(workspaceConfig.config.detectors?.[language]);
+    const resolved = offline ?? workspaceConfig.config.offline ?? this.config.offline;
+    return resolved === undefined ? detectors : { ...detectors, offline: resolved };
+  }
+
+  private columnEncoding(handler: LanguageHandler): ColumnEncoding {
+    return handler.getColumnEncoding?.() ?? DEFAULT_COLUMN_ENCODING;
+  }
+
+  /**
+   * Convert the columns of diagnostics spread over several files, each against
+   * the file on disk, keeping their order
+   */
+  private async normalizeColumnsOnDisk(errors: LanguageError[], handler: LanguageHandler): Promise<LanguageError[]> {
+    const sources = new Map<string, string>();
+    for (const file of new Set(errors.map(error => error.location.file))) {
+      sources.set(file, await fs.readFile(file, 'utf-8').catch(() => ''));
+    }
+    return errors.map(error => (
+      normalizeColumns([error], sources.get(error.location.file)!, this.columnEncoding(handler), error.location.file)[0]!
+    ));
+  }
+
+  private createCrashError(what: string, filePath: string, error: unknown): LanguageError {
+    return {
+      message: `${what} failed: ${error instanceof Error ? error.message : String(error)}`,
+      severity: 'error',
+      location: { file: filePath, line: 1, column: 1 },
+      source: TOOLCHAIN_SOURCE,
+      code: 'toolchain',
+      relatedInformation: []
+    };
+  }
+
+  /**
+   * Relative names in tool output are tried against the owning root, the analyzed
+   * file's directory and the working directory, in that order
+   */
+  private createPathNormalizer(filePath: string, workspaceRoot?: string): PathNormalizer {
+    const baseDirs = [workspaceRoot, dirname(filePath), process.cwd()]
+      .filter((dir): dir is string => dir !== undefined);
+    return new PathNormalizer(Array.from(new Set(baseDirs)));
+  }
+
+  /**
+   * Get the deadline for a language's handler in milliseconds; 0 means none
+   */
+  getDetectorTimeout(language: LanguageId): number {
+    const timeouts = this.config.timeouts || {};
+    return timeouts[language] ?? timeouts['default'] ?? DEFAULT_DETECTOR_TIMEOUT_MS;
+  }
+
+  /**
+   * Run a handler under the options' abort signal so spawned tools are killed on
+   * cancellation. Handlers may turn a killed tool into a diagnostic, so the signal is
+   * checked again afterwards and partial results are discarded.
+   *
+   * The handler's own deadline is different: its tools are killed, whatever output
+   * they produced is still parsed, and an "analysis timed out" error is appended.
+   */
+  private runDetection(
+    handler: LanguageHandler,
+    source: string,
+    options: DetectionOptions
+  ): Promise<{ errors: LanguageError[]; timedOut: boolean }> {
+    return this.timeDetector(
+      handler.language,
+      () => this.track(options.signal, signal => this.runDetectionWithDeadline(handler, source, { ...options, signal })),
+      run => run.timedOut
+    );
+  }
+
+  /**
+   * Record how long a detector run took and how it ended. Canceled runs are not
+   * counted, since their duration says nothing about the detector.
+   */
+  private async timeDetector<T>(
+    detector: string,
+    work: () => Promise<T>,
+    timedOut: (result: T) => boolean = () => false
+  ): Promise<T> {
+    if (currentCommandPlan()) {
+      return work();
+    }
+    const startedAt = Date.now();
+    try {
+      const result = await work();
+      analysisStats.recordDetectorRun(detector, Date.now() - startedAt, timedOut(result) ? 'timeout' : 'ok');
+      return result;
+    } catch (error) {
+      if (!isCancellationError(error)) {
+        analysisStats.recordDetectorRun(detector, Date.now() - startedAt, 'failed');
+      }
+      throw error;
+    }
+  }
+
+  private async runDetectionWithDeadline(
+    handler: LanguageHandler,
+    source: string,
+    options: DetectionOptions
+  ): Promise<{ errors: LanguageError[]; timedOut: boolean }> {
+    const { signal } = options;
+    // Config-defined commands always run under their own deadline
+    const timeoutMs = handler instanceof GenericCommandDetector
+      ? handler.timeoutMs
+      : this.getDetectorTimeout(handler.language);
+
+    throwIfAborted(signal);
+    if (timeoutMs <= 0) {
+      const errors = await runWithSignal(signal, () => handler.detectErrors(source, options));
+      throwIfAborted(signal);
+      return { errors, timedOut: false };
+    }
+
+    const deadline = new AbortController();
+    const combined = anySignal([signal, deadline.signal])!;
+    let graceTimer: NodeJS.Timeout | undefined;
+
+    // Handlers that ignore the signal are abandoned shortly after the deadline
+    const abandoned = new Promise<null>(resolve => {
+      deadline.signal.addEventListener('abort', () => {
+        graceTimer = setTimeout(() => resolve(null), DETECTOR_TIMEOUT_GRACE_MS);
+      }, { once: true });
+    });
+    const deadlineTimer = setTimeout(
+      () => deadline.abort(new DetectorTimeoutError(handler.language, timeoutMs)),
+      timeoutMs
+    );
+
+    let errors: LanguageError[] | null;
+    try {
+      errors = await Promise.race([
+        runWithSignal(combined, () => handler.detectErrors(source, { ...options, signal: combined })),
+        abandoned
+      ]);
+    } catch (error) {
+      // A handler that rethrows the deadline still gets a timeout diagnostic
+      if (!(deadline.signal.aborted && isCancellationError(error))) {
+        throw error;
+      }
+      errors = [];
+    } finally {
+      clearTimeout(deadlineTimer);
+      clearTimeout(graceTimer);
+    }
+    throwIfAborted(signal);
+
+    if (!deadline.signal.aborted) {
+      return { errors: errors || [], timedOut: false };
+    }
+
+    this.logger.warn(`${handler.language} analysis timed out after ${timeoutMs}ms`, {
+      file: options.filePath
+    });
+    const timeoutError: LanguageError = {
+      message: formatMessage('diagnostic.timeout'),
+      severity: 'error',
+      location: { file: options.filePath || '', line: 1, column: 1 },
+      source: handler.language,
+      code: 'timeout'
+    };
+    return { errors: [...(errors || []), timeoutError], timedOut: true };
+  }
+
+  /**
+   * Whether each command of a workspace config runs. The server config must
+   * enable them, and with an `allow` list only the programs on it are started.
+   */
+  getWorkspaceCommands(loaded: LoadedWorkspaceConfig): WorkspaceCommandStatus[] {
+    const { enabled, allow } = this.config.commands || {};
+    return Object.entries(loaded.config.commands || {}).map(([name, definition]) => {
+      const program = commandProgram(definition) || '';
+      const reason = !enabled
+        ? 'Workspace commands are disabled in the server config'
+        : allow && !allow.includes(program)
+          ? `${program} is not on the server's allow list`
+          : undefined;
+      return { name, program, enabled: reason === undefined, ...(reason && { reason }) };
+    });
+  }
+
+  /**
+   * Detectors for the `commands` of a workspace config that the server lets run.
+   * They run in the workspace root, or in the config file's directory outside of
+   * any root.
+   */
+  private getCommandDetectors(loaded: LoadedWorkspaceConfig, workspaceRoot?: string): GenericCommandDetector[] {
+    const commands = loaded.config.commands;
+    if (!commands || !loaded.file) {
+      return [];
+    }
+
+    const allowed = new Set(this.getWorkspaceCommands(loaded).filter(command => command.enabled).map(command => command.name));
+    const cwd = workspaceRoot || dirname(loaded.file);
+    return Object.entries(commands).filter(([name]) => allowed.has(name)).map(([name, definition]) => {
+      const key = JSON.stringify([loaded.file, cwd, name, definition]);
+      let detector = this.commandDetectors.get(key);
+      if (!detector) {
+        detector = new GenericCommandDetector(name, definition, cwd, this.logger);
+        this.commandDetectors.set(key, detector);
+      }
+      return detector;
+    });
+  }
+
+  /**
+   * Find files below a directory that an available handler, or a command of the
+   * directory's workspace config, can analyze
+   */
+  private async findSupportedFiles(directory: string): Promise<string[]> {
+    const workspaceRoot = this.workspaceRoots.requireRoot(directory);
+    const workspaceConfig = await this.workspaceConfigs.load(directory, workspaceRoot);
+    const commandExtensions = this.getCommandDetectors(workspaceConfig, workspaceRoot)
+      .filter(detector => !this.disabledDetectors.has(detector.language))
+      .flatMap(detector => detector.getFileExtensions());
+    const patterns = [
+      ...Array.from(new Set([...this.getSupportedExtensions().keys(), ...commandExtensions])).map(ext => `**/*${ext}`),
+      ...Array.from(this.getSupportedFileNames().keys()).map(name => `**/${name}`)
+    ];
+    if (patterns.length === 0) {
+      return [];
+    }
+
+    const { glob } = await import('fast-glob');
+    const files = await glob(patterns, {
+      cwd: directory,
+      ignore: ['**/node_modules/**', '**/.git/**', '**/dist/**', '**/build/**', '**/vendor/**'],
+      absolute: true,
+    });
+
+    return files.sort();
+  }
+
+  /**
+   * Drop all cached analysis results
+   */
+  clearCache(): void {
+    this.cache.clear();
+    this.changeSurfaces.clear();
+    this.siblingSurfaces.clear();
+    this.workspaceConfigs.clear();
+    this.logger.debug('Analysis cache cleared');
+  }
+
+  /**
+   * Forget cached toolchain paths and versions, so the next lookups probe again
+   * (e.g. after installing or upgrading a tool)
+   */
+  invalidateToolchainCache(): void {
+    toolchainCache.invalidate();
+    this.logger.debug('Toolchain cache cleared');
+  }
+
+  /**
+   * Get toolchain lookup cache counters
+   */
+  getToolchainCacheStats(): ToolchainCacheStats {
+    return toolchainCache.getStats();
+  }
+
+  /**
+   * Get analysis cache hit/miss counters
+   */
+  getCacheStats(): AnalysisCacheStats {
+    return this.cache.getStats();
+  }
+
+  /**
+   * Zero the analysis cache hit/miss counters without dropping cached results
+   */
+  resetCacheStats(): void {
+    this.cache.resetStats();
+  }
+
+  /**
+   * Get counters of analyses started and of requests that shared one in flight
+   */
+  getCoalescingStats(): SingleFlightStats {
+    return this.analyses.getStats();
+  }
+
   /**
    * Parse stack trace using appropriate language handler
    */
-  parseStackTrace(stackTrace: string, language: SupportedLanguage) {
+  parseStackTrace(stackTrace: string, language: LanguageId) {
     const handler = this.handlers.get(language);
     if (!handler) {
       this.logger.warn(`No handler available for language: ${language}`);
@@ -206,7 +1660,7 @@ export class LanguageHandlerManager extends EventEmitter {
   /**
    * Get debug capabilities for a language
    */
-  getDebugCapabilities(language: SupportedLanguage) {
+  getDebugCapabilities(language: LanguageId) {
     const handler = this.handlers.get(language);
     if (!handler) {
       return null;
@@ -218,7 +1672,7 @@ export class LanguageHandlerManager extends EventEmitter {
   /**
    * Analyze performance of source code
    */
-  async analyzePerformance(source: string, language: SupportedLanguage) {
+  async analyzePerformance(source: string, language: LanguageId) {
     const handler = this.handlers.get(language);
     if (!handler) {
       this.logger.warn(`No handler available for language: ${language}`);
@@ -236,36 +1690,54 @@ export class LanguageHandlerManager extends EventEmitter {
   /**
    * Check if a language is supported
    */
-  isLanguageSupported(language: SupportedLanguage): boolean {
+  isLanguageSupported(language: LanguageId): boolean {
     return this.handlers.has(language);
   }
 
   /**
    * Get supported file extensions for all languages
    */
-  getSupportedExtensions(): Map<string, SupportedLanguage[]> {
-    const extensions = new Map<string, SupportedLanguage[]>();
+  getSupportedExtensions(): Map<string, LanguageId[]> {
+    const extensions = new Map<string, LanguageId[]>();
 
-    for (const [language, handler] of this.handlers) {
+    for (const handler of this.handlers.handlers()) {
       for (const ext of handler.getFileExtensions()) {
         if (!extensions.has(ext)) {
           extensions.set(ext, []);
         }
-        extensions.get(ext)!.push(language);
+        extensions.get(ext)!.push(handler.language);
       }
     }
 
     return extensions;
   }
 
+  /**
+   * Get exact file names claimed by handlers regardless of extension
+   */
+  getSupportedFileNames(): Map<string, LanguageId[]> {
+    const fileNames = new Map<string, LanguageId[]>();
+
+    for (const handler of this.handlers.handlers()) {
+      for (const name of handler.getFileNames?.() || []) {
+        if (!fileNames.has(name)) {
+          fileNames.set(name, []);
+        }
+        fileNames.get(name)!.push(handler.language);
+      }
+    }
+
+    return fileNames;
+  }
+
   /**
    * Get configuration files for all languages
    */
-  getConfigFiles(): Map<SupportedLanguage, string[]> {
-    const configFiles = new Map<SupportedLanguage, string[]>();
+  getConfigFiles(): Map<LanguageId, string[]> {
+    const configFiles = new Map<LanguageId, string[]>();
 
-    for (const [language, handler] of this.handlers) {
-      configFiles.set(language, handler.getConfigFiles());
+    for (const handler of this.handlers.handlers()) {
+      configFiles.set(handler.language, handler.getConfigFiles());
     }
 
     return configFiles;
@@ -275,11 +1747,11 @@ export class LanguageHandlerManager extends EventEmitter {
    * Reload a specific language handler
    */
   async reloadHandler(language: SupportedLanguage): Promise<void> {
-    const existingHandler = this.handlers.get(language);
+    const existingHandler = this.handlers.unregister(language);
     if (existingHandler) {
       await existingHandler.dispose();
-      this.handlers.delete(language);
     }
+    this.cache.clear();
 
     await this.initializeHandler(language);
   }
@@ -291,12 +1763,14 @@ export class LanguageHandlerManager extends EventEmitter {
     this.logger.info('Disposing language handler manager');
 
     const disposePromises: Promise<void>[] = [];
-    for (const handler of this.handlers.values()) {
+    for (const handler of this.handlers.handlers()) {
       disposePromises.push(handler.dispose());
     }
 
     await Promise.allSettled(disposePromises);
     this.handlers.clear();
+    this.commandDetectors.clear();
+    this.cache.clear();
 
     this.logger.info('Language handler manager disposed');
     this.emit('disposed');
@@ -308,9 +1782,11 @@ export class LanguageHandlerManager extends EventEmitter {
   getStatistics() {
     return {
       totalHandlers: this.handlers.size,
-      availableLanguages: Array.from(this.handlers.keys()),
+      availableLanguages: this.handlers.languages(),
       supportedExtensions: this.getSupportedExtensions(),
-      configFiles: this.getConfigFiles()
+      configFiles: this.getConfigFiles(),
+      cache: this.getCacheStats(),
+      coalescing: this.getCoalescingStats()
     };
   }
 
@@ -319,6 +1795,9 @@ export class LanguageHandlerManager extends EventEmitter {
    */
   updateConfig(config: Partial<LanguageHandlerManagerConfig>): void {
     this.config = { ...this.config, ...config };
+    this.severityRules = compileSeverityRules(this.config.severityOverrides);
+    this.analyses.setMinInterval(this.config.minAnalysisIntervalMs ?? 0);
+    this.cache.clear();
     this.emit('configUpdated', this.config);
   }
 
@@ -2,9 +2,8 @@
  * PHP language handler implementation
  */
 
-import { spawn } from 'child_process';
 import { promises as fs } from 'fs';
-import { BaseLanguageHandler } from './base-language-handler.js';
+import { BaseLanguageHandler, type ToolchainProbe } from './base-language-handler.js';
 import type {
   DetectionOptions,
   LanguageError,
@@ -87,6 +86,10 @@ export class PHPHandler extends BaseLanguageHandler {
     this.composerPath = undefined;
   }
 
+  protected getToolchainProbe(): ToolchainProbe {
+    return { command: this.phpPath || 'php', args: ['--version'] };
+  }
+
   protected async checkAvailability(): Promise<boolean> {
     try {
       const result = await this.runCommand('php', ['--version']);
@@ -407,45 +410,6 @@ export class PHPHandler extends BaseLanguageHandler {
     ];
   }
 
-  private async findExecutable(name: string): Promise<string | undefined> {
-    try {
-      const result = await this.runCommand('which', [name]);
-      return result.exitCode === 0 ? result.stdout.trim() : undefined;
-    } catch {
-      return undefined;
-    }
-  }
-
-  private async runCommand(command: string, args: string[]): Promise<{
-    stdout: string;
-    stderr: string;
-    exitCode: number;
-  }> {
-    return new Promise((resolve, reject) => {
-      const child = spawn(command, args, { stdio: 'pipe' });
-      let stdout = '';
-      let stderr = '';
-
-      child.stdout?.on('data', (data) => {
-        stdout += data.toString();
-      });
-
-      child.stderr?.on('data', (data) => {
-        stderr += data.toString();
-      });
-
-      child.on('close', (code) => {
-        resolve({
-          stdout,
-          stderr,
-          exitCode: code || 0
-        });
-      });
-
-      child.on('error', reject);
-    });
-  }
-
   private calculateComplexity(source: string): number {
     const patterns = [
       /\bif\b/g,
@@ -2,10 +2,12 @@
  * Python language handler implementation
  */
 
-import { spawn } from 'child_process';
 import { promises as fs } from 'fs';
-import { BaseLanguageHandler } from './base-language-handler.js';
+import { tmpdir } from 'os';
+import { dirname, isAbsolute, join, resolve } from 'path';
+import { BaseLanguageHandler, type ToolchainProbe } from './base-language-handler.js';
 import type {
+  ColumnEncoding,
   DetectionOptions,
   LanguageError,
   StackFrame,
@@ -17,10 +19,14 @@ import type {
 import { SupportedLanguage } from '../types/languages.js';
 import { Logger } from '../utils/logger.js';
 
+export type PythonChecker = 'pyflakes' | 'mypy';
+
 export class PythonHandler extends BaseLanguageHandler {
   private pythonPath: string | undefined;
   private pylintPath: string | undefined;
   private flake8Path: string | undefined;
+  private mypyPath: string | undefined;
+  private hasPyflakes = false;
 
   constructor(options: Record<string, unknown> = {}, logger?: Logger) {
     super(SupportedLanguage.PYTHON, options, logger);
@@ -30,6 +36,11 @@ export class PythonHandler extends BaseLanguageHandler {
     return ['.py', '.pyw', '.pyi'];
   }
 
+  /** Python's ast reports UTF-8 byte offsets; the tokenizer drops the BOM first */
+  getColumnEncoding(): ColumnEncoding {
+    return { unit: 'byte', includesBom: false };
+  }
+
   getConfigFiles(): string[] {
     return [
       'pyproject.toml',
@@ -59,10 +70,28 @@ export class PythonHandler extends BaseLanguageHandler {
       this.logger.warn('No Python linting tools found. Install pylint or flake8 for better error detection.');
     }
 
+    // Find checkers used for quick undefined-name and type checks
+    const checkers = this.getCheckers();
+    if (checkers.includes('pyflakes')) {
+      this.hasPyflakes = await this.isModuleAvailable('pyflakes');
+    }
+    if (checkers.includes('mypy')) {
+      this.mypyPath = await this.findExecutable('mypy');
+      if (!this.mypyPath && await this.isModuleAvailable('mypy')) {
+        this.mypyPath = this.pythonPath;
+      }
+      if (!this.mypyPath) {
+        this.logger.warn('mypy not found. Type checking is disabled, falling back to syntax-only detection.');
+      }
+    }
+
     this.logger.info('Python handler initialized', {
       pythonPath: this.pythonPath,
       pylintPath: this.pylintPath,
-      flake8Path: this.flake8Path
+      flake8Path: this.flake8Path,
+      mypyPath: this.mypyPath,
+      hasPyflakes: this.hasPyflakes,
+      checkers
     });
   }
 
@@ -70,6 +99,12 @@ export class PythonHandler extends BaseLanguageHandler {
     this.pythonPath = undefined;
     this.pylintPath = undefined;
     this.flake8Path = undefined;
+    this.mypyPath = undefined;
+    this.hasPyflakes = false;
+  }
+
+  protected getToolchainProbe(): ToolchainProbe {
+    return { command: this.pythonPath || 'python3', args: ['--version'] };
   }
 
   protected async checkAvailability(): Promise<boolean> {
@@ -90,6 +125,18 @@ export class PythonHandler extends BaseLanguageHandler {
 
     // Linting with available tools
     if (options?.enableLinting !== false) {
+      const filePath = options?.filePath || 'temp.py';
+
+      if (this.hasPyflakes) {
+        const pyflakesErrors = await this.runPyflakes(source, filePath);
+        errors.push(...pyflakesErrors);
+      }
+
+      if (this.mypyPath && options?.includeTypeChecking !== false) {
+        const mypyErrors = await this.runMypy(source, filePath, options?.workspaceRoot);
+        errors.push(...mypyErrors);
+      }
+
       if (this.pylintPath) {
         const pylintErrors = await this.runPylint(source, options?.filePath || 'temp.py');
         errors.push(...pylintErrors);
@@ -104,13 +151,9 @@ export class PythonHandler extends BaseLanguageHandler {
 
   protected async validateSyntax(source: string): Promise<LanguageError[]> {
     try {
-      const tempFile = `/tmp/py-syntax-check-${Date.now()}.py`;
-      await fs.writeFile(tempFile, source);
-
-      const result = await this.runCommand(this.pythonPath!, ['-m', 'py_compile', tempFile]);
-
-      // Cleanup
-      await fs.unlink(tempFile).catch(() => {});
+      const result = await this.withTempSource('py-syntax-check-', source, tempFile =>
+        this.runCommand(this.pythonPath!, ['-m', 'py_compile', tempFile])
+      );
 
       if (result.exitCode === 0) {
         return [];
@@ -134,18 +177,13 @@ export class PythonHandler extends BaseLanguageHandler {
     }
 
     try {
-      const tempFile = `/tmp/pylint-check-${Date.now()}.py`;
-      await fs.writeFile(tempFile, source);
-
-      const result = await this.runCommand(this.pylintPath, [
+      const pylintPath = this.pylintPath;
+      const result = await this.withTempSource('pylint-check-', source, tempFile => this.runCommand(pylintPath, [
         '--output-format=json',
         '--disable=all',
         '--enable=syntax-error,undefined-variable,unused-variable,import-error',
         tempFile
-      ]);
-
-      // Cleanup
-      await fs.unlink(tempFile).catch(() => {});
+      ]));
 
       if (result.stdout) {
         return this.parsePylintOutput(result.stdout, filePath);
@@ -164,16 +202,11 @@ export class PythonHandler extends BaseLanguageHandler {
     }
 
     try {
-      const tempFile = `/tmp/flake8-check-${Date.now()}.py`;
-      await fs.writeFile(tempFile, source);
-
-      const result = await this.runCommand(this.flake8Path, [
+      const flake8Path = this.flake8Path;
+      const result = await this.withTempSource('flake8-check-', source, tempFile => this.runCommand(flake8Path, [
         '--format=%(path)s:%(row)d:%(col)d: %(code)s %(text)s',
         tempFile
-      ]);
-
-      // Cleanup
-      await fs.unlink(tempFile).catch(() => {});
+      ]));
 
       return this.parseFlake8Output(result.stdout, filePath);
     } catch (error) {
@@ -182,6 +215,123 @@ export class PythonHandler extends BaseLanguageHandler {
     }
   }
 
+  private async runPyflakes(source: string, filePath: string): Promise<LanguageError[]> {
+    try {
+      const result = await this.withTempSource('pyflakes-check-', source, tempFile =>
+        this.runCommand(this.pythonPath!, ['-m', 'pyflakes', tempFile])
+      );
+
+      // pyflakes reports findings on stdout and syntax errors on stderr
+      return this.parsePyflakesOutput(result.stdout + result.stderr, filePath);
+    } catch (error) {
+      this.logger.debug('Pyflakes execution failed', error);
+      return [];
+    }
+  }
+
+  /**
+   * Type-check with mypy from the workspace root, so the project's mypy.ini or
+   * pyproject.toml applies and its own modules resolve. A file saved on disk is
+   * checked in place; an unsaved buffer is checked as a copy, with imports mypy
+   * cannot find ignored.
+   */
+  private async runMypy(source: string, filePath: string, workspaceRoot?: string): Promise<LanguageError[]> {
+    const mypyPath = this.mypyPath;
+    if (!mypyPath) {
+      return [];
+    }
+
+    const onDisk = isAbsolute(filePath) && await this.isUnmodifiedOnDisk(filePath, source);
+    const cwd = workspaceRoot || (isAbsolute(filePath) ? dirname(filePath) : undefined);
+    const check = async (target: string, extraArgs: string[]): Promise<LanguageError[]> => {
+      const mypyArgs = [
+        '--show-column-numbers',
+        '--show-error-codes',
+        '--no-error-summary',
+        '--no-color-output',
+        ...extraArgs,
+        target
+      ];
+      const args = mypyPath === this.pythonPath ? ['-m', 'mypy', ...mypyArgs] : mypyArgs;
+      const result = await this.runCommand(mypyPath, args, { ...(cwd && { cwd }) });
+      return this.parseMypyOutput(result.stdout, filePath, resolve(cwd ?? '', target), cwd);
+    };
+
+    try {
+      return onDisk
+        ? await check(filePath, [])
+        : await this.withTempSource('mypy-check-', source, tempFile => check(tempFile, ['--ignore-missing-imports']));
+    } catch (error) {
+      this.logger.debug('Mypy execution failed', error);
+      return [];
+    }
+  }
+
+  /**
+   * Run a check on a copy of the source in a directory of its own, so analyses
+   * running at the same time never share a file. The directory is removed afterwards.
+   */
+  private async withTempSource<T>(prefix: string, source: string, check: (tempFile: string) => Promise<T>): Promise<T> {
+    const tempDir = await fs.mkdtemp(join(tmpdir(), prefix));
+    try {
+      const tempFile = join(tempDir, 'check.py');
+      await fs.writeFile(tempFile, source);
+      return await check(tempFile);
+    } finally {
+      await fs.rm(tempDir, { recursive: true, force: true }).catch(() => {});
+    }
+  }
+
+  private parsePyflakesOutput(output: string, filePath: string): LanguageError[] {
+    const errors: LanguageError[] = [];
+    const lines = output.split('\n');
+
+    for (const line of lines) {
+      // Parse pyflakes output: /tmp/check.py:3:5: undefined name 'foo'
+      // Older pyflakes releases omit the column: /tmp/check.py:3: undefined name 'foo'
+      const match = line.match(/^(.+?):(\d+):(?:(\d+):?)?\s+(.+)$/);
+      if (match) {
+        const message = match[4] || 'Unknown error';
+        errors.push(this.createError(
+          message,
+          filePath,
+          parseInt(match[2] || '1'),
+          parseInt(match[3] || '1'),
+          this.mapPyflakesSeverity(message),
+          'pyflakes'
+        ));
+      }
+    }
+
+    return errors;
+  }
+
+  /**
+   * Parse mypy output. With `target`, lines about other files, such as modules
+   * mypy followed imports into, are skipped; their paths are relative to `cwd`.
+   */
+  private parseMypyOutput(output: string, filePath: string, target?: string, cwd?: string): LanguageError[] {
+    const errors: LanguageError[] = [];
+    const lines = output.split('\n');
+
+    for (const line of lines) {
+      // Parse mypy output: /tmp/check.py:5:12: error: Incompatible types in assignment  [assignment]
+      const match = line.match(/^(.+?):(\d+):(?:(\d+):)?\s*(error|warning|note):\s+(.+?)(?:\s+\[([\w-]+)\])?$/);
+      if (match && (!target || resolve(cwd ?? '', match[1] ?? '') === target)) {
+        errors.push(this.createError(
+          match[5] || 'Unknown error',
+          filePath,
+          parseInt(match[2] || '1'),
+          parseInt(match[3] || '1'),
+          this.mapMypySeverity(match[4] || ''),
+          match[6] || 'mypy'
+        ));
+      }
+    }
+
+    return errors;
+  }
+
   private parsePythonErrors(stderr: string, filePath: string): LanguageError[] {
     const errors: LanguageError[] = [];
     const lines = stderr.split('\n');
@@ -264,6 +414,23 @@ export class PythonHandler extends BaseLanguageHandler {
     }
   }
 
+  private mapPyflakesSeverity(message: string): 'error' | 'warning' | 'info' | 'hint' {
+    if (message.startsWith('undefined name') || message.includes('invalid syntax') ||
+        message.includes('unexpected') || message.includes('expected')) {
+      return 'error';
+    }
+    return 'warning';
+  }
+
+  private mapMypySeverity(level: string): 'error' | 'warning' | 'info' | 'hint' {
+    switch (level) {
+      case 'error': return 'error';
+      case 'warning': return 'warning';
+      case 'note': return 'info';
+      default: return 'hint';
+    }
+  }
+
   private mapFlake8Severity(code: string): 'error' | 'warning' | 'info' | 'hint' {
     if (code.startsWith('E')) return 'error';
     if (code.startsWith('W')) return 'warning';
@@ -345,43 +512,27 @@ export class PythonHandler extends BaseLanguageHandler {
     ];
   }
 
-  private async findExecutable(name: string): Promise<string | undefined> {
-    try {
-      const result = await this.runCommand('which', [name]);
-      return result.exitCode === 0 ? result.stdout.trim() : undefined;
-    } catch {
-      return undefined;
+  private getCheckers(): PythonChecker[] {
+    const checkers = this.options['checkers'];
+    if (Array.isArray(checkers)) {
+      return checkers.filter((checker): checker is PythonChecker =>
+        checker === 'pyflakes' || checker === 'mypy'
+      );
     }
+    return ['pyflakes', 'mypy'];
   }
 
-  private async runCommand(command: string, args: string[]): Promise<{
-    stdout: string;
-    stderr: string;
-    exitCode: number;
-  }> {
-    return new Promise((resolve, reject) => {
-      const child = spawn(command, args, { stdio: 'pipe' });
-      let stdout = '';
-      let stderr = '';
-
-      child.stdout?.on('data', (data) => {
-        stdout += data.toString();
-      });
-
-      child.stderr?.on('data', (data) => {
-        stderr += data.toString();
-      });
-
-      child.on('close', (code) => {
-        resolve({
-          stdout,
-          stderr,
-          exitCode: code || 0
-        });
-      });
+  private async isModuleAvailable(module: string): Promise<boolean> {
+    if (!this.pythonPath) {
+      return false;
+    }
 
-      child.on('error', reject);
-    });
+    try {
+      const result = await this.runCommand(this.pythonPath, ['-m', module, '--version']);
+      return result.exitCode === 0;
+    } catch {
+      return false;
+    }
   }
 
   private calculateComplexity(source: string): number {
@@ -2,12 +2,13 @@
  * Rust language handler implementation
  */
 
-import { spawn } from 'child_process';
 import { promises as fs } from 'fs';
-import { BaseLanguageHandler } from './base-language-handler.js';
+import { dirname, resolve, sep } from 'path';
+import { BaseLanguageHandler, type CommandResult, type ToolchainProbe } from './base-language-handler.js';
 import type {
   DetectionOptions,
   LanguageError,
+  RelatedInformation,
   StackFrame,
   LanguageDebugCapabilities,
   LanguageDebugConfig,
@@ -16,6 +17,33 @@ import type {
 } from '../types/languages.js';
 import { SupportedLanguage } from '../types/languages.js';
 import { Logger } from '../utils/logger.js';
+import { isCancellationError } from '../utils/cancellation.js';
+import { ToolNotFoundError, isToolNotFoundError } from '../utils/errors.js';
+import { findUpwards } from '../utils/workspace-roots.js';
+
+interface RustSpan {
+  file_name: string;
+  byte_start: number;
+  byte_end: number;
+  line_start: number;
+  line_end: number;
+  column_start: number;
+  column_end: number;
+  is_primary: boolean;
+  label?: string | null;
+  suggested_replacement?: string | null;
+}
+
+interface RustDiagnostic {
+  message: string;
+  code?: { code: string } | null;
+  level: string;
+  spans: RustSpan[];
+  children?: RustDiagnostic[];
+}
+
+/** Maps a span's file name to the reported path, or undefined to drop the diagnostic */
+type SpanFileResolver = (fileName: string) => string | undefined;
 
 export class RustHandler extends BaseLanguageHandler {
   private rustcPath: string | undefined;
@@ -47,7 +75,7 @@ export class RustHandler extends BaseLanguageHandler {
     this.cargoPath = await this.findExecutable('cargo');
     
     if (!this.rustcPath && !this.cargoPath) {
-      throw new Error('Rust toolchain not found. Please install Rust to use Rust error detection.');
+      throw new ToolNotFoundError('cargo', 'Rust toolchain not found (neither cargo nor rustc is on PATH). Please install Rust to use Rust error detection.');
     }
 
     // Find Clippy for linting
@@ -77,6 +105,10 @@ export class RustHandler extends BaseLanguageHandler {
     this.clippyPath = undefined;
   }
 
+  protected getToolchainProbe(): ToolchainProbe {
+    return { command: this.cargoPath || 'cargo', args: ['--version'] };
+  }
+
   protected async checkAvailability(): Promise<boolean> {
     try {
       if (this.rustcPath) {
@@ -95,10 +127,20 @@ export class RustHandler extends BaseLanguageHandler {
 
   async detectErrors(source: string, options?: DetectionOptions): Promise<LanguageError[]> {
     const errors: LanguageError[] = [];
-
-    // Syntax validation using rustc
-    const syntaxErrors = await this.validateSyntax(source);
-    errors.push(...syntaxErrors);
+    const filePath = options?.filePath;
+
+    // Check the real crate when the file is on disk unchanged, so imports and modules resolve
+    const manifest = filePath && this.cargoPath && await this.isUnmodifiedOnDisk(filePath, source)
+      ? await findUpwards(filePath, ['Cargo.toml'], options?.workspaceRoot)
+      : undefined;
+
+    if (filePath && manifest) {
+      errors.push(...await this.checkWorkspaceFile(manifest, filePath));
+    } else {
+      // Syntax validation using rustc
+      const syntaxErrors = await this.validateSyntax(source, filePath);
+      errors.push(...syntaxErrors);
+    }
 
     // Clippy analysis
     if (this.clippyPath && options?.enableLinting !== false) {
@@ -109,18 +151,21 @@ export class RustHandler extends BaseLanguageHandler {
     return errors;
   }
 
-  protected async validateSyntax(source: string): Promise<LanguageError[]> {
+  protected async validateSyntax(source: string, filePath = 'temp.rs'): Promise<LanguageError[]> {
     try {
       if (this.cargoPath) {
-        return await this.validateWithCargo(source);
+        return await this.validateWithCargo(source, filePath);
       } else if (this.rustcPath) {
-        return await this.validateWithRustc(source);
+        return await this.validateWithRustc(source, filePath);
       }
       return [];
     } catch (error) {
+      if (isToolNotFoundError(error) || isCancellationError(error)) {
+        throw error;
+      }
       return [this.createError(
         `Syntax validation failed: ${error instanceof Error ? error.message : 'Unknown error'}`,
-        'temp.rs',
+        filePath,
         1,
         1,
         'error'
@@ -128,7 +173,27 @@ export class RustHandler extends BaseLanguageHandler {
     }
   }
 
-  private async validateWithCargo(source: string): Promise<LanguageError[]> {
+  /**
+   * Run `cargo check` on the crate that owns the file and keep its diagnostics
+   */
+  private async checkWorkspaceFile(manifest: string, filePath: string): Promise<LanguageError[]> {
+    const crateDir = dirname(manifest);
+    const target = resolve(filePath);
+
+    const result = await this.runCommand(this.cargoPath!, [
+      'check',
+      '--message-format=json',
+      '--manifest-path', manifest
+    ], { cwd: crateDir });
+
+    // Paths are relative to the workspace root, which may sit above the crate
+    const errors = this.parseCargoOutput(result.stdout, fileName =>
+      resolve(crateDir, fileName) === target || target.endsWith(`${sep}${fileName}`) ? target : undefined
+    );
+    return this.withToolchainFailure('cargo check', result, errors, filePath);
+  }
+
+  private async validateWithCargo(source: string, filePath: string): Promise<LanguageError[]> {
     const tempDir = `/tmp/rust-syntax-check-${Date.now()}`;
     const srcDir = `${tempDir}/src`;
     const mainFile = `${srcDir}/main.rs`;
@@ -151,7 +216,8 @@ edition = "2021"
       // Cleanup
       await fs.rm(tempDir, { recursive: true, force: true }).catch(() => {});
 
-      return this.parseCargoOutput(result.stdout, 'temp.rs');
+      const errors = this.parseCargoOutput(result.stdout, () => filePath);
+      return this.withToolchainFailure('cargo check', result, errors, filePath);
     } catch (error) {
       // Cleanup on error
       await fs.rm(tempDir, { recursive: true, force: true }).catch(() => {});
@@ -159,7 +225,18 @@ edition = "2021"
     }
   }
 
-  private async validateWithRustc(source: string): Promise<LanguageError[]> {
+  /**
+   * Report a failed cargo run that emitted no compiler messages at all (for example
+   * a broken manifest), instead of returning no diagnostics
+   */
+  private withToolchainFailure(tool: string, result: CommandResult, errors: LanguageError[], filePath: string): LanguageError[] {
+    if (this.isUnparsedFailure(result, errors) && !result.stdout.includes('"reason":"compiler-message"')) {
+      return [this.createToolchainError(tool, result, filePath)];
+    }
+    return errors;
+  }
+
+  private async validateWithRustc(source: string, filePath: string): Promise<LanguageError[]> {
     const tempFile = `/tmp/rustc-check-${Date.now()}.rs`;
     
     try {
@@ -175,7 +252,7 @@ edition = "2021"
       // Cleanup
       await fs.unlink(tempFile).catch(() => {});
 
-      return this.parseRustcOutput(result.stderr, 'temp.rs');
+      return this.parseCargoOutput(result.stderr, () => filePath);
     } catch (error) {
       // Cleanup on error
       await fs.unlink(tempFile).catch(() => {});
@@ -214,7 +291,7 @@ edition = "2021"
       // Cleanup
       await fs.rm(tempDir, { recursive: true, force: true }).catch(() => {});
 
-      return this.parseCargoOutput(result.stdout, filePath);
+      return this.parseCargoOutput(result.stdout, () => filePath);
     } catch (error) {
       // Cleanup on error
       await fs.rm(tempDir, { recursive: true, force: true }).catch(() => {});
@@ -223,29 +300,30 @@ edition = "2021"
     }
   }
 
-  private parseCargoOutput(output: string, filePath: string): LanguageError[] {
+  /**
+   * Parse a JSON diagnostic stream. Accepts both cargo's `compiler-message`
+   * envelopes and bare rustc `--error-format=json` diagnostics.
+   */
+  private parseCargoOutput(output: string, resolveFile: SpanFileResolver): LanguageError[] {
     const errors: LanguageError[] = [];
     const lines = output.split('\n');
 
     for (const line of lines) {
       if (!line.trim()) continue;
-      
+
       try {
         const message = JSON.parse(line);
-        
-        if (message.message && message.message.spans) {
-          for (const span of message.message.spans) {
-            if (span.is_primary) {
-              errors.push(this.createError(
-                message.message.message,
-                filePath,
-                span.line_start || 1,
-                span.column_start || 1,
-                this.mapRustSeverity(message.message.level),
-                message.message.code?.code
-              ));
-            }
-          }
+        let diagnostic: RustDiagnostic | undefined;
+
+        if (message.reason === 'compiler-message') {
+          diagnostic = message.message;
+        } else if (message.reason === undefined && Array.isArray(message.spans)) {
+          diagnostic = message;
+        }
+
+        const error = diagnostic && this.convertRustDiagnostic(diagnostic, resolveFile);
+        if (error) {
+          errors.push(error);
         }
       } catch {
         // Skip invalid JSON lines
@@ -255,36 +333,59 @@ edition = "2021"
     return errors;
   }
 
-  private parseRustcOutput(output: string, filePath: string): LanguageError[] {
-    const errors: LanguageError[] = [];
-    const lines = output.split('\n');
+  /**
+   * Convert one rustc diagnostic anchored at its primary span. Secondary span labels
+   * and child notes/help become related information instead of separate errors.
+   */
+  private convertRustDiagnostic(diagnostic: RustDiagnostic, resolveFile: SpanFileResolver): LanguageError | undefined {
+    const primary = diagnostic.spans.find(span => span.is_primary);
+    if (!primary) {
+      // Summaries such as "aborting due to 2 previous errors" have no location
+      return undefined;
+    }
 
-    for (const line of lines) {
-      if (!line.trim()) continue;
-      
-      try {
-        const message = JSON.parse(line);
-        
-        if (message.message && message.spans) {
-          for (const span of message.spans) {
-            if (span.is_primary) {
-              errors.push(this.createError(
-                message.message,
-                filePath,
-                span.line_start || 1,
-                span.column_start || 1,
-                this.mapRustSeverity(message.level),
-                message.code
-              ));
-            }
-          }
-        }
-      } catch {
-        // Skip invalid JSON lines
+    const file = resolveFile(primary.file_name);
+    if (!file) {
+      return undefined;
+    }
+
+    const error = this.createError(
+      primary.label ? `${diagnostic.message}: ${primary.label}` : diagnostic.message,
+      file,
+      primary.line_start || 1,
+      primary.column_start || 1,
+      this.mapRustSeverity(diagnostic.level),
+      diagnostic.code?.code
+    );
+    error.location.endLine = primary.line_end || error.location.line;
+    error.location.endColumn = primary.column_end || error.location.column;
+    error.location.byteStart = primary.byte_start;
+    error.location.byteEnd = primary.byte_end;
+
+    const related: RelatedInformation[] = [];
+    const toLocation = (span: RustSpan | undefined) => ({
+      file: span ? this.normalizePath(resolveFile(span.file_name) || span.file_name) : error.location.file,
+      line: span?.line_start || error.location.line,
+      column: span?.column_start || error.location.column
+    });
+
+    for (const span of diagnostic.spans) {
+      if (span !== primary && span.label) {
+        related.push({ location: toLocation(span), message: span.label });
       }
     }
 
-    return errors;
+    for (const child of diagnostic.children || []) {
+      const span = child.spans.find(candidate => candidate.is_primary) || child.spans[0];
+      const replacement = span?.suggested_replacement;
+      related.push({
+        location: toLocation(span),
+        message: `${child.level}: ${child.message}${replacement ? `: \`${replacement}\`` : ''}`
+      });
+    }
+
+    error.relatedInformation = related;
+    return error;
   }
 
   private mapRustSeverity(level: string): 'error' | 'warning' | 'info' | 'hint' {
@@ -380,48 +481,6 @@ edition = "2021"
     ];
   }
 
-  private async findExecutable(name: string): Promise<string | undefined> {
-    try {
-      const result = await this.runCommand('which', [name]);
-      return result.exitCode === 0 ? result.stdout.trim() : undefined;
-    } catch {
-      return undefined;
-    }
-  }
-
-  private async runCommand(command: string, args: string[], options?: { cwd?: string }): Promise<{
-    stdout: string;
-    stderr: string;
-    exitCode: number;
-  }> {
-    return new Promise((resolve, reject) => {
-      const child = spawn(command, args, { 
-        stdio: 'pipe',
-        cwd: options?.cwd
-      });
-      let stdout = '';
-      let stderr = '';
-
-      child.stdout?.on('data', (data) => {
-        stdout += data.toString();
-      });
-
-      child.stderr?.on('data', (data) => {
-        stderr += data.toString();
-      });
-
-      child.on('close', (code) => {
-        resolve({
-          stdout,
-          stderr,
-          exitCode: code || 0
-        });
-      });
-
-      child.on('error', reject);
-    });
-  }
-
   private calculateComplexity(source: string): number {
     const patterns = [
       /\bif\b/g,
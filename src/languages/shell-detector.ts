@@ -0,0 +1,303 @@
+/**
+ * Shell script detector backed by shellcheck's JSON output
+ */
+
+import { promises as fs } from 'fs';
+import { tmpdir } from 'os';
+import { basename, dirname, join, resolve } from 'path';
+import { BaseLanguageHandler, type ToolchainProbe } from './base-language-handler.js';
+import type {
+  ColumnEncoding,
+  DetectionOptions,
+  LanguageError,
+  StackFrame,
+  LanguageDebugCapabilities,
+  LanguageDebugConfig,
+  LanguageDebugSession,
+  PerformanceAnalysis
+} from '../types/languages.js';
+import { SupportedLanguage } from '../types/languages.js';
+import { Logger } from '../utils/logger.js';
+import { isCancellationError } from '../utils/cancellation.js';
+import { ToolNotFoundError, isToolNotFoundError } from '../utils/errors.js';
+import { formatMessage } from '../utils/messages.js';
+
+/** `source` of shellcheck diagnostics */
+export const SHELLCHECK_SOURCE = 'shellcheck';
+
+/** One edit of a shellcheck fix. Columns are 1-based and the end is exclusive. */
+export interface ShellcheckReplacement {
+  line: number;
+  column: number;
+  endLine: number;
+  endColumn: number;
+  replacement: string;
+  precedence?: number;
+}
+
+/** An entry of the `comments` array printed by `shellcheck --format=json1` */
+export interface ShellcheckComment {
+  file: string;
+  line: number;
+  endLine: number;
+  column: number;
+  endColumn: number;
+  level: 'error' | 'warning' | 'info' | 'style';
+  code: number;
+  message: string;
+  fix: { replacements: ShellcheckReplacement[] } | null;
+}
+
+/** `file: line N: message`, as printed by bash and sh when a script fails */
+const SHELL_ERROR_LINE = /^(.+?): line (\d+): (.+)$/;
+
+/**
+ * Parse `shellcheck --format=json1` output. Anything that is not the expected
+ * JSON object yields no comments, so the caller can report the failed run.
+ */
+export function parseShellcheckOutput(output: string): ShellcheckComment[] {
+  try {
+    const parsed = JSON.parse(output) as { comments?: unknown };
+    return Array.isArray(parsed.comments) ? parsed.comments as ShellcheckComment[] : [];
+  } catch {
+    return [];
+  }
+}
+
+/**
+ * Describe a shellcheck fix as a textual replacement of the flagged text, e.g.
+ * ``Replace `$1` with `"$1"` ``. Returns undefined when the comment has no fix
+ * or the fix does not fit the source.
+ */
+export function describeShellcheckFix(source: string, comment: ShellcheckComment): string | undefined {
+  const replacements = comment.fix?.replacements || [];
+  if (replacements.length === 0) {
+    return undefined;
+  }
+
+  const lineStarts = [0];
+  for (let index = source.indexOf('\n'); index !== -1; index = source.indexOf('\n', index + 1)) {
+    lineStarts.push(index + 1);
+  }
+  const offsetOf = (line: number, column: number): number | undefined => {
+    const start = lineStarts[line - 1];
+    return start === undefined ? undefined : Math.min(start + column - 1, source.length);
+  };
+
+  const edits: Array<{ start: number; end: number; text: string; precedence: number }> = [];
+  for (const replacement of replacements) {
+    const start = offsetOf(replacement.line, replacement.column);
+    const end = offsetOf(replacement.endLine, replacement.endColumn);
+    if (start === undefined || end === undefined || end < start) {
+      return undefined;
+    }
+    edits.push({ start, end, text: replacement.replacement, precedence: replacement.precedence ?? 0 });
+  }
+
+  // The flagged text plus whatever the edits reach outside it
+  const flaggedStart = offsetOf(comment.line, comment.column) ?? edits[0]!.start;
+  const flaggedEnd = offsetOf(comment.endLine, comment.endColumn) ?? edits[0]!.end;
+  const spanStart = Math.min(flaggedStart, ...edits.map(edit => edit.start));
+  const spanEnd = Math.max(flaggedEnd, ...edits.map(edit => edit.end));
+
+  // Applied back to front so earlier offsets stay valid
+  let fixed = source.slice(spanStart, spanEnd);
+  for (const edit of edits.sort((a, b) => b.start - a.start || b.precedence - a.precedence)) {
+    fixed = fixed.slice(0, edit.start - spanStart) + edit.text + fixed.slice(edit.end - spanStart);
+  }
+
+  const original = source.slice(spanStart, spanEnd);
+  return original ? formatMessage('shellcheck.replace', { original, fixed }) : formatMessage('shellcheck.insert', { fixed });
+}
+
+export class ShellDetector extends BaseLanguageHandler {
+  private shellcheckPath: string | undefined;
+
+  constructor(options: Record<string, unknown> = {}, logger?: Logger) {
+    super(SupportedLanguage.SHELL, options, logger);
+  }
+
+  getFileExtensions(): string[] {
+    return ['.sh', '.bash'];
+  }
+
+  /** shellcheck's json1 columns count characters, a tab as one, and the BOM among them */
+  getColumnEncoding(): ColumnEncoding {
+    return { unit: 'codepoint', includesBom: true };
+  }
+
+  getConfigFiles(): string[] {
+    return ['.shellcheckrc', 'shellcheckrc'];
+  }
+
+  protected async doInitialize(): Promise<void> {
+    this.shellcheckPath = await this.findExecutable('shellcheck');
+    if (!this.shellcheckPath) {
+      throw new ToolNotFoundError('shellcheck', 'shellcheck not found on PATH. Please install ShellCheck to check shell scripts.');
+    }
+
+    this.logger.info('Shell detector initialized', {
+      shellcheckPath: this.shellcheckPath
+    });
+  }
+
+  protected async doDispose(): Promise<void> {
+    this.shellcheckPath = undefined;
+  }
+
+  protected getToolchainProbe(): ToolchainProbe {
+    return { command: this.shellcheckPath || 'shellcheck', args: ['--version'] };
+  }
+
+  protected async checkAvailability(): Promise<boolean> {
+    try {
+      const result = await this.runCommand(this.shellcheckPath || 'shellcheck', ['--version']);
+      return result.exitCode === 0;
+    } catch {
+      return false;
+    }
+  }
+
+  /**
+   * Check a script with shellcheck. A saved file is checked in place so its
+   * `.shellcheckrc` and sourced files are found; unsaved buffers are written to a
+   * temporary file of the same name, keeping the extension shellcheck infers the
+   * shell from. Without linting only error-level findings are reported.
+   */
+  async detectErrors(source: string, options?: DetectionOptions): Promise<LanguageError[]> {
+    const filePath = options?.filePath;
+    const args = ['--format=json1', ...(options?.enableLinting === false ? ['--severity=error'] : [])];
+
+    if (filePath && await this.isUnmodifiedOnDisk(filePath, source)) {
+      return this.runShellcheck(args, resolve(filePath), source, filePath);
+    }
+    return this.validateSyntax(source, filePath, args);
+  }
+
+  protected async validateSyntax(source: string, filePath?: string, args: string[] = ['--format=json1']): Promise<LanguageError[]> {
+    const reportedFile = filePath || 'script.sh';
+    const tempDir = await fs.mkdtemp(join(tmpdir(), 'shellcheck-'));
+    const tempFile = join(tempDir, basename(reportedFile));
+
+    try {
+      await fs.writeFile(tempFile, source);
+      return await this.runShellcheck(args, tempFile, source, reportedFile);
+    } catch (error) {
+      if (isToolNotFoundError(error) || isCancellationError(error)) {
+        throw error;
+      }
+      return [this.createError(
+        `Shell script check failed: ${error instanceof Error ? error.message : 'Unknown error'}`,
+        reportedFile,
+        1,
+        1,
+        'error'
+      )];
+    } finally {
+      await fs.rm(tempDir, { recursive: true, force: true }).catch(() => {});
+    }
+  }
+
+  private async runShellcheck(args: string[], target: string, source: string, reportedFile: string): Promise<LanguageError[]> {
+    const cwd = dirname(target);
+    const result = await this.runCommand(this.shellcheckPath || 'shellcheck', [...args, target], { cwd });
+
+    // Exit code 1 means findings; without any, a non-zero exit is a usage or I/O error
+    const comments = parseShellcheckOutput(result.stdout);
+    if (this.isUnparsedFailure(result, comments)) {
+      return [this.createToolchainError('shellcheck', result, reportedFile)];
+    }
+
+    return comments
+      .filter(comment => resolve(cwd, comment.file) === target)
+      .map(comment => this.convertComment(comment, source, reportedFile));
+  }
+
+  private convertComment(comment: ShellcheckComment, source: string, filePath: string): LanguageError {
+    const error = this.createError(
+      comment.message,
+      filePath,
+      comment.line,
+      comment.column,
+      this.mapShellcheckLevel(comment.level),
+      `SC${comment.code}`
+    );
+    error.source = SHELLCHECK_SOURCE;
+    error.location.endLine = Math.max(error.location.line, comment.endLine || comment.line);
+    error.location.endColumn = Math.max(1, comment.endColumn || comment.column);
+
+    const suggestedFix = describeShellcheckFix(source, comment);
+    if (suggestedFix) {
+      error.suggestedFix = suggestedFix;
+    }
+    return error;
+  }
+
+  private mapShellcheckLevel(level: ShellcheckComment['level']): 'error' | 'warning' | 'info' | 'hint' {
+    switch (level) {
+      case 'error': return 'error';
+      case 'warning': return 'warning';
+      case 'info': return 'info';
+      case 'style': return 'hint';
+      default: return 'warning';
+    }
+  }
+
+  parseStackTrace(stackTrace: string): StackFrame[] {
+    const frames: StackFrame[] = [];
+
+    for (const line of stackTrace.split('\n')) {
+      // deploy.sh: line 12: kubectl: command not found
+      const match = line.match(SHELL_ERROR_LINE);
+      if (match) {
+        frames.push({
+          function: '<main>',
+          file: match[1] || '<unknown>',
+          line: parseInt(match[2] || '1'),
+          column: 1
+        });
+      }
+    }
+
+    return frames;
+  }
+
+  getDebugCapabilities(): LanguageDebugCapabilities {
+    return {
+      supportsBreakpoints: false,
+      supportsConditionalBreakpoints: false,
+      supportsStepInto: false,
+      supportsStepOver: false,
+      supportsStepOut: false,
+      supportsVariableInspection: false,
+      supportsWatchExpressions: false,
+      supportsHotReload: false,
+      supportsRemoteDebugging: false,
+      // Legacy properties for backward compatibility
+      breakpoints: false,
+      stepDebugging: false,
+      variableInspection: false,
+      callStackInspection: false,
+      conditionalBreakpoints: false,
+      hotReload: false,
+      profiling: false,
+      memoryInspection: false
+    };
+  }
+
+  async createDebugSession(_config: LanguageDebugConfig): Promise<LanguageDebugSession> {
+    throw new Error('Debugging is not supported for shell scripts');
+  }
+
+  async analyzePerformance(source: string): Promise<PerformanceAnalysis> {
+    return {
+      complexity: 1,
+      suggestions: [],
+      metrics: { linesOfCode: source.split('\n').length, cyclomaticComplexity: 1 }
+    };
+  }
+
+  protected getErrorPatterns(): RegExp[] {
+    return [SHELL_ERROR_LINE];
+  }
+}
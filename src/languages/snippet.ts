@@ -0,0 +1,87 @@
+/**
+ * Preparation of code fragments for analysis by file-oriented toolchains
+ */
+
+import type { LanguageId } from '../types/languages.js';
+
+export type SnippetWrapper = 'none' | 'package' | 'main';
+
+export interface PreparedSnippet {
+  /** Complete source handed to the language handler */
+  source: string;
+  wrapper: SnippetWrapper;
+  /** Lines inserted before the snippet; subtract to get snippet lines */
+  lineOffset: number;
+  /** Number of lines in the original snippet */
+  lineCount: number;
+}
+
+export interface SnippetPosition {
+  line: number;
+  /** Whether the position was moved back into the snippet by the wrapper offset */
+  shifted: boolean;
+  /** Whether the tool pointed at generated wrapper code rather than the snippet */
+  inWrapper: boolean;
+}
+
+const GO_TOP_LEVEL = /^(func|type|var|const|import)\b/m;
+
+/**
+ * Wrap a bare Go fragment so `go build` accepts it.
+ * Declarations get a `package main` clause (and an empty `main` when none is
+ * declared); anything else is treated as statements inside `func main`.
+ */
+function prepareGoSnippet(code: string, lineCount: number): PreparedSnippet {
+  if (/^\s*package\s+\w+/m.test(code)) {
+    return { source: code, wrapper: 'none', lineOffset: 0, lineCount };
+  }
+
+  if (GO_TOP_LEVEL.test(code)) {
+    const needsMain = !/^func\s+main\s*\(\s*\)/m.test(code);
+    return {
+      source: `package main\n\n${code}\n${needsMain ? '\nfunc main() {}\n' : ''}`,
+      wrapper: 'package',
+      lineOffset: 2,
+      lineCount
+    };
+  }
+
+  return {
+    source: `package main\n\nfunc main() {\n${code}\n}\n`,
+    wrapper: 'main',
+    lineOffset: 3,
+    lineCount
+  };
+}
+
+/**
+ * Turn a snippet into a source file for the given language
+ */
+export function prepareSnippet(language: LanguageId, code: string): PreparedSnippet {
+  const lineCount = code.split('\n').length;
+
+  if (language === 'go') {
+    return prepareGoSnippet(code, lineCount);
+  }
+
+  return { source: code, wrapper: 'none', lineOffset: 0, lineCount };
+}
+
+/**
+ * Map a line reported against the prepared source back onto the snippet.
+ * Lines inside the wrapper are clamped to the nearest snippet line.
+ */
+export function toSnippetLine(line: number, snippet: PreparedSnippet): SnippetPosition {
+  if (snippet.wrapper === 'none') {
+    return { line, shifted: false, inWrapper: false };
+  }
+
+  const relative = line - snippet.lineOffset;
+  const clamped = Math.min(Math.max(relative, 1), snippet.lineCount);
+
+  return {
+    line: clamped,
+    shifted: true,
+    inWrapper: clamped !== relative
+  };
+}
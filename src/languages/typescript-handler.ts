@@ -2,10 +2,10 @@
  * TypeScript language handler implementation
  */
 
-import { spawn } from 'child_process';
 import { promises as fs } from 'fs';
-import { BaseLanguageHandler } from './base-language-handler.js';
+import { BaseLanguageHandler, type ToolchainProbe } from './base-language-handler.js';
 import type {
+  ColumnEncoding,
   DetectionOptions,
   LanguageError,
   StackFrame,
@@ -16,11 +16,16 @@ import type {
 } from '../types/languages.js';
 import { SupportedLanguage } from '../types/languages.js';
 import { Logger } from '../utils/logger.js';
+import {
+  TypeScriptProjectChecker,
+  mapTypeScriptSeverity
+} from './typescript-project-checker.js';
 
 export class TypeScriptHandler extends BaseLanguageHandler {
   private tscPath: string | undefined;
   private eslintPath: string | undefined;
   private nodePath: string | undefined;
+  private projectChecker: TypeScriptProjectChecker | undefined;
 
   constructor(options: Record<string, unknown> = {}, logger?: Logger) {
     super(SupportedLanguage.TYPESCRIPT, options, logger);
@@ -30,6 +35,11 @@ export class TypeScriptHandler extends BaseLanguageHandler {
     return ['.ts', '.tsx', '.d.ts'];
   }
 
+  /** tsc columns are offsets into JavaScript strings, read with the BOM removed */
+  getColumnEncoding(): ColumnEncoding {
+    return { unit: 'utf16', includesBom: false };
+  }
+
   getConfigFiles(): string[] {
     return [
       'tsconfig.json',
@@ -51,6 +61,11 @@ export class TypeScriptHandler extends BaseLanguageHandler {
       throw new Error('TypeScript compiler not found. Please install TypeScript to use TypeScript error detection.');
     }
 
+    this.projectChecker = new TypeScriptProjectChecker(
+      this.tscPath,
+      (command, args, cwd) => this.runCommand(command, args, { cwd })
+    );
+
     // Find Node.js for runtime support
     this.nodePath = await this.findExecutable('node');
     if (!this.nodePath) {
@@ -71,11 +86,16 @@ export class TypeScriptHandler extends BaseLanguageHandler {
   }
 
   protected async doDispose(): Promise<void> {
+    this.projectChecker = undefined;
     this.tscPath = undefined;
     this.nodePath = undefined;
     this.eslintPath = undefined;
   }
 
+  protected getToolchainProbe(): ToolchainProbe {
+    return { command: this.tscPath || 'tsc', args: ['--version'] };
+  }
+
   protected async checkAvailability(): Promise<boolean> {
     try {
       const result = await this.runCommand(this.tscPath!, ['--version']);
@@ -88,9 +108,14 @@ export class TypeScriptHandler extends BaseLanguageHandler {
   async detectErrors(source: string, options?: DetectionOptions): Promise<LanguageError[]> {
     const errors: LanguageError[] = [];
 
-    // TypeScript compilation check
-    const compileErrors = await this.validateSyntax(source);
-    errors.push(...compileErrors);
+    // TypeScript compilation check, project-wide when the file is on disk unchanged
+    const filePath = options?.filePath;
+    if (filePath && this.projectChecker && await this.isUnmodifiedOnDisk(filePath, source)) {
+      errors.push(...await this.checkProjectFile(filePath, options?.workspaceRoot));
+    } else {
+      const compileErrors = await this.validateSyntax(source);
+      errors.push(...compileErrors);
+    }
 
     // ESLint validation if available and enabled
     if (this.eslintPath && options?.enableLinting !== false) {
@@ -145,6 +170,29 @@ export class TypeScriptHandler extends BaseLanguageHandler {
     }
   }
 
+  private async checkProjectFile(filePath: string, workspaceRoot?: string): Promise<LanguageError[]> {
+    try {
+      const diagnostics = await this.projectChecker!.check(filePath, workspaceRoot);
+
+      return diagnostics.map(diagnostic => this.createError(
+        diagnostic.message,
+        diagnostic.file,
+        diagnostic.line,
+        diagnostic.column,
+        mapTypeScriptSeverity(diagnostic.code, diagnostic.category),
+        diagnostic.code
+      ));
+    } catch (error) {
+      return [this.createError(
+        `TypeScript compilation failed: ${error instanceof Error ? error.message : 'Unknown error'}`,
+        filePath,
+        1,
+        1,
+        'error'
+      )];
+    }
+  }
+
   private async runESLint(source: string, filePath: string): Promise<LanguageError[]> {
     if (!this.eslintPath) {
       return [];
@@ -201,7 +249,7 @@ export class TypeScriptHandler extends BaseLanguageHandler {
           filePath,
           parseInt(match[2] || '1'),
           parseInt(match[3] || '1'),
-          match[4] === 'error' ? 'error' : 'warning',
+          mapTypeScriptSeverity(match[5] || '', match[4] === 'error' ? 'error' : 'warning'),
           match[5]
         ));
       }
@@ -324,45 +372,6 @@ export class TypeScriptHandler extends BaseLanguageHandler {
     ];
   }
 
-  private async findExecutable(name: string): Promise<string | undefined> {
-    try {
-      const result = await this.runCommand('which', [name]);
-      return result.exitCode === 0 ? result.stdout.trim() : undefined;
-    } catch {
-      return undefined;
-    }
-  }
-
-  private async runCommand(command: string, args: string[]): Promise<{
-    stdout: string;
-    stderr: string;
-    exitCode: number;
-  }> {
-    return new Promise((resolve, reject) => {
-      const child = spawn(command, args, { stdio: 'pipe' });
-      let stdout = '';
-      let stderr = '';
-
-      child.stdout?.on('data', (data) => {
-        stdout += data.toString();
-      });
-
-      child.stderr?.on('data', (data) => {
-        stderr += data.toString();
-      });
-
-      child.on('close', (code) => {
-        resolve({
-          stdout,
-          stderr,
-          exitCode: code || 0
-        });
-      });
-
-      child.on('error', reject);
-    });
-  }
-
   private calculateComplexity(source: string): number {
     const patterns = [
       /\bif\b/g,
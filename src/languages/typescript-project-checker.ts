@@ -0,0 +1,220 @@
+/**
+ * Project-wide `tsc --noEmit` runner shared by the TypeScript and JavaScript handlers
+ */
+
+import { promises as fs } from 'fs';
+import { dirname, isAbsolute, resolve } from 'path';
+import type { LanguageError } from '../types/languages.js';
+import type { CommandResult } from './base-language-handler.js';
+import { findUpwards } from '../utils/workspace-roots.js';
+import { currentCommandPlan } from '../utils/command-plan.js';
+
+export type CommandRunner = (command: string, args: string[], cwd: string) => Promise<CommandResult>;
+
+export interface TypeScriptDiagnostic {
+  file: string;
+  line: number;
+  column: number;
+  category: 'error' | 'warning' | 'message';
+  code: string;
+  message: string;
+}
+
+export interface TypeScriptProjectCheckerOptions {
+  /** How long a completed project run is reused for other files, in milliseconds */
+  reuseWindowMs?: number;
+}
+
+interface ProjectRun {
+  startedAt: number;
+  completedAt?: number;
+  diagnostics: Promise<Map<string, TypeScriptDiagnostic[]>>;
+}
+
+/**
+ * TypeScript codes that describe hygiene issues rather than broken code.
+ * tsc reports these as errors when the matching compiler option is on.
+ */
+const WARNING_CODES = new Set([
+  'TS6133', // declared but never read
+  'TS6138', // property declared but never read
+  'TS6192', // all imports are unused
+  'TS6196', // declared but never used
+  'TS6198', // all destructured elements are unused
+  'TS7027', // unreachable code
+  'TS7028', // unused label
+  'TS7029', // fallthrough case in switch
+  'TS7030', // not all code paths return a value
+]);
+
+const TSC_LINE = /^(.+?)\((\d+),(\d+)\): (error|warning|message) (TS\d+): (.+)$/;
+
+/**
+ * Map a TypeScript diagnostic onto a stable severity, independent of tsc's category
+ */
+export function mapTypeScriptSeverity(code: string, category: TypeScriptDiagnostic['category'] = 'error'): LanguageError['severity'] {
+  if (WARNING_CODES.has(code)) {
+    return 'warning';
+  }
+  // 8xxxx codes are JavaScript-only suggestions
+  if (/^TS8\d{4}$/.test(code) || category === 'message') {
+    return 'info';
+  }
+  return category === 'warning' ? 'warning' : 'error';
+}
+
+/**
+ * Parse `file(line,col): error TSxxxx: message` output.
+ * Relative file names are resolved against the directory tsc ran in.
+ */
+export function parseTscOutput(output: string, cwd: string): TypeScriptDiagnostic[] {
+  const diagnostics: TypeScriptDiagnostic[] = [];
+  let current: TypeScriptDiagnostic | undefined;
+
+  for (const line of output.split('\n')) {
+    const match = line.trimEnd().match(TSC_LINE);
+    if (match) {
+      const file = match[1] || '';
+      current = {
+        file: isAbsolute(file) ? file : resolve(cwd, file),
+        line: parseInt(match[2] || '1'),
+        column: parseInt(match[3] || '1'),
+        category: (match[4] || 'error') as TypeScriptDiagnostic['category'],
+        code: match[5] || '',
+        message: match[6] || 'Unknown error'
+      };
+      diagnostics.push(current);
+    } else if (current && /^\s+\S/.test(line)) {
+      // Message chains continue on indented lines
+      current.message += `\n${line.trim()}`;
+    } else {
+      current = undefined;
+    }
+  }
+
+  return diagnostics;
+}
+
+export class TypeScriptProjectChecker {
+  private runs = new Map<string, ProjectRun>();
+  private reuseWindowMs: number;
+
+  constructor(
+    private tscPath: string,
+    private run: CommandRunner,
+    options: TypeScriptProjectCheckerOptions = {}
+  ) {
+    this.reuseWindowMs = options.reuseWindowMs ?? 2000;
+  }
+
+  /**
+   * Find the nearest tsconfig.json at or above the file's directory, not going above `stopAt`
+   */
+  async findProjectConfig(filePath: string, stopAt?: string): Promise<string | undefined> {
+    return findUpwards(filePath, ['tsconfig.json'], stopAt);
+  }
+
+  /**
+   * Get diagnostics for a file on disk.
+   * Files in the same project share one `tsc --project` run; files without a
+   * tsconfig.json are checked on their own.
+   */
+  async check(filePath: string, workspaceRoot?: string): Promise<TypeScriptDiagnostic[]> {
+    const fullPath = resolve(filePath);
+    const tsconfig = await this.findProjectConfig(fullPath, workspaceRoot);
+
+    if (!tsconfig) {
+      return this.checkSingleFile(fullPath);
+    }
+
+    const run = await this.getProjectRun(tsconfig, fullPath);
+    const diagnostics = await run.diagnostics;
+    return diagnostics.get(fullPath) || [];
+  }
+
+  /**
+   * Forget completed project runs so the next check re-runs tsc
+   */
+  invalidate(): void {
+    this.runs.clear();
+  }
+
+  private async getProjectRun(tsconfig: string, filePath: string): Promise<ProjectRun> {
+    // A planned run neither reuses a real one nor is reused
+    if (currentCommandPlan()) {
+      return { startedAt: Date.now(), diagnostics: this.runProject(tsconfig) };
+    }
+    const existing = this.runs.get(tsconfig);
+
+    if (existing && await this.canReuse(existing, filePath)) {
+      return existing;
+    }
+
+    const run: ProjectRun = {
+      startedAt: Date.now(),
+      diagnostics: this.runProject(tsconfig)
+    };
+    this.runs.set(tsconfig, run);
+
+    run.diagnostics
+      .then(() => {
+        run.completedAt = Date.now();
+      })
+      .catch(() => {
+        // Failed runs are not reused
+        if (this.runs.get(tsconfig) === run) {
+          this.runs.delete(tsconfig);
+        }
+      });
+
+    return run;
+  }
+
+  private async canReuse(run: ProjectRun, filePath: string): Promise<boolean> {
+    // An in-flight run already covers every file in the project
+    if (run.completedAt === undefined) {
+      return true;
+    }
+
+    if (Date.now() - run.completedAt > this.reuseWindowMs) {
+      return false;
+    }
+
+    // The file must not have changed since tsc read it
+    try {
+      const stats = await fs.stat(filePath);
+      return stats.mtimeMs < run.startedAt;
+    } catch {
+      return false;
+    }
+  }
+
+  private async runProject(tsconfig: string): Promise<Map<string, TypeScriptDiagnostic[]>> {
+    const cwd = dirname(tsconfig);
+    const result = await this.run(this.tscPath, ['--noEmit', '--pretty', 'false', '--project', tsconfig], cwd);
+    const byFile = new Map<string, TypeScriptDiagnostic[]>();
+
+    for (const diagnostic of parseTscOutput(result.stdout + result.stderr, cwd)) {
+      const list = byFile.get(diagnostic.file) || [];
+      list.push(diagnostic);
+      byFile.set(diagnostic.file, list);
+    }
+
+    return byFile;
+  }
+
+  private async checkSingleFile(filePath: string): Promise<TypeScriptDiagnostic[]> {
+    const cwd = dirname(filePath);
+    const result = await this.run(this.tscPath, [
+      '--noEmit',
+      '--pretty', 'false',
+      '--skipLibCheck',
+      '--strict',
+      '--allowJs',
+      filePath
+    ], cwd);
+
+    return parseTscOutput(result.stdout + result.stderr, cwd)
+      .filter(diagnostic => diagnostic.file === filePath);
+  }
+}
@@ -0,0 +1,298 @@
+/**
+ * Diagnostic Watch Manager
+ * Re-analyzes files as they change on disk and reports diagnostic diffs
+ */
+
+import { EventEmitter } from 'events';
+import { watch, type FSWatcher } from 'chokidar';
+import { promises as fs } from 'fs';
+import { resolve } from 'path';
+
+import type { LanguageHandlerManager } from '@/languages/language-handler-manager.js';
+import { Logger } from '@/utils/logger.js';
+import { generateId } from '@/utils/helpers.js';
+import { isNoFilesError } from '@/utils/errors.js';
+import { canonicalPath } from '@/utils/paths.js';
+import {
+  dedupeDiagnostics,
+  diffDiagnostics,
+  sortDiagnostics,
+  toDiagnosticRecord,
+  type DiagnosticRecord
+} from '@/utils/diagnostics.js';
+
+export const DEFAULT_WATCH_DEBOUNCE_MS = 300;
+
+export interface WatchOptions {
+  debounceMs?: number;
+}
+
+export interface WatchSessionInfo {
+  id: string;
+  path: string;
+  debounceMs: number;
+  startedAt: Date;
+  filesWithDiagnostics: number;
+  diagnostics: number;
+}
+
+export interface DiagnosticsChangedEvent {
+  watchId: string;
+  path: string;
+  changedFiles: string[];
+  added: DiagnosticRecord[];
+  removed: DiagnosticRecord[];
+  unchanged: number;
+}
+
+interface WatchSession {
+  id: string;
+  root: string;
+  debounceMs: number;
+  startedAt: Date;
+  watcher: FSWatcher;
+  pending: Set<string>;
+  timer: NodeJS.Timeout | null;
+  flushing: Promise<void> | null;
+  diagnostics: Map<string, DiagnosticRecord[]>;
+}
+
+export class DiagnosticWatchManager extends EventEmitter {
+  private sessions = new Map<string, WatchSession>();
+  /** Bumped by `stopAll`, so watches still starting when it ran are not registered */
+  private generation = 0;
+  private logger: Logger;
+
+  constructor(private languageHandlerManager: LanguageHandlerManager, logger?: Logger) {
+    super();
+    this.logger = logger || new Logger('info', { logFile: undefined });
+  }
+
+  /**
+   * Start watching a file or directory. Returns the session and its baseline
+   * diagnostics. Paths are resolved through symlinks, as diagnostics name files.
+   */
+  async startWatch(
+    targetPath: string,
+    options: WatchOptions = {}
+  ): Promise<{ session: WatchSessionInfo; diagnostics: DiagnosticRecord[] }> {
+    await fs.stat(targetPath);
+    const root = await canonicalPath(targetPath);
+
+    const debounceMs = options.debounceMs ?? DEFAULT_WATCH_DEBOUNCE_MS;
+    const generation = this.generation;
+    // A directory without supported files yet is watched for the files it gains
+    const initial = await this.languageHandlerManager.analyzePath(root).catch(error => {
+      if (isNoFilesError(error)) {
+        return [];
+      }
+      throw error;
+    });
+    if (generation !== this.generation) {
+      throw new Error(`Watch of ${root} was stopped before it started`);
+    }
+    const baseline = dedupeDiagnostics(initial.map(toDiagnosticRecord));
+
+    const diagnostics = new Map<string, DiagnosticRecord[]>();
+    for (const record of baseline) {
+      const list = diagnostics.get(record.file) || [];
+      list.push(record);
+      diagnostics.set(record.file, list);
+    }
+
+    const watcher = watch(root, {
+      ignored: ['**/node_modules/**', '**/.git/**', '**/dist/**', '**/build/**', '**/vendor/**'],
+      persistent: true,
+      ignoreInitial: true,
+      followSymlinks: false
+    });
+
+    const session: WatchSession = {
+      id: generateId('watch'),
+      root,
+      debounceMs,
+      startedAt: new Date(),
+      watcher,
+      pending: new Set(),
+      timer: null,
+      flushing: null,
+      diagnostics
+    };
+
+    const onFileEvent = (filePath: string) => {
+      void canonicalPath(resolve(root, filePath)).then(file => this.queueFile(session, file));
+    };
+    watcher.on('add', onFileEvent);
+    watcher.on('change', onFileEvent);
+    watcher.on('unlink', onFileEvent);
+    watcher.on('error', (error: Error) => {
+      this.logger.error('Diagnostic watcher error', { watchId: session.id, error });
+    });
+
+    this.sessions.set(session.id, session);
+    this.logger.info('Started diagnostic watch', { watchId: session.id, root, debounceMs });
+
+    return { session: this.toInfo(session), diagnostics: sortDiagnostics(baseline) };
+  }
+
+  /**
+   * Stop a watch session, cancelling any pending re-analysis
+   */
+  async stopWatch(watchId: string): Promise<boolean> {
+    const session = this.sessions.get(watchId);
+    if (!session) {
+      return false;
+    }
+
+    this.sessions.delete(watchId);
+    if (session.timer) {
+      clearTimeout(session.timer);
+      session.timer = null;
+    }
+    session.pending.clear();
+
+    await session.watcher.close();
+    // Let an in-flight analysis settle so nothing outlives the session
+    await session.flushing?.catch(() => {});
+
+    this.logger.info('Stopped diagnostic watch', { watchId });
+    return true;
+  }
+
+  async stopAll(): Promise<void> {
+    this.generation++;
+    await Promise.all(Array.from(this.sessions.keys()).map(id => this.stopWatch(id)));
+  }
+
+  listWatches(): WatchSessionInfo[] {
+    return Array.from(this.sessions.values()).map(session => this.toInfo(session));
+  }
+
+  private queueFile(session: WatchSession, filePath: string): void {
+    if (!this.sessions.has(session.id)) {
+      return;
+    }
+
+    // Ignore files no handler understands, unless they carried diagnostics before
+    if (!this.languageHandlerManager.detectLanguage(filePath) && !session.diagnostics.has(filePath)) {
+      return;
+    }
+
+    session.pending.add(filePath);
+    this.scheduleFlush(session);
+  }
+
+  private scheduleFlush(session: WatchSession): void {
+    if (session.timer) {
+      clearTimeout(session.timer);
+    }
+
+    session.timer = setTimeout(() => {
+      session.timer = null;
+      // Runs are serialized; changes arriving mid-run are picked up afterwards
+      if (session.flushing) {
+        return;
+      }
+      session.flushing = this.flush(session).finally(() => {
+        session.flushing = null;
+        if (session.pending.size > 0 && this.sessions.has(session.id)) {
+          this.scheduleFlush(session);
+        }
+      });
+    }, session.debounceMs);
+  }
+
+  private async flush(session: WatchSession): Promise<void> {
+    const files = Array.from(session.pending).sort();
+    session.pending.clear();
+
+    const added: DiagnosticRecord[] = [];
+    const removed: DiagnosticRecord[] = [];
+    let unchanged = 0;
+
+    for (const file of files) {
+      let current: DiagnosticRecord[] | undefined;
+      try {
+        current = await this.analyzeWatchedFile(file);
+      } catch (error) {
+        // A failed analysis says nothing about the file, so its diagnostics stay until the next change
+        this.logger.warn('Failed to re-analyze watched file', { watchId: session.id, file, error });
+      }
+
+      if (!this.sessions.has(session.id)) {
+        return;
+      }
+      if (current === undefined) {
+        continue;
+      }
+
+      const diff = diffDiagnostics(session.diagnostics.get(file) || [], current);
+      added.push(...diff.added);
+      removed.push(...diff.removed);
+      unchanged += diff.unchanged.length;
+
+      if (current.length > 0) {
+        session.diagnostics.set(file, current);
+      } else {
+        session.diagnostics.delete(file);
+      }
+    }
+
+    if (added.length === 0 && removed.length === 0) {
+      return;
+    }
+
+    const event: DiagnosticsChangedEvent = {
+      watchId: session.id,
+      path: session.root,
+      changedFiles: files,
+      added: sortDiagnostics(added),
+      removed: sortDiagnostics(removed),
+      unchanged
+    };
+
+    this.logger.debug('Watched diagnostics changed', {
+      watchId: session.id,
+      added: added.length,
+      removed: removed.length
+    });
+    this.emit('diagnostics-changed', event);
+  }
+
+  /**
+   * Diagnostics of a watched file; deleted files have none
+   */
+  private async analyzeWatchedFile(file: string): Promise<DiagnosticRecord[]> {
+    try {
+      await fs.access(file);
+    } catch {
+      return [];
+    }
+
+    try {
+      return dedupeDiagnostics((await this.languageHandlerManager.analyzeFile(file)).map(toDiagnosticRecord));
+    } catch (error) {
+      // Deleted between the check and the read
+      if ((error as NodeJS.ErrnoException).code === 'ENOENT') {
+        return [];
+      }
+      throw error;
+    }
+  }
+
+  private toInfo(session: WatchSession): WatchSessionInfo {
+    let diagnostics = 0;
+    for (const records of session.diagnostics.values()) {
+      diagnostics += records.length;
+    }
+
+    return {
+      id: session.id,
+      path: session.root,
+      debounceMs: session.debounceMs,
+      startedAt: session.startedAt,
+      filesWithDiagnostics: session.diagnostics.size,
+      diagnostics
+    };
+  }
+}
@@ -0,0 +1,193 @@
+/**
+ * `error://<abs-path>` resources exposing the current diagnostics of a file
+ */
+
+import { EventEmitter } from 'events';
+import { promises as fs } from 'fs';
+import { resolve } from 'path';
+
+import type { MCPContent } from '@/types/index.js';
+import type { LanguageHandlerManager } from '@/languages/language-handler-manager.js';
+import type {
+  DiagnosticWatchManager,
+  DiagnosticsChangedEvent,
+} from '@/monitoring/diagnostic-watch-manager.js';
+import { Logger } from '@/utils/logger.js';
+import { canonicalPath } from '@/utils/paths.js';
+import {
+  dedupeDiagnostics,
+  sortDiagnostics,
+  summarizeDiagnostics,
+  toDiagnosticRecord,
+  type DiagnosticRecord,
+} from '@/utils/diagnostics.js';
+import type { ResourceTemplateProvider } from './resource-manager.js';
+
+export const DIAGNOSTIC_RESOURCE_SCHEME = 'error://';
+
+export const DIAGNOSTIC_RESOURCE_TEMPLATE = Object.freeze({
+  uriTemplate: `${DIAGNOSTIC_RESOURCE_SCHEME}{path}`,
+  name: 'File Diagnostics',
+  description: 'Current diagnostics for a file, by absolute path',
+  mimeType: 'application/json',
+});
+
+export function isDiagnosticResourceUri(uri: string): boolean {
+  return uri.startsWith(DIAGNOSTIC_RESOURCE_SCHEME);
+}
+
+export function toDiagnosticResourceUri(filePath: string): string {
+  return `${DIAGNOSTIC_RESOURCE_SCHEME}${encodeURI(resolve(filePath))}`;
+}
+
+export function parseDiagnosticResourceUri(uri: string): string {
+  if (!isDiagnosticResourceUri(uri)) {
+    throw new Error(`Not a diagnostics resource: ${uri}`);
+  }
+
+  const path = decodeURI(uri.slice(DIAGNOSTIC_RESOURCE_SCHEME.length));
+  if (!path) {
+    throw new Error(`Missing file path in resource URI: ${uri}`);
+  }
+  return resolve(path);
+}
+
+/**
+ * Serves `error://` reads and reports when a subscribed file's diagnostics change.
+ * Emits `'resource-updated'` with the resource URI.
+ */
+export class DiagnosticResourceProvider extends EventEmitter implements ResourceTemplateProvider {
+  private inFlight = new Map<string, Promise<DiagnosticRecord[]>>();
+  /** Subscribed file path, with symlinks resolved as in diagnostics -> URI and the watch session backing it */
+  private subscriptions = new Map<string, { uri: string; watchId: string | null }>();
+  private logger: Logger;
+
+  constructor(
+    private languageHandlerManager: LanguageHandlerManager,
+    private watchManager: DiagnosticWatchManager,
+    logger?: Logger
+  ) {
+    super();
+    this.logger = logger || new Logger('info', { logFile: undefined });
+    this.watchManager.on('diagnostics-changed', (event: DiagnosticsChangedEvent) => this.onDiagnosticsChanged(event));
+  }
+
+  matches(uri: string): boolean {
+    return isDiagnosticResourceUri(uri);
+  }
+
+  /**
+   * Read the diagnostics of the file named by the URI.
+   * Missing files yield an empty list rather than an error.
+   */
+  async read(uri: string): Promise<MCPContent> {
+    const path = parseDiagnosticResourceUri(uri);
+    const diagnostics = await this.getDiagnostics(path);
+
+    return {
+      type: 'text',
+      mimeType: 'application/json',
+      text: JSON.stringify({
+        uri,
+        path,
+        summary: summarizeDiagnostics(diagnostics),
+        diagnostics: sortDiagnostics(diagnostics),
+      }, null, 2),
+    };
+  }
+
+  async subscribe(uri: string): Promise<void> {
+    const path = await canonicalPath(parseDiagnosticResourceUri(uri));
+    if (this.subscriptions.has(path)) {
+      return;
+    }
+
+    const subscription: { uri: string; watchId: string | null } = { uri, watchId: null };
+    this.subscriptions.set(path, subscription);
+
+    try {
+      const { session } = await this.watchManager.startWatch(path);
+      // Unsubscribed while the baseline was being computed
+      if (this.subscriptions.get(path) !== subscription) {
+        await this.watchManager.stopWatch(session.id);
+        return;
+      }
+      subscription.watchId = session.id;
+    } catch (error) {
+      // The file may not exist yet; changes from other watch sessions still notify
+      this.logger.debug('Could not watch subscribed resource', {
+        uri,
+        error: error instanceof Error ? error.message : error,
+      });
+    }
+  }
+
+  async unsubscribe(uri: string): Promise<void> {
+    const path = await canonicalPath(parseDiagnosticResourceUri(uri));
+    const subscription = this.subscriptions.get(path);
+    if (!subscription) {
+      return;
+    }
+
+    this.subscriptions.delete(path);
+    if (subscription.watchId) {
+      await this.watchManager.stopWatch(subscription.watchId);
+    }
+  }
+
+  async dispose(): Promise<void> {
+    await Promise.all(Array.from(this.subscriptions.values()).map(subscription => this.unsubscribe(subscription.uri)));
+  }
+
+  /**
+   * Concurrent reads of the same file share one analysis
+   */
+  private getDiagnostics(path: string): Promise<DiagnosticRecord[]> {
+    const existing = this.inFlight.get(path);
+    if (existing) {
+      return existing;
+    }
+
+    const pending = this.analyze(path).finally(() => {
+      this.inFlight.delete(path);
+    });
+    this.inFlight.set(path, pending);
+    return pending;
+  }
+
+  private async analyze(path: string): Promise<DiagnosticRecord[]> {
+    try {
+      const stats = await fs.stat(path);
+      if (!stats.isFile()) {
+        return [];
+      }
+    } catch {
+      return [];
+    }
+
+    try {
+      const errors = await this.languageHandlerManager.analyzeFile(path);
+      return dedupeDiagnostics(errors.map(toDiagnosticRecord));
+    } catch (error) {
+      // Deleted between the stat and the read
+      if ((error as NodeJS.ErrnoException).code === 'ENOENT') {
+        return [];
+      }
+      throw error;
+    }
+  }
+
+  private onDiagnosticsChanged(event: DiagnosticsChangedEvent): void {
+    if (this.subscriptions.size === 0) {
+      return;
+    }
+
+    const files = new Set([...event.added, ...event.removed].map(record => resolve(record.file)));
+    for (const file of files) {
+      const subscription = this.subscriptions.get(file);
+      if (subscription) {
+        this.emit('resource-updated', subscription.uri);
+      }
+    }
+  }
+}
@@ -9,10 +9,13 @@ import {
   ErrorCode,
   ListPromptsRequestSchema,
   ListResourcesRequestSchema,
+  ListResourceTemplatesRequestSchema,
   ListToolsRequestSchema,
   McpError,
   ReadResourceRequestSchema,
   GetPromptRequestSchema,
+  SubscribeRequestSchema,
+  UnsubscribeRequestSchema,
 } from '@modelcontextprotocol/sdk/types.js';
 
 import type {
@@ -21,11 +24,22 @@ import type {
 import { EventEmitter } from './event-emitter.js';
 import { PluginManager } from './plugin-manager.js';
 import { ResourceManager } from './resource-manager.js';
+import {
+  DIAGNOSTIC_RESOURCE_TEMPLATE,
+  DiagnosticResourceProvider,
+  isDiagnosticResourceUri,
+} from './diagnostic-resources.js';
 import { ToolRegistry } from './tool-registry.js';
 import { PromptRegistry } from './prompt-registry.js';
 import { ErrorDetectorManager } from '@/detectors/error-detector-manager.js';
 import { LanguageHandlerManager } from '@/languages/language-handler-manager.js';
+import { CacheWarmer } from '@/languages/cache-warmer.js';
+import {
+  DiagnosticWatchManager,
+  type DiagnosticsChangedEvent,
+} from '@/monitoring/diagnostic-watch-manager.js';
 import { Logger } from '@/utils/logger.js';
+import { configureMessages } from '@/utils/messages.js';
 
 export class ErrorDebuggingMCPServer extends EventEmitter {
   private server: Server;
@@ -35,14 +49,19 @@ export class ErrorDebuggingMCPServer extends EventEmitter {
   private promptRegistry: PromptRegistry;
   private errorDetectorManager: ErrorDetectorManager;
   private languageHandlerManager: LanguageHandlerManager;
+  private watchManager: DiagnosticWatchManager;
+  private cacheWarmer: CacheWarmer;
+  private diagnosticResources: DiagnosticResourceProvider;
   private config: ServerConfig;
   private _isRunning = false;
+  private stopping: Promise<void> | undefined;
   private logger: Logger;
 
   constructor(config: ServerConfig, logger?: Logger) {
     super();
     this.config = config;
     this.logger = logger || new Logger('info', { logFile: undefined });
+    configureMessages(config.localization);
     this.server = new Server(
       {
         name: config.server.name,
@@ -63,8 +82,33 @@ export class ErrorDebuggingMCPServer extends EventEmitter {
     });
     this.languageHandlerManager = new LanguageHandlerManager({
       autoDetectLanguages: true,
+      ...(config.detection.timeouts && { timeouts: config.detection.timeouts }),
+      ...(config.detection.workspaceRoots && { workspaceRoots: config.detection.workspaceRoots }),
+      ...(config.detection.toolchainRetry && { defaultOptions: { retry: config.detection.toolchainRetry } }),
+      ...(config.detection.concurrency && { concurrency: config.detection.concurrency }),
+      ...(config.detection.severityOverrides && { severityOverrides: config.detection.severityOverrides }),
+      ...(config.detection.execution && { execution: config.detection.execution }),
+      ...(config.detection.toolchainCacheTtlMs !== undefined && { toolchainCacheTtlMs: config.detection.toolchainCacheTtlMs }),
+      ...(config.detection.minAnalysisIntervalMs && { minAnalysisIntervalMs: config.detection.minAnalysisIntervalMs }),
+      ...(config.detection.maxFileSize !== undefined && { maxFileSize: config.detection.maxFileSize }),
+      ...(config.detection.offline !== undefined && { offline: config.detection.offline }),
+      ...(config.detection.commands && { commands: config.detection.commands }),
       logger: this.logger,
     });
+    this.watchManager = new DiagnosticWatchManager(this.languageHandlerManager, this.logger);
+    this.cacheWarmer = new CacheWarmer(this.languageHandlerManager, config.detection.warmup, this.logger);
+    this.watchManager.on('diagnostics-changed', (event: DiagnosticsChangedEvent) => {
+      void this.sendDiagnosticsChanged(event);
+    });
+    this.diagnosticResources = new DiagnosticResourceProvider(
+      this.languageHandlerManager,
+      this.watchManager,
+      this.logger
+    );
+    this.diagnosticResources.on('resource-updated', (uri: string) => {
+      void this.sendResourceUpdated(uri);
+    });
+    this.resourceManager.registerResourceTemplate(DIAGNOSTIC_RESOURCE_TEMPLATE, this.diagnosticResources);
 
     this.setupHandlers();
   }
@@ -93,14 +137,19 @@ export class ErrorDebuggingMCPServer extends EventEmitter {
       };
     });
 
-    this.server.setRequestHandler(CallToolRequestSchema, async (request) => {
+    this.server.setRequestHandler(CallToolRequestSchema, async (request, extra?: { signal?: AbortSignal }) => {
       const { name, arguments: args } = request.params;
+      if (this.stopping) {
+        throw new McpError(ErrorCode.InternalError, 'Server is shutting down');
+      }
 
       try {
-        const result = await this.toolRegistry.callTool(name, args || {});
+        const result = await this.toolRegistry.callTool(name, args || {}, extra?.signal ? { signal: extra.signal } : {});
         return {
           content: result.content,
           isError: result.isError,
+          // Clients tell failure modes apart by this code rather than by the text
+          ...(result.errorCode && { _meta: { errorCode: result.errorCode } }),
         };
       } catch (error) {
         const message = error instanceof Error ? error.message : 'Unknown error';
@@ -115,13 +164,19 @@ export class ErrorDebuggingMCPServer extends EventEmitter {
       };
     });
 
+    this.server.setRequestHandler(ListResourceTemplatesRequestSchema, async () => {
+      return {
+        resourceTemplates: this.resourceManager.listResourceTemplates(),
+      };
+    });
+
     this.server.setRequestHandler(ReadResourceRequestSchema, async (request) => {
       const { uri } = request.params;
       
       try {
         const content = await this.resourceManager.readResource(uri);
         return {
-          contents: [content],
+          contents: [{ uri, ...content }],
         };
       } catch (error) {
         const message = error instanceof Error ? error.message : 'Unknown error';
@@ -129,6 +184,21 @@ export class ErrorDebuggingMCPServer extends EventEmitter {
       }
     });
 
+    this.server.setRequestHandler(SubscribeRequestSchema, async (request) => {
+      // Only diagnostics resources change; other subscriptions are accepted and never fire
+      if (isDiagnosticResourceUri(request.params.uri)) {
+        await this.diagnosticResources.subscribe(request.params.uri);
+      }
+      return {};
+    });
+
+    this.server.setRequestHandler(UnsubscribeRequestSchema, async (request) => {
+      if (isDiagnosticResourceUri(request.params.uri)) {
+        await this.diagnosticResources.unsubscribe(request.params.uri);
+      }
+      return {};
+    });
+
     // Prompt handlers
     this.server.setRequestHandler(ListPromptsRequestSchema, async () => {
       return {
@@ -194,6 +264,8 @@ export class ErrorDebuggingMCPServer extends EventEmitter {
       this.logger.debug('Connecting managers to tool registry...');
       this.toolRegistry.setErrorDetectorManager(this.errorDetectorManager);
       this.toolRegistry.setLanguageHandlerManager(this.languageHandlerManager);
+      this.toolRegistry.setWatchManager(this.watchManager);
+      this.toolRegistry.setCacheWarmer(this.cacheWarmer);
 
       // Register core tools and resources
       this.logger.debug('Registering core components...');
@@ -227,8 +299,15 @@ export class ErrorDebuggingMCPServer extends EventEmitter {
       }
 
       await this.server.connect(transport);
+      // A client that disconnects gets the same cleanup as a stop request
+      this.server.onclose = () => {
+        void this.stop().catch(() => undefined);
+      };
       this.logger.logPerformance('transport-connection', Date.now() - transportStartTime);
 
+      // Runs in the background; tool calls are served while it does
+      this.cacheWarmer.start();
+
       this._isRunning = true;
       const totalStartupTime = Date.now() - startTime;
 
@@ -255,15 +334,32 @@ export class ErrorDebuggingMCPServer extends EventEmitter {
     }
   }
 
+  /**
+   * Stop the server. New tool calls are refused, the cache warm-up and watches
+   * stop, and running analyses are canceled and given `server.shutdownGraceMs`
+   * to finish, so no spawned tool outlives the server. Concurrent calls share
+   * one shutdown.
+   */
   async stop(): Promise<void> {
     if (!this._isRunning) {
       return;
     }
+    if (!this.stopping) {
+      this.stopping = this.shutdown().finally(() => {
+        this.stopping = undefined;
+      });
+    }
+    return this.stopping;
+  }
 
+  private async shutdown(): Promise<void> {
     try {
+      await this.diagnosticResources.dispose();
+      await this.cacheWarmer.cancel();
+      await this.watchManager.stopAll();
+      await this.languageHandlerManager.shutdown(this.config.server.shutdownGraceMs);
       await this.server.close();
       await this.errorDetectorManager.stop();
-      await this.languageHandlerManager.dispose();
       await this.pluginManager.shutdown();
 
       this._isRunning = false;
@@ -274,6 +370,40 @@ export class ErrorDebuggingMCPServer extends EventEmitter {
     }
   }
 
+  /**
+   * Push a watched diagnostics diff to the client
+   */
+  private async sendDiagnosticsChanged(event: DiagnosticsChangedEvent): Promise<void> {
+    try {
+      await this.server.notification({
+        method: 'notifications/diagnostics/changed',
+        params: { ...event },
+      });
+    } catch (error) {
+      this.logger.warn('Failed to send diagnostics notification', {
+        watchId: event.watchId,
+        error: error instanceof Error ? error.message : error,
+      });
+    }
+  }
+
+  /**
+   * Tell the client a subscribed resource has new content
+   */
+  private async sendResourceUpdated(uri: string): Promise<void> {
+    try {
+      await this.server.notification({
+        method: 'notifications/resources/updated',
+        params: { uri },
+      });
+    } catch (error) {
+      this.logger.warn('Failed to send resource update notification', {
+        uri,
+        error: error instanceof Error ? error.message : error,
+      });
+    }
+  }
+
   private async registerCoreComponents(): Promise<void> {
     // Register core tools
     await this.toolRegistry.registerTool({
@@ -335,6 +465,408 @@ export class ErrorDebuggingMCPServer extends EventEmitter {
       },
     });
 
+    await this.toolRegistry.registerTool({
+      name: 'list-errors',
+      description: 'List structured diagnostics for a file or directory as JSON',
+      inputSchema: {
+        type: 'object',
+        properties: {
+          path: {
+            type: 'string',
+            description: 'File or directory to analyze',
+          },
+          severity: {
+            type: 'string',
+            enum: ['error', 'warning', 'all'],
+            description: 'Minimum severity to include (warning includes errors); defaults to the workspace config, then all',
+          },
+          maxResults: {
+            type: 'number',
+            description: 'Maximum number of diagnostics to return (default: workspace config, then 1000); errors are kept first',
+          },
+          offset: {
+            type: 'number',
+            description: 'Index of the first diagnostic to return, for paging through large results (default 0)',
+          },
+          limit: {
+            type: 'number',
+            description: 'Page size when paging with offset (default maxResults)',
+          },
+          dedupe: {
+            type: 'boolean',
+            description: 'Merge identical diagnostics reported by several tools (default true)',
+          },
+          dedupKey: {
+            type: 'array',
+            items: {
+              type: 'string',
+              enum: ['file', 'line', 'column', 'message', 'code', 'severity'],
+            },
+            description: 'Fields that identify a duplicate (default file, line, column, message)',
+          },
+          format: {
+            type: 'string',
+            enum: ['json', 'text', 'sarif'],
+            description: 'json (default) for structured output, text for a report grouped by file, sarif for a SARIF 2.1.0 log for code scanning',
+          },
+          changedOnly: {
+            type: 'boolean',
+            description: 'Only report diagnostics on lines changed relative to `since` according to git diff',
+          },
+          since: {
+            type: 'string',
+            description: 'Git ref to diff against (default HEAD); implies changedOnly',
+          },
+          include: {
+            type: 'array',
+            items: { type: 'string' },
+            description: 'Only report diagnostics in files matching one of these globs, such as internal/api/**',
+          },
+          exclude: {
+            type: 'array',
+            items: { type: 'string' },
+            description: 'Never report diagnostics in files matching these globs, such as **/*_test.go; wins over include',
+          },
+          overlay: {
+            type: 'object',
+            additionalProperties: { type: 'string' },
+            description: 'Unsaved file contents by path, relative to path or absolute, analyzed in place of the files on disk without modifying them',
+          },
+          packages: {
+            type: ['string', 'array'],
+            items: { type: 'string' },
+            description: 'Go package patterns such as ./... or ./internal/store, resolved in the path directory and checked with go build and go vet; cannot be combined with overlay',
+          },
+          saveBaseline: {
+            type: 'boolean',
+            description: 'Keep the matching diagnostics on the server and return their baselineId for compare-diagnostics',
+            default: false,
+          },
+          includeRaw: {
+            type: 'boolean',
+            description: 'Attach the tool output lines behind each diagnostic and return the full output of every tool run under rawOutputs; bypasses the cache',
+            default: false,
+          },
+          offline: {
+            type: 'boolean',
+            description: 'Analyze without downloading modules or toolchains and without updating go.mod or go.sum; defaults to the workspace config and server setting',
+          },
+        },
+        required: ['path'],
+      },
+    });
+
+    await this.toolRegistry.registerTool({
+      name: 'watch-errors',
+      description: 'Watch a file or directory and push diagnostic changes as notifications',
+      inputSchema: {
+        type: 'object',
+        properties: {
+          path: {
+            type: 'string',
+            description: 'File or directory to watch',
+          },
+          debounceMs: {
+            type: 'number',
+            description: 'Quiet period after the last change before re-analyzing (default 300)',
+          },
+        },
+        required: ['path'],
+      },
+    });
+
+    await this.toolRegistry.registerTool({
+      name: 'stop-watch',
+      description: 'Stop a watch started with watch-errors',
+      inputSchema: {
+        type: 'object',
+        properties: {
+          watchId: {
+            type: 'string',
+            description: 'Watch session ID returned by watch-errors',
+          },
+        },
+        required: ['watchId'],
+      },
+    });
+
+    await this.toolRegistry.registerTool({
+      name: 'analyze-snippet',
+      description: 'Analyze an in-memory code fragment without saving it; diagnostics use snippet line numbers',
+      inputSchema: {
+        type: 'object',
+        properties: {
+          language: {
+            type: 'string',
+            description: 'Language of the snippet, e.g. go or typescript',
+          },
+          code: {
+            type: 'string',
+            description: 'Source code to analyze',
+          },
+          filename: {
+            type: 'string',
+            description: 'File name reported in diagnostics and used for file-name based rules such as Go build suffixes',
+          },
+          offline: {
+            type: 'boolean',
+            description: 'Analyze without downloading modules or toolchains and without updating go.mod or go.sum; defaults to the workspace config and server setting',
+          },
+        },
+        required: ['language', 'code'],
+      },
+    });
+
+    await this.toolRegistry.registerTool({
+      name: 'analyze-change',
+      description: 'Re-analyze one file an editor changed, scoped to its package and reusing cached results of the other files when the change cannot affect them',
+      inputSchema: {
+        type: 'object',
+        properties: {
+          path: {
+            type: 'string',
+            description: 'The changed file',
+          },
+          content: {
+            type: 'string',
+            description: 'Unsaved contents of the file; omit after the file was saved',
+          },
+          allFiles: {
+            type: 'boolean',
+            description: 'Return the diagnostics of every file of the package instead of only the changed one',
+            default: false,
+          },
+          severity: {
+            type: 'string',
+            enum: ['error', 'warning', 'all'],
+            description: 'Minimum severity to include (warning includes errors); default all',
+            default: 'all',
+          },
+          offline: {
+            type: 'boolean',
+            description: 'Analyze without downloading modules or toolchains and without updating go.mod or go.sum; defaults to the workspace config and server setting',
+          },
+        },
+        required: ['path'],
+      },
+    });
+
+    await this.toolRegistry.registerTool({
+      name: 'plan',
+      description: 'Show which detectors analyzing a file or directory would select and the exact commands they would execute, with working directory and environment, without running anything',
+      inputSchema: {
+        type: 'object',
+        properties: {
+          path: {
+            type: 'string',
+            description: 'File or directory to plan the analysis of',
+          },
+          enableLinting: {
+            type: 'boolean',
+            description: 'Plan the linters as well as the compilers, as detect-errors does',
+            default: true,
+          },
+          offline: {
+            type: 'boolean',
+            description: 'Plan an analysis without downloading modules or toolchains and without updating go.mod or go.sum; defaults to the workspace config and server setting',
+          },
+          fullEnv: {
+            type: 'boolean',
+            description: 'Report the whole environment of each command instead of only the variables it adds, with credentials redacted',
+            default: false,
+          },
+        },
+        required: ['path'],
+      },
+    });
+
+    await this.toolRegistry.registerTool({
+      name: 'capabilities',
+      description: 'Report which language detectors are usable here: toolchain availability, versions and applied settings',
+      inputSchema: {
+        type: 'object',
+        properties: {
+          path: {
+            type: 'string',
+            description: 'File or directory whose workspace config file should be reported and merged into the settings',
+          },
+          refresh: {
+            type: 'boolean',
+            description: 'Forget cached toolchain paths and versions and probe again, e.g. after installing a tool',
+          },
+        },
+      },
+    });
+
+    await this.toolRegistry.registerTool({
+      name: 'stats',
+      description: 'Report analysis counts, per-detector timings, cache effectiveness, spawned processes, active watchers and the progress of the cache warm-up',
+      inputSchema: {
+        type: 'object',
+        properties: {
+          reset: {
+            type: 'boolean',
+            description: 'Start counting over after returning the current numbers, e.g. before benchmarking a change',
+            default: false,
+          },
+        },
+      },
+    });
+
+    await this.toolRegistry.registerTool({
+      name: 'run-and-detect',
+      description: 'Build and run a Go program or its tests with a timeout and report build errors or the panic it crashes with. Executes code, so it must be enabled in the detection.execution config',
+      inputSchema: {
+        type: 'object',
+        properties: {
+          path: {
+            type: 'string',
+            description: 'Package directory, or a file in it',
+          },
+          mode: {
+            type: 'string',
+            enum: ['run', 'test'],
+            description: 'Run the program, or build and run its test binary',
+            default: 'run',
+          },
+          args: {
+            type: 'array',
+            items: { type: 'string' },
+            description: 'Arguments passed to the program, such as -test.run=TestName',
+          },
+          timeoutMs: {
+            type: 'number',
+            description: 'Kill the run after this many milliseconds; capped by the configured limit',
+          },
+        },
+        required: ['path'],
+      },
+    });
+
+    await this.toolRegistry.registerTool({
+      name: 'set-detector-enabled',
+      description: 'Switch a language detector off or back on for later analyses without restarting the server. A disabled detector is neither probed nor run',
+      inputSchema: {
+        type: 'object',
+        properties: {
+          language: {
+            type: 'string',
+            description: 'Language of the detector, such as go',
+          },
+          enabled: {
+            type: 'boolean',
+            description: 'Whether the detector takes part in analyses',
+          },
+        },
+        required: ['language', 'enabled'],
+      },
+    });
+
+    await this.toolRegistry.registerTool({
+      name: 'analyze-batch',
+      description: 'Detect errors in several files or directories in one call. Files of one module are checked together, so shared compiler runs are reused',
+      inputSchema: {
+        type: 'object',
+        properties: {
+          paths: {
+            type: 'array',
+            items: { type: 'string' },
+            description: 'Files or directories to analyze',
+          },
+          severity: {
+            type: 'string',
+            enum: ['error', 'warning', 'all'],
+            description: 'Minimum severity to include (warning includes errors); default all',
+            default: 'all',
+          },
+          dedupe: {
+            type: 'boolean',
+            description: 'Merge identical diagnostics reported by several tools (default true)',
+            default: true,
+          },
+          offline: {
+            type: 'boolean',
+            description: 'Analyze without downloading modules or toolchains and without updating go.mod or go.sum; defaults to the workspace config and server setting',
+          },
+        },
+        required: ['paths'],
+      },
+    });
+
+    // A diagnostic as list-errors returns it; only the fields used for matching are required
+    const diagnosticInput = {
+      type: 'object',
+      properties: {
+        file: { type: 'string' },
+        line: { type: 'number' },
+        column: { type: 'number' },
+        code: { type: ['string', 'number'] },
+        message: { type: 'string' },
+        severity: { type: 'string', enum: ['error', 'warning', 'info', 'hint'] },
+      },
+      required: ['file', 'line', 'message'],
+    };
+
+    await this.toolRegistry.registerTool({
+      name: 'compare-diagnostics',
+      description: 'Compare diagnostics before and after a change: which were fixed, which are new and which persist. Diagnostics that moved a few lines still match',
+      inputSchema: {
+        type: 'object',
+        properties: {
+          baseline: {
+            oneOf: [
+              { type: 'string', description: 'baselineId returned by list-errors with saveBaseline' },
+              { type: 'array', items: diagnosticInput },
+            ],
+            description: 'Diagnostics before the change',
+          },
+          current: {
+            type: 'array',
+            items: diagnosticInput,
+            description: 'Diagnostics after the change; omit to analyze path instead',
+          },
+          path: {
+            type: 'string',
+            description: 'File or directory to analyze for the current diagnostics. Defaults to the stored baseline\'s path',
+          },
+          lineTolerance: {
+            type: 'number',
+            description: 'How many lines a diagnostic may move and still count as the same one; 0 requires the same line (default 3)',
+            default: 3,
+          },
+        },
+        required: ['baseline'],
+      },
+    });
+
+    await this.toolRegistry.registerTool({
+      name: 'explain-error',
+      description: 'Explain a common compiler error in depth, with a minimal example that reports it and its fix',
+      inputSchema: {
+        type: 'object',
+        properties: {
+          diagnostic: {
+            type: 'object',
+            description: 'A diagnostic as list-errors returns it; only message is required',
+            properties: {
+              message: { type: 'string' },
+              source: { type: 'string' },
+              code: { type: ['string', 'number', 'null'] },
+            },
+            required: ['message'],
+          },
+          message: {
+            type: 'string',
+            description: 'The error message, when no diagnostic is given',
+          },
+          source: {
+            type: 'string',
+            description: 'Tool or language that reported the message, such as go; every rule is tried when omitted',
+          },
+        },
+      },
+    });
+
     // Register more tools...
     // (Additional tool registrations will be added in the next iteration)
   }
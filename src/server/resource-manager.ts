@@ -2,11 +2,12 @@
  * Resource manager for handling MCP resources
  */
 
-import type { MCPResource, MCPContent } from '@/types/index.js';
+import type { MCPResource, MCPResourceTemplate, MCPContent } from '@/types/index.js';
 
 export class ResourceManager {
   private resources: Map<string, MCPResource> = new Map();
   private resourceProviders: Map<string, ResourceProvider> = new Map();
+  private templates: Array<{ template: MCPResourceTemplate; provider: ResourceTemplateProvider }> = [];
 
   constructor() {
     this.registerCoreResources();
@@ -28,21 +29,38 @@ export class ResourceManager {
     this.resourceProviders.delete(uri);
   }
 
+  /**
+   * Register a family of resources, such as `error://{path}`, served by one provider
+   */
+  registerResourceTemplate(template: MCPResourceTemplate, provider: ResourceTemplateProvider): void {
+    this.templates = this.templates.filter(entry => entry.template.uriTemplate !== template.uriTemplate);
+    this.templates.push({ template, provider });
+  }
+
   listResources(): MCPResource[] {
     return Array.from(this.resources.values());
   }
 
+  listResourceTemplates(): MCPResourceTemplate[] {
+    return this.templates.map(entry => entry.template);
+  }
+
   getResource(uri: string): MCPResource | undefined {
     return this.resources.get(uri);
   }
 
   async readResource(uri: string): Promise<MCPContent> {
     const provider = this.resourceProviders.get(uri);
-    if (!provider) {
-      throw new Error(`No provider registered for resource: ${uri}`);
+    if (provider) {
+      return await provider.read();
     }
 
-    return await provider.read();
+    const templated = this.templates.find(entry => entry.provider.matches(uri));
+    if (templated) {
+      return await templated.provider.read(uri);
+    }
+
+    throw new Error(`No provider registered for resource: ${uri}`);
   }
 
   private registerCoreResources(): void {
@@ -378,3 +396,8 @@ export class ResourceManager {
 interface ResourceProvider {
   read(): Promise<MCPContent>;
 }
+
+export interface ResourceTemplateProvider {
+  matches(uri: string): boolean;
+  read(uri: string): Promise<MCPContent>;
+}
@@ -2,19 +2,69 @@
  * Tool registry for managing MCP tools
  */
 
+import { promises as fs } from 'fs';
+import { dirname, relative, resolve } from 'path';
 import type { MCPTool, MCPToolResult } from '@/types/index.js';
 import type { ErrorDetectorManager } from '@/detectors/error-detector-manager.js';
 import type { LanguageHandlerManager } from '@/languages/language-handler-manager.js';
+import type { DiagnosticWatchManager } from '@/monitoring/diagnostic-watch-manager.js';
+import type { CacheWarmer } from '@/languages/cache-warmer.js';
 import { SupportedLanguage } from '@/types/languages.js';
 import { Logger } from '@/utils/logger.js';
+import {
+  DEFAULT_DEDUP_KEY,
+  DEFAULT_LINE_TOLERANCE,
+  DEFAULT_MAX_DIAGNOSTICS,
+  compareDiagnostics,
+  dedupeDiagnostics,
+  matchesSeverityFilter,
+  paginateDiagnostics,
+  parseDiagnosticRecord,
+  sortDiagnostics,
+  summarizeDiagnostics,
+  toDiagnosticRecord,
+  type DedupKeyField,
+  type DiagnosticRecord,
+  type DiagnosticSummary,
+  type SeverityFilter
+} from '@/utils/diagnostics.js';
+import { formatDiagnosticsText } from '@/utils/diagnostic-formatter.js';
+import { explainError } from '@/utils/explanations.js';
+import { redactWorkspaceConfig } from '@/utils/workspace-config.js';
+import { formatDiagnosticsSarif } from '@/utils/sarif.js';
+import { DiagnosticBaselineStore, type DiagnosticBaseline } from '@/utils/baselines.js';
+import { RawOutputRecorder, findRawLines, recordRawOutput } from '@/utils/raw-output.js';
+import { DEFAULT_DIFF_REF, getChangedLines, isChangedLine, type ChangeSet } from '@/utils/git-changes.js';
+import { isCancellationError } from '@/utils/cancellation.js';
+import { UnsupportedLanguageError, getErrorCode } from '@/utils/errors.js';
+import { compilePathFilter } from '@/utils/path-filter.js';
+import { analysisStats } from '@/utils/analysis-stats.js';
+import type { Overlay } from '@/utils/overlay.js';
+import { prepareSnippet, toSnippetLine } from '@/languages/snippet.js';
 
-export type ToolHandler = (args: Record<string, unknown>) => Promise<MCPToolResult>;
+export interface ToolCallContext {
+  /** Aborted when the client cancels the request or it times out */
+  signal?: AbortSignal;
+}
+
+export type ToolHandler = (args: Record<string, unknown>, context?: ToolCallContext) => Promise<MCPToolResult>;
+
+/**
+ * The failure mode of a tool error, so clients can branch on it instead of the text
+ */
+function errorCodeOf(error: unknown): Pick<MCPToolResult, 'errorCode'> {
+  const errorCode = getErrorCode(error);
+  return errorCode ? { errorCode } : {};
+}
 
 export class ToolRegistry {
   private tools: Map<string, MCPTool> = new Map();
   private handlers: Map<string, ToolHandler> = new Map();
   private errorDetectorManager: ErrorDetectorManager | null = null;
   private languageHandlerManager: LanguageHandlerManager | null = null;
+  private watchManager: DiagnosticWatchManager | null = null;
+  private cacheWarmer: CacheWarmer | null = null;
+  private baselines = new DiagnosticBaselineStore();
   private logger: Logger;
 
   constructor(logger?: Logger) {
@@ -71,7 +121,7 @@ export class ToolRegistry {
     return this.tools.get(name);
   }
 
-  async callTool(name: string, args: Record<string, unknown>): Promise<MCPToolResult> {
+  async callTool(name: string, args: Record<string, unknown>, context: ToolCallContext = {}): Promise<MCPToolResult> {
     const startTime = Date.now();
     this.logger.debug(`Calling tool: ${name}`, {
       toolName: name,
@@ -91,7 +141,7 @@ export class ToolRegistry {
     }
 
     try {
-      const result = await handler(args);
+      const result = await handler(args, context);
       const executionTime = Date.now() - startTime;
 
       this.logger.logPerformance(`tool-${name}-execution`, executionTime);
@@ -124,18 +174,58 @@ export class ToolRegistry {
           text: `Error executing tool ${name}: ${message}`,
         }],
         isError: true,
+        ...errorCodeOf(error),
       };
     }
   }
 
   private createDefaultHandler(toolName: string): ToolHandler {
-    return async (args: Record<string, unknown>): Promise<MCPToolResult> => {
+    return async (args: Record<string, unknown>, context: ToolCallContext = {}): Promise<MCPToolResult> => {
       switch (toolName) {
         case 'detect-errors':
-          return this.handleDetectErrors(args);
+          return this.handleDetectErrors(args, context);
         
         case 'analyze-error':
           return this.handleAnalyzeError(args);
+
+        case 'list-errors':
+          return this.handleListErrors(args, context);
+
+        case 'analyze-batch':
+          return this.handleAnalyzeBatch(args, context);
+
+        case 'analyze-snippet':
+          return this.handleAnalyzeSnippet(args, context);
+
+        case 'analyze-change':
+          return this.handleAnalyzeChange(args, context);
+
+        case 'plan':
+          return this.handlePlan(args, context);
+
+        case 'watch-errors':
+          return this.handleWatchErrors(args);
+
+        case 'stop-watch':
+          return this.handleStopWatch(args);
+
+        case 'capabilities':
+          return this.handleCapabilities(args);
+
+        case 'stats':
+          return this.handleStats(args);
+
+        case 'run-and-detect':
+          return this.handleRunAndDetect(args, context);
+
+        case 'set-detector-enabled':
+          return this.handleSetDetectorEnabled(args);
+
+        case 'compare-diagnostics':
+          return this.handleCompareDiagnostics(args, context);
+        
+        case 'explain-error':
+          return this.handleExplainError(args);
         
         case 'suggest-fixes':
           return this.handleSuggestFixes(args);
@@ -158,7 +248,7 @@ export class ToolRegistry {
     };
   }
 
-  private async handleDetectErrors(args: Record<string, unknown>): Promise<MCPToolResult> {
+  private async handleDetectErrors(args: Record<string, unknown>, context: ToolCallContext = {}): Promise<MCPToolResult> {
     const source = args['source'] as string;
     const language = args['language'] as string;
     const files = args['files'] as string[] || [];
@@ -168,7 +258,7 @@ export class ToolRegistry {
     try {
       // If a specific language is requested and we have files, use language handler manager
       if (language && files.length > 0 && this.languageHandlerManager) {
-        return await this.handleLanguageSpecificDetection(language, files, includeWarnings, source);
+        return await this.handleLanguageSpecificDetection(language, files, includeWarnings, source, context.signal);
       }
 
       // Otherwise, use the error detector manager for general detection
@@ -227,6 +317,7 @@ export class ToolRegistry {
           text: `Error detecting errors: ${error instanceof Error ? error.message : 'Unknown error'}`,
         }],
         isError: true,
+        ...errorCodeOf(error),
       };
     }
   }
@@ -235,62 +326,42 @@ export class ToolRegistry {
     language: string,
     files: string[],
     includeWarnings: boolean,
-    source: string
+    source: string,
+    signal?: AbortSignal
   ): Promise<MCPToolResult> {
     if (!this.languageHandlerManager) {
       throw new Error('Language handler manager not initialized');
     }
 
-    const fs = await import('fs/promises');
-    const path = await import('path');
-    const allErrors: any[] = [];
+    const records: DiagnosticRecord[] = [];
+    const failures: any[] = [];
+    const cacheHitsBefore = this.languageHandlerManager.getCacheStats().hits;
 
     // Convert language string to SupportedLanguage enum
     const supportedLanguage = language as SupportedLanguage;
 
     for (const filePath of files) {
       try {
-        // Read file content
-        const fullPath = path.resolve(filePath);
-        const fileContent = await fs.readFile(fullPath, 'utf-8');
-
-        // Detect errors using language handler
-        const languageErrors = await this.languageHandlerManager.detectErrors(
-          fileContent,
+        // Detect errors using language handler, reusing results for unchanged files
+        const languageErrors = await this.languageHandlerManager.analyzeFile(
+          filePath,
           supportedLanguage,
           {
-            filePath: fullPath,
             enableLinting: true,
             includeWarnings,
+            ...(signal && { signal }),
           }
         );
 
-        // Convert language errors to the expected format
-        for (const langError of languageErrors) {
-          allErrors.push({
-            id: `error-${Date.now()}-${Math.random().toString(36).substr(2, 9)}`,
-            message: langError.message,
-            type: `${language}Error`,
-            category: 'syntax',
-            severity: langError.severity === 'error' ? 'high' : 'low',
-            file: langError.location.file,
-            line: langError.location.line,
-            column: langError.location.column,
-            timestamp: new Date().toISOString(),
-            source: {
-              type: source,
-              tool: `${language}-handler`,
-              version: '1.0.0',
-              configuration: {
-                detector: source,
-              },
-            },
-          });
-        }
+        records.push(...languageErrors.map(toDiagnosticRecord));
       } catch (error) {
+        // A canceled request stops the whole batch rather than reporting per-file failures
+        if (isCancellationError(error)) {
+          throw error;
+        }
         this.logger.error(`Failed to process file ${filePath}`, error);
         // Add file processing error
-        allErrors.push({
+        failures.push({
           id: `error-${Date.now()}-${Math.random().toString(36).substr(2, 9)}`,
           message: `Failed to process file: ${error instanceof Error ? error.message : 'Unknown error'}`,
           type: 'FileProcessingError',
@@ -312,6 +383,37 @@ export class ToolRegistry {
       }
     }
 
+    // Package-level errors come back for every file of the package, so they are merged before counting
+    const diagnostics = dedupeDiagnostics(records);
+    const allErrors: any[] = [
+      ...diagnostics.map(record => ({
+        id: `error-${Date.now()}-${Math.random().toString(36).substr(2, 9)}`,
+        message: record.message,
+        type: `${language}Error`,
+        category: 'syntax',
+        severity: record.severity === 'error' ? 'high' : 'low',
+        file: record.file,
+        line: record.line,
+        column: record.column,
+        timestamp: new Date().toISOString(),
+        source: {
+          type: source,
+          tool: `${language}-handler`,
+          version: '1.0.0',
+          configuration: {
+            detector: source,
+          },
+        },
+      })),
+      ...failures,
+    ];
+
+    this.logger.debug('Language-specific detection finished', {
+      language,
+      files: files.length,
+      cacheHits: this.languageHandlerManager.getCacheStats().hits - cacheHitsBefore
+    });
+
     return {
       content: [{
         type: 'text',
@@ -321,6 +423,7 @@ export class ToolRegistry {
           files,
           includeWarnings,
           realTime: false,
+          summary: summarizeDiagnostics([...diagnostics, ...failures.map(() => ({ severity: 'error' as const }))]),
           errors: allErrors,
           stats: {
             totalErrors: allErrors.length,
@@ -342,6 +445,837 @@ export class ToolRegistry {
     };
   }
 
+  private async handleListErrors(args: Record<string, unknown>, context: ToolCallContext = {}): Promise<MCPToolResult> {
+    const targetPath = args['path'] as string;
+    const offset = args['offset'] as number | undefined;
+    const dedupe = args['dedupe'] !== false;
+    const dedupKey = (args['dedupKey'] as DedupKeyField[] | undefined) || DEFAULT_DEDUP_KEY;
+    const format = args['format'] === 'text' || args['format'] === 'sarif' ? args['format'] : 'json';
+    const since = args['since'] as string | undefined;
+    const changedOnly = args['changedOnly'] === true || since !== undefined;
+    const include = args['include'] as string[] | undefined;
+    const exclude = args['exclude'] as string[] | undefined;
+    const overlay = args['overlay'] as Overlay | undefined;
+    const saveBaseline = args['saveBaseline'] === true;
+    const includeRaw = args['includeRaw'] === true;
+    const offline = typeof args['offline'] === 'boolean' ? args['offline'] : undefined;
+    const packages = typeof args['packages'] === 'string' ? [args['packages']] : args['packages'] as string[] | undefined;
+
+    if (!targetPath) {
+      return {
+        content: [{
+          type: 'text',
+          text: 'Error listing errors: path is required',
+        }],
+        isError: true,
+      };
+    }
+    if (packages && overlay) {
+      return {
+        content: [{
+          type: 'text',
+          text: 'Error listing errors: packages cannot be combined with overlay',
+        }],
+        isError: true,
+      };
+    }
+
+    try {
+      if (!this.languageHandlerManager) {
+        throw new Error('Language handler manager not initialized');
+      }
+
+      // Arguments win over the workspace config file, which wins over built-in defaults
+      const { config: fileConfig } = await this.languageHandlerManager.getWorkspaceConfig(targetPath);
+      const severity = (args['severity'] as SeverityFilter) || fileConfig.severity || 'all';
+      const maxResults = args['maxResults'] as number || fileConfig.maxResults || DEFAULT_MAX_DIAGNOSTICS;
+      const limit = (args['limit'] as number | undefined) ?? maxResults;
+
+      const manager = this.languageHandlerManager;
+      // Package patterns are resolved in path, as the go command would in that directory
+      const analyze = () => packages
+        ? manager.analyzePackages(targetPath, packages, {
+          enableLinting: true,
+          ...(offline !== undefined && { offline }),
+          ...(context.signal && { signal: context.signal }),
+        })
+        : manager.analyzePath(targetPath, {
+          enableLinting: true,
+          includeWarnings: severity !== 'error',
+          ...(includeRaw && { includeRaw }),
+          ...(offline !== undefined && { offline }),
+          ...(context.signal && { signal: context.signal }),
+        }, overlay);
+      // With includeRaw every tool run is recorded, bypassing the cache
+      const recorder = includeRaw ? new RawOutputRecorder() : undefined;
+      const errors = recorder ? await recordRawOutput(recorder, analyze) : await analyze();
+
+      // Outside a git repository everything is reported, with a note saying so
+      let changes: ChangeSet | undefined;
+      let note: string | undefined;
+      if (changedOnly) {
+        changes = await getChangedLines(targetPath, since || DEFAULT_DIFF_REF, context.signal);
+        if (!changes) {
+          note = `${targetPath} is not inside a git repository; showing all diagnostics`;
+        }
+      }
+
+      // The whole project is still analyzed so type information stays correct; globs only trim the report
+      const pathFilter = include?.length || exclude?.length
+        ? compilePathFilter({ ...(include && { include }), ...(exclude && { exclude }) })
+        : undefined;
+      const filterBase = pathFilter ? await this.resolveFilterBase(targetPath) : '';
+
+      const records = errors
+        .filter(error => matchesSeverityFilter(error.severity, severity))
+        .filter(error => !changes || isChangedLine(changes, error.location.file, error.location.line))
+        .map(error => this.locateInWorkspace(toDiagnosticRecord(error)))
+        .filter(record => !pathFilter || pathFilter(record.relativePath ?? relative(filterBase, record.file)));
+      const matching = dedupe ? dedupeDiagnostics(records, dedupKey) : records;
+      const page = paginateDiagnostics(matching, { limit, ...(offset !== undefined && { offset }) });
+      const summary = summarizeDiagnostics(matching);
+      // The baseline holds every match, not just this page
+      const baseline = saveBaseline ? this.baselines.save(targetPath, severity, matching) : undefined;
+
+      if (format === 'sarif') {
+        // Files outside the workspace roots are made relative to the analyzed directory
+        const sarif = formatDiagnosticsSarif(page.diagnostics, {
+          baseDir: await this.resolveFilterBase(targetPath),
+          total: page.total,
+          ...(note && { notes: [note] }),
+        });
+        return {
+          content: [{
+            type: 'text',
+            text: JSON.stringify(sarif, null, 2),
+          }],
+        };
+      }
+
+      if (format === 'text') {
+        const report = formatDiagnosticsText(page.diagnostics, {
+          summary,
+          total: page.total,
+          offset: page.offset,
+          fileCount: new Set(matching.map(record => record.file)).size,
+        });
+        return {
+          content: [{
+            type: 'text',
+            text: note ? `${note}\n\n${report}` : report,
+          }],
+        };
+      }
+
+      return {
+        content: [{
+          type: 'text',
+          text: JSON.stringify({
+            path: targetPath,
+            severity,
+            total: page.total,
+            truncated: page.truncated,
+            omittedCount: page.omittedCount,
+            offset: page.offset,
+            limit: page.limit,
+            nextOffset: page.nextOffset,
+            summary,
+            ...(changes && {
+              changes: {
+                ref: changes.ref,
+                root: changes.root,
+                files: changes.files.size,
+              },
+            }),
+            ...(pathFilter && { filter: { include: include || [], exclude: exclude || [] } }),
+            ...(overlay && { overlay: Object.keys(overlay) }),
+            ...(packages && { packages }),
+            ...(note && { note }),
+            ...(baseline && { baselineId: baseline.id }),
+            diagnostics: recorder
+              ? page.diagnostics.map(record => ({ ...record, raw: findRawLines(record, recorder.getInvocations()) }))
+              : page.diagnostics,
+            ...(recorder && { rawOutputs: recorder.byTool() }),
+          }, null, 2),
+        }],
+      };
+    } catch (error) {
+      return {
+        content: [{
+          type: 'text',
+          text: `Error listing errors: ${error instanceof Error ? error.message : 'Unknown error'}`,
+        }],
+        isError: true,
+        ...errorCodeOf(error),
+      };
+    }
+  }
+
+  private async handleAnalyzeBatch(args: Record<string, unknown>, context: ToolCallContext = {}): Promise<MCPToolResult> {
+    const paths = args['paths'] as string[] | undefined;
+    const severity = (args['severity'] as SeverityFilter) || 'all';
+    const dedupe = args['dedupe'] !== false;
+    const offline = typeof args['offline'] === 'boolean' ? args['offline'] : undefined;
+
+    if (!Array.isArray(paths) || paths.length === 0) {
+      return {
+        content: [{
+          type: 'text',
+          text: 'Error analyzing batch: paths must be a non-empty array',
+        }],
+        isError: true,
+      };
+    }
+
+    try {
+      if (!this.languageHandlerManager) {
+        throw new Error('Language handler manager not initialized');
+      }
+
+      const startedAt = Date.now();
+      const batch = await this.languageHandlerManager.analyzeBatch(paths, {
+        enableLinting: true,
+        includeWarnings: severity !== 'error',
+        ...(offline !== undefined && { offline }),
+        ...(context.signal && { signal: context.signal }),
+      });
+
+      const results: Record<string, { total: number; summary: DiagnosticSummary; diagnostics: DiagnosticRecord[] }> = {};
+      for (const { path, errors } of batch.results) {
+        const records = errors
+          .filter(error => matchesSeverityFilter(error.severity, severity))
+          .map(error => this.locateInWorkspace(toDiagnosticRecord(error)));
+        const diagnostics = sortDiagnostics(dedupe ? dedupeDiagnostics(records) : records);
+        results[path] = { total: diagnostics.length, summary: summarizeDiagnostics(diagnostics), diagnostics };
+      }
+      const all = Object.values(results).flatMap(result => result.diagnostics);
+
+      return {
+        content: [{
+          type: 'text',
+          text: JSON.stringify({
+            severity,
+            total: all.length,
+            summary: summarizeDiagnostics(all),
+            durationMs: Date.now() - startedAt,
+            groups: batch.groups.map(group => ({
+              language: group.language,
+              directory: group.directory,
+              files: group.files.length,
+            })),
+            results,
+          }, null, 2),
+        }],
+      };
+    } catch (error) {
+      return {
+        content: [{
+          type: 'text',
+          text: `Error analyzing batch: ${error instanceof Error ? error.message : 'Unknown error'}`,
+        }],
+        isError: true,
+        ...errorCodeOf(error),
+      };
+    }
+  }
+
+  /**
+   * Directory that include/exclude globs and SARIF URIs are relative to when no
+   * workspace root contains a file: the analyzed directory, or the analyzed file's directory
+   */
+  private async resolveFilterBase(targetPath: string): Promise<string> {
+    const fullPath = await fs.realpath(resolve(targetPath)).catch(() => resolve(targetPath));
+    const stats = await fs.stat(fullPath).catch(() => undefined);
+    return stats?.isDirectory() ? fullPath : dirname(fullPath);
+  }
+
+  /**
+   * Add the owning root and root-relative path when workspace roots are configured
+   */
+  private locateInWorkspace(record: DiagnosticRecord): DiagnosticRecord {
+    const location = this.languageHandlerManager?.getWorkspaceRoots().locate(record.file);
+    return location ? { ...record, root: location.root, relativePath: location.relativePath } : record;
+  }
+
+  private async handleAnalyzeSnippet(args: Record<string, unknown>, context: ToolCallContext = {}): Promise<MCPToolResult> {
+    const language = args['language'] as string;
+    const code = args['code'] as string;
+    const offline = typeof args['offline'] === 'boolean' ? args['offline'] : undefined;
+
+    if (!language || typeof code !== 'string') {
+      return {
+        content: [{
+          type: 'text',
+          text: 'Error analyzing snippet: language and code are required',
+        }],
+        isError: true,
+      };
+    }
+
+    try {
+      if (!this.languageHandlerManager) {
+        throw new Error('Language handler manager not initialized');
+      }
+
+      const handler = this.languageHandlerManager.getHandler(language);
+      if (!handler) {
+        throw new UnsupportedLanguageError(language, `No handler available for language: ${language}`);
+      }
+
+      const filename = (args['filename'] as string | undefined) || `snippet${handler.getFileExtensions()[0] || ''}`;
+      const snippet = prepareSnippet(language, code);
+
+      // Handlers copy the source to a private temp location, so nothing is written next to `filename`
+      const errors = await this.languageHandlerManager.detectErrors(snippet.source, language, {
+        filePath: filename,
+        enableLinting: true,
+        includeWarnings: true,
+        ...(offline !== undefined && { offline }),
+        ...(context.signal && { signal: context.signal }),
+      });
+
+      const diagnostics = sortDiagnostics(dedupeDiagnostics(errors.map(toDiagnosticRecord)).map(record => {
+        const start = toSnippetLine(record.line, snippet);
+        const end = toSnippetLine(record.endLine, snippet);
+
+        return {
+          ...record,
+          file: filename,
+          line: start.line,
+          endLine: Math.max(start.line, end.line),
+          lineShifted: start.shifted,
+          inWrapper: start.inWrapper,
+        };
+      }));
+
+      return {
+        content: [{
+          type: 'text',
+          text: JSON.stringify({
+            language,
+            filename,
+            wrapper: snippet.wrapper,
+            lineOffset: snippet.lineOffset,
+            total: diagnostics.length,
+            summary: summarizeDiagnostics(diagnostics),
+            diagnostics,
+          }, null, 2),
+        }],
+      };
+    } catch (error) {
+      return {
+        content: [{
+          type: 'text',
+          text: `Error analyzing snippet: ${error instanceof Error ? error.message : 'Unknown error'}`,
+        }],
+        isError: true,
+        ...errorCodeOf(error),
+      };
+    }
+  }
+
+  private async handleAnalyzeChange(args: Record<string, unknown>, context: ToolCallContext = {}): Promise<MCPToolResult> {
+    const path = args['path'] as string | undefined;
+    const content = args['content'];
+    const severity = (args['severity'] as SeverityFilter) || 'all';
+    const offline = typeof args['offline'] === 'boolean' ? args['offline'] : undefined;
+
+    if (!path || (content !== undefined && typeof content !== 'string')) {
+      return {
+        content: [{
+          type: 'text',
+          text: 'Error analyzing change: path is required and content must be a string',
+        }],
+        isError: true,
+      };
+    }
+
+    try {
+      if (!this.languageHandlerManager) {
+        throw new Error('Language handler manager not initialized');
+      }
+
+      const startedAt = Date.now();
+      const change = await this.languageHandlerManager.analyzeChange(path, {
+        ...(typeof content === 'string' && { content }),
+        allFiles: args['allFiles'] === true,
+      }, {
+        enableLinting: true,
+        includeWarnings: severity !== 'error',
+        ...(offline !== undefined && { offline }),
+        ...(context.signal && { signal: context.signal }),
+      });
+
+      const diagnostics = sortDiagnostics(dedupeDiagnostics(change.errors
+        .filter(error => matchesSeverityFilter(error.severity, severity))
+        .map(error => this.locateInWorkspace(toDiagnosticRecord(error)))));
+
+      return {
+        content: [{
+          type: 'text',
+          text: JSON.stringify({
+            path: resolve(path),
+            packageDir: change.packageDir,
+            siblings: change.siblings.length,
+            siblingsAffected: change.siblingsAffected,
+            invalidated: change.invalidated,
+            severity,
+            total: diagnostics.length,
+            summary: summarizeDiagnostics(diagnostics),
+            durationMs: Date.now() - startedAt,
+            diagnostics,
+          }, null, 2),
+        }],
+      };
+    } catch (error) {
+      return {
+        content: [{
+          type: 'text',
+          text: `Error analyzing change: ${error instanceof Error ? error.message : 'Unknown error'}`,
+        }],
+        isError: true,
+        ...errorCodeOf(error),
+      };
+    }
+  }
+
+  private async handlePlan(args: Record<string, unknown>, context: ToolCallContext = {}): Promise<MCPToolResult> {
+    const path = args['path'] as string | undefined;
+    const offline = typeof args['offline'] === 'boolean' ? args['offline'] : undefined;
+
+    if (!path) {
+      return {
+        content: [{
+          type: 'text',
+          text: 'Error planning analysis: path is required',
+        }],
+        isError: true,
+      };
+    }
+
+    try {
+      if (!this.languageHandlerManager) {
+        throw new Error('Language handler manager not initialized');
+      }
+
+      const plans = await this.languageHandlerManager.planAnalysis(path, {
+        enableLinting: args['enableLinting'] !== false,
+        ...(offline !== undefined && { offline }),
+        ...(context.signal && { signal: context.signal }),
+      }, { fullEnv: args['fullEnv'] === true });
+
+      return {
+        content: [{
+          type: 'text',
+          text: JSON.stringify({
+            path: resolve(path),
+            files: plans.map(plan => ({
+              file: plan.file,
+              detectors: plan.detectors,
+              commands: plan.commands,
+              ...(plan.diagnostics.length > 0 && {
+                diagnostics: sortDiagnostics(plan.diagnostics.map(error => this.locateInWorkspace(toDiagnosticRecord(error)))),
+              }),
+            })),
+          }, null, 2),
+        }],
+      };
+    } catch (error) {
+      return {
+        content: [{
+          type: 'text',
+          text: `Error planning analysis: ${error instanceof Error ? error.message : 'Unknown error'}`,
+        }],
+        isError: true,
+        ...errorCodeOf(error),
+      };
+    }
+  }
+
+  private async handleWatchErrors(args: Record<string, unknown>): Promise<MCPToolResult> {
+    const targetPath = args['path'] as string;
+    const debounceMs = args['debounceMs'] as number | undefined;
+
+    if (!targetPath) {
+      return {
+        content: [{
+          type: 'text',
+          text: 'Error starting watch: path is required',
+        }],
+        isError: true,
+      };
+    }
+
+    try {
+      if (!this.watchManager) {
+        throw new Error('Watch manager not initialized');
+      }
+
+      const { session, diagnostics } = await this.watchManager.startWatch(
+        targetPath,
+        debounceMs !== undefined ? { debounceMs } : {}
+      );
+
+      return {
+        content: [{
+          type: 'text',
+          text: JSON.stringify({
+            watchId: session.id,
+            path: session.path,
+            debounceMs: session.debounceMs,
+            diagnostics,
+          }, null, 2),
+        }],
+      };
+    } catch (error) {
+      return {
+        content: [{
+          type: 'text',
+          text: `Error starting watch: ${error instanceof Error ? error.message : 'Unknown error'}`,
+        }],
+        isError: true,
+        ...errorCodeOf(error),
+      };
+    }
+  }
+
+  private async handleStopWatch(args: Record<string, unknown>): Promise<MCPToolResult> {
+    const watchId = args['watchId'] as string;
+
+    if (!this.watchManager) {
+      return {
+        content: [{
+          type: 'text',
+          text: 'Error stopping watch: Watch manager not initialized',
+        }],
+        isError: true,
+      };
+    }
+
+    const stopped = await this.watchManager.stopWatch(watchId);
+
+    return {
+      content: [{
+        type: 'text',
+        text: JSON.stringify({ watchId, stopped }, null, 2),
+      }],
+      ...(stopped ? {} : { isError: true }),
+    };
+  }
+
+  private async handleCapabilities(args: Record<string, unknown>): Promise<MCPToolResult> {
+    const targetPath = args['path'] as string | undefined;
+    const refresh = args['refresh'] === true;
+
+    try {
+      if (!this.languageHandlerManager) {
+        throw new Error('Language handler manager not initialized');
+      }
+
+      if (refresh) {
+        this.languageHandlerManager.invalidateToolchainCache();
+      }
+
+      const detectors = await this.languageHandlerManager.getCapabilities(targetPath);
+      const workspaceConfig = targetPath ? await this.languageHandlerManager.getWorkspaceConfig(targetPath) : undefined;
+
+      return {
+        content: [{
+          type: 'text',
+          text: JSON.stringify({
+            detectors,
+            available: detectors.filter(detector => detector.available).map(detector => detector.language),
+            workspaceRoots: this.languageHandlerManager.getWorkspaceRoots().list(),
+            toolchainCache: this.languageHandlerManager.getToolchainCacheStats(),
+            ...(workspaceConfig && { workspaceConfig: { ...workspaceConfig, config: redactWorkspaceConfig(workspaceConfig.config) } }),
+            ...(workspaceConfig?.config.commands && { commands: this.languageHandlerManager.getWorkspaceCommands(workspaceConfig) }),
+          }, null, 2),
+        }],
+      };
+    } catch (error) {
+      return {
+        content: [{
+          type: 'text',
+          text: `Error checking capabilities: ${error instanceof Error ? error.message : 'Unknown error'}`,
+        }],
+        isError: true,
+        ...errorCodeOf(error),
+      };
+    }
+  }
+
+  private async handleStats(args: Record<string, unknown>): Promise<MCPToolResult> {
+    const reset = args['reset'] === true;
+
+    try {
+      if (!this.languageHandlerManager) {
+        throw new Error('Language handler manager not initialized');
+      }
+
+      const cache = this.languageHandlerManager.getCacheStats();
+      const lookups = cache.hits + cache.misses;
+      const { detectors, ...counters } = analysisStats.snapshot();
+      const stats = {
+        ...counters,
+        cache: { ...cache, hitRatio: lookups > 0 ? Math.round((cache.hits / lookups) * 1000) / 1000 : 0 },
+        watchers: this.watchManager?.listWatches().length ?? 0,
+        detectors,
+        ...(this.cacheWarmer && { warmup: this.cacheWarmer.getStatus() }),
+      };
+
+      // The numbers collected so far are returned, then counting starts over
+      if (reset) {
+        analysisStats.reset();
+        this.languageHandlerManager.resetCacheStats();
+      }
+
+      return {
+        content: [{
+          type: 'text',
+          text: JSON.stringify({ ...stats, ...(reset && { reset }) }, null, 2),
+        }],
+      };
+    } catch (error) {
+      return {
+        content: [{
+          type: 'text',
+          text: `Error collecting stats: ${error instanceof Error ? error.message : 'Unknown error'}`,
+        }],
+        isError: true,
+        ...errorCodeOf(error),
+      };
+    }
+  }
+
+  private async handleRunAndDetect(args: Record<string, unknown>, context: ToolCallContext = {}): Promise<MCPToolResult> {
+    const targetPath = args['path'] as string;
+    const mode = args['mode'] === 'test' ? 'test' : 'run';
+    const programArgs = args['args'] as string[] | undefined;
+    const timeoutMs = args['timeoutMs'] as number | undefined;
+
+    if (!targetPath) {
+      return {
+        content: [{
+          type: 'text',
+          text: 'Error running program: path is required',
+        }],
+        isError: true,
+      };
+    }
+
+    try {
+      if (!this.languageHandlerManager) {
+        throw new Error('Language handler manager not initialized');
+      }
+
+      const result = await this.languageHandlerManager.runAndDetect(targetPath, {
+        mode,
+        ...(programArgs && { args: programArgs }),
+        ...(timeoutMs !== undefined && { timeoutMs }),
+        ...(context.signal && { signal: context.signal }),
+      });
+      const { errors, ...run } = result;
+
+      return {
+        content: [{
+          type: 'text',
+          text: JSON.stringify({
+            path: targetPath,
+            mode,
+            ...run,
+            diagnostics: sortDiagnostics(errors.map(error => this.locateInWorkspace(toDiagnosticRecord(error)))),
+          }, null, 2),
+        }],
+      };
+    } catch (error) {
+      return {
+        content: [{
+          type: 'text',
+          text: `Error running program: ${error instanceof Error ? error.message : 'Unknown error'}`,
+        }],
+        isError: true,
+        ...errorCodeOf(error),
+      };
+    }
+  }
+
+  /**
+   * Diff a baseline, given inline or by the id list-errors stored it under, against
+   * given diagnostics or a fresh analysis. Without `current` or `path`, a stored
+   * baseline's own path is analyzed again with its severity filter.
+   */
+  private async handleCompareDiagnostics(args: Record<string, unknown>, context: ToolCallContext = {}): Promise<MCPToolResult> {
+    const baselineArg = args['baseline'];
+    const currentArg = args['current'];
+    const lineTolerance = (args['lineTolerance'] as number | undefined) ?? DEFAULT_LINE_TOLERANCE;
+
+    try {
+      let stored: DiagnosticBaseline | undefined;
+      let baseline: DiagnosticRecord[];
+      if (typeof baselineArg === 'string') {
+        stored = this.baselines.get(baselineArg);
+        if (!stored) {
+          throw new Error(`Unknown baseline: ${baselineArg}`);
+        }
+        baseline = stored.diagnostics;
+      } else if (Array.isArray(baselineArg)) {
+        baseline = this.parseDiagnosticList(baselineArg, 'baseline');
+      } else {
+        throw new Error('baseline must be a baseline id or an array of diagnostics');
+      }
+
+      const targetPath = (args['path'] as string | undefined) || (currentArg === undefined ? stored?.path : undefined);
+      let current: DiagnosticRecord[];
+      if (Array.isArray(currentArg)) {
+        current = this.parseDiagnosticList(currentArg, 'current');
+      } else if (targetPath) {
+        current = await this.collectDiagnostics(targetPath, stored?.severity || 'all', context);
+      } else {
+        throw new Error('current or path is required when the baseline is given inline');
+      }
+
+      const comparison = compareDiagnostics(baseline, current, { lineTolerance });
+      return {
+        content: [{
+          type: 'text',
+          text: JSON.stringify({
+            baseline: {
+              ...(stored && { id: stored.id, path: stored.path, createdAt: stored.createdAt }),
+              total: baseline.length,
+            },
+            current: {
+              ...(targetPath && !Array.isArray(currentArg) && { path: targetPath }),
+              total: current.length,
+            },
+            lineTolerance,
+            summary: {
+              fixed: comparison.fixed.length,
+              new: comparison.new.length,
+              persisting: comparison.persisting.length,
+              moved: comparison.persisting.filter(record => record.baselineLine !== undefined).length,
+            },
+            fixed: sortDiagnostics(comparison.fixed),
+            new: sortDiagnostics(comparison.new),
+            persisting: sortDiagnostics(comparison.persisting),
+          }, null, 2),
+        }],
+      };
+    } catch (error) {
+      return {
+        content: [{
+          type: 'text',
+          text: `Error comparing diagnostics: ${error instanceof Error ? error.message : 'Unknown error'}`,
+        }],
+        isError: true,
+        ...errorCodeOf(error),
+      };
+    }
+  }
+
+  private parseDiagnosticList(values: unknown[], name: string): DiagnosticRecord[] {
+    return values.map((value, index) => {
+      try {
+        return parseDiagnosticRecord(value);
+      } catch (error) {
+        throw new Error(`${name}[${index}]: ${error instanceof Error ? error.message : String(error)}`);
+      }
+    });
+  }
+
+  /**
+   * Analyze a path the way list-errors does by default: deduplicated, unpaginated
+   */
+  private async collectDiagnostics(targetPath: string, severity: SeverityFilter, context: ToolCallContext): Promise<DiagnosticRecord[]> {
+    if (!this.languageHandlerManager) {
+      throw new Error('Language handler manager not initialized');
+    }
+
+    const errors = await this.languageHandlerManager.analyzePath(targetPath, {
+      enableLinting: true,
+      includeWarnings: severity !== 'error',
+      ...(context.signal && { signal: context.signal }),
+    });
+    return dedupeDiagnostics(errors
+      .filter(error => matchesSeverityFilter(error.severity, severity))
+      .map(error => this.locateInWorkspace(toDiagnosticRecord(error))));
+  }
+
+  private async handleExplainError(args: Record<string, unknown>): Promise<MCPToolResult> {
+    const diagnostic = args['diagnostic'] as Record<string, unknown> | undefined;
+    const message = (diagnostic?.['message'] ?? args['message']) as string | undefined;
+
+    if (typeof message !== 'string' || !message.trim()) {
+      return {
+        content: [{
+          type: 'text',
+          text: 'Error explaining error: message or diagnostic.message is required',
+        }],
+        isError: true,
+      };
+    }
+
+    const source = diagnostic?.['source'] ?? args['source'];
+    const code = diagnostic?.['code'];
+    const explanation = explainError({
+      message,
+      ...(typeof source === 'string' && { source }),
+      ...((typeof code === 'string' || typeof code === 'number') && { code }),
+    });
+
+    // An unrecognized message is an answer, not a failure
+    return {
+      content: [{
+        type: 'text',
+        text: JSON.stringify(explanation
+          ? { message, recognized: true, ...explanation }
+          : { message, recognized: false, explanation: 'No explanation available for this message' }, null, 2),
+      }],
+    };
+  }
+
+  private async handleSetDetectorEnabled(args: Record<string, unknown>): Promise<MCPToolResult> {
+    const language = args['language'] as string;
+    const enabled = args['enabled'];
+
+    if (!language || typeof enabled !== 'boolean') {
+      return {
+        content: [{
+          type: 'text',
+          text: 'Error setting detector state: language and enabled are required',
+        }],
+        isError: true,
+      };
+    }
+
+    try {
+      if (!this.languageHandlerManager) {
+        throw new Error('Language handler manager not initialized');
+      }
+
+      const changed = this.languageHandlerManager.setDetectorEnabled(language, enabled);
+
+      return {
+        content: [{
+          type: 'text',
+          text: JSON.stringify({
+            language,
+            enabled: this.languageHandlerManager.isDetectorEnabled(language),
+            changed,
+          }, null, 2),
+        }],
+      };
+    } catch (error) {
+      return {
+        content: [{
+          type: 'text',
+          text: `Error setting detector state: ${error instanceof Error ? error.message : 'Unknown error'}`,
+        }],
+        isError: true,
+        ...errorCodeOf(error),
+      };
+    }
+  }
+
   private async handleAnalyzeError(args: Record<string, unknown>): Promise<MCPToolResult> {
     const errorId = args['errorId'] as string;
     const includeContext = args['includeContext'] as boolean || false;
@@ -522,4 +1456,12 @@ export class ToolRegistry {
   setLanguageHandlerManager(manager: LanguageHandlerManager): void {
     this.languageHandlerManager = manager;
   }
+
+  setWatchManager(manager: DiagnosticWatchManager): void {
+    this.watchManager = manager;
+  }
+
+  setCacheWarmer(warmer: CacheWarmer): void {
+    this.cacheWarmer = warmer;
+  }
 }
@@ -23,8 +23,12 @@ export interface ServerConfig {
     name: string;
     version: string;
     logLevel: 'debug' | 'info' | 'warn' | 'error';
+    /** Write JSON log lines to this file */
+    logFile?: string;
     maxConnections?: number;
     timeout?: number;
+    /** How long stopping waits for running analyses after canceling them (default 5000) */
+    shutdownGraceMs?: number;
     // Legacy port/host fields (deprecated - use transport.port/host instead)
     /** @deprecated Use transport.port instead */
     port?: number;
@@ -38,6 +42,15 @@ export interface ServerConfig {
   performance: PerformanceConfig;
   integrations: IntegrationsConfig;
   security: SecurityConfig;
+  /** Language of the text the server writes itself (default English) */
+  localization?: LocalizationConfig;
+}
+
+export interface LocalizationConfig {
+  /** BCP 47 locale, such as `de` or `pt-BR`; missing messages fall back to its language, then English */
+  locale?: string;
+  /** Messages by locale and key, added to or overriding the built-in ones; plural forms by category */
+  messages?: Record<string, Record<string, string | ({ other: string } & Partial<Record<Intl.LDMLPluralRule, string>>)>>;
 }
 
 export interface ErrorDetectionConfig {
@@ -67,6 +80,62 @@ export interface ErrorDetectionConfig {
   };
   bufferSize: number;
   maxErrorsPerSession: number;
+  /** Per-language analysis deadlines in milliseconds, with a `default` fallback (30s) */
+  timeouts?: Record<string, number>;
+  /** Roots this session analyzes; files outside them are rejected (default: no restriction) */
+  workspaceRoots?: string[];
+  /** Retries of `go` commands that fail on the network or module proxy */
+  toolchainRetry?: ToolchainRetryConfig;
+  /** Files of a directory analyzed in parallel (default: the number of CPUs) */
+  concurrency?: number;
+  /** Severity by diagnostic code, analyzer name or `/message regex/`; `off` drops the diagnostic */
+  severityOverrides?: Record<string, 'error' | 'warning' | 'info' | 'hint' | 'off'>;
+  /** Building and running programs to catch crashes; off unless enabled */
+  execution?: ExecutionConfig;
+  /** How long toolchain paths and versions are cached in milliseconds (default 10 minutes) */
+  toolchainCacheTtlMs?: number;
+  /** Shortest time between two analyses of the same file in milliseconds (default 0) */
+  minAnalysisIntervalMs?: number;
+  /** Files larger than this many bytes are skipped with an info diagnostic (default 2 MiB, 0 for no limit) */
+  maxFileSize?: number;
+  /** Analyze without downloading modules or toolchains and without touching go.mod and go.sum (default false) */
+  offline?: boolean;
+  /** Building and analyzing Go modules in the background after startup; off unless enabled */
+  warmup?: WarmupConfig;
+  /** Whether the `commands` of workspace config files run; off unless enabled */
+  commands?: WorkspaceCommandsConfig;
+}
+
+export interface WorkspaceCommandsConfig {
+  /** Run the commands workspace config files define (default false) */
+  enabled?: boolean;
+  /** Programs they may start, as written in the config; without it any program may run */
+  allow?: string[];
+}
+
+export interface WarmupConfig {
+  /** Warm the caches once the server has started (default false) */
+  enabled?: boolean;
+  /** Directories searched for Go modules (default: the workspace roots, or the working directory without any) */
+  paths?: string[];
+}
+
+export interface ExecutionConfig {
+  /** Allow the run-and-detect tool to execute code (default false) */
+  enabled?: boolean;
+  /** Longest a run may take in milliseconds; requests can only lower it (default 30000) */
+  timeoutMs?: number;
+  /** Output captured before the program is killed (default 1 MiB) */
+  maxOutputBytes?: number;
+}
+
+export interface ToolchainRetryConfig {
+  /** Retries after the first attempt (default 2, 0 disables) */
+  retries?: number;
+  /** Wait before the first retry in milliseconds, doubled for each further one (default 1000) */
+  backoffMs?: number;
+  /** Upper bound on a single wait (default 10000) */
+  maxBackoffMs?: number;
 }
 
 export interface ErrorAnalysisConfig {
@@ -81,12 +81,14 @@ export type {
   GoModule,
   GoDependency,
   RustCrate,
-  RustDependency
+  RustDependency,
+  ToolchainInfo
 } from './languages.js';
 
 export type {
   MCPTool,
   MCPResource,
+  MCPResourceTemplate,
   MCPPrompt,
   MCPPromptArgument,
   MCPToolCall,
@@ -97,6 +99,7 @@ export type {
   MCPInitializeParams,
   MCPInitializeResult,
   DetectErrorsParams,
+  ListErrorsParams,
   AnalyzeErrorParams,
   SuggestFixesParams,
   SetBreakpointParams,
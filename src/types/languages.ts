@@ -9,24 +9,105 @@ export enum SupportedLanguage {
   GO = 'go',
   RUST = 'rust',
   PHP = 'php',
+  CPP = 'cpp',
+  JAVA = 'java',
+  SHELL = 'shell',
 }
 
+/**
+ * Built-in languages plus any id a custom handler registers under
+ */
+export type LanguageId = SupportedLanguage | (string & {});
+
 export interface LanguageHandler {
-  language: SupportedLanguage;
+  language: LanguageId;
   initialize(): Promise<void>;
   dispose(): Promise<void>;
   isAvailable(): Promise<boolean>;
   isFileSupported(filePath: string): boolean;
   getFileExtensions(): string[];
+  /** Exact file names the handler claims regardless of extension, e.g. `go.mod` */
+  getFileNames?(): string[];
+  /** How the handler's tools count columns; code points without the BOM when omitted */
+  getColumnEncoding?(): ColumnEncoding;
+  /**
+   * What other files of the package see of `source`. An edit that keeps it
+   * unchanged cannot change their diagnostics; without it, any edit can.
+   */
+  getPackageSurface?(source: string): string;
   getConfigFiles(): string[];
   detectErrors(source: string, options?: DetectionOptions): Promise<LanguageError[]>;
   parseStackTrace(stackTrace: string): StackFrame[];
   getDebugCapabilities(): LanguageDebugCapabilities;
   createDebugSession(config: LanguageDebugConfig): Promise<LanguageDebugSession>;
   analyzePerformance(source: string): Promise<PerformanceAnalysis>;
+  /** Probe the handler's toolchain; handlers without one are assumed available */
+  getToolchainInfo?(): Promise<ToolchainInfo>;
+  /** Build and execute a program or its tests, reporting build errors and crashes */
+  runAndDetect?(target: string, options: RunOptions): Promise<RunResult>;
+  /** Check the packages matched by patterns such as `./...`, resolved in `options.dir` */
+  detectPackageErrors?(patterns: string[], options: PackageDetectionOptions): Promise<LanguageError[]>;
+  /** Build every package below `dir` so later analyses find the tool's build cache warm; resolves whether all of them built */
+  warmBuildCache?(dir: string, options?: { signal?: AbortSignal }): Promise<boolean>;
   on(event: string, listener: (...args: any[]) => void): this;
 }
 
+/**
+ * What a tool's columns count: UTF-8 bytes, UTF-16 code units or Unicode code points
+ */
+export type ColumnUnit = 'byte' | 'utf16' | 'codepoint';
+
+export interface ColumnEncoding {
+  unit: ColumnUnit;
+  /** Whether columns on the first line count a leading UTF-8 BOM */
+  includesBom: boolean;
+}
+
+export interface ToolchainInfo {
+  available: boolean;
+  /** Binary that was probed, such as `go` or `javac` */
+  tool: string;
+  /** First line the tool printed for its version flag */
+  version?: string;
+  /** Why the toolchain is unavailable */
+  reason?: string;
+}
+
+export interface RunOptions {
+  /** Run the program itself or its test binary */
+  mode?: 'run' | 'test';
+  /** Arguments passed to the program */
+  args?: string[];
+  /** The run is killed after this long */
+  timeoutMs: number;
+  /** The run is killed once stdout and stderr together exceed this */
+  maxOutputBytes: number;
+  workspaceRoot?: string;
+  signal?: AbortSignal;
+}
+
+export interface PackageDetectionOptions {
+  /** Directory the patterns are resolved in and the tools run from */
+  dir: string;
+  enableLinting?: boolean;
+  workspaceRoot?: string;
+  signal?: AbortSignal;
+}
+
+export interface RunResult {
+  /** Whether the build succeeded and the program was started */
+  ran: boolean;
+  /** -1 when the run was killed for its timeout or output size */
+  exitCode: number;
+  timedOut: boolean;
+  truncated: boolean;
+  durationMs: number;
+  stdout: string;
+  stderr: string;
+  /** Build errors, or the crash the program died with */
+  errors: LanguageError[];
+}
+
 export interface DetectionOptions {
   includeWarnings?: boolean;
   includeLinting?: boolean;
@@ -35,6 +116,15 @@ export interface DetectionOptions {
   filePath?: string;
   configPath?: string;
   workspaceRoot?: string;
+  /** Aborting kills any running tool processes and rejects with a cancellation error */
+  signal?: AbortSignal;
+  /**
+   * The caller records raw tool output with `recordRawOutput`, so the tools must
+   * actually run: results are neither taken from the cache nor shared
+   */
+  includeRaw?: boolean;
+  /** Overrides the workspace config's and the server's `offline` setting */
+  offline?: boolean;
 }
 
 export interface LanguageError {
@@ -46,10 +136,23 @@ export interface LanguageError {
     column: number;
     endLine?: number;
     endColumn?: number;
+    /** Byte offsets into the file, when the tool reports them */
+    byteStart?: number;
+    byteEnd?: number;
   };
   code?: string | number;
   source: string;
+  analyzer?: string;
   relatedInformation?: RelatedInformation[];
+  /** Actionable hint for fixing the problem, when one is known */
+  suggestedFix?: string;
+  /** The tool reported no column, so the range was inferred from the source line */
+  approximateRange?: boolean;
+  /**
+   * Unit of the columns when it differs from the handler's column encoding.
+   * Cleared once the columns are converted to code points.
+   */
+  columnUnit?: ColumnUnit;
 }
 
 export interface RelatedInformation {
@@ -19,6 +19,13 @@ export interface MCPResource {
   mimeType?: string;
 }
 
+export interface MCPResourceTemplate {
+  uriTemplate: string;
+  name: string;
+  description?: string;
+  mimeType?: string;
+}
+
 export interface MCPPrompt {
   name: string;
   description: string;
@@ -39,6 +46,8 @@ export interface MCPToolCall {
 export interface MCPToolResult {
   content: MCPContent[];
   isError?: boolean;
+  /** Failure mode of an error result, such as `tool-not-found` or `no-files` */
+  errorCode?: string;
 }
 
 export interface MCPContent {
@@ -94,6 +103,19 @@ export interface DetectErrorsParams {
   realTime?: boolean;
 }
 
+export interface ListErrorsParams {
+  path: string;
+  severity?: 'error' | 'warning' | 'all';
+  maxResults?: number;
+  offset?: number;
+  limit?: number;
+  dedupe?: boolean;
+  dedupKey?: Array<'file' | 'line' | 'column' | 'message' | 'code' | 'severity'>;
+  format?: 'json' | 'text';
+  changedOnly?: boolean;
+  since?: string;
+}
+
 export interface AnalyzeErrorParams {
   errorId: string;
   includeContext?: boolean;
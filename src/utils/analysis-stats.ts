@@ -0,0 +1,142 @@
+/**
+ * Process-wide counters and timings of analyses, for the stats tool
+ */
+
+/** Upper bounds in milliseconds of the latency histogram buckets; slower runs land in one more */
+export const LATENCY_BUCKETS_MS = [1, 2, 5, 10, 20, 50, 100, 200, 500, 1000, 2000, 5000, 10000, 20000, 60000];
+
+export interface LatencySummary {
+  count: number;
+  totalMs: number;
+  avgMs: number;
+  p50Ms: number;
+  p90Ms: number;
+  p99Ms: number;
+  maxMs: number;
+}
+
+export interface DetectorStats extends LatencySummary {
+  /** Runs that threw */
+  failures: number;
+  /** Runs cut short by the detector deadline */
+  timeouts: number;
+}
+
+export interface AnalysisStatsSnapshot {
+  /** When counting started: server start or the last reset */
+  since: string;
+  /** Files, buffers and package sets analyzed, including cache hits */
+  analyses: number;
+  /** Tool processes started by detectors, gopls servers included */
+  subprocesses: number;
+  detectors: Record<string, DetectorStats>;
+}
+
+export type DetectorOutcome = 'ok' | 'failed' | 'timeout';
+
+/**
+ * Durations counted into fixed buckets, so recording is constant time and
+ * memory whatever the number of runs. Percentiles are the upper bound of the
+ * bucket they fall in, capped at the slowest run.
+ */
+export class LatencyHistogram {
+  private buckets = new Array<number>(LATENCY_BUCKETS_MS.length + 1).fill(0);
+  private count = 0;
+  private totalMs = 0;
+  private maxMs = 0;
+
+  record(durationMs: number): void {
+    const ms = Math.max(0, durationMs);
+    let index = LATENCY_BUCKETS_MS.findIndex(bound => ms <= bound);
+    if (index === -1) {
+      index = LATENCY_BUCKETS_MS.length;
+    }
+    this.buckets[index]!++;
+    this.count++;
+    this.totalMs += ms;
+    this.maxMs = Math.max(this.maxMs, ms);
+  }
+
+  percentile(fraction: number): number {
+    if (this.count === 0) {
+      return 0;
+    }
+    const rank = Math.max(1, Math.ceil(fraction * this.count));
+    let seen = 0;
+    for (let index = 0; index < this.buckets.length; index++) {
+      seen += this.buckets[index]!;
+      if (seen >= rank) {
+        return Math.min(LATENCY_BUCKETS_MS[index] ?? this.maxMs, this.maxMs);
+      }
+    }
+    return this.maxMs;
+  }
+
+  summary(): LatencySummary {
+    return {
+      count: this.count,
+      totalMs: Math.round(this.totalMs),
+      avgMs: this.count > 0 ? Math.round(this.totalMs / this.count) : 0,
+      p50Ms: Math.round(this.percentile(0.5)),
+      p90Ms: Math.round(this.percentile(0.9)),
+      p99Ms: Math.round(this.percentile(0.99)),
+      maxMs: Math.round(this.maxMs)
+    };
+  }
+}
+
+/**
+ * Counters are only touched synchronously on the event loop, so a plain
+ * increment is never interleaved with another one
+ */
+export class AnalysisStats {
+  private since = new Date();
+  private analyses = 0;
+  private subprocesses = 0;
+  private detectors = new Map<string, { latency: LatencyHistogram; failures: number; timeouts: number }>();
+
+  recordAnalysis(): void {
+    this.analyses++;
+  }
+
+  recordSubprocess(): void {
+    this.subprocesses++;
+  }
+
+  recordDetectorRun(detector: string, durationMs: number, outcome: DetectorOutcome): void {
+    let stats = this.detectors.get(detector);
+    if (!stats) {
+      stats = { latency: new LatencyHistogram(), failures: 0, timeouts: 0 };
+      this.detectors.set(detector, stats);
+    }
+    stats.latency.record(durationMs);
+    if (outcome === 'failed') {
+      stats.failures++;
+    } else if (outcome === 'timeout') {
+      stats.timeouts++;
+    }
+  }
+
+  snapshot(): AnalysisStatsSnapshot {
+    const detectors: Record<string, DetectorStats> = {};
+    for (const name of Array.from(this.detectors.keys()).sort()) {
+      const stats = this.detectors.get(name)!;
+      detectors[name] = { ...stats.latency.summary(), failures: stats.failures, timeouts: stats.timeouts };
+    }
+    return {
+      since: this.since.toISOString(),
+      analyses: this.analyses,
+      subprocesses: this.subprocesses,
+      detectors
+    };
+  }
+
+  reset(): void {
+    this.since = new Date();
+    this.analyses = 0;
+    this.subprocesses = 0;
+    this.detectors.clear();
+  }
+}
+
+export const analysisStats = new AnalysisStats();
@@ -0,0 +1,54 @@
+/**
+ * Diagnostic sets kept by the server so later runs can be compared against them
+ */
+
+import type { DiagnosticRecord, SeverityFilter } from './diagnostics.js';
+
+/** Baselines kept before the oldest is dropped */
+export const DEFAULT_BASELINE_CAPACITY = 50;
+
+export interface DiagnosticBaseline {
+  id: string;
+  /** Path that was analyzed */
+  path: string;
+  /** Severity filter the diagnostics were collected with */
+  severity: SeverityFilter;
+  createdAt: string;
+  diagnostics: DiagnosticRecord[];
+}
+
+/**
+ * In-memory baselines, dropped oldest first beyond the capacity. Ids embed the
+ * server start time so an id from an earlier server process never resolves.
+ */
+export class DiagnosticBaselineStore {
+  private baselines = new Map<string, DiagnosticBaseline>();
+  private readonly prefix = Date.now().toString(36);
+  private counter = 0;
+
+  constructor(private readonly capacity = DEFAULT_BASELINE_CAPACITY) {}
+
+  save(path: string, severity: SeverityFilter, diagnostics: DiagnosticRecord[]): DiagnosticBaseline {
+    const baseline: DiagnosticBaseline = {
+      id: `baseline-${this.prefix}-${++this.counter}`,
+      path,
+      severity,
+      createdAt: new Date().toISOString(),
+      diagnostics: diagnostics.map(record => ({ ...record })),
+    };
+    this.baselines.set(baseline.id, baseline);
+
+    while (this.baselines.size > this.capacity) {
+      this.baselines.delete(this.baselines.keys().next().value!);
+    }
+    return baseline;
+  }
+
+  get(id: string): DiagnosticBaseline | undefined {
+    return this.baselines.get(id);
+  }
+
+  get size(): number {
+    return this.baselines.size;
+  }
+}
@@ -0,0 +1,114 @@
+/**
+ * Cancellation support for analysis runs
+ */
+
+import { AsyncLocalStorage } from 'async_hooks';
+import { formatMessage } from './messages.js';
+
+/**
+ * Thrown when an analysis is canceled before it completes
+ */
+export class AnalysisCanceledError extends Error {
+  constructor(message = 'Analysis canceled') {
+    super(message);
+    this.name = 'AnalysisCanceledError';
+  }
+}
+
+/**
+ * Thrown when an analysis exceeds its deadline
+ */
+export class AnalysisTimeoutError extends AnalysisCanceledError {
+  constructor(message = 'Analysis timed out') {
+    super(message);
+    this.name = 'AnalysisTimeoutError';
+  }
+}
+
+/**
+ * Abort reason used when a single detector exceeds its configured deadline.
+ * Unlike a caller's cancellation, output gathered so far is still reported.
+ */
+export class DetectorTimeoutError extends AnalysisTimeoutError {
+  constructor(public readonly detector: string, public readonly timeoutMs: number) {
+    super(formatMessage('error.detectorTimeout', { detector, timeoutMs }));
+    this.name = 'DetectorTimeoutError';
+  }
+}
+
+const signalScope = new AsyncLocalStorage<AbortSignal>();
+
+/**
+ * Run a function with a signal that spawned tool processes observe
+ */
+export function runWithSignal<T>(signal: AbortSignal | undefined, fn: () => Promise<T>): Promise<T> {
+  return signal ? signalScope.run(signal, fn) : fn();
+}
+
+/**
+ * Get the signal of the enclosing `runWithSignal` call, if any
+ */
+export function currentSignal(): AbortSignal | undefined {
+  return signalScope.getStore();
+}
+
+/**
+ * Translate an aborted signal's reason into a typed error.
+ * `AbortSignal.timeout()` aborts with a `TimeoutError`, which maps to AnalysisTimeoutError.
+ */
+export function cancellationError(signal: AbortSignal): AnalysisCanceledError {
+  const reason: unknown = signal.reason;
+
+  if (reason instanceof AnalysisCanceledError) {
+    return reason;
+  }
+  if (reason instanceof Error && reason.name === 'TimeoutError') {
+    return new AnalysisTimeoutError();
+  }
+  return new AnalysisCanceledError();
+}
+
+export function throwIfAborted(signal: AbortSignal | undefined): void {
+  if (signal?.aborted) {
+    throw cancellationError(signal);
+  }
+}
+
+export function isCancellationError(error: unknown): error is AnalysisCanceledError {
+  return error instanceof AnalysisCanceledError;
+}
+
+/**
+ * Check whether a signal was aborted by a detector deadline
+ */
+export function isDetectorTimeout(signal: AbortSignal | undefined): boolean {
+  return signal?.aborted === true && signal.reason instanceof DetectorTimeoutError;
+}
+
+/**
+ * Combine signals into one that aborts, with the same reason, as soon as any of them does
+ */
+export function anySignal(signals: Array<AbortSignal | undefined>): AbortSignal | undefined {
+  const active = signals.filter((signal): signal is AbortSignal => signal !== undefined);
+  if (active.length <= 1) {
+    return active[0];
+  }
+
+  const controller = new AbortController();
+  const onAbort = () => {
+    for (const signal of active) {
+      signal.removeEventListener('abort', onAbort);
+    }
+    controller.abort(active.find(signal => signal.aborted)?.reason);
+  };
+
+  for (const signal of active) {
+    if (signal.aborted) {
+      controller.abort(signal.reason);
+      break;
+    }
+    signal.addEventListener('abort', onAbort, { once: true });
+  }
+
+  return controller.signal;
+}
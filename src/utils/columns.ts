@@ -0,0 +1,103 @@
+/**
+ * Conversion of the columns tools report into the unit clients get: 1-based
+ * Unicode code points of the line as it appears in the file, a tab counting as
+ * one and a leading UTF-8 BOM not counted
+ */
+
+import { resolve } from 'path';
+import type { ColumnEncoding, ColumnUnit, LanguageError } from '@/types/languages.js';
+
+export const UTF8_BOM = '\uFEFF';
+
+/** What handlers that don't declare a column encoding are assumed to report */
+export const DEFAULT_COLUMN_ENCODING: ColumnEncoding = { unit: 'codepoint', includesBom: false };
+
+export function stripBom(text: string): string {
+  return text.startsWith(UTF8_BOM) ? text.slice(UTF8_BOM.length) : text;
+}
+
+function width(char: string, unit: ColumnUnit): number {
+  if (unit === 'byte') {
+    return Buffer.byteLength(char, 'utf-8');
+  }
+  return unit === 'utf16' ? char.length : 1;
+}
+
+/**
+ * Convert a 1-based column counted in `unit` into code points of `lineText`.
+ * A column inside a multi-byte character lands on that character, and columns
+ * past the end of the line keep their distance from it.
+ */
+export function toCodePointColumn(lineText: string, column: number, unit: ColumnUnit): number {
+  if (unit === 'codepoint' || !/[^\x00-\x7f]/.test(lineText)) {
+    return column;
+  }
+
+  let offset = 1;
+  let points = 0;
+  for (const char of lineText) {
+    const charWidth = width(char, unit);
+    if (column < offset + charWidth) {
+      return points + 1;
+    }
+    offset += charWidth;
+    points++;
+  }
+  return points + 1 + Math.max(0, column - offset);
+}
+
+/**
+ * Rewrite the columns of the diagnostics for the file holding `source` into
+ * code points, from the unit the handler's tools count in. Diagnostics for
+ * other files are left as reported.
+ */
+export function normalizeColumns(
+  errors: LanguageError[],
+  source: string,
+  encoding: ColumnEncoding,
+  filePath?: string
+): LanguageError[] {
+  const target = filePath ? resolve(filePath) : undefined;
+  const hasBom = source.startsWith(UTF8_BOM);
+  const lines = source.split('\n');
+
+  const convert = (line: number, column: number, unit: ColumnUnit): number => {
+    let lineText = lines[line - 1]?.replace(/\r$/, '');
+    if (lineText === undefined) {
+      return column;
+    }
+    const onBomLine = line === 1 && hasBom;
+    if (onBomLine && !encoding.includesBom) {
+      lineText = lineText.slice(UTF8_BOM.length);
+    }
+    const converted = toCodePointColumn(lineText, column, unit);
+    // The BOM is a single code point once converted
+    return onBomLine && encoding.includesBom ? Math.max(1, converted - 1) : converted;
+  };
+  const inTarget = (file: string) => !target || !file || resolve(file) === target;
+
+  return errors.map(error => {
+    const { columnUnit, ...rest } = error;
+    if (!inTarget(error.location.file)) {
+      return columnUnit ? rest : error;
+    }
+
+    const unit = columnUnit ?? encoding.unit;
+    const { location } = error;
+    return {
+      ...rest,
+      location: {
+        ...location,
+        column: convert(location.line, location.column, unit),
+        ...(location.endColumn !== undefined && {
+          endColumn: convert(location.endLine ?? location.line, location.endColumn, unit)
+        })
+      },
+      ...(error.relatedInformation && {
+        relatedInformation: error.relatedInformation.map(info => (inTarget(info.location.file)
+          ? { ...info, location: { ...info.location, column: convert(info.location.line, info.location.column, unit) } }
+          : info))
+      })
+    };
+  });
+}
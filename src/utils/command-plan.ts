@@ -0,0 +1,106 @@
+/**
+ * Dry runs of an analysis: the commands its detectors would execute, recorded
+ * instead of spawned, for the `plan` tool
+ */
+
+import { AsyncLocalStorage } from 'async_hooks';
+import { redactEnv } from './env.js';
+
+export interface PlannedCommand {
+  /** Detector that would run the command */
+  detector: string;
+  command: string;
+  args: string[];
+  cwd: string;
+  /**
+   * Variables the command gets on top of the server's own environment, or its
+   * whole environment for a plan made with `fullEnv`, with credentials redacted
+   */
+  env: Record<string, string>;
+  /** Set for a server that stays up and is reused by later analyses, such as gopls */
+  persistent?: boolean;
+}
+
+export interface CommandPlanOptions {
+  /** Report the whole environment of each command instead of what it adds */
+  fullEnv?: boolean;
+}
+
+/**
+ * Collects the detectors selected for a file and the commands they would run,
+ * in the order they would run them
+ */
+export class CommandPlan {
+  private detectors: string[] = [];
+  private commands: PlannedCommand[] = [];
+
+  constructor(private options: CommandPlanOptions = {}) {}
+
+  selectDetectors(detectors: string[]): void {
+    this.detectors.push(...detectors.filter(detector => !this.detectors.includes(detector)));
+  }
+
+  /**
+   * Record a command that would run with `env` as its environment, or the
+   * server's when none is given
+   */
+  record(
+    detector: string,
+    command: string,
+    args: string[],
+    options: { cwd?: string; env?: NodeJS.ProcessEnv; persistent?: boolean } = {}
+  ): void {
+    this.commands.push({
+      detector,
+      command,
+      args: [...args],
+      cwd: options.cwd || process.cwd(),
+      env: redactEnv(this.options.fullEnv ? definedVariables(options.env ?? process.env) : environmentChanges(options.env)),
+      ...(options.persistent && { persistent: true })
+    });
+  }
+
+  getDetectors(): string[] {
+    return [...this.detectors];
+  }
+
+  getCommands(): PlannedCommand[] {
+    return this.commands.map(command => ({ ...command, args: [...command.args], env: { ...command.env } }));
+  }
+}
+
+function definedVariables(env: NodeJS.ProcessEnv): Record<string, string> {
+  return Object.fromEntries(
+    Object.entries(env).filter((entry): entry is [string, string] => entry[1] !== undefined)
+  );
+}
+
+/**
+ * The variables of `env` that are missing from the server's environment or
+ * differ from it. Variables `env` drops are not reported.
+ */
+export function environmentChanges(env: NodeJS.ProcessEnv | undefined): Record<string, string> {
+  if (!env || env === process.env) {
+    return {};
+  }
+  return Object.fromEntries(
+    Object.entries(definedVariables(env)).filter(([key, value]) => process.env[key] !== value)
+  );
+}
+
+const planScope = new AsyncLocalStorage<CommandPlan>();
+
+/**
+ * Run a function with the commands it would spawn recorded in `plan`, and none
+ * of them spawned
+ */
+export function planCommands<T>(plan: CommandPlan, fn: () => Promise<T>): Promise<T> {
+  return planScope.run(plan, fn);
+}
+
+/**
+ * Get the plan of the enclosing `planCommands` call, if any
+ */
+export function currentCommandPlan(): CommandPlan | undefined {
+  return planScope.getStore();
+}
@@ -0,0 +1,118 @@
+/**
+ * Human-readable rendering of diagnostics for text tool responses
+ */
+
+import { basename, isAbsolute, join, relative } from 'path';
+import {
+  compareDiagnosticOrder,
+  summarizeDiagnostics,
+  type DiagnosticRecord,
+  type DiagnosticSeverity,
+  type DiagnosticSummary
+} from './diagnostics.js';
+import { formatMessage } from './messages.js';
+
+export interface TextFormatOptions {
+  /** Directory file headers are shown relative to (default: the working directory) */
+  baseDir?: string;
+  /** Counts for the trailing line when `diagnostics` is a truncated page */
+  summary?: DiagnosticSummary;
+  /** Total number of diagnostics when `diagnostics` is a truncated page */
+  total?: number;
+  /** Position of the page's first diagnostic in the full result */
+  offset?: number;
+  /** Number of files with diagnostics when `diagnostics` is a truncated page */
+  fileCount?: number;
+}
+
+function displayPath(file: string, baseDir: string): string {
+  const relativePath = relative(baseDir, file);
+  // Keep absolute paths for files outside the base directory
+  return !relativePath || relativePath.startsWith('..') || isAbsolute(relativePath) ? file : relativePath;
+}
+
+/**
+ * Header for a file: its workspace-relative path when it has an owning root,
+ * prefixed with the root's name when the report spans several roots
+ */
+function fileLabel(diagnostic: DiagnosticRecord, baseDir: string, multiRoot: boolean): string {
+  if (diagnostic.root && diagnostic.relativePath) {
+    return multiRoot ? join(basename(diagnostic.root), diagnostic.relativePath) : diagnostic.relativePath;
+  }
+  return displayPath(diagnostic.file, baseDir);
+}
+
+/**
+ * Describe counts as "12 errors, 3 warnings across 5 files" in the configured
+ * locale; info and hints are mentioned only when present
+ */
+export function formatSummaryLine(summary: DiagnosticSummary, fileCount: number): string {
+  const parts = [
+    formatMessage('summary.errors', { count: summary.errors }),
+    formatMessage('summary.warnings', { count: summary.warnings })
+  ];
+  if (summary.info > 0) {
+    parts.push(formatMessage('summary.info', { count: summary.info }));
+  }
+  if (summary.hints > 0) {
+    parts.push(formatMessage('summary.hints', { count: summary.hints }));
+  }
+  return formatMessage('summary.line', {
+    counts: parts.join(formatMessage('summary.separator')),
+    files: formatMessage('summary.files', { count: fileCount })
+  });
+}
+
+/**
+ * Render diagnostics grouped under per-file headers. Files are sorted by path and
+ * diagnostics by line, then column.
+ */
+export function formatDiagnosticsText(diagnostics: DiagnosticRecord[], options: TextFormatOptions = {}): string {
+  const baseDir = options.baseDir || process.cwd();
+  const multiRoot = new Set(diagnostics.map(diagnostic => diagnostic.root).filter(Boolean)).size > 1;
+  const byFile = new Map<string, DiagnosticRecord[]>();
+  const labels = new Map<string, string>();
+
+  for (const diagnostic of diagnostics) {
+    const list = byFile.get(diagnostic.file) || [];
+    list.push(diagnostic);
+    byFile.set(diagnostic.file, list);
+    labels.set(diagnostic.file, fileLabel(diagnostic, baseDir, multiRoot));
+  }
+
+  const severities: DiagnosticSeverity[] = ['error', 'warning', 'info', 'hint'];
+  const severityLabels = new Map(severities.map(severity => [severity, formatMessage(`severity.${severity}`)]));
+  const labelWidth = Math.max(...Array.from(severityLabels.values()).map(label => label.length));
+
+  const files = Array.from(byFile.keys()).sort((a, b) => {
+    const left = labels.get(a)!;
+    const right = labels.get(b)!;
+    return left < right ? -1 : left > right ? 1 : 0;
+  });
+  const sections = files.map(file => {
+    const records = byFile.get(file)!.slice().sort(compareDiagnosticOrder);
+    const positions = records.map(record => `${record.line}:${record.column}`);
+    const width = Math.max(...positions.map(position => position.length));
+    const indent = ' '.repeat(width + labelWidth + 6);
+
+    const lines = records.map((record, index) => {
+      const code = record.code ? ` [${record.code}]` : '';
+      // Continuation lines of multi-line messages line up under the first
+      const message = record.message.replace(/\n/g, `\n${indent}`);
+      return `  ${positions[index]!.padEnd(width)}  ${severityLabels.get(record.severity)!.padEnd(labelWidth)}  ${message}${code}`;
+    });
+    return [labels.get(file)!, ...lines].join('\n');
+  });
+
+  const summary = options.summary || summarizeDiagnostics(diagnostics);
+  const total = options.total ?? diagnostics.length;
+  const footer = [formatSummaryLine(summary, options.fileCount ?? files.length)];
+  const offset = options.offset ?? 0;
+  if (offset > 0 && diagnostics.length > 0) {
+    footer.push(formatMessage('page.range', { first: offset + 1, last: offset + diagnostics.length, total }));
+  } else if (total > diagnostics.length) {
+    footer.push(formatMessage('page.partial', { shown: diagnostics.length, total }));
+  }
+
+  return [...sections, footer.join(' ')].join('\n\n');
+}
@@ -0,0 +1,469 @@
+/**
+ * Diagnostic post-processing utilities shared by the MCP tool layer
+ */
+
+import type { LanguageError } from '@/types/languages.js';
+import { suggestFix } from './quick-fixes.js';
+
+export type DiagnosticSeverity = LanguageError['severity'];
+
+export type SeverityFilter = 'error' | 'warning' | 'all';
+
+/**
+ * Flat diagnostic shape returned to MCP clients.
+ * Lines and columns are 1-based.
+ */
+export interface DiagnosticRecord {
+  file: string;
+  line: number;
+  column: number;
+  endLine: number;
+  endColumn: number;
+  severity: DiagnosticSeverity;
+  code: string | null;
+  message: string;
+  source: string;
+  analyzer: string | null;
+  /** Every tool that reported this diagnostic, after deduplication */
+  sources: string[];
+  analyzers: string[];
+  /** Workspace root containing the file, when roots are configured */
+  root?: string;
+  /** `file` relative to `root` */
+  relativePath?: string;
+  /** Other locations involved, such as the other declaration of a redeclared name */
+  relatedInformation?: DiagnosticRelatedRecord[];
+  /** Quick-fix hint from the handler or the quick-fix rules */
+  suggestedFix?: string;
+  /** The column range was inferred because the tool only reported a line */
+  approximateRange?: boolean;
+}
+
+/**
+ * A related location of a diagnostic, 1-based like the diagnostic itself
+ */
+export interface DiagnosticRelatedRecord {
+  file: string;
+  line: number;
+  column: number;
+  message: string;
+}
+
+/**
+ * Per-severity counts of a diagnostic list
+ */
+export interface DiagnosticSummary {
+  errors: number;
+  warnings: number;
+  info: number;
+  hints: number;
+  hasErrors: boolean;
+}
+
+export type DedupKeyField = 'file' | 'line' | 'column' | 'message' | 'code' | 'severity';
+
+export const DEFAULT_DEDUP_KEY: readonly DedupKeyField[] = Object.freeze(['file', 'line', 'column', 'message']);
+
+export const DEFAULT_MAX_DIAGNOSTICS = 1000;
+
+/**
+ * Severity ranking, higher is more severe
+ */
+export const SEVERITY_RANK: Readonly<Record<DiagnosticSeverity, number>> = Object.freeze({
+  error: 3,
+  warning: 2,
+  info: 1,
+  hint: 0,
+});
+
+/**
+ * Check whether a severity passes a minimum-severity filter.
+ * `warning` keeps errors and warnings, `all` keeps everything.
+ */
+export function matchesSeverityFilter(severity: DiagnosticSeverity, filter: SeverityFilter): boolean {
+  switch (filter) {
+    case 'error':
+      return severity === 'error';
+    case 'warning':
+      return SEVERITY_RANK[severity] >= SEVERITY_RANK.warning;
+    default:
+      return true;
+  }
+}
+
+/**
+ * Count diagnostics by severity. An empty list yields all-zero counts.
+ */
+export function summarizeDiagnostics(diagnostics: ReadonlyArray<{ severity: DiagnosticSeverity }>): DiagnosticSummary {
+  const summary: DiagnosticSummary = { errors: 0, warnings: 0, info: 0, hints: 0, hasErrors: false };
+
+  for (const { severity } of diagnostics) {
+    switch (severity) {
+      case 'error':
+        summary.errors++;
+        break;
+      case 'warning':
+        summary.warnings++;
+        break;
+      case 'info':
+        summary.info++;
+        break;
+      default:
+        summary.hints++;
+    }
+  }
+
+  summary.hasErrors = summary.errors > 0;
+  return summary;
+}
+
+export interface PaginationOptions {
+  /** Index of the first diagnostic to return (default 0) */
+  offset?: number;
+  /** Page size (default 1000) */
+  limit?: number;
+}
+
+export interface DiagnosticPage {
+  diagnostics: DiagnosticRecord[];
+  total: number;
+  offset: number;
+  limit: number;
+  /** Whether any matching diagnostic is not on this page */
+  truncated: boolean;
+  omittedCount: number;
+  /** Offset of the next page, or null on the last page */
+  nextOffset: number | null;
+}
+
+function compareText(left: string, right: string): number {
+  return left < right ? -1 : left > right ? 1 : 0;
+}
+
+/**
+ * Priority order used when a result set is cut: more severe diagnostics first, then
+ * in canonical order so pages are stable across calls
+ */
+export function comparePriority(a: DiagnosticRecord, b: DiagnosticRecord): number {
+  return SEVERITY_RANK[b.severity] - SEVERITY_RANK[a.severity]
+    || compareText(a.file, b.file)
+    || a.line - b.line
+    || a.column - b.column
+    || compareDiagnosticOrder(a, b);
+}
+
+/**
+ * Canonical order of returned diagnostics: by file, line, column, severity (most
+ * severe first) and message. Range, code, source and analyzer break the remaining
+ * ties, so the order is total and the same results always serialize the same way.
+ */
+export function compareDiagnosticOrder(a: DiagnosticRecord, b: DiagnosticRecord): number {
+  return compareText(a.file, b.file)
+    || a.line - b.line
+    || a.column - b.column
+    || SEVERITY_RANK[b.severity] - SEVERITY_RANK[a.severity]
+    || compareText(a.message, b.message)
+    || a.endLine - b.endLine
+    || a.endColumn - b.endColumn
+    || compareText(a.code ?? '', b.code ?? '')
+    || compareText(a.source, b.source)
+    || compareText(a.analyzer ?? '', b.analyzer ?? '');
+}
+
+/**
+ * Diagnostics in canonical order, the last step before any of them is serialized
+ */
+export function sortDiagnostics<T extends DiagnosticRecord>(records: readonly T[]): T[] {
+  return records.slice().sort(compareDiagnosticOrder);
+}
+
+/**
+ * Select one page of diagnostics in priority order, so a truncated first page keeps
+ * errors over warnings and info. The page is returned in canonical order.
+ */
+export function paginateDiagnostics(records: DiagnosticRecord[], options: PaginationOptions = {}): DiagnosticPage {
+  const offset = Math.max(0, Math.floor(options.offset ?? 0));
+  const limit = Math.max(0, Math.floor(options.limit ?? DEFAULT_MAX_DIAGNOSTICS));
+  const ordered = records.slice().sort(comparePriority);
+  const diagnostics = sortDiagnostics(ordered.slice(offset, offset + limit));
+  const end = offset + diagnostics.length;
+
+  return {
+    diagnostics,
+    total: records.length,
+    offset,
+    limit,
+    truncated: diagnostics.length < records.length,
+    omittedCount: records.length - diagnostics.length,
+    nextOffset: end < records.length ? end : null,
+  };
+}
+
+/**
+ * Convert a language handler error into the flat client-facing record
+ */
+export function toDiagnosticRecord(error: LanguageError): DiagnosticRecord {
+  const line = Math.max(1, error.location.line);
+  const column = Math.max(1, error.location.column);
+  const endLine = Math.max(line, error.location.endLine ?? line);
+  const endColumn = endLine === line
+    ? Math.max(column, error.location.endColumn ?? column)
+    : Math.max(1, error.location.endColumn ?? 1);
+  const suggestedFix = error.suggestedFix ?? suggestFix(error);
+  const relatedInformation = (error.relatedInformation ?? []).map(info => ({
+    file: info.location.file,
+    line: Math.max(1, info.location.line),
+    column: Math.max(1, info.location.column),
+    message: info.message,
+  }));
+
+  return {
+    file: error.location.file,
+    line,
+    column,
+    endLine,
+    endColumn,
+    severity: error.severity,
+    code: error.code !== undefined ? String(error.code) : null,
+    message: error.message,
+    source: error.source,
+    analyzer: error.analyzer ?? null,
+    sources: [error.source],
+    analyzers: error.analyzer ? [error.analyzer] : [],
+    ...(relatedInformation.length > 0 && { relatedInformation }),
+    ...(suggestedFix !== undefined && { suggestedFix }),
+    ...(error.approximateRange && { approximateRange: true }),
+  };
+}
+
+export interface DiagnosticDiff {
+  added: DiagnosticRecord[];
+  removed: DiagnosticRecord[];
+  unchanged: DiagnosticRecord[];
+}
+
+/**
+ * Identity of a diagnostic for comparisons between runs
+ */
+export function diagnosticKey(record: DiagnosticRecord): string {
+  return [
+    record.file,
+    record.line,
+    record.column,
+    record.severity,
+    record.code ?? '',
+    record.message,
+  ].join('\0');
+}
+
+/**
+ * Compare two diagnostic sets. Duplicates are matched one-for-one.
+ */
+export function diffDiagnostics(previous: DiagnosticRecord[], current: DiagnosticRecord[]): DiagnosticDiff {
+  const remaining = new Map<string, DiagnosticRecord[]>();
+  for (const record of previous) {
+    const key = diagnosticKey(record);
+    const list = remaining.get(key) || [];
+    list.push(record);
+    remaining.set(key, list);
+  }
+
+  const added: DiagnosticRecord[] = [];
+  const unchanged: DiagnosticRecord[] = [];
+
+  for (const record of current) {
+    const matches = remaining.get(diagnosticKey(record));
+    if (matches && matches.length > 0) {
+      matches.shift();
+      unchanged.push(record);
+    } else {
+      added.push(record);
+    }
+  }
+
+  const removed = Array.from(remaining.values()).flat();
+
+  return { added, removed, unchanged };
+}
+
+/** Lines a diagnostic may move between runs and still count as the same one */
+export const DEFAULT_LINE_TOLERANCE = 3;
+
+export interface ComparedDiagnostic extends DiagnosticRecord {
+  /** Line the diagnostic had in the baseline, when it moved */
+  baselineLine?: number;
+}
+
+export interface DiagnosticComparison {
+  /** In the baseline only */
+  fixed: DiagnosticRecord[];
+  /** In the current run only */
+  new: DiagnosticRecord[];
+  /** In both, as reported by the current run */
+  persisting: ComparedDiagnostic[];
+}
+
+export interface CompareOptions {
+  /** How far a diagnostic may move and still match; 0 requires the same line */
+  lineTolerance?: number;
+}
+
+function comparisonKey(record: DiagnosticRecord): string {
+  return [record.file, record.code ?? '', normalizeMessage(record.message)].join('\0');
+}
+
+/**
+ * Compare a baseline run with a current one to tell what a change fixed and what
+ * it introduced. Diagnostics match on file, code and message. Same-line matches
+ * are made first, then the nearest baseline entry within `lineTolerance` lines,
+ * so editing code above an error does not report it as fixed and new. Duplicates
+ * are matched one-for-one and severity changes still count as persisting.
+ */
+export function compareDiagnostics(
+  baseline: DiagnosticRecord[],
+  current: DiagnosticRecord[],
+  options: CompareOptions = {}
+): DiagnosticComparison {
+  const tolerance = Math.max(0, options.lineTolerance ?? DEFAULT_LINE_TOLERANCE);
+  const candidates = new Map<string, Array<{ record: DiagnosticRecord; matched: boolean }>>();
+  for (const record of baseline) {
+    const key = comparisonKey(record);
+    const list = candidates.get(key) || [];
+    list.push({ record, matched: false });
+    candidates.set(key, list);
+  }
+
+  const persisting: Array<ComparedDiagnostic | undefined> = new Array(current.length);
+  current.forEach((record, index) => {
+    const exact = candidates.get(comparisonKey(record))?.find(entry => !entry.matched && entry.record.line === record.line);
+    if (exact) {
+      exact.matched = true;
+      persisting[index] = record;
+    }
+  });
+
+  current.forEach((record, index) => {
+    if (persisting[index] || tolerance === 0) {
+      return;
+    }
+    let nearest: { record: DiagnosticRecord; matched: boolean } | undefined;
+    for (const entry of candidates.get(comparisonKey(record)) || []) {
+      const distance = Math.abs(entry.record.line - record.line);
+      if (!entry.matched && distance <= tolerance &&
+        (!nearest || distance < Math.abs(nearest.record.line - record.line))) {
+        nearest = entry;
+      }
+    }
+    if (nearest) {
+      nearest.matched = true;
+      persisting[index] = { ...record, baselineLine: nearest.record.line };
+    }
+  });
+
+  return {
+    fixed: Array.from(candidates.values()).flat().filter(entry => !entry.matched).map(entry => entry.record),
+    new: current.filter((_, index) => !persisting[index]),
+    persisting: persisting.filter((record): record is ComparedDiagnostic => record !== undefined),
+  };
+}
+
+/**
+ * Read a diagnostic passed in by a client, such as one taken from a list-errors
+ * response. Only `file`, `line` and `message` are required.
+ */
+export function parseDiagnosticRecord(value: unknown): DiagnosticRecord {
+  if (typeof value !== 'object' || value === null) {
+    throw new Error('expected an object');
+  }
+  const { file, line, column, endLine, endColumn, message, severity, code, source, root, relativePath, suggestedFix } =
+    value as Record<string, unknown>;
+  if (typeof file !== 'string' || typeof line !== 'number' || typeof message !== 'string') {
+    throw new Error('file, line and message are required');
+  }
+
+  const record = toDiagnosticRecord({
+    message,
+    severity: severity === 'error' || severity === 'warning' || severity === 'info' || severity === 'hint' ? severity : 'error',
+    location: {
+      file,
+      line,
+      column: typeof column === 'number' ? column : 1,
+      ...(typeof endLine === 'number' && { endLine }),
+      ...(typeof endColumn === 'number' && { endColumn }),
+    },
+    source: typeof source === 'string' ? source : 'unknown',
+    ...((typeof code === 'string' || typeof code === 'number') && { code }),
+  });
+  return {
+    ...record,
+    ...(typeof root === 'string' && { root }),
+    ...(typeof relativePath === 'string' && { relativePath }),
+    ...(typeof suggestedFix === 'string' && { suggestedFix }),
+  };
+}
+
+/**
+ * Normalize a message for comparison: case, quoting, whitespace and trailing punctuation
+ * differ between tools reporting the same problem.
+ */
+export function normalizeMessage(message: string): string {
+  return message
+    .toLowerCase()
+    .replace(/[`'"‘’“”]/g, '')
+    .replace(/\s+/g, ' ')
+    .replace(/[.;:\s]+$/, '')
+    .trim();
+}
+
+function dedupKey(record: DiagnosticRecord, fields: readonly DedupKeyField[]): string {
+  return fields.map(field => {
+    switch (field) {
+      case 'message':
+        return normalizeMessage(record.message);
+      case 'code':
+        return record.code ?? '';
+      default:
+        return String(record[field]);
+    }
+  }).join('\0');
+}
+
+/**
+ * Collapse diagnostics that share a key into one, keeping the most severe entry
+ * and merging the `sources`/`analyzers` provenance of every duplicate.
+ * Order follows the first occurrence of each key.
+ */
+export function dedupeDiagnostics(
+  records: DiagnosticRecord[],
+  keyFields: readonly DedupKeyField[] = DEFAULT_DEDUP_KEY
+): DiagnosticRecord[] {
+  const merged = new Map<string, DiagnosticRecord>();
+
+  for (const record of records) {
+    const key = dedupKey(record, keyFields);
+    const existing = merged.get(key);
+
+    if (!existing) {
+      merged.set(key, { ...record, sources: [...record.sources], analyzers: [...record.analyzers] });
+      continue;
+    }
+
+    const primary = SEVERITY_RANK[record.severity] > SEVERITY_RANK[existing.severity] ? record : existing;
+    const suggestedFix = primary.suggestedFix ?? existing.suggestedFix ?? record.suggestedFix;
+    const relatedInformation = primary.relatedInformation ?? existing.relatedInformation ?? record.relatedInformation;
+    merged.set(key, {
+      ...primary,
+      ...(relatedInformation && { relatedInformation }),
+      ...(suggestedFix !== undefined && { suggestedFix }),
+      code: primary.code ?? existing.code ?? record.code,
+      sources: unionOf(existing.sources, record.sources),
+      analyzers: unionOf(existing.analyzers, record.analyzers),
+    });
+  }
+
+  return Array.from(merged.values());
+}
+
+function unionOf(left: string[], right: string[]): string[] {
+  return Array.from(new Set([...left, ...right]));
+}
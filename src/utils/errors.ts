@@ -0,0 +1,144 @@
+/**
+ * Typed errors raised while running analyses. Each failure mode has its own
+ * class, and `findError` recovers one from an error that wraps it as `cause`,
+ * so callers embedding the managers can tell them apart.
+ */
+
+import { AnalysisCanceledError, AnalysisTimeoutError } from './cancellation.js';
+
+/**
+ * Thrown when a required tool is not installed or not on PATH
+ */
+export class ToolNotFoundError extends Error {
+  constructor(public readonly tool: string, message?: string) {
+    super(message || `${tool} not found on PATH. Install it to enable this analysis.`);
+    this.name = 'ToolNotFoundError';
+  }
+}
+
+export function isToolNotFoundError(error: unknown): error is ToolNotFoundError {
+  return findError(error, ToolNotFoundError) !== undefined;
+}
+
+/**
+ * Thrown when a tool runs but fails without output that can be turned into diagnostics
+ */
+export class ToolFailedError extends Error {
+  constructor(
+    public readonly tool: string,
+    public readonly exitCode: number,
+    public readonly stderr: string,
+    message?: string,
+    options?: ErrorOptions
+  ) {
+    super(message || `${tool} exited with code ${exitCode}${stderr.trim() ? `: ${stderr.trim()}` : ''}`, options);
+    this.name = 'ToolFailedError';
+  }
+}
+
+export function isToolFailedError(error: unknown): error is ToolFailedError {
+  return findError(error, ToolFailedError) !== undefined;
+}
+
+/**
+ * Thrown when a directory holds no file that any detector can analyze
+ */
+export class NoFilesError extends Error {
+  constructor(public readonly path: string) {
+    super(`No supported files found in ${path}`);
+    this.name = 'NoFilesError';
+  }
+}
+
+export function isNoFilesError(error: unknown): error is NoFilesError {
+  return findError(error, NoFilesError) !== undefined;
+}
+
+/**
+ * Thrown when no handler is registered for a language, or the handler lacks the
+ * requested feature
+ */
+export class UnsupportedLanguageError extends Error {
+  constructor(public readonly language: string, message?: string) {
+    super(message || `Unsupported language: ${language}`);
+    this.name = 'UnsupportedLanguageError';
+  }
+}
+
+export function isUnsupportedLanguageError(error: unknown): error is UnsupportedLanguageError {
+  return findError(error, UnsupportedLanguageError) !== undefined;
+}
+
+/**
+ * Thrown when a path is analyzed that no configured workspace root contains
+ */
+export class OutsideWorkspaceError extends Error {
+  constructor(public readonly path: string, public readonly roots: string[]) {
+    super(`${path} is outside the configured workspace roots: ${roots.join(', ')}`);
+    this.name = 'OutsideWorkspaceError';
+  }
+}
+
+export function isOutsideWorkspaceError(error: unknown): error is OutsideWorkspaceError {
+  return findError(error, OutsideWorkspaceError) !== undefined;
+}
+
+/**
+ * The error itself or the first error in its `cause` chain that is an instance
+ * of `type`. Wrap with `new Error(message, { cause })` to keep the typed error
+ * recoverable.
+ */
+export function findError<T extends Error>(error: unknown, type: abstract new (...args: any[]) => T): T | undefined {
+  const seen = new Set<Error>();
+  let current = error;
+  while (current instanceof Error && !seen.has(current)) {
+    if (current instanceof type) {
+      return current;
+    }
+    seen.add(current);
+    current = current.cause;
+  }
+  return undefined;
+}
+
+/**
+ * Failure modes reported to MCP clients alongside the error text
+ */
+export type AnalysisErrorCode =
+  | 'tool-not-found'
+  | 'tool-failed'
+  | 'no-files'
+  | 'unsupported-language'
+  | 'outside-workspace'
+  | 'timeout'
+  | 'canceled';
+
+/**
+ * Code of the failure mode behind an error, searching its `cause` chain.
+ * Errors of no known mode have none.
+ */
+export function getErrorCode(error: unknown): AnalysisErrorCode | undefined {
+  if (isToolNotFoundError(error)) {
+    return 'tool-not-found';
+  }
+  if (isToolFailedError(error)) {
+    return 'tool-failed';
+  }
+  if (isNoFilesError(error)) {
+    return 'no-files';
+  }
+  if (isUnsupportedLanguageError(error)) {
+    return 'unsupported-language';
+  }
+  if (isOutsideWorkspaceError(error)) {
+    return 'outside-workspace';
+  }
+  // Timeouts are a kind of cancellation, so they are checked first
+  if (findError(error, AnalysisTimeoutError)) {
+    return 'timeout';
+  }
+  if (findError(error, AnalysisCanceledError)) {
+    return 'canceled';
+  }
+  return undefined;
+}
@@ -0,0 +1,268 @@
+/**
+ * Longer-form explanations of common compiler errors, driven by a table of rules.
+ * Go has no numbered error codes, so rules recognize the message itself.
+ */
+
+import type { LanguageError } from '@/types/languages.js';
+
+export interface ErrorExample {
+  /** Smallest program that reports the error */
+  broken: string;
+  /** The same program, fixed */
+  fixed: string;
+}
+
+/**
+ * One entry of the explanation table. A rule applies when the diagnostic's
+ * source, code and message all match; the first applicable rule wins.
+ */
+export interface ExplanationRule {
+  id: string;
+  /** Diagnostic `source` values the rule applies to; any when omitted */
+  sources?: readonly string[];
+  /** Exact diagnostic code; any when omitted */
+  code?: string;
+  /** Matched against the first line of the message */
+  pattern: RegExp;
+  title: string;
+  explanation: string;
+  example: ErrorExample;
+}
+
+export interface ErrorExplanation {
+  id: string;
+  title: string;
+  explanation: string;
+  example: ErrorExample;
+}
+
+/** The go command and gopls report the same compiler messages */
+const GO_SOURCES: readonly string[] = ['go', 'gopls'];
+
+export const DEFAULT_EXPLANATION_RULES: readonly ExplanationRule[] = Object.freeze([
+  {
+    id: 'go-unused-import',
+    sources: GO_SOURCES,
+    pattern: /^"[^"]+" imported (?:as \w+ )?and not used/,
+    title: 'Unused import',
+    explanation: 'Go refuses to compile a file that imports a package it never uses, so that dependencies stay ' +
+      'honest and builds stay fast. Remove the import, or use the package. An import that is only needed for ' +
+      'its init side effects is written with the blank identifier: import _ "net/http/pprof".',
+    example: {
+      broken: 'package main\n\nimport (\n\t"fmt"\n\t"os"\n)\n\nfunc main() {\n\tfmt.Println("hi")\n}\n',
+      fixed: 'package main\n\nimport "fmt"\n\nfunc main() {\n\tfmt.Println("hi")\n}\n',
+    },
+  },
+  {
+    id: 'go-unused-variable',
+    sources: GO_SOURCES,
+    pattern: /^(?:declared and not used: \w+|\w+ declared (?:and|but) not used)$/,
+    title: 'Unused local variable',
+    explanation: 'Every local variable must be read at least once; assigning to it does not count. This usually ' +
+      'points at a leftover from refactoring or a typo where another variable was meant. Delete the variable, ' +
+      'use it, or assign the value to _ when only the side effects of the expression matter. Unused package-level ' +
+      'variables and function parameters are allowed.',
+    example: {
+      broken: 'func total(items []int) int {\n\tcount := len(items)\n\tsum := 0\n\tfor _, v := range items {\n\t\tsum += v\n\t}\n\treturn sum\n}\n',
+      fixed: 'func total(items []int) int {\n\tsum := 0\n\tfor _, v := range items {\n\t\tsum += v\n\t}\n\treturn sum\n}\n',
+    },
+  },
+  {
+    id: 'go-does-not-implement',
+    sources: GO_SOURCES,
+    pattern: /does not (?:implement|satisfy) /,
+    title: 'Type does not implement an interface',
+    explanation: 'A type implements an interface by having every one of its methods, with the same names and ' +
+      'signatures. The message names the method that is missing or whose signature differs. Methods declared on ' +
+      'a pointer receiver (func (s *Store) Get()) belong to *Store only, so pass &store rather than store.',
+    example: {
+      broken: 'type Store struct{}\n\nfunc (s *Store) Get(key string) string { return "" }\n\nvar _ Getter = Store{}\n',
+      fixed: 'type Store struct{}\n\nfunc (s *Store) Get(key string) string { return "" }\n\nvar _ Getter = &Store{}\n',
+    },
+  },
+  {
+    id: 'go-cannot-use',
+    sources: GO_SOURCES,
+    pattern: /^cannot use .+ \((?:variable of |constant of |value of )?type .+\) as (?:type )?.+?(?: value)? in /,
+    title: 'Value of the wrong type',
+    explanation: 'Go converts between types only when asked to. The value in the message has the first type, but ' +
+      'the assignment, argument, return or field needs the second. Convert it explicitly (string(r), float64(n), ' +
+      'strconv.Itoa(n)), change the declared type, or pass a different value. When the target is an interface, ' +
+      'the message goes on to list the method the value\'s type is missing; a pointer receiver method means only ' +
+      'the pointer (&v) implements the interface.',
+    example: {
+      broken: 'func label(n int) string {\n\treturn n\n}\n',
+      fixed: 'func label(n int) string {\n\treturn strconv.Itoa(n)\n}\n',
+    },
+  },
+  {
+    id: 'go-undefined',
+    sources: GO_SOURCES,
+    pattern: /^undefined: \w+/,
+    title: 'Undefined name',
+    explanation: 'The name is not declared in any scope visible here. Check the spelling and capitalization, that ' +
+      'it is declared before use inside a function, that the package providing it is imported, and that the file ' +
+      'declaring it is part of the build (same package, not excluded by a build constraint or a _test.go suffix). ' +
+      'Names from another package need its qualifier and must start with an upper-case letter.',
+    example: {
+      broken: 'package main\n\nfunc main() {\n\tfmt.Println("hi")\n}\n',
+      fixed: 'package main\n\nimport "fmt"\n\nfunc main() {\n\tfmt.Println("hi")\n}\n',
+    },
+  },
+  {
+    id: 'go-undefined-field',
+    sources: GO_SOURCES,
+    pattern: /^\S+ undefined \(type .+ has no field or method \w+/,
+    title: 'No such field or method',
+    explanation: 'The type has no field or method by that name. Go is case-sensitive and unexported (lower-case) ' +
+      'fields and methods are invisible outside their package; the message often adds "but does have" with the ' +
+      'name that exists. For an interface value, only the interface\'s methods are available until you use a ' +
+      'type assertion.',
+    example: {
+      broken: 'var b strings.Builder\nb.Writestring("hi")\n',
+      fixed: 'var b strings.Builder\nb.WriteString("hi")\n',
+    },
+  },
+  {
+    id: 'go-missing-return',
+    sources: GO_SOURCES,
+    pattern: /^missing return$/,
+    title: 'Missing return',
+    explanation: 'A function with results must end in a terminating statement: a return, a panic, an infinite for ' +
+      'loop, or an if/else or switch whose every branch terminates. The compiler does not reason about values, so ' +
+      'a switch without a default case is not terminating even if its cases cover every input.',
+    example: {
+      broken: 'func sign(n int) int {\n\tif n < 0 {\n\t\treturn -1\n\t} else if n >= 0 {\n\t\treturn 1\n\t}\n}\n',
+      fixed: 'func sign(n int) int {\n\tif n < 0 {\n\t\treturn -1\n\t}\n\treturn 1\n}\n',
+    },
+  },
+  {
+    id: 'go-no-new-variables',
+    sources: GO_SOURCES,
+    pattern: /^no new variables on left side of :=$/,
+    title: 'No new variables on the left of :=',
+    explanation: 'The short declaration := must declare at least one new variable in the current scope. When every ' +
+      'name on the left already exists here, assign with = instead. A := inside a nested block declares a new ' +
+      'variable that shadows the outer one, which is a common source of bugs with err.',
+    example: {
+      broken: 'err := load()\nerr := save()\n',
+      fixed: 'err := load()\nerr = save()\n',
+    },
+  },
+  {
+    id: 'go-assignment-mismatch',
+    sources: GO_SOURCES,
+    pattern: /^assignment mismatch: /,
+    title: 'Assignment mismatch',
+    explanation: 'The number of variables on the left does not match the number of values on the right. A call ' +
+      'that returns several values must be assigned to that many variables; use _ for the ones you do not need.',
+    example: {
+      broken: 'n := strconv.Atoi(text)\n',
+      fixed: 'n, err := strconv.Atoi(text)\nif err != nil {\n\treturn err\n}\n',
+    },
+  },
+  {
+    id: 'go-multiple-value',
+    sources: GO_SOURCES,
+    pattern: /^multiple-value .+ in single-value context/,
+    title: 'Multiple values where one is expected',
+    explanation: 'A call that returns several values, typically a result and an error, was used as a single value, ' +
+      'for example as an argument or in an expression. Assign the results to variables first and pass the one ' +
+      'you need.',
+    example: {
+      broken: 'fmt.Println(strconv.Atoi(text) + 1)\n',
+      fixed: 'n, err := strconv.Atoi(text)\nif err != nil {\n\treturn err\n}\nfmt.Println(n + 1)\n',
+    },
+  },
+  {
+    id: 'go-argument-count',
+    sources: GO_SOURCES,
+    pattern: /^(?:not enough|too many) (?:arguments in call to|return values)/,
+    title: 'Wrong number of arguments or results',
+    explanation: 'The call passes a different number of arguments than the function declares, or the return ' +
+      'statement gives a different number of values than the function\'s result list. The message lists what was ' +
+      'given ("have") and what the signature expects ("want").',
+    example: {
+      broken: 'func div(a, b int) (int, error) {\n\treturn a / b\n}\n',
+      fixed: 'func div(a, b int) (int, error) {\n\treturn a / b, nil\n}\n',
+    },
+  },
+  {
+    id: 'go-mismatched-types',
+    sources: GO_SOURCES,
+    pattern: /^invalid operation: .+ \(mismatched types .+ and .+\)/,
+    title: 'Operands of different types',
+    explanation: 'Binary operators need both operands to have the same type; Go never converts implicitly, even ' +
+      'between int and int64 or int and float64. Convert one operand to the type of the other.',
+    example: {
+      broken: 'var total int64\nvar n int\ntotal = total + n\n',
+      fixed: 'var total int64\nvar n int\ntotal = total + int64(n)\n',
+    },
+  },
+  {
+    id: 'go-import-cycle',
+    sources: GO_SOURCES,
+    pattern: /^import cycle not allowed/,
+    title: 'Import cycle',
+    explanation: 'Packages may not import each other, directly or through other packages. Move the shared types ' +
+      'into a third package both can import, or have one side depend on an interface it declares instead of the ' +
+      'other package\'s concrete type.',
+    example: {
+      broken: '// store/store.go\nimport "example.com/app/api"\n\n// api/api.go\nimport "example.com/app/store"\n',
+      fixed: '// model/model.go holds the shared types\n// store/store.go\nimport "example.com/app/model"\n\n// api/api.go\nimport (\n\t"example.com/app/model"\n\t"example.com/app/store"\n)\n',
+    },
+  },
+  {
+    id: 'go-deadlock',
+    sources: GO_SOURCES,
+    pattern: /^(?:fatal error: )?all goroutines are asleep - deadlock!/,
+    title: 'Deadlock',
+    explanation: 'Every goroutine is blocked, so the program can never continue. Typical causes are a send on an ' +
+      'unbuffered channel with no receiver, a receive from a channel nobody closes or sends on, ranging over a ' +
+      'channel that is never closed, and a sync.WaitGroup whose Add and Done calls do not balance.',
+    example: {
+      broken: 'ch := make(chan int)\nch <- 1\nfmt.Println(<-ch)\n',
+      fixed: 'ch := make(chan int, 1)\nch <- 1\nfmt.Println(<-ch)\n',
+    },
+  },
+  {
+    id: 'go-nil-dereference',
+    sources: GO_SOURCES,
+    pattern: /invalid memory address or nil pointer dereference/,
+    title: 'Nil pointer dereference',
+    explanation: 'The program read a field or called a method through a nil pointer, map entry or interface. The ' +
+      'innermost frame of the trace shows where. Check the error returned alongside the value before using it, ' +
+      'and initialize pointers and maps before first use.',
+    example: {
+      broken: 'var cfg *Config\nfmt.Println(cfg.Name)\n',
+      fixed: 'cfg := &Config{}\nfmt.Println(cfg.Name)\n',
+    },
+  },
+]);
+
+/**
+ * Find the explanation for a diagnostic, or undefined when no rule matches.
+ * Without a `source`, as when only a message is given, rules of every source apply.
+ */
+export function explainError(
+  error: Pick<LanguageError, 'message'> & Partial<Pick<LanguageError, 'source' | 'code'>>,
+  rules: readonly ExplanationRule[] = DEFAULT_EXPLANATION_RULES
+): ErrorExplanation | undefined {
+  const message = error.message.split('\n')[0]?.trim() || '';
+  const code = error.code !== undefined ? String(error.code) : undefined;
+
+  for (const rule of rules) {
+    if (rule.sources && error.source && !rule.sources.includes(error.source)) {
+      continue;
+    }
+    if (rule.code !== undefined && rule.code !== code) {
+      continue;
+    }
+    if (rule.pattern.test(message)) {
+      const { id, title, explanation, example } = rule;
+      return { id, title, explanation, example };
+    }
+  }
+
+  return undefined;
+}
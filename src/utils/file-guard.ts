@@ -0,0 +1,116 @@
+/**
+ * Files that are never handed to a detector: ones too large to analyze without
+ * pathological memory use or tool hangs, binary files, and text in an encoding
+ * other than UTF-8
+ */
+
+import type { LanguageError } from '@/types/languages.js';
+import { formatMessage } from './messages.js';
+
+/** Files larger than this are skipped unless configured otherwise (2 MiB) */
+export const DEFAULT_MAX_FILE_SIZE = 2 * 1024 * 1024;
+
+/** `source` of the diagnostics noting a skipped file */
+export const FILE_GUARD_SOURCE = 'file-guard';
+
+/** Bytes searched for a NUL, the same heuristic as git's */
+const BINARY_SNIFF_BYTES = 8000;
+
+/**
+ * Whether a file looks binary: a NUL byte near its start
+ */
+export function isBinaryContent(content: Buffer): boolean {
+  return content.subarray(0, BINARY_SNIFF_BYTES).includes(0);
+}
+
+/** Byte order marks of the encodings detectors can't read, longest first */
+const UNSUPPORTED_BOMS: Array<[string, number[]]> = [
+  ['UTF-32LE', [0xff, 0xfe, 0x00, 0x00]],
+  ['UTF-32BE', [0x00, 0x00, 0xfe, 0xff]],
+  ['UTF-16LE', [0xff, 0xfe]],
+  ['UTF-16BE', [0xfe, 0xff]]
+];
+
+/**
+ * The encoding of a file that is obviously not UTF-8: a UTF-16 or UTF-32 byte
+ * order mark, or UTF-16 text without one, where nearly every other byte is
+ * NUL. Checked before isBinaryContent, which would take such text for binary.
+ */
+export function detectUnsupportedEncoding(content: Buffer): string | undefined {
+  for (const [encoding, bom] of UNSUPPORTED_BOMS) {
+    if (content.length >= bom.length && bom.every((byte, index) => content[index] === byte)) {
+      return encoding;
+    }
+  }
+
+  const sample = content.subarray(0, BINARY_SNIFF_BYTES - BINARY_SNIFF_BYTES % 2);
+  const units = Math.floor(sample.length / 2);
+  if (units < 2) {
+    return undefined;
+  }
+  let evenNuls = 0;
+  let oddNuls = 0;
+  for (let index = 0; index < units * 2; index += 2) {
+    evenNuls += sample[index] === 0 ? 1 : 0;
+    oddNuls += sample[index + 1] === 0 ? 1 : 0;
+  }
+  // ASCII text in UTF-16 has a NUL in one half of every code unit and none in the other
+  if (oddNuls >= units * 0.9 && evenNuls <= units * 0.1) {
+    return 'UTF-16LE';
+  }
+  if (evenNuls >= units * 0.9 && oddNuls <= units * 0.1) {
+    return 'UTF-16BE';
+  }
+  return undefined;
+}
+
+/**
+ * Effective size limit: the workspace config wins over the server config.
+ * 0 turns the limit off.
+ */
+export function resolveMaxFileSize(workspaceLimit?: number, serverLimit?: number): number {
+  return workspaceLimit ?? serverLimit ?? DEFAULT_MAX_FILE_SIZE;
+}
+
+export function isOversized(size: number, maxFileSize: number): boolean {
+  return maxFileSize > 0 && size > maxFileSize;
+}
+
+function formatSize(bytes: number): string {
+  if (bytes >= 1024 * 1024) {
+    return `${(bytes / (1024 * 1024)).toFixed(1)} MiB`;
+  }
+  return bytes >= 1024 ? `${(bytes / 1024).toFixed(1)} KiB` : `${bytes} bytes`;
+}
+
+/**
+ * Informational diagnostic for a file that was not analyzed
+ */
+export function skippedFileDiagnostic(
+  file: string,
+  reason:
+    | { kind: 'too-large'; size: number; maxFileSize: number }
+    | { kind: 'binary' }
+    | { kind: 'encoding'; encoding: string }
+): LanguageError {
+  const skipped = (message: string, code: string): LanguageError => ({
+    message,
+    severity: 'info',
+    location: { file, line: 1, column: 1 },
+    source: FILE_GUARD_SOURCE,
+    code,
+    relatedInformation: []
+  });
+
+  switch (reason.kind) {
+    case 'too-large':
+      return skipped(
+        formatMessage('skipped.tooLarge', { size: formatSize(reason.size), limit: formatSize(reason.maxFileSize) }),
+        'file-too-large'
+      );
+    case 'encoding':
+      return skipped(formatMessage('skipped.encoding', { encoding: reason.encoding }), 'unsupported-encoding');
+    default:
+      return skipped(formatMessage('skipped.binary'), 'binary-file');
+  }
+}
@@ -0,0 +1,218 @@
+/**
+ * Changed line ranges from `git diff`, used to report only newly introduced diagnostics
+ */
+
+import { execFile } from 'child_process';
+import { promises as fs } from 'fs';
+import { dirname, join, resolve } from 'path';
+import { promisify } from 'util';
+
+const execFileAsync = promisify(execFile);
+
+export const DEFAULT_DIFF_REF = 'HEAD';
+
+export interface LineRange {
+  /** First changed line, 1-based */
+  start: number;
+  /** Last changed line, inclusive */
+  end: number;
+}
+
+export interface ChangedFile {
+  /** Absolute path of the file in the working tree */
+  path: string;
+  status: 'modified' | 'added' | 'renamed' | 'untracked';
+  /** Path before a rename, relative to the repository root */
+  previousPath?: string;
+  /** Added or modified lines; every line counts when `status` is `untracked` */
+  ranges: LineRange[];
+}
+
+export interface ChangeSet {
+  /** Repository root the paths are resolved against */
+  root: string;
+  ref: string;
+  files: Map<string, ChangedFile>;
+}
+
+const HUNK_HEADER = /^@@ -\d+(?:,\d+)? \+(\d+)(?:,(\d+))? @@/;
+
+/** Byte values of the C escapes git uses in quoted names */
+const QUOTED_ESCAPES: Readonly<Record<string, number>> = Object.freeze({
+  a: 0x07, b: 0x08, t: 0x09, n: 0x0a, v: 0x0b, f: 0x0c, r: 0x0d, '"': 0x22, '\\': 0x5c,
+});
+
+/**
+ * Decode a name git quoted. core.quotePath=false still quotes names with control
+ * characters, writing them as C escapes or octal bytes of their UTF-8 encoding.
+ */
+function unquotePath(path: string): string {
+  if (path.length < 2 || !path.startsWith('"') || !path.endsWith('"')) {
+    return path;
+  }
+
+  const bytes: Buffer[] = [];
+  for (const [segment, escape] of path.slice(1, -1).matchAll(/\\([0-7]{3}|.)|[^\\]+/gs)) {
+    if (escape === undefined) {
+      bytes.push(Buffer.from(segment));
+    } else if (escape.length === 3) {
+      bytes.push(Buffer.from([parseInt(escape, 8)]));
+    } else {
+      bytes.push(Buffer.from([QUOTED_ESCAPES[escape] ?? escape.charCodeAt(0)]));
+    }
+  }
+  return Buffer.concat(bytes).toString('utf-8');
+}
+
+function addHunk(file: ChangedFile, hunk: RegExpMatchArray): void {
+  const start = parseInt(hunk[1] || '1');
+  const count = hunk[2] === undefined ? 1 : parseInt(hunk[2]);
+  // Pure deletions (`+n,0`) add no lines
+  if (count > 0) {
+    file.ranges.push({ start, end: start + count - 1 });
+  }
+}
+
+/**
+ * Parse `git diff --unified=0` output into the added/modified lines of each file.
+ * Deleted files and pure deletion hunks contribute no lines.
+ */
+export function parseUnifiedDiff(diff: string, root: string): Map<string, ChangedFile> {
+  const files = new Map<string, ChangedFile>();
+  let current: ChangedFile | undefined;
+  let status: ChangedFile['status'] = 'modified';
+  let previousPath: string | undefined;
+  // Header lines are only trusted before the first hunk; added content may look like one
+  let inHeader = false;
+
+  for (const line of diff.split('\n')) {
+    if (line.startsWith('diff --git ')) {
+      current = undefined;
+      status = 'modified';
+      previousPath = undefined;
+      inHeader = true;
+    } else if (!inHeader || line.startsWith('@@')) {
+      inHeader = false;
+      const hunk = line.match(HUNK_HEADER);
+      if (current && hunk) {
+        addHunk(current, hunk);
+      }
+    } else if (line.startsWith('new file mode')) {
+      status = 'added';
+    } else if (line.startsWith('rename from ')) {
+      status = 'renamed';
+      previousPath = unquotePath(line.slice('rename from '.length));
+    } else if (line.startsWith('rename to ') && status === 'renamed') {
+      // Pure renames have no `+++` line, so record the file here
+      const path = resolve(root, unquotePath(line.slice('rename to '.length)));
+      current = { path, status, ranges: [], ...(previousPath && { previousPath }) };
+      files.set(path, current);
+    } else if (line.startsWith('+++ ')) {
+      const name = unquotePath(line.slice(4));
+      if (name === '/dev/null') {
+        current = undefined;
+        continue;
+      }
+      // The diff is run with fixed prefixes, so exactly one `b/` comes off
+      const path = resolve(root, name.replace(/^b\//, ''));
+      current = files.get(path) || { path, status, ranges: [], ...(previousPath && { previousPath }) };
+      files.set(path, current);
+    }
+  }
+
+  return files;
+}
+
+async function git(args: string[], cwd: string, signal?: AbortSignal): Promise<string> {
+  const { stdout } = await execFileAsync('git', ['-c', 'core.quotePath=false', ...args], {
+    cwd,
+    maxBuffer: 64 * 1024 * 1024,
+    ...(signal && { signal })
+  });
+  return stdout;
+}
+
+/**
+ * Find the repository containing a file or directory, or undefined outside git
+ */
+export async function findGitRoot(path: string, signal?: AbortSignal): Promise<string | undefined> {
+  const target = resolve(path);
+  const stat = await fs.stat(target).catch(() => undefined);
+  const cwd = stat?.isDirectory() ? target : dirname(target);
+
+  try {
+    return (await git(['rev-parse', '--show-toplevel'], cwd, signal)).trim() || undefined;
+  } catch (error) {
+    if (signal?.aborted) {
+      throw error;
+    }
+    return undefined;
+  }
+}
+
+/**
+ * Check that a ref names a commit. Refs come from clients, so one starting with
+ * `-` is rejected before git could read it as an option such as `--output`.
+ */
+async function verifyCommitRef(ref: string, root: string, signal?: AbortSignal): Promise<void> {
+  if (!ref || ref.startsWith('-')) {
+    throw new Error(`Invalid git ref "${ref}": refs cannot be empty or start with -`);
+  }
+
+  try {
+    await git(['rev-parse', '--verify', '--quiet', '--end-of-options', `${ref}^{commit}`], root, signal);
+  } catch (error) {
+    if (signal?.aborted) {
+      throw error;
+    }
+    throw new Error(`Invalid git ref "${ref}": it does not name a commit in ${root}`);
+  }
+}
+
+/**
+ * Collect lines changed in the working tree relative to `ref`, including staged
+ * changes and untracked files. Returns undefined when `path` is not inside a git
+ * repository, and rejects refs that do not name a commit.
+ */
+export async function getChangedLines(
+  path: string,
+  ref: string = DEFAULT_DIFF_REF,
+  signal?: AbortSignal
+): Promise<ChangeSet | undefined> {
+  const root = await findGitRoot(path, signal);
+  if (!root) {
+    return undefined;
+  }
+
+  await verifyCommitRef(ref, root, signal);
+  // Prefixes are fixed whatever diff.noprefix or diff.mnemonicPrefix say
+  const diff = await git([
+    'diff', '--unified=0', '--no-color', '--no-ext-diff', '-M', '--src-prefix=a/', '--dst-prefix=b/',
+    '--end-of-options', ref, '--'
+  ], root, signal);
+  const files = parseUnifiedDiff(diff, root);
+
+  const untracked = await git(['ls-files', '-z', '--others', '--exclude-standard'], root, signal);
+  for (const name of untracked.split('\0')) {
+    if (name) {
+      const filePath = join(root, name);
+      files.set(filePath, { path: filePath, status: 'untracked', ranges: [] });
+    }
+  }
+
+  return { root, ref, files };
+}
+
+/**
+ * Whether a diagnostic at `line` of `file` falls inside the change set
+ */
+export function isChangedLine(changes: ChangeSet, file: string, line: number): boolean {
+  const changed = changes.files.get(resolve(file));
+  if (!changed) {
+    return false;
+  }
+  if (changed.status === 'untracked') {
+    return true;
+  }
+  return changed.ranges.some(range => line >= range.start && line <= range.end);
+}
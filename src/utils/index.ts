@@ -8,3 +8,26 @@ export * from './file-watcher.js';
 export * from './process-manager.js';
 export * from './validation.js';
 export * from './helpers.js';
+export * from './diagnostics.js';
+export * from './cancellation.js';
+export * from './errors.js';
+export * from './suppressions.js';
+export * from './diagnostic-formatter.js';
+export * from './git-changes.js';
+export * from './workspace-roots.js';
+export * from './paths.js';
+export * from './retry.js';
+export * from './quick-fixes.js';
+export * from './workspace-config.js';
+export * from './severity-rules.js';
+export * from './path-filter.js';
+export * from './sarif.js';
+export * from './overlay.js';
+export * from './toolchain-cache.js';
+export * from './baselines.js';
+export * from './raw-output.js';
+export * from './singleflight.js';
+export * from './explanations.js';
+export * from './env.js';
+export * from './file-guard.js';
+export * from './messages.js';
@@ -8,6 +8,16 @@ import { existsSync } from 'fs';
 
 export type LogLevel = 'debug' | 'info' | 'warn' | 'error';
 
+const LOG_LEVELS: readonly LogLevel[] = ['debug', 'info', 'warn', 'error'];
+
+/**
+ * Read a log level from user input such as an environment variable
+ */
+export function parseLogLevel(value: string | undefined): LogLevel | undefined {
+  const level = value?.trim().toLowerCase();
+  return LOG_LEVELS.find(candidate => candidate === level);
+}
+
 export interface LogEntry {
   timestamp: string;
   level: LogLevel;
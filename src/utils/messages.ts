@@ -0,0 +1,218 @@
+/**
+ * Message catalog for the text the server writes itself: severity labels,
+ * summaries, skipped-file and timeout diagnostics, the diagnostics and fixes
+ * the handlers compose, and quick-fix hints. Messages from the tools are passed
+ * on as they are.
+ */
+
+import type { LocalizationConfig } from '@/types/config.js';
+
+/** Forms of a message by plural category of its `count`; `other` is required */
+export type PluralMessage = Partial<Record<Intl.LDMLPluralRule, string>> & { other: string };
+
+export type MessageTemplate = string | PluralMessage;
+
+export type MessageCatalog = Readonly<Record<string, MessageTemplate>>;
+
+export type MessageParams = Readonly<Record<string, string | number>>;
+
+export const DEFAULT_LOCALE = 'en';
+
+const ENGLISH = {
+  'severity.error': 'error',
+  'severity.warning': 'warning',
+  'severity.info': 'info',
+  'severity.hint': 'hint',
+  'summary.errors': { one: '{count} error', other: '{count} errors' },
+  'summary.warnings': { one: '{count} warning', other: '{count} warnings' },
+  'summary.info': '{count} info',
+  'summary.hints': { one: '{count} hint', other: '{count} hints' },
+  'summary.files': { one: '{count} file', other: '{count} files' },
+  'summary.separator': ', ',
+  'summary.line': '{counts} across {files}',
+  'page.range': '(showing {first}-{last} of {total})',
+  'page.partial': '(showing {shown} of {total})',
+  'page.omitted': {
+    one: '{count} of {total} diagnostics was left out; raise maxResults or page with offset and limit',
+    other: '{count} of {total} diagnostics were left out; raise maxResults or page with offset and limit',
+  },
+  'diagnostic.timeout': 'analysis timed out',
+  'skipped.tooLarge': 'File not analyzed: {size} is over the {limit} limit (maxFileSize)',
+  'skipped.encoding': 'File not analyzed: it is encoded as {encoding}; convert it to UTF-8',
+  'skipped.binary': 'File not analyzed: it looks binary',
+  'error.detectorTimeout': '{detector} analysis timed out after {timeoutMs}ms',
+  'goModule.runCommands': 'Run {commands}',
+  'goModule.or': ' or ',
+  'goModule.goSumFix': 'Run `go mod tidy` to add the missing go.sum entry',
+  'goModule.goSumModuleFix': 'Run `go mod download {module}` or `go mod tidy` to add the missing go.sum entry',
+  'goModule.goSumGoModFix': '{run} or `go mod tidy` to add the missing go.sum entry',
+  'goModule.tidy': 'go.mod is out of date with the code that uses it',
+  'goModule.vendoring': 'vendor/modules.txt does not match go.mod',
+  'goModule.vendoringFix': 'Run `go mod vendor` to update the vendor directory',
+  'goModule.missingPackageFix': 'Check the import path, or require a version of {module} that contains {package}',
+  'goModule.checksumMismatchFix': 'Make sure the go.sum entry for {module}@{version} is genuine; if so, run `go clean -modcache` and `go mod download`',
+  'goModule.modifiedFix': 'Run `go clean -modcache` and `go mod download` to restore the module cache',
+  'goModule.versionConflictFix': 'Require {module} at {version} or later, or run `go mod tidy`',
+  'goModule.invalidDependencyFix': 'Upgrade {module} to a version whose requirement on {dependency} resolves, then run `go mod tidy`',
+  'goModule.offlineBlocked': '{module}@{version} is not in the module cache, and offline mode ({setting}) blocked downloading it',
+  'goModule.offlineBlockedFix': 'Run `go mod download {module}@{version}` with network access, or analyze without offline mode',
+  'goModule.invalidVersionFix': 'Fix the version of {module} in go.mod, then run `go mod tidy`',
+  'goModule.fetchFix': 'Check that {module}@{version} can be downloaded (GOPROXY, GOPRIVATE, credentials), then run `go mod download`',
+  'goOffline.import': 'Import "{import}" needs a module that offline mode cannot download: {message}',
+  'goOffline.anyImport': 'An import needs a module that offline mode cannot download: {message}',
+  'goOffline.fix': 'Run `go mod download` with network access, or analyze without offline mode',
+  'goGenerate.failed': 'go generate failed, so the output of "{command}" was not checked: {detail}',
+  'goGenerate.timedOut': 'it timed out',
+  'goGenerate.exitStatus': 'it exited with status {status}',
+  'goGenerate.missing': '{file} is missing: "{command}" generates it',
+  'goGenerate.outdated': '{file} is out of date with "{command}"',
+  'goGenerate.firstDifference': 'First line that differs from the regenerated output',
+  'goGenerate.fix': 'Re-run go generate ./{file} and commit the regenerated files',
+  'goCgo.generatedFor': '{message} (in code cgo generated for C.{name})',
+  'goCgo.generatedPreamble': '{message} (in code cgo generated for the preamble)',
+  'goCgo.compilerFix': 'Install a C compiler or point CC at one (looked for {compiler}); files that import "C" need one unless cgo is disabled',
+  'goCgo.symbolFix': 'Define {symbol} in the preamble, or link the library that provides it with a `#cgo LDFLAGS` directive',
+  'goCgo.libraryFix': 'Check the libraries named in `#cgo LDFLAGS` directives are installed',
+  'goImports.redundantAlias': 'Import alias {alias} is the name of the package "{path}" already',
+  'goImports.redundantAliasFix': 'Remove the alias',
+  'goImports.duplicate': '"{path}" is already imported on line {line}',
+  'goImports.duplicateFix': 'Remove this import and refer to the package as {name} instead of {current}',
+  'goImports.redundantBlank': 'Blank import of "{path}" is redundant: the import on line {line} already runs its init',
+  'goImports.redundantBlankFix': 'Remove the blank import',
+  'goImports.inconsistent': '"{path}" is imported {spelling} here, but {elsewhere} of the module',
+  'goImports.inconsistentFix': 'Import it {spelling}, as the other files do, and refer to it as {name} instead of {current}',
+  'goImports.spellingFiles': { one: '{spelling} in {count} file', other: '{spelling} in {count} files' },
+  'goImports.separator': ', ',
+  'goImports.alias': 'as {alias}',
+  'goImports.noAlias': 'without an alias',
+  'goImports.importedHere': '"{path}" imported here',
+  'goImports.importedAs': '"{path}" imported {spelling} here',
+  'shellcheck.replace': 'Replace `{original}` with `{fixed}`',
+  'shellcheck.insert': 'Insert `{fixed}`'
+} satisfies MessageCatalog;
+
+/**
+ * Keys of the catalog. Quick-fix hints are keyed `quickFix.<rule id>`; their
+ * English text is the rule's own `fix`.
+ */
+export type MessageKey = keyof typeof ENGLISH | `quickFix.${string}`;
+
+const BUILTIN_CATALOGS: Readonly<Record<string, MessageCatalog>> = {
+  en: ENGLISH,
+  de: {
+    'severity.error': 'Fehler',
+    'severity.warning': 'Warnung',
+    'severity.info': 'Info',
+    'severity.hint': 'Hinweis',
+    'summary.errors': '{count} Fehler',
+    'summary.warnings': { one: '{count} Warnung', other: '{count} Warnungen' },
+    'summary.info': '{count} Info',
+    'summary.hints': { one: '{count} Hinweis', other: '{count} Hinweise' },
+    'summary.files': { one: '{count} Datei', other: '{count} Dateien' },
+    'summary.line': '{counts} in {files}',
+    'page.range': '({first}-{last} von {total} angezeigt)',
+    'page.partial': '({shown} von {total} angezeigt)',
+    'diagnostic.timeout': 'Zeitlimit der Analyse überschritten',
+    'skipped.tooLarge': 'Datei nicht analysiert: {size} überschreitet das Limit von {limit} (maxFileSize)',
+    'skipped.encoding': 'Datei nicht analysiert: sie ist als {encoding} kodiert; in UTF-8 umwandeln',
+    'skipped.binary': 'Datei nicht analysiert: sie scheint binär zu sein',
+    'quickFix.go-unused-import': 'Den nicht verwendeten Import "$1" entfernen',
+    'quickFix.go-undefined-package': 'Den fehlenden Import "$lookup" hinzufügen',
+    'quickFix.go-unused-variable': '$1$2 entfernen oder verwenden; _ zuweisen, wenn nur die Nebenwirkungen gebraucht werden',
+    'quickFix.go-no-new-variables': '= statt := verwenden, da alle Variablen links bereits deklariert sind',
+    'quickFix.go-missing-return': 'Am Ende der Funktion eine return-Anweisung ergänzen',
+    'quickFix.go-unused-result': 'Das Ergebnis von $1 einer Variablen zuweisen oder mit _ ausdrücklich verwerfen'
+  },
+  es: {
+    'severity.error': 'error',
+    'severity.warning': 'advertencia',
+    'severity.info': 'información',
+    'severity.hint': 'sugerencia',
+    'summary.errors': { one: '{count} error', other: '{count} errores' },
+    'summary.warnings': { one: '{count} advertencia', other: '{count} advertencias' },
+    'summary.info': { one: '{count} aviso informativo', other: '{count} avisos informativos' },
+    'summary.hints': { one: '{count} sugerencia', other: '{count} sugerencias' },
+    'summary.files': { one: '{count} archivo', other: '{count} archivos' },
+    'summary.line': '{counts} en {files}',
+    'page.range': '(mostrando {first}-{last} de {total})',
+    'page.partial': '(mostrando {shown} de {total})',
+    'diagnostic.timeout': 'el análisis superó el tiempo límite',
+    'skipped.tooLarge': 'Archivo no analizado: {size} supera el límite de {limit} (maxFileSize)',
+    'skipped.encoding': 'Archivo no analizado: está codificado en {encoding}; conviértalo a UTF-8',
+    'skipped.binary': 'Archivo no analizado: parece binario',
+    'quickFix.go-unused-import': 'Elimine la importación no usada "$1"',
+    'quickFix.go-undefined-package': 'Añada la importación que falta "$lookup"',
+    'quickFix.go-unused-variable': 'Elimine $1$2 o úselo; asígnelo a _ si solo se necesitan sus efectos secundarios',
+    'quickFix.go-no-new-variables': 'Use = en lugar de :=, ya que todas las variables de la izquierda ya están declaradas',
+    'quickFix.go-missing-return': 'Añada una sentencia return al final de la función',
+    'quickFix.go-unused-result': 'Asigne el resultado de $1 a una variable, o a _ para descartarlo explícitamente'
+  }
+};
+
+let activeLocale = DEFAULT_LOCALE;
+let configuredCatalogs: Readonly<Record<string, MessageCatalog>> = {};
+
+function canonicalLocale(locale: string | undefined): string {
+  try {
+    return (locale && Intl.getCanonicalLocales(locale)[0]) || DEFAULT_LOCALE;
+  } catch {
+    return DEFAULT_LOCALE;
+  }
+}
+
+/**
+ * Set the locale of server-written text and the catalogs that add to or
+ * override the built-in ones. An unknown or invalid locale renders English.
+ */
+export function configureMessages(config: LocalizationConfig = {}): void {
+  activeLocale = canonicalLocale(config.locale);
+  configuredCatalogs = Object.fromEntries(
+    Object.entries(config.messages || {}).map(([locale, catalog]) => [canonicalLocale(locale), catalog])
+  );
+}
+
+export function getLocale(): string {
+  return activeLocale;
+}
+
+/** The locale, its language without the region, then English */
+function fallbackChain(locale: string): string[] {
+  const language = locale.split('-')[0]!;
+  return Array.from(new Set([locale, language, DEFAULT_LOCALE]));
+}
+
+function selectForm(template: MessageTemplate, locale: string, count: number | undefined): string {
+  if (typeof template === 'string') {
+    return template;
+  }
+  if (count === undefined) {
+    return template.other;
+  }
+  return template[new Intl.PluralRules(locale).select(count)] ?? template.other;
+}
+
+/**
+ * The template of a message in the active locale, or undefined when no catalog
+ * of the locale, its language or English has the key. `count` picks the plural form.
+ */
+export function getMessageTemplate(key: MessageKey, count?: number): string | undefined {
+  for (const locale of fallbackChain(activeLocale)) {
+    const template = configuredCatalogs[locale]?.[key] ?? BUILTIN_CATALOGS[locale]?.[key];
+    if (template !== undefined) {
+      return selectForm(template, locale, count);
+    }
+  }
+  return undefined;
+}
+
+/**
+ * Render a message in the active locale, replacing `{name}` with its parameter.
+ * A numeric `count` parameter selects the plural form.
+ */
+export function formatMessage(key: MessageKey, params: MessageParams = {}): string {
+  const count = typeof params['count'] === 'number' ? params['count'] : undefined;
+  const template = getMessageTemplate(key, count) ?? key;
+  return template.replace(/\{(\w+)\}/g, (placeholder, name: string) =>
+    params[name] !== undefined ? String(params[name]) : placeholder
+  );
+}
@@ -0,0 +1,175 @@
+/**
+ * Unsaved editor buffers, analyzed through a temporary mirror of the workspace
+ */
+
+import { promises as fs, type Dirent } from 'fs';
+import { tmpdir } from 'os';
+import { dirname, isAbsolute, join, relative, resolve, sep } from 'path';
+import type { LanguageError } from '@/types/languages.js';
+
+/** File contents by path that replace what is on disk for one analysis */
+export type Overlay = Record<string, string>;
+
+function isInside(root: string, path: string): boolean {
+  const rel = relative(root, path);
+  return rel === '' || (!rel.startsWith('..') && !isAbsolute(rel));
+}
+
+/**
+ * A temporary directory that stands in for `root`, with overlay files written in
+ * place of the originals. Only the directories leading to an overlay file are
+ * real: their other files are copied and everything else is symlinked back to
+ * the original, so setting one up does not depend on the size of the workspace.
+ * Tools may write into the copies (e.g. a go.sum update) without touching the
+ * original files, and the overlay contents are never written outside the mirror.
+ */
+export class OverlayMirror {
+  private constructor(
+    /** Original directory, with symlinks resolved and as given */
+    private readonly roots: string[],
+    /** Mirror directory, as created and with symlinks resolved */
+    private readonly mirrorRoots: string[]
+  ) {}
+
+  /**
+   * Mirror `root` with the overlay files, given by absolute path, replaced.
+   * Rejects overlay paths outside the root.
+   */
+  static async create(root: string, files: Map<string, string>): Promise<OverlayMirror> {
+    const realRoot = await fs.realpath(root);
+    const relativeFiles = new Map<string, string>();
+    for (const [file, content] of files) {
+      const base = [root, realRoot].find(candidate => isInside(candidate, file));
+      if (!base) {
+        throw new Error(`Overlay file ${file} is outside ${root}`);
+      }
+      relativeFiles.set(relative(base, file), content);
+    }
+
+    const mirrorRoot = await fs.mkdtemp(join(tmpdir(), 'error-debugging-overlay-'));
+    const mirror = new OverlayMirror(
+      Array.from(new Set([realRoot, resolve(root)])),
+      Array.from(new Set([mirrorRoot, await fs.realpath(mirrorRoot)]))
+    );
+
+    try {
+      // Every directory on the way to an overlay file has to be real so the file can be replaced
+      const realDirs = new Set<string>(['']);
+      for (const file of relativeFiles.keys()) {
+        for (let dir = dirname(file); dir !== '.' && !realDirs.has(dir); dir = dirname(dir)) {
+          realDirs.add(dir);
+        }
+      }
+
+      await mirror.mirrorDirectory(realRoot, mirrorRoot, '', realDirs, relativeFiles);
+      for (const [file, content] of relativeFiles) {
+        await fs.mkdir(dirname(join(mirrorRoot, file)), { recursive: true });
+        await fs.writeFile(join(mirrorRoot, file), content);
+      }
+      return mirror;
+    } catch (error) {
+      await mirror.dispose();
+      throw error;
+    }
+  }
+
+  /** The mirror directory */
+  get path(): string {
+    return this.mirrorRoots[0]!;
+  }
+
+  /** The mirrored workspace directory, with symlinks resolved */
+  get originalRoot(): string {
+    return this.roots[0]!;
+  }
+
+  private async mirrorDirectory(
+    realRoot: string,
+    mirrorRoot: string,
+    dir: string,
+    realDirs: Set<string>,
+    overlay: Map<string, string>
+  ): Promise<void> {
+    await fs.mkdir(join(mirrorRoot, dir), { recursive: true });
+
+    let entries: Dirent[];
+    try {
+      entries = await fs.readdir(join(realRoot, dir), { withFileTypes: true });
+    } catch {
+      // A directory that only exists in the overlay
+      return;
+    }
+
+    for (const entry of entries) {
+      const child = join(dir, entry.name);
+      const source = join(realRoot, child);
+      const target = join(mirrorRoot, child);
+
+      if (overlay.has(child)) {
+        continue;
+      }
+      if (entry.isDirectory() && realDirs.has(child)) {
+        await this.mirrorDirectory(realRoot, mirrorRoot, child, realDirs, overlay);
+      } else if (entry.isFile()) {
+        await fs.copyFile(source, target);
+      } else {
+        await fs.symlink(source, target, entry.isDirectory() ? 'junction' : 'file');
+      }
+    }
+  }
+
+  /**
+   * Where a path of the original workspace lives in the mirror
+   */
+  toMirror(path: string): string {
+    const root = this.roots.find(candidate => isInside(candidate, path));
+    return root ? join(this.path, relative(root, path)) : path;
+  }
+
+  /**
+   * Map a path in the mirror back to the original workspace; other paths are kept
+   */
+  fromMirror(path: string): string {
+    const mirrorRoot = this.mirrorRoots.find(candidate => path === candidate || path.startsWith(candidate + sep));
+    return mirrorRoot ? join(this.roots[0]!, path.slice(mirrorRoot.length)) : path;
+  }
+
+  /**
+   * Point diagnostics, their related locations and any mirror paths quoted in
+   * their messages back at the original files
+   */
+  remapErrors(errors: LanguageError[]): LanguageError[] {
+    return errors.map(error => {
+      const remapped: LanguageError = {
+        ...error,
+        message: this.remapText(error.message),
+        location: { ...error.location, file: this.fromMirror(error.location.file) }
+      };
+      if (error.relatedInformation) {
+        remapped.relatedInformation = error.relatedInformation.map(info => ({
+          ...info,
+          message: this.remapText(info.message),
+          location: { ...info.location, file: this.fromMirror(info.location.file) }
+        }));
+      }
+      return remapped;
+    });
+  }
+
+  /**
+   * Replace mirror paths anywhere in a text with the original workspace root
+   */
+  remapText(text: string): string {
+    // Longest first, so a resolved /private/var/... path is not half-replaced on macOS
+    return [...this.mirrorRoots]
+      .sort((a, b) => b.length - a.length)
+      .reduce((result, mirrorRoot) => result.split(mirrorRoot).join(this.roots[0]!), text);
+  }
+
+  /**
+   * Remove the mirror. Symlinks are removed, never followed.
+   */
+  async dispose(): Promise<void> {
+    await fs.rm(this.path, { recursive: true, force: true }).catch(() => {});
+  }
+}
@@ -0,0 +1,93 @@
+/**
+ * Include/exclude glob filtering of diagnostics by workspace-relative path
+ */
+
+export interface PathFilterOptions {
+  /** Only files matching one of these are reported (default: every file) */
+  include?: string[];
+  /** Files matching one of these are never reported, even when included */
+  exclude?: string[];
+}
+
+/**
+ * Compile a doublestar glob into a regex over `/`-separated relative paths.
+ *
+ * - `*` matches within one path segment, `?` one character of it
+ * - `**` as a whole segment matches any number of segments, including none
+ * - `{a,b}` matches either alternative and `[abc]` one of the characters
+ * - A trailing `/` matches everything below the directory
+ */
+export function globToRegExp(glob: string): RegExp {
+  let pattern = glob.replace(/\\/g, '/').replace(/^\.\//, '');
+  if (pattern.endsWith('/')) {
+    pattern += '**';
+  }
+
+  let source = '';
+  let braceDepth = 0;
+  for (let index = 0; index < pattern.length; index++) {
+    const char = pattern[index]!;
+
+    if (char === '*' && pattern[index + 1] === '*') {
+      const atStart = index === 0 || pattern[index - 1] === '/';
+      const next = pattern[index + 2];
+      if (atStart && next === '/') {
+        // `**/` also matches no directory at all
+        source += '(?:.*/)?';
+        index += 2;
+        continue;
+      }
+      if (atStart && next === undefined) {
+        source += '.*';
+        index += 1;
+        continue;
+      }
+      // `a**b` is two single-segment stars
+      source += '[^/]*';
+      index += 1;
+    } else if (char === '*') {
+      source += '[^/]*';
+    } else if (char === '?') {
+      source += '[^/]';
+    } else if (char === '[') {
+      const end = pattern.indexOf(']', index + 2);
+      if (end < 0) {
+        source += '\\[';
+        continue;
+      }
+      const body = pattern.slice(index + 1, end).replace(/\\/g, '\\\\');
+      source += body.startsWith('!') ? `[^/${body.slice(1)}]` : `[${body}]`;
+      index = end;
+    } else if (char === '{') {
+      braceDepth++;
+      source += '(?:';
+    } else if (char === '}' && braceDepth > 0) {
+      braceDepth--;
+      source += ')';
+    } else if (char === ',' && braceDepth > 0) {
+      source += '|';
+    } else {
+      source += char.replace(/[.+^$()|{}\]\\]/g, '\\$&');
+    }
+  }
+  source += ')'.repeat(braceDepth);
+
+  return new RegExp(`^${source}$`);
+}
+
+/**
+ * Build a predicate over workspace-relative paths. Excludes win over includes; an
+ * empty include list includes everything.
+ */
+export function compilePathFilter(options: PathFilterOptions = {}): (relativePath: string) => boolean {
+  const include = (options.include || []).map(globToRegExp);
+  const exclude = (options.exclude || []).map(globToRegExp);
+
+  return (relativePath: string): boolean => {
+    const path = relativePath.replace(/\\/g, '/').replace(/^\.\//, '');
+    if (exclude.some(pattern => pattern.test(path))) {
+      return false;
+    }
+    return include.length === 0 || include.some(pattern => pattern.test(path));
+  };
+}
@@ -0,0 +1,119 @@
+/**
+ * Canonical file paths for diagnostics, so the same file reported by different tools compares equal
+ */
+
+import { promises as fs } from 'fs';
+import { basename, dirname, isAbsolute, join, resolve } from 'path';
+import type { LanguageError } from '@/types/languages.js';
+
+const DRIVE_PATH = /^([a-zA-Z]):[\\/]/;
+
+/**
+ * Upper-case the drive letter of a Windows path (`c:\src` becomes `C:\src`)
+ */
+export function normalizeDriveLetter(path: string): string {
+  const match = path.match(DRIVE_PATH);
+  return match ? `${match[1]!.toUpperCase()}${path.slice(1)}` : path;
+}
+
+async function exists(path: string): Promise<boolean> {
+  try {
+    await fs.access(path);
+    return true;
+  } catch {
+    return false;
+  }
+}
+
+/**
+ * An absolute path with symlinks resolved, as diagnostics name files. For a path
+ * that does not exist, such as a deleted file, its nearest existing parent is resolved.
+ */
+export async function canonicalPath(path: string): Promise<string> {
+  const absolute = resolve(path);
+  try {
+    return normalizeDriveLetter(await fs.realpath(absolute));
+  } catch {
+    const parent = dirname(absolute);
+    return parent === absolute ? normalizeDriveLetter(absolute) : join(await canonicalPath(parent), basename(absolute));
+  }
+}
+
+/**
+ * Resolves the file names tools print (`./pkg/a.go`, `src/main.rs`, absolute paths
+ * through symlinks) to absolute paths with symlinks resolved. Results are cached,
+ * so one normalizer should cover a single analysis run.
+ */
+export class PathNormalizer {
+  private readonly baseDirs: string[];
+  private readonly cache = new Map<string, Promise<string>>();
+
+  /**
+   * @param baseDirs Directories relative names are tried against, in order. The
+   * first one that contains the file wins; the first one is used when none does.
+   */
+  constructor(baseDirs: string[] = []) {
+    this.baseDirs = baseDirs.length > 0 ? baseDirs : [process.cwd()];
+  }
+
+  normalize(file: string): Promise<string> {
+    if (!file) {
+      return Promise.resolve(file);
+    }
+
+    let normalized = this.cache.get(file);
+    if (!normalized) {
+      normalized = this.canonicalize(file);
+      this.cache.set(file, normalized);
+    }
+    return normalized;
+  }
+
+  private async canonicalize(file: string): Promise<string> {
+    const absolute = await this.toAbsolute(file);
+
+    try {
+      return normalizeDriveLetter(await fs.realpath(absolute));
+    } catch {
+      // Files that no longer exist (temporary copies, deleted sources) keep their resolved path
+      return normalizeDriveLetter(absolute);
+    }
+  }
+
+  private async toAbsolute(file: string): Promise<string> {
+    if (DRIVE_PATH.test(file)) {
+      return process.platform === 'win32' ? resolve(file) : file;
+    }
+    if (isAbsolute(file)) {
+      return resolve(file);
+    }
+
+    const candidates = this.baseDirs.map(base => resolve(base, file));
+    for (const candidate of candidates) {
+      if (await exists(candidate)) {
+        return candidate;
+      }
+    }
+    return candidates[0]!;
+  }
+}
+
+/**
+ * Rewrite the file of every diagnostic and related location to its canonical form
+ */
+export async function normalizeErrorPaths(errors: LanguageError[], normalizer: PathNormalizer): Promise<LanguageError[]> {
+  return Promise.all(errors.map(async error => {
+    const normalized: LanguageError = {
+      ...error,
+      location: { ...error.location, file: await normalizer.normalize(error.location.file) }
+    };
+
+    if (error.relatedInformation) {
+      normalized.relatedInformation = await Promise.all(error.relatedInformation.map(async info => ({
+        ...info,
+        location: { ...info.location, file: await normalizer.normalize(info.location.file) }
+      })));
+    }
+    return normalized;
+  }));
+}
@@ -0,0 +1,143 @@
+/**
+ * Quick-fix hints for well-understood diagnostics, driven by a table of rules
+ */
+
+import type { LanguageError } from '@/types/languages.js';
+import { getMessageTemplate } from './messages.js';
+
+/**
+ * One entry of the quick-fix table. A rule applies when the diagnostic's
+ * language, code and message all match; the first applicable rule wins.
+ */
+export interface QuickFixRule {
+  id: string;
+  /** Diagnostic `source` values the rule applies to, such as `go`; any when omitted */
+  sources?: readonly string[];
+  /** Exact diagnostic code; any when omitted */
+  code?: string;
+  /** Matched against the first line of the message */
+  pattern: RegExp;
+  /**
+   * Suggestion text. `$1`, `$2`... are replaced with the pattern's capture groups
+   * and `$lookup` with the `lookup` entry for the first group. A `quickFix.<id>`
+   * message of the configured locale takes its place.
+   */
+  fix: string;
+  /** When set, the rule only applies if the first capture group is a key */
+  lookup?: Readonly<Record<string, string>>;
+}
+
+/**
+ * Standard library packages by the identifier code refers to them with
+ */
+export const GO_STANDARD_PACKAGES: Readonly<Record<string, string>> = Object.freeze({
+  atomic: 'sync/atomic',
+  base64: 'encoding/base64',
+  bufio: 'bufio',
+  bytes: 'bytes',
+  context: 'context',
+  errors: 'errors',
+  exec: 'os/exec',
+  filepath: 'path/filepath',
+  fmt: 'fmt',
+  hex: 'encoding/hex',
+  http: 'net/http',
+  io: 'io',
+  json: 'encoding/json',
+  log: 'log',
+  math: 'math',
+  os: 'os',
+  rand: 'math/rand',
+  reflect: 'reflect',
+  regexp: 'regexp',
+  signal: 'os/signal',
+  slices: 'slices',
+  sort: 'sort',
+  strconv: 'strconv',
+  strings: 'strings',
+  sync: 'sync',
+  time: 'time',
+  unicode: 'unicode',
+  url: 'net/url',
+  utf8: 'unicode/utf8',
+});
+
+export const DEFAULT_QUICK_FIX_RULES: readonly QuickFixRule[] = Object.freeze([
+  {
+    id: 'go-unused-import',
+    sources: ['go'],
+    pattern: /^"([^"]+)" imported (?:as \w+ )?and not used/,
+    fix: 'Remove the unused import "$1"',
+  },
+  {
+    id: 'go-undefined-package',
+    sources: ['go'],
+    pattern: /^undefined: (\w+)$/,
+    lookup: GO_STANDARD_PACKAGES,
+    fix: 'Add the missing import "$lookup"',
+  },
+  {
+    id: 'go-unused-variable',
+    sources: ['go'],
+    pattern: /^(?:declared and not used: (\w+)|(\w+) declared (?:and|but) not used)$/,
+    fix: 'Remove $1$2 or use it; assign it to _ if only its side effects are needed',
+  },
+  {
+    id: 'go-no-new-variables',
+    sources: ['go'],
+    pattern: /^no new variables on left side of :=$/,
+    fix: 'Use = instead of :=, since every variable on the left is already declared',
+  },
+  {
+    id: 'go-missing-return',
+    sources: ['go'],
+    pattern: /^missing return$/,
+    fix: 'Add a return statement at the end of the function',
+  },
+  {
+    id: 'go-unused-result',
+    sources: ['go'],
+    pattern: /^(.+) \(value of type .+\) is not used$/,
+    fix: 'Assign the result of $1 to a variable, or to _ to discard it explicitly',
+  },
+]);
+
+function renderFix(template: string, match: RegExpMatchArray, lookupValue: string | undefined): string {
+  return template.replace(/\$(lookup|\d)/g, (_, name: string) =>
+    name === 'lookup' ? lookupValue ?? '' : match[Number(name)] ?? ''
+  );
+}
+
+/**
+ * Find the quick-fix hint for a diagnostic, or undefined when no rule matches
+ */
+export function suggestFix(
+  error: Pick<LanguageError, 'message' | 'source' | 'code'>,
+  rules: readonly QuickFixRule[] = DEFAULT_QUICK_FIX_RULES
+): string | undefined {
+  const message = error.message.split('\n')[0]?.trim() || '';
+  const code = error.code !== undefined ? String(error.code) : undefined;
+
+  for (const rule of rules) {
+    if (rule.sources && !rule.sources.includes(error.source)) {
+      continue;
+    }
+    if (rule.code !== undefined && rule.code !== code) {
+      continue;
+    }
+
+    const match = message.match(rule.pattern);
+    if (!match) {
+      continue;
+    }
+
+    const key = match[1] || '';
+    if (rule.lookup && !Object.hasOwn(rule.lookup, key)) {
+      continue;
+    }
+    const lookupValue = rule.lookup?.[key];
+    return renderFix(getMessageTemplate(`quickFix.${rule.id}`) ?? rule.fix, match, lookupValue);
+  }
+
+  return undefined;
+}
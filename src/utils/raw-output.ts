@@ -0,0 +1,155 @@
+/**
+ * Raw output of the tools run for a request, returned with `includeRaw`
+ */
+
+import { AsyncLocalStorage } from 'async_hooks';
+import { realpathSync } from 'fs';
+import { tmpdir } from 'os';
+import { basename, sep } from 'path';
+
+/** Output kept per tool run; the rest is cut with a note */
+export const MAX_RAW_OUTPUT_CHARS = 100_000;
+
+/** Continuation lines attached to a diagnostic after the line that reported it */
+const MAX_CONTINUATION_LINES = 20;
+
+export interface ToolInvocation {
+  /** Executable name, such as `go` or `tsc` */
+  tool: string;
+  commandLine: string;
+  exitCode: number;
+  /** stdout followed by stderr */
+  output: string;
+}
+
+export type RawOutputsByTool = Record<string, Array<Omit<ToolInvocation, 'tool'>>>;
+
+/**
+ * Collects every tool run of a request, in the order the runs finished
+ */
+export class RawOutputRecorder {
+  private invocations: ToolInvocation[] = [];
+
+  record(command: string, args: string[], result: { stdout: string; stderr: string; exitCode: number }): void {
+    const output = result.stdout && result.stderr && !result.stdout.endsWith('\n')
+      ? `${result.stdout}\n${result.stderr}`
+      : result.stdout + result.stderr;
+    this.invocations.push({
+      tool: basename(command),
+      commandLine: [command, ...args].join(' '),
+      exitCode: result.exitCode,
+      output: output.length > MAX_RAW_OUTPUT_CHARS
+        ? `${output.slice(0, MAX_RAW_OUTPUT_CHARS)}\n[${output.length - MAX_RAW_OUTPUT_CHARS} more characters omitted]`
+        : output
+    });
+  }
+
+  /** Number of runs recorded so far */
+  get size(): number {
+    return this.invocations.length;
+  }
+
+  /**
+   * Rewrite the command lines and output of the runs recorded from `start` on
+   */
+  rewrite(start: number, rewrite: (text: string) => string): void {
+    for (const invocation of this.invocations.slice(start)) {
+      invocation.commandLine = rewrite(invocation.commandLine);
+      invocation.output = rewrite(invocation.output);
+    }
+  }
+
+  getInvocations(): ToolInvocation[] {
+    return [...this.invocations];
+  }
+
+  byTool(): RawOutputsByTool {
+    const grouped: RawOutputsByTool = {};
+    for (const { tool, ...run } of this.invocations) {
+      const runs = grouped[tool] || [];
+      runs.push(run);
+      grouped[tool] = runs;
+    }
+    return grouped;
+  }
+}
+
+const recorderScope = new AsyncLocalStorage<RawOutputRecorder>();
+
+/**
+ * Run a function with every tool it spawns recorded
+ */
+export function recordRawOutput<T>(recorder: RawOutputRecorder, fn: () => Promise<T>): Promise<T> {
+  return recorderScope.run(recorder, fn);
+}
+
+/**
+ * Get the recorder of the enclosing `recordRawOutput` call, if any
+ */
+export function currentRawOutputRecorder(): RawOutputRecorder | undefined {
+  return recorderScope.getStore();
+}
+
+function escapeRegExp(text: string): string {
+  return text.replace(/[.*+?^${}()|[\]\\]/g, '\\$&');
+}
+
+/**
+ * Replace temporary directories, such as the mirror of an overlay or a scratch
+ * module, with `<tmp>`: `/tmp/go-syntax-check-x1/main.go` becomes `<tmp>/main.go`.
+ * Directories in `keep`, such as a workspace that itself lives in a temp dir,
+ * are left alone.
+ */
+export function redactTempPaths(text: string, keep: string[] = []): string {
+  const roots = new Set([tmpdir()]);
+  try {
+    roots.add(realpathSync(tmpdir()));
+  } catch {
+    // Keep the configured path only
+  }
+
+  const kept = (dir: string) => keep.some(path => path === dir || path.startsWith(dir + sep));
+  // Longest first, so a resolved /private/var/... path is not half-replaced on macOS
+  return Array.from(roots)
+    .sort((a, b) => b.length - a.length)
+    .reduce((result, root) => result.replace(
+      new RegExp(`${escapeRegExp(root.endsWith(sep) ? root.slice(0, -1) : root)}[\\\\/][^\\\\/\\s'"\`:]+`, 'g'),
+      dir => kept(dir) ? dir : '<tmp>'
+    ), text);
+}
+
+/**
+ * The output lines a diagnostic was most likely parsed from: the first line
+ * holding its message and position, else its message, else its file and line,
+ * followed by indented continuation lines. Empty when no line matches, as for
+ * diagnostics that come from a language server rather than tool output.
+ */
+export function findRawLines(
+  diagnostic: { file: string; line: number; message: string },
+  invocations: ToolInvocation[]
+): string[] {
+  const head = diagnostic.message.split('\n')[0]!.trim();
+  const name = basename(diagnostic.file);
+  const position = new RegExp(`${escapeRegExp(name)}(?::|\\(|", line )${diagnostic.line}\\b`);
+  const tests: Array<(text: string) => boolean> = [
+    text => text.includes(head) && position.test(text),
+    text => head.length >= 8 && text.includes(head),
+    text => position.test(text)
+  ];
+
+  for (const test of tests) {
+    for (const invocation of invocations) {
+      const lines = invocation.output.split('\n');
+      const index = lines.findIndex(test);
+      if (index < 0) {
+        continue;
+      }
+      let end = index + 1;
+      while (end < lines.length && end - index <= MAX_CONTINUATION_LINES && /^\s+\S/.test(lines[end]!)) {
+        end++;
+      }
+      return lines.slice(index, end);
+    }
+  }
+  return [];
+}
@@ -0,0 +1,48 @@
+/**
+ * Retry with exponential backoff for tool runs that fail for reasons outside the code
+ */
+
+import type { ToolchainRetryConfig } from '@/types/config.js';
+
+export const DEFAULT_RETRY_OPTIONS: Readonly<Required<ToolchainRetryConfig>> = Object.freeze({
+  retries: 2,
+  backoffMs: 1000,
+  maxBackoffMs: 10_000,
+});
+
+/**
+ * Fill in defaults for unset retry settings
+ */
+export function resolveRetryOptions(config: ToolchainRetryConfig = {}): Required<ToolchainRetryConfig> {
+  return {
+    retries: Math.max(0, config.retries ?? DEFAULT_RETRY_OPTIONS.retries),
+    backoffMs: Math.max(0, config.backoffMs ?? DEFAULT_RETRY_OPTIONS.backoffMs),
+    maxBackoffMs: Math.max(0, config.maxBackoffMs ?? DEFAULT_RETRY_OPTIONS.maxBackoffMs),
+  };
+}
+
+/**
+ * Wait before retry `attempt` (1-based): `backoffMs`, doubling each attempt, capped at `maxBackoffMs`
+ */
+export function backoffDelay(attempt: number, options: Required<ToolchainRetryConfig>): number {
+  return Math.min(options.backoffMs * 2 ** Math.max(0, attempt - 1), options.maxBackoffMs);
+}
+
+/**
+ * Sleep for `ms`, returning early when the signal aborts so the caller can observe it
+ */
+export function delay(ms: number, signal?: AbortSignal): Promise<void> {
+  if (ms <= 0 || signal?.aborted) {
+    return Promise.resolve();
+  }
+
+  return new Promise(resolve => {
+    const done = () => {
+      clearTimeout(timer);
+      signal?.removeEventListener('abort', done);
+      resolve();
+    };
+    const timer = setTimeout(done, ms);
+    signal?.addEventListener('abort', done, { once: true });
+  });
+}
@@ -0,0 +1,238 @@
+/**
+ * SARIF 2.1.0 rendering of diagnostics, for code scanning in CI
+ */
+
+import { isAbsolute, relative, sep } from 'path';
+import { pathToFileURL } from 'url';
+import type { DiagnosticRecord, DiagnosticSeverity } from './diagnostics.js';
+import { formatMessage } from './messages.js';
+
+export const SARIF_VERSION = '2.1.0';
+export const SARIF_SCHEMA = 'https://json.schemastore.org/sarif-2.1.0.json';
+
+export type SarifLevel = 'error' | 'warning' | 'note';
+
+export interface SarifArtifactLocation {
+  uri: string;
+  uriBaseId?: string;
+}
+
+export interface SarifRegion {
+  startLine: number;
+  startColumn: number;
+  endLine?: number;
+  endColumn?: number;
+}
+
+export interface SarifPhysicalLocation {
+  artifactLocation: SarifArtifactLocation;
+  region: SarifRegion;
+}
+
+export interface SarifResult {
+  ruleId?: string;
+  ruleIndex?: number;
+  level: SarifLevel;
+  message: { text: string };
+  locations: Array<{ physicalLocation: SarifPhysicalLocation }>;
+  relatedLocations?: Array<{ id: number; physicalLocation: SarifPhysicalLocation; message: { text: string } }>;
+  properties?: Record<string, unknown>;
+}
+
+export interface SarifNotification {
+  level: SarifLevel;
+  message: { text: string };
+}
+
+export interface SarifInvocation {
+  executionSuccessful: boolean;
+  toolExecutionNotifications: SarifNotification[];
+}
+
+export interface SarifRun {
+  tool: {
+    driver: {
+      name: string;
+      rules: Array<{ id: string }>;
+    };
+  };
+  originalUriBaseIds?: Record<string, { uri: string }>;
+  /** Diagnostics count columns in code points whatever the tool */
+  columnKind: 'unicodeCodePoints';
+  results: SarifResult[];
+  invocations?: SarifInvocation[];
+  properties?: Record<string, unknown>;
+}
+
+export interface SarifLog {
+  $schema: string;
+  version: typeof SARIF_VERSION;
+  runs: SarifRun[];
+}
+
+export interface SarifFormatOptions {
+  /**
+   * Directory that files outside every workspace root are made relative to;
+   * files outside it too keep an absolute file URI
+   */
+  baseDir?: string;
+  /**
+   * How many diagnostics matched in all when `diagnostics` is only a page of
+   * them. Every run is then marked truncated, so CI does not mistake the log
+   * for the complete report.
+   */
+  total?: number;
+  /** Notes on the report as a whole, reported as notifications of every run */
+  notes?: string[];
+}
+
+const SARIF_LEVELS: Readonly<Record<DiagnosticSeverity, SarifLevel>> = Object.freeze({
+  error: 'error',
+  warning: 'warning',
+  info: 'note',
+  hint: 'note',
+});
+
+/** Driver of the empty run reported when nothing was found, so CI clears old alerts */
+const FALLBACK_DRIVER = 'error-debugging-mcp-server';
+
+/** Base id of the first root; later roots are numbered from 2 */
+const ROOT_BASE_ID = 'SRCROOT';
+
+function directoryUri(directory: string): string {
+  const uri = pathToFileURL(directory).href;
+  return uri.endsWith('/') ? uri : `${uri}/`;
+}
+
+/**
+ * Relative references use forward slashes and percent-encoded segments
+ */
+function relativeUri(relativePath: string): string {
+  return relativePath.split(sep).map(segment => encodeURIComponent(segment)).join('/');
+}
+
+function isInside(relativePath: string): boolean {
+  return relativePath !== '' && !relativePath.startsWith('..') && !isAbsolute(relativePath);
+}
+
+/**
+ * Serialize diagnostics as a SARIF log with one run per reporting tool, in order
+ * of first appearance. `code` becomes the rule id and each run lists its rules.
+ * Files inside a workspace root are addressed relative to that root through
+ * `originalUriBaseIds`, as the SARIF spec recommends for portable results.
+ */
+export function formatDiagnosticsSarif(diagnostics: DiagnosticRecord[], options: SarifFormatOptions = {}): SarifLog {
+  const baseIds = new Map<string, string>();
+  const baseIdFor = (root: string): string => {
+    let id = baseIds.get(root);
+    if (!id) {
+      id = baseIds.size === 0 ? ROOT_BASE_ID : `${ROOT_BASE_ID}${baseIds.size + 1}`;
+      baseIds.set(root, id);
+    }
+    return id;
+  };
+
+  // Related files are addressed from the diagnostic's root when they are inside it
+  const fileLocation = (file: string, root?: string): SarifArtifactLocation => {
+    for (const base of [root, options.baseDir]) {
+      const relativePath = base ? relative(base, file) : '';
+      if (base && isInside(relativePath)) {
+        return { uri: relativeUri(relativePath), uriBaseId: baseIdFor(base) };
+      }
+    }
+    return { uri: pathToFileURL(file).href };
+  };
+
+  const artifactLocation = (diagnostic: DiagnosticRecord): SarifArtifactLocation => {
+    if (diagnostic.root && diagnostic.relativePath) {
+      return { uri: relativeUri(diagnostic.relativePath), uriBaseId: baseIdFor(diagnostic.root) };
+    }
+    return fileLocation(diagnostic.file);
+  };
+
+  const runs = new Map<string, { run: SarifRun; ruleIndexes: Map<string, number> }>();
+  for (const diagnostic of diagnostics) {
+    let entry = runs.get(diagnostic.source);
+    if (!entry) {
+      entry = {
+        run: { tool: { driver: { name: diagnostic.source, rules: [] } }, columnKind: 'unicodeCodePoints', results: [] },
+        ruleIndexes: new Map()
+      };
+      runs.set(diagnostic.source, entry);
+    }
+
+    const region: SarifRegion = { startLine: diagnostic.line, startColumn: diagnostic.column };
+    // Empty ranges are left open so viewers highlight from the start position
+    if (diagnostic.endLine > diagnostic.line || diagnostic.endColumn > diagnostic.column) {
+      region.endLine = diagnostic.endLine;
+      region.endColumn = diagnostic.endColumn;
+    }
+
+    const result: SarifResult = {
+      level: SARIF_LEVELS[diagnostic.severity],
+      message: { text: diagnostic.message },
+      locations: [{ physicalLocation: { artifactLocation: artifactLocation(diagnostic), region } }],
+    };
+    if (diagnostic.relatedInformation) {
+      result.relatedLocations = diagnostic.relatedInformation.map((info, id) => ({
+        id,
+        physicalLocation: {
+          artifactLocation: fileLocation(info.file, diagnostic.root),
+          region: { startLine: info.line, startColumn: info.column },
+        },
+        message: { text: info.message },
+      }));
+    }
+    if (diagnostic.code !== null) {
+      let ruleIndex = entry.ruleIndexes.get(diagnostic.code);
+      if (ruleIndex === undefined) {
+        ruleIndex = entry.run.tool.driver.rules.push({ id: diagnostic.code }) - 1;
+        entry.ruleIndexes.set(diagnostic.code, ruleIndex);
+      }
+      result.ruleId = diagnostic.code;
+      result.ruleIndex = ruleIndex;
+    }
+
+    const properties: Record<string, unknown> = {
+      ...(diagnostic.analyzer && { analyzer: diagnostic.analyzer }),
+      ...(diagnostic.sources.length > 1 && { sources: diagnostic.sources }),
+      ...(diagnostic.suggestedFix && { suggestedFix: diagnostic.suggestedFix }),
+    };
+    if (Object.keys(properties).length > 0) {
+      result.properties = properties;
+    }
+    entry.run.results.push(result);
+  }
+
+  // Every run gets the base ids, since results of one tool can span roots
+  const originalUriBaseIds = Object.fromEntries(
+    Array.from(baseIds, ([root, id]) => [id, { uri: directoryUri(root) }])
+  );
+  const sarifRuns = Array.from(runs.values(), ({ run }) => (
+    baseIds.size > 0 ? { ...run, originalUriBaseIds } : run
+  ));
+  if (sarifRuns.length === 0) {
+    sarifRuns.push({ tool: { driver: { name: FALLBACK_DRIVER, rules: [] } }, columnKind: 'unicodeCodePoints', results: [] });
+  }
+
+  const total = Math.max(options.total ?? 0, diagnostics.length);
+  const omittedCount = total - diagnostics.length;
+  const notifications: SarifNotification[] = (options.notes ?? []).map(text => ({ level: 'note', message: { text } }));
+  if (omittedCount > 0) {
+    notifications.unshift({
+      level: 'warning',
+      message: { text: formatMessage('page.omitted', { count: omittedCount, total }) },
+    });
+  }
+  return {
+    $schema: SARIF_SCHEMA,
+    version: SARIF_VERSION,
+    runs: sarifRuns.map(run => ({
+      ...run,
+      ...(notifications.length > 0 && {
+        invocations: [{ executionSuccessful: true, toolExecutionNotifications: notifications }],
+      }),
+      ...(omittedCount > 0 && { properties: { truncated: true, total, omittedCount } }),
+    })),
+  };
+}
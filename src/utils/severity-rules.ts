@@ -0,0 +1,73 @@
+/**
+ * Severity remapping: promote, demote or drop diagnostics by code or message
+ */
+
+import type { LanguageError } from '@/types/languages.js';
+
+/** Severity to report a diagnostic with; `off` drops it */
+export type SeverityTarget = LanguageError['severity'] | 'off';
+
+/**
+ * Remapping table. Keys are codes or analyzer names (`printf`, `SA1019`),
+ * matched case-insensitively, or regexes written `/pattern/flags` that are
+ * tested against the message.
+ */
+export type SeverityRemap = Record<string, SeverityTarget>;
+
+export interface SeverityRule {
+  /** Lower-cased code or analyzer name */
+  code?: string;
+  pattern?: RegExp;
+  severity: SeverityTarget;
+}
+
+const REGEX_KEY = /^\/(.+)\/([a-z]*)$/;
+
+/**
+ * Turn a remapping table into rules, in key order. Throws on an invalid regex key.
+ */
+export function compileSeverityRules(remap: SeverityRemap = {}): SeverityRule[] {
+  return Object.entries(remap).map(([key, severity]) => {
+    const regex = key.match(REGEX_KEY);
+    if (!regex) {
+      return { code: key.toLowerCase(), severity };
+    }
+
+    try {
+      return { pattern: new RegExp(regex[1]!, regex[2]), severity };
+    } catch (error) {
+      throw new Error(`invalid severity pattern ${key}: ${error instanceof Error ? error.message : String(error)}`);
+    }
+  });
+}
+
+function matches(error: LanguageError, rule: SeverityRule): boolean {
+  if (rule.pattern) {
+    rule.pattern.lastIndex = 0;
+    return rule.pattern.test(error.message);
+  }
+  return [error.code, error.analyzer]
+    .some(value => value !== undefined && String(value).toLowerCase() === rule.code);
+}
+
+/**
+ * Apply the first matching rule to each diagnostic, dropping those mapped to `off`.
+ * Runs before deduplication and counting, so merged duplicates and summaries see
+ * the remapped severity.
+ */
+export function applySeverityRules(errors: LanguageError[], rules: SeverityRule[]): LanguageError[] {
+  if (rules.length === 0) {
+    return errors;
+  }
+
+  const remapped: LanguageError[] = [];
+  for (const error of errors) {
+    const rule = rules.find(candidate => matches(error, candidate));
+    if (!rule) {
+      remapped.push(error);
+    } else if (rule.severity !== 'off') {
+      remapped.push(rule.severity === error.severity ? error : { ...error, severity: rule.severity });
+    }
+  }
+  return remapped;
+}
@@ -0,0 +1,133 @@
+/**
+ * Coalescing of identical concurrent work, with an optional minimum interval
+ * between runs for the same target
+ */
+
+import { cancellationError, throwIfAborted } from './cancellation.js';
+import { delay } from './retry.js';
+
+export interface SingleFlightStats {
+  /** Runs actually started */
+  runs: number;
+  /** Calls that shared a run already in flight */
+  coalesced: number;
+  inFlight: number;
+}
+
+interface Flight<T> {
+  controller: AbortController;
+  promise: Promise<T>;
+  waiters: number;
+}
+
+/** Targets whose last start is remembered before old ones are swept */
+const MAX_TRACKED_TARGETS = 256;
+
+/**
+ * Calls with the same key while a run is in flight wait for that run instead of
+ * starting their own, so they get a result computed after they arrived. A
+ * finished run is forgotten at once; reusing results is up to the caller.
+ *
+ * The run is canceled only once every waiting caller has canceled. With
+ * `minIntervalMs`, runs for the same target start at least that far apart and
+ * later ones wait for their turn.
+ */
+export class SingleFlight<T> {
+  private flights = new Map<string, Flight<T>>();
+  private nextStarts = new Map<string, number>();
+  private runs = 0;
+  private coalesced = 0;
+
+  constructor(private minIntervalMs = 0) {}
+
+  /**
+   * @param key Identifies the work; calls with equal keys share a run
+   * @param target What the work runs against, for `minIntervalMs`; defaults to the key
+   */
+  run(key: string, signal: AbortSignal | undefined, work: (signal: AbortSignal) => Promise<T>, target = key): Promise<T> {
+    throwIfAborted(signal);
+
+    let flight = this.flights.get(key);
+    if (flight) {
+      this.coalesced++;
+    } else {
+      const controller = new AbortController();
+      const created: Flight<T> = { controller, promise: this.start(target, controller.signal, work), waiters: 0 };
+      const settle = () => {
+        if (this.flights.get(key) === created) {
+          this.flights.delete(key);
+        }
+      };
+      created.promise.then(settle, settle);
+      this.flights.set(key, created);
+      this.runs++;
+      flight = created;
+    }
+
+    return this.wait(key, flight, signal);
+  }
+
+  setMinInterval(minIntervalMs: number): void {
+    this.minIntervalMs = Math.max(0, minIntervalMs);
+  }
+
+  getStats(): SingleFlightStats {
+    return { runs: this.runs, coalesced: this.coalesced, inFlight: this.flights.size };
+  }
+
+  private async start(target: string, signal: AbortSignal, work: (signal: AbortSignal) => Promise<T>): Promise<T> {
+    if (this.minIntervalMs > 0) {
+      const now = Date.now();
+      const startAt = Math.max(now, this.nextStarts.get(target) ?? now);
+      this.nextStarts.set(target, startAt + this.minIntervalMs);
+      this.sweep(now);
+      await delay(startAt - now, signal);
+      throwIfAborted(signal);
+    }
+    return work(signal);
+  }
+
+  private sweep(now: number): void {
+    if (this.nextStarts.size <= MAX_TRACKED_TARGETS) {
+      return;
+    }
+    for (const [target, nextStart] of this.nextStarts) {
+      if (nextStart <= now) {
+        this.nextStarts.delete(target);
+      }
+    }
+  }
+
+  private wait(key: string, flight: Flight<T>, signal: AbortSignal | undefined): Promise<T> {
+    flight.waiters++;
+    if (!signal) {
+      return flight.promise;
+    }
+
+    return new Promise<T>((resolve, reject) => {
+      let waiting = true;
+      const leave = () => {
+        waiting = false;
+        signal.removeEventListener('abort', onAbort);
+        flight.waiters--;
+      };
+      // The last caller to leave cancels the run; later callers start afresh
+      const onAbort = () => {
+        leave();
+        if (flight.waiters === 0) {
+          if (this.flights.get(key) === flight) {
+            this.flights.delete(key);
+          }
+          flight.controller.abort(signal.reason);
+        }
+        reject(cancellationError(signal));
+      };
+
+      signal.addEventListener('abort', onAbort, { once: true });
+      flight.promise.then(
+        value => { if (waiting) { leave(); resolve(value); } },
+        (error: unknown) => { if (waiting) { leave(); reject(error); } }
+      );
+    });
+  }
+}
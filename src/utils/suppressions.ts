@@ -0,0 +1,123 @@
+/**
+ * Inline suppression directives read from analyzed source files
+ */
+
+import { resolve } from 'path';
+import type { LanguageError } from '@/types/languages.js';
+
+export const DEFAULT_SUPPRESSION_PREFIX = 'error-debugging';
+
+export interface SuppressionOptions {
+  /** Set to `false` to ignore all directives */
+  enabled?: boolean;
+  /** Directive prefix, `<prefix>:ignore[code]` (default `error-debugging`) */
+  prefix?: string;
+  /** Honor golangci-lint style `//nolint` comments (default `true`) */
+  nolint?: boolean;
+}
+
+export interface Suppression {
+  /** 1-based line the directive applies to */
+  line: number;
+  /** Lower-cased codes to match; empty suppresses everything the directive covers */
+  codes: string[];
+  /** `nolint` only covers linter diagnostics, never compiler errors */
+  kind: 'ignore' | 'nolint';
+}
+
+const COMMENT_START = String.raw`(?:\/\/|#|--|\/\*|<!--)`;
+
+function escapeRegExp(value: string): string {
+  return value.replace(/[.*+?^${}()|[\]\\]/g, '\\$&');
+}
+
+function parseCodes(list: string | undefined): string[] {
+  return (list || '')
+    .split(',')
+    .map(code => code.trim().toLowerCase())
+    .filter(Boolean);
+}
+
+/**
+ * Find suppression directives in a source file.
+ * `<prefix>:ignore` covers its own line, and the next line when the comment stands
+ * alone. `//nolint` covers its own line only, as in golangci-lint.
+ */
+export function parseSuppressions(source: string, options: SuppressionOptions = {}): Suppression[] {
+  if (options.enabled === false) {
+    return [];
+  }
+
+  const prefix = escapeRegExp(options.prefix || DEFAULT_SUPPRESSION_PREFIX);
+  const ignorePattern = new RegExp(`${COMMENT_START}\\s*${prefix}:ignore(?:\\[([^\\]]*)\\])?`);
+  const standalonePattern = new RegExp(`^\\s*${COMMENT_START}`);
+  const nolintPattern = /\/\/\s*nolint(?::([\w,-]+))?\b/;
+  const suppressions: Suppression[] = [];
+  const lines = source.split(/\r?\n/);
+
+  lines.forEach((text, index) => {
+    const line = index + 1;
+
+    const ignore = text.match(ignorePattern);
+    if (ignore) {
+      const codes = parseCodes(ignore[1]);
+      suppressions.push({ line, codes, kind: 'ignore' });
+
+      // A directive on a line of its own applies to the line below
+      if (standalonePattern.test(text)) {
+        suppressions.push({ line: line + 1, codes, kind: 'ignore' });
+      }
+    }
+
+    if (options.nolint !== false) {
+      const nolint = text.match(nolintPattern);
+      if (nolint) {
+        suppressions.push({ line, codes: parseCodes(nolint[1]), kind: 'nolint' });
+      }
+    }
+  });
+
+  return suppressions;
+}
+
+function isSuppressedBy(error: LanguageError, suppression: Suppression): boolean {
+  if (suppression.line !== error.location.line) {
+    return false;
+  }
+  if (suppression.kind === 'nolint' && error.severity === 'error') {
+    return false;
+  }
+  if (suppression.codes.length === 0) {
+    return true;
+  }
+
+  const identifiers = [error.code, error.analyzer]
+    .filter((value): value is string => Boolean(value))
+    .map(value => value.toLowerCase());
+  return suppression.codes.some(code => identifiers.includes(code));
+}
+
+/**
+ * Drop diagnostics silenced by directives in `source`.
+ * When `filePath` is given, only diagnostics located in that file are considered.
+ */
+export function applySuppressions(
+  errors: LanguageError[],
+  source: string,
+  options: SuppressionOptions = {},
+  filePath?: string
+): LanguageError[] {
+  const suppressions = parseSuppressions(source, options);
+  if (suppressions.length === 0) {
+    return errors;
+  }
+
+  const target = filePath ? resolve(filePath) : undefined;
+
+  return errors.filter(error => {
+    if (target && error.location.file && resolve(error.location.file) !== target) {
+      return true;
+    }
+    return !suppressions.some(suppression => isSuppressedBy(error, suppression));
+  });
+}
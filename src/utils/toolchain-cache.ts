@@ -0,0 +1,93 @@
+/**
+ * Process-wide cache of toolchain lookups (`which go`, `go version`, `go env`)
+ */
+
+import { currentCommandPlan } from './command-plan.js';
+
+/** How long a lookup is trusted before it is probed again */
+export const DEFAULT_TOOLCHAIN_CACHE_TTL_MS = 10 * 60 * 1000;
+
+interface CacheEntry {
+  value: Promise<unknown>;
+  expiresAt: number;
+}
+
+export interface ToolchainCacheStats {
+  entries: number;
+  hits: number;
+  misses: number;
+}
+
+/**
+ * Remembers the result of each probe for a TTL. Entries are keyed per PATH: when
+ * PATH changes every entry is dropped, since a different binary may now win.
+ * Failed probes are not cached, so a tool installed later is picked up.
+ */
+export class ToolchainCache {
+  private entries = new Map<string, CacheEntry>();
+  private path: string | undefined;
+  private hits = 0;
+  private misses = 0;
+
+  constructor(private ttlMs = DEFAULT_TOOLCHAIN_CACHE_TTL_MS) {}
+
+  /**
+   * Return the cached result for `key`, running `probe` on a miss. Concurrent
+   * callers of a missing key share one probe. A probe made for a plan, whose
+   * commands never run, is not cached.
+   */
+  async get<T>(key: string, probe: () => Promise<T>): Promise<T> {
+    this.checkPath();
+
+    const now = Date.now();
+    const cached = this.entries.get(key);
+    if (cached && cached.expiresAt > now) {
+      this.hits++;
+      return cached.value as Promise<T>;
+    }
+
+    if (currentCommandPlan()) {
+      return probe();
+    }
+
+    this.misses++;
+    const entry: CacheEntry = { value: probe(), expiresAt: now + this.ttlMs };
+    this.entries.set(key, entry);
+    entry.value.catch(() => {
+      if (this.entries.get(key) === entry) {
+        this.entries.delete(key);
+      }
+    });
+    return entry.value as Promise<T>;
+  }
+
+  /**
+   * Drop one entry, or every entry when no key is given
+   */
+  invalidate(key?: string): void {
+    if (key === undefined) {
+      this.entries.clear();
+    } else {
+      this.entries.delete(key);
+    }
+  }
+
+  setTtl(ttlMs: number): void {
+    this.ttlMs = ttlMs;
+  }
+
+  getStats(): ToolchainCacheStats {
+    return { entries: this.entries.size, hits: this.hits, misses: this.misses };
+  }
+
+  private checkPath(): void {
+    const path = process.env['PATH'];
+    if (path !== this.path) {
+      this.entries.clear();
+      this.path = path;
+    }
+  }
+}
+
+/** Shared by every handler, so handlers created per request reuse earlier probes */
+export const toolchainCache = new ToolchainCache();
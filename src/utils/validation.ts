@@ -19,6 +19,7 @@ const ServerConfigSchema = z.object({
     port: z.number().int().min(1).max(65535).optional(),
     host: z.string().optional(),
     logLevel: z.enum(['debug', 'info', 'warn', 'error']),
+    logFile: z.string().optional(),
     maxConnections: z.number().int().min(1).optional(),
     timeout: z.number().int().min(1000).optional(),
   }),
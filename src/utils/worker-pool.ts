@@ -0,0 +1,44 @@
+/**
+ * Bounded parallelism for per-file work
+ */
+
+import { availableParallelism, cpus } from 'os';
+
+/**
+ * Number of CPUs this process may use, the default pool size
+ */
+export function defaultConcurrency(): number {
+  // availableParallelism arrived in Node 18.14
+  return Math.max(1, typeof availableParallelism === 'function' ? availableParallelism() : cpus().length);
+}
+
+/**
+ * Run `fn` over every item with at most `concurrency` calls in flight. Results are
+ * returned in input order whatever order the calls finish in. The first rejection
+ * rejects the whole run and stops workers from starting further items.
+ */
+export async function mapWithConcurrency<T, R>(
+  items: readonly T[],
+  concurrency: number,
+  fn: (item: T, index: number) => Promise<R>
+): Promise<R[]> {
+  const results = new Array<R>(items.length);
+  const size = Math.max(1, Math.min(Math.floor(concurrency) || 1, items.length));
+  let next = 0;
+  let failed = false;
+
+  const worker = async (): Promise<void> => {
+    while (!failed && next < items.length) {
+      const index = next++;
+      try {
+        results[index] = await fn(items[index]!, index);
+      } catch (error) {
+        failed = true;
+        throw error;
+      }
+    }
+  };
+
+  await Promise.all(Array.from({ length: size }, worker));
+  return results;
+}
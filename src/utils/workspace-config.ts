@@ -0,0 +1,544 @@
+/**
+ * Per-workspace configuration files (`.errordebug.json`, `.errordebug.yaml`), found
+ * by walking up from the analyzed path to the workspace root that contains it
+ */
+
+import { AsyncLocalStorage } from 'async_hooks';
+import { promises as fs } from 'fs';
+import { dirname, join, resolve } from 'path';
+import { z } from 'zod';
+import type { LanguageError } from '@/types/languages.js';
+import { applySeverityRules, compileSeverityRules, type SeverityRule } from './severity-rules.js';
+import { redactDetectorOptions, redactEnv, workspaceDetectorEnv } from './env.js';
+import { stripBom } from './columns.js';
+
+/** Names looked for in every directory, in order; the first one present wins */
+export const WORKSPACE_CONFIG_FILES = ['.errordebug.json', '.errordebugrc', '.errordebug.yaml', '.errordebug.yml'];
+
+/** `source` of the diagnostic reported for a config file that cannot be used */
+export const WORKSPACE_CONFIG_SOURCE = 'config';
+
+/** Named groups a command detector pattern may capture; `message` is required */
+export const COMMAND_PATTERN_GROUPS = ['file', 'line', 'col', 'severity', 'code', 'message'] as const;
+
+/**
+ * Compile a command detector pattern. Python-style `(?P<name>...)` groups are
+ * accepted too, since that is how many tools document their output formats.
+ */
+export function compileCommandPattern(pattern: string): RegExp {
+  const regex = new RegExp(pattern.replace(/\(\?P</g, '(?<'));
+  const groups = Array.from(regex.source.matchAll(/\(\?<([A-Za-z_]\w*)>/g), match => match[1]!);
+  const unknown = groups.filter(group => !(COMMAND_PATTERN_GROUPS as readonly string[]).includes(group));
+  if (unknown.length > 0) {
+    throw new Error(`unknown capture group ${unknown.map(group => `"${group}"`).join(', ')}; use ${COMMAND_PATTERN_GROUPS.join(', ')}`);
+  }
+  if (!groups.includes('message')) {
+    throw new Error('pattern needs a "message" capture group');
+  }
+  return regex;
+}
+
+const CommandDetectorSchema = z.object({
+  /** Program and arguments; `{file}`, `{relativeFile}` and `{dir}` are substituted */
+  command: z.union([z.string().min(1), z.array(z.string()).min(1)]),
+  /** Extensions the command checks, such as `.tf` */
+  extensions: z.array(z.string().min(1)).min(1),
+  /** Matched against each line of stdout and stderr */
+  pattern: z.string().min(1).superRefine((pattern, context) => {
+    try {
+      compileCommandPattern(pattern);
+    } catch (error) {
+      context.addIssue({ code: z.ZodIssueCode.custom, message: error instanceof Error ? error.message : String(error) });
+    }
+  }),
+  /** Severity of matches without a `severity` group (default warning) */
+  defaultSeverity: z.enum(['error', 'warning', 'info', 'hint']).optional(),
+  /** The command is killed after this long (default 30000) */
+  timeoutMs: z.number().int().min(1).optional(),
+  /** Variables added to the command's environment */
+  env: z.record(z.union([z.string(), z.number(), z.boolean()]).transform(String)).optional(),
+}).strict();
+
+export type CommandDetectorConfig = z.infer<typeof CommandDetectorSchema>;
+
+const WorkspaceConfigSchema = z.object({
+  /** Languages analyzed when a tool call does not name one; all when omitted */
+  enabledLanguages: z.array(z.string().min(1)).optional(),
+  /** Default `severity` filter of list-errors */
+  severity: z.enum(['error', 'warning', 'all']).optional(),
+  /** Default `maxResults` of list-errors */
+  maxResults: z.number().int().min(1).optional(),
+  /** Files larger than this many bytes are skipped; 0 for no limit */
+  maxFileSize: z.number().int().min(0).optional(),
+  /** Never download modules or toolchains, nor rewrite go.mod and go.sum */
+  offline: z.boolean().optional(),
+  /** Severity to report diagnostics with, by code, analyzer name or `/message regex/`; `off` drops them */
+  severityOverrides: z.record(z.enum(['error', 'warning', 'info', 'hint', 'off'])).optional(),
+  /** Codes or analyzer names whose diagnostics are dropped */
+  suppressCodes: z.array(z.union([z.string(), z.number()]).transform(String)).optional(),
+  /** Handler options by language, such as `go: { vet: { enabled: false } }` */
+  detectors: z.record(z.record(z.unknown())).optional(),
+  /** Tools run on matching files, keyed by the name their diagnostics are reported under */
+  commands: z.record(CommandDetectorSchema).optional(),
+}).strict();
+
+export type WorkspaceConfig = z.infer<typeof WorkspaceConfigSchema>;
+
+export interface WorkspaceConfigError {
+  message: string;
+  /** 1-based line of the problem, when known */
+  line?: number;
+}
+
+export interface LoadedWorkspaceConfig {
+  /** The config file that applies, when one was found */
+  file?: string;
+  /** Settings from the file; empty when there is none or it could not be used */
+  config: WorkspaceConfig;
+  /** Why the file could not be used. Its settings are ignored entirely. */
+  error?: WorkspaceConfigError;
+}
+
+class ConfigSyntaxError extends Error {
+  constructor(message: string, public readonly line?: number) {
+    super(message);
+    this.name = 'ConfigSyntaxError';
+  }
+}
+
+/**
+ * Loads the config file closest to an analyzed path. Files are re-read only when
+ * their mtime changes.
+ */
+export class WorkspaceConfigLoader {
+  private cache = new Map<string, { mtimeMs: number; loaded: LoadedWorkspaceConfig }>();
+
+  /**
+   * @param targetPath File or directory being analyzed
+   * @param stopDir Highest directory searched, normally the owning workspace root.
+   * The search goes up to the filesystem root when omitted.
+   */
+  async load(targetPath: string, stopDir?: string): Promise<LoadedWorkspaceConfig> {
+    const fullPath = resolve(targetPath);
+    const start = await isDirectory(fullPath) ? fullPath : dirname(fullPath);
+    const found = await findConfigFile(start, stopDir ? resolve(stopDir) : undefined);
+    if (!found) {
+      return { config: {} };
+    }
+
+    const cached = this.cache.get(found.file);
+    if (cached && cached.mtimeMs === found.mtimeMs) {
+      return cached.loaded;
+    }
+
+    const loaded = await readWorkspaceConfig(found.file);
+    this.cache.set(found.file, { mtimeMs: found.mtimeMs, loaded });
+    return loaded;
+  }
+
+  clear(): void {
+    this.cache.clear();
+  }
+}
+
+async function isDirectory(path: string): Promise<boolean> {
+  try {
+    return (await fs.stat(path)).isDirectory();
+  } catch {
+    return false;
+  }
+}
+
+async function findConfigFile(start: string, stopDir?: string): Promise<{ file: string; mtimeMs: number } | undefined> {
+  let directory = start;
+
+  for (;;) {
+    for (const name of WORKSPACE_CONFIG_FILES) {
+      const file = join(directory, name);
+      try {
+        const stats = await fs.stat(file);
+        if (stats.isFile()) {
+          return { file, mtimeMs: stats.mtimeMs };
+        }
+      } catch {
+        // Not present at this level
+      }
+    }
+
+    const parent = dirname(directory);
+    if (directory === stopDir || parent === directory) {
+      return undefined;
+    }
+    directory = parent;
+  }
+}
+
+/**
+ * Read and validate one config file. Problems are returned, never thrown, so a
+ * broken file cannot take analysis down with it.
+ */
+export async function readWorkspaceConfig(file: string): Promise<LoadedWorkspaceConfig> {
+  let text: string;
+  try {
+    text = await fs.readFile(file, 'utf-8');
+  } catch (error) {
+    return { file, config: {}, error: { message: error instanceof Error ? error.message : String(error) } };
+  }
+
+  try {
+    return { file, config: parseWorkspaceConfig(text, /\.ya?ml$/.test(file) ? 'yaml' : 'json') };
+  } catch (error) {
+    const message = error instanceof Error ? error.message : String(error);
+    const line = error instanceof ConfigSyntaxError ? error.line : undefined;
+    return { file, config: {}, error: { message, ...(line !== undefined && { line }) } };
+  }
+}
+
+/**
+ * Parse and validate config file contents, throwing on syntax or schema errors
+ */
+export function parseWorkspaceConfig(text: string, format: 'json' | 'yaml'): WorkspaceConfig {
+  const raw = format === 'yaml' ? parseYaml(text) : parseJson(text);
+  const result = WorkspaceConfigSchema.safeParse(raw ?? {});
+  if (!result.success) {
+    const issues = result.error.errors.map(issue =>
+      issue.path.length > 0 ? `${issue.path.join('.')}: ${issue.message}` : issue.message
+    );
+    throw new Error(issues.join('; '));
+  }
+  // Surface bad regex keys now rather than on every analysis
+  compileSeverityRules(result.data.severityOverrides);
+  return result.data;
+}
+
+/**
+ * Offset of a JSON syntax error. V8 reports positions for most errors, but only a
+ * snippet of the surrounding text for unexpected tokens.
+ */
+function jsonErrorOffset(message: string, text: string): number | undefined {
+  const position = message.match(/position (\d+)/);
+  if (position) {
+    return Number(position[1]);
+  }
+
+  const token = message.match(/^Unexpected token '([\s\S])', (?:\.\.\.)?"([\s\S]*)"(?:\.\.\.)? is not valid JSON$/);
+  if (token) {
+    const at = text.indexOf(token[2]!);
+    return at >= 0 ? at + Math.max(0, token[2]!.indexOf(token[1]!)) : undefined;
+  }
+  return /end of JSON input/.test(message) ? text.length : undefined;
+}
+
+function parseJson(text: string): unknown {
+  const content = stripBom(text);
+  try {
+    return JSON.parse(content);
+  } catch (error) {
+    const message = error instanceof Error ? error.message : String(error);
+    const offset = jsonErrorOffset(message, content);
+    throw new ConfigSyntaxError(message, offset !== undefined ? content.slice(0, offset).split('\n').length : undefined);
+  }
+}
+
+interface YamlLine {
+  indent: number;
+  text: string;
+  line: number;
+}
+
+/**
+ * Parse the block-style YAML config files are written in: nested mappings, `- item`
+ * lists, flow lists such as `[a, b]`, scalars and comments. Anchors, multi-line
+ * strings and lists of mappings are not supported.
+ */
+function parseYaml(text: string): unknown {
+  const lines: YamlLine[] = [];
+
+  stripBom(text).split(/\r?\n/).forEach((raw, index) => {
+    const content = stripYamlComment(raw).trimEnd();
+    if (!content.trim() || content.trim() === '---') {
+      return;
+    }
+    const indent = content.length - content.trimStart().length;
+    if (content.slice(0, indent).includes('\t')) {
+      throw new ConfigSyntaxError('tabs are not allowed in indentation', index + 1);
+    }
+    lines.push({ indent, text: content.trim(), line: index + 1 });
+  });
+
+  if (lines.length === 0) {
+    return {};
+  }
+
+  const [value, next] = parseYamlBlock(lines, 0, lines[0]!.indent);
+  if (next < lines.length) {
+    throw new ConfigSyntaxError('unexpected indentation', lines[next]!.line);
+  }
+  return value;
+}
+
+function stripYamlComment(line: string): string {
+  let quote: string | undefined;
+  for (let i = 0; i < line.length; i++) {
+    const char = line[i]!;
+    if (quote) {
+      if (char === quote) {
+        quote = undefined;
+      }
+    } else if (char === '"' || char === "'") {
+      quote = char;
+    } else if (char === '#' && (i === 0 || /\s/.test(line[i - 1]!))) {
+      return line.slice(0, i);
+    }
+  }
+  return line;
+}
+
+function isListItem(text: string): boolean {
+  return text === '-' || text.startsWith('- ');
+}
+
+function parseYamlBlock(lines: YamlLine[], start: number, indent: number): [unknown, number] {
+  return isListItem(lines[start]!.text)
+    ? parseYamlList(lines, start, indent)
+    : parseYamlMap(lines, start, indent);
+}
+
+function parseYamlList(lines: YamlLine[], start: number, indent: number): [unknown[], number] {
+  const items: unknown[] = [];
+  let index = start;
+
+  while (index < lines.length && lines[index]!.indent === indent && isListItem(lines[index]!.text)) {
+    const current = lines[index]!;
+    const item = current.text.slice(1).trim();
+    if (/^[^"'[{][^:]*:(\s|$)/.test(item)) {
+      throw new ConfigSyntaxError('mappings inside lists are not supported', current.line);
+    }
+
+    if (item) {
+      items.push(parseYamlScalar(item, current.line));
+      index++;
+    } else if (index + 1 < lines.length && lines[index + 1]!.indent > indent) {
+      const [value, next] = parseYamlBlock(lines, index + 1, lines[index + 1]!.indent);
+      items.push(value);
+      index = next;
+    } else {
+      items.push(null);
+      index++;
+    }
+  }
+
+  return [items, index];
+}
+
+function parseYamlMap(lines: YamlLine[], start: number, indent: number): [Record<string, unknown>, number] {
+  const map: Record<string, unknown> = {};
+  let index = start;
+
+  while (index < lines.length && lines[index]!.indent === indent) {
+    const current = lines[index]!;
+    const entry = current.text.match(/^("[^"]*"|'[^']*'|[^:]+?)\s*:(?:\s+(.*))?$/);
+    if (!entry) {
+      throw new ConfigSyntaxError(`expected "key: value", found "${current.text}"`, current.line);
+    }
+
+    const key = entry[1]!.replace(/^(["'])(.*)\1$/, '$2');
+    if (Object.hasOwn(map, key)) {
+      throw new ConfigSyntaxError(`duplicate key "${key}"`, current.line);
+    }
+
+    const rest = entry[2];
+    index++;
+    if (rest !== undefined && rest !== '') {
+      map[key] = parseYamlScalar(rest, current.line);
+      continue;
+    }
+
+    // A nested block is indented further, except that lists may sit at the key's own indent
+    const next = lines[index];
+    if (next && (next.indent > indent || (next.indent === indent && isListItem(next.text)))) {
+      const [value, after] = parseYamlBlock(lines, index, next.indent);
+      map[key] = value;
+      index = after;
+    } else {
+      map[key] = null;
+    }
+  }
+
+  return [map, index];
+}
+
+function parseYamlScalar(text: string, line: number): unknown {
+  if (text.startsWith('[')) {
+    if (!text.endsWith(']')) {
+      throw new ConfigSyntaxError('unterminated flow list', line);
+    }
+    const inner = text.slice(1, -1).trim();
+    return inner ? inner.split(',').map(item => parseYamlScalar(item.trim(), line)) : [];
+  }
+  if (text === '{}') {
+    return {};
+  }
+  if (text.startsWith('{')) {
+    throw new ConfigSyntaxError('flow mappings are not supported; use an indented block', line);
+  }
+  if (text.startsWith('"')) {
+    try {
+      return JSON.parse(text);
+    } catch {
+      throw new ConfigSyntaxError(`invalid quoted string ${text}`, line);
+    }
+  }
+  if (text.startsWith("'")) {
+    if (text.length < 2 || !text.endsWith("'")) {
+      throw new ConfigSyntaxError(`invalid quoted string ${text}`, line);
+    }
+    return text.slice(1, -1).replace(/''/g, "'");
+  }
+  if (/^(?:true|false)$/i.test(text)) {
+    return text.toLowerCase() === 'true';
+  }
+  if (text === '~' || /^null$/i.test(text)) {
+    return null;
+  }
+  if (/^-?\d+(?:\.\d+)?$/.test(text)) {
+    return Number(text);
+  }
+  return text;
+}
+
+/**
+ * Diagnostic pointing at a config file that could not be used
+ */
+export function workspaceConfigDiagnostic(loaded: LoadedWorkspaceConfig): LanguageError | undefined {
+  if (!loaded.error || !loaded.file) {
+    return undefined;
+  }
+
+  return {
+    message: `Invalid workspace config, using defaults: ${loaded.error.message}`,
+    severity: 'error',
+    location: { file: loaded.file, line: loaded.error.line ?? 1, column: 1 },
+    source: WORKSPACE_CONFIG_SOURCE,
+    code: 'invalid-config',
+  };
+}
+
+/**
+ * Whether a config leaves a language enabled for automatic detection
+ */
+export function isLanguageEnabled(config: WorkspaceConfig, language: string): boolean {
+  return !config.enabledLanguages || config.enabledLanguages.includes(language);
+}
+
+/**
+ * Drop diagnostics with suppressed codes and apply severity overrides. Codes and
+ * analyzer names are matched case-insensitively. The file's overrides take
+ * precedence over `defaultRules`, the server-wide ones.
+ */
+export function applyWorkspaceConfig(
+  errors: LanguageError[],
+  config: WorkspaceConfig,
+  defaultRules: SeverityRule[] = []
+): LanguageError[] {
+  const suppressed = new Set((config.suppressCodes || []).map(code => code.toLowerCase()));
+  const kept = suppressed.size === 0 ? errors : errors.filter(error =>
+    ![error.code, error.analyzer].some(value => value !== undefined && suppressed.has(String(value).toLowerCase()))
+  );
+
+  const rules = [...compileSeverityRules(config.severityOverrides), ...defaultRules];
+  return applySeverityRules(kept, rules);
+}
+
+function isPlainObject(value: unknown): value is Record<string, unknown> {
+  return typeof value === 'object' && value !== null && !Array.isArray(value);
+}
+
+/**
+ * Handler options a config file cannot set, each a setting or `setting.field`.
+ * They make analyses execute code of the analyzed repository, or a program it
+ * names, so only the server's own handler options can set them.
+ */
+const SERVER_ONLY_DETECTOR_OPTIONS = ['generate', 'tests.enabled', 'vet.vettool', 'gopls.enabled', 'gopls.path', 'gopls.args'];
+
+/**
+ * Handler options from a config file without those only the server may set,
+ * and with only the variables of `env` that cannot run programs
+ */
+export function withoutServerOnlyOptions(options: Record<string, unknown> | undefined): Record<string, unknown> | undefined {
+  if (!options) {
+    return options;
+  }
+
+  const kept: Record<string, unknown> = { ...options };
+  for (const entry of SERVER_ONLY_DETECTOR_OPTIONS) {
+    const [key, field] = entry.split('.') as [string, string | undefined];
+    const value = kept[key];
+    if (field === undefined) {
+      delete kept[key];
+    } else if (isPlainObject(value) && field in value) {
+      const rest = { ...value };
+      delete rest[field];
+      kept[key] = rest;
+    }
+  }
+  if (kept['env'] !== undefined) {
+    kept['env'] = workspaceDetectorEnv(kept['env']);
+  }
+  return kept;
+}
+
+/**
+ * Layer handler options from a config file over the server's. Object-valued
+ * settings such as `vet` are merged one level deep; everything else is replaced.
+ * Settings only the server may make are ignored.
+ */
+export function mergeDetectorOptions(
+  base: Record<string, unknown>,
+  overrides: Record<string, unknown> | undefined
+): Record<string, unknown> {
+  if (!overrides) {
+    return base;
+  }
+
+  const merged: Record<string, unknown> = { ...base };
+  for (const [key, value] of Object.entries(withoutServerOnlyOptions(overrides)!)) {
+    const current = merged[key];
+    merged[key] = isPlainObject(current) && isPlainObject(value) ? { ...current, ...value } : value;
+  }
+  return merged;
+}
+
+/**
+ * A config with the `env` values of its detectors and commands redacted, for reporting
+ */
+export function redactWorkspaceConfig(config: WorkspaceConfig): WorkspaceConfig {
+  const { detectors, commands } = config;
+  return {
+    ...config,
+    ...(detectors && {
+      detectors: Object.fromEntries(Object.entries(detectors).map(([language, options]) => [language, redactDetectorOptions(options)]))
+    }),
+    ...(commands && {
+      commands: Object.fromEntries(Object.entries(commands).map(([name, command]) =>
+        [name, command.env ? { ...command, env: redactEnv(command.env) } : command]))
+    })
+  };
+}
+
+const detectorOptionsScope = new AsyncLocalStorage<Record<string, unknown>>();
+
+/**
+ * Run a detection with handler options from a workspace config layered over the
+ * handler's own
+ */
+export function runWithDetectorOptions<T>(options: Record<string, unknown> | undefined, fn: () => Promise<T>): Promise<T> {
+  return options ? detectorOptionsScope.run(options, fn) : fn();
+}
+
+/**
+ * Get the options of the enclosing `runWithDetectorOptions` call, if any
+ */
+export function currentDetectorOptions(): Record<string, unknown> | undefined {
+  return detectorOptionsScope.getStore();
+}
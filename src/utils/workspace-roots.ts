@@ -0,0 +1,129 @@
+/**
+ * Workspace roots a server session analyzes, and lookups scoped to them
+ */
+
+import { promises as fs, realpathSync } from 'fs';
+import { dirname, isAbsolute, join, relative, resolve } from 'path';
+import { OutsideWorkspaceError } from './errors.js';
+
+export interface WorkspacePath {
+  /** Root that owns the path */
+  root: string;
+  /** Path relative to `root`, `.` for the root itself */
+  relativePath: string;
+}
+
+interface RootEntry {
+  root: string;
+  /** `root` with symlinks resolved, which is how diagnostics name files */
+  realRoot: string;
+}
+
+function contains(root: string, path: string): boolean {
+  const relativePath = relative(root, path);
+  return !relativePath.startsWith('..') && !isAbsolute(relativePath);
+}
+
+function realRootOf(root: string): string {
+  try {
+    return realpathSync(root);
+  } catch {
+    return root;
+  }
+}
+
+/**
+ * The configured workspace roots. With no roots configured every path is allowed
+ * and none has an owning root.
+ */
+export class WorkspaceRoots {
+  private readonly roots: string[];
+  /** Deepest first, so nested roots win over the roots that contain them */
+  private readonly byDepth: RootEntry[];
+
+  constructor(roots: string[] = []) {
+    this.roots = Array.from(new Set(roots.map(root => resolve(root))));
+    this.byDepth = this.roots
+      .map(root => ({ root, realRoot: realRootOf(root) }))
+      .sort((a, b) => b.root.length - a.root.length);
+  }
+
+  get isConfigured(): boolean {
+    return this.roots.length > 0;
+  }
+
+  list(): string[] {
+    return [...this.roots];
+  }
+
+  /**
+   * Find the root that owns a path
+   */
+  findRoot(path: string): string | undefined {
+    return this.findEntry(resolve(path))?.root;
+  }
+
+  /**
+   * Get the owning root of a path, throwing when roots are configured and none
+   * contains it. Returns undefined when no roots are configured.
+   */
+  requireRoot(path: string): string | undefined {
+    if (!this.isConfigured) {
+      return undefined;
+    }
+
+    const root = this.findRoot(path);
+    if (!root) {
+      throw new OutsideWorkspaceError(resolve(path), this.list());
+    }
+    return root;
+  }
+
+  /**
+   * Describe a path relative to its owning root
+   */
+  locate(path: string): WorkspacePath | undefined {
+    const target = resolve(path);
+    const entry = this.findEntry(target);
+    if (!entry) {
+      return undefined;
+    }
+
+    const base = contains(entry.root, target) ? entry.root : entry.realRoot;
+    return { root: entry.root, relativePath: relative(base, target) || '.' };
+  }
+
+  /**
+   * Match a path against each root as configured and with symlinks resolved
+   */
+  private findEntry(target: string): RootEntry | undefined {
+    return this.byDepth.find(entry => contains(entry.root, target) || contains(entry.realRoot, target));
+  }
+}
+
+/**
+ * Find the nearest of `names` in the file's directory or its parents.
+ * The search does not go above `stopAt` when the file is inside it.
+ */
+export async function findUpwards(filePath: string, names: string[], stopAt?: string): Promise<string | undefined> {
+  const boundary = stopAt && contains(resolve(stopAt), resolve(filePath)) ? resolve(stopAt) : undefined;
+  let directory = dirname(resolve(filePath));
+
+  while (true) {
+    for (const name of names) {
+      const candidate = join(directory, name);
+      try {
+        await fs.access(candidate);
+        return candidate;
+      } catch {
+        // Keep walking up
+      }
+    }
+
+    const parent = dirname(directory);
+    if (parent === directory || directory === boundary) {
+      return undefined;
+    }
+    directory = parent;
+  }
+}
@@ -0,0 +1,5 @@
+﻿package main; var _ = undefinedA
+
+func main() {
+	greeting := "héllo wörld"; _ = greeting + undefinedB
+}
@@ -0,0 +1,14 @@
+package main
+
+// #include <stdlib.h>
+// #include "config.h"
+// static int twice(int x) { return x * 2 }
+// int missing_helper(void);
+import "C"
+
+import "fmt"
+
+func main() {
+	fmt.Println(C.twice(2), C.missing_helper())
+	C.free(nil)
+}
@@ -0,0 +1,60 @@
+// Minimal stand-in for `gopls serve`: answers initialize and shutdown and publishes
+// diagnostics for opened documents in the two rounds gopls uses.
+const diagnostics = require('./gopls_diagnostics.json');
+
+let buffer = Buffer.alloc(0);
+
+function send(message) {
+  const body = JSON.stringify({ jsonrpc: '2.0', ...message });
+  process.stdout.write(`Content-Length: ${Buffer.byteLength(body)}\r\n\r\n${body}`);
+}
+
+function publish(uri, version, items) {
+  send({ method: 'textDocument/publishDiagnostics', params: { uri, version, diagnostics: items } });
+}
+
+function handle(message) {
+  switch (message.method) {
+    case 'initialize':
+      // Servers may ask the client things before answering
+      send({ id: 'config-1', method: 'workspace/configuration', params: { items: [{ section: 'gopls' }] } });
+      send({ id: message.id, result: { capabilities: { textDocumentSync: 1 }, serverInfo: { name: 'fake-gopls', pid: process.pid } } });
+      break;
+    case 'textDocument/didOpen':
+    case 'textDocument/didChange': {
+      const { uri, version } = message.params.textDocument;
+      const text = message.method === 'textDocument/didOpen'
+        ? message.params.textDocument.text
+        : message.params.contentChanges[0].text;
+      if (!text.includes('func')) {
+        publish(uri, version, []);
+        break;
+      }
+      publish(uri, version, diagnostics.slice(0, 1));
+      setTimeout(() => publish(uri, version, diagnostics), 50);
+      break;
+    }
+    case 'shutdown':
+      send({ id: message.id, result: null });
+      break;
+    case 'exit':
+      process.exit(0);
+  }
+}
+
+process.stdin.on('data', chunk => {
+  buffer = Buffer.concat([buffer, chunk]);
+  for (;;) {
+    const headerEnd = buffer.indexOf('\r\n\r\n');
+    if (headerEnd < 0) {
+      return;
+    }
+    const length = Number(buffer.subarray(0, headerEnd).toString().match(/Content-Length: (\d+)/)[1]);
+    if (buffer.length < headerEnd + 4 + length) {
+      return;
+    }
+    const body = buffer.subarray(headerEnd + 4, headerEnd + 4 + length).toString();
+    buffer = buffer.subarray(headerEnd + 4 + length);
+    handle(JSON.parse(body));
+  }
+});
@@ -0,0 +1,16 @@
+package main
+
+import "fmt"
+
+func total(items []int) int {
+	sum := 0
+	for _, v := range items {
+		sum += v
+	return sum
+}
+
+func main() {
+	fmt.Println(total([]int{1, 2, 3})
+	if {
+	}
+}
@@ -0,0 +1,13 @@
+// Fixture: declares x twice in the same block and redeclares a function
+
+package main
+
+var x = 1
+
+var x = "two"
+
+func helper() {}
+
+func helper() {}
+
+func main() {}
@@ -0,0 +1,6 @@
+# Fixture: assigns a str to a variable annotated as int
+
+
+def count() -> int:
+    total: int = "zero"
+    return total
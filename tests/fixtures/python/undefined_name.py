@@ -0,0 +1,6 @@
+# Fixture: references a name that is never defined
+import os
+
+
+def greet(name):
+    return "Hello, " + nme
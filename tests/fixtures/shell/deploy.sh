@@ -0,0 +1,6 @@
+#!/bin/bash
+target=$1
+cp build/* $target
+cd /srv/app
+for f in $(ls *.log); do echo "$f"; done
+echo `date`
@@ -0,0 +1,88 @@
+/**
+ * Tests for the content-hash analysis cache
+ */
+
+import { describe, it, expect, beforeEach } from 'vitest';
+import { AnalysisCache } from '../../../src/languages/analysis-cache.js';
+import type { LanguageError } from '../../../src/types/languages.js';
+
+describe('AnalysisCache', () => {
+  let cache: AnalysisCache;
+  const errors: LanguageError[] = [{
+    message: 'undefined: foo',
+    severity: 'error',
+    location: { file: '/repo/main.go', line: 3, column: 2 },
+    source: 'go'
+  }];
+
+  beforeEach(() => {
+    cache = new AnalysisCache();
+  });
+
+  it('should return cached results for unchanged content and mtime', () => {
+    const hash = AnalysisCache.hashContent('package main');
+    const fingerprint = AnalysisCache.fingerprint({ tags: [] }, {});
+
+    expect(cache.get('/repo/main.go', hash, 100, fingerprint)).toBeUndefined();
+    cache.set('/repo/main.go', hash, 100, fingerprint, errors);
+
+    expect(cache.get('/repo/main.go', hash, 100, fingerprint)).toEqual(errors);
+    expect(cache.getStats()).toEqual({ hits: 1, misses: 1, entries: 1 });
+  });
+
+  it('should miss when the file is touched or its content changes', () => {
+    const hash = AnalysisCache.hashContent('package main');
+    const fingerprint = AnalysisCache.fingerprint({}, {});
+    cache.set('/repo/main.go', hash, 100, fingerprint, errors);
+
+    expect(cache.get('/repo/main.go', hash, 101, fingerprint)).toBeUndefined();
+    expect(cache.get('/repo/main.go', AnalysisCache.hashContent('package other'), 100, fingerprint)).toBeUndefined();
+  });
+
+  it('should miss when options or environment change', () => {
+    const hash = AnalysisCache.hashContent('package main');
+    cache.set('/repo/main.go', hash, 100, AnalysisCache.fingerprint({ tags: [] }, { GOOS: 'linux' }), errors);
+
+    expect(cache.get('/repo/main.go', hash, 100, AnalysisCache.fingerprint({ tags: ['integration'] }, { GOOS: 'linux' }))).toBeUndefined();
+    expect(cache.get('/repo/main.go', hash, 100, AnalysisCache.fingerprint({ tags: [] }, { GOOS: 'darwin' }))).toBeUndefined();
+  });
+
+  it('should not leak mutations into cached entries', () => {
+    const hash = AnalysisCache.hashContent('x');
+    const fingerprint = AnalysisCache.fingerprint({}, {});
+    cache.set('/repo/main.go', hash, 1, fingerprint, errors);
+
+    const first = cache.get('/repo/main.go', hash, 1, fingerprint)!;
+    first[0]!.message = 'mutated';
+
+    expect(cache.get('/repo/main.go', hash, 1, fingerprint)![0]!.message).toBe('undefined: foo');
+  });
+
+  it('should reset counters and entries on clear', () => {
+    const fingerprint = AnalysisCache.fingerprint({}, {});
+    cache.set('/repo/main.go', 'hash', 1, fingerprint, errors);
+    cache.get('/repo/main.go', 'hash', 1, fingerprint);
+
+    cache.clear();
+
+    expect(cache.getStats()).toEqual({ hits: 0, misses: 0, entries: 0 });
+  });
+
+  it('should drop a result computed before its file was invalidated', () => {
+    const fingerprint = AnalysisCache.fingerprint({}, {});
+    const started = cache.generation('/repo/main.go');
+    const other = cache.generation('/repo/other.go');
+
+    cache.invalidate('/repo/main.go');
+
+    expect(cache.set('/repo/main.go', 'hash', 1, fingerprint, errors, started)).toBe(false);
+    expect(cache.set('/repo/other.go', 'hash', 1, fingerprint, errors, other)).toBe(true);
+    expect(cache.get('/repo/main.go', 'hash', 1, fingerprint)).toBeUndefined();
+
+    const restarted = cache.generation('/repo/main.go');
+    cache.clear();
+
+    expect(cache.set('/repo/main.go', 'hash', 1, fingerprint, errors, restarted)).toBe(false);
+    expect(cache.set('/repo/main.go', 'hash', 1, fingerprint, errors, cache.generation('/repo/main.go'))).toBe(true);
+  });
+});
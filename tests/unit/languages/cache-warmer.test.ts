@@ -0,0 +1,152 @@
+/**
+ * Tests for the background cache warm-up
+ */
+
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { EventEmitter } from 'events';
+import { promises as fs } from 'fs';
+import { tmpdir } from 'os';
+import { join } from 'path';
+import { CacheWarmer, WARMUP_ANALYSIS_OPTIONS, findGoModules } from '../../../src/languages/cache-warmer.js';
+import { LanguageHandlerManager } from '../../../src/languages/language-handler-manager.js';
+import { type LanguageError, type LanguageHandler } from '../../../src/types/languages.js';
+
+function goHandler(build: (dir: string, signal?: AbortSignal) => Promise<boolean>): LanguageHandler {
+  return Object.assign(new EventEmitter() as unknown as LanguageHandler, {
+    language: 'go',
+    initialize: vi.fn(async () => {}),
+    dispose: vi.fn(async () => {}),
+    isAvailable: vi.fn(async () => true),
+    isFileSupported: (filePath: string) => filePath.endsWith('.go'),
+    getFileExtensions: () => ['.go'],
+    getConfigFiles: () => ['go.mod'],
+    detectErrors: vi.fn(async (): Promise<LanguageError[]> => []),
+    warmBuildCache: vi.fn(async (dir: string, options?: { signal?: AbortSignal }) => build(dir, options?.signal))
+  });
+}
+
+/** Stands in for a long build: rejects with the signal's reason once it aborts */
+function untilAborted(signal?: AbortSignal): Promise<boolean> {
+  return new Promise((_resolve, reject) => {
+    signal?.addEventListener('abort', () => reject(signal.reason), { once: true });
+  });
+}
+
+describe('CacheWarmer', () => {
+  let root: string;
+
+  beforeEach(async () => {
+    root = await fs.realpath(await fs.mkdtemp(join(tmpdir(), 'cache-warmer-')));
+    for (const [name, content] of Object.entries({
+      'shop/go.mod': 'module example.com/shop\n\ngo 1.22\n',
+      'shop/main.go': 'package main\n\nfunc main() {}\n',
+      'shop/tools/go.mod': 'module example.com/shop/tools\n\ngo 1.22\n',
+      'shop/tools/gen.go': 'package tools\n',
+      'shop/testdata/mod/go.mod': 'module example.com/fixture\n',
+      'shop/vendor/example.com/dep/go.mod': 'module example.com/dep\n',
+      'shop/.cache/go.mod': 'module example.com/cache\n',
+      'shop/_old/go.mod': 'module example.com/old\n',
+      'web/go.mod': 'module example.com/web\n\ngo 1.22\n',
+      'web/server.go': 'package web\n'
+    })) {
+      await fs.mkdir(join(root, name, '..'), { recursive: true });
+      await fs.writeFile(join(root, name), content);
+    }
+  });
+
+  afterEach(async () => {
+    vi.restoreAllMocks();
+    await fs.rm(root, { recursive: true, force: true });
+  });
+
+  it('should find the modules the go command would, nested ones included', async () => {
+    expect(await findGoModules([join(root, 'web'), join(root, 'shop')])).toEqual([
+      join(root, 'shop'),
+      join(root, 'shop/tools'),
+      join(root, 'web')
+    ]);
+  });
+
+  it('should build each module and leave its analyses in the cache', async () => {
+    const manager = new LanguageHandlerManager({ enabledLanguages: [], workspaceRoots: [root] });
+    const go = goHandler(async () => true);
+    await manager.registerHandler(go);
+    const warmer = new CacheWarmer(manager, { enabled: true });
+
+    warmer.start();
+    await vi.waitFor(() => expect(warmer.getStatus().state).toBe('completed'));
+
+    expect(go.warmBuildCache).toHaveBeenCalledTimes(3);
+    expect(warmer.getStatus().modules.map(module => [module.path, module.state, module.built])).toEqual([
+      [join(root, 'shop'), 'warm', true],
+      [join(root, 'shop/tools'), 'warm', true],
+      [join(root, 'web'), 'warm', true]
+    ]);
+    const analyzed = vi.mocked(go.detectErrors).mock.calls.length;
+
+    // What detect-errors and list-errors ask for by default is answered from the cache
+    await manager.analyzeFile(join(root, 'shop/main.go'), 'go', { ...WARMUP_ANALYSIS_OPTIONS });
+    await manager.analyzePath(join(root, 'web'), { ...WARMUP_ANALYSIS_OPTIONS });
+    expect(go.detectErrors).toHaveBeenCalledTimes(analyzed);
+
+    // A file changed since the warm-up is analyzed again
+    await fs.writeFile(join(root, 'web/server.go'), 'package web\n\nvar x = 1\n');
+    await manager.analyzePath(join(root, 'web'), { ...WARMUP_ANALYSIS_OPTIONS });
+    expect(go.detectErrors).toHaveBeenCalledTimes(analyzed + 1);
+  });
+
+  it('should run in the background and stop when canceled', async () => {
+    const manager = new LanguageHandlerManager({ enabledLanguages: [], workspaceRoots: [root] });
+    const go = goHandler((_dir, signal) => untilAborted(signal));
+    await manager.registerHandler(go);
+    const warmer = new CacheWarmer(manager, { enabled: true, paths: [join(root, 'shop')] });
+
+    warmer.start();
+    expect(warmer.getStatus().state).toBe('running');
+    await vi.waitFor(() => expect(go.warmBuildCache).toHaveBeenCalledTimes(1));
+    await warmer.cancel();
+
+    const status = warmer.getStatus();
+    expect(status.state).toBe('canceled');
+    expect(status.completedAt).toBeDefined();
+    expect(status.modules.map(module => module.state)).toEqual(['canceled', 'canceled']);
+    expect(go.warmBuildCache).toHaveBeenCalledTimes(1);
+    expect(go.detectErrors).not.toHaveBeenCalled();
+    expect(manager.getInFlightCount()).toBe(0);
+  });
+
+  it('should carry on with the other modules when one fails', async () => {
+    const manager = new LanguageHandlerManager({ enabledLanguages: [], workspaceRoots: [root] });
+    const go = goHandler(async dir => {
+      if (dir.endsWith('tools')) {
+        throw new Error('go: cannot find main module');
+      }
+      return !dir.endsWith('web');
+    });
+    await manager.registerHandler(go);
+    const warmer = new CacheWarmer(manager, { enabled: true });
+
+    warmer.start();
+    await vi.waitFor(() => expect(warmer.getStatus().state).toBe('completed'));
+
+    expect(warmer.getStatus().modules).toMatchObject([
+      { state: 'warm', built: true, diagnostics: 0 },
+      { state: 'failed', error: 'go: cannot find main module' },
+      // Packages that do not build are still analyzed, so their errors are cached too
+      { state: 'warm', built: false, diagnostics: 0 }
+    ]);
+  });
+
+  it('should do nothing unless enabled, and fail without a Go detector', async () => {
+    const manager = new LanguageHandlerManager({ enabledLanguages: [], workspaceRoots: [root] });
+    const disabled = new CacheWarmer(manager);
+    const enabled = new CacheWarmer(manager, { enabled: true });
+
+    disabled.start();
+    enabled.start();
+    await vi.waitFor(() => expect(enabled.getStatus().completedAt).toBeDefined());
+
+    expect(disabled.getStatus()).toEqual({ state: 'disabled', modules: [] });
+    expect(enabled.getStatus()).toMatchObject({ state: 'failed', error: 'The go detector is not available', modules: [] });
+  });
+});
@@ -0,0 +1,163 @@
+/**
+ * Tests for the clang-based C/C++ handler
+ */
+
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { promises as fs } from 'fs';
+import { tmpdir } from 'os';
+import { join } from 'path';
+import {
+  ClangHandler,
+  extractCompileFlags,
+  parseClangOutput,
+  splitCommandLine
+} from '../../../src/languages/clang-handler.js';
+import { ToolNotFoundError } from '../../../src/utils/errors.js';
+
+describe('ClangHandler', () => {
+  let handler: ClangHandler;
+
+  beforeEach(() => {
+    handler = new ClangHandler();
+  });
+
+  describe('parseClangOutput', () => {
+    it('should parse locations, levels and warning flags', () => {
+      const output = [
+        "src/main.cpp:4:7: warning: unused variable 'x' [-Wunused-variable]",
+        "src/main.cpp:9:3: error: use of undeclared identifier 'foo'",
+        "src/util.h:1:10: fatal error: 'missing.h' file not found",
+        '3 errors generated.'
+      ].join('\n');
+
+      const diagnostics = parseClangOutput(output, '/repo');
+
+      expect(diagnostics).toHaveLength(3);
+      expect(diagnostics[0]).toEqual({
+        file: '/repo/src/main.cpp',
+        line: 4,
+        column: 7,
+        level: 'warning',
+        message: "unused variable 'x'",
+        flag: '-Wunused-variable',
+        notes: []
+      });
+      expect(diagnostics[1]).toMatchObject({ level: 'error', message: "use of undeclared identifier 'foo'" });
+      expect(diagnostics[1]!.flag).toBeUndefined();
+      expect(diagnostics[2]).toMatchObject({ file: '/repo/src/util.h', level: 'fatal error' });
+    });
+
+    it('should attach notes to the preceding diagnostic', () => {
+      const output = [
+        "/repo/a.cpp:5:3: error: no matching function for call to 'f'",
+        "/repo/a.cpp:1:6: note: candidate function not viable: requires 2 arguments, but 1 was provided",
+        "/repo/b.h:2:6: note: candidate function not viable: no known conversion",
+        "/repo/a.cpp:7:1: warning: control reaches end of non-void function [-Werror,-Wreturn-type]"
+      ].join('\n');
+
+      const diagnostics = parseClangOutput(output, '/repo');
+
+      expect(diagnostics).toHaveLength(2);
+      expect(diagnostics[0]!.notes).toEqual([
+        { file: '/repo/a.cpp', line: 1, column: 6, message: 'candidate function not viable: requires 2 arguments, but 1 was provided' },
+        { file: '/repo/b.h', line: 2, column: 6, message: 'candidate function not viable: no known conversion' }
+      ]);
+      expect(diagnostics[1]!.flag).toBe('-Wreturn-type');
+    });
+  });
+
+  describe('compile commands', () => {
+    it('should split quoted command lines', () => {
+      expect(splitCommandLine('clang++ -DNAME="a b" -I\'inc dir\' -c main.cpp')).toEqual([
+        'clang++', '-DNAME=a b', '-Iinc dir', '-c', 'main.cpp'
+      ]);
+    });
+
+    it('should keep include paths, defines and language flags only', () => {
+      const flags = extractCompileFlags({
+        directory: '/repo/build',
+        file: '../src/main.cpp',
+        arguments: [
+          '/usr/bin/clang++', '-I../include', '-isystem', '/opt/include', '-DDEBUG=1',
+          '-std=c++20', '-O2', '-o', 'main.o', '-MD', '-MF', 'main.d', '-c', '../src/main.cpp'
+        ]
+      });
+
+      expect(flags).toEqual(['-I../include', '-isystem', '/opt/include', '-DDEBUG=1', '-std=c++20']);
+    });
+
+    it('should fall back to the command string', () => {
+      expect(extractCompileFlags({
+        directory: '/repo',
+        file: 'main.c',
+        command: 'cc -Iinclude -D FOO -c main.c'
+      })).toEqual(['-Iinclude', '-D', 'FOO']);
+    });
+  });
+
+  describe('detectErrors', () => {
+    let root: string;
+
+    beforeEach(async () => {
+      root = await fs.mkdtemp(join(tmpdir(), 'clang-handler-test-'));
+      (handler as any).clangxxPath = 'clang++';
+    });
+
+    afterEach(async () => {
+      await fs.rm(root, { recursive: true, force: true });
+    });
+
+    it('should run in the compile command directory with its flags', async () => {
+      const file = join(root, 'src', 'main.cpp');
+      const source = 'int main() { return x; }\n';
+      await fs.mkdir(join(root, 'src'));
+      await fs.mkdir(join(root, 'build'));
+      await fs.writeFile(file, source);
+      await fs.writeFile(join(root, 'build', 'compile_commands.json'), JSON.stringify([
+        { directory: join(root, 'build'), file: '../src/main.cpp', arguments: ['clang++', '-I../include', '-c', '../src/main.cpp'] }
+      ]));
+
+      const runCommand = vi.spyOn(handler as any, 'runCommand').mockResolvedValue({
+        stdout: '',
+        stderr: [
+          "../src/main.cpp:1:21: error: use of undeclared identifier 'x'",
+          "../include/defs.h:3:5: note: 'y' declared here"
+        ].join('\n'),
+        exitCode: 1
+      });
+
+      const errors = await handler.detectErrors(source, { filePath: file });
+
+      expect(runCommand).toHaveBeenCalledWith('clang++', expect.arrayContaining(['-fsyntax-only', '-I../include', file]), { cwd: join(root, 'build') });
+      expect(errors).toHaveLength(1);
+      expect(errors[0]).toMatchObject({ location: { file, line: 1, column: 21 }, severity: 'error' });
+      expect(errors[0]!.relatedInformation).toEqual([
+        { location: { file: join(root, 'include', 'defs.h'), line: 3, column: 5 }, message: "note: 'y' declared here" }
+      ]);
+    });
+
+    it('should fall back to a standalone syntax check without a database entry', async () => {
+      const file = join(root, 'main.cpp');
+      const source = 'int main() { return 0 }\n';
+
+      const runCommand = vi.spyOn(handler as any, 'runCommand').mockImplementation(async (...args: unknown[]) => {
+        const argv = args[1] as string[];
+        return { stdout: '', stderr: `${argv[argv.length - 1]}:1:22: error: expected ';' after return statement`, exitCode: 1 };
+      });
+
+      const errors = await handler.detectErrors(source, { filePath: file });
+
+      expect(runCommand.mock.calls[0]![1]).toEqual(expect.arrayContaining(['-fsyntax-only', '-I', root]));
+      expect(errors).toHaveLength(1);
+      expect(errors[0]).toMatchObject({ message: "expected ';' after return statement", location: { file, line: 1, column: 22 } });
+    });
+  });
+
+  describe('missing toolchain', () => {
+    it('should fail initialization with a typed error when clang is not on PATH', async () => {
+      vi.spyOn(handler as any, 'findExecutable').mockResolvedValue(undefined);
+
+      await expect(handler.initialize()).rejects.toBeInstanceOf(ToolNotFoundError);
+    });
+  });
+});
@@ -0,0 +1,200 @@
+/**
+ * Tests for detectors defined by workspace config commands
+ */
+
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { promises as fs } from 'fs';
+import { tmpdir } from 'os';
+import { join } from 'path';
+import {
+  GenericCommandDetector,
+  parseCommandSeverity,
+  splitCommandTemplate
+} from '../../../src/languages/generic-command-detector.js';
+import { LanguageHandlerManager } from '../../../src/languages/language-handler-manager.js';
+import { parseWorkspaceConfig, runWithDetectorOptions } from '../../../src/utils/workspace-config.js';
+
+const PATTERN = '^(?<file>[^:]+):(?<line>\\d+):(?<col>\\d+): (?<severity>\\w+) \\[(?<code>[\\w-]+)\\] (?<message>.+)$';
+
+describe('GenericCommandDetector', () => {
+  let directory: string;
+  let file: string;
+
+  beforeEach(async () => {
+    directory = await fs.realpath(await fs.mkdtemp(join(tmpdir(), 'command-detector-test-')));
+    await fs.mkdir(join(directory, 'policy'));
+    file = join(directory, 'policy', 'main.rego');
+    await fs.writeFile(file, 'package main\n');
+  });
+
+  afterEach(async () => {
+    await fs.rm(directory, { recursive: true, force: true });
+  });
+
+  it('should parse matching lines of the command output for the analyzed file', async () => {
+    const script = [
+      'echo "{relativeFile}:2:5: error [no-root] runs as root"',
+      'echo "policy/other.rego:1:1: error [no-root] not this file"',
+      'echo "{relativeFile}:7:1: style [naming] use snake_case" >&2',
+      'echo "summary: 2 problems"',
+      'exit 1'
+    ].join('; ');
+    const detector = new GenericCommandDetector('policylint', {
+      command: ['sh', '-c', script],
+      extensions: ['rego'],
+      pattern: PATTERN
+    }, directory);
+
+    expect(detector.isFileSupported(file)).toBe(true);
+    const errors = await detector.detectErrors('package main\n', { filePath: file });
+
+    expect(errors).toEqual([
+      {
+        message: 'runs as root',
+        severity: 'error',
+        location: { file, line: 2, column: 5 },
+        source: 'policylint',
+        code: 'no-root',
+        relatedInformation: []
+      },
+      expect.objectContaining({ message: 'use snake_case', severity: 'hint', code: 'naming', location: { file, line: 7, column: 1 } })
+    ]);
+  });
+
+  it('should report a failing command without matches as a toolchain error', async () => {
+    const detector = new GenericCommandDetector('policylint', {
+      command: 'sh -c "echo \'config not found\' >&2; exit 2"',
+      extensions: ['.rego'],
+      pattern: PATTERN
+    }, directory);
+
+    const errors = await detector.detectErrors('', { filePath: file });
+
+    expect(errors).toEqual([expect.objectContaining({
+      source: 'toolchain',
+      code: 'toolchain',
+      message: 'policylint failed: config not found'
+    })]);
+  });
+
+  it('should pass the env of its definition to the command without logging secrets', async () => {
+    const detector = new GenericCommandDetector('policylint', {
+      command: ['sh', '-c', 'echo "{relativeFile}:1:1: error [env] $POLICY_MODE ${#POLICY_TOKEN}"'],
+      extensions: ['.rego'],
+      pattern: PATTERN,
+      env: { POLICY_MODE: 'strict', POLICY_TOKEN: 'hunter22' }
+    }, directory);
+    const debug = vi.spyOn((detector as any).logger, 'debug');
+
+    const errors = await detector.detectErrors('', { filePath: file });
+
+    expect(errors.map(error => error.message)).toEqual(['strict 8']);
+    expect(debug).toHaveBeenCalledWith('Running sh', expect.objectContaining({
+      env: { POLICY_MODE: 'strict', POLICY_TOKEN: '[redacted]' }
+    }));
+    expect(JSON.stringify(debug.mock.calls)).not.toContain('hunter22');
+  });
+
+  it('should layer env from detector options over the inherited environment', async () => {
+    process.env['POLICY_INHERITED'] = 'inherited';
+    const detector = new GenericCommandDetector('policylint', {
+      command: ['sh', '-c', 'echo "{relativeFile}:1:1: error [env] $POLICY_INHERITED $GOFLAGS"'],
+      extensions: ['.rego'],
+      pattern: PATTERN
+    }, directory);
+
+    try {
+      const errors = await runWithDetectorOptions({ env: { GOFLAGS: '-tags=lenient' } }, () =>
+        detector.detectErrors('', { filePath: file })
+      );
+
+      expect(errors.map(error => error.message)).toEqual(['inherited -tags=lenient']);
+    } finally {
+      delete process.env['POLICY_INHERITED'];
+    }
+  });
+
+  it('should split command strings and map severity words', () => {
+    expect(splitCommandTemplate(`lint --rule "a b" 'c d' {file}`)).toEqual(['lint', '--rule', 'a b', 'c d', '{file}']);
+    expect(parseCommandSeverity('FATAL', 'warning')).toBe('error');
+    expect(parseCommandSeverity('note', 'warning')).toBe('info');
+    expect(parseCommandSeverity('unknown', 'warning')).toBe('warning');
+    expect(parseCommandSeverity(undefined, 'error')).toBe('error');
+  });
+
+  it('should validate patterns in the workspace config', () => {
+    const config = (pattern: string) => JSON.stringify({ commands: { lint: { command: 'lint', extensions: ['.x'], pattern } } });
+
+    expect(parseWorkspaceConfig(config('^(?P<line>\\d+): (?P<message>.+)$'), 'json').commands?.['lint']?.pattern).toBeDefined();
+    expect(() => parseWorkspaceConfig(config('^(?<line>\\d+): .+$'), 'json')).toThrow('pattern needs a "message" capture group');
+    expect(() => parseWorkspaceConfig(config('^(?<lineno>\\d+): (?<message>.+)$'), 'json')).toThrow('unknown capture group "lineno"');
+    expect(() => parseWorkspaceConfig(config('(?<message>'), 'json')).toThrow();
+  });
+
+  it('should run commands from the workspace config for matching files in a directory', async () => {
+    await fs.writeFile(join(directory, '.errordebug.yaml'), [
+      'commands:',
+      '  policylint:',
+      `    command: [sh, -c, 'echo "{relativeFile}:3:1: warning [deny] denied"; exit 1']`,
+      '    extensions: [.rego]',
+      `    pattern: '${PATTERN}'`,
+      '    timeoutMs: 5000',
+      ''
+    ].join('\n'));
+    const manager = new LanguageHandlerManager({ enabledLanguages: [], workspaceRoots: [directory], commands: { enabled: true } });
+
+    try {
+      const errors = await manager.analyzePath(directory);
+
+      expect(errors).toEqual([expect.objectContaining({
+        message: 'denied',
+        severity: 'warning',
+        source: 'policylint',
+        code: 'deny',
+        location: expect.objectContaining({ file, line: 3, column: 1 })
+      })]);
+    } finally {
+      await manager.dispose();
+    }
+  });
+
+  it('should only run workspace commands the server config lets run', async () => {
+    await fs.writeFile(join(directory, 'x.sh'), '#!/bin/sh\ntouch "$(dirname "$0")/ran"\n', { mode: 0o755 });
+    await fs.writeFile(join(directory, '.errordebug.yaml'), [
+      'commands:',
+      '  script:',
+      '    command: ./x.sh',
+      '    extensions: [.rego]',
+      `    pattern: '${PATTERN}'`,
+      '  policylint:',
+      `    command: [sh, -c, 'echo "{relativeFile}:3:1: warning [deny] denied"']`,
+      '    extensions: [.rego]',
+      `    pattern: '${PATTERN}'`,
+      ''
+    ].join('\n'));
+    const off = new LanguageHandlerManager({ enabledLanguages: [], workspaceRoots: [directory] });
+    const allowed = new LanguageHandlerManager({
+      enabledLanguages: [],
+      workspaceRoots: [directory],
+      commands: { enabled: true, allow: ['sh'] }
+    });
+
+    try {
+      await expect(off.analyzePath(directory)).rejects.toThrow('No supported files found');
+      expect(off.getWorkspaceCommands(await off.getWorkspaceConfig(directory))).toEqual([
+        { name: 'script', program: './x.sh', enabled: false, reason: 'Workspace commands are disabled in the server config' },
+        { name: 'policylint', program: 'sh', enabled: false, reason: 'Workspace commands are disabled in the server config' }
+      ]);
+
+      expect((await allowed.analyzePath(directory)).map(error => error.source)).toEqual(['policylint']);
+      expect(allowed.getWorkspaceCommands(await allowed.getWorkspaceConfig(directory))).toEqual([
+        { name: 'script', program: './x.sh', enabled: false, reason: "./x.sh is not on the server's allow list" },
+        { name: 'policylint', program: 'sh', enabled: true }
+      ]);
+      await expect(fs.access(join(directory, 'ran'))).rejects.toThrow();
+    } finally {
+      await off.dispose();
+      await allowed.dispose();
+    }
+  });
+});
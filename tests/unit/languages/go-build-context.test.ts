@@ -0,0 +1,77 @@
+/**
+ * Tests for Go build constraint evaluation
+ */
+
+import { describe, it, expect } from 'vitest';
+import {
+  checkBuildConstraints,
+  evaluateBuildExpression,
+  matchesFileNameConstraints,
+  type ResolvedGoBuildContext
+} from '../../../src/languages/go-build-context.js';
+
+describe('go-build-context', () => {
+  const linux: ResolvedGoBuildContext = { tags: [], goos: 'linux', goarch: 'amd64', cgoEnabled: true };
+  const windows: ResolvedGoBuildContext = { tags: [], goos: 'windows', goarch: 'arm64', cgoEnabled: false };
+
+  describe('evaluateBuildExpression', () => {
+    it('should evaluate operators and grouping', () => {
+      expect(evaluateBuildExpression('linux && (amd64 || arm64)', linux)).toBe(true);
+      expect(evaluateBuildExpression('linux && !cgo', linux)).toBe(false);
+      expect(evaluateBuildExpression('windows || darwin', linux)).toBe(false);
+      expect(evaluateBuildExpression('unix', linux)).toBe(true);
+      expect(evaluateBuildExpression('unix', windows)).toBe(false);
+    });
+
+    it('should honour custom tags and release tags', () => {
+      expect(evaluateBuildExpression('integration', linux)).toBe(false);
+      expect(evaluateBuildExpression('integration', { ...linux, tags: ['integration'] })).toBe(true);
+      expect(evaluateBuildExpression('go1.21', linux)).toBe(true);
+    });
+
+    it('should throw on malformed expressions', () => {
+      expect(() => evaluateBuildExpression('linux &&', linux)).toThrow('Invalid build constraint');
+      expect(() => evaluateBuildExpression('(linux', linux)).toThrow('Invalid build constraint');
+    });
+  });
+
+  describe('matchesFileNameConstraints', () => {
+    it('should apply GOOS and GOARCH file name suffixes', () => {
+      expect(matchesFileNameConstraints('/src/poll_windows.go', linux)).toBe(false);
+      expect(matchesFileNameConstraints('/src/poll_windows.go', windows)).toBe(true);
+      expect(matchesFileNameConstraints('/src/asm_linux_amd64.go', linux)).toBe(true);
+      expect(matchesFileNameConstraints('/src/asm_linux_arm64_test.go', linux)).toBe(false);
+      expect(matchesFileNameConstraints('/src/my_helper.go', linux)).toBe(true);
+    });
+  });
+
+  describe('checkBuildConstraints', () => {
+    it('should report excluded //go:build files with a reason', () => {
+      const source = '//go:build windows\n\npackage main\n';
+      const result = checkBuildConstraints(source, 'main.go', linux);
+
+      expect(result.satisfied).toBe(false);
+      expect(result.constraint).toBe('windows');
+      expect(result.reason).toContain('GOOS=linux');
+    });
+
+    it('should evaluate legacy +build lines', () => {
+      const source = '// +build linux,!cgo darwin\n\npackage main\n';
+
+      expect(checkBuildConstraints(source, 'main.go', linux).satisfied).toBe(false);
+      expect(checkBuildConstraints(source, 'main.go', { ...linux, cgoEnabled: false }).satisfied).toBe(true);
+    });
+
+    it('should ignore constraint-like comments after the package clause', () => {
+      const source = 'package main\n\n//go:build windows\n';
+
+      expect(checkBuildConstraints(source, 'main.go', linux).satisfied).toBe(true);
+    });
+
+    it('should leave malformed constraints to the compiler', () => {
+      const source = '//go:build linux &&\n\npackage main\n';
+
+      expect(checkBuildConstraints(source, 'main.go', linux).satisfied).toBe(true);
+    });
+  });
+});
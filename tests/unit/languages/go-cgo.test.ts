@@ -0,0 +1,103 @@
+/**
+ * Tests for cgo C compiler and linker diagnostics
+ */
+
+import { describe, it, expect } from 'vitest';
+import { readFileSync } from 'fs';
+import { join } from 'path';
+import { locateCgoImport, mapPreambleColumn, parseCgoErrors } from '../../../src/languages/go-cgo.js';
+import { GoHandler } from '../../../src/languages/go-handler.js';
+
+const fixturesDir = join(__dirname, '../../fixtures/go');
+const SOURCE = readFileSync(join(fixturesDir, 'cgo_main.go'), 'utf-8');
+const STDERR = readFileSync(join(fixturesDir, 'cgo_errors.stderr'), 'utf-8');
+
+const options = { buildFile: 'main.go', filePath: '/repo/main.go', buildDir: '/repo', source: SOURCE };
+
+describe('cgo positions', () => {
+  it('should find import "C" and shift columns past the comment markers', () => {
+    expect(locateCgoImport(SOURCE)).toEqual({ line: 7, column: 8, endColumn: 11 });
+    expect(locateCgoImport('package main\n\nimport "fmt"\n')).toBeUndefined();
+
+    expect(mapPreambleColumn('// static int twice(int x) { return x * 2 }', 40)).toBe(42);
+    expect(mapPreambleColumn('\t/* #include <stdio.h>', 1)).toBe(4);
+    expect(mapPreambleColumn('static int n;', 8)).toBe(8);
+  });
+});
+
+describe('parseCgoErrors', () => {
+  it('should map C compiler errors from the fixture back to the Go source', () => {
+    const { errors, remaining } = parseCgoErrors(STDERR, options);
+
+    expect(errors.map(error => [error.location.line, error.location.column, error.severity, error.code, error.message])).toEqual([
+      [4, 4, 'error', 'cgo-compile', "./config.h:3:1: unknown type name 'uint'"],
+      [5, 42, 'error', 'cgo-compile', "expected ';' before '}' token"],
+      [12, 14, 'warning', '-Wunused-variable', "unused variable '_cgo_a' (in code cgo generated for C.twice)"]
+    ]);
+    expect(errors[1]!.relatedInformation).toEqual([{
+      location: { file: '/repo/main.go', line: 6, column: 5 },
+      message: "previous declaration of 'missing_helper' with type 'int(void)'"
+    }]);
+    expect(errors.every(error => error.analyzer === 'cgo' && error.location.file === '/repo/main.go')).toBe(true);
+
+    // Go errors are left for the Go parser, without the C source context
+    expect(remaining.split('\n').filter(Boolean)).toEqual([
+      '# example.com/cgodemo',
+      './main.go:13:2: could not determine kind of name for C.free'
+    ]);
+  });
+
+  it('should handle clang output and report link failures at the C name they concern', () => {
+    const clang = [
+      './main.go:5:40: error: expected \';\' after return statement',
+      ' static int twice(int x) { return x * 2 }',
+      '                                       ^',
+      '1 error generated.'
+    ].join('\n');
+    expect(parseCgoErrors(clang, options).errors.map(error => error.location.column)).toEqual([42]);
+    expect(parseCgoErrors(clang, options).remaining).toBe('');
+
+    const link = [
+      '# example.com/cgodemo',
+      '/usr/local/go/pkg/tool/linux_amd64/link: running gcc failed: exit status 1',
+      "/usr/bin/ld: /tmp/go-link-3727281761/000001.o: in function `_cgo_a1b2c3d4e5f6_Cfunc_missing_helper':",
+      "/tmp/go-build/cgo-gcc-prolog:52: undefined reference to `missing_helper'",
+      '/usr/bin/ld: cannot find -lsqlite3: No such file or directory',
+      'collect2: error: ld returned 1 exit status'
+    ].join('\n');
+    const { errors, remaining } = parseCgoErrors(link, options);
+
+    expect(errors.map(error => [error.location.line, error.location.column, error.message])).toEqual([
+      [12, 26, 'undefined reference to missing_helper'],
+      [7, 8, 'cannot find -lsqlite3: No such file or directory']
+    ]);
+    expect(errors[0]!.suggestedFix).toContain('#cgo LDFLAGS');
+    expect(remaining).toBe('# example.com/cgodemo');
+  });
+
+  it('should report a missing C compiler at import "C"', () => {
+    const { errors } = parseCgoErrors('# runtime/cgo\ncgo: C compiler "gcc" not found: exec: "gcc": executable file not found in $PATH', options);
+
+    expect(errors).toMatchObject([{ location: { line: 7, column: 8 }, code: 'cgo-toolchain' }]);
+  });
+
+  it('should drop generated-code problems for files that do not use cgo', () => {
+    const output = "cgo-gcc-prolog: In function '_cgo_1_Cfunc_f':\ncgo-gcc-prolog:3:1: error: bad";
+
+    expect(parseCgoErrors(output, { ...options, source: 'package main\n' }).errors).toEqual([]);
+  });
+});
+
+describe('GoHandler cgo errors', () => {
+  it('should combine cgo diagnostics with Go compiler errors', () => {
+    const errors = (new GoHandler() as any).parseGoErrors(STDERR, '/repo/main.go', 'main.go', '/repo', SOURCE);
+
+    expect(errors.map((error: any) => [error.location.line, error.analyzer])).toEqual([
+      [4, 'cgo'],
+      [5, 'cgo'],
+      [12, 'cgo'],
+      [13, undefined]
+    ]);
+    expect(errors[3].message).toBe('could not determine kind of name for C.free');
+  });
+});
@@ -0,0 +1,191 @@
+/**
+ * Tests for the go generate staleness check
+ */
+
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { promises as fs } from 'fs';
+import { tmpdir } from 'os';
+import { join } from 'path';
+import {
+  GO_GENERATE_ANALYZER,
+  GoGenerateChecker,
+  goGenerateRunPattern,
+  isAllowedGenerator,
+  parseGoGenerateDirectives
+} from '../../../src/languages/go-generate.js';
+import { GoHandler } from '../../../src/languages/go-handler.js';
+import { runWithDetectorOptions } from '../../../src/utils/workspace-config.js';
+
+const COLORS_GO = `package colors
+
+//go:generate -command enum go run example.com/tools/enum
+//go:generate enum -type=Color -output color_enum.go
+//go:generate stringer -type=Color
+//go:generate sh -c "echo \\"const Name = 1\\" > name.go"
+  //go:generate stringer -type=Ignored
+//go:generatestringer
+//go:generate protoc --go_out=. colors.proto
+
+type Color int
+`;
+
+describe('parseGoGenerateDirectives', () => {
+  it('should split directives like go generate and expand shorthands', () => {
+    expect(parseGoGenerateDirectives(COLORS_GO).map(directive => [directive.line, directive.words])).toEqual([
+      [4, ['go', 'run', 'example.com/tools/enum', '-type=Color', '-output', 'color_enum.go']],
+      [5, ['stringer', '-type=Color']],
+      [6, ['sh', '-c', 'echo "const Name = 1" > name.go']],
+      [9, ['protoc', '--go_out=.', 'colors.proto']]
+    ]);
+  });
+
+  it('should select a directive and the shorthands it may use with -run', () => {
+    const [enumDirective] = parseGoGenerateDirectives(COLORS_GO);
+
+    expect(goGenerateRunPattern(enumDirective!)).toBe(
+      '^(?://go:generate -command enum go run example\\.com/tools/enum|//go:generate enum -type=Color -output color_enum\\.go)$'
+    );
+  });
+
+  it('should only allow generators whose leading words are listed', () => {
+    const [enumDirective, stringer, sh] = parseGoGenerateDirectives(COLORS_GO);
+    const allow = ['stringer', 'go run example.com/tools/enum'];
+
+    expect(isAllowedGenerator(enumDirective!, allow)).toBe(true);
+    expect(isAllowedGenerator(stringer!, allow)).toBe(true);
+    expect(isAllowedGenerator(sh!, allow)).toBe(false);
+    expect(isAllowedGenerator(stringer!, ['string'])).toBe(false);
+    expect(isAllowedGenerator(stringer!, ['stringer -type=Other'])).toBe(false);
+    expect(isAllowedGenerator(stringer!, [' '])).toBe(false);
+  });
+});
+
+describe('GoGenerateChecker', () => {
+  let moduleRoot: string;
+  let file: string;
+  const source = [
+    'package colors',
+    '',
+    '//go:generate stringer -type=Color',
+    '//go:generate enum -type=Color',
+    '//go:generate mockgen -source=colors.go',
+    '//go:generate broken',
+    '//go:generate rm -rf /',
+    '',
+    'type Color int',
+    ''
+  ].join('\n');
+
+  beforeEach(async () => {
+    moduleRoot = await fs.realpath(await fs.mkdtemp(join(tmpdir(), 'go-generate-checker-')));
+    file = join(moduleRoot, 'colors/colors.go');
+    await fs.mkdir(join(moduleRoot, 'colors'));
+    await fs.mkdir(join(moduleRoot, '.git'));
+    await fs.writeFile(join(moduleRoot, 'go.mod'), 'module example.com/shop\n\ngo 1.22\n');
+    await fs.writeFile(file, source);
+    await fs.writeFile(join(moduleRoot, 'colors/color_string.go'), 'package colors\n\nfunc (Color) String() string { return "red" }\n');
+    await fs.writeFile(join(moduleRoot, 'colors/color_enum.go'), 'package colors\n\nconst Colors = 2\n');
+  });
+
+  afterEach(async () => {
+    vi.restoreAllMocks();
+    await fs.rm(moduleRoot, { recursive: true, force: true });
+  });
+
+  // Stands in for go generate: writes what each generator would, in the copy of the module
+  const fakeGenerators = () => vi.fn(async (args: string[], cwd: string) => {
+    const pattern = new RegExp(args[1]!);
+    const ran = (line: string) => pattern.test(`//go:generate ${line}`);
+    if (ran('stringer -type=Color')) {
+      await fs.writeFile(join(cwd, 'color_string.go'), 'package colors\n\nfunc (Color) String() string { return "blue" }\n');
+    } else if (ran('enum -type=Color')) {
+      await fs.writeFile(join(cwd, 'color_enum.go'), 'package colors\n\nconst Colors = 2\n');
+    } else if (ran('mockgen -source=colors.go')) {
+      await fs.mkdir(join(cwd, '../mocks'));
+      await fs.writeFile(join(cwd, '../mocks/colors.go'), 'package mocks\n');
+    } else if (ran('broken')) {
+      await fs.writeFile(join(cwd, 'partial.go'), 'package colors\n');
+      return { stdout: '', stderr: 'colors.go:6: running "broken": exec: "broken": executable file not found in $PATH\n', exitCode: 1 };
+    }
+    return { stdout: '', stderr: '', exitCode: 0 };
+  });
+
+  it('should tell stale and missing output apart from failed generators', async () => {
+    const generators = fakeGenerators();
+    const errors = await new GoGenerateChecker(generators).check(file, source, {
+      allow: ['stringer', 'enum', 'mockgen', 'broken']
+    });
+
+    expect(errors.map(error => [error.location.line, error.code, error.message])).toEqual([
+      [3, 'stale-generated-file', 'colors/color_string.go is out of date with "stringer -type=Color"'],
+      [5, 'stale-generated-file', 'mocks/colors.go is missing: "mockgen -source=colors.go" generates it'],
+      [6, 'generator-failed', 'go generate failed, so the output of "broken" was not checked: colors.go:6: running "broken": exec: "broken": executable file not found in $PATH']
+    ]);
+    expect(errors[0]).toMatchObject({
+      severity: 'warning',
+      source: 'go',
+      analyzer: GO_GENERATE_ANALYZER,
+      location: { file, column: 1, endLine: 3, endColumn: 35 },
+      suggestedFix: 'Re-run go generate ./colors/colors.go and commit the regenerated files',
+      relatedInformation: [{ location: { file: join(moduleRoot, 'colors/color_string.go'), line: 3, column: 1 } }]
+    });
+    expect(errors[2]!.suggestedFix).toBeUndefined();
+  });
+
+  it('should run only allowed directives, in a copy of the module', async () => {
+    const generators = fakeGenerators();
+    let copied: string[] = [];
+    await new GoGenerateChecker(async (args, cwd) => {
+      copied = (await fs.readdir(join(cwd, '..'))).sort();
+      return generators(args, cwd);
+    }).check(file, source, { allow: ['stringer', 'mockgen'] });
+
+    expect(generators).toHaveBeenCalledTimes(2);
+    const [args, cwd] = generators.mock.calls[0]!;
+    expect(args).toEqual(['-run', '^(?://go:generate stringer -type=Color)$', 'colors.go']);
+    expect(cwd.startsWith(moduleRoot)).toBe(false);
+    expect(copied).toEqual(['colors', 'go.mod']);
+    await expect(fs.access(cwd)).rejects.toThrow();
+    expect(await fs.readFile(join(moduleRoot, 'colors/color_string.go'), 'utf-8')).toContain('"red"');
+    await expect(fs.access(join(moduleRoot, 'mocks'))).rejects.toThrow();
+  });
+
+  it('should run nothing without an allowlist or outside a module', async () => {
+    const generators = fakeGenerators();
+    const checker = new GoGenerateChecker(generators);
+
+    expect(await checker.check(file, source)).toEqual([]);
+    await fs.rm(join(moduleRoot, 'go.mod'));
+    expect(await checker.check(file, source, { allow: ['stringer'] }, moduleRoot)).toEqual([]);
+    expect(generators).not.toHaveBeenCalled();
+  });
+
+  it('should only check generated files from the Go handler when enabled', async () => {
+    const generators = fakeGenerators();
+    for (const enabled of [false, true]) {
+      const handler = new GoHandler({ generate: { enabled, allow: ['stringer'] }, vet: { enabled: false } });
+      (handler as any).generateChecker = new GoGenerateChecker(generators);
+      vi.spyOn(handler as any, 'validateSyntax').mockResolvedValue([]);
+
+      const errors = await handler.detectErrors(source, { filePath: file });
+
+      expect(errors.map(error => error.code)).toEqual(enabled ? ['stale-generated-file'] : []);
+    }
+    expect(generators).toHaveBeenCalledTimes(1);
+  });
+
+  it('should not let a workspace config enable the check', async () => {
+    const generators = fakeGenerators();
+    const handler = new GoHandler({ vet: { enabled: false } });
+    (handler as any).generateChecker = new GoGenerateChecker(generators);
+    vi.spyOn(handler as any, 'validateSyntax').mockResolvedValue([]);
+
+    const errors = await runWithDetectorOptions(
+      { generate: { enabled: true, allow: ['stringer', 'sh'] } },
+      () => handler.detectErrors(source, { filePath: file })
+    );
+
+    expect(errors).toEqual([]);
+    expect(generators).not.toHaveBeenCalled();
+  });
+});
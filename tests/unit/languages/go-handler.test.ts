@@ -0,0 +1,308 @@
+/**
+ * Tests for Go language handler
+ */
+
+import { describe, it, expect, beforeEach, vi } from 'vitest';
+import { readFileSync } from 'fs';
+import { join } from 'path';
+import { GoHandler, isTransientGoFailure } from '../../../src/languages/go-handler.js';
+import { runWithDetectorOptions } from '../../../src/utils/workspace-config.js';
+
+const fixturesDir = join(__dirname, '../../fixtures/go');
+const readFixture = (name: string) => readFileSync(join(fixturesDir, name), 'utf-8');
+
+describe('GoHandler', () => {
+  let handler: GoHandler;
+
+  beforeEach(() => {
+    handler = new GoHandler();
+  });
+
+  describe('go vet parsing', () => {
+    it('should tag vet JSON findings with their analyzer', () => {
+      const stderr = [
+        '# temp',
+        '{',
+        '\t"temp": {',
+        '\t\t"printf": [',
+        '\t\t\t{',
+        '\t\t\t\t"posn": "/tmp/go-vet-check-1/main.go:6:2",',
+        '\t\t\t\t"message": "fmt.Printf format %d has arg \\"x\\" of wrong type string"',
+        '\t\t\t}',
+        '\t\t],',
+        '\t\t"composites": [',
+        '\t\t\t{',
+        '\t\t\t\t"posn": "/tmp/go-vet-check-1/main.go:9:7",',
+        '\t\t\t\t"message": "image.Point struct literal uses unkeyed fields"',
+        '\t\t\t}',
+        '\t\t]',
+        '\t}',
+        '}'
+      ].join('\n');
+
+      const errors = (handler as any).parseGoVetOutput(stderr, '/repo/main.go');
+
+      expect(errors).toHaveLength(2);
+      expect(errors[0]).toMatchObject({
+        severity: 'warning',
+        code: 'printf',
+        analyzer: 'printf',
+        location: { line: 6, column: 2 }
+      });
+      expect(errors[1].analyzer).toBe('composites');
+    });
+
+    it('should read the JSON findings go vet writes to stdout', async () => {
+      (handler as any).goPath = 'go';
+      vi.spyOn(handler as any, 'runInPackage').mockResolvedValue({
+        stdout: readFixture('vet.stdout'),
+        stderr: '# example.com/shop\n',
+        exitCode: 0
+      });
+
+      const errors = await (handler as any).runGoVet('package main\n', '/repo/shop/main.go', '/repo/shop');
+
+      expect(errors).toEqual([expect.objectContaining({
+        analyzer: 'printf',
+        message: 'fmt.Printf format %d has arg "x" of wrong type string',
+        location: expect.objectContaining({ file: '/repo/shop/main.go', line: 6, column: 14 })
+      })]);
+    });
+
+    it('should parse bracketed analyzer names from text output', () => {
+      const errors = (handler as any).parseGoVetOutput(
+        './main.go:5:2: [structtag] struct field tag `json:x` not compatible with reflect.StructTag.Get',
+        '/repo/main.go'
+      );
+
+      expect(errors).toHaveLength(1);
+      expect(errors[0].analyzer).toBe('structtag');
+      expect(errors[0].message).toContain('struct field tag');
+    });
+
+    it('should leave type errors to the compiler pass', () => {
+      const errors = (handler as any).parseGoVetOutput('vet: ./main.go:3:2: undefined: x', '/repo/main.go');
+
+      expect(errors).toHaveLength(0);
+    });
+  });
+
+  describe('go build parsing', () => {
+    it('should link redeclarations to the other declaration', () => {
+      const errors = (handler as any).parseGoErrors(readFixture('redeclared.stderr'), '/repo/redeclared.go');
+
+      expect(errors).toHaveLength(2);
+      expect(errors[0]).toMatchObject({
+        message: 'x redeclared in this block',
+        location: { file: '/repo/redeclared.go', line: 7, column: 5 },
+        relatedInformation: [
+          { location: { file: '/repo/redeclared.go', line: 5, column: 5 }, message: 'other declaration of x' }
+        ]
+      });
+      expect(errors[1].relatedInformation).toEqual([
+        { location: { file: '/repo/redeclared.go', line: 9, column: 6 }, message: 'other declaration of helper' }
+      ]);
+    });
+
+    it('should point related locations at the declarations in the fixture source', () => {
+      const source = readFixture('redeclared.go').split('\n');
+      const errors = (handler as any).parseGoErrors(readFixture('redeclared.stderr'), '/repo/redeclared.go');
+
+      for (const error of errors) {
+        const related = error.relatedInformation[0].location;
+        expect(source[related.line - 1]!.slice(related.column - 1)).toMatch(/^(x|helper)\b/);
+      }
+    });
+
+    it('should keep only the analyzed file when building a real package', () => {
+      const stderr = [
+        '# example.com/svc/pkg',
+        './other.go:4:2: undefined: y',
+        './x.go:3:6: helper redeclared in this block',
+        '\t./other.go:9:6: other declaration of helper'
+      ].join('\n');
+
+      const errors = (handler as any).parseGoErrors(stderr, '/repo/svc/pkg/x.go', 'x.go', '/repo/svc/pkg');
+
+      expect(errors).toHaveLength(1);
+      expect(errors[0]).toMatchObject({
+        location: { file: '/repo/svc/pkg/x.go', line: 3, column: 6 },
+        relatedInformation: [
+          { location: { file: '/repo/svc/pkg/other.go', line: 9, column: 6 }, message: 'other declaration of helper' }
+        ]
+      });
+    });
+
+    it('should fold indented detail lines into the parent message', () => {
+      const errors = (handler as any).parseGoErrors(readFixture('type_mismatch.stderr'), '/repo/main.go');
+
+      expect(errors).toHaveLength(2);
+      expect(errors[0].relatedInformation).toEqual([]);
+      expect(errors[1].message).toBe('impossible type assertion: r.(T)\nT does not implement io.Reader (missing method Read)');
+    });
+  });
+
+  describe('package runs', () => {
+    it('should share one in-flight build between files of a package', async () => {
+      let finish: (value: unknown) => void = () => {};
+      const runGoCommand = vi.spyOn(handler as any, 'runGoCommand').mockImplementation(
+        () => new Promise(resolve => { finish = resolve; })
+      );
+      // Any file that was last written before the run started
+      const file = join(fixturesDir, 'redeclared.stderr');
+      const args = ['build', '-o', '/dev/null', '.'];
+
+      const first = (handler as any).runInPackage(fixturesDir, args, file);
+      const second = (handler as any).runInPackage(fixturesDir, args, file);
+      await new Promise(resolve => setTimeout(resolve, 0));
+      finish({ stdout: '', stderr: '', exitCode: 0 });
+
+      expect(await first).toEqual(await second);
+      expect(runGoCommand).toHaveBeenCalledTimes(1);
+
+      // A finished run is not reused
+      await new Promise(resolve => setTimeout(resolve, 0));
+      const third = (handler as any).runInPackage(fixturesDir, args, file);
+      finish({ stdout: '', stderr: '', exitCode: 0 });
+      await third;
+      expect(runGoCommand).toHaveBeenCalledTimes(2);
+    });
+
+    it('should warm the build cache with every package of the module, writing nothing', async () => {
+      (handler as any).goPath = 'go';
+      const runGoCommand = vi.spyOn(handler as any, 'runGoCommand')
+        .mockResolvedValueOnce({ stdout: '', stderr: '', exitCode: 0 })
+        .mockResolvedValueOnce({ stdout: '', stderr: '# example.com/shop/api\napi/api.go:3:2: undefined: x\n', exitCode: 1 });
+
+      expect(await handler.warmBuildCache('/repo/shop')).toBe(true);
+      expect(await handler.warmBuildCache('/repo/shop')).toBe(false);
+      expect(runGoCommand).toHaveBeenCalledWith(
+        ['build', '-o', expect.any(String), './...'],
+        expect.objectContaining({ cwd: '/repo/shop' })
+      );
+    });
+  });
+
+  describe('toolchain failures', () => {
+    beforeEach(() => {
+      (handler as any).goPath = 'go';
+    });
+
+    it('should surface unparseable build failures as a toolchain diagnostic', async () => {
+      vi.spyOn(handler as any, 'runInTempModule').mockResolvedValue({
+        stdout: '',
+        stderr: 'go: cannot find main module, but found .git/config in /repo\n',
+        exitCode: 1
+      });
+
+      const errors = await (handler as any).validateSyntax('package main\n', '/repo/main.go');
+
+      expect(errors).toEqual([expect.objectContaining({
+        severity: 'error',
+        source: 'toolchain',
+        code: 'toolchain',
+        message: 'go build failed: go: cannot find main module, but found .git/config in /repo',
+        location: expect.objectContaining({ file: '/repo/main.go', line: 1 })
+      })]);
+    });
+
+    it('should not report a failure when only sibling files have errors', async () => {
+      vi.spyOn(handler as any, 'runInPackage').mockResolvedValue({
+        stdout: '',
+        stderr: '# example.com/svc\n./other.go:4:2: undefined: y\n',
+        exitCode: 1
+      });
+
+      expect(await (handler as any).validateSyntax('package main\n', '/repo/x.go', '/repo')).toEqual([]);
+    });
+  });
+
+  describe('transient failures', () => {
+    const proxyFailure = {
+      stdout: '',
+      stderr: 'go: example.com/lib@v1.2.0: reading https://proxy.golang.org/example.com/lib/@v/v1.2.0.zip: 410 Gone\n',
+      exitCode: 1
+    };
+    const compileFailure = {
+      stdout: '',
+      stderr: '# temp\n./main.go:3:2: undefined: fetch // i/o timeout\n',
+      exitCode: 1
+    };
+
+    it('should only treat network failures without code positions as transient', () => {
+      expect(isTransientGoFailure(proxyFailure)).toBe(true);
+      expect(isTransientGoFailure({ ...proxyFailure, stderr: 'dial tcp 1.2.3.4:443: i/o timeout' })).toBe(true);
+      expect(isTransientGoFailure(compileFailure)).toBe(false);
+      expect(isTransientGoFailure({ ...proxyFailure, exitCode: 0 })).toBe(false);
+    });
+
+    it('should retry transient failures with exponential backoff', async () => {
+      handler = new GoHandler({ retry: { retries: 3, backoffMs: 1, maxBackoffMs: 2 } });
+      (handler as any).goPath = 'go';
+      const warn = vi.spyOn((handler as any).logger, 'warn');
+      const runCommand = vi.spyOn(handler as any, 'runCommand')
+        .mockResolvedValueOnce(proxyFailure)
+        .mockResolvedValueOnce(proxyFailure)
+        .mockResolvedValueOnce({ stdout: '', stderr: '', exitCode: 0 });
+
+      const result = await (handler as any).runInPackage('/repo', ['build', '.']);
+
+      expect(result.exitCode).toBe(0);
+      expect(runCommand).toHaveBeenCalledTimes(3);
+      expect(warn.mock.calls.map(call => call[0])).toEqual([
+        'go build failed with a transient error, retrying in 1ms (attempt 1 of 3)',
+        'go build failed with a transient error, retrying in 2ms (attempt 2 of 3)'
+      ]);
+    });
+
+    it('should not retry compile errors and should give up after the configured retries', async () => {
+      handler = new GoHandler({ retry: { retries: 1, backoffMs: 0 } });
+      (handler as any).goPath = 'go';
+      const runCommand = vi.spyOn(handler as any, 'runCommand').mockResolvedValue(compileFailure);
+
+      await (handler as any).runInPackage('/repo', ['build', '.']);
+      expect(runCommand).toHaveBeenCalledTimes(1);
+
+      runCommand.mockResolvedValue(proxyFailure);
+      const result = await (handler as any).runInPackage('/repo', ['build', '.']);
+      expect(runCommand).toHaveBeenCalledTimes(3);
+      expect(result).toBe(proxyFailure);
+    });
+  });
+
+  describe('gopls options', () => {
+    it('should only let the server enable gopls and choose its binary', async () => {
+      const workspace = { gopls: { enabled: true, path: './bin/gopls', args: ['-remote=./sock'], settleMs: 50 } };
+
+      expect(await runWithDetectorOptions(workspace, async () => (handler as any).getGoplsOptions()))
+        .toEqual({ enabled: false, settleMs: 50 });
+
+      handler = new GoHandler({ gopls: { enabled: true, path: '/usr/local/bin/gopls' } });
+      expect(await runWithDetectorOptions(workspace, async () => (handler as any).getGoplsOptions()))
+        .toEqual({ enabled: true, path: '/usr/local/bin/gopls', settleMs: 50 });
+    });
+  });
+
+  describe('go vet flags', () => {
+    it('should pass vettool and analyzer selection from options', () => {
+      handler = new GoHandler({
+        vet: { vettool: '/usr/bin/myvet', analyzers: ['printf'], disabledAnalyzers: ['composites'] }
+      });
+
+      expect((handler as any).getVetFlags()).toEqual([
+        '-json',
+        '-vettool=/usr/bin/myvet',
+        '-printf',
+        '-composites=false'
+      ]);
+    });
+
+    it('should only run a vettool the server configured', async () => {
+      const flags = await runWithDetectorOptions({ vet: { vettool: './evil', analyzers: ['printf'] } }, async () =>
+        (handler as any).getVetFlags()
+      );
+
+      expect(flags).toEqual(['-json', '-printf']);
+    });
+  });
+});
@@ -0,0 +1,174 @@
+/**
+ * Tests for the module-wide Go import check
+ */
+
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { promises as fs, readFileSync } from 'fs';
+import { tmpdir } from 'os';
+import { join } from 'path';
+import {
+  GO_IMPORTS_ANALYZER,
+  GoImportChecker,
+  findImportIssues,
+  parseGoImportsOutput,
+  type GoFileImports
+} from '../../../src/languages/go-imports.js';
+import { GoHandler } from '../../../src/languages/go-handler.js';
+
+/** Helper output for a module with duplicate, blank and aliased imports, a generated file and an unparsable one */
+const output = readFileSync(join(__dirname, '../../fixtures/go/imports.jsonl'), 'utf-8');
+
+const summarize = (errors: ReturnType<typeof findImportIssues>) =>
+  errors.map(error => [error.location.file, error.location.line, error.code, error.suggestedFix]);
+
+function fileImports(file: string, imports: Array<[string, string?]>): GoFileImports {
+  return {
+    file,
+    package: 'main',
+    generated: false,
+    imports: imports.map(([path, name], index) => ({
+      path,
+      ...(name && { name }),
+      line: index + 3,
+      column: 2,
+      endLine: index + 3,
+      endColumn: 10
+    }))
+  };
+}
+
+describe('findImportIssues', () => {
+  it('should parse the helper output against the module root', () => {
+    const files = parseGoImportsOutput(`${output}not json\n`, '/repo/shop');
+
+    expect(files.map(file => [file.file, file.package, file.generated, file.imports.length])).toEqual([
+      ['/repo/shop/api/api.go', 'api', false, 6],
+      ['/repo/shop/api/gen.go', 'api', true, 1],
+      ['/repo/shop/store/store.go', 'store', false, 2],
+      ['/repo/shop/store/store_test.go', 'store_test', false, 2]
+    ]);
+  });
+
+  it('should flag redundant and inconsistent imports across the module', () => {
+    const errors = findImportIssues(parseGoImportsOutput(output, '/repo/shop'), '/repo/shop', 'example.com/shop');
+
+    expect(summarize(errors)).toEqual([
+      ['/repo/shop/api/api.go', 4, 'redundant-import-alias', 'Remove the alias'],
+      ['/repo/shop/api/api.go', 6, 'duplicate-import', 'Remove this import and refer to the package as http instead of h'],
+      ['/repo/shop/api/api.go', 7, 'redundant-blank-import', 'Remove the blank import'],
+      // The generated file's `s` alias neither counts nor is reported
+      [
+        '/repo/shop/store/store.go', 5, 'inconsistent-import-alias',
+        'Import it without an alias, as the other files do, and refer to it as strings instead of strs'
+      ],
+      // One file each way: no name wins, so there is no fix
+      ['/repo/shop/api/api.go', 9, 'inconsistent-import-alias', undefined],
+      ['/repo/shop/store/store_test.go', 5, 'inconsistent-import-alias', undefined]
+    ]);
+    expect(errors[3]).toMatchObject({
+      message: '"strings" is imported as strs here, but without an alias in 2 files of the module',
+      severity: 'hint',
+      source: 'go',
+      analyzer: GO_IMPORTS_ANALYZER,
+      location: { line: 5, column: 2, endLine: 5, endColumn: 16 },
+      relatedInformation: [{
+        location: { file: '/repo/shop/api/api.go', line: 8, column: 2 },
+        message: '"strings" imported without an alias here'
+      }]
+    });
+  });
+
+  it('should only suggest an alias the file has no other use for', () => {
+    const errors = findImportIssues([
+      fileImports('/repo/a.go', [['github.com/acme/yaml/v3', 'yaml']]),
+      fileImports('/repo/b.go', [['github.com/acme/yaml/v3', 'yaml']]),
+      fileImports('/repo/c.go', [['github.com/acme/yaml/v3', 'yamlv3'], ['gopkg.in/yaml.v2', 'yaml']]),
+      fileImports('/repo/d.go', [['github.com/acme/yaml/v3', 'y']])
+    ], '/repo', 'example.com/app');
+
+    expect(summarize(errors)).toEqual([
+      ['/repo/c.go', 3, 'inconsistent-import-alias', undefined],
+      ['/repo/d.go', 3, 'inconsistent-import-alias', 'Import it as yaml, as the other files do, and refer to it as yaml instead of y']
+    ]);
+  });
+
+  it('should leave agreeing files and unknown package names alone', () => {
+    const errors = findImportIssues([
+      fileImports('/repo/a.go', [['github.com/acme/log'], ['fmt']]),
+      fileImports('/repo/b.go', [['github.com/acme/log'], ['fmt', '.'], ['fmt', '_']]),
+      fileImports('/repo/c.go', [['github.com/acme/log', 'log']])
+    ], '/repo', 'example.com/app');
+
+    // Whether `log` is the package's own name cannot be told from the path
+    expect(summarize(errors)).toEqual([
+      ['/repo/c.go', 3, 'inconsistent-import-alias', undefined]
+    ]);
+  });
+});
+
+describe('GoImportChecker', () => {
+  let moduleRoot: string;
+
+  beforeEach(async () => {
+    moduleRoot = await fs.realpath(await fs.mkdtemp(join(tmpdir(), 'go-import-checker-')));
+    const past = new Date(Date.now() - 60_000);
+    for (const [name, content] of Object.entries({
+      'go.mod': 'module example.com/shop\n\ngo 1.22\n',
+      'api/api.go': 'package api\n',
+      'store/store.go': 'package store\n'
+    })) {
+      await fs.mkdir(join(moduleRoot, name, '..'), { recursive: true });
+      await fs.writeFile(join(moduleRoot, name), content);
+      await fs.utimes(join(moduleRoot, name), past, past);
+    }
+  });
+
+  afterEach(async () => {
+    vi.restoreAllMocks();
+    await fs.rm(moduleRoot, { recursive: true, force: true });
+  });
+
+  it('should walk the module once for the files analyzed together', async () => {
+    const run = vi.fn(async () => ({ stdout: output, stderr: '', exitCode: 0 }));
+    const checker = new GoImportChecker(run);
+
+    const api = await checker.check(join(moduleRoot, 'api/api.go'));
+    const store = await checker.check(join(moduleRoot, 'store/store.go'));
+
+    expect(run).toHaveBeenCalledTimes(1);
+    expect(run).toHaveBeenCalledWith(moduleRoot);
+    expect(api.map(error => error.code)).toEqual([
+      'redundant-import-alias', 'duplicate-import', 'redundant-blank-import', 'inconsistent-import-alias'
+    ]);
+    expect(store.map(error => error.message)).toEqual([
+      '"strings" is imported as strs here, but without an alias in 2 files of the module'
+    ]);
+  });
+
+  it('should walk again once a file changed', async () => {
+    const run = vi.fn(async () => ({ stdout: output, stderr: '', exitCode: 0 }));
+    const checker = new GoImportChecker(run);
+
+    await checker.check(join(moduleRoot, 'api/api.go'));
+    await fs.writeFile(join(moduleRoot, 'api/api.go'), 'package api\n\nimport "fmt"\n');
+    await checker.check(join(moduleRoot, 'api/api.go'));
+
+    expect(run).toHaveBeenCalledTimes(2);
+  });
+
+  it('should only check imports from the Go handler when enabled', async () => {
+    const file = join(moduleRoot, 'store/store.go');
+    const run = vi.fn(async () => ({ stdout: output, stderr: '', exitCode: 0 }));
+
+    for (const enabled of [false, true]) {
+      const handler = new GoHandler({ imports: { enabled }, vet: { enabled: false } });
+      (handler as any).importChecker = new GoImportChecker(run);
+      vi.spyOn(handler as any, 'validateSyntax').mockResolvedValue([]);
+
+      const errors = await handler.detectErrors('package store\n', { filePath: file });
+
+      expect(errors.map(error => error.severity)).toEqual(enabled ? ['hint'] : []);
+    }
+    expect(run).toHaveBeenCalledTimes(1);
+  });
+});
@@ -0,0 +1,101 @@
+/**
+ * Tests for go.mod module error diagnostics
+ */
+
+import { describe, it, expect, vi } from 'vitest';
+import { readFileSync } from 'fs';
+import { join } from 'path';
+import { locateGoModModule, parseGoModDirectives, parseGoModuleErrors } from '../../../src/languages/go-module.js';
+import { GoHandler } from '../../../src/languages/go-handler.js';
+
+const fixturesDir = join(__dirname, '../../fixtures/go');
+
+const GO_MOD = `module example.com/svc
+
+go 1.22
+
+require github.com/pkg/errors v0.9.1
+
+require (
+	github.com/google/uuid v1.6.0
+	golang.org/x/text v0.14.0 // indirect
+)
+
+replace golang.org/x/text => ../text
+`;
+
+describe('go.mod directives', () => {
+  it('should read single-line and block directives with their positions', () => {
+    expect(parseGoModDirectives(GO_MOD).map(({ verb, path, line }) => [verb, path, line])).toEqual([
+      ['module', 'example.com/svc', 1],
+      ['require', 'github.com/pkg/errors', 5],
+      ['require', 'github.com/google/uuid', 8],
+      ['require', 'golang.org/x/text', 9],
+      ['replace', 'golang.org/x/text', 12]
+    ]);
+  });
+
+  it('should find the require line of the module providing a package', () => {
+    expect(locateGoModModule(GO_MOD, 'golang.org/x/text/language')).toMatchObject({ verb: 'require', line: 9, column: 2, endColumn: 19 });
+    expect(locateGoModModule(GO_MOD, 'github.com/pkg/errors')).toMatchObject({ line: 5, column: 9, endColumn: 30 });
+    expect(locateGoModModule(GO_MOD, 'example.com/other')).toMatchObject({ verb: 'module', line: 1 });
+  });
+});
+
+describe('parseGoModuleErrors', () => {
+  it('should place build module errors on require lines and suggest go mod download or tidy', () => {
+    const output = readFileSync(join(fixturesDir, 'module_errors.stderr'), 'utf-8');
+
+    const errors = parseGoModuleErrors(output, '/repo/go.mod', GO_MOD);
+
+    expect(errors.map(error => [error.code, error.location.line, error.location.column, error.suggestedFix])).toEqual([
+      ['missing-go-sum', 8, 2, 'Run `go mod download github.com/google/uuid` or `go mod tidy` to add the missing go.sum entry'],
+      ['missing-go-sum', 9, 2, 'Run `go mod download golang.org/x/text` or `go mod tidy` to add the missing go.sum entry'],
+      ['missing-go-sum', 5, 9, 'Run `go mod download github.com/pkg/errors` or `go mod tidy` to add the missing go.sum entry'],
+      ['go-mod-tidy', 1, 8, 'Run `go mod tidy`']
+    ]);
+    expect(errors[0]).toMatchObject({
+      message: 'missing go.sum entry for module providing package github.com/google/uuid (imported by example.com/svc)',
+      severity: 'error',
+      source: 'go',
+      analyzer: 'modules',
+      location: { file: '/repo/go.mod', endLine: 8, endColumn: 24 }
+    });
+  });
+
+  it('should report go mod verify and resolution failures', () => {
+    const output = [
+      'github.com/google/uuid v1.6.0: dir has been modified (/root/go/pkg/mod/github.com/google/uuid@v1.6.0)',
+      'go: github.com/pkg/errors@v0.9.1: invalid version: unknown revision v0.9.1',
+      'go.mod:3: invalid go version \'1.x\': must match format 1.23'
+    ].join('\n');
+
+    const errors = parseGoModuleErrors(output, '/repo/go.mod', GO_MOD);
+
+    expect(errors.map(error => [error.code, error.location.line])).toEqual([
+      ['module-modified', 8],
+      ['invalid-version', 5],
+      ['go-mod-syntax', 3]
+    ]);
+    expect(errors[1]!.suggestedFix).toBe('Fix the version of github.com/pkg/errors in go.mod, then run `go mod tidy`');
+  });
+});
+
+describe('GoHandler go.mod checks', () => {
+  it('should check go.mod files with go list and go mod verify', async () => {
+    const handler = new GoHandler();
+    (handler as any).goPath = 'go';
+    const runGoCommand = vi.spyOn(handler as any, 'runGoCommand').mockImplementation(async (...call: unknown[]) => {
+      const [args] = call as [string[]];
+      return args[0] === 'mod'
+        ? { stdout: '', stderr: 'github.com/google/uuid v1.6.0: dir has been modified (/mod/uuid)\n', exitCode: 1 }
+        : { stdout: 'example.com/svc\n', stderr: '', exitCode: 0 };
+    });
+    vi.spyOn(handler as any, 'isUnmodifiedOnDisk').mockResolvedValue(true);
+
+    const errors = await handler.detectErrors(GO_MOD, { filePath: '/repo/go.mod' });
+
+    expect(runGoCommand.mock.calls.map(([args]) => args)).toEqual([['list', '-m', 'all'], ['mod', 'verify']]);
+    expect(errors).toEqual([expect.objectContaining({ code: 'module-modified', location: expect.objectContaining({ file: '/repo/go.mod', line: 8 }) })]);
+  });
+});
@@ -0,0 +1,153 @@
+/**
+ * Tests for Go offline mode
+ */
+
+import { describe, it, expect, vi } from 'vitest';
+import { readFileSync } from 'fs';
+import { join } from 'path';
+import {
+  OFFLINE_BLOCKED_CODE,
+  goOfflineVariables,
+  markOfflineBlocked,
+  parseOfflineImportErrors
+} from '../../../src/languages/go-offline.js';
+import { parseGoModuleErrors } from '../../../src/languages/go-module.js';
+import { GoHandler } from '../../../src/languages/go-handler.js';
+import { runWithDetectorOptions } from '../../../src/utils/workspace-config.js';
+import { CommandPlan, planCommands } from '../../../src/utils/command-plan.js';
+
+const fixturesDir = join(__dirname, '../../fixtures/go');
+const readFixture = (name: string) => readFileSync(join(fixturesDir, name), 'utf-8');
+
+const GO_MOD = `module example.com/svc
+
+go 1.22
+
+require github.com/google/uuid v1.6.0
+`;
+
+const MAIN_GO = `package main
+
+import (
+	"github.com/google/uuid"
+	"golang.org/x/text/language"
+)
+
+func main() {
+	_ = uuid.New()
+	_ = language.English
+}
+`;
+
+describe('Go offline mode', () => {
+  describe('goOfflineVariables', () => {
+    it('should disable the proxy, toolchain downloads and go.mod updates', () => {
+      expect(goOfflineVariables()).toEqual({ GOPROXY: 'off', GOTOOLCHAIN: 'local', GOFLAGS: '-mod=readonly' });
+    });
+
+    it('should replace other -mod flags but keep -mod=vendor and unrelated flags', () => {
+      expect(goOfflineVariables('-mod=mod -trimpath')['GOFLAGS']).toBe('-trimpath -mod=readonly');
+      expect(goOfflineVariables(' -mod=vendor  -trimpath ')['GOFLAGS']).toBe('-mod=vendor -trimpath');
+    });
+  });
+
+  describe('parseOfflineImportErrors', () => {
+    it('should report blocked lookups at the imports that needed them', () => {
+      const errors = parseOfflineImportErrors(readFixture('offline_build.stderr'), 'main.go', '/repo/cmd/main.go', MAIN_GO);
+
+      expect(errors.map(error => [error.location.line, error.location.column, error.location.endColumn, error.code])).toEqual([
+        [4, 2, 26, OFFLINE_BLOCKED_CODE],
+        [5, 2, 30, OFFLINE_BLOCKED_CODE]
+      ]);
+      expect(errors[0]).toMatchObject({
+        message: 'Import "github.com/google/uuid" needs a module that offline mode cannot download: module lookup disabled by GOPROXY=off',
+        severity: 'error',
+        source: 'go',
+        location: { file: '/repo/cmd/main.go' },
+        suggestedFix: 'Run `go mod download` with network access, or analyze without offline mode'
+      });
+    });
+
+    it('should recognize -mod=readonly and skip other files of the package', () => {
+      const stderr = [
+        'main.go:3:8: cannot find module providing package github.com/google/uuid: import lookup disabled by -mod=readonly',
+        'store.go:3:8: module lookup disabled by GOPROXY=off',
+        './main.go:9:2: undefined: x'
+      ].join('\n');
+
+      const errors = parseOfflineImportErrors(stderr, 'main.go', 'main.go');
+
+      expect(errors).toHaveLength(1);
+      expect(errors[0]!.message).toBe('An import needs a module that offline mode cannot download: cannot find module providing package github.com/google/uuid: import lookup disabled by -mod=readonly');
+    });
+  });
+
+  it('should leave errors unrelated to offline mode alone', () => {
+    const error = { message: 'undefined: x', severity: 'error' as const, location: { file: 'main.go', line: 1, column: 1 }, source: 'go' };
+
+    expect(markOfflineBlocked(error)).toBe(error);
+  });
+
+  it('should place module lookups blocked in go.mod checks on the require line', () => {
+    const errors = parseGoModuleErrors(readFixture('offline_module.stderr'), '/repo/go.mod', GO_MOD);
+
+    expect(errors).toHaveLength(1);
+    expect(errors[0]).toMatchObject({
+      message: 'github.com/google/uuid@v1.6.0 is not in the module cache, and offline mode (GOPROXY=off) blocked downloading it',
+      code: OFFLINE_BLOCKED_CODE,
+      location: { file: '/repo/go.mod', line: 5, column: 9 },
+      suggestedFix: 'Run `go mod download github.com/google/uuid@v1.6.0` with network access, or analyze without offline mode'
+    });
+  });
+
+  describe('GoHandler', () => {
+    // The environment a go build would be started with
+    const runBuild = async (handler: GoHandler) => {
+      (handler as any).goPath = 'go';
+      const plan = new CommandPlan({ fullEnv: true });
+      await planCommands(plan, () => (handler as any).runInPackage('/repo', ['build', '.']));
+      return plan.getCommands()[0]!.env;
+    };
+
+    it('should run go commands offline when the option is set', async () => {
+      const env = await runBuild(new GoHandler({ offline: true }));
+
+      expect(env).toMatchObject({ GOPROXY: 'off', GOTOOLCHAIN: 'local' });
+      expect(env['GOFLAGS']).toContain('-mod=readonly');
+    });
+
+    it('should apply offline mode after the detector env', async () => {
+      const handler = new GoHandler({ offline: true, env: { GOFLAGS: '-mod=mod -tags=e2e', GOPROXY: 'https://proxy', GOTOOLCHAIN: 'auto' } });
+
+      expect(await runBuild(handler)).toMatchObject({ GOPROXY: 'off', GOTOOLCHAIN: 'local', GOFLAGS: '-tags=e2e -mod=readonly' });
+      expect((handler as any).getGoplsOptions().env).toEqual({ GOPROXY: 'off', GOTOOLCHAIN: 'local', GOFLAGS: '-tags=e2e -mod=readonly' });
+
+      const vendored = new GoHandler({ offline: true, env: { GOFLAGS: '-mod=vendor' } });
+      expect(await runBuild(vendored)).toMatchObject({ GOPROXY: 'off', GOFLAGS: '-mod=vendor' });
+    });
+
+    it('should take the option from the detection scope', async () => {
+      const handler = new GoHandler();
+
+      expect(await runBuild(handler)).not.toMatchObject({ GOPROXY: 'off', GOTOOLCHAIN: 'local' });
+      expect(await runWithDetectorOptions({ offline: true }, () => runBuild(new GoHandler()))).toMatchObject({ GOPROXY: 'off' });
+    });
+
+    it('should report blocked imports of a checked buffer instead of a toolchain failure', async () => {
+      const handler = new GoHandler({ offline: true });
+      vi.spyOn(handler as any, 'runInTempModule').mockResolvedValue({
+        stdout: '',
+        stderr: 'main.go:4:2: cannot find module providing package github.com/google/uuid: import lookup disabled by -mod=readonly\n',
+        exitCode: 1
+      });
+
+      const errors = await (handler as any).validateSyntax(MAIN_GO, 'snippet.go');
+
+      expect(errors).toHaveLength(1);
+      expect(errors[0]).toMatchObject({
+        code: OFFLINE_BLOCKED_CODE,
+        location: { file: 'snippet.go', line: 4, column: 2, endColumn: 26 }
+      });
+    });
+  });
+});
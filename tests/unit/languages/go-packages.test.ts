@@ -0,0 +1,144 @@
+/**
+ * Tests for analyzing Go package patterns
+ */
+
+import { describe, it, expect, beforeEach, vi } from 'vitest';
+import { readFileSync } from 'fs';
+import { devNull } from 'os';
+import { join } from 'path';
+import { GoHandler } from '../../../src/languages/go-handler.js';
+import {
+  parseGoListPatterns,
+  parseGoPackageBuildOutput,
+  parseGoPackageVetOutput
+} from '../../../src/languages/go-packages.js';
+
+const readFixture = (name: string) => readFileSync(join(__dirname, '../../fixtures/go', name), 'utf-8');
+
+describe('parseGoListPatterns', () => {
+  it('should report patterns that match nothing usable', () => {
+    const { packages, problems } = parseGoListPatterns(readFixture('packages_list.stdout'), readFixture('packages_list.stderr'));
+
+    expect(packages.map(pkg => pkg.dir)).toEqual(['/repo', '/repo/cmd/api', '/repo/internal/store']);
+    expect(problems).toEqual([
+      './nope: stat /repo/nope: directory not found',
+      './empty: no Go files in /repo/empty',
+      './empty/...: matched no packages'
+    ]);
+  });
+
+  it('should keep packages that only fail to compile', () => {
+    const { packages, problems } = parseGoListPatterns('example.com/shop\t/repo\t\n', '');
+
+    expect(packages).toEqual([{ importPath: 'example.com/shop', dir: '/repo' }]);
+    expect(problems).toEqual([]);
+  });
+});
+
+describe('parseGoPackageBuildOutput', () => {
+  it('should resolve paths of every package against the build directory', () => {
+    const errors = parseGoPackageBuildOutput(readFixture('packages_build.stderr'), '/repo');
+
+    expect(errors.map(error => [error.location.file, error.location.line, error.message])).toEqual([
+      ['/repo/main.go', 2, 'declared and not used: y'],
+      ['/repo/internal/store/store.go', 3, '"os" imported and not used'],
+      ['/repo/internal/store/store.go', 6, 'cannot use "x" (untyped string constant) as int value in return statement']
+    ]);
+    expect(errors.every(error => error.severity === 'error' && error.source === 'go')).toBe(true);
+  });
+
+  it('should attach indented positions as related information', () => {
+    const stderr = [
+      '# example.com/shop/internal/store',
+      'internal/store/store.go:9:6: Open redeclared in this block',
+      '\tinternal/store/open.go:4:6: other declaration of Open'
+    ].join('\n');
+
+    const [error] = parseGoPackageBuildOutput(stderr, '/repo');
+
+    expect(error!.relatedInformation).toEqual([{
+      location: { file: '/repo/internal/store/open.go', line: 4, column: 6 },
+      message: 'other declaration of Open'
+    }]);
+  });
+});
+
+describe('parseGoPackageVetOutput', () => {
+  it('should report analyzer findings and leave type errors to the build', () => {
+    const output = `${readFixture('packages_vet.stdout')}\n${readFixture('packages_vet.stderr')}`;
+    const errors = parseGoPackageVetOutput(output, '/repo');
+
+    expect(errors).toHaveLength(1);
+    expect(errors[0]).toMatchObject({
+      message: 'fmt.Printf format %d has arg "x" of wrong type string',
+      severity: 'warning',
+      code: 'printf',
+      analyzer: 'printf',
+      location: { file: '/repo/cmd/api/main.go', line: 6, column: 14 }
+    });
+  });
+});
+
+describe('GoHandler.detectPackageErrors', () => {
+  let handler: GoHandler;
+  let runGoCommand: ReturnType<typeof vi.spyOn>;
+
+  beforeEach(() => {
+    handler = new GoHandler();
+    (handler as any).goPath = 'go';
+    runGoCommand = vi.spyOn(handler as any, 'runGoCommand').mockImplementation(async (...call: unknown[]) => {
+      const [command] = call[0] as string[];
+      if (command === 'list') {
+        return { stdout: 'example.com/shop\t/repo\t\nexample.com/shop/internal/store\t/repo/internal/store\t\n', stderr: '', exitCode: 0 };
+      }
+      if (command === 'build') {
+        return { stdout: '', stderr: readFixture('packages_build.stderr'), exitCode: 1 };
+      }
+      return { stdout: readFixture('packages_vet.stdout'), stderr: readFixture('packages_vet.stderr'), exitCode: 1 };
+    });
+  });
+
+  it('should pass the patterns to go build and go vet', async () => {
+    const errors = await handler.detectPackageErrors(['./...'], { dir: '/repo' });
+
+    expect(runGoCommand).toHaveBeenCalledWith(['build', '-o', devNull, './...'], expect.objectContaining({ cwd: '/repo' }));
+    expect(runGoCommand).toHaveBeenCalledWith(['vet', '-json', './...'], expect.objectContaining({ cwd: '/repo' }));
+    expect(errors.map(error => error.severity)).toEqual(['error', 'error', 'error', 'warning']);
+  });
+
+  it('should skip go vet without linting', async () => {
+    const errors = await handler.detectPackageErrors(['./internal/store'], { dir: '/repo', enableLinting: false });
+
+    expect(runGoCommand).toHaveBeenCalledTimes(2);
+    expect(errors).toHaveLength(3);
+  });
+
+  it('should reject patterns that match nothing', async () => {
+    runGoCommand.mockResolvedValueOnce({
+      stdout: readFixture('packages_list.stdout'),
+      stderr: readFixture('packages_list.stderr'),
+      exitCode: 0
+    });
+
+    await expect(handler.detectPackageErrors(['./...', './nope'], { dir: '/repo' }))
+      .rejects.toThrow('Invalid Go package pattern: ./nope: stat /repo/nope: directory not found');
+    expect(runGoCommand).toHaveBeenCalledTimes(1);
+  });
+
+  it('should never pass a pattern as a flag', async () => {
+    await expect(handler.detectPackageErrors(['-toolexec=sh'], { dir: '/repo' }))
+      .rejects.toThrow('Invalid Go package pattern -toolexec=sh: patterns cannot start with -');
+    expect(runGoCommand).not.toHaveBeenCalled();
+  });
+
+  it('should report a failed build without diagnostics as a toolchain error', async () => {
+    runGoCommand.mockImplementation(async (...call: unknown[]) => (call[0] as string[])[0] === 'list'
+      ? { stdout: 'example.com/shop\t/repo\t\n', stderr: '', exitCode: 0 }
+      : { stdout: '', stderr: 'go: updates to go.mod needed', exitCode: 1 });
+
+    const errors = await handler.detectPackageErrors(['./...'], { dir: '/repo', enableLinting: false });
+
+    expect(errors).toHaveLength(1);
+    expect(errors[0]).toMatchObject({ code: 'toolchain', message: 'go build failed: go: updates to go.mod needed' });
+  });
+});
@@ -0,0 +1,169 @@
+/**
+ * Tests for Go panic parsing and run-and-detect
+ */
+
+import { describe, it, expect, afterEach, vi } from 'vitest';
+import { promises as fs, readFileSync } from 'fs';
+import { tmpdir } from 'os';
+import { join } from 'path';
+import { findPanicFrame, goPanicToError, parseGoPanic } from '../../../src/languages/go-panic.js';
+import { GoHandler } from '../../../src/languages/go-handler.js';
+import { LanguageHandlerManager } from '../../../src/languages/language-handler-manager.js';
+
+const fixturesDir = join(__dirname, '../../fixtures/go');
+const panicOutput = readFileSync(join(fixturesDir, 'panic.stderr'), 'utf-8');
+const testPanicOutput = readFileSync(join(fixturesDir, 'nil_map_test.stderr'), 'utf-8');
+
+describe('Go panic parsing', () => {
+  it('should parse the crashing goroutine and ignore the others', () => {
+    const panic = parseGoPanic(panicOutput)!;
+
+    expect(panic.message).toBe('runtime error: index out of range [5] with length 3');
+    expect(panic.kind).toBe('panic');
+    expect(panic.goroutine).toBe('1 [running]');
+    expect(panic.frames.map(frame => `${frame.file}:${frame.line}`)).toEqual([
+      '/work/api/internal/store/store.go:42',
+      '/work/api/internal/handlers/get.go:17',
+      '/home/me/go/pkg/mod/github.com/go-chi/chi/v5@v5.0.10/mux.go:444',
+      '/work/api/main.go:9'
+    ]);
+  });
+
+  it('should return undefined for output without a panic', () => {
+    expect(parseGoPanic('--- FAIL: TestAdd (0.00s)\n    add_test.go:8: got 3, want 4\nFAIL\n')).toBeUndefined();
+  });
+
+  it('should report a recovered test panic at the user frame below the testing runtime', () => {
+    const panic = parseGoPanic(testPanicOutput)!;
+
+    expect(panic.message).toBe('assignment to entry in nil map');
+    expect(findPanicFrame(panic, '/work/api', '/usr/local/go')).toEqual({
+      function: 'example.com/api/cache.(*Cache).Put',
+      file: '/work/api/cache/cache.go',
+      line: 14
+    });
+  });
+
+  it('should prefer workspace frames over dependencies and attach the call stack', () => {
+    const error = goPanicToError(parseGoPanic(panicOutput)!, '/work/api/main.go', '/work/api');
+
+    expect(error).toMatchObject({
+      severity: 'error',
+      code: 'panic',
+      source: 'go',
+      analyzer: 'runtime',
+      location: { file: '/work/api/internal/store/store.go', line: 42, column: 1 }
+    });
+    expect(error.message).toBe(
+      'panic: runtime error: index out of range [5] with length 3 (in example.com/api/internal/store.(*Store).Lookup)'
+    );
+    expect(error.relatedInformation!.map(info => info.message)).toEqual([
+      'in example.com/api/internal/handlers.Get',
+      'in github.com/go-chi/chi/v5.(*Mux).routeHTTP',
+      'in main.main'
+    ]);
+  });
+});
+
+describe('GoHandler.runAndDetect', () => {
+  const dirs: string[] = [];
+
+  afterEach(async () => {
+    vi.restoreAllMocks();
+    await Promise.all(dirs.splice(0).map(dir => fs.rm(dir, { recursive: true, force: true })));
+  });
+
+  function createHandler(): GoHandler {
+    const handler = new GoHandler();
+    (handler as any).goPath = 'go';
+    return handler;
+  }
+
+  it('should build the package, run it and report its panic', async () => {
+    const handler = createHandler();
+    const runGoCommand = vi.spyOn(handler as any, 'runGoCommand').mockImplementation(async (...call: unknown[]) => {
+      const args = call[0] as string[];
+      await fs.writeFile(args[args.indexOf('-o') + 1]!, '');
+      return { stdout: '', stderr: '', exitCode: 0 };
+    });
+    const runCommand = vi.spyOn(handler as any, 'runCommand').mockImplementation(async (...call: unknown[]) => {
+      const args = call[1] as string[];
+      return args[0] === 'env'
+        ? { stdout: '/usr/local/go\n', stderr: '', exitCode: 0 }
+        : { stdout: '', stderr: testPanicOutput, exitCode: 2 };
+    });
+
+    const result = await handler.runAndDetect(fixturesDir, {
+      mode: 'test',
+      args: ['-test.run=TestCache'],
+      timeoutMs: 5000,
+      maxOutputBytes: 4096,
+      workspaceRoot: '/work/api'
+    });
+
+    expect(runGoCommand.mock.calls[0]![0]).toEqual(expect.arrayContaining(['test', '-c']));
+    const binaryCall = runCommand.mock.calls.find(call => call[1] !== undefined && (call[1] as string[])[0] !== 'env')!;
+    expect(binaryCall[1]).toEqual(['-test.run=TestCache']);
+    expect(binaryCall[2]).toMatchObject({ cwd: fixturesDir, maxOutputBytes: 4096 });
+    expect(result).toMatchObject({ ran: true, exitCode: 2, timedOut: false, truncated: false });
+    expect(result.errors).toHaveLength(1);
+    expect(result.errors[0]!.location).toEqual({ file: '/work/api/cache/cache.go', line: 14, column: 1 });
+  });
+
+  it('should return build errors without running anything', async () => {
+    const dir = await fs.mkdtemp(join(tmpdir(), 'go-run-test-'));
+    dirs.push(dir);
+    const handler = createHandler();
+    vi.spyOn(handler as any, 'runGoCommand').mockResolvedValue({
+      stdout: '',
+      stderr: '# example.com/api\n./main.go:5:2: undefined: missing\n./util.go:3:8: "os" imported and not used\n',
+      exitCode: 1
+    });
+    const runCommand = vi.spyOn(handler as any, 'runCommand');
+
+    const result = await handler.runAndDetect(dir, { timeoutMs: 5000, maxOutputBytes: 4096 });
+
+    expect(runCommand).not.toHaveBeenCalled();
+    expect(result.ran).toBe(false);
+    expect(result.errors.map(error => [error.location.file, error.location.line, error.message])).toEqual([
+      [join(dir, 'main.go'), 5, 'undefined: missing'],
+      [join(dir, 'util.go'), 3, '"os" imported and not used']
+    ]);
+  });
+
+  it('should kill a command that writes more than its output cap', async () => {
+    const handler = createHandler();
+
+    const result = await (handler as any).runCommand(
+      process.execPath,
+      ['-e', 'setInterval(() => process.stdout.write("x".repeat(1000)), 1)'],
+      { maxOutputBytes: 5000 }
+    );
+
+    expect(result.truncated).toBe(true);
+    expect(result.exitCode).toBe(-1);
+    expect(result.stdout.length).toBe(5000);
+  });
+
+  it('should decode characters split across chunks or cut by the output cap', async () => {
+    const handler = createHandler();
+    const script = 'process.stdout.write(Buffer.from([0xc3])); setTimeout(() => process.stdout.write(Buffer.from([0xa9, 0x0a])), 50)';
+
+    const split = await (handler as any).runCommand(process.execPath, ['-e', script]);
+    const capped = await (handler as any).runCommand(
+      process.execPath,
+      ['-e', 'setInterval(() => process.stdout.write("é".repeat(1000)), 1)'],
+      { maxOutputBytes: 4999 }
+    );
+
+    expect(split.stdout).toBe('é\n');
+    expect(capped.truncated).toBe(true);
+    expect(capped.stdout).toBe('é'.repeat(2499));
+  });
+
+  it('should refuse to run anything unless execution is enabled', async () => {
+    const manager = new LanguageHandlerManager({ enabledLanguages: [] });
+
+    await expect(manager.runAndDetect(fixturesDir)).rejects.toThrow(/disabled/);
+  });
+});
@@ -0,0 +1,122 @@
+/**
+ * Tests for the go/parser fallback of the Go handler
+ */
+
+import { describe, it, expect, beforeEach, vi } from 'vitest';
+import { readFileSync } from 'fs';
+import { join } from 'path';
+import { GoHandler } from '../../../src/languages/go-handler.js';
+import { GO_PARSER_SOURCE, needsParserFallback, parseGoParserOutput } from '../../../src/languages/go-parser.js';
+import type { LanguageError } from '../../../src/types/languages.js';
+
+const readFixture = (name: string) => readFileSync(join(__dirname, '../../fixtures/go', name), 'utf-8');
+
+function buildError(message: string, code?: string): LanguageError {
+  return {
+    message,
+    severity: 'error',
+    location: { file: '/repo/main.go', line: 1, column: 1 },
+    source: 'go',
+    ...(code && { code })
+  };
+}
+
+describe('parseGoParserOutput', () => {
+  it('should report every syntax error the parser collected', () => {
+    const errors = parseGoParserOutput(readFixture('partial_edit.parser.jsonl'), '/repo/main.go');
+
+    expect(errors).toHaveLength(12);
+    expect(errors.every(error => error.source === GO_PARSER_SOURCE && error.code === 'syntax-error')).toBe(true);
+    expect(errors[0]).toMatchObject({
+      message: "expected '(', found main",
+      location: { file: '/repo/main.go', line: 12, column: 6 }
+    });
+    expect(errors.at(-1)).toMatchObject({ message: 'missing condition in if statement', location: { line: 14, column: 5 } });
+  });
+
+  it('should sort type errors by position and drop repeats', () => {
+    const stdout = [
+      '{"line":6,"column":17,"message":"cannot use helper() (value of type int) as string value in variable declaration","kind":"type"}',
+      '{"line":3,"column":16,"message":"\\"os\\" imported and not used","kind":"type"}',
+      '{"line":3,"column":16,"message":"\\"os\\" imported and not used","kind":"type"}',
+      'not json'
+    ].join('\n');
+
+    expect(parseGoParserOutput(stdout, '/repo/main.go').map(error => [error.location.line, error.code])).toEqual([
+      [3, 'type-error'],
+      [6, 'type-error']
+    ]);
+  });
+});
+
+describe('needsParserFallback', () => {
+  it('should ask for the fallback after syntax errors or a toolchain failure', () => {
+    expect(needsParserFallback([buildError('syntax error: unexpected name main, expected (')])).toBe(true);
+    expect(needsParserFallback([buildError('go build failed: go: cannot find main module', 'toolchain')])).toBe(true);
+  });
+
+  it('should trust a build that reported type errors', () => {
+    expect(needsParserFallback([buildError('undefined: helper')])).toBe(false);
+    expect(needsParserFallback([])).toBe(false);
+  });
+});
+
+describe('GoHandler parser fallback', () => {
+  const source = readFixture('partial_edit.go');
+  let handler: GoHandler;
+
+  beforeEach(() => {
+    handler = new GoHandler({ parserFallback: true });
+    (handler as any).goPath = 'go';
+    vi.spyOn(handler as any, 'runInTempModule').mockResolvedValue({
+      stdout: '',
+      stderr: readFixture('partial_edit.stderr'),
+      exitCode: 1
+    });
+  });
+
+  it('should replace the syntax errors of an aborted build with the parser\'s', async () => {
+    vi.spyOn(handler as any, 'getHelper').mockResolvedValue('/cache/go-parser-check');
+    const runCommand = vi.spyOn(handler as any, 'runCommand').mockResolvedValue({
+      stdout: readFixture('partial_edit.parser.jsonl'),
+      stderr: '',
+      exitCode: 0
+    });
+
+    const errors: LanguageError[] = await (handler as any).validateSyntax(source, '/repo/main.go');
+
+    expect(runCommand).toHaveBeenCalledWith('/cache/go-parser-check', ['.', 'main.go'], expect.anything());
+    expect(errors).toHaveLength(12);
+    expect(errors.every(error => error.source === 'parser')).toBe(true);
+  });
+
+  it('should keep the build errors when the fallback finds nothing', async () => {
+    vi.spyOn(handler as any, 'runParserFallback').mockResolvedValue([]);
+
+    const errors: LanguageError[] = await (handler as any).validateSyntax(source, '/repo/main.go');
+
+    expect(errors.map(error => error.message)).toEqual([
+      'syntax error: unexpected name main, expected (',
+      'syntax error: unexpected ] at end of statement'
+    ]);
+  });
+
+  it('should keep the build errors when the helper cannot be built', async () => {
+    vi.spyOn(handler as any, 'runCommand').mockResolvedValue({ stdout: '', stderr: 'go: no space left on device', exitCode: 1 });
+
+    const errors: LanguageError[] = await (handler as any).validateSyntax(source, '/repo/main.go');
+
+    expect(errors.map(error => error.source)).toEqual(['go', 'go']);
+    expect((handler as any).helpers.size).toBe(0);
+  });
+
+  it('should not run without the option', async () => {
+    const plain = new GoHandler();
+    (plain as any).goPath = 'go';
+    vi.spyOn(plain as any, 'runInTempModule').mockResolvedValue({ stdout: '', stderr: readFixture('partial_edit.stderr'), exitCode: 1 });
+    const fallback = vi.spyOn(plain as any, 'runParserFallback');
+
+    expect(await (plain as any).validateSyntax(source, '/repo/main.go')).toHaveLength(2);
+    expect(fallback).not.toHaveBeenCalled();
+  });
+});
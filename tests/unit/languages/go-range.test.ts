@@ -0,0 +1,132 @@
+/**
+ * Tests for recovering the ranges of Go diagnostics
+ */
+
+import { describe, it, expect, vi } from 'vitest';
+import { inferGoEndColumn, recoverGoRange, tokenizeGoLine } from '../../../src/languages/go-range.js';
+import { GoHandler } from '../../../src/languages/go-handler.js';
+
+const SOURCE = `package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func main() {
+	count := 1
+	fmt.Println(strings.ToUpper("héllo"), totl)
+	if count > 0 {
+}
+`;
+
+describe('recoverGoRange', () => {
+  it('should find the identifier named in the message', () => {
+    const line = '\tfmt.Println(strings.ToUpper("héllo"), totl)';
+
+    expect(recoverGoRange('undefined: totl', line)).toEqual({ column: 41, endColumn: 45 });
+    expect(recoverGoRange('undefined: strings', line)).toEqual({ column: 14, endColumn: 21 });
+    expect(recoverGoRange('"os" imported and not used', '\t"os"')).toEqual({ column: 2, endColumn: 6 });
+    expect(recoverGoRange('declared and not used: count', '\tcount := 1')).toEqual({ column: 2, endColumn: 7 });
+  });
+
+  it('should point past the line for unexpected newlines and cover the line otherwise', () => {
+    expect(recoverGoRange('syntax error: unexpected newline, expected comma or )', '\tf(a, b  ')).toEqual({ column: 8, endColumn: 8 });
+    expect(recoverGoRange('missing return', '}')).toEqual({ column: 1, endColumn: 2 });
+    expect(recoverGoRange('undefined: gone', '\treturn x')).toEqual({ column: 1, endColumn: 10 });
+  });
+});
+
+describe('GoHandler line-only errors', () => {
+  it('should recover columns from the analyzed source and flag them as approximate', () => {
+    const stderr = [
+      '# temp',
+      './main.go:5:2: "os" imported and not used',
+      './main.go:10: undefined: totl',
+      './main.go:13: syntax error: unexpected EOF, expected }'
+    ].join('\n');
+
+    const errors = (new GoHandler() as any).parseGoErrors(stderr, '/repo/main.go', 'main.go', undefined, SOURCE);
+
+    expect(errors.map((error: any) => [error.location, error.approximateRange])).toEqual([
+      [{ file: '/repo/main.go', line: 5, column: 2 }, undefined],
+      [{ file: '/repo/main.go', line: 10, column: 41, endLine: 10, endColumn: 45 }, true],
+      [{ file: '/repo/main.go', line: 13, column: 1, endLine: 13, endColumn: 1 }, true]
+    ]);
+  });
+
+  it('should fall back to column 1 for vet findings without source', () => {
+    const [error] = (new GoHandler() as any).parseGoVetOutput('./main.go:9: [unusedresult] result of fmt.Sprint call not used', '/repo/main.go');
+
+    expect(error.location).toEqual({ file: '/repo/main.go', line: 9, column: 1 });
+    expect(error.approximateRange).toBe(true);
+  });
+});
+
+describe('tokenizeGoLine', () => {
+  it('should split literals, comments and operators like go/scanner', () => {
+    const tokens = tokenizeGoLine('\tx := `raw str` + "a\\"b" /* c */ &^= 0x1F + .5 + \'r\' // tail');
+
+    expect(tokens.map(token => token.text)).toEqual([
+      'x', ':=', '`raw str`', '+', '"a\\"b"', '/* c */', '&^=', '0x1F', '+', '.5', '+', "'r'", '// tail'
+    ]);
+    expect(tokens[0]).toEqual({ start: 1, end: 2, text: 'x' });
+  });
+
+  it('should run unterminated literals to the end of the line', () => {
+    expect(tokenizeGoLine('s := "open').map(token => token.text)).toEqual(['s', ':=', '"open']);
+  });
+});
+
+describe('inferGoEndColumn', () => {
+  it('should underline exactly the undefined name', () => {
+    expect(inferGoEndColumn('undefined: totl', '\ttotl++', 2)).toBe(6);
+    // The compiler points at the selector of a qualified name
+    expect(inferGoEndColumn('undefined: strings.Buildr', '\tvar b strings.Buildr', 16)).toBe(22);
+    expect(inferGoEndColumn('undefined: strings', '\tvar b strings.Buildr', 8)).toBe(15);
+  });
+
+  it('should extend over the expression of a type mismatch', () => {
+    const line = '\tvar s string = f("a") + 1';
+
+    expect(inferGoEndColumn('cannot use f("a") + 1 (value of type int) as string value in variable declaration', line, 17)).toBe(27);
+    expect(inferGoEndColumn('cannot use x[0] * 2 (value of type int) as string value in argument to f', '\t_ = f(x[0] * 2)', 8)).toBe(16);
+  });
+
+  it('should cover the token at the start when the message names nothing there', () => {
+    expect(inferGoEndColumn('missing return', '}', 1)).toBe(2);
+    // Abbreviated expressions do not match the source
+    expect(inferGoEndColumn('cannot use []int{…} (value of type []int) as string value', '\tvar s string = []int{1, 2}', 17)).toBe(18);
+  });
+
+  it('should count columns in bytes', () => {
+    expect(inferGoEndColumn('undefined: héllo', 's := "é" + héllo', 13)).toBe(19);
+  });
+
+  it('should fall back to a one-column range off any token', () => {
+    expect(inferGoEndColumn('syntax error: unexpected newline', '\tf(a, ', 7)).toBe(8);
+    expect(inferGoEndColumn('undefined: x', '', 1)).toBe(2);
+  });
+});
+
+describe('GoHandler end positions', () => {
+  it('should fill in the end of every compiler and vet diagnostic', async () => {
+    const handler = new GoHandler();
+    const stderr = [
+      '# temp',
+      './main.go:10:41: undefined: totl',
+      './main.go:10:14: cannot use strings.ToUpper("héllo") (value of type string) as int value',
+      './main.go:13: syntax error: unexpected EOF, expected }'
+    ].join('\n');
+    vi.spyOn(handler as any, 'runInTempModule').mockResolvedValue({ stdout: '', stderr, exitCode: 1 });
+
+    const errors = await handler.detectErrors(SOURCE, { enableLinting: false });
+
+    expect(errors.map(error => error.location)).toEqual([
+      { file: 'temp.go', line: 10, column: 41, endLine: 10, endColumn: 45 },
+      { file: 'temp.go', line: 10, column: 14, endLine: 10, endColumn: 39 },
+      // Recovered ranges are kept as they are
+      { file: 'temp.go', line: 13, column: 1, endLine: 13, endColumn: 1 }
+    ]);
+  });
+});
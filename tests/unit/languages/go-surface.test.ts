@@ -0,0 +1,79 @@
+/**
+ * Tests for the package surface of Go files
+ */
+
+import { describe, it, expect } from 'vitest';
+import { goPackageSurface } from '../../../src/languages/go-surface.js';
+
+const STORE_GO = `//go:build linux
+
+package store
+
+import "fmt"
+
+// Store keeps items by key
+type Store struct {
+	items map[string]int
+	hook  func(string) error
+}
+
+const Limit = 10
+
+var handlers = map[string]func(){"dump": func() { fmt.Println("{") }}
+
+func (s *Store) Get(key string) (int, error) {
+	if v, ok := s.items[key]; ok {
+		return v, nil
+	}
+	return 0, fmt.Errorf("missing %q", key)
+}
+
+func Keys[T interface{ ~string }](s *Store) struct{ Keys []T } {
+	return struct{ Keys []T }{}
+}
+
+func asm(x int) int
+`;
+
+describe('goPackageSurface', () => {
+  it('should keep declarations and signatures and leave out function bodies', () => {
+    expect(goPackageSurface(STORE_GO)).toBe([
+      '//go:build linux package store ; import "fmt" ;',
+      'type Store struct { items map [ string ] int ; hook func ( string ) error ; } ;',
+      'const Limit = 10 ;',
+      'var handlers = map [ string ] func ( ) {} ;',
+      'func ( s * Store ) Get ( key string ) ( int , error ) {} ;',
+      'func Keys [ T interface { ~ string } ] ( s * Store ) struct { Keys [ ] T } {} ;',
+      'func asm ( x int ) int ;'
+    ].join(' '));
+  });
+
+  it('should ignore edits to bodies, comments and layout', () => {
+    const edited = STORE_GO
+      .replace('return 0, fmt.Errorf("missing %q", key)', 'return -1, nil // not found')
+      .replace('// Store keeps items by key', '/* Store keeps items */')
+      .replace('items map[string]int', 'items    map[string]int');
+
+    expect(goPackageSurface(edited)).toBe(goPackageSurface(STORE_GO));
+  });
+
+  it('should see edits other files of the package depend on', () => {
+    const surface = goPackageSurface(STORE_GO);
+
+    for (const [from, to] of [
+      ['Limit = 10', 'Limit = 11'],
+      ['(int, error)', '(int64, error)'],
+      ['hook  func(string) error', 'hook  func(string)'],
+      ['//go:build linux', '//go:build darwin'],
+      ['func asm(x int) int', 'func asm(x int) int\n\nfunc helper() {}']
+    ]) {
+      expect(goPackageSurface(STORE_GO.replace(from!, to!)), to).not.toBe(surface);
+    }
+  });
+
+  it('should take all of a cgo file as its surface', () => {
+    const cgo = 'package main\n\n// int add(int a, int b) { return a + b; }\nimport "C"\n\nfunc main() { _ = C.add(1, 2) }\n';
+
+    expect(goPackageSurface(cgo)).toBe(cgo);
+  });
+});
@@ -0,0 +1,191 @@
+/**
+ * Tests for go test -json failure reporting
+ */
+
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { promises as fs, readFileSync } from 'fs';
+import { tmpdir } from 'os';
+import { join } from 'path';
+import {
+  GoTestRunner,
+  TEST_FAILURE_SOURCE,
+  parseGoTestJson,
+  parseTestAssertions
+} from '../../../src/languages/go-test-runner.js';
+import { GoHandler } from '../../../src/languages/go-handler.js';
+import { runWithDetectorOptions } from '../../../src/utils/workspace-config.js';
+
+const events = readFileSync(join(__dirname, '../../fixtures/go/test_events.jsonl'), 'utf-8');
+
+const CART_TEST = `package cart
+
+import "testing"
+
+func TestTotal(t *testing.T) {
+	c := New()
+	c.Add("apple", 40)
+	c.Add("pear", 50)
+	want := 100
+	got := c.Total()
+	if got != want {
+		items := c.Items()
+		_ = items
+		t.Errorf("total = %d, want %d", got, want)
+	}
+}
+
+func TestDiscount(t *testing.T) {
+	t.Run("percent", func(t *testing.T) {
+		c := New()
+		c.Add("apple", 100)
+		c.Discount(10)
+		got := c.Saved()
+		want := 10
+		_ = want
+		if got != 10 {
+			t.Errorf("discount = %d, want %d", got, 10)
+		}
+	})
+}
+
+func TestEmpty(t *testing.T) {
+	t.Fail()
+}
+`;
+
+const SLOW_TEST = `package slow
+
+import "testing"
+
+func TestSlow(t *testing.T) {
+	select {}
+}
+`;
+
+describe('go test -json parsing', () => {
+  it('should collect failed tests and keep non-JSON lines as build output', () => {
+    const report = parseGoTestJson(events);
+
+    expect(report.failures.map(failure => failure.test)).toEqual([
+      'TestTotal',
+      'TestDiscount/percent',
+      'TestDiscount',
+      'TestEmpty'
+    ]);
+    expect(report.packageFailures.map(failure => failure.package)).toEqual([
+      'example.com/shop/cart',
+      'example.com/shop/slow',
+      'example.com/shop/broken'
+    ]);
+    expect(report.unfinished.get('example.com/shop/slow')).toEqual(['TestSlow']);
+    expect(report.buildOutput).toContain('seeding prices from testdata/prices.csv');
+    expect(report.buildOutput).toContain('broken/broken_test.go:5:2: undefined: missing');
+  });
+
+  it('should join indented continuation lines of an assertion', () => {
+    const [failure] = parseGoTestJson(events).failures;
+
+    expect(parseTestAssertions(failure!.output)).toEqual([
+      { file: 'cart_test.go', line: 14, message: 'total = 90, want 100\nitems: [apple pear]' }
+    ]);
+  });
+});
+
+describe('GoTestRunner', () => {
+  let moduleRoot: string;
+
+  beforeEach(async () => {
+    moduleRoot = await fs.mkdtemp(join(tmpdir(), 'go-test-runner-'));
+    const files: Record<string, string> = {
+      'go.mod': 'module example.com/shop\n\ngo 1.22\n',
+      'cart/cart_test.go': CART_TEST,
+      'slow/slow_test.go': SLOW_TEST,
+      'broken/broken_test.go': 'package broken\n\nfunc TestBroken() {\n\t_ = 1\n\tmissing()\n}\n'
+    };
+    const past = new Date(Date.now() - 60_000);
+    for (const [name, content] of Object.entries(files)) {
+      await fs.mkdir(join(moduleRoot, name, '..'), { recursive: true });
+      await fs.writeFile(join(moduleRoot, name), content);
+      await fs.utimes(join(moduleRoot, name), past, past);
+    }
+  });
+
+  afterEach(async () => {
+    vi.restoreAllMocks();
+    await fs.rm(moduleRoot, { recursive: true, force: true });
+  });
+
+  it('should report assertions, timeouts and test build errors at their lines from one run', async () => {
+    const run = vi.fn(async () => ({ stdout: events, stderr: '', exitCode: 1 }));
+    const runner = new GoTestRunner(run);
+
+    const cart = await runner.check(join(moduleRoot, 'cart/cart_test.go'));
+    const slow = await runner.check(join(moduleRoot, 'slow/slow_test.go'));
+    const broken = await runner.check(join(moduleRoot, 'broken/broken_test.go'));
+
+    expect(run).toHaveBeenCalledTimes(1);
+    expect(run).toHaveBeenCalledWith(['test', '-json', '-timeout=2m', './...'], moduleRoot);
+
+    expect(cart.map(error => [error.location.line, error.code, error.message])).toEqual([
+      [14, 'test-failure', 'TestTotal: total = 90, want 100\nitems: [apple pear]'],
+      [27, 'test-failure', 'TestDiscount/percent: discount = 5, want 10'],
+      // A bare t.Fail() is reported at the test function
+      [32, 'test-failure', 'TestEmpty failed']
+    ]);
+    expect(cart[0]).toMatchObject({ severity: 'error', source: TEST_FAILURE_SOURCE, analyzer: 'TestTotal' });
+
+    expect(slow).toEqual([expect.objectContaining({
+      message: 'TestSlow timed out after 1s',
+      code: 'test-timeout',
+      location: { file: join(moduleRoot, 'slow/slow_test.go'), line: 5, column: 1 }
+    })]);
+
+    expect(broken).toEqual([expect.objectContaining({
+      message: 'undefined: missing',
+      source: 'go',
+      code: 'test-build',
+      location: { file: join(moduleRoot, 'broken/broken_test.go'), line: 5, column: 2 }
+    })]);
+  });
+
+  it('should pass the configured timeout and flags', async () => {
+    const run = vi.fn(async () => ({ stdout: '', stderr: '', exitCode: 0 }));
+
+    await new GoTestRunner(run).check(join(moduleRoot, 'cart/cart_test.go'), { timeout: '30s', args: ['-short'] });
+
+    expect(run).toHaveBeenCalledWith(['test', '-json', '-timeout=30s', '-short', './...'], moduleRoot);
+  });
+
+  it('should only run tests from the Go handler when enabled', async () => {
+    const file = join(moduleRoot, 'cart/cart_test.go');
+    const run = vi.fn(async () => ({ stdout: events, stderr: '', exitCode: 1 }));
+
+    for (const enabled of [false, true]) {
+      const handler = new GoHandler({ tests: { enabled }, vet: { enabled: false } });
+      (handler as any).testRunner = new GoTestRunner(run);
+      vi.spyOn(handler as any, 'validateSyntax').mockResolvedValue([]);
+
+      const errors = await handler.detectErrors(CART_TEST, { filePath: file });
+
+      expect(errors).toHaveLength(enabled ? 3 : 0);
+    }
+    expect(run).toHaveBeenCalledTimes(1);
+  });
+
+  it('should take whether tests run from the server options, not a workspace config', async () => {
+    const file = join(moduleRoot, 'cart/cart_test.go');
+    const run = vi.fn(async () => ({ stdout: events, stderr: '', exitCode: 1 }));
+    const handler = new GoHandler({ vet: { enabled: false } });
+    (handler as any).testRunner = new GoTestRunner(run);
+    vi.spyOn(handler as any, 'validateSyntax').mockResolvedValue([]);
+
+    const errors = await runWithDetectorOptions({ tests: { enabled: true } }, () => handler.detectErrors(CART_TEST, { filePath: file }));
+    expect(errors).toEqual([]);
+    expect(run).not.toHaveBeenCalled();
+
+    // Other test settings still come from the workspace config
+    const enabled = new GoHandler({ tests: { enabled: true } });
+    const options = await runWithDetectorOptions({ tests: { enabled: false, timeout: '30s' } }, async () => (enabled as any).getTestOptions());
+    expect(options).toEqual({ enabled: true, timeout: '30s' });
+  });
+});
@@ -0,0 +1,105 @@
+/**
+ * Tests for the gopls LSP client
+ */
+
+import { describe, it, expect, afterEach, vi } from 'vitest';
+import { readFileSync } from 'fs';
+import { join } from 'path';
+import {
+  GoplsClient,
+  LspMessageReader,
+  encodeLspMessage,
+  fromLspDiagnostic,
+  type LspDiagnostic
+} from '../../../src/languages/gopls-client.js';
+import { GoHandler } from '../../../src/languages/go-handler.js';
+
+const fixturesDir = join(__dirname, '../../fixtures/go');
+const fakeGopls = join(fixturesDir, 'fake-gopls.cjs');
+const publishedDiagnostics: LspDiagnostic[] = JSON.parse(readFileSync(join(fixturesDir, 'gopls_diagnostics.json'), 'utf-8'));
+
+describe('gopls client', () => {
+  const clients: GoplsClient[] = [];
+
+  afterEach(async () => {
+    vi.restoreAllMocks();
+    await Promise.all(clients.splice(0).map(client => client.shutdown()));
+  });
+
+  function createClient(): GoplsClient {
+    // Runs `node fake-gopls.cjs serve`
+    const client = new GoplsClient(fixturesDir, { path: process.execPath, args: [fakeGopls], settleMs: 150 });
+    clients.push(client);
+    return client;
+  }
+
+  it('should split a byte stream into messages however it is chunked', () => {
+    const stream = Buffer.from(
+      encodeLspMessage({ id: 1, result: { name: 'héllo' } }) + encodeLspMessage({ method: 'exit' })
+    );
+    const reader = new LspMessageReader();
+
+    const messages = [
+      ...reader.push(stream.subarray(0, 10)),
+      ...reader.push(stream.subarray(10, 40)),
+      ...reader.push(stream.subarray(40))
+    ];
+
+    expect(messages).toEqual([
+      { jsonrpc: '2.0', id: 1, result: { name: 'héllo' } },
+      { jsonrpc: '2.0', method: 'exit' }
+    ]);
+  });
+
+  it('should translate LSP diagnostics', () => {
+    const errors = publishedDiagnostics.map(diagnostic => fromLspDiagnostic(diagnostic, '/work/api/main.go'));
+
+    expect(errors[0]).toEqual({
+      message: 'declared and not used: count',
+      severity: 'error',
+      location: { file: '/work/api/main.go', line: 6, column: 2, endLine: 6, endColumn: 7 },
+      code: 'UnusedVar',
+      source: 'gopls',
+      analyzer: 'compiler',
+      relatedInformation: [],
+      columnUnit: 'utf16'
+    });
+    expect(errors[1]).toMatchObject({
+      severity: 'warning',
+      analyzer: 'printf',
+      relatedInformation: [{ location: { file: '/work/api/format.go', line: 3, column: 6 }, message: 'format defined here' }]
+    });
+    expect(errors[2]).toMatchObject({ severity: 'hint', analyzer: 'unusedparams' });
+    expect('code' in errors[2]!).toBe(false);
+  });
+
+  it('should wait for the later publish and reuse the server across calls', async () => {
+    const client = createClient();
+    const file = join(fixturesDir, 'main.go');
+
+    const first = await client.diagnose(file, 'package main\n\nfunc main() {}\n');
+    expect(first.map(error => error.message)).toEqual([
+      'declared and not used: count',
+      'fmt.Sprintf format %d has arg name of wrong type string',
+      'unused parameter: ctx'
+    ]);
+    expect(client.isRunning()).toBe(true);
+
+    // The edited text goes out as a didChange on the same server
+    const second = await client.diagnose(file, 'package main\n');
+    expect(second).toEqual([]);
+
+    await client.shutdown();
+    expect(client.isRunning()).toBe(false);
+  });
+
+  it('should make the Go handler fall back to go build when gopls is missing', async () => {
+    const handler = new GoHandler({ gopls: { enabled: true, path: '/nonexistent/gopls' }, vet: { enabled: false } });
+    vi.spyOn(handler as any, 'validateSyntax').mockResolvedValue([]);
+
+    const errors = await handler.detectErrors('package main\n', { filePath: join(fixturesDir, 'main.go') });
+
+    expect(errors).toEqual([]);
+    expect((handler as any).goplsUnavailable).toBe(true);
+  });
+});
@@ -0,0 +1,140 @@
+/**
+ * Tests for the language handler registry and multi-handler analysis
+ */
+
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { EventEmitter } from 'events';
+import { promises as fs } from 'fs';
+import { tmpdir } from 'os';
+import { basename, join } from 'path';
+import { LanguageHandlerRegistry } from '../../../src/languages/handler-registry.js';
+import { LanguageHandlerManager } from '../../../src/languages/language-handler-manager.js';
+import type { LanguageError, LanguageHandler } from '../../../src/types/languages.js';
+
+function createHandler(language: string, extensions: string[], fileNames: string[] = []): LanguageHandler {
+  const handler = new EventEmitter() as unknown as LanguageHandler;
+
+  return Object.assign(handler, {
+    language,
+    initialize: vi.fn(async () => {}),
+    dispose: vi.fn(async () => {}),
+    isAvailable: vi.fn(async () => true),
+    isFileSupported: (filePath: string) =>
+      extensions.some(ext => filePath.endsWith(ext)) || fileNames.includes(basename(filePath)),
+    getFileExtensions: () => extensions,
+    getFileNames: () => fileNames,
+    getConfigFiles: () => [],
+    detectErrors: vi.fn(async (_source: string, options?: { filePath?: string }): Promise<LanguageError[]> => [{
+      message: `${language} finding`,
+      severity: 'warning',
+      location: { file: options?.filePath || '', line: 1, column: 1 },
+      source: language
+    }])
+  });
+}
+
+describe('LanguageHandlerRegistry', () => {
+  let registry: LanguageHandlerRegistry;
+
+  beforeEach(() => {
+    registry = new LanguageHandlerRegistry();
+  });
+
+  it('should order handlers by priority and then language id', () => {
+    registry.register(createHandler('zeta', ['.ts']));
+    registry.register(createHandler('alpha', ['.ts']));
+    registry.register(createHandler('lint', ['.ts']), { priority: 5 });
+
+    expect(registry.languages()).toEqual(['lint', 'alpha', 'zeta']);
+  });
+
+  it('should match handlers by extension or exact file name', () => {
+    registry.register(createHandler('go', ['.go']));
+    registry.register(createHandler('gomod', [], ['go.mod']));
+
+    expect(registry.handlersFor('/repo/main.go').map(h => h.language)).toEqual(['go']);
+    expect(registry.handlersFor('/repo/go.mod').map(h => h.language)).toEqual(['gomod']);
+    expect(registry.handlersFor('/repo/README.md')).toEqual([]);
+  });
+
+  it('should replace a handler registered under the same language', () => {
+    const first = createHandler('go', ['.go']);
+    const second = createHandler('go', ['.go']);
+    registry.register(first);
+    registry.register(second);
+
+    expect(registry.size).toBe(1);
+    expect(registry.get('go')).toBe(second);
+    expect(registry.unregister('go')).toBe(second);
+    expect(registry.has('go')).toBe(false);
+  });
+});
+
+describe('LanguageHandlerManager registration', () => {
+  let manager: LanguageHandlerManager;
+  let directory: string;
+
+  beforeEach(async () => {
+    manager = new LanguageHandlerManager({ enabledLanguages: [] });
+    directory = await fs.mkdtemp(join(tmpdir(), 'handler-registry-'));
+  });
+
+  afterEach(async () => {
+    await manager.dispose();
+    await fs.rm(directory, { recursive: true, force: true });
+  });
+
+  it('should run every matching handler and merge results deterministically', async () => {
+    await manager.registerHandler(createHandler('typescript', ['.ts']));
+    await manager.registerHandler(createHandler('go', ['.go']));
+    await manager.registerHandler(createHandler('custom-lint', ['.ts', '.go']));
+
+    await fs.writeFile(join(directory, 'main.go'), 'package main\n');
+    await fs.writeFile(join(directory, 'app.ts'), 'export {};\n');
+    await fs.writeFile(join(directory, 'notes.txt'), 'ignored\n');
+
+    const errors = await manager.analyzePath(directory);
+
+    expect(errors.map(error => `${basename(error.location.file)}:${error.source}`)).toEqual([
+      'app.ts:custom-lint',
+      'app.ts:typescript',
+      'main.go:custom-lint',
+      'main.go:go'
+    ]);
+  });
+
+  it('should restrict analysis to an explicit language', async () => {
+    await manager.registerHandler(createHandler('typescript', ['.ts']));
+    await manager.registerHandler(createHandler('custom-lint', ['.ts']));
+    const file = join(directory, 'app.ts');
+    await fs.writeFile(file, 'export {};\n');
+
+    const errors = await manager.analyzeFile(file, 'typescript');
+
+    expect(errors.map(error => error.source)).toEqual(['typescript']);
+  });
+
+  it('should keep results from other handlers when one fails', async () => {
+    const broken = createHandler('broken', ['.ts']);
+    vi.mocked(broken.detectErrors).mockRejectedValue(new Error('crashed'));
+    await manager.registerHandler(broken);
+    await manager.registerHandler(createHandler('typescript', ['.ts']));
+    const file = join(directory, 'app.ts');
+    await fs.writeFile(file, 'export {};\n');
+
+    const errors = await manager.analyzeFile(file);
+
+    expect(errors.map(error => error.source)).toEqual(['toolchain', 'typescript']);
+    expect(errors[0]).toMatchObject({ message: 'broken detector failed: crashed', severity: 'error', location: { file } });
+  });
+
+  it('should dispose handlers when unregistered', async () => {
+    const handler = createHandler('custom-lint', ['.ts']);
+    await manager.registerHandler(handler);
+
+    expect(manager.detectLanguages('/repo/app.ts')).toEqual(['custom-lint']);
+    expect(await manager.unregisterHandler('custom-lint')).toBe(true);
+    expect(handler.dispose).toHaveBeenCalled();
+    expect(manager.detectLanguage('/repo/app.ts')).toBeUndefined();
+  });
+});
@@ -0,0 +1,141 @@
+/**
+ * Tests for the javac-based Java handler
+ */
+
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { readFileSync, promises as fs } from 'fs';
+import { tmpdir } from 'os';
+import { join } from 'path';
+import { JavaHandler, findSourceRoot, parseJavacOutput } from '../../../src/languages/java-handler.js';
+import { ToolNotFoundError } from '../../../src/utils/errors.js';
+
+const readFixture = (name: string) => readFileSync(join(__dirname, '../../fixtures/java', name), 'utf-8');
+
+describe('JavaHandler', () => {
+  let handler: JavaHandler;
+
+  beforeEach(() => {
+    handler = new JavaHandler({ javac: { classpath: ['/libs/a.jar', '/libs/b.jar'] } });
+    (handler as any).javacPath = 'javac';
+  });
+
+  describe('parseJavacOutput', () => {
+    const diagnostics = parseJavacOutput(readFixture('OrderService.stderr'), '/work/api');
+    const service = '/work/api/src/main/java/com/example/orders/OrderService.java';
+
+    it('should recover the column from the caret line', () => {
+      expect(diagnostics).toHaveLength(4);
+      expect(diagnostics[1]).toMatchObject({ file: service, line: 22, column: 26, kind: 'error' });
+      expect(diagnostics[2]).toMatchObject({ line: 30, column: 22, message: "';' expected" });
+    });
+
+    it('should append symbol details and keep lint categories', () => {
+      expect(diagnostics[0]).toMatchObject({ kind: 'warning', lint: 'rawtypes', column: 13 });
+      expect(diagnostics[0]!.message.startsWith('found raw type: List\nmissing type arguments')).toBe(true);
+      expect(diagnostics[1]!.message).toBe([
+        'cannot find symbol',
+        'symbol:   method findByCustomer(long)',
+        'location: variable repository of type OrderRepository'
+      ].join('\n'));
+    });
+
+    it('should drop the error and warning totals', () => {
+      expect(diagnostics.some(diagnostic => /\d+ (errors|warning)/.test(diagnostic.message))).toBe(false);
+      expect(diagnostics[3]!.message).toBe('class Orders is public, should be declared in a file named Orders.java');
+    });
+  });
+
+  describe('findSourceRoot', () => {
+    it('should strip the package path from the file directory', () => {
+      const file = '/work/api/src/main/java/com/example/orders/OrderService.java';
+
+      expect(findSourceRoot(file, 'package com.example.orders;\n')).toBe('/work/api/src/main/java');
+      expect(findSourceRoot(file, 'package com.other;\n')).toBeUndefined();
+      expect(findSourceRoot('/work/Main.java', 'class Main {}\n')).toBe('/work');
+    });
+  });
+
+  describe('detectErrors', () => {
+    let root: string;
+
+    beforeEach(async () => {
+      root = await fs.mkdtemp(join(tmpdir(), 'java-handler-test-'));
+    });
+
+    afterEach(async () => {
+      await fs.rm(root, { recursive: true, force: true });
+    });
+
+    it('should compile a saved package in one javac run with the configured class path', async () => {
+      const packageDir = join(root, 'com', 'example');
+      const first = join(packageDir, 'A.java');
+      const second = join(packageDir, 'B.java');
+      const sourceA = 'package com.example;\nclass A { B b = new B(1); }\n';
+      const sourceB = 'package com.example;\nclass B { int x = "s"; }\n';
+      await fs.mkdir(packageDir, { recursive: true });
+      await fs.writeFile(first, sourceA);
+      await fs.writeFile(second, sourceB);
+
+      const runCommand = vi.spyOn(handler as any, 'runCommand').mockResolvedValue({
+        stdout: '',
+        stderr: [
+          `${first}:2: error: constructor B in class B cannot be applied to given types;`,
+          'class A { B b = new B(1); }',
+          '                ^',
+          `${second}:2: error: incompatible types: String cannot be converted to int`,
+          'class B { int x = "s"; }',
+          '                  ^',
+          '2 errors'
+        ].join('\n'),
+        exitCode: 1
+      });
+
+      const [errorsA, errorsB] = await Promise.all([
+        handler.detectErrors(sourceA, { filePath: first }),
+        handler.detectErrors(sourceB, { filePath: second })
+      ]);
+
+      expect(runCommand).toHaveBeenCalledTimes(1);
+      const args = runCommand.mock.calls[0]![1] as string[];
+      expect(args).toEqual(expect.arrayContaining(['-Xlint:all', '-proc:none', '-classpath', '/libs/a.jar:/libs/b.jar', '-sourcepath', root, first, second]));
+      expect(errorsA).toHaveLength(1);
+      expect(errorsA[0]).toMatchObject({ location: { file: first, line: 2, column: 17 }, severity: 'error' });
+      expect(errorsB[0]).toMatchObject({ location: { file: second, line: 2, column: 19 } });
+    });
+
+    it('should name the temporary file after the public class of an unsaved snippet', async () => {
+      const source = 'public class Greeter {\n  void hi() { undefinedCall(); }\n}\n';
+      const runCommand = vi.spyOn(handler as any, 'runCommand').mockImplementation(async (...args: unknown[]) => {
+        const argv = args[1] as string[];
+        return { stdout: '', stderr: `${argv[argv.length - 1]}:2: error: cannot find symbol\n`, exitCode: 1 };
+      });
+
+      const errors = await handler.detectErrors(source);
+
+      expect((runCommand.mock.calls[0]![1] as string[]).at(-1)!.endsWith('Greeter.java')).toBe(true);
+      expect(errors).toHaveLength(1);
+      expect(errors[0]).toMatchObject({ message: 'cannot find symbol', location: { file: 'Greeter.java', line: 2 } });
+    });
+
+    it('should report javac failures without positions as a toolchain error', async () => {
+      vi.spyOn(handler as any, 'runCommand').mockResolvedValue({
+        stdout: '',
+        stderr: 'error: invalid target release: 99\n',
+        exitCode: 2
+      });
+
+      const errors = await handler.detectErrors('class Main {}\n', { filePath: join(root, 'Main.java') });
+
+      expect(errors).toHaveLength(1);
+      expect(errors[0]).toMatchObject({ source: 'toolchain', message: 'javac failed: error: invalid target release: 99' });
+    });
+  });
+
+  describe('missing toolchain', () => {
+    it('should fail initialization with a typed error when javac is not on PATH', async () => {
+      vi.spyOn(handler as any, 'findExecutable').mockResolvedValue(undefined);
+
+      await expect(handler.initialize()).rejects.toBeInstanceOf(ToolNotFoundError);
+    });
+  });
+});
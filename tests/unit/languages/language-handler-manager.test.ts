@@ -0,0 +1,535 @@
+/**
+ * Tests for the language handler manager
+ */
+
+import { describe, it, expect, vi, afterEach, beforeEach } from 'vitest';
+import { EventEmitter } from 'events';
+import { promises as fs } from 'fs';
+import { tmpdir } from 'os';
+import { basename, join } from 'path';
+import { LanguageHandlerManager } from '../../../src/languages/language-handler-manager.js';
+import { GoHandler } from '../../../src/languages/go-handler.js';
+import { JavaHandler } from '../../../src/languages/java-handler.js';
+import { ToolNotFoundError } from '../../../src/utils/errors.js';
+import { SupportedLanguage, type LanguageError, type LanguageHandler } from '../../../src/types/languages.js';
+
+function customHandler(language: string, available: boolean): LanguageHandler {
+  return Object.assign(new EventEmitter() as unknown as LanguageHandler, {
+    language,
+    initialize: vi.fn(async () => {}),
+    dispose: vi.fn(async () => {}),
+    isAvailable: vi.fn(async () => available),
+    isFileSupported: (filePath: string) => filePath.endsWith('.tf'),
+    getFileExtensions: () => ['.tf'],
+    getConfigFiles: () => [],
+    detectErrors: vi.fn(async (): Promise<LanguageError[]> => [])
+  });
+}
+
+describe('LanguageHandlerManager', () => {
+  afterEach(() => {
+    vi.restoreAllMocks();
+  });
+
+  describe('getCapabilities', () => {
+    it('should probe toolchain versions and report missing tools without failing', async () => {
+      vi.spyOn(GoHandler.prototype as any, 'runCommand').mockResolvedValue({
+        stdout: 'go version go1.22.3 linux/amd64\n',
+        stderr: '',
+        exitCode: 0
+      });
+      vi.spyOn(JavaHandler.prototype as any, 'runCommand').mockRejectedValue(new ToolNotFoundError('javac'));
+
+      const manager = new LanguageHandlerManager({
+        enabledLanguages: [SupportedLanguage.GO, SupportedLanguage.JAVA],
+        timeouts: { go: 120000 },
+        defaultOptions: { vet: { enabled: false } }
+      });
+      await manager.registerHandler(customHandler('terraform', true));
+
+      const capabilities = await manager.getCapabilities();
+      const byLanguage = new Map(capabilities.map(capability => [capability.language, capability]));
+
+      expect(byLanguage.get('go')).toMatchObject({
+        registered: false,
+        available: true,
+        tool: 'go',
+        version: 'go version go1.22.3 linux/amd64',
+        extensions: ['.go'],
+        config: { timeoutMs: 120000, options: { vet: { enabled: false } } }
+      });
+      expect(byLanguage.get('java')).toMatchObject({
+        available: false,
+        tool: 'javac',
+        reason: 'javac not found on PATH',
+        config: { timeoutMs: 30000 }
+      });
+      expect(byLanguage.get('terraform')).toMatchObject({ registered: true, available: true, tool: 'terraform' });
+    });
+
+    it('should report why an installed toolchain has no handler', async () => {
+      vi.spyOn(GoHandler.prototype as any, 'runCommand').mockResolvedValue({ stdout: 'go version go1.22.3\n', stderr: '', exitCode: 0 });
+      vi.spyOn(GoHandler.prototype as any, 'findExecutable').mockResolvedValue(undefined);
+      vi.spyOn(GoHandler.prototype as any, 'checkAvailability').mockResolvedValue(true);
+
+      const manager = new LanguageHandlerManager({ enabledLanguages: [SupportedLanguage.GO] });
+      await manager.initialize();
+
+      const [go] = await manager.getCapabilities();
+
+      expect(go).toMatchObject({
+        registered: false,
+        available: false,
+        reason: 'Go compiler not found. Please install Go to use Go error detection.'
+      });
+    });
+  });
+
+  describe('setDetectorEnabled', () => {
+    let directory: string;
+
+    afterEach(async () => {
+      await fs.rm(directory, { recursive: true, force: true });
+    });
+
+    function reporting(language: string): LanguageHandler {
+      const handler = customHandler(language, true);
+      handler.detectErrors = vi.fn(async (_source, options): Promise<LanguageError[]> => [{
+        message: `${language} finding`,
+        severity: 'error',
+        location: { file: options!.filePath!, line: 1, column: 1 },
+        source: language
+      }]);
+      return handler;
+    }
+
+    it('should skip a disabled detector in later calls while in-flight calls keep their state', async () => {
+      directory = await fs.mkdtemp(join(tmpdir(), 'detector-toggle-'));
+      await fs.writeFile(join(directory, 'a.tf'), 'resource {}\n');
+      await fs.writeFile(join(directory, 'b.tf'), 'resource {}\n');
+
+      const manager = new LanguageHandlerManager({ enabledLanguages: [], concurrency: 1 });
+      const terraform = reporting('terraform');
+      const tflint = reporting('tflint');
+      await manager.registerHandler(terraform);
+      await manager.registerHandler(tflint);
+
+      // Toggled while the first file is analyzed; the second file of the same call still gets tflint
+      vi.mocked(terraform.detectErrors).mockImplementationOnce(async (_source, options) => {
+        expect(manager.setDetectorEnabled('tflint', false)).toBe(true);
+        return [{ message: 'terraform finding', severity: 'error', location: { file: options!.filePath!, line: 1, column: 1 }, source: 'terraform' }];
+      });
+
+      const first = await manager.analyzePath(directory);
+      expect(first.map(error => error.source)).toEqual(['terraform', 'tflint', 'terraform', 'tflint']);
+
+      vi.mocked(tflint.detectErrors).mockClear();
+      const second = await manager.analyzePath(directory);
+      expect(second.map(error => error.source)).toEqual(['terraform', 'terraform']);
+      expect(tflint.detectErrors).not.toHaveBeenCalled();
+      expect(await manager.analyzeFile(join(directory, 'a.tf'), 'tflint')).toEqual([]);
+
+      expect(manager.setDetectorEnabled('tflint', false)).toBe(false);
+      manager.setDetectorEnabled('tflint', true);
+      expect((await manager.analyzePath(directory)).map(error => error.source)).toContain('tflint');
+    });
+
+    it('should report a disabled detector in capabilities without probing it', async () => {
+      directory = await fs.mkdtemp(join(tmpdir(), 'detector-toggle-'));
+      const manager = new LanguageHandlerManager({ enabledLanguages: [] });
+      const terraform = customHandler('terraform', true);
+      await manager.registerHandler(terraform);
+      vi.mocked(terraform.isAvailable).mockClear();
+
+      manager.setDetectorEnabled('terraform', false);
+      const [capability] = await manager.getCapabilities();
+
+      expect(capability).toMatchObject({
+        language: 'terraform',
+        detectorEnabled: false,
+        available: false,
+        reason: 'The terraform detector is disabled'
+      });
+      expect(terraform.isAvailable).not.toHaveBeenCalled();
+      expect(() => manager.setDetectorEnabled('cobol', false)).toThrow('Unknown language: cobol');
+    });
+  });
+
+  describe('request coalescing', () => {
+    let directory: string;
+
+    afterEach(async () => {
+      await fs.rm(directory, { recursive: true, force: true });
+    });
+
+    // Each run reads the file, records itself in runs.log and reports the contents after a pause
+    async function countingWorkspace(): Promise<string> {
+      directory = await fs.realpath(await fs.mkdtemp(join(tmpdir(), 'coalesce-')));
+      await fs.writeFile(join(directory, '.errordebug.yaml'), [
+        'commands:',
+        '  slowlint:',
+        `    command: [sh, -c, 'contents=$(cat {file}); echo run >> runs.log; sleep 0.3; echo "{relativeFile}:1:1: error $contents"']`,
+        '    extensions: [.rego]',
+        `    pattern: '^(?<file>[^:]+):(?<line>\\d+):(?<col>\\d+): (?<severity>\\w+) (?<message>.+)$'`,
+        ''
+      ].join('\n'));
+      const file = join(directory, 'main.rego');
+      await fs.writeFile(file, 'version one\n');
+      return file;
+    }
+
+    const runCount = async () => (await fs.readFile(join(directory, 'runs.log'), 'utf-8')).trim().split('\n').length;
+
+    it('should spawn a single tool process for concurrent requests for the same file', async () => {
+      const file = await countingWorkspace();
+      const manager = new LanguageHandlerManager({ enabledLanguages: [], workspaceRoots: [directory], commands: { enabled: true } });
+
+      try {
+        const results = await Promise.all(Array.from({ length: 5 }, () => manager.analyzeFile(file)));
+
+        expect(await runCount()).toBe(1);
+        expect(results.map(errors => errors.map(error => error.message))).toEqual(Array(5).fill(['version one']));
+        expect(manager.getCoalescingStats()).toMatchObject({ runs: 1, coalesced: 4, inFlight: 0 });
+
+        // Callers get copies, so one cannot change what the others see
+        results[0]![0]!.message = 'changed';
+        expect(results[1]![0]!.message).toBe('version one');
+      } finally {
+        await manager.dispose();
+      }
+    });
+
+    it('should give a request for a changed file a fresh run instead of the one in flight', async () => {
+      const file = await countingWorkspace();
+      const manager = new LanguageHandlerManager({ enabledLanguages: [], workspaceRoots: [directory], commands: { enabled: true } });
+
+      try {
+        const stale = manager.analyzeFile(file);
+        await vi.waitFor(async () => expect(await runCount()).toBe(1));
+        await fs.writeFile(file, 'version two\n');
+        const fresh = await manager.analyzeFile(file);
+
+        expect((await stale).map(error => error.message)).toEqual(['version one']);
+        expect(fresh.map(error => error.message)).toEqual(['version two']);
+        expect(await runCount()).toBe(2);
+      } finally {
+        await manager.dispose();
+      }
+    });
+
+    it('should keep the shared run going when only one of its callers cancels', async () => {
+      const file = await countingWorkspace();
+      const manager = new LanguageHandlerManager({ enabledLanguages: [], workspaceRoots: [directory], commands: { enabled: true } });
+      const controller = new AbortController();
+
+      try {
+        const canceled = manager.analyzeFile(file, undefined, { signal: controller.signal });
+        const kept = manager.analyzeFile(file);
+        await vi.waitFor(async () => expect(await runCount()).toBe(1));
+        controller.abort();
+
+        await expect(canceled).rejects.toThrow('Analysis canceled');
+        expect((await kept).map(error => error.message)).toEqual(['version one']);
+      } finally {
+        await manager.dispose();
+      }
+    });
+
+    it('should wait for the minimum interval before analyzing the same file again', async () => {
+      const file = await countingWorkspace();
+      const manager = new LanguageHandlerManager({ enabledLanguages: [], workspaceRoots: [directory], commands: { enabled: true }, minAnalysisIntervalMs: 1000 });
+
+      try {
+        const started = Date.now();
+        await manager.analyzeFile(file);
+        await fs.writeFile(file, 'version two\n');
+        await manager.analyzeFile(file);
+
+        expect(Date.now() - started).toBeGreaterThanOrEqual(990);
+        expect(await runCount()).toBe(2);
+      } finally {
+        await manager.dispose();
+      }
+    });
+  });
+
+  describe('concurrent requests', () => {
+    let directory: string;
+
+    afterEach(async () => {
+      await fs.rm(directory, { recursive: true, force: true });
+    });
+
+    // Reports one finding naming the detector and file, after a pause set per call
+    function naming(language: string, delay: () => number): LanguageHandler {
+      const handler = customHandler(language, true);
+      handler.detectErrors = vi.fn(async (_source, options): Promise<LanguageError[]> => {
+        await new Promise(resolve => setTimeout(resolve, delay()));
+        return [{
+          message: `${language} ${basename(options!.filePath!)}`,
+          severity: 'error',
+          location: { file: options!.filePath!, line: 1, column: 1 },
+          source: language
+        }];
+      });
+      return handler;
+    }
+
+    it('should not cache a result whose file was invalidated while it was computed', async () => {
+      directory = await fs.realpath(await fs.mkdtemp(join(tmpdir(), 'concurrent-')));
+      const file = join(directory, 'main.tf');
+      await fs.writeFile(file, 'resource {}\n');
+      const manager = new LanguageHandlerManager({ enabledLanguages: [] });
+      const terraform = naming('terraform', () => 20);
+      await manager.registerHandler(terraform);
+
+      try {
+        const stale = manager.analyzeFile(file);
+        await vi.waitFor(() => expect(terraform.detectErrors).toHaveBeenCalledTimes(1));
+        manager.clearCache();
+
+        // Neither joins the run started before the clear nor gets its result from the cache
+        await Promise.all([stale, manager.analyzeFile(file)]);
+        await manager.analyzeFile(file);
+
+        expect(terraform.detectErrors).toHaveBeenCalledTimes(2);
+      } finally {
+        await manager.dispose();
+      }
+    });
+
+    it('should keep the results of interleaved requests from different clients apart', async () => {
+      directory = await fs.realpath(await fs.mkdtemp(join(tmpdir(), 'concurrent-')));
+      const files = await Promise.all(Array.from({ length: 8 }, async (_, index) => {
+        const file = join(directory, `module${index}.tf`);
+        await fs.writeFile(file, `resource "r${index}" {}\n`);
+        return file;
+      }));
+      let calls = 0;
+      const delay = () => (calls++ * 7) % 13;
+      const manager = new LanguageHandlerManager({ enabledLanguages: [], concurrency: 4 });
+      await manager.registerHandler(naming('terraform', delay));
+      await manager.registerHandler(naming('tflint', delay));
+
+      try {
+        const requests = Array.from({ length: 200 }, async (_, index) => {
+          const file = files[index % files.length]!;
+          await new Promise(resolve => setTimeout(resolve, index % 5));
+          switch (index % 10) {
+            case 3:
+              manager.setDetectorEnabled('tflint', index % 20 !== 3);
+              return;
+            case 6:
+              manager.clearCache();
+              return;
+            case 8:
+              expect(manager.getCacheStats().entries).toBeLessThanOrEqual(files.length);
+              return;
+            case 9: {
+              const errors = await manager.analyzePath(directory);
+              expect(new Set(errors.map(error => error.location.file))).toEqual(new Set(files));
+              return;
+            }
+            default: {
+              const errors = await manager.analyzeFile(file);
+              expect(errors.map(error => error.location.file)).toEqual(errors.map(() => file));
+              expect(errors.map(error => error.message)).toContain(`terraform ${basename(file)}`);
+            }
+          }
+        });
+        await Promise.all(requests);
+
+        manager.setDetectorEnabled('tflint', true);
+        for (const file of files) {
+          expect((await manager.analyzeFile(file)).map(error => error.message).sort())
+            .toEqual([`terraform ${basename(file)}`, `tflint ${basename(file)}`]);
+        }
+        expect(manager.getCoalescingStats().inFlight).toBe(0);
+      } finally {
+        await manager.dispose();
+      }
+    });
+  });
+
+  describe('analyzeBatch', () => {
+    let directory: string;
+
+    afterEach(async () => {
+      await fs.rm(directory, { recursive: true, force: true });
+    });
+
+    it('should group files by module and return the errors of each path in request order', async () => {
+      directory = await fs.realpath(await fs.mkdtemp(join(tmpdir(), 'batch-')));
+      for (const file of ['a/module.hcl', 'a/x.tf', 'a/sub/y.tf', 'b/module.hcl', 'b/z.tf']) {
+        await fs.mkdir(join(directory, file, '..'), { recursive: true });
+        await fs.writeFile(join(directory, file), 'resource {}\n');
+      }
+
+      const manager = new LanguageHandlerManager({ enabledLanguages: [] });
+      const handler = customHandler('terraform', true);
+      handler.getConfigFiles = () => ['module.hcl'];
+      handler.detectErrors = vi.fn(async (_source, options): Promise<LanguageError[]> => [{
+        message: 'finding',
+        severity: 'error',
+        location: { file: options!.filePath!, line: 1, column: 1 },
+        source: 'terraform'
+      }]);
+      await manager.registerHandler(handler);
+
+      const batch = await manager.analyzeBatch([join(directory, 'b', 'z.tf'), join(directory, 'a'), join(directory, 'a', 'x.tf')]);
+
+      expect(batch.results.map(result => [result.path, result.errors.map(error => error.location.file)])).toEqual([
+        [join(directory, 'b', 'z.tf'), [join(directory, 'b', 'z.tf')]],
+        [join(directory, 'a'), [join(directory, 'a', 'sub', 'y.tf'), join(directory, 'a', 'x.tf')]],
+        [join(directory, 'a', 'x.tf'), [join(directory, 'a', 'x.tf')]]
+      ]);
+      expect(batch.groups).toEqual([
+        { language: 'terraform', directory: join(directory, 'b'), files: [join(directory, 'b', 'z.tf')] },
+        { language: 'terraform', directory: join(directory, 'a'), files: [join(directory, 'a', 'sub', 'y.tf'), join(directory, 'a', 'x.tf')] }
+      ]);
+      // x.tf is listed twice but analyzed once
+      expect(handler.detectErrors).toHaveBeenCalledTimes(3);
+    });
+  });
+
+  describe('analyzeChange', () => {
+    let directory: string;
+    let manager: LanguageHandlerManager;
+    let handler: LanguageHandler;
+    const analyzed = () => (handler.detectErrors as ReturnType<typeof vi.fn>).mock.calls
+      .map(([, options]) => basename((options as { filePath: string }).filePath));
+
+    beforeEach(async () => {
+      directory = await fs.realpath(await fs.mkdtemp(join(tmpdir(), 'change-')));
+      await fs.writeFile(join(directory, 'a.tf'), 'decl x\nbody 1\n');
+      await fs.writeFile(join(directory, 'b.tf'), 'decl y\n');
+      await fs.writeFile(join(directory, 'notes.md'), 'not a sibling\n');
+
+      manager = new LanguageHandlerManager({ enabledLanguages: [] });
+      handler = customHandler('terraform', true);
+      // Everything but the body lines is visible to the other files
+      handler.getPackageSurface = (source: string) => source.split('\n').filter(line => !line.startsWith('body')).join('\n');
+      handler.detectErrors = vi.fn(async (source, options): Promise<LanguageError[]> => [{
+        message: source.split('\n')[0]!,
+        severity: 'error',
+        location: { file: options!.filePath!, line: 1, column: 1 },
+        source: 'terraform'
+      }]);
+      await manager.registerHandler(handler);
+      await manager.analyzeFile(join(directory, 'b.tf'));
+      (handler.detectErrors as ReturnType<typeof vi.fn>).mockClear();
+    });
+
+    afterEach(async () => {
+      await manager.dispose();
+      await fs.rm(directory, { recursive: true, force: true });
+    });
+
+    it('should re-run the package on the first saved change and reuse siblings after body edits', async () => {
+      const first = await manager.analyzeChange(join(directory, 'a.tf'), { allFiles: true });
+
+      expect(first).toMatchObject({
+        packageDir: directory,
+        siblings: [join(directory, 'b.tf')],
+        siblingsAffected: true,
+        invalidated: [join(directory, 'b.tf')]
+      });
+      expect(analyzed()).toEqual(['a.tf', 'b.tf']);
+
+      (handler.detectErrors as ReturnType<typeof vi.fn>).mockClear();
+      await fs.writeFile(join(directory, 'a.tf'), 'decl x\nbody 2\n');
+      const bodyEdit = await manager.analyzeChange(join(directory, 'a.tf'), { allFiles: true });
+
+      expect(bodyEdit).toMatchObject({ siblingsAffected: false, invalidated: [] });
+      expect(bodyEdit.errors.map(error => basename(error.location.file))).toEqual(['a.tf', 'b.tf']);
+      expect(analyzed()).toEqual(['a.tf']);
+    });
+
+    it('should drop sibling results when declarations change and return only the changed file by default', async () => {
+      await manager.analyzeChange(join(directory, 'a.tf'));
+      (handler.detectErrors as ReturnType<typeof vi.fn>).mockClear();
+
+      await fs.writeFile(join(directory, 'a.tf'), 'decl z\nbody 1\n');
+      const change = await manager.analyzeChange(join(directory, 'a.tf'));
+
+      expect(change.siblingsAffected).toBe(true);
+      expect(change.errors.map(error => error.message)).toEqual(['decl z']);
+      expect(analyzed()).toEqual(['a.tf']);
+
+      await manager.analyzeFile(join(directory, 'b.tf'));
+      expect(analyzed()).toEqual(['a.tf', 'b.tf']);
+    });
+
+    it('should analyze unsaved content against the file on disk', async () => {
+      const bodyEdit = await manager.analyzeChange(join(directory, 'a.tf'), { content: 'decl x\nbody 3\n', allFiles: true });
+
+      expect(bodyEdit).toMatchObject({ siblingsAffected: false, invalidated: [] });
+      expect(bodyEdit.errors.map(error => [basename(error.location.file), error.message])).toEqual([
+        ['a.tf', 'decl x'],
+        ['b.tf', 'decl y']
+      ]);
+      // b.tf comes from the cache
+      expect(analyzed()).toEqual(['a.tf']);
+
+      const declEdit = await manager.analyzeChange(join(directory, 'a.tf'), { content: 'decl w\n', allFiles: true });
+
+      expect(declEdit).toMatchObject({ siblingsAffected: true, invalidated: [] });
+      expect(declEdit.errors.map(error => [error.location.file, error.message])).toEqual([
+        [join(directory, 'a.tf'), 'decl w'],
+        [join(directory, 'b.tf'), 'decl y']
+      ]);
+      expect(analyzed()).toEqual(['a.tf', 'a.tf', 'b.tf']);
+      expect(await fs.readFile(join(directory, 'a.tf'), 'utf-8')).toBe('decl x\nbody 1\n');
+    });
+  });
+
+  describe('package inputs', () => {
+    let directory: string;
+
+    afterEach(async () => {
+      await fs.rm(directory, { recursive: true, force: true });
+    });
+
+    it('should not answer from the cache once a sibling or the module file changed', async () => {
+      directory = await fs.realpath(await fs.mkdtemp(join(tmpdir(), 'package-inputs-')));
+      await fs.writeFile(join(directory, 'go.mod'), 'module example.com/shop\n\ngo 1.22\n');
+      await fs.writeFile(join(directory, 'a.go'), 'package shop\n\nfunc Bar() { Foo() }\n');
+      await fs.writeFile(join(directory, 'b.go'), 'package shop\n\nfunc Foo() {}\n');
+
+      const manager = new LanguageHandlerManager({ enabledLanguages: [], workspaceRoots: [directory] });
+      const handler = customHandler('go', true);
+      handler.isFileSupported = (filePath: string) => filePath.endsWith('.go');
+      handler.getFileExtensions = () => ['.go'];
+      handler.getFileNames = () => ['go.mod'];
+      handler.getConfigFiles = () => ['go.mod', 'go.sum'];
+      // Declarations are what the other files see
+      handler.getPackageSurface = (source: string) => source.split('\n').map(line => line.replace(/\{.*\}/, '{}')).join('\n');
+      // Like the compiler, reports calls to functions no file of the package declares
+      handler.detectErrors = vi.fn(async (source, options): Promise<LanguageError[]> => {
+        const files = await Promise.all(['a.go', 'b.go'].map(name => fs.readFile(join(directory, name), 'utf-8')));
+        return source.includes('Foo()') && !files.some(file => file.includes('func Foo'))
+          ? [{ message: 'undefined: Foo', severity: 'error', location: { file: options!.filePath!, line: 3, column: 14 }, source: 'go' }]
+          : [];
+      });
+      await manager.registerHandler(handler);
+
+      try {
+        expect(await manager.analyzeFile(join(directory, 'a.go'))).toEqual([]);
+
+        // A body edit in a sibling keeps the cached result
+        await fs.writeFile(join(directory, 'b.go'), 'package shop\n\nfunc Foo() { println() }\n');
+        expect(await manager.analyzeFile(join(directory, 'a.go'))).toEqual([]);
+        expect(handler.detectErrors).toHaveBeenCalledTimes(1);
+
+        await fs.writeFile(join(directory, 'b.go'), 'package shop\n');
+        expect((await manager.analyzeFile(join(directory, 'a.go'))).map(error => error.message)).toEqual(['undefined: Foo']);
+
+        await fs.writeFile(join(directory, 'go.sum'), 'example.com/lib v1.0.0 h1:abc=\n');
+        await manager.analyzeFile(join(directory, 'a.go'));
+        expect(handler.detectErrors).toHaveBeenCalledTimes(3);
+      } finally {
+        await manager.dispose();
+      }
+    });
+  });
+});
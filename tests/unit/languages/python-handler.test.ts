@@ -0,0 +1,184 @@
+/**
+ * Tests for Python language handler
+ */
+
+import { describe, it, expect, beforeEach, vi } from 'vitest';
+import { existsSync, promises as fs, readFileSync } from 'fs';
+import { tmpdir } from 'os';
+import { join } from 'path';
+import { PythonHandler } from '../../../src/languages/python-handler.js';
+import { SupportedLanguage } from '../../../src/types/languages.js';
+
+const fixturesDir = join(__dirname, '../../fixtures/python');
+const readFixture = (name: string) => readFileSync(join(fixturesDir, name), 'utf-8');
+
+describe('PythonHandler', () => {
+  let handler: PythonHandler;
+
+  beforeEach(() => {
+    handler = new PythonHandler();
+    (handler as any).pythonPath = '/usr/bin/python3';
+  });
+
+  describe('Basic Properties', () => {
+    it('should have correct language', () => {
+      expect(handler.language).toBe(SupportedLanguage.PYTHON);
+    });
+
+    it('should support Python files', () => {
+      expect(handler.isFileSupported('main.py')).toBe(true);
+      expect(handler.isFileSupported('stubs.pyi')).toBe(true);
+      expect(handler.isFileSupported('main.go')).toBe(false);
+    });
+  });
+
+  describe('Pyflakes Parsing', () => {
+    it('should parse file:line:col output', () => {
+      const output = "/tmp/pyflakes-check-1.py:6:23: undefined name 'nme'\n" +
+        "/tmp/pyflakes-check-1.py:2:1: 'os' imported but unused\n";
+
+      const errors = (handler as any).parsePyflakesOutput(output, 'undefined_name.py');
+      expect(errors).toHaveLength(2);
+      expect(errors[0]).toMatchObject({
+        message: "undefined name 'nme'",
+        severity: 'error',
+        location: { file: 'undefined_name.py', line: 6, column: 23 },
+        code: 'pyflakes'
+      });
+      expect(errors[1].severity).toBe('warning');
+    });
+
+    it('should parse output without column numbers', () => {
+      const output = "/tmp/pyflakes-check-1.py:6: undefined name 'nme'\n";
+
+      const errors = (handler as any).parsePyflakesOutput(output, 'undefined_name.py');
+      expect(errors).toHaveLength(1);
+      expect(errors[0].location).toEqual({ file: 'undefined_name.py', line: 6, column: 1 });
+    });
+  });
+
+  describe('Mypy Parsing', () => {
+    it('should map error, warning and note prefixes onto severities', () => {
+      const output = [
+        '/tmp/mypy-check-1.py:5:18: error: Incompatible types in assignment (expression has type "str", variable has type "int")  [assignment]',
+        '/tmp/mypy-check-1.py:6:5: warning: Returning Any from function declared to return "int"  [no-any-return]',
+        '/tmp/mypy-check-1.py:6: note: See https://mypy.rtfd.io for details'
+      ].join('\n');
+
+      const errors = (handler as any).parseMypyOutput(output, 'type_error.py');
+      expect(errors).toHaveLength(3);
+      expect(errors[0]).toMatchObject({
+        severity: 'error',
+        code: 'assignment',
+        location: { file: 'type_error.py', line: 5, column: 18 }
+      });
+      expect(errors[0].message).not.toContain('[assignment]');
+      expect(errors[1].severity).toBe('warning');
+      expect(errors[2]).toMatchObject({ severity: 'info', code: 'mypy' });
+    });
+  });
+
+  describe('Error Detection', () => {
+    it('should run pyflakes and mypy against fixture files', async () => {
+      (handler as any).hasPyflakes = true;
+      (handler as any).mypyPath = '/usr/bin/mypy';
+
+      vi.spyOn(handler as any, 'runCommand').mockImplementation(async (...params: unknown[]) => {
+        const [command, args] = params as [string, string[]];
+        if (args.includes('pyflakes')) {
+          return { stdout: "/tmp/pyflakes-check-1.py:6:23: undefined name 'nme'\n", stderr: '', exitCode: 1 };
+        }
+        if (command === '/usr/bin/mypy') {
+          return {
+            stdout: `${args[args.length - 1]}:5:18: error: Incompatible types in assignment  [assignment]\n`,
+            stderr: '',
+            exitCode: 1
+          };
+        }
+        return { stdout: '', stderr: '', exitCode: 0 };
+      });
+
+      const errors = await handler.detectErrors(readFixture('undefined_name.py'), {
+        filePath: 'undefined_name.py'
+      });
+
+      expect(errors.map(error => error.code)).toEqual(['pyflakes', 'assignment']);
+      expect(errors.every(error => error.location.file === 'undefined_name.py')).toBe(true);
+    });
+
+    it('should give each check a temporary file of its own and remove it afterwards', async () => {
+      (handler as any).hasPyflakes = true;
+      const tempFiles: string[] = [];
+      vi.spyOn(handler as any, 'runCommand').mockImplementation(async (...params: unknown[]) => {
+        const args = params[1] as string[];
+        const tempFile = args[args.length - 1]!;
+        tempFiles.push(tempFile);
+        expect(readFileSync(tempFile, 'utf-8')).toBe('x = 1\n');
+        if (args.includes('pyflakes')) {
+          throw new Error('pyflakes crashed');
+        }
+        return { stdout: '', stderr: '', exitCode: 0 };
+      });
+
+      await Promise.all([
+        handler.detectErrors('x = 1\n', { filePath: 'a.py' }),
+        handler.detectErrors('x = 1\n', { filePath: 'b.py' })
+      ]);
+
+      expect(new Set(tempFiles).size).toBe(4);
+      expect(tempFiles.every(file => file.startsWith(tmpdir()))).toBe(true);
+      expect(tempFiles.some(file => existsSync(file))).toBe(false);
+    });
+
+    it('should run mypy on a saved file from the workspace root, keeping only its own errors', async () => {
+      const root = await fs.realpath(await fs.mkdtemp(join(tmpdir(), 'python-mypy-')));
+      const file = join(root, 'app', 'main.py');
+      await fs.mkdir(join(root, 'app'));
+      await fs.writeFile(file, 'x: int = "a"\n');
+      (handler as any).mypyPath = '/usr/bin/mypy';
+      const runCommand = vi.spyOn(handler as any, 'runCommand').mockResolvedValue({
+        stdout: [
+          'app/models.py:3:5: error: Name "y" is not defined  [name-defined]',
+          'app/main.py:1:10: error: Incompatible types in assignment  [assignment]'
+        ].join('\n'),
+        stderr: '',
+        exitCode: 1
+      });
+
+      try {
+        const errors = await (handler as any).runMypy('x: int = "a"\n', file, root);
+
+        expect(runCommand).toHaveBeenCalledWith('/usr/bin/mypy', expect.not.arrayContaining(['--ignore-missing-imports']), { cwd: root });
+        expect(runCommand.mock.calls[0]![1]).toContain(file);
+        expect(errors).toEqual([expect.objectContaining({ code: 'assignment', location: expect.objectContaining({ file, line: 1, column: 10 }) })]);
+      } finally {
+        await fs.rm(root, { recursive: true, force: true });
+      }
+    });
+
+    it('should degrade to syntax-only detection when mypy is not installed', async () => {
+      (handler as any).hasPyflakes = false;
+      (handler as any).mypyPath = undefined;
+
+      const runCommand = vi.spyOn(handler as any, 'runCommand').mockResolvedValue({
+        stdout: '',
+        stderr: '',
+        exitCode: 0
+      });
+
+      const errors = await handler.detectErrors(readFixture('type_error.py'), {
+        filePath: 'type_error.py'
+      });
+
+      expect(errors).toEqual([]);
+      expect(runCommand).toHaveBeenCalledTimes(1);
+      expect(runCommand.mock.calls[0]?.[1]).toContain('py_compile');
+    });
+
+    it('should honour the configured checkers', () => {
+      const pyflakesOnly = new PythonHandler({ checkers: ['pyflakes'] });
+      expect((pyflakesOnly as any).getCheckers()).toEqual(['pyflakes']);
+      expect((handler as any).getCheckers()).toEqual(['pyflakes', 'mypy']);
+    });
+  });
+});
@@ -0,0 +1,138 @@
+/**
+ * Tests for Rust language handler
+ */
+
+import { describe, it, expect, beforeEach, vi } from 'vitest';
+import { RustHandler } from '../../../src/languages/rust-handler.js';
+import { ToolNotFoundError } from '../../../src/utils/errors.js';
+
+describe('RustHandler', () => {
+  let handler: RustHandler;
+
+  const compilerMessage = {
+    reason: 'compiler-message',
+    package_id: 'demo 0.1.0 (path+file:///repo)',
+    message: {
+      message: 'mismatched types',
+      code: { code: 'E0308', explanation: null },
+      level: 'error',
+      spans: [
+        {
+          file_name: 'src/main.rs',
+          byte_start: 52,
+          byte_end: 59,
+          line_start: 3,
+          line_end: 3,
+          column_start: 18,
+          column_end: 25,
+          is_primary: true,
+          label: 'expected `u32`, found `&str`'
+        },
+        {
+          file_name: 'src/main.rs',
+          byte_start: 46,
+          byte_end: 49,
+          line_start: 3,
+          line_end: 3,
+          column_start: 12,
+          column_end: 15,
+          is_primary: false,
+          label: 'expected due to this'
+        }
+      ],
+      children: [
+        {
+          message: 'try using a conversion method',
+          code: null,
+          level: 'help',
+          spans: [
+            {
+              file_name: 'src/main.rs',
+              byte_start: 52,
+              byte_end: 59,
+              line_start: 3,
+              line_end: 3,
+              column_start: 18,
+              column_end: 25,
+              is_primary: true,
+              label: null,
+              suggested_replacement: '"hello".parse().unwrap()'
+            }
+          ],
+          children: []
+        }
+      ]
+    }
+  };
+
+  beforeEach(() => {
+    handler = new RustHandler();
+  });
+
+  describe('cargo JSON parsing', () => {
+    it('should map the primary span and keep the rustc code', () => {
+      const output = [
+        JSON.stringify({ reason: 'compiler-artifact', package_id: 'dep 1.0.0' }),
+        JSON.stringify(compilerMessage),
+        JSON.stringify({ reason: 'build-finished', success: false })
+      ].join('\n');
+
+      const errors = (handler as any).parseCargoOutput(output, () => '/repo/src/main.rs');
+
+      expect(errors).toHaveLength(1);
+      expect(errors[0]).toMatchObject({
+        message: 'mismatched types: expected `u32`, found `&str`',
+        severity: 'error',
+        code: 'E0308',
+        location: {
+          file: '/repo/src/main.rs',
+          line: 3,
+          column: 18,
+          endLine: 3,
+          endColumn: 25,
+          byteStart: 52,
+          byteEnd: 59
+        }
+      });
+    });
+
+    it('should attach child notes and secondary labels as related information', () => {
+      const errors = (handler as any).parseCargoOutput(JSON.stringify(compilerMessage), () => '/repo/src/main.rs');
+
+      expect(errors[0].relatedInformation).toEqual([
+        {
+          location: { file: '/repo/src/main.rs', line: 3, column: 12 },
+          message: 'expected due to this'
+        },
+        {
+          location: { file: '/repo/src/main.rs', line: 3, column: 18 },
+          message: 'help: try using a conversion method: `"hello".parse().unwrap()`'
+        }
+      ]);
+    });
+
+    it('should drop diagnostics for other files and span-less summaries', () => {
+      const summary = {
+        reason: 'compiler-message',
+        message: { message: 'aborting due to 1 previous error', code: null, level: 'error', spans: [], children: [] }
+      };
+      const output = [JSON.stringify(compilerMessage), JSON.stringify(summary)].join('\n');
+
+      expect((handler as any).parseCargoOutput(output, () => undefined)).toEqual([]);
+    });
+  });
+
+  describe('missing toolchain', () => {
+    it('should fail initialization with a typed error when cargo is not on PATH', async () => {
+      vi.spyOn(handler as any, 'findExecutable').mockResolvedValue(undefined);
+
+      await expect(handler.initialize()).rejects.toBeInstanceOf(ToolNotFoundError);
+    });
+
+    it('should report a missing executable as a typed error', async () => {
+      await expect(
+        (handler as any).runCommand('cargo-definitely-not-installed', ['check'])
+      ).rejects.toMatchObject({ name: 'ToolNotFoundError', tool: 'cargo-definitely-not-installed' });
+    });
+  });
+});
@@ -0,0 +1,136 @@
+/**
+ * Tests for the shellcheck-based shell script detector
+ */
+
+import { describe, it, expect, beforeEach, vi } from 'vitest';
+import { readFileSync } from 'fs';
+import { tmpdir } from 'os';
+import { join } from 'path';
+import {
+  ShellDetector,
+  describeShellcheckFix,
+  parseShellcheckOutput
+} from '../../../src/languages/shell-detector.js';
+import { ToolNotFoundError } from '../../../src/utils/errors.js';
+
+const fixtureDir = join(__dirname, '../../fixtures/shell');
+const readFixture = (name: string) => readFileSync(join(fixtureDir, name), 'utf-8');
+
+describe('ShellDetector', () => {
+  const script = join(fixtureDir, 'deploy.sh');
+  const source = readFixture('deploy.sh');
+  let handler: ShellDetector;
+  let runCommand: ReturnType<typeof vi.spyOn>;
+
+  beforeEach(() => {
+    handler = new ShellDetector();
+    (handler as any).shellcheckPath = 'shellcheck';
+    runCommand = vi.spyOn(handler as any, 'runCommand').mockResolvedValue({
+      stdout: readFixture('deploy.shellcheck.json'),
+      stderr: '',
+      exitCode: 1
+    });
+  });
+
+  describe('parseShellcheckOutput', () => {
+    it('should read the comments array', () => {
+      const comments = parseShellcheckOutput(readFixture('deploy.shellcheck.json'));
+
+      expect(comments).toHaveLength(5);
+      expect(comments[0]).toMatchObject({ line: 3, column: 12, endColumn: 19, level: 'info', code: 2086 });
+      expect(comments[2]!.fix).toBeNull();
+    });
+
+    it('should yield nothing for output that is not json1', () => {
+      expect(parseShellcheckOutput('')).toEqual([]);
+      expect(parseShellcheckOutput('[{"line":1}]')).toEqual([]);
+    });
+  });
+
+  describe('describeShellcheckFix', () => {
+    const comments = parseShellcheckOutput(readFixture('deploy.shellcheck.json'));
+
+    it('should render replacements as the fixed text', () => {
+      expect(describeShellcheckFix(source, comments[0]!)).toBe('Replace `$target` with `"$target"`');
+      expect(describeShellcheckFix(source, comments[1]!)).toBe('Replace `cd /srv/app` with `cd /srv/app || exit`');
+      expect(describeShellcheckFix(source, comments[3]!)).toBe('Replace ``date`` with `$(date)`');
+    });
+
+    it('should skip comments without a usable fix', () => {
+      expect(describeShellcheckFix(source, comments[2]!)).toBeUndefined();
+      expect(describeShellcheckFix('echo\n', comments[0]!)).toBeUndefined();
+    });
+  });
+
+  describe('detectErrors', () => {
+    it('should check a saved script in place and keep codes, levels and ranges', async () => {
+      const errors = await handler.detectErrors(source, { filePath: script });
+
+      expect(runCommand).toHaveBeenCalledWith('shellcheck', ['--format=json1', script], { cwd: fixtureDir });
+      // The comment for the sourced lib.sh belongs to that file's own analysis
+      expect(errors.map(error => [error.code, error.severity])).toEqual([
+        ['SC2086', 'info'],
+        ['SC2164', 'warning'],
+        ['SC2045', 'error'],
+        ['SC2006', 'hint']
+      ]);
+      expect(errors[0]).toMatchObject({
+        message: 'Double quote to prevent globbing and word splitting.',
+        source: 'shellcheck',
+        location: { file: script, line: 3, column: 12, endLine: 3, endColumn: 19 },
+        suggestedFix: 'Replace `$target` with `"$target"`'
+      });
+      expect(errors[2]!.suggestedFix).toBeUndefined();
+    });
+
+    it('should check unsaved buffers through a temporary file of the same name', async () => {
+      const edited = `${source}echo done\n`;
+
+      const errors = await handler.detectErrors(edited, { filePath: script, enableLinting: false });
+
+      const [, args, options] = runCommand.mock.calls[0]! as [string, string[], { cwd: string }];
+      expect(args.slice(0, 2)).toEqual(['--format=json1', '--severity=error']);
+      expect(args[2]!.startsWith(tmpdir())).toBe(true);
+      expect(args[2]!.endsWith('deploy.sh')).toBe(true);
+      expect(options.cwd).not.toBe(fixtureDir);
+      expect(errors).toHaveLength(4);
+      expect(errors[0]!.location.file).toBe(script);
+    });
+
+    it('should report shellcheck failures without findings as a toolchain error', async () => {
+      runCommand.mockResolvedValue({ stdout: '', stderr: 'Invalid value for --shell: zsh\n', exitCode: 3 });
+
+      const errors = await handler.detectErrors(source, { filePath: script });
+
+      expect(errors).toHaveLength(1);
+      expect(errors[0]).toMatchObject({ source: 'toolchain', message: 'shellcheck failed: Invalid value for --shell: zsh' });
+    });
+
+    it('should report nothing for a clean script', async () => {
+      runCommand.mockResolvedValue({ stdout: '{"comments":[]}', stderr: '', exitCode: 0 });
+
+      expect(await handler.detectErrors('#!/bin/sh\necho "$1"\n', { filePath: 'clean.sh' })).toEqual([]);
+    });
+  });
+
+  describe('missing shellcheck', () => {
+    it('should report itself unavailable instead of failing', async () => {
+      runCommand.mockRejectedValue(new ToolNotFoundError('shellcheck'));
+      (handler as any).shellcheckPath = undefined;
+
+      expect(await handler.isAvailable()).toBe(false);
+    });
+
+    it('should fail initialization with a typed error', async () => {
+      vi.spyOn(handler as any, 'findExecutable').mockResolvedValue(undefined);
+
+      await expect(handler.initialize()).rejects.toBeInstanceOf(ToolNotFoundError);
+    });
+  });
+
+  it('should read file and line from shell error output', () => {
+    expect(handler.parseStackTrace('./deploy.sh: line 12: kubectl: command not found')).toEqual([
+      { function: '<main>', file: './deploy.sh', line: 12, column: 1 }
+    ]);
+  });
+});
@@ -0,0 +1,69 @@
+/**
+ * Tests for snippet preparation and line mapping
+ */
+
+import { describe, it, expect } from 'vitest';
+import { prepareSnippet, toSnippetLine } from '../../../src/languages/snippet.js';
+
+describe('snippets', () => {
+  describe('prepareSnippet', () => {
+    it('should leave complete Go files untouched', () => {
+      const code = 'package foo\n\nfunc Bar() {}\n';
+      const snippet = prepareSnippet('go', code);
+
+      expect(snippet.wrapper).toBe('none');
+      expect(snippet.source).toBe(code);
+    });
+
+    it('should add a package clause and main to bare declarations', () => {
+      const snippet = prepareSnippet('go', 'func add(a, b int) int {\n\treturn a + b\n}');
+
+      expect(snippet.wrapper).toBe('package');
+      expect(snippet.lineOffset).toBe(2);
+      expect(snippet.source).toMatch(/^package main\n\nfunc add/);
+      expect(snippet.source).toContain('func main() {}');
+    });
+
+    it('should not add main when the snippet declares one', () => {
+      const snippet = prepareSnippet('go', 'import "fmt"\n\nfunc main() {\n\tfmt.Println("hi")\n}');
+
+      expect(snippet.wrapper).toBe('package');
+      expect(snippet.source.match(/func main/g)).toHaveLength(1);
+    });
+
+    it('should wrap bare statements in func main', () => {
+      const snippet = prepareSnippet('go', 'x := 1\nx = "two"');
+
+      expect(snippet.wrapper).toBe('main');
+      expect(snippet.lineOffset).toBe(3);
+      expect(snippet.source.split('\n')[3]).toBe('x := 1');
+    });
+
+    it('should not wrap other languages', () => {
+      const snippet = prepareSnippet('typescript', 'const x: string = 1;');
+
+      expect(snippet).toEqual({ source: 'const x: string = 1;', wrapper: 'none', lineOffset: 0, lineCount: 1 });
+    });
+  });
+
+  describe('toSnippetLine', () => {
+    it('should shift lines back by the wrapper offset', () => {
+      const snippet = prepareSnippet('go', 'x := 1\nx = "two"');
+
+      expect(toSnippetLine(5, snippet)).toEqual({ line: 2, shifted: true, inWrapper: false });
+    });
+
+    it('should clamp and flag positions inside the wrapper', () => {
+      const snippet = prepareSnippet('go', 'x := 1\nx = "two"');
+
+      expect(toSnippetLine(1, snippet)).toEqual({ line: 1, shifted: true, inWrapper: true });
+      expect(toSnippetLine(6, snippet)).toEqual({ line: 2, shifted: true, inWrapper: true });
+    });
+
+    it('should keep lines of unwrapped snippets', () => {
+      const snippet = prepareSnippet('go', 'package main\n');
+
+      expect(toSnippetLine(1, snippet)).toEqual({ line: 1, shifted: false, inWrapper: false });
+    });
+  });
+});
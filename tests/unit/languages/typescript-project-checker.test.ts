@@ -0,0 +1,98 @@
+/**
+ * Tests for the project-wide tsc runner
+ */
+
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { promises as fs } from 'fs';
+import { tmpdir } from 'os';
+import { join } from 'path';
+import {
+  TypeScriptProjectChecker,
+  mapTypeScriptSeverity,
+  parseTscOutput
+} from '../../../src/languages/typescript-project-checker.js';
+
+describe('TypeScriptProjectChecker', () => {
+  let projectDir: string;
+
+  beforeEach(async () => {
+    projectDir = await fs.mkdtemp(join(tmpdir(), 'ts-project-'));
+    await fs.writeFile(join(projectDir, 'tsconfig.json'), '{}');
+    await fs.mkdir(join(projectDir, 'src'));
+  });
+
+  afterEach(async () => {
+    await fs.rm(projectDir, { recursive: true, force: true });
+  });
+
+  describe('parseTscOutput', () => {
+    it('should parse diagnostics and resolve relative paths', () => {
+      const output = [
+        "src/a.ts(3,7): error TS2322: Type 'number' is not assignable to type 'string'.",
+        "src/b.ts(1,1): error TS2304: Cannot find name 'foo'.",
+        '  Did you mean \'for\'?'
+      ].join('\n');
+
+      const diagnostics = parseTscOutput(output, '/repo');
+
+      expect(diagnostics).toHaveLength(2);
+      expect(diagnostics[0]).toMatchObject({ file: '/repo/src/a.ts', line: 3, column: 7, code: 'TS2322' });
+      expect(diagnostics[1]?.message).toContain("Did you mean 'for'?");
+    });
+  });
+
+  describe('mapTypeScriptSeverity', () => {
+    it('should map codes to stable severities', () => {
+      expect(mapTypeScriptSeverity('TS2304')).toBe('error');
+      expect(mapTypeScriptSeverity('TS6133')).toBe('warning');
+      expect(mapTypeScriptSeverity('TS80001', 'error')).toBe('info');
+    });
+  });
+
+  describe('check', () => {
+    it('should run tsc once for several files in the same project', async () => {
+      const files = ['a.ts', 'b.ts', 'c.ts'].map(name => join(projectDir, 'src', name));
+      for (const file of files) {
+        await fs.writeFile(file, 'export {};');
+      }
+      await new Promise(resolve => setTimeout(resolve, 5));
+
+      const run = vi.fn().mockResolvedValue({
+        stdout: "src/b.ts(2,5): error TS2304: Cannot find name 'foo'.\n",
+        stderr: '',
+        exitCode: 2
+      });
+      const checker = new TypeScriptProjectChecker('tsc', run);
+
+      const results = [];
+      for (const file of files) {
+        results.push(await checker.check(file));
+      }
+
+      expect(run).toHaveBeenCalledTimes(1);
+      expect(run.mock.calls[0]?.[1]).toEqual([
+        '--noEmit', '--pretty', 'false', '--project', join(projectDir, 'tsconfig.json')
+      ]);
+      expect(results[0]).toEqual([]);
+      expect(results[1]?.[0]?.code).toBe('TS2304');
+    });
+
+    it('should fall back to a per-file check without a tsconfig', async () => {
+      await fs.rm(join(projectDir, 'tsconfig.json'));
+      const file = join(projectDir, 'src', 'single.ts');
+      await fs.writeFile(file, 'const x: string = 1;');
+
+      const run = vi.fn().mockResolvedValue({
+        stdout: `${file}(1,7): error TS2322: Type 'number' is not assignable to type 'string'.\n`,
+        stderr: '',
+        exitCode: 2
+      });
+      const checker = new TypeScriptProjectChecker('tsc', run);
+
+      const diagnostics = await checker.check(file);
+
+      expect(run.mock.calls[0]?.[1]).toContain(file);
+      expect(diagnostics).toHaveLength(1);
+    });
+  });
+});
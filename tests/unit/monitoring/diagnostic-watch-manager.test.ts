@@ -0,0 +1,144 @@
+/**
+ * Tests for the diagnostic watch manager
+ */
+
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { promises as fs } from 'fs';
+import { tmpdir } from 'os';
+import { basename, join } from 'path';
+import { DiagnosticWatchManager, type DiagnosticsChangedEvent } from '../../../src/monitoring/diagnostic-watch-manager.js';
+import type { LanguageError } from '../../../src/types/languages.js';
+
+describe('DiagnosticWatchManager', () => {
+  let workspace: string;
+  let file: string;
+  let manager: DiagnosticWatchManager;
+  let languageHandlerManager: any;
+
+  const error = (message: string, line: number): LanguageError => ({
+    message,
+    severity: 'error',
+    location: { file, line, column: 1 },
+    source: 'typescript',
+    code: 'TS2304'
+  });
+
+  beforeEach(async () => {
+    workspace = await fs.realpath(await fs.mkdtemp(join(tmpdir(), 'watch-')));
+    file = join(workspace, 'index.ts');
+    await fs.writeFile(file, 'foo;');
+
+    languageHandlerManager = {
+      analyzePath: vi.fn().mockResolvedValue([error("Cannot find name 'foo'.", 1)]),
+      analyzeFile: vi.fn().mockResolvedValue([error("Cannot find name 'bar'.", 2)]),
+      detectLanguage: vi.fn().mockReturnValue('typescript')
+    };
+    manager = new DiagnosticWatchManager(languageHandlerManager);
+  });
+
+  afterEach(async () => {
+    await manager.stopAll();
+    await fs.rm(workspace, { recursive: true, force: true });
+  });
+
+  it('should return baseline diagnostics when a watch starts', async () => {
+    const { session, diagnostics } = await manager.startWatch(workspace, { debounceMs: 10 });
+
+    expect(session.path).toBe(workspace);
+    expect(session.debounceMs).toBe(10);
+    expect(diagnostics).toHaveLength(1);
+    expect(manager.listWatches()).toHaveLength(1);
+  });
+
+  it('should debounce bursts of changes and emit only the diff', async () => {
+    const { session } = await manager.startWatch(workspace, { debounceMs: 20 });
+    const changed = new Promise<DiagnosticsChangedEvent>(resolve => {
+      manager.once('diagnostics-changed', resolve);
+    });
+
+    const internal = (manager as any).sessions.get(session.id);
+    for (let i = 0; i < 5; i++) {
+      (manager as any).queueFile(internal, file);
+    }
+
+    const event = await changed;
+
+    expect(languageHandlerManager.analyzeFile).toHaveBeenCalledTimes(1);
+    expect(event.watchId).toBe(session.id);
+    expect(event.added.map(d => d.message)).toEqual(["Cannot find name 'bar'."]);
+    expect(event.removed.map(d => d.message)).toEqual(["Cannot find name 'foo'."]);
+    expect(event.unchanged).toBe(0);
+  });
+
+  it('should keep the diagnostics of a file whose analysis failed and drop those of a deleted one', async () => {
+    const { session } = await manager.startWatch(workspace, { debounceMs: 10 });
+    const internal = (manager as any).sessions.get(session.id);
+    const changed = vi.fn();
+    manager.on('diagnostics-changed', changed);
+
+    languageHandlerManager.analyzeFile.mockRejectedValueOnce(new Error('tsc crashed'));
+    (manager as any).queueFile(internal, file);
+    await vi.waitFor(() => expect(languageHandlerManager.analyzeFile).toHaveBeenCalledTimes(1));
+    await vi.waitFor(() => expect(internal.flushing).toBeNull());
+
+    expect(changed).not.toHaveBeenCalled();
+    expect(manager.listWatches()[0]).toMatchObject({ filesWithDiagnostics: 1, diagnostics: 1 });
+
+    await fs.rm(file);
+    (manager as any).queueFile(internal, file);
+    await vi.waitFor(() => expect(changed).toHaveBeenCalledTimes(1));
+
+    expect(changed.mock.calls[0]![0].removed.map((d: { message: string }) => d.message)).toEqual(["Cannot find name 'foo'."]);
+    expect(languageHandlerManager.analyzeFile).toHaveBeenCalledTimes(1);
+    expect(manager.listWatches()[0]).toMatchObject({ filesWithDiagnostics: 0, diagnostics: 0 });
+  });
+
+  it('should match file events under a symlinked root to the files diagnostics name', async () => {
+    const link = join(workspace, '..', `${basename(workspace)}-link`);
+    await fs.symlink(workspace, link);
+
+    try {
+      const { session } = await manager.startWatch(link, { debounceMs: 10 });
+      const changed = new Promise<DiagnosticsChangedEvent>(resolve => {
+        manager.once('diagnostics-changed', resolve);
+      });
+      (manager as any).sessions.get(session.id).watcher.emit('change', join(link, 'index.ts'));
+      const event = await changed;
+
+      expect(session.path).toBe(workspace);
+      expect(languageHandlerManager.analyzePath).toHaveBeenCalledWith(workspace);
+      expect(languageHandlerManager.analyzeFile).toHaveBeenCalledWith(file);
+      expect(event.changedFiles).toEqual([file]);
+      expect(event.removed.map(d => d.message)).toEqual(["Cannot find name 'foo'."]);
+    } finally {
+      await fs.rm(link);
+    }
+  });
+
+  it('should cancel pending analysis when the watch is stopped', async () => {
+    const { session } = await manager.startWatch(workspace, { debounceMs: 20 });
+    (manager as any).queueFile((manager as any).sessions.get(session.id), file);
+
+    expect(await manager.stopWatch(session.id)).toBe(true);
+    await new Promise(resolve => setTimeout(resolve, 50));
+
+    expect(languageHandlerManager.analyzeFile).not.toHaveBeenCalled();
+    expect(await manager.stopWatch(session.id)).toBe(false);
+    expect(manager.listWatches()).toHaveLength(0);
+  });
+
+  it('should not register a watch still starting when all watches are stopped', async () => {
+    let finishAnalysis!: (errors: LanguageError[]) => void;
+    languageHandlerManager.analyzePath.mockReturnValueOnce(new Promise(resolve => {
+      finishAnalysis = resolve;
+    }));
+
+    const starting = manager.startWatch(workspace, { debounceMs: 10 });
+    await vi.waitFor(() => expect(languageHandlerManager.analyzePath).toHaveBeenCalled());
+    await manager.stopAll();
+    finishAnalysis([]);
+
+    await expect(starting).rejects.toThrow('stopped before it started');
+    expect(manager.listWatches()).toHaveLength(0);
+  });
+});
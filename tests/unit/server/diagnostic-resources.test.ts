@@ -0,0 +1,134 @@
+/**
+ * Tests for error:// diagnostics resources
+ */
+
+import { describe, it, expect, beforeEach, vi } from 'vitest';
+import { EventEmitter } from 'events';
+import { promises as fs } from 'fs';
+import { tmpdir } from 'os';
+import { join, resolve } from 'path';
+
+import {
+  DiagnosticResourceProvider,
+  parseDiagnosticResourceUri,
+  toDiagnosticResourceUri,
+} from '@/server/diagnostic-resources.js';
+import type { LanguageHandlerManager } from '@/languages/language-handler-manager.js';
+import type { DiagnosticWatchManager } from '@/monitoring/diagnostic-watch-manager.js';
+import { toDiagnosticRecord } from '@/utils/diagnostics.js';
+import type { LanguageError } from '@/types/languages.js';
+
+const file = resolve(__filename);
+
+const error: LanguageError = {
+  message: 'Type mismatch',
+  severity: 'error',
+  location: { file, line: 3, column: 5 },
+  source: 'typescript',
+  code: 'TS2322',
+};
+
+describe('DiagnosticResourceProvider', () => {
+  let manager: { analyzeFile: ReturnType<typeof vi.fn> };
+  let watchManager: EventEmitter & { startWatch: ReturnType<typeof vi.fn>; stopWatch: ReturnType<typeof vi.fn> };
+  let provider: DiagnosticResourceProvider;
+
+  beforeEach(() => {
+    manager = { analyzeFile: vi.fn(async () => [error]) };
+    watchManager = Object.assign(new EventEmitter(), {
+      startWatch: vi.fn(async () => ({ session: { id: 'watch-1' }, diagnostics: [] })),
+      stopWatch: vi.fn(async () => true),
+    });
+    provider = new DiagnosticResourceProvider(
+      manager as unknown as LanguageHandlerManager,
+      watchManager as unknown as DiagnosticWatchManager
+    );
+  });
+
+  it('should round-trip file paths through resource URIs', () => {
+    const uri = toDiagnosticResourceUri('/work space/src/app.ts');
+
+    expect(uri).toBe('error:///work%20space/src/app.ts');
+    expect(parseDiagnosticResourceUri(uri)).toBe('/work space/src/app.ts');
+  });
+
+  it('should return the current diagnostics for a file', async () => {
+    const content = await provider.read(toDiagnosticResourceUri(file));
+    const body = JSON.parse(content.text || '{}');
+
+    expect(content.mimeType).toBe('application/json');
+    expect(body.path).toBe(file);
+    expect(body.summary.errors).toBe(1);
+    expect(body.diagnostics[0].code).toBe('TS2322');
+  });
+
+  it('should share one analysis between concurrent reads', async () => {
+    const uri = toDiagnosticResourceUri(file);
+
+    const [first, second] = await Promise.all([provider.read(uri), provider.read(uri)]);
+
+    expect(manager.analyzeFile).toHaveBeenCalledTimes(1);
+    expect(first.text).toBe(second.text);
+  });
+
+  it('should return an empty list for stale paths', async () => {
+    const content = await provider.read('error:///does/not/exist.ts');
+    const body = JSON.parse(content.text || '{}');
+
+    expect(body.diagnostics).toEqual([]);
+    expect(body.summary).toEqual({ errors: 0, warnings: 0, info: 0, hints: 0, hasErrors: false });
+    expect(manager.analyzeFile).not.toHaveBeenCalled();
+  });
+
+  it('should notify subscribers when a file\'s diagnostics change', async () => {
+    const uri = toDiagnosticResourceUri(file);
+    const updated = vi.fn();
+    provider.on('resource-updated', updated);
+
+    await provider.subscribe(uri);
+    watchManager.emit('diagnostics-changed', {
+      watchId: 'watch-1',
+      path: file,
+      changedFiles: [file],
+      added: [toDiagnosticRecord(error)],
+      removed: [],
+      unchanged: 0,
+    });
+
+    expect(watchManager.startWatch).toHaveBeenCalledWith(file);
+    expect(updated).toHaveBeenCalledWith(uri);
+
+    await provider.unsubscribe(uri);
+    expect(watchManager.stopWatch).toHaveBeenCalledWith('watch-1');
+  });
+
+  it('should notify subscribers of a path through a symlink, which diagnostics name by its target', async () => {
+    const directory = await fs.realpath(await fs.mkdtemp(join(tmpdir(), 'resources-')));
+    const target = join(directory, 'real', 'main.ts');
+    await fs.mkdir(join(directory, 'real'));
+    await fs.writeFile(target, 'let x: number = "";\n');
+    await fs.symlink(join(directory, 'real'), join(directory, 'link'));
+    const uri = toDiagnosticResourceUri(join(directory, 'link', 'main.ts'));
+    const updated = vi.fn();
+    provider.on('resource-updated', updated);
+
+    try {
+      await provider.subscribe(uri);
+      watchManager.emit('diagnostics-changed', {
+        watchId: 'watch-1',
+        path: target,
+        changedFiles: [target],
+        added: [toDiagnosticRecord({ ...error, location: { ...error.location, file: target } })],
+        removed: [],
+        unchanged: 0,
+      });
+
+      expect(watchManager.startWatch).toHaveBeenCalledWith(target);
+      expect(updated).toHaveBeenCalledWith(uri);
+      await provider.unsubscribe(uri);
+      expect(watchManager.stopWatch).toHaveBeenCalledWith('watch-1');
+    } finally {
+      await fs.rm(directory, { recursive: true, force: true });
+    }
+  });
+});
@@ -0,0 +1,317 @@
+/**
+ * Tests for the diagnostics returned by the tools, their order, and the stats tool
+ */
+
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { EventEmitter } from 'events';
+import { promises as fs } from 'fs';
+import { tmpdir } from 'os';
+import { basename, join } from 'path';
+import { ToolRegistry } from '../../../src/server/tool-registry.js';
+import { LanguageHandlerManager } from '../../../src/languages/language-handler-manager.js';
+import type { DetectionOptions, LanguageError, LanguageHandler } from '../../../src/types/languages.js';
+
+const sleep = (ms: number) => new Promise(resolve => setTimeout(resolve, ms));
+
+describe('ToolRegistry diagnostic order', () => {
+  let directory: string;
+
+  beforeEach(async () => {
+    directory = await fs.realpath(await fs.mkdtemp(join(tmpdir(), 'diagnostic-order-')));
+    for (const name of ['d.c', 'b.c', 'a.c', 'c.c', 'e.c']) {
+      await fs.writeFile(join(directory, name), 'int main(void) { return 0; }\n');
+    }
+  });
+
+  afterEach(async () => {
+    await fs.rm(directory, { recursive: true, force: true });
+  });
+
+  // Each run reports the same findings, in a different order and with files finishing in a different order
+  const createHandler = (run: number) => Object.assign(new EventEmitter() as unknown as LanguageHandler, {
+    language: 'c',
+    initialize: vi.fn(async () => {}),
+    dispose: vi.fn(async () => {}),
+    isAvailable: vi.fn(async () => true),
+    isFileSupported: (filePath: string) => filePath.endsWith('.c'),
+    getFileExtensions: () => ['.c'],
+    getConfigFiles: () => [],
+    detectErrors: vi.fn(async (_source: string, options?: DetectionOptions): Promise<LanguageError[]> => {
+      const file = options!.filePath!;
+      const weight = basename(file).charCodeAt(0);
+      await sleep((weight * (run + 3)) % 17);
+      const errors: LanguageError[] = [
+        { message: 'unused variable y', severity: 'warning', location: { file, line: 3, column: 7 }, source: 'cc' },
+        { message: 'implicit declaration', severity: 'error', location: { file, line: 3, column: 7 }, source: 'cc' },
+        { message: 'missing return', severity: 'error', location: { file, line: 12, column: 1 }, source: 'cc' },
+        { message: 'comparison is always true', severity: 'warning', location: { file, line: 3, column: 2 }, source: 'cc' },
+        { message: 'unused variable x', severity: 'warning', location: { file, line: 3, column: 7 }, source: 'cc' }
+      ];
+      return run % 2 === 0 ? errors.reverse() : errors;
+    })
+  });
+
+  const listErrors = async (run: number, concurrency: number, args: Record<string, unknown> = {}) => {
+    const manager = new LanguageHandlerManager({ enabledLanguages: [], workspaceRoots: [directory], concurrency });
+    const registry = new ToolRegistry();
+    try {
+      await manager.registerHandler(createHandler(run));
+      registry.setLanguageHandlerManager(manager);
+      await registry.registerTool({ name: 'list-errors', description: 'List errors', inputSchema: { type: 'object' } });
+      const result = await registry.callTool('list-errors', { path: directory, ...args });
+      expect(result.isError).toBeUndefined();
+      return result.content[0]!.text as string;
+    } finally {
+      await manager.dispose();
+    }
+  };
+
+  it('should return byte-identical JSON across runs, with or without concurrency', async () => {
+    const first = await listErrors(0, 4);
+
+    expect(await listErrors(1, 4)).toBe(first);
+    expect(await listErrors(2, 1)).toBe(first);
+
+    const diagnostics = JSON.parse(first).diagnostics as Array<{ file: string; line: number; column: number; message: string }>;
+    expect(diagnostics.slice(0, 5).map(record => `${basename(record.file)}:${record.line}:${record.column} ${record.message}`)).toEqual([
+      'a.c:3:2 comparison is always true',
+      'a.c:3:7 implicit declaration',
+      'a.c:3:7 unused variable x',
+      'a.c:3:7 unused variable y',
+      'a.c:12:1 missing return'
+    ]);
+  });
+
+  it('should keep the text and SARIF formats stable too', async () => {
+    for (const format of ['text', 'sarif']) {
+      expect(await listErrors(1, 4, { format })).toBe(await listErrors(2, 2, { format }));
+    }
+  });
+});
+
+describe('ToolRegistry list-errors', () => {
+  let directory: string;
+  let manager: LanguageHandlerManager;
+  let registry: ToolRegistry;
+
+  beforeEach(async () => {
+    directory = await fs.realpath(await fs.mkdtemp(join(tmpdir(), 'list-errors-')));
+    await fs.writeFile(join(directory, 'main.c'), 'int main(void) { return 0; }\n');
+
+    manager = new LanguageHandlerManager({ enabledLanguages: [], workspaceRoots: [directory] });
+    await manager.registerHandler(Object.assign(new EventEmitter() as unknown as LanguageHandler, {
+      language: 'c',
+      initialize: vi.fn(async () => {}),
+      dispose: vi.fn(async () => {}),
+      isAvailable: vi.fn(async () => true),
+      isFileSupported: (filePath: string) => filePath.endsWith('.c'),
+      getFileExtensions: () => ['.c'],
+      getConfigFiles: () => [],
+      detectErrors: vi.fn(async (_source: string, options?: DetectionOptions): Promise<LanguageError[]> => {
+        const file = options!.filePath!;
+        return [
+          // Some tools report 0-based or missing positions for file-level findings
+          { message: 'no newline at end of file', severity: 'error', location: { file, line: 0, column: 0 }, source: 'cc', code: 'E1' },
+          { message: 'unused variable x', severity: 'warning', location: { file, line: 4, column: 7, endColumn: 3 }, source: 'cc' },
+          { message: 'consider const', severity: 'info', location: { file, line: 5, column: 1 }, source: 'cc' },
+          { message: 'missing return', severity: 'error', location: { file, line: 9, column: 1 }, source: 'cc' },
+          {
+            message: 'redefinition of helper',
+            severity: 'error',
+            location: { file, line: 12, column: 6 },
+            source: 'cc',
+            relatedInformation: [{ location: { file, line: 2, column: 6 }, message: 'previous definition is here' }]
+          }
+        ];
+      })
+    }));
+    registry = new ToolRegistry();
+    registry.setLanguageHandlerManager(manager);
+    await registry.registerTool({ name: 'list-errors', description: 'List errors', inputSchema: { type: 'object' } });
+  });
+
+  afterEach(async () => {
+    await manager.dispose();
+    await fs.rm(directory, { recursive: true, force: true });
+  });
+
+  const listErrors = async (args: Record<string, unknown> = {}) => {
+    const result = await registry.callTool('list-errors', { path: directory, ...args });
+    expect(result.isError).toBeUndefined();
+    return JSON.parse(result.content[0]!.text as string);
+  };
+
+  it('should return 1-based positions with the end never before the start', async () => {
+    const { diagnostics } = await listErrors();
+
+    expect(diagnostics[0]).toMatchObject({
+      file: join(directory, 'main.c'),
+      line: 1,
+      column: 1,
+      endLine: 1,
+      endColumn: 1,
+      severity: 'error',
+      code: 'E1',
+      message: 'no newline at end of file',
+      source: 'cc'
+    });
+    expect(diagnostics[1]).toMatchObject({ line: 4, column: 7, endLine: 4, endColumn: 7, code: null });
+  });
+
+  it('should carry related locations into the JSON and SARIF reports', async () => {
+    const file = join(directory, 'main.c');
+    const { diagnostics } = await listErrors();
+
+    expect(diagnostics[4]).toMatchObject({
+      message: 'redefinition of helper',
+      relatedInformation: [{ file, line: 2, column: 6, message: 'previous definition is here' }]
+    });
+    expect(diagnostics[0].relatedInformation).toBeUndefined();
+
+    const result = await registry.callTool('list-errors', { path: directory, format: 'sarif' });
+    const sarif = JSON.parse(result.content[0]!.text as string);
+    expect(sarif.runs[0].results[4].relatedLocations).toEqual([{
+      id: 0,
+      physicalLocation: { artifactLocation: { uri: 'main.c', uriBaseId: 'SRCROOT' }, region: { startLine: 2, startColumn: 6 } },
+      message: { text: 'previous definition is here' }
+    }]);
+  });
+
+  it('should filter by severity, counting warnings and errors for warning', async () => {
+    const messages = async (severity: string) =>
+      (await listErrors({ severity })).diagnostics.map((record: { message: string }) => record.message);
+
+    expect(await messages('error')).toEqual(['no newline at end of file', 'missing return', 'redefinition of helper']);
+    expect(await messages('warning')).toEqual(['no newline at end of file', 'unused variable x', 'missing return', 'redefinition of helper']);
+    expect(await messages('all')).toHaveLength(5);
+  });
+
+  it('should flag a list cut at maxResults, keeping errors first and counting every match', async () => {
+    const report = await listErrors({ maxResults: 3 });
+
+    expect(report).toMatchObject({
+      total: 5,
+      truncated: true,
+      omittedCount: 2,
+      nextOffset: 3,
+      summary: { errors: 3, warnings: 1, info: 1 }
+    });
+    expect(report.diagnostics.map((record: { severity: string }) => record.severity)).toEqual(['error', 'error', 'error']);
+
+    expect(await listErrors({ maxResults: 5 })).toMatchObject({ total: 5, truncated: false, omittedCount: 0, nextOffset: null });
+  });
+});
+
+describe('ToolRegistry detect-errors', () => {
+  let directory: string;
+
+  beforeEach(async () => {
+    directory = await fs.realpath(await fs.mkdtemp(join(tmpdir(), 'detect-errors-')));
+    for (const name of ['a.go', 'b.go']) {
+      await fs.writeFile(join(directory, name), 'package main\n');
+    }
+  });
+
+  afterEach(async () => {
+    await fs.rm(directory, { recursive: true, force: true });
+  });
+
+  it('should count package errors reported for every file of the package once', async () => {
+    const manager = new LanguageHandlerManager({ enabledLanguages: [], workspaceRoots: [directory] });
+    const registry = new ToolRegistry();
+    const shared = join(directory, 'b.go');
+
+    try {
+      await manager.registerHandler(Object.assign(new EventEmitter() as unknown as LanguageHandler, {
+        language: 'go',
+        initialize: vi.fn(async () => {}),
+        dispose: vi.fn(async () => {}),
+        isAvailable: vi.fn(async () => true),
+        isFileSupported: (filePath: string) => filePath.endsWith('.go'),
+        getFileExtensions: () => ['.go'],
+        getConfigFiles: () => [],
+        detectErrors: vi.fn(async (_source: string, options?: DetectionOptions): Promise<LanguageError[]> => [
+          { message: 'undefined: Foo', severity: 'error', location: { file: shared, line: 3, column: 2 }, source: 'go' },
+          { message: 'unused variable', severity: 'warning', location: { file: options!.filePath!, line: 1, column: 1 }, source: 'go' }
+        ])
+      }));
+      registry.setLanguageHandlerManager(manager);
+      await registry.registerTool({ name: 'detect-errors', description: 'Detect errors', inputSchema: { type: 'object' } });
+
+      const result = await registry.callTool('detect-errors', {
+        source: 'build',
+        language: 'go',
+        files: [join(directory, 'a.go'), shared],
+        includeWarnings: true
+      });
+      const report = JSON.parse(result.content[0]!.text as string);
+
+      expect(report.summary).toEqual({ errors: 1, warnings: 2, info: 0, hints: 0, hasErrors: true });
+      expect(report.errors).toHaveLength(3);
+      expect(report.stats.totalErrors).toBe(3);
+    } finally {
+      await manager.dispose();
+    }
+  });
+});
+
+describe('ToolRegistry stats', () => {
+  let directory: string;
+
+  beforeEach(async () => {
+    directory = await fs.realpath(await fs.mkdtemp(join(tmpdir(), 'tool-stats-')));
+    await fs.writeFile(join(directory, 'main.c'), 'int main(void) { return 0; }\n');
+  });
+
+  afterEach(async () => {
+    await fs.rm(directory, { recursive: true, force: true });
+  });
+
+  it('should report analyses, detector timings and cache hits, and start over on reset', async () => {
+    const manager = new LanguageHandlerManager({ enabledLanguages: [], workspaceRoots: [directory] });
+    const registry = new ToolRegistry();
+    const call = async (name: string, args: Record<string, unknown> = {}) => {
+      const result = await registry.callTool(name, args);
+      expect(result.isError).toBeUndefined();
+      return JSON.parse(result.content[0]!.text as string);
+    };
+
+    try {
+      await manager.registerHandler(Object.assign(new EventEmitter() as unknown as LanguageHandler, {
+        language: 'c',
+        initialize: vi.fn(async () => {}),
+        dispose: vi.fn(async () => {}),
+        isAvailable: vi.fn(async () => true),
+        isFileSupported: (filePath: string) => filePath.endsWith('.c'),
+        getFileExtensions: () => ['.c'],
+        getConfigFiles: () => [],
+        detectErrors: vi.fn(async () => {
+          await sleep(5);
+          return [];
+        })
+      }));
+      registry.setLanguageHandlerManager(manager);
+      for (const name of ['list-errors', 'stats']) {
+        await registry.registerTool({ name, description: name, inputSchema: { type: 'object' } });
+      }
+      await call('stats', { reset: true });
+
+      await registry.callTool('list-errors', { path: directory });
+      await registry.callTool('list-errors', { path: directory });
+      const stats = await call('stats', { reset: true });
+
+      expect(stats).toMatchObject({
+        analyses: 2,
+        cache: { hits: 1, misses: 1, hitRatio: 0.5 },
+        watchers: 0,
+        reset: true
+      });
+      expect(stats.detectors.c).toMatchObject({ count: 1, failures: 0, timeouts: 0 });
+      expect(stats.detectors.c.maxMs).toBeGreaterThanOrEqual(4);
+
+      expect(await call('stats')).toMatchObject({ analyses: 0, detectors: {}, cache: { hits: 0, misses: 0, entries: 1 } });
+    } finally {
+      await manager.dispose();
+    }
+  });
+});
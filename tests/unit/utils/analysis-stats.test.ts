@@ -0,0 +1,75 @@
+/**
+ * Tests for analysis counters and latency histograms
+ */
+
+import { describe, it, expect } from 'vitest';
+import { AnalysisStats, LatencyHistogram } from '../../../src/utils/analysis-stats.js';
+
+describe('LatencyHistogram', () => {
+  it('should summarize an empty histogram as zeros', () => {
+    expect(new LatencyHistogram().summary()).toEqual({
+      count: 0, totalMs: 0, avgMs: 0, p50Ms: 0, p90Ms: 0, p99Ms: 0, maxMs: 0
+    });
+  });
+
+  it('should report percentiles as bucket upper bounds capped at the slowest run', () => {
+    const histogram = new LatencyHistogram();
+    for (let index = 0; index < 90; index++) {
+      histogram.record(30);
+    }
+    for (let index = 0; index < 9; index++) {
+      histogram.record(400);
+    }
+    histogram.record(1234);
+
+    expect(histogram.summary()).toEqual({
+      count: 100,
+      totalMs: 7534,
+      avgMs: 75,
+      p50Ms: 50,
+      p90Ms: 50,
+      p99Ms: 500,
+      maxMs: 1234
+    });
+    expect(histogram.percentile(1)).toBe(1234);
+  });
+
+  it('should keep runs slower than the last bucket', () => {
+    const histogram = new LatencyHistogram();
+    histogram.record(90000);
+
+    expect(histogram.summary()).toMatchObject({ p50Ms: 90000, maxMs: 90000 });
+  });
+});
+
+describe('AnalysisStats', () => {
+  it('should count analyses, subprocesses and detector outcomes', () => {
+    const stats = new AnalysisStats();
+    stats.recordAnalysis();
+    stats.recordAnalysis();
+    stats.recordSubprocess();
+    stats.recordDetectorRun('shell', 12, 'ok');
+    stats.recordDetectorRun('go', 700, 'ok');
+    stats.recordDetectorRun('go', 30000, 'timeout');
+    stats.recordDetectorRun('go', 5, 'failed');
+
+    const snapshot = stats.snapshot();
+
+    expect(snapshot).toMatchObject({ analyses: 2, subprocesses: 1 });
+    expect(Object.keys(snapshot.detectors)).toEqual(['go', 'shell']);
+    expect(snapshot.detectors['go']).toMatchObject({ count: 3, failures: 1, timeouts: 1, maxMs: 30000 });
+    expect(snapshot.detectors['shell']).toMatchObject({ count: 1, avgMs: 12, failures: 0, timeouts: 0 });
+  });
+
+  it('should start over on reset', () => {
+    const stats = new AnalysisStats();
+    stats.recordAnalysis();
+    stats.recordDetectorRun('go', 10, 'ok');
+    const before = stats.snapshot().since;
+
+    stats.reset();
+
+    expect(stats.snapshot()).toMatchObject({ analyses: 0, subprocesses: 0, detectors: {} });
+    expect(Date.parse(stats.snapshot().since)).toBeGreaterThanOrEqual(Date.parse(before));
+  });
+});
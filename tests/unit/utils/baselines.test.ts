@@ -0,0 +1,38 @@
+/**
+ * Tests for stored diagnostic baselines
+ */
+
+import { describe, it, expect } from 'vitest';
+import { DiagnosticBaselineStore } from '../../../src/utils/baselines.js';
+import { toDiagnosticRecord } from '../../../src/utils/diagnostics.js';
+
+const record = toDiagnosticRecord({
+  message: 'undefined: x',
+  severity: 'error',
+  location: { file: '/repo/main.go', line: 3, column: 2 },
+  source: 'go'
+});
+
+describe('DiagnosticBaselineStore', () => {
+  it('should keep a copy of the diagnostics under a fresh id', () => {
+    const store = new DiagnosticBaselineStore();
+    const diagnostics = [record];
+
+    const first = store.save('/repo', 'all', diagnostics);
+    const second = store.save('/repo', 'error', []);
+    diagnostics[0]!.line = 99;
+
+    expect(first.id).not.toBe(second.id);
+    expect(store.get(first.id)).toMatchObject({ path: '/repo', severity: 'all', diagnostics: [{ line: 3 }] });
+    expect(store.get('baseline-unknown-1')).toBeUndefined();
+  });
+
+  it('should drop the oldest baseline beyond its capacity', () => {
+    const store = new DiagnosticBaselineStore(2);
+    const ids = [1, 2, 3].map(() => store.save('/repo', 'all', [record]).id);
+
+    expect(store.size).toBe(2);
+    expect(store.get(ids[0]!)).toBeUndefined();
+    expect(store.get(ids[2]!)).toBeDefined();
+  });
+});
@@ -0,0 +1,202 @@
+/**
+ * Tests for cancelable tool execution
+ */
+
+import { describe, it, expect, vi, afterEach } from 'vitest';
+import { promises as fs, readFileSync } from 'fs';
+import { tmpdir } from 'os';
+import { join } from 'path';
+import { GoHandler } from '../../../src/languages/go-handler.js';
+import { LanguageHandlerManager } from '../../../src/languages/language-handler-manager.js';
+import {
+  AnalysisCanceledError,
+  AnalysisTimeoutError,
+  DetectorTimeoutError,
+  anySignal,
+  runWithSignal
+} from '../../../src/utils/cancellation.js';
+import { ToolFailedError } from '../../../src/utils/errors.js';
+
+describe('cancellation', () => {
+  const handler = new GoHandler();
+  // Spawns a grandchild so the test covers process-group cleanup
+  const slowCommand = ['sh', ['-c', 'sleep 5 & wait']] as const;
+
+  it('should kill the tool and report a timeout when the deadline expires', async () => {
+    const startTime = Date.now();
+
+    await expect(
+      (handler as any).runCommand(slowCommand[0], slowCommand[1], { signal: AbortSignal.timeout(50) })
+    ).rejects.toBeInstanceOf(AnalysisTimeoutError);
+
+    expect(Date.now() - startTime).toBeLessThan(2000);
+  });
+
+  it('should distinguish an explicit cancel from a timeout', async () => {
+    const controller = new AbortController();
+    setTimeout(() => controller.abort(), 20);
+
+    const result = (handler as any).runCommand(slowCommand[0], slowCommand[1], { signal: controller.signal });
+
+    await expect(result).rejects.toBeInstanceOf(AnalysisCanceledError);
+    await expect(result).rejects.not.toBeInstanceOf(AnalysisTimeoutError);
+  });
+
+  it('should inherit the signal from runWithSignal', async () => {
+    await expect(
+      runWithSignal(AbortSignal.timeout(50), () => (handler as any).runCommand(slowCommand[0], slowCommand[1]))
+    ).rejects.toThrow('Analysis timed out');
+  });
+
+  it('should not start tools once the signal has aborted', async () => {
+    const controller = new AbortController();
+    controller.abort();
+
+    await expect(
+      (handler as any).runCommand('echo', ['hello'], { signal: controller.signal })
+    ).rejects.toThrow('Analysis canceled');
+  });
+
+  it('should fail a tool killed by a signal instead of reporting a clean run', async () => {
+    const result = (handler as any).runCommand('sh', ['-c', 'echo partial >&2; kill -KILL $$']);
+
+    await expect(result).rejects.toBeInstanceOf(ToolFailedError);
+    await expect(result).rejects.toMatchObject({ exitCode: 137, message: 'sh was killed by SIGKILL: partial' });
+  });
+
+  it('should combine signals and keep the first abort reason', () => {
+    const first = new AbortController();
+    const second = new AbortController();
+    const combined = anySignal([first.signal, undefined, second.signal])!;
+
+    second.abort(new DetectorTimeoutError('go', 10));
+    first.abort();
+
+    expect(combined.reason).toBeInstanceOf(DetectorTimeoutError);
+    expect(anySignal([undefined])).toBeUndefined();
+  });
+
+  describe('detector deadlines', () => {
+    let manager: LanguageHandlerManager;
+
+    afterEach(async () => {
+      await manager?.dispose();
+    });
+
+    async function createManager(timeoutMs: number): Promise<GoHandler> {
+      const goHandler = new GoHandler();
+      // Skip toolchain discovery; the tool itself is replaced below
+      (goHandler as any).isInitialized = true;
+      (goHandler as any).goPath = 'go';
+
+      manager = new LanguageHandlerManager({ enabledLanguages: [], timeouts: { go: timeoutMs } });
+      await manager.registerHandler(goHandler);
+      return goHandler;
+    }
+
+    it('should kill a slow tool and return its partial output with a timeout error', async () => {
+      const goHandler = await createManager(200);
+      // A build that reports one error and then hangs, like a stuck module download
+      const runInTempModule = vi.spyOn(goHandler as any, 'runInTempModule').mockImplementation(() =>
+        (goHandler as any).runCommand('sh', ['-c', 'echo "./main.go:3:5: undefined: x" >&2; sleep 5 & wait'])
+      );
+      const startTime = Date.now();
+
+      const errors = await manager.detectErrors('package main\n', 'go', { filePath: 'main.go' });
+
+      expect(Date.now() - startTime).toBeLessThan(2000);
+      expect(errors.map(error => error.message)).toEqual(['undefined: x', 'analysis timed out']);
+      expect(errors[1]).toMatchObject({ severity: 'error', code: 'timeout' });
+      // go vet is skipped once the deadline has passed
+      expect(runInTempModule).toHaveBeenCalledTimes(2);
+    });
+
+    it('should report nothing extra when the tool finishes in time', async () => {
+      const goHandler = await createManager(2000);
+      vi.spyOn(goHandler as any, 'runInTempModule').mockImplementation(() =>
+        (goHandler as any).runCommand('sh', ['-c', 'exit 0'])
+      );
+
+      expect(await manager.detectErrors('package main\n', 'go', { filePath: 'main.go' })).toEqual([]);
+    });
+
+    it('should report a build killed from outside rather than a clean file', async () => {
+      const goHandler = await createManager(2000);
+      vi.spyOn(goHandler as any, 'runInTempModule').mockImplementation(() =>
+        (goHandler as any).runCommand('sh', ['-c', 'kill -KILL $$'])
+      );
+
+      const errors = await manager.detectErrors('package main\n', 'go', { filePath: 'main.go' });
+
+      expect(errors).toEqual([expect.objectContaining({ severity: 'error', message: 'Syntax validation failed: sh was killed by SIGKILL' })]);
+    });
+
+    it('should still discard results when the caller cancels', async () => {
+      const goHandler = await createManager(2000);
+      vi.spyOn(goHandler as any, 'runInTempModule').mockImplementation(() =>
+        (goHandler as any).runCommand('sh', ['-c', 'sleep 5 & wait'])
+      );
+      const controller = new AbortController();
+      setTimeout(() => controller.abort(), 50);
+
+      await expect(
+        manager.detectErrors('package main\n', 'go', { filePath: 'main.go', signal: controller.signal })
+      ).rejects.toBeInstanceOf(AnalysisCanceledError);
+    });
+  });
+
+  describe('shutdown', () => {
+    function isRunning(pid: number): boolean {
+      try {
+        process.kill(pid, 0);
+      } catch {
+        return false;
+      }
+      // A killed process that has not been reaped yet is a zombie, not running
+      try {
+        return !/^\d+ \(.*\) Z/.test(readFileSync(`/proc/${pid}/stat`, 'utf-8'));
+      } catch {
+        return true;
+      }
+    }
+
+    it('should cancel running analyses and leave no tool processes behind', async () => {
+      const goHandler = new GoHandler();
+      (goHandler as any).isInitialized = true;
+      (goHandler as any).goPath = 'go';
+      const manager = new LanguageHandlerManager({ enabledLanguages: [] });
+      await manager.registerHandler(goHandler);
+
+      const dir = await fs.mkdtemp(join(tmpdir(), 'shutdown-test-'));
+      const pidFile = join(dir, 'pids');
+      // The tool and a grandchild it spawned record their pids, then hang
+      vi.spyOn(goHandler as any, 'runInTempModule').mockImplementation(() =>
+        (goHandler as any).runCommand('sh', ['-c', `echo $$ > ${pidFile}; sleep 30 & echo $! >> ${pidFile}; wait`])
+      );
+
+      try {
+        const analysis = manager.detectErrors('package main\n', 'go', { filePath: 'main.go' });
+        analysis.catch(() => undefined);
+        let pids: number[] = [];
+        await vi.waitFor(async () => {
+          pids = (await fs.readFile(pidFile, 'utf-8')).trim().split('\n').map(Number);
+          expect(pids).toHaveLength(2);
+        }, { timeout: 2000, interval: 20 });
+        expect(manager.getInFlightCount()).toBe(1);
+
+        const startTime = Date.now();
+        await manager.shutdown(2000);
+
+        expect(Date.now() - startTime).toBeLessThan(2000);
+        await expect(analysis).rejects.toThrow('Server is shutting down');
+        expect(manager.getInFlightCount()).toBe(0);
+        expect(pids.filter(isRunning)).toEqual([]);
+
+        // Nothing new starts once shut down
+        await expect(manager.detectErrors('package main\n', 'go', { filePath: 'main.go' })).rejects.toBeInstanceOf(AnalysisCanceledError);
+      } finally {
+        await fs.rm(dir, { recursive: true, force: true });
+      }
+    });
+  });
+});
@@ -0,0 +1,133 @@
+/**
+ * Tests for converting tool columns into code points
+ */
+
+import { describe, it, expect, vi } from 'vitest';
+import { EventEmitter } from 'events';
+import { promises as fs, readFileSync } from 'fs';
+import { tmpdir } from 'os';
+import { join } from 'path';
+import { normalizeColumns, stripBom, toCodePointColumn, UTF8_BOM } from '../../../src/utils/columns.js';
+import { LanguageHandlerManager } from '../../../src/languages/language-handler-manager.js';
+import type { ColumnEncoding, LanguageError, LanguageHandler } from '../../../src/types/languages.js';
+
+const fixturesDir = join(__dirname, '../../fixtures/go');
+const BOM_SOURCE = readFileSync(join(fixturesDir, 'bom.go'), 'utf-8');
+const GO_ENCODING: ColumnEncoding = { unit: 'byte', includesBom: true };
+
+/** The diagnostics of bom.stderr, as the go command reported them */
+function goErrors(file: string): LanguageError[] {
+  return readFileSync(join(fixturesDir, 'bom.stderr'), 'utf-8')
+    .split('\n')
+    .map(line => line.match(/^\.\/bom\.go:(\d+):(\d+): (.+)$/))
+    .filter((match): match is RegExpMatchArray => match !== null)
+    .map(match => ({
+      message: match[3]!,
+      severity: 'error' as const,
+      location: { file, line: parseInt(match[1]!), column: parseInt(match[2]!) },
+      source: 'go'
+    }));
+}
+
+const positions = (errors: LanguageError[]) => errors.map(error => [error.location.line, error.location.column]);
+
+describe('columns', () => {
+  describe('toCodePointColumn', () => {
+    const line = 'x := "héllo" + 😀 + y';
+
+    it('should count multi-byte characters once', () => {
+      // y
+      expect(toCodePointColumn(line, 24, 'byte')).toBe(20);
+      expect(toCodePointColumn(line, 21, 'utf16')).toBe(20);
+      expect(toCodePointColumn(line, 20, 'codepoint')).toBe(20);
+    });
+
+    it('should leave ASCII lines alone and count a tab as one', () => {
+      expect(toCodePointColumn('\treturn y', 9, 'byte')).toBe(9);
+    });
+
+    it('should land on a character a column points into and keep columns past the end', () => {
+      // The second byte of é
+      expect(toCodePointColumn(line, 9, 'byte')).toBe(8);
+      // One past the end, and two past it
+      expect(toCodePointColumn(line, 25, 'byte')).toBe(21);
+      expect(toCodePointColumn(line, 26, 'byte')).toBe(22);
+    });
+  });
+
+  it('should strip a leading BOM only', () => {
+    expect(stripBom(`${UTF8_BOM}package main`)).toBe('package main');
+    expect(stripBom(`package ${UTF8_BOM}`)).toBe(`package ${UTF8_BOM}`);
+  });
+
+  describe('normalizeColumns', () => {
+    it('should not count the BOM of the fixture, nor the bytes of its accented letters', () => {
+      const errors = normalizeColumns(goErrors('/repo/bom.go'), BOM_SOURCE, GO_ENCODING, '/repo/bom.go');
+
+      // undefinedA follows `package main; var _ = `, undefinedB a tab and two accented letters
+      expect(positions(errors)).toEqual([[1, 23], [4, 44]]);
+    });
+
+    it('should keep first-line columns of tools that already skip the BOM', () => {
+      const errors = normalizeColumns(
+        [{ message: 'undefined', severity: 'error', location: { file: 'bom.go', line: 1, column: 23, endColumn: 33 }, source: 'rustc' }],
+        BOM_SOURCE,
+        { unit: 'codepoint', includesBom: false },
+        'bom.go'
+      );
+
+      expect(errors[0]!.location).toMatchObject({ column: 23, endColumn: 33 });
+    });
+
+    it('should convert range ends and honor a per-diagnostic unit', () => {
+      const source = 'package main\r\nvar s = "😀" + undefinedC\r\n';
+      const errors = normalizeColumns([{
+        message: 'undefined: undefinedC',
+        severity: 'error',
+        location: { file: 'main.go', line: 2, column: 16, endLine: 2, endColumn: 26 },
+        source: 'gopls',
+        columnUnit: 'utf16'
+      }], source, GO_ENCODING);
+
+      expect(errors[0]!.location).toMatchObject({ column: 15, endColumn: 25 });
+      expect('columnUnit' in errors[0]!).toBe(false);
+    });
+
+    it('should pass positions in other files through', () => {
+      const other: LanguageError = {
+        message: 'undefined: helper',
+        severity: 'error',
+        location: { file: '/repo/util.go', line: 4, column: 46 },
+        source: 'go'
+      };
+
+      expect(normalizeColumns([other], BOM_SOURCE, GO_ENCODING, '/repo/bom.go')).toEqual([other]);
+    });
+  });
+
+  it('should report code points through the manager', async () => {
+    const directory = await fs.realpath(await fs.mkdtemp(join(tmpdir(), 'columns-test-')));
+    const file = join(directory, 'bom.go');
+    await fs.writeFile(file, BOM_SOURCE);
+    const manager = new LanguageHandlerManager({ enabledLanguages: [], workspaceRoots: [directory] });
+
+    try {
+      await manager.registerHandler(Object.assign(new EventEmitter() as unknown as LanguageHandler, {
+        language: 'gobom',
+        initialize: vi.fn(async () => {}),
+        dispose: vi.fn(async () => {}),
+        isAvailable: vi.fn(async () => true),
+        isFileSupported: (filePath: string) => filePath.endsWith('.go'),
+        getFileExtensions: () => ['.go'],
+        getConfigFiles: () => [],
+        getColumnEncoding: () => GO_ENCODING,
+        detectErrors: vi.fn(async () => goErrors(file))
+      }));
+
+      expect(positions(await manager.analyzeFile(file))).toEqual([[1, 23], [4, 44]]);
+    } finally {
+      await manager.dispose();
+      await fs.rm(directory, { recursive: true, force: true });
+    }
+  });
+});
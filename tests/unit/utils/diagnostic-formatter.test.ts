@@ -0,0 +1,86 @@
+/**
+ * Tests for the human-readable diagnostic report
+ */
+
+import { describe, it, expect } from 'vitest';
+import { formatDiagnosticsText, formatSummaryLine } from '../../../src/utils/diagnostic-formatter.js';
+import { toDiagnosticRecord, type DiagnosticRecord } from '../../../src/utils/diagnostics.js';
+import type { LanguageError } from '../../../src/types/languages.js';
+
+function record(file: string, line: number, column: number, severity: LanguageError['severity'], message: string, code?: string): DiagnosticRecord {
+  return toDiagnosticRecord({
+    message,
+    severity,
+    location: { file, line, column },
+    source: 'test',
+    ...(code && { code })
+  });
+}
+
+describe('diagnostic formatter', () => {
+  it('should group by file, sort files and positions, and end with a summary', () => {
+    const text = formatDiagnosticsText([
+      record('/repo/src/main.go', 8, 2, 'error', 'undefined: x'),
+      record('/repo/src/app.ts', 12, 10, 'warning', 'unused', 'TS6133'),
+      record('/repo/src/app.ts', 3, 5, 'error', 'type mismatch', 'TS2322'),
+      record('/repo/src/app.ts', 3, 1, 'info', 'note')
+    ], { baseDir: '/repo' });
+
+    expect(text).toBe([
+      'src/app.ts',
+      '  3:1    info     note',
+      '  3:5    error    type mismatch [TS2322]',
+      '  12:10  warning  unused [TS6133]',
+      '',
+      'src/main.go',
+      '  8:2  error    undefined: x',
+      '',
+      '2 errors, 1 warning, 1 info across 2 files'
+    ].join('\n'));
+  });
+
+  it('should keep absolute paths for files outside the base directory', () => {
+    const text = formatDiagnosticsText([record('/elsewhere/lib.rs', 1, 1, 'error', 'oops')], { baseDir: '/repo' });
+
+    expect(text.split('\n')[0]).toBe('/elsewhere/lib.rs');
+  });
+
+  it('should indent continuation lines of multi-line messages', () => {
+    const text = formatDiagnosticsText([record('/repo/a.ts', 1, 1, 'error', 'first\nsecond')], { baseDir: '/repo' });
+
+    expect(text.split('\n').slice(0, 3)).toEqual([
+      'a.ts',
+      '  1:1  error    first',
+      '                second'
+    ]);
+  });
+
+  it('should mention truncation and report an empty result', () => {
+    const page = [record('/repo/a.ts', 1, 1, 'error', 'oops')];
+    const truncated = formatDiagnosticsText(page, {
+      baseDir: '/repo',
+      summary: { errors: 4, warnings: 0, info: 0, hints: 0, hasErrors: true },
+      total: 4,
+      fileCount: 3
+    });
+
+    expect(truncated.endsWith('4 errors, 0 warnings across 3 files (showing 1 of 4)')).toBe(true);
+    expect(formatDiagnosticsText(page, { baseDir: '/repo', total: 4, offset: 2 }).endsWith('(showing 3-3 of 4)')).toBe(true);
+    expect(formatDiagnosticsText([])).toBe('0 errors, 0 warnings across 0 files');
+  });
+
+  it('should label files by workspace-relative path, naming the root when there are several', () => {
+    const api = { ...record('/work/api/main.go', 1, 1, 'error', 'oops'), root: '/work/api', relativePath: 'main.go' };
+    const web = { ...record('/work/web/main.go', 2, 1, 'error', 'oops'), root: '/work/web', relativePath: 'main.go' };
+
+    expect(formatDiagnosticsText([api]).split('\n')[0]).toBe('main.go');
+
+    const headers = formatDiagnosticsText([web, api]).split('\n\n').map(section => section.split('\n')[0]);
+    expect(headers.slice(0, 2)).toEqual(['api/main.go', 'web/main.go']);
+  });
+
+  it('should pluralize summary counts', () => {
+    expect(formatSummaryLine({ errors: 1, warnings: 2, info: 0, hints: 1, hasErrors: true }, 1))
+      .toBe('1 error, 2 warnings, 1 hint across 1 file');
+  });
+});
@@ -0,0 +1,233 @@
+/**
+ * Tests for diagnostic post-processing
+ */
+
+import { describe, it, expect } from 'vitest';
+import {
+  compareDiagnostics,
+  dedupeDiagnostics,
+  diffDiagnostics,
+  matchesSeverityFilter,
+  normalizeMessage,
+  paginateDiagnostics,
+  parseDiagnosticRecord,
+  sortDiagnostics,
+  summarizeDiagnostics,
+  toDiagnosticRecord
+} from '../../../src/utils/diagnostics.js';
+import type { LanguageError } from '../../../src/types/languages.js';
+
+const languageError = (overrides: Partial<LanguageError> = {}): LanguageError => ({
+  message: '"fmt" imported and not used',
+  severity: 'error',
+  location: { file: '/repo/main.go', line: 3, column: 2 },
+  source: 'go',
+  ...overrides
+});
+
+describe('diagnostics', () => {
+  describe('toDiagnosticRecord', () => {
+    it('should clamp positions and default end positions to the start', () => {
+      const record = toDiagnosticRecord(languageError({
+        location: { file: '/repo/main.go', line: 0, column: 0 }
+      }));
+
+      expect(record).toMatchObject({ line: 1, column: 1, endLine: 1, endColumn: 1, code: null });
+      expect(record.sources).toEqual(['go']);
+    });
+  });
+
+  describe('matchesSeverityFilter', () => {
+    it('should treat warning as a minimum severity', () => {
+      expect(matchesSeverityFilter('error', 'warning')).toBe(true);
+      expect(matchesSeverityFilter('info', 'warning')).toBe(false);
+      expect(matchesSeverityFilter('hint', 'all')).toBe(true);
+    });
+  });
+
+  describe('dedupeDiagnostics', () => {
+    it('should merge overlapping results from two detectors', () => {
+      const compiler = [
+        toDiagnosticRecord(languageError()),
+        toDiagnosticRecord(languageError({ message: 'undefined: x', location: { file: '/repo/main.go', line: 7, column: 1 } }))
+      ];
+      const linter = [
+        toDiagnosticRecord(languageError({
+          message: "'fmt' imported and not used.",
+          severity: 'warning',
+          source: 'golangci-lint',
+          analyzer: 'unused',
+          code: 'unused'
+        }))
+      ];
+
+      const merged = dedupeDiagnostics([...linter, ...compiler]);
+
+      expect(merged).toHaveLength(2);
+      expect(merged[0]).toMatchObject({
+        severity: 'error',
+        source: 'go',
+        code: 'unused',
+        sources: ['golangci-lint', 'go'],
+        analyzers: ['unused']
+      });
+    });
+
+    it('should honour a custom key', () => {
+      const records = [
+        toDiagnosticRecord(languageError({ location: { file: '/repo/main.go', line: 3, column: 1 } })),
+        toDiagnosticRecord(languageError({ location: { file: '/repo/main.go', line: 3, column: 9 }, source: 'vet' }))
+      ];
+
+      expect(dedupeDiagnostics(records)).toHaveLength(2);
+      expect(dedupeDiagnostics(records, ['file', 'line', 'message'])).toHaveLength(1);
+    });
+
+    it('should normalize quoting, case and punctuation', () => {
+      expect(normalizeMessage('Cannot find name `foo`.')).toBe(normalizeMessage("cannot find  name 'foo'"));
+    });
+  });
+
+  describe('summarizeDiagnostics', () => {
+    it('should count diagnostics per severity', () => {
+      const summary = summarizeDiagnostics([
+        { severity: 'error' },
+        { severity: 'warning' },
+        { severity: 'warning' },
+        { severity: 'info' },
+        { severity: 'hint' }
+      ]);
+
+      expect(summary).toEqual({ errors: 1, warnings: 2, info: 1, hints: 1, hasErrors: true });
+    });
+
+    it('should return zero counts for empty results', () => {
+      expect(summarizeDiagnostics([])).toEqual({ errors: 0, warnings: 0, info: 0, hints: 0, hasErrors: false });
+    });
+  });
+
+  describe('sortDiagnostics', () => {
+    it('should order by file, line, column, severity and message', () => {
+      const records = [
+        languageError({ location: { file: '/repo/b.go', line: 1, column: 1 } }),
+        languageError({ severity: 'warning', message: 'b', location: { file: '/repo/a.go', line: 2, column: 1 } }),
+        languageError({ severity: 'error', message: 'z', location: { file: '/repo/a.go', line: 2, column: 1 } }),
+        languageError({ severity: 'warning', message: 'a', location: { file: '/repo/a.go', line: 2, column: 1 } }),
+        languageError({ location: { file: '/repo/a.go', line: 2, column: 9 } }),
+        languageError({ location: { file: '/repo/a.go', line: 10, column: 1 } })
+      ].map(toDiagnosticRecord);
+
+      expect(sortDiagnostics(records).map(record => `${record.file}:${record.line}:${record.column} ${record.severity} ${record.message}`)).toEqual([
+        '/repo/a.go:2:1 error z',
+        '/repo/a.go:2:1 warning a',
+        '/repo/a.go:2:1 warning b',
+        '/repo/a.go:2:9 error "fmt" imported and not used',
+        '/repo/a.go:10:1 error "fmt" imported and not used',
+        '/repo/b.go:1:1 error "fmt" imported and not used'
+      ]);
+    });
+
+    it('should serialize the same whatever order the diagnostics arrive in', () => {
+      const records = [
+        languageError({ source: 'go' }),
+        languageError({ source: 'gopls', code: 'UnusedImport' }),
+        languageError({ source: 'go', location: { file: '/repo/main.go', line: 3, column: 2, endLine: 3, endColumn: 7 } }),
+        languageError({ severity: 'hint', source: 'staticcheck' })
+      ].map(toDiagnosticRecord);
+      const expected = JSON.stringify(sortDiagnostics(records));
+
+      for (const order of [[3, 2, 1, 0], [1, 3, 0, 2], [2, 0, 3, 1]]) {
+        expect(JSON.stringify(sortDiagnostics(order.map(index => records[index]!)))).toBe(expected);
+      }
+      expect(sortDiagnostics(records)).not.toBe(records);
+    });
+  });
+
+  describe('paginateDiagnostics', () => {
+    const records = [
+      toDiagnosticRecord(languageError({ severity: 'warning', location: { file: '/repo/a.go', line: 1, column: 1 } })),
+      toDiagnosticRecord(languageError({ severity: 'info', location: { file: '/repo/a.go', line: 2, column: 1 } })),
+      toDiagnosticRecord(languageError({ severity: 'error', location: { file: '/repo/b.go', line: 9, column: 1 } })),
+      toDiagnosticRecord(languageError({ severity: 'error', location: { file: '/repo/a.go', line: 5, column: 1 } }))
+    ];
+
+    it('should keep errors first when truncating', () => {
+      const page = paginateDiagnostics(records, { limit: 2 });
+
+      expect(page.diagnostics.map(record => `${record.file}:${record.line}`)).toEqual(['/repo/a.go:5', '/repo/b.go:9']);
+      expect(page).toMatchObject({ total: 4, truncated: true, omittedCount: 2, offset: 0, nextOffset: 2 });
+    });
+
+    it('should visit every diagnostic once when following nextOffset', () => {
+      const seen: string[] = [];
+      let offset: number | null = 0;
+
+      while (offset !== null) {
+        const page = paginateDiagnostics(records.slice().reverse(), { offset, limit: 3 });
+        seen.push(...page.diagnostics.map(record => `${record.file}:${record.line}`));
+        offset = page.nextOffset;
+      }
+
+      // Pages are cut in priority order and each is returned in canonical order
+      expect(seen).toEqual(['/repo/a.go:1', '/repo/a.go:5', '/repo/b.go:9', '/repo/a.go:2']);
+    });
+
+    it('should report an untruncated result', () => {
+      expect(paginateDiagnostics(records)).toMatchObject({ truncated: false, omittedCount: 0, nextOffset: null, limit: 1000 });
+    });
+  });
+
+  describe('diffDiagnostics', () => {
+    it('should report added, removed and unchanged diagnostics', () => {
+      const kept = toDiagnosticRecord(languageError());
+      const gone = toDiagnosticRecord(languageError({ message: 'undefined: x' }));
+      const fresh = toDiagnosticRecord(languageError({ message: 'undefined: y' }));
+
+      const diff = diffDiagnostics([kept, gone], [kept, fresh]);
+
+      expect(diff.added).toEqual([fresh]);
+      expect(diff.removed).toEqual([gone]);
+      expect(diff.unchanged).toEqual([kept]);
+    });
+  });
+
+  describe('compareDiagnostics', () => {
+    const at = (line: number, message = 'undefined: x') =>
+      toDiagnosticRecord(languageError({ message, location: { file: '/repo/main.go', line, column: 2 } }));
+
+    it('should match diagnostics that moved within the tolerance', () => {
+      const comparison = compareDiagnostics([at(10), at(20, 'undefined: y')], [at(12), at(30, 'undefined: z')]);
+
+      expect(comparison.persisting).toEqual([{ ...at(12), baselineLine: 10 }]);
+      expect(comparison.fixed).toEqual([at(20, 'undefined: y')]);
+      expect(comparison.new).toEqual([at(30, 'undefined: z')]);
+    });
+
+    it('should report moves beyond the tolerance as fixed and new', () => {
+      expect(compareDiagnostics([at(10)], [at(14)])).toEqual({ fixed: [at(10)], new: [at(14)], persisting: [] });
+      expect(compareDiagnostics([at(10)], [at(11)], { lineTolerance: 0 })).toEqual({ fixed: [at(10)], new: [at(11)], persisting: [] });
+      expect(compareDiagnostics([at(10)], [at(16)], { lineTolerance: 6 }).persisting).toHaveLength(1);
+    });
+
+    it('should prefer same-line matches and pair duplicates one-for-one', () => {
+      const comparison = compareDiagnostics([at(10), at(12)], [at(12), at(13), at(14)]);
+
+      expect(comparison.persisting).toEqual([at(12), { ...at(13), baselineLine: 10 }]);
+      expect(comparison.new).toEqual([at(14)]);
+      expect(comparison.fixed).toEqual([]);
+    });
+  });
+
+  describe('parseDiagnosticRecord', () => {
+    it('should fill defaults for a minimal diagnostic and keep workspace fields', () => {
+      const record = parseDiagnosticRecord({ file: '/repo/a.go', line: 4, message: 'undefined: x', root: '/repo', relativePath: 'a.go' });
+
+      expect(record).toMatchObject({ file: '/repo/a.go', line: 4, column: 1, severity: 'error', code: null, root: '/repo', relativePath: 'a.go' });
+    });
+
+    it('should reject diagnostics without a position or message', () => {
+      expect(() => parseDiagnosticRecord('main.go:4')).toThrow('expected an object');
+      expect(() => parseDiagnosticRecord({ file: '/repo/a.go', message: 'x' })).toThrow('file, line and message are required');
+    });
+  });
+});
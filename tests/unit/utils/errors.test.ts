@@ -0,0 +1,91 @@
+/**
+ * Tests for the typed errors of the detector layer
+ */
+
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { promises as fs } from 'fs';
+import { tmpdir } from 'os';
+import { join } from 'path';
+import {
+  NoFilesError,
+  OutsideWorkspaceError,
+  ToolFailedError,
+  ToolNotFoundError,
+  UnsupportedLanguageError,
+  findError,
+  getErrorCode,
+  isToolFailedError,
+  isToolNotFoundError
+} from '../../../src/utils/errors.js';
+import { AnalysisCanceledError, DetectorTimeoutError } from '../../../src/utils/cancellation.js';
+import { GoplsStartError } from '../../../src/languages/gopls-client.js';
+import { LanguageHandlerManager } from '../../../src/languages/language-handler-manager.js';
+
+describe('typed errors', () => {
+  it('should recover the concrete error from a wrapped one', () => {
+    const failed = new ToolFailedError('go list', 1, 'go: cannot find main module\n');
+    const wrapped = new Error('Listing packages failed', { cause: new Error('go list failed', { cause: failed }) });
+
+    const found = findError(wrapped, ToolFailedError);
+
+    expect(found).toBe(failed);
+    expect(found).toMatchObject({ tool: 'go list', exitCode: 1, stderr: 'go: cannot find main module\n' });
+    expect(found!.message).toBe('go list exited with code 1: go: cannot find main module');
+    expect(isToolFailedError(wrapped)).toBe(true);
+    expect(findError(wrapped, ToolNotFoundError)).toBeUndefined();
+  });
+
+  it('should find subclasses and stop at cyclic causes', () => {
+    const start = new GoplsStartError('gopls failed to start: exit status 2', 'panic: bad flag');
+    const cyclic = new Error('outer');
+    cyclic.cause = cyclic;
+
+    expect(findError(start, ToolFailedError)).toBe(start);
+    expect(findError(cyclic, ToolFailedError)).toBeUndefined();
+    expect(findError('not an error', Error)).toBeUndefined();
+  });
+
+  it('should map each failure mode to its code', () => {
+    expect(getErrorCode(new ToolNotFoundError('golangci-lint'))).toBe('tool-not-found');
+    expect(getErrorCode(new ToolFailedError('go env', 2, ''))).toBe('tool-failed');
+    expect(getErrorCode(new NoFilesError('/repo/docs'))).toBe('no-files');
+    expect(getErrorCode(new UnsupportedLanguageError('cobol'))).toBe('unsupported-language');
+    expect(getErrorCode(new OutsideWorkspaceError('/tmp/x.go', ['/repo']))).toBe('outside-workspace');
+    expect(getErrorCode(new DetectorTimeoutError('go', 100))).toBe('timeout');
+    expect(getErrorCode(new AnalysisCanceledError())).toBe('canceled');
+    expect(getErrorCode(new Error('wrapper', { cause: new ToolNotFoundError('go') }))).toBe('tool-not-found');
+    expect(getErrorCode(new Error('something else'))).toBeUndefined();
+  });
+});
+
+describe('LanguageHandlerManager failure modes', () => {
+  let directory: string;
+  let manager: LanguageHandlerManager;
+
+  beforeEach(async () => {
+    directory = await fs.mkdtemp(join(tmpdir(), 'typed-errors-'));
+    manager = new LanguageHandlerManager({ enabledLanguages: [], workspaceRoots: [directory] });
+  });
+
+  afterEach(async () => {
+    await manager.dispose();
+    await fs.rm(directory, { recursive: true, force: true });
+  });
+
+  it('should throw NoFilesError for a directory without supported files', async () => {
+    await fs.writeFile(join(directory, 'README'), 'nothing to analyze\n');
+
+    const error = await manager.analyzePath(directory).catch(caught => caught);
+
+    expect(error).toBeInstanceOf(NoFilesError);
+    expect(error).toMatchObject({ path: directory, message: `No supported files found in ${directory}` });
+  });
+
+  it('should throw UnsupportedLanguageError for unknown languages', () => {
+    expect(() => manager.setDetectorEnabled('cobol', false)).toThrow(UnsupportedLanguageError);
+  });
+
+  it('should keep ToolNotFoundError recognizable when wrapped', () => {
+    expect(isToolNotFoundError(new Error('detect failed', { cause: new ToolNotFoundError('go') }))).toBe(true);
+  });
+});
@@ -0,0 +1,73 @@
+/**
+ * Tests for rule-based error explanations
+ */
+
+import { describe, it, expect } from 'vitest';
+import { DEFAULT_EXPLANATION_RULES, explainError, type ExplanationRule } from '../../../src/utils/explanations.js';
+
+describe('explainError', () => {
+  it.each([
+    ['"os" imported and not used', 'go-unused-import'],
+    ['declared and not used: count', 'go-unused-variable'],
+    ['count declared but not used', 'go-unused-variable'],
+    ['cannot use n (variable of type int) as string value in return statement', 'go-cannot-use'],
+    ['cannot use n (type int) as type string in return argument', 'go-cannot-use'],
+    ['cannot use Store{} (value of type Store) as Getter value in variable declaration: Store does not implement Getter (method Get has pointer receiver)', 'go-does-not-implement'],
+    ['undefined: helper', 'go-undefined'],
+    ['b.Writestring undefined (type strings.Builder has no field or method Writestring, but does have method WriteString)', 'go-undefined-field'],
+    ['missing return', 'go-missing-return'],
+    ['no new variables on left side of :=', 'go-no-new-variables'],
+    ['assignment mismatch: 1 variable but strconv.Atoi returns 2 values', 'go-assignment-mismatch'],
+    ['multiple-value strconv.Atoi(text) (value of type (int, error)) in single-value context', 'go-multiple-value'],
+    ['not enough arguments in call to div', 'go-argument-count'],
+    ['too many return values', 'go-argument-count'],
+    ['invalid operation: total + n (mismatched types int64 and int)', 'go-mismatched-types'],
+    ['import cycle not allowed', 'go-import-cycle'],
+    ['fatal error: all goroutines are asleep - deadlock!', 'go-deadlock'],
+    ['runtime error: invalid memory address or nil pointer dereference', 'go-nil-dereference']
+  ])('should recognize %s', (message, id) => {
+    expect(explainError({ message, source: 'go' })?.id).toBe(id);
+  });
+
+  it('should give every rule an explanation and a broken and fixed example', () => {
+    for (const rule of DEFAULT_EXPLANATION_RULES) {
+      expect(rule.explanation.length).toBeGreaterThan(40);
+      expect(rule.example.broken).not.toBe(rule.example.fixed);
+    }
+  });
+
+  it('should only match the first line of the message', () => {
+    const message = 'not enough return values\n\thave (int)\n\twant (int, error)';
+
+    expect(explainError({ message, source: 'go' })).toMatchObject({
+      id: 'go-argument-count',
+      title: 'Wrong number of arguments or results'
+    });
+  });
+
+  it('should try every rule when no source is given', () => {
+    expect(explainError({ message: 'missing return' })?.id).toBe('go-missing-return');
+    expect(explainError({ message: 'missing return', source: 'gopls' })?.id).toBe('go-missing-return');
+    expect(explainError({ message: 'missing return', source: 'rustc' })).toBeUndefined();
+  });
+
+  it('should return undefined for unrecognized messages', () => {
+    expect(explainError({ message: 'something unusual happened', source: 'go' })).toBeUndefined();
+  });
+
+  it('should accept custom rules with a code', () => {
+    const rules: ExplanationRule[] = [{
+      id: 'ts-2322',
+      sources: ['typescript'],
+      code: 'TS2322',
+      pattern: /is not assignable to type/,
+      title: 'Type not assignable',
+      explanation: 'The value does not fit the declared type.',
+      example: { broken: 'const n: number = "1";', fixed: 'const n: number = 1;' }
+    }];
+    const message = "Type 'string' is not assignable to type 'number'.";
+
+    expect(explainError({ message, source: 'typescript', code: 'TS2322' }, rules)?.id).toBe('ts-2322');
+    expect(explainError({ message, source: 'typescript', code: 'TS2345' }, rules)).toBeUndefined();
+  });
+});
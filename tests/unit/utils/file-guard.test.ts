@@ -0,0 +1,119 @@
+/**
+ * Tests for skipping oversized, binary and non-UTF-8 files
+ */
+
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { promises as fs } from 'fs';
+import { tmpdir } from 'os';
+import { join } from 'path';
+import {
+  DEFAULT_MAX_FILE_SIZE,
+  detectUnsupportedEncoding,
+  isBinaryContent,
+  isOversized,
+  resolveMaxFileSize,
+  skippedFileDiagnostic
+} from '../../../src/utils/file-guard.js';
+import { LanguageHandlerManager } from '../../../src/languages/language-handler-manager.js';
+
+describe('file guard', () => {
+  it('should sniff binary content for NUL bytes', () => {
+    expect(isBinaryContent(Buffer.from('package main\n\nfunc main() {}\n'))).toBe(false);
+    expect(isBinaryContent(Buffer.from([0x7f, 0x45, 0x4c, 0x46, 0x02, 0x01, 0x01, 0x00]))).toBe(true);
+    // Only the start of the file is searched
+    expect(isBinaryContent(Buffer.concat([Buffer.alloc(8000, 'a'), Buffer.from([0])]))).toBe(false);
+  });
+
+  it('should recognize UTF-16 and UTF-32 text', () => {
+    const text = 'package main\n';
+    expect(detectUnsupportedEncoding(Buffer.concat([Buffer.from([0xff, 0xfe]), Buffer.from(text, 'utf16le')]))).toBe('UTF-16LE');
+    expect(detectUnsupportedEncoding(Buffer.from([0xfe, 0xff, 0x00, 0x70]))).toBe('UTF-16BE');
+    expect(detectUnsupportedEncoding(Buffer.from([0xff, 0xfe, 0x00, 0x00, 0x70, 0x00, 0x00, 0x00]))).toBe('UTF-32LE');
+    // Without a byte order mark, by the NUL in every other byte
+    expect(detectUnsupportedEncoding(Buffer.from(text, 'utf16le'))).toBe('UTF-16LE');
+    expect(detectUnsupportedEncoding(Buffer.from(text, 'utf16le').swap16())).toBe('UTF-16BE');
+  });
+
+  it('should take UTF-8, with or without a BOM, and binary files for something else', () => {
+    expect(detectUnsupportedEncoding(Buffer.from('\uFEFFpackage main // é\n'))).toBeUndefined();
+    expect(detectUnsupportedEncoding(Buffer.from([0x7f, 0x45, 0x4c, 0x46, 0x02, 0x01, 0x01, 0x00]))).toBeUndefined();
+    expect(detectUnsupportedEncoding(Buffer.alloc(0))).toBeUndefined();
+  });
+
+  it('should prefer the workspace limit and allow turning it off', () => {
+    expect(resolveMaxFileSize(undefined, undefined)).toBe(DEFAULT_MAX_FILE_SIZE);
+    expect(resolveMaxFileSize(undefined, 1000)).toBe(1000);
+    expect(resolveMaxFileSize(0, 1000)).toBe(0);
+    expect(isOversized(40 * 1024 * 1024, 0)).toBe(false);
+    expect(isOversized(1001, 1000)).toBe(true);
+  });
+
+  it('should describe why a file was skipped', () => {
+    expect(skippedFileDiagnostic('/repo/gen.go', { kind: 'too-large', size: 40 * 1024 * 1024, maxFileSize: DEFAULT_MAX_FILE_SIZE }))
+      .toMatchObject({
+        message: 'File not analyzed: 40.0 MiB is over the 2.0 MiB limit (maxFileSize)',
+        severity: 'info',
+        code: 'file-too-large',
+        source: 'file-guard'
+      });
+    expect(skippedFileDiagnostic('/repo/blob.go', { kind: 'binary' })).toMatchObject({ code: 'binary-file' });
+    expect(skippedFileDiagnostic('/repo/notes.go', { kind: 'encoding', encoding: 'UTF-16LE' })).toMatchObject({
+      message: 'File not analyzed: it is encoded as UTF-16LE; convert it to UTF-8',
+      code: 'unsupported-encoding'
+    });
+  });
+});
+
+describe('LanguageHandlerManager file guard', () => {
+  let directory: string;
+
+  beforeEach(async () => {
+    directory = await fs.realpath(await fs.mkdtemp(join(tmpdir(), 'file-guard-test-')));
+    await fs.writeFile(join(directory, '.errordebug.yaml'), [
+      'commands:',
+      '  policylint:',
+      `    command: [sh, -c, 'echo "{relativeFile}:1:1: checked"; exit 1']`,
+      '    extensions: [.rego]',
+      `    pattern: '^(?<file>[^:]+):(?<line>\\d+):(?<col>\\d+): (?<message>.+)$'`,
+      ''
+    ].join('\n'));
+    await fs.writeFile(join(directory, 'a.rego'), 'package a\n');
+    await fs.writeFile(join(directory, 'b.rego'), `package b\n${'# generated\n'.repeat(200)}`);
+    await fs.writeFile(join(directory, 'c.rego'), Buffer.from([0x70, 0x00, 0x01, 0x02]));
+    await fs.writeFile(join(directory, 'd.rego'), Buffer.from('\uFEFFpackage d\n', 'utf16le'));
+  });
+
+  afterEach(async () => {
+    await fs.rm(directory, { recursive: true, force: true });
+  });
+
+  it('should skip oversized, binary and UTF-16 files and analyze the rest', async () => {
+    const manager = new LanguageHandlerManager({ enabledLanguages: [], workspaceRoots: [directory], commands: { enabled: true }, maxFileSize: 1000 });
+
+    try {
+      const errors = await manager.analyzePath(directory);
+
+      expect(errors.map(error => [error.location.file, error.code ?? error.message])).toEqual([
+        [join(directory, 'a.rego'), 'checked'],
+        [join(directory, 'b.rego'), 'file-too-large'],
+        [join(directory, 'c.rego'), 'binary-file'],
+        [join(directory, 'd.rego'), 'unsupported-encoding']
+      ]);
+    } finally {
+      await manager.dispose();
+    }
+  });
+
+  it('should let the workspace config lift the limit', async () => {
+    await fs.appendFile(join(directory, '.errordebug.yaml'), 'maxFileSize: 0\n');
+    const manager = new LanguageHandlerManager({ enabledLanguages: [], workspaceRoots: [directory], commands: { enabled: true }, maxFileSize: 1000 });
+
+    try {
+      const errors = await manager.analyzeFile(join(directory, 'b.rego'));
+
+      expect(errors.map(error => error.message)).toEqual(['checked']);
+    } finally {
+      await manager.dispose();
+    }
+  });
+});
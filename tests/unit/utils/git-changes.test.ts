@@ -0,0 +1,159 @@
+/**
+ * Tests for changed line detection from git diff
+ */
+
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { execFileSync } from 'child_process';
+import { promises as fs } from 'fs';
+import { tmpdir } from 'os';
+import { join } from 'path';
+import { getChangedLines, isChangedLine, parseUnifiedDiff } from '../../../src/utils/git-changes.js';
+
+describe('git changes', () => {
+  describe('parseUnifiedDiff', () => {
+    it('should collect added and modified ranges from the new side', () => {
+      const diff = [
+        'diff --git a/src/main.go b/src/main.go',
+        'index 1111111..2222222 100644',
+        '--- a/src/main.go',
+        '+++ b/src/main.go',
+        '@@ -3 +3 @@ func main() {',
+        '-\tx := 1',
+        '+\tx := 2',
+        '@@ -10,0 +11,3 @@',
+        '+a',
+        '+++ looks like a header but is content',
+        '+c',
+        '@@ -20,2 +23,0 @@',
+        '-gone',
+        '-gone'
+      ].join('\n');
+
+      const files = parseUnifiedDiff(diff, '/repo');
+
+      expect(files.get('/repo/src/main.go')).toEqual({
+        path: '/repo/src/main.go',
+        status: 'modified',
+        ranges: [{ start: 3, end: 3 }, { start: 11, end: 13 }]
+      });
+    });
+
+    it('should track new, renamed and deleted files', () => {
+      const diff = [
+        'diff --git a/new.ts b/new.ts',
+        'new file mode 100644',
+        '--- /dev/null',
+        '+++ b/new.ts',
+        '@@ -0,0 +1,2 @@',
+        '+one',
+        '+two',
+        'diff --git a/old.ts b/moved.ts',
+        'similarity index 100%',
+        'rename from old.ts',
+        'rename to moved.ts',
+        'diff --git a/dead.ts b/dead.ts',
+        'deleted file mode 100644',
+        '--- a/dead.ts',
+        '+++ /dev/null',
+        '@@ -1 +0,0 @@',
+        '-bye'
+      ].join('\n');
+
+      const files = parseUnifiedDiff(diff, '/repo');
+
+      expect(files.get('/repo/new.ts')).toMatchObject({ status: 'added', ranges: [{ start: 1, end: 2 }] });
+      expect(files.get('/repo/moved.ts')).toEqual({ path: '/repo/moved.ts', status: 'renamed', previousPath: 'old.ts', ranges: [] });
+      expect(files.has('/repo/dead.ts')).toBe(false);
+    });
+
+    it('should strip only the b/ prefix and decode quoted names', () => {
+      const diff = [
+        'diff --git a/b/x.go b/b/x.go',
+        '--- a/b/x.go',
+        '+++ b/b/x.go',
+        '@@ -1 +1 @@',
+        '+x',
+        'diff --git "a/tab\\there.go" "b/tab\\there.go"',
+        '--- "a/tab\\there.go"',
+        '+++ "b/tab\\there\\n\\"q\\"\\303\\251.go"',
+        '@@ -0,0 +1 @@',
+        '+y'
+      ].join('\n');
+
+      const files = parseUnifiedDiff(diff, '/repo');
+
+      expect(Array.from(files.keys())).toEqual(['/repo/b/x.go', '/repo/tab\there\n"q"é.go']);
+    });
+  });
+
+  describe('getChangedLines', () => {
+    let root: string;
+    const git = (...args: string[]) => execFileSync('git', ['-c', 'user.email=test@example.com', '-c', 'user.name=test', ...args], { cwd: root });
+
+    beforeEach(async () => {
+      root = await fs.mkdtemp(join(tmpdir(), 'git-changes-test-'));
+      git('init', '-q');
+      await fs.writeFile(join(root, 'a.ts'), 'one\ntwo\nthree\n');
+      await fs.writeFile(join(root, 'b.ts'), 'untouched\n');
+      git('add', '.');
+      git('commit', '-qm', 'initial');
+    });
+
+    afterEach(async () => {
+      await fs.rm(root, { recursive: true, force: true });
+    });
+
+    it('should report modified lines and whole untracked files', async () => {
+      await fs.writeFile(join(root, 'a.ts'), 'one\nTWO\nthree\n');
+      await fs.writeFile(join(root, 'c.ts'), 'brand new\n');
+
+      const changes = (await getChangedLines(root))!;
+
+      expect(changes.ref).toBe('HEAD');
+      expect(isChangedLine(changes, join(changes.root, 'a.ts'), 2)).toBe(true);
+      expect(isChangedLine(changes, join(changes.root, 'a.ts'), 1)).toBe(false);
+      expect(isChangedLine(changes, join(changes.root, 'b.ts'), 1)).toBe(false);
+      expect(isChangedLine(changes, join(changes.root, 'c.ts'), 1)).toBe(true);
+    });
+
+    it('should find changes whatever prefixes the diff config asks for', async () => {
+      await fs.mkdir(join(root, 'b'));
+      await fs.writeFile(join(root, 'b', 'x.ts'), 'one\n');
+      git('add', '.');
+      git('commit', '-qm', 'b');
+      await fs.writeFile(join(root, 'b', 'x.ts'), 'ONE\n');
+      await fs.writeFile(join(root, 'a.ts'), 'one\nTWO\nthree\n');
+      await fs.writeFile(join(root, 'new\tfile.ts'), 'new\n');
+
+      for (const config of [['diff.noprefix', 'true'], ['diff.mnemonicPrefix', 'true']]) {
+        git('config', ...config);
+        const changes = (await getChangedLines(root))!;
+
+        expect(isChangedLine(changes, join(changes.root, 'b', 'x.ts'), 1)).toBe(true);
+        expect(isChangedLine(changes, join(changes.root, 'a.ts'), 2)).toBe(true);
+        expect(isChangedLine(changes, join(changes.root, 'new\tfile.ts'), 1)).toBe(true);
+        git('config', '--unset', config[0]!);
+      }
+    });
+
+    it('should reject refs that git would read as options or that name no commit', async () => {
+      const output = join(root, 'pwned');
+
+      await expect(getChangedLines(root, `--output=${output}`)).rejects.toThrow('Invalid git ref');
+      await expect(getChangedLines(root, 'no-such-branch')).rejects.toThrow('does not name a commit');
+      await expect(fs.access(output)).rejects.toThrow();
+
+      git('tag', 'v1');
+      expect((await getChangedLines(root, 'v1'))!.ref).toBe('v1');
+    });
+
+    it('should return undefined outside a git repository', async () => {
+      const outside = await fs.mkdtemp(join(tmpdir(), 'git-changes-none-'));
+      try {
+        expect(await getChangedLines(outside)).toBeUndefined();
+      } finally {
+        await fs.rm(outside, { recursive: true, force: true });
+      }
+    });
+  });
+});
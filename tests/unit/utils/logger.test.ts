@@ -1,5 +1,5 @@
 import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
-import { Logger, LogLevel, LogEntry } from '../../../src/utils/logger.js';
+import { Logger, LogLevel, LogEntry, parseLogLevel } from '../../../src/utils/logger.js';
 import { writeFile, appendFile, mkdir } from 'fs/promises';
 import { existsSync } from 'fs';
 
@@ -262,4 +262,16 @@ describe('Logger', () => {
       expect(stderrWriteSpy).toHaveBeenCalled();
     });
   });
+
+  describe('parseLogLevel', () => {
+    it('should accept level names regardless of case and whitespace', () => {
+      expect(parseLogLevel('DEBUG')).toBe('debug');
+      expect(parseLogLevel(' warn ')).toBe('warn');
+    });
+
+    it('should reject unknown or missing values', () => {
+      expect(parseLogLevel('verbose')).toBeUndefined();
+      expect(parseLogLevel(undefined)).toBeUndefined();
+    });
+  });
 });
@@ -0,0 +1,111 @@
+/**
+ * Tests for the catalog of server-written messages
+ */
+
+import { describe, it, expect, afterEach } from 'vitest';
+import { configureMessages, formatMessage, getLocale } from '../../../src/utils/messages.js';
+import { formatDiagnosticsText, formatSummaryLine } from '../../../src/utils/diagnostic-formatter.js';
+import { toDiagnosticRecord } from '../../../src/utils/diagnostics.js';
+import { suggestFix } from '../../../src/utils/quick-fixes.js';
+import { skippedFileDiagnostic } from '../../../src/utils/file-guard.js';
+import { DetectorTimeoutError } from '../../../src/utils/cancellation.js';
+import { parseGoModuleErrors } from '../../../src/languages/go-module.js';
+import { markOfflineBlocked } from '../../../src/languages/go-offline.js';
+
+describe('messages', () => {
+  afterEach(() => {
+    configureMessages();
+  });
+
+  it('should render English by default and for unknown or invalid locales', () => {
+    for (const locale of [undefined, 'ja', 'not a locale!']) {
+      configureMessages({ ...(locale && { locale }) });
+
+      expect(formatMessage('summary.errors', { count: 1 })).toBe('1 error');
+      expect(formatMessage('summary.errors', { count: 2 })).toBe('2 errors');
+    }
+    expect(getLocale()).toBe('en');
+  });
+
+  it('should fall back from the region to the language, then to English', () => {
+    configureMessages({ locale: 'de-AT' });
+
+    expect(getLocale()).toBe('de-AT');
+    expect(formatMessage('severity.hint')).toBe('Hinweis');
+    expect(formatMessage('summary.files', { count: 1 })).toBe('1 Datei');
+    expect(formatMessage('summary.files', { count: 3 })).toBe('3 Dateien');
+    // No German separator, so the English one is used
+    expect(formatMessage('summary.separator')).toBe(', ');
+  });
+
+  it('should take configured messages over the built-in ones', () => {
+    configureMessages({
+      locale: 'pt-BR',
+      messages: {
+        pt: { 'severity.error': 'erro', 'summary.errors': { one: '{count} erro', other: '{count} erros' } },
+        EN: { 'summary.line': '{counts} in {files}' }
+      }
+    });
+
+    expect(formatMessage('severity.error')).toBe('erro');
+    expect(formatSummaryLine({ errors: 2, warnings: 1, info: 0, hints: 0 }, 3)).toBe('2 erros, 1 warning in 3 files');
+  });
+
+  it('should leave unknown placeholders and keys visible', () => {
+    expect(formatMessage('page.partial', { shown: 1 })).toBe('(showing 1 of {total})');
+    expect(formatMessage('quickFix.unknown-rule')).toBe('quickFix.unknown-rule');
+  });
+
+  it('should localize the text report but not the tools\' messages', () => {
+    configureMessages({ locale: 'es' });
+    const text = formatDiagnosticsText([
+      toDiagnosticRecord({ message: 'undefined: x', severity: 'error', location: { file: '/repo/a.go', line: 3, column: 1 }, source: 'go' }),
+      toDiagnosticRecord({ message: 'unused\nsecond', severity: 'warning', location: { file: '/repo/a.go', line: 9, column: 2 }, source: 'go' })
+    ], { baseDir: '/repo', total: 4 });
+
+    expect(text).toBe([
+      'a.go',
+      '  3:1  error        undefined: x',
+      '  9:2  advertencia  unused',
+      '                    second',
+      '',
+      '1 error, 1 advertencia en 1 archivo (mostrando 2 de 4)'
+    ].join('\n'));
+  });
+
+  it('should localize quick fixes and skipped-file diagnostics', () => {
+    configureMessages({ locale: 'de' });
+
+    expect(suggestFix({ message: 'undefined: strings', source: 'go' })).toBe('Den fehlenden Import "strings" hinzufügen');
+    expect(skippedFileDiagnostic('/repo/a.bin', { kind: 'binary' }).message).toBe('Datei nicht analysiert: sie scheint binär zu sein');
+  });
+
+  it('should render the diagnostics and fixes handlers compose from the catalog', () => {
+    configureMessages({
+      locale: 'fr',
+      messages: {
+        fr: {
+          'goModule.tidy': 'go.mod ne correspond plus au code qui l\'utilise',
+          'goModule.runCommands': 'Lancer {commands}',
+          'goOffline.import': 'L\'import "{import}" demande un module hors ligne : {message}',
+          'error.detectorTimeout': 'l\'analyse {detector} a dépassé {timeoutMs} ms'
+        }
+      }
+    });
+
+    const [tidy] = parseGoModuleErrors('go: updates to go.mod needed; to update it:\n\tgo mod tidy\n', '/repo/go.mod', 'module example.com/shop\n');
+    expect(tidy).toMatchObject({ message: 'go.mod ne correspond plus au code qui l\'utilise', suggestedFix: 'Lancer `go mod tidy`' });
+
+    const offline = markOfflineBlocked({
+      message: 'module lookup disabled by GOPROXY=off',
+      severity: 'error',
+      location: { file: '/repo/main.go', line: 3, column: 8 },
+      source: 'go'
+    }, 'example.com/lib');
+    expect(offline.message).toBe('L\'import "example.com/lib" demande un module hors ligne : module lookup disabled by GOPROXY=off');
+    // Keys the catalog lacks fall back to English
+    expect(offline.suggestedFix).toBe('Run `go mod download` with network access, or analyze without offline mode');
+
+    expect(new DetectorTimeoutError('go', 500).message).toBe('l\'analyse go a dépassé 500 ms');
+  });
+});
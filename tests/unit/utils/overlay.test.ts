@@ -0,0 +1,126 @@
+/**
+ * Tests for analyzing unsaved buffers through an overlay mirror
+ */
+
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { EventEmitter } from 'events';
+import { promises as fs } from 'fs';
+import { tmpdir } from 'os';
+import { join } from 'path';
+import { OverlayMirror } from '../../../src/utils/overlay.js';
+import { LanguageHandlerManager } from '../../../src/languages/language-handler-manager.js';
+import type { DetectionOptions, LanguageError, LanguageHandler } from '../../../src/types/languages.js';
+
+describe('OverlayMirror', () => {
+  let directory: string;
+
+  beforeEach(async () => {
+    directory = await fs.realpath(await fs.mkdtemp(join(tmpdir(), 'overlay-')));
+    await fs.mkdir(join(directory, 'cmd', 'api'), { recursive: true });
+    await fs.mkdir(join(directory, 'internal'));
+    await fs.writeFile(join(directory, 'go.mod'), 'module example.com/shop\n');
+    await fs.writeFile(join(directory, 'cmd', 'api', 'main.go'), 'package main\n');
+    await fs.writeFile(join(directory, 'internal', 'store.go'), 'package internal\n');
+  });
+
+  afterEach(async () => {
+    vi.restoreAllMocks();
+    await fs.rm(directory, { recursive: true, force: true });
+  });
+
+  it('should write the overlay into the mirror only and link everything else', async () => {
+    const main = join(directory, 'cmd', 'api', 'main.go');
+    const mirror = await OverlayMirror.create(directory, new Map([
+      [main, 'package main\n\nfunc main() { broken }\n'],
+      [join(directory, 'cmd', 'api', 'new.go'), 'package main\n']
+    ]));
+
+    try {
+      expect(await fs.readFile(mirror.toMirror(main), 'utf-8')).toContain('broken');
+      expect(await fs.readFile(main, 'utf-8')).toBe('package main\n');
+      await expect(fs.access(join(directory, 'cmd', 'api', 'new.go'))).rejects.toThrow();
+
+      // Files next to an overlay are copies, so tools writing to them leave the originals alone
+      const goMod = mirror.toMirror(join(directory, 'go.mod'));
+      expect((await fs.lstat(goMod)).isSymbolicLink()).toBe(false);
+      await fs.writeFile(goMod, 'module changed\n');
+      expect(await fs.readFile(join(directory, 'go.mod'), 'utf-8')).toBe('module example.com/shop\n');
+
+      expect((await fs.lstat(mirror.toMirror(join(directory, 'internal')))).isSymbolicLink()).toBe(true);
+    } finally {
+      await mirror.dispose();
+    }
+
+    await expect(fs.access(mirror.path)).rejects.toThrow();
+    expect(await fs.readFile(join(directory, 'internal', 'store.go'), 'utf-8')).toBe('package internal\n');
+  });
+
+  it('should map diagnostics and quoted paths back to the original files', async () => {
+    const main = join(directory, 'cmd', 'api', 'main.go');
+    const mirror = await OverlayMirror.create(directory, new Map([[main, 'package main\n']]));
+    const inMirror = mirror.toMirror(main);
+
+    try {
+      const [error] = mirror.remapErrors([{
+        message: `${inMirror}:3:15: undefined: broken`,
+        severity: 'error',
+        location: { file: inMirror, line: 3, column: 15 },
+        relatedInformation: [{ message: 'declared here', location: { file: mirror.toMirror(join(directory, 'go.mod')), line: 1, column: 1 } }]
+      }]);
+
+      expect(error!.location.file).toBe(main);
+      expect(error!.message).toBe(`${main}:3:15: undefined: broken`);
+      expect(error!.relatedInformation![0]!.location.file).toBe(join(directory, 'go.mod'));
+    } finally {
+      await mirror.dispose();
+    }
+  });
+
+  it('should reject overlay files outside the mirrored root', async () => {
+    await expect(OverlayMirror.create(join(directory, 'cmd'), new Map([[join(directory, 'go.mod'), '']])))
+      .rejects.toThrow('is outside');
+  });
+
+  it('should analyze overlays through the manager without touching the files on disk', async () => {
+    const manager = new LanguageHandlerManager({ enabledLanguages: [], workspaceRoots: [directory] });
+    const seen: string[] = [];
+    // Reads the file from disk like a compiler would, instead of using the source it is given
+    const detectErrors = vi.fn(async (_source: string, options?: DetectionOptions): Promise<LanguageError[]> => {
+      const file = options!.filePath!;
+      seen.push(file);
+      const content = await fs.readFile(file, 'utf-8');
+      return content.includes('broken')
+        ? [{ message: `${file}:3:15: undefined: broken`, severity: 'error', location: { file, line: 3, column: 15 } }]
+        : [];
+    });
+    await manager.registerHandler(Object.assign(new EventEmitter() as unknown as LanguageHandler, {
+      language: 'go',
+      initialize: vi.fn(async () => {}),
+      dispose: vi.fn(async () => {}),
+      isAvailable: vi.fn(async () => true),
+      isFileSupported: (filePath: string) => filePath.endsWith('.go'),
+      getFileExtensions: () => ['.go'],
+      getConfigFiles: () => [],
+      detectErrors
+    }));
+
+    const main = join(directory, 'cmd', 'api', 'main.go');
+    const errors = await manager.analyzePath(directory, {}, {
+      'cmd/api/main.go': 'package main\n\nfunc main() { broken }\n'
+    });
+
+    expect(errors).toEqual([expect.objectContaining({
+      message: `${main}:3:15: undefined: broken`,
+      location: { file: main, line: 3, column: 15 }
+    })]);
+    expect(seen).toHaveLength(2);
+    expect(await fs.readFile(main, 'utf-8')).toBe('package main\n');
+    for (const file of seen) {
+      expect(file.startsWith(directory)).toBe(false);
+    }
+    await expect(fs.access(seen[0]!)).rejects.toThrow();
+
+    // The same files without the overlay are clean, so nothing was cached from the mirror
+    expect(await manager.analyzePath(directory)).toEqual([]);
+  });
+});
@@ -0,0 +1,56 @@
+/**
+ * Tests for include/exclude path filtering
+ */
+
+import { describe, it, expect } from 'vitest';
+import { compilePathFilter, globToRegExp } from '../../../src/utils/path-filter.js';
+
+describe('path filter', () => {
+  const files = [
+    'main.go',
+    'main_test.go',
+    'internal/store/store.go',
+    'internal/store/store_test.go',
+    'internal/store/mock/mock.go',
+    'cmd/api/main.go'
+  ];
+
+  it('should exclude test files at any depth with **/*_test.go', () => {
+    const matches = compilePathFilter({ exclude: ['**/*_test.go'] });
+
+    expect(files.filter(matches)).toEqual([
+      'main.go',
+      'internal/store/store.go',
+      'internal/store/mock/mock.go',
+      'cmd/api/main.go'
+    ]);
+  });
+
+  it('should let excludes win over includes', () => {
+    const matches = compilePathFilter({ include: ['internal/**'], exclude: ['**/*_test.go', 'internal/store/mock/'] });
+
+    expect(files.filter(matches)).toEqual(['internal/store/store.go']);
+  });
+
+  it('should include everything when no include pattern is given', () => {
+    expect(files.filter(compilePathFilter())).toEqual(files);
+  });
+
+  it('should keep single stars within one segment', () => {
+    const matches = compilePathFilter({ include: ['*.go'] });
+
+    expect(files.filter(matches)).toEqual(['main.go', 'main_test.go']);
+  });
+
+  it('should support alternatives, character classes and ./ prefixes', () => {
+    expect(globToRegExp('**/*.{go,mod}').test('go.mod')).toBe(true);
+    expect(globToRegExp('cmd/[!x]*/main.go').test('cmd/api/main.go')).toBe(true);
+    expect(globToRegExp('./internal/**').test('internal/store/store.go')).toBe(true);
+    expect(globToRegExp('internal/**/store.go').test('internal/store.go')).toBe(true);
+    expect(globToRegExp('a.go').test('aXgo')).toBe(false);
+  });
+
+  it('should match Windows separators in the path', () => {
+    expect(compilePathFilter({ exclude: ['**/*_test.go'] })('internal\\store\\store_test.go')).toBe(false);
+  });
+});
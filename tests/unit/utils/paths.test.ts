@@ -0,0 +1,110 @@
+/**
+ * Tests for canonical diagnostic paths
+ */
+
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { EventEmitter } from 'events';
+import { promises as fs } from 'fs';
+import { tmpdir } from 'os';
+import { join } from 'path';
+import { PathNormalizer, canonicalPath, normalizeDriveLetter, normalizeErrorPaths } from '../../../src/utils/paths.js';
+import { WorkspaceRoots } from '../../../src/utils/workspace-roots.js';
+import { LanguageHandlerManager } from '../../../src/languages/language-handler-manager.js';
+import type { LanguageError, LanguageHandler } from '../../../src/types/languages.js';
+
+describe('paths', () => {
+  let directory: string;
+  let real: string;
+
+  beforeEach(async () => {
+    directory = await fs.mkdtemp(join(tmpdir(), 'paths-test-'));
+    // tmpdir itself may be a symlink (macOS /var -> /private/var)
+    real = await fs.realpath(directory);
+    await fs.mkdir(join(real, 'module', 'pkg'), { recursive: true });
+    await fs.writeFile(join(real, 'module', 'pkg', 'a.go'), 'package pkg\n');
+    await fs.symlink(join(real, 'module'), join(real, 'link'));
+  });
+
+  afterEach(async () => {
+    await fs.rm(directory, { recursive: true, force: true });
+  });
+
+  it('should resolve symlinks in the parents of paths that do not exist', async () => {
+    expect(await canonicalPath(join(directory, 'link', 'pkg', 'a.go'))).toBe(join(real, 'module', 'pkg', 'a.go'));
+    expect(await canonicalPath(join(directory, 'link', 'pkg', 'deleted.go'))).toBe(join(real, 'module', 'pkg', 'deleted.go'));
+  });
+
+  describe('PathNormalizer', () => {
+    it('should resolve relative tool output against the first base directory containing it', async () => {
+      const normalizer = new PathNormalizer([join(real, 'module'), join(real, 'module', 'pkg')]);
+
+      expect(await normalizer.normalize('./pkg/a.go')).toBe(join(real, 'module', 'pkg', 'a.go'));
+      expect(await normalizer.normalize('a.go')).toBe(join(real, 'module', 'pkg', 'a.go'));
+      expect(await normalizer.normalize('missing.go')).toBe(join(real, 'module', 'missing.go'));
+    });
+
+    it('should resolve symlinks', async () => {
+      const normalizer = new PathNormalizer();
+
+      expect(await normalizer.normalize(join(directory, 'link', 'pkg', 'a.go'))).toBe(join(real, 'module', 'pkg', 'a.go'));
+    });
+
+    it('should upper-case Windows drive letters', () => {
+      expect(normalizeDriveLetter('c:\\src\\main.go')).toBe('C:\\src\\main.go');
+      expect(normalizeDriveLetter('d:/src/main.go')).toBe('D:/src/main.go');
+      expect(normalizeDriveLetter('/src/main.go')).toBe('/src/main.go');
+    });
+  });
+
+  describe('normalizeErrorPaths', () => {
+    it('should rewrite diagnostic and related locations', async () => {
+      const errors: LanguageError[] = [{
+        message: 'x redeclared in this block',
+        severity: 'error',
+        location: { file: 'pkg/a.go', line: 3, column: 2 },
+        source: 'go',
+        relatedInformation: [{ location: { file: join(directory, 'link', 'pkg', 'a.go'), line: 1, column: 1 }, message: 'other declaration of x' }]
+      }];
+
+      const [error] = await normalizeErrorPaths(errors, new PathNormalizer([join(real, 'module')]));
+
+      expect(error!.location.file).toBe(join(real, 'module', 'pkg', 'a.go'));
+      expect(error!.relatedInformation![0]!.location.file).toBe(join(real, 'module', 'pkg', 'a.go'));
+      expect(errors[0]!.location.file).toBe('pkg/a.go');
+    });
+  });
+
+  it('should locate real paths under a symlinked workspace root', () => {
+    const roots = new WorkspaceRoots([join(real, 'link')]);
+
+    expect(roots.locate(join(real, 'module', 'pkg', 'a.go'))).toEqual({ root: join(real, 'link'), relativePath: join('pkg', 'a.go') });
+  });
+
+  it('should return absolute paths from the manager for relative handler output', async () => {
+    const manager = new LanguageHandlerManager({ enabledLanguages: [], workspaceRoots: [join(real, 'module')] });
+    const handler = Object.assign(new EventEmitter() as unknown as LanguageHandler, {
+      language: 'go',
+      initialize: vi.fn(async () => {}),
+      dispose: vi.fn(async () => {}),
+      isAvailable: vi.fn(async () => true),
+      isFileSupported: (filePath: string) => filePath.endsWith('.go'),
+      getFileExtensions: () => ['.go'],
+      getConfigFiles: () => [],
+      detectErrors: vi.fn(async (): Promise<LanguageError[]> => [{
+        message: 'undefined: y',
+        severity: 'error',
+        location: { file: './pkg/a.go', line: 1, column: 1 },
+        source: 'go'
+      }])
+    });
+
+    try {
+      await manager.registerHandler(handler);
+      const errors = await manager.analyzeFile(join(real, 'module', 'pkg', 'a.go'));
+
+      expect(errors[0]!.location.file).toBe(join(real, 'module', 'pkg', 'a.go'));
+    } finally {
+      await manager.dispose();
+    }
+  });
+});
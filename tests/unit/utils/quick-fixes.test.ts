@@ -0,0 +1,58 @@
+/**
+ * Tests for rule-based quick-fix hints
+ */
+
+import { describe, it, expect } from 'vitest';
+import { readFileSync } from 'fs';
+import { join } from 'path';
+import { suggestFix, type QuickFixRule } from '../../../src/utils/quick-fixes.js';
+import { toDiagnosticRecord } from '../../../src/utils/diagnostics.js';
+import { GoHandler } from '../../../src/languages/go-handler.js';
+import type { LanguageError } from '../../../src/types/languages.js';
+
+const readFixture = (name: string) => readFileSync(join(__dirname, '../../fixtures/go', name), 'utf-8');
+
+describe('quick fixes', () => {
+  it('should suggest fixes for go build errors from the fixture', () => {
+    const errors: LanguageError[] = (new GoHandler() as any).parseGoErrors(readFixture('quick_fixes.stderr'), '/repo/main.go');
+
+    expect(errors.map(error => toDiagnosticRecord(error).suggestedFix)).toEqual([
+      'Remove the unused import "os"',
+      'Remove the unused import "encoding/json"',
+      'Remove count or use it; assign it to _ if only its side effects are needed',
+      'Add the missing import "strings"',
+      'Use = instead of :=, since every variable on the left is already declared',
+      undefined,
+      'Add a return statement at the end of the function'
+    ]);
+  });
+
+  it('should leave the field out when no rule matches', () => {
+    const record = toDiagnosticRecord({
+      message: 'undefined: helper',
+      severity: 'error',
+      location: { file: '/repo/main.go', line: 1, column: 1 },
+      source: 'go'
+    });
+
+    expect('suggestedFix' in record).toBe(false);
+  });
+
+  it('should only apply rules to their own languages', () => {
+    expect(suggestFix({ message: '"os" imported and not used', source: 'typescript' })).toBeUndefined();
+    expect(suggestFix({ message: 'undefined: constructor', source: 'go' })).toBeUndefined();
+  });
+
+  it('should accept custom rule tables', () => {
+    const rules: QuickFixRule[] = [{
+      id: 'ts-missing-name',
+      sources: ['typescript'],
+      code: 'TS2304',
+      pattern: /^Cannot find name '(\w+)'/,
+      fix: 'Declare or import $1'
+    }];
+
+    expect(suggestFix({ message: "Cannot find name 'foo'.", source: 'typescript', code: 'TS2304' }, rules)).toBe('Declare or import foo');
+    expect(suggestFix({ message: "Cannot find name 'foo'.", source: 'typescript', code: 'TS2552' }, rules)).toBeUndefined();
+  });
+});
@@ -0,0 +1,160 @@
+/**
+ * Tests for recording the raw output behind diagnostics
+ */
+
+import { describe, it, expect, afterEach } from 'vitest';
+import { promises as fs } from 'fs';
+import { tmpdir } from 'os';
+import { join } from 'path';
+import {
+  RawOutputRecorder,
+  findRawLines,
+  recordRawOutput,
+  redactTempPaths,
+  type ToolInvocation
+} from '../../../src/utils/raw-output.js';
+import { LanguageHandlerManager } from '../../../src/languages/language-handler-manager.js';
+
+function invocation(output: string, tool = 'go'): ToolInvocation {
+  return { tool, commandLine: `${tool} vet ./...`, exitCode: 1, output };
+}
+
+describe('findRawLines', () => {
+  it('should return the line holding the message and position with its continuation lines', () => {
+    const output = [
+      '# example.com/shop',
+      './main.go:3:2: "fmt" imported and not used',
+      './main.go:7:9: cannot use x (variable of type int) as string value in return statement',
+      '\thave (int)',
+      '\twant (string)',
+      './store.go:1:1: expected package'
+    ].join('\n');
+
+    expect(findRawLines(
+      { file: '/work/main.go', line: 7, message: 'cannot use x (variable of type int) as string value in return statement' },
+      [invocation(output)]
+    )).toEqual([
+      './main.go:7:9: cannot use x (variable of type int) as string value in return statement',
+      '\thave (int)',
+      '\twant (string)'
+    ]);
+  });
+
+  it('should prefer the line with the right position when a message repeats', () => {
+    const output = 'main.go:3:2: declared and not used: x\nmain.go:9:2: declared and not used: x';
+
+    expect(findRawLines({ file: 'main.go', line: 9, message: 'declared and not used: x' }, [invocation(output)]))
+      .toEqual(['main.go:9:2: declared and not used: x']);
+  });
+
+  it('should fall back to the position when the message was rewritten', () => {
+    const output = 'src/app.ts(12,5): error TS2322: Type \'number\' is not assignable to type \'string\'.';
+
+    expect(findRawLines({ file: '/work/src/app.ts', line: 12, message: 'Assignment of a number to a string' }, [invocation(output, 'tsc')]))
+      .toEqual([output]);
+  });
+
+  it('should return nothing for diagnostics no tool printed', () => {
+    expect(findRawLines({ file: 'main.go', line: 4, message: 'unreachable code' }, [invocation('ok\n')])).toEqual([]);
+  });
+});
+
+describe('redactTempPaths', () => {
+  it('should replace temporary directories but keep the path inside them', () => {
+    const scratch = join(tmpdir(), 'go-syntax-check-x1');
+
+    expect(redactTempPaths(`${join(scratch, 'main.go')}:3:1: expected 'package'`))
+      .toBe(`${join('<tmp>', 'main.go')}:3:1: expected 'package'`);
+  });
+
+  it('should leave kept directories alone', () => {
+    const workspace = join(tmpdir(), 'shop');
+    const text = `${join(workspace, 'main.go')}:1:1: error\n${join(tmpdir(), 'mirror-1', 'main.go')}:1:1: error`;
+
+    expect(redactTempPaths(text, [workspace]))
+      .toBe(`${join(workspace, 'main.go')}:1:1: error\n${join('<tmp>', 'main.go')}:1:1: error`);
+  });
+});
+
+describe('RawOutputRecorder', () => {
+  it('should group runs by tool and join stdout and stderr', () => {
+    const recorder = new RawOutputRecorder();
+    recorder.record('/usr/local/go/bin/go', ['vet', './...'], { stdout: 'one', stderr: 'two\n', exitCode: 1 });
+    recorder.record('staticcheck', ['./...'], { stdout: '', stderr: '', exitCode: 0 });
+
+    expect(recorder.byTool()).toEqual({
+      go: [{ commandLine: '/usr/local/go/bin/go vet ./...', exitCode: 1, output: 'one\ntwo\n' }],
+      staticcheck: [{ commandLine: 'staticcheck ./...', exitCode: 0, output: '' }]
+    });
+  });
+
+  it('should only rewrite the runs recorded from the given index on', () => {
+    const recorder = new RawOutputRecorder();
+    recorder.record('go', ['build'], { stdout: '/mirror/main.go', stderr: '', exitCode: 1 });
+    recorder.record('go', ['vet'], { stdout: '/mirror/main.go', stderr: '', exitCode: 1 });
+    recorder.rewrite(1, text => text.replace('/mirror', '/work'));
+
+    expect(recorder.getInvocations().map(run => run.output)).toEqual(['/mirror/main.go', '/work/main.go']);
+  });
+});
+
+describe('includeRaw', () => {
+  let directory: string;
+
+  afterEach(async () => {
+    await fs.rm(directory, { recursive: true, force: true });
+  });
+
+  async function lintWorkspace(): Promise<string> {
+    directory = await fs.realpath(await fs.mkdtemp(join(tmpdir(), 'raw-output-')));
+    await fs.writeFile(join(directory, '.errordebug.yaml'), [
+      'commands:',
+      '  lint:',
+      `    command: [sh, -c, 'echo "{file}:1:1: error $(cat {file})"']`,
+      '    extensions: [.rego]',
+      `    pattern: '^(?<file>[^:]+):(?<line>\\d+):(?<col>\\d+): (?<severity>\\w+) (?<message>.+)$'`,
+      ''
+    ].join('\n'));
+    const file = join(directory, 'main.rego');
+    await fs.writeFile(file, 'saved\n');
+    return file;
+  }
+
+  it('should run the tools again instead of answering from the cache', async () => {
+    const file = await lintWorkspace();
+    const manager = new LanguageHandlerManager({ enabledLanguages: [], workspaceRoots: [directory], commands: { enabled: true } });
+
+    try {
+      await manager.analyzeFile(file);
+      const recorder = new RawOutputRecorder();
+      await recordRawOutput(recorder, async () => {
+        await manager.analyzePath(file, { includeRaw: true });
+        await manager.analyzePath(file, { includeRaw: true });
+      });
+
+      expect(recorder.getInvocations()).toHaveLength(2);
+      expect(recorder.getInvocations()[0]).toMatchObject({ tool: 'sh', exitCode: 0, output: `${file}:1:1: error saved\n` });
+    } finally {
+      await manager.dispose();
+    }
+  });
+
+  it('should name the workspace instead of the overlay mirror', async () => {
+    const file = await lintWorkspace();
+    const manager = new LanguageHandlerManager({ enabledLanguages: [], workspaceRoots: [directory], commands: { enabled: true } });
+
+    try {
+      const recorder = new RawOutputRecorder();
+      const errors = await recordRawOutput(recorder, () =>
+        manager.analyzePath(file, { includeRaw: true }, { 'main.rego': 'unsaved' })
+      );
+
+      expect(errors.map(error => error.message)).toEqual(['unsaved']);
+      const [run] = recorder.getInvocations();
+      expect(run!.output).toBe(`${file}:1:1: error unsaved\n`);
+      expect(run!.commandLine).not.toContain('<tmp>');
+    } finally {
+      await manager.dispose();
+    }
+  });
+});
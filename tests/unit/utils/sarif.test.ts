@@ -0,0 +1,194 @@
+/**
+ * Tests for SARIF output
+ */
+
+import { describe, it, expect } from 'vitest';
+import { z } from 'zod';
+import { formatDiagnosticsSarif } from '../../../src/utils/sarif.js';
+import { toDiagnosticRecord, type DiagnosticRecord } from '../../../src/utils/diagnostics.js';
+import type { LanguageError } from '../../../src/types/languages.js';
+
+/**
+ * The parts of the SARIF 2.1.0 schema the formatter produces. Objects are strict,
+ * as the schema disallows additional properties on them.
+ */
+const uriReference = z.string().refine(uri => !uri.includes('\\') && !/\s/.test(uri), 'invalid URI reference');
+const propertyBag = z.record(z.unknown());
+const region = z.object({
+  startLine: z.number().int().min(1),
+  startColumn: z.number().int().min(1),
+  endLine: z.number().int().min(1).optional(),
+  endColumn: z.number().int().min(1).optional()
+}).strict();
+const result = z.object({
+  ruleId: z.string().optional(),
+  ruleIndex: z.number().int().min(-1).optional(),
+  level: z.enum(['none', 'note', 'warning', 'error']),
+  message: z.object({ text: z.string() }).strict(),
+  locations: z.array(z.object({
+    physicalLocation: z.object({
+      artifactLocation: z.object({ uri: uriReference, uriBaseId: z.string().optional() }).strict(),
+      region
+    }).strict()
+  }).strict()),
+  relatedLocations: z.array(z.object({
+    id: z.number().int().min(-1),
+    physicalLocation: z.object({
+      artifactLocation: z.object({ uri: uriReference, uriBaseId: z.string().optional() }).strict(),
+      region
+    }).strict(),
+    message: z.object({ text: z.string() }).strict()
+  }).strict()).optional(),
+  properties: propertyBag.optional()
+}).strict();
+const sarifLog = z.object({
+  $schema: z.string().url(),
+  version: z.literal('2.1.0'),
+  runs: z.array(z.object({
+    tool: z.object({
+      driver: z.object({
+        name: z.string().min(1),
+        rules: z.array(z.object({ id: z.string() }).strict())
+      }).strict()
+    }).strict(),
+    // Base URIs must be absolute and end with a slash
+    originalUriBaseIds: z.record(z.object({ uri: z.string().regex(/^file:\/\/.*\/$/) }).strict()).optional(),
+    results: z.array(result),
+    invocations: z.array(z.object({
+      executionSuccessful: z.boolean(),
+      toolExecutionNotifications: z.array(z.object({
+        level: z.enum(['none', 'note', 'warning', 'error']),
+        message: z.object({ text: z.string() }).strict()
+      }).strict())
+    }).strict()).optional(),
+    properties: propertyBag.optional()
+  }).strict())
+}).strict();
+
+function record(
+  file: string,
+  source: string,
+  severity: LanguageError['severity'],
+  code?: string,
+  location: Partial<LanguageError['location']> = {}
+): DiagnosticRecord {
+  return toDiagnosticRecord({
+    message: `${source} finding`,
+    severity,
+    location: { file, line: 3, column: 5, ...location },
+    source,
+    ...(code && { code })
+  });
+}
+
+describe('SARIF formatter', () => {
+  it('should produce a schema-valid log with one run per tool and root-relative URIs', () => {
+    const diagnostics = [
+      { ...record('/work/api/cmd/main.go', 'go', 'error', 'UndeclaredName', { endLine: 3, endColumn: 9 }), root: '/work/api', relativePath: 'cmd/main.go' },
+      { ...record('/work/api/cmd/main.go', 'go', 'warning', 'UndeclaredName'), root: '/work/api', relativePath: 'cmd/main.go' },
+      { ...record('/work/web/src/my app.ts', 'typescript', 'info', 'TS6133'), root: '/work/web', relativePath: 'src/my app.ts' },
+      record('/elsewhere/tool.go', 'go', 'hint')
+    ];
+
+    const log = formatDiagnosticsSarif(diagnostics);
+
+    expect(sarifLog.safeParse(log).success).toBe(true);
+    expect(log.runs.map(run => run.tool.driver.name)).toEqual(['go', 'typescript']);
+    expect(log.runs[0]!.tool.driver.rules).toEqual([{ id: 'UndeclaredName' }]);
+    expect(log.runs[0]!.originalUriBaseIds).toEqual({
+      SRCROOT: { uri: 'file:///work/api/' },
+      SRCROOT2: { uri: 'file:///work/web/' }
+    });
+
+    const [first, second, outside] = log.runs[0]!.results;
+    expect(first).toMatchObject({
+      ruleId: 'UndeclaredName',
+      ruleIndex: 0,
+      level: 'error',
+      locations: [{
+        physicalLocation: {
+          artifactLocation: { uri: 'cmd/main.go', uriBaseId: 'SRCROOT' },
+          region: { startLine: 3, startColumn: 5, endLine: 3, endColumn: 9 }
+        }
+      }]
+    });
+    expect(second!.locations[0]!.physicalLocation.region).toEqual({ startLine: 3, startColumn: 5 });
+    expect(outside).toMatchObject({ level: 'note', locations: [{ physicalLocation: { artifactLocation: { uri: 'file:///elsewhere/tool.go' } } }] });
+    expect(outside!.ruleId).toBeUndefined();
+    expect(log.runs[1]!.results[0]!.locations[0]!.physicalLocation.artifactLocation).toEqual({
+      uri: 'src/my%20app.ts',
+      uriBaseId: 'SRCROOT2'
+    });
+  });
+
+  it('should make paths relative to the base directory without roots and report an empty run for no findings', () => {
+    const log = formatDiagnosticsSarif([record('/repo/pkg/a.go', 'go', 'error')], { baseDir: '/repo' });
+
+    expect(log.runs[0]!.results[0]!.locations[0]!.physicalLocation.artifactLocation).toEqual({ uri: 'pkg/a.go', uriBaseId: 'SRCROOT' });
+    expect(log.runs[0]!.originalUriBaseIds).toEqual({ SRCROOT: { uri: 'file:///repo/' } });
+
+    const empty = formatDiagnosticsSarif([]);
+    expect(sarifLog.safeParse(empty).success).toBe(true);
+    expect(empty.runs).toEqual([
+      { tool: { driver: { name: 'error-debugging-mcp-server', rules: [] } }, columnKind: 'unicodeCodePoints', results: [] }
+    ]);
+  });
+
+  it('should mark every run of a truncated page and carry notes as notifications', () => {
+    const diagnostics = [record('/repo/a.go', 'go', 'error'), record('/repo/b.ts', 'typescript', 'warning')];
+
+    const log = formatDiagnosticsSarif(diagnostics, { total: 5, notes: ['/repo is not inside a git repository; showing all diagnostics'] });
+
+    expect(sarifLog.safeParse(log).success).toBe(true);
+    for (const run of log.runs) {
+      expect(run.properties).toEqual({ truncated: true, total: 5, omittedCount: 3 });
+      expect(run.invocations).toEqual([{
+        executionSuccessful: true,
+        toolExecutionNotifications: [
+          { level: 'warning', message: { text: '3 of 5 diagnostics were left out; raise maxResults or page with offset and limit' } },
+          { level: 'note', message: { text: '/repo is not inside a git repository; showing all diagnostics' } }
+        ]
+      }]);
+    }
+
+    // A page cut from nothing still tells CI there was more
+    const empty = formatDiagnosticsSarif([], { total: 1 });
+    expect(empty.runs[0]!.properties).toEqual({ truncated: true, total: 1, omittedCount: 1 });
+
+    const complete = formatDiagnosticsSarif(diagnostics, { total: 2 });
+    expect(complete.runs.every(run => !run.properties && !run.invocations)).toBe(true);
+  });
+
+  it('should emit related information as related locations', () => {
+    const diagnostic = {
+      ...toDiagnosticRecord({
+        message: 'x redeclared in this block',
+        severity: 'error',
+        location: { file: '/work/api/a.go', line: 7, column: 5 },
+        source: 'go',
+        relatedInformation: [
+          { location: { file: '/work/api/b.go', line: 5, column: 5 }, message: 'other declaration of x' },
+          { location: { file: '/elsewhere/c.go', line: 2, column: 1 }, message: 'other declaration of x' }
+        ]
+      }),
+      root: '/work/api',
+      relativePath: 'a.go'
+    };
+
+    const log = formatDiagnosticsSarif([diagnostic]);
+
+    expect(sarifLog.safeParse(log).success).toBe(true);
+    expect(log.runs[0]!.results[0]!.relatedLocations).toEqual([
+      {
+        id: 0,
+        physicalLocation: { artifactLocation: { uri: 'b.go', uriBaseId: 'SRCROOT' }, region: { startLine: 5, startColumn: 5 } },
+        message: { text: 'other declaration of x' }
+      },
+      {
+        id: 1,
+        physicalLocation: { artifactLocation: { uri: 'file:///elsewhere/c.go' }, region: { startLine: 2, startColumn: 1 } },
+        message: { text: 'other declaration of x' }
+      }
+    ]);
+  });
+});
@@ -0,0 +1,70 @@
+/**
+ * Tests for severity remapping
+ */
+
+import { describe, it, expect } from 'vitest';
+import { applySeverityRules, compileSeverityRules } from '../../../src/utils/severity-rules.js';
+import { applyWorkspaceConfig } from '../../../src/utils/workspace-config.js';
+import { dedupeDiagnostics, summarizeDiagnostics, toDiagnosticRecord } from '../../../src/utils/diagnostics.js';
+import type { LanguageError } from '../../../src/types/languages.js';
+
+function vetError(analyzer: string, message: string, line = 1): LanguageError {
+  return {
+    message,
+    severity: 'warning',
+    location: { file: '/repo/main.go', line, column: 2 },
+    source: 'go-vet',
+    analyzer
+  };
+}
+
+describe('severity rules', () => {
+  const rules = compileSeverityRules({
+    printf: 'error',
+    SHADOW: 'hint',
+    '/^should have comment/i': 'off'
+  });
+
+  it('should promote matching codes', () => {
+    const [error] = applySeverityRules([vetError('printf', 'fmt.Sprintf format %d has arg s of wrong type string')], rules);
+
+    expect(error!.severity).toBe('error');
+  });
+
+  it('should demote matching codes case-insensitively', () => {
+    const [error] = applySeverityRules([vetError('shadow', 'declaration of "err" shadows declaration at line 10')], rules);
+
+    expect(error!.severity).toBe('hint');
+  });
+
+  it('should drop diagnostics mapped to off', () => {
+    const errors = applySeverityRules([
+      vetError('stylecheck', 'Should have comment or be unexported'),
+      vetError('unusedresult', 'result of fmt.Sprintf call not used')
+    ], rules);
+
+    expect(errors.map(error => error.analyzer)).toEqual(['unusedresult']);
+    expect(errors[0]!.severity).toBe('warning');
+  });
+
+  it('should reject invalid patterns', () => {
+    expect(() => compileSeverityRules({ '/(unclosed/': 'off' })).toThrow(/invalid severity pattern/);
+  });
+
+  it('should let workspace overrides win over server rules', () => {
+    const [error] = applyWorkspaceConfig([vetError('printf', 'bad format')], { severityOverrides: { printf: 'info' } }, rules);
+
+    expect(error!.severity).toBe('info');
+  });
+
+  it('should remap before duplicates are merged and counted', () => {
+    const duplicate = { ...vetError('shadow', 'declaration of "err" shadows declaration at line 10'), source: 'staticcheck', severity: 'error' as const };
+    const records = applySeverityRules([vetError('shadow', duplicate.message), duplicate], rules).map(toDiagnosticRecord);
+
+    const merged = dedupeDiagnostics(records);
+
+    expect(merged).toHaveLength(1);
+    expect(merged[0]!.severity).toBe('hint');
+    expect(summarizeDiagnostics(merged)).toMatchObject({ errors: 0, hints: 1, hasErrors: false });
+  });
+});
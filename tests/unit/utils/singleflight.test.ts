@@ -0,0 +1,68 @@
+/**
+ * Tests for coalescing identical concurrent work
+ */
+
+import { describe, it, expect, vi } from 'vitest';
+import { SingleFlight } from '../../../src/utils/singleflight.js';
+import { AnalysisCanceledError } from '../../../src/utils/cancellation.js';
+
+function deferred<T>() {
+  let resolve!: (value: T) => void;
+  const promise = new Promise<T>(settle => { resolve = settle; });
+  return { promise, resolve };
+}
+
+describe('SingleFlight', () => {
+  it('should share one run between calls with the same key while it is in flight', async () => {
+    const flight = new SingleFlight<string>();
+    const gate = deferred<string>();
+    const work = vi.fn(() => gate.promise);
+
+    const calls = [flight.run('a', undefined, work), flight.run('a', undefined, work), flight.run('b', undefined, work)];
+    gate.resolve('done');
+
+    expect(await Promise.all(calls)).toEqual(['done', 'done', 'done']);
+    expect(work).toHaveBeenCalledTimes(2);
+    expect(flight.getStats()).toEqual({ runs: 2, coalesced: 1, inFlight: 0 });
+
+    // A finished run is not reused
+    await flight.run('a', undefined, async () => 'again');
+    expect(flight.getStats().runs).toBe(3);
+  });
+
+  it('should cancel the run only when every waiting caller has canceled', async () => {
+    const flight = new SingleFlight<string>();
+    let runSignal: AbortSignal | undefined;
+    const gate = deferred<string>();
+    const work = async (signal: AbortSignal) => { runSignal = signal; return gate.promise; };
+
+    const first = new AbortController();
+    const second = new AbortController();
+    const firstCall = flight.run('a', first.signal, work);
+    const secondCall = flight.run('a', second.signal, work);
+
+    first.abort(new AnalysisCanceledError('first gave up'));
+    await expect(firstCall).rejects.toThrow('first gave up');
+    expect(runSignal!.aborted).toBe(false);
+
+    second.abort();
+    await expect(secondCall).rejects.toBeInstanceOf(AnalysisCanceledError);
+    expect(runSignal!.aborted).toBe(true);
+
+    // The canceled run is forgotten, so the next call starts afresh
+    expect(await flight.run('a', undefined, async () => 'fresh')).toBe('fresh');
+  });
+
+  it('should space runs for the same target by the minimum interval', async () => {
+    const flight = new SingleFlight<number>(150);
+    const starts: number[] = [];
+    const work = async () => { starts.push(Date.now()); return starts.length; };
+
+    await flight.run('v1', undefined, work, 'file');
+    await flight.run('v2', undefined, work, 'file');
+    await flight.run('x', undefined, work, 'other');
+
+    expect(starts[1]! - starts[0]!).toBeGreaterThanOrEqual(140);
+    expect(starts[2]! - starts[1]!).toBeLessThan(100);
+  });
+});
@@ -0,0 +1,110 @@
+/**
+ * Tests for inline suppression directives
+ */
+
+import { describe, it, expect } from 'vitest';
+import { applySuppressions, parseSuppressions } from '../../../src/utils/suppressions.js';
+import type { LanguageError } from '../../../src/types/languages.js';
+
+function diagnostic(line: number, code?: string, severity: LanguageError['severity'] = 'warning'): LanguageError {
+  return {
+    message: `problem on line ${line}`,
+    severity,
+    location: { file: '/repo/main.go', line, column: 1 },
+    source: 'go',
+    ...(code && { code })
+  };
+}
+
+describe('suppressions', () => {
+  describe('error-debugging:ignore', () => {
+    it('should drop matching diagnostics on the same line', () => {
+      const source = [
+        'package main',
+        'x := 1 // error-debugging:ignore[unusedvar]',
+        'y := 2'
+      ].join('\n');
+
+      const remaining = applySuppressions(
+        [diagnostic(2, 'unusedvar'), diagnostic(2, 'printf'), diagnostic(3, 'unusedvar')],
+        source
+      );
+
+      expect(remaining.map(error => `${error.location.line}:${error.code}`)).toEqual(['2:printf', '3:unusedvar']);
+    });
+
+    it('should apply a directive on its own line to the next line', () => {
+      const source = [
+        'package main',
+        '// error-debugging:ignore',
+        'x := undefinedName',
+        'y := undefinedName'
+      ].join('\n');
+
+      const remaining = applySuppressions(
+        [diagnostic(3, 'undefined', 'error'), diagnostic(4, 'undefined', 'error')],
+        source
+      );
+
+      expect(remaining.map(error => error.location.line)).toEqual([4]);
+    });
+
+    it('should not carry a trailing directive over to the next line', () => {
+      const source = 'x = 1  # error-debugging:ignore[E501]\ny = 2\n';
+
+      const remaining = applySuppressions([diagnostic(1, 'E501'), diagnostic(2, 'E501')], source);
+
+      expect(remaining.map(error => error.location.line)).toEqual([2]);
+    });
+
+    it('should honor a custom prefix', () => {
+      const source = 'x := 1 // myteam:ignore\ny := 2 // error-debugging:ignore\n';
+
+      const remaining = applySuppressions(
+        [diagnostic(1), diagnostic(2)],
+        source,
+        { prefix: 'myteam' }
+      );
+
+      expect(remaining.map(error => error.location.line)).toEqual([2]);
+    });
+  });
+
+  describe('nolint', () => {
+    it('should suppress linter diagnostics but not compiler errors', () => {
+      const source = 'result, _ := call() //nolint\n';
+
+      const remaining = applySuppressions(
+        [diagnostic(1, 'errcheck'), diagnostic(1, 'undefined', 'error')],
+        source
+      );
+
+      expect(remaining.map(error => error.code)).toEqual(['undefined']);
+    });
+
+    it('should respect listed linters and only cover its own line', () => {
+      const source = '//nolint:errcheck\ncall()\ncall() //nolint:errcheck\n';
+
+      const remaining = applySuppressions(
+        [diagnostic(2, 'errcheck'), diagnostic(3, 'errcheck'), diagnostic(3, 'printf')],
+        source
+      );
+
+      expect(remaining.map(error => `${error.location.line}:${error.code}`)).toEqual(['2:errcheck', '3:printf']);
+    });
+
+    it('should be disabled on request', () => {
+      expect(parseSuppressions('call() //nolint\n', { nolint: false })).toEqual([]);
+    });
+  });
+
+  it('should leave diagnostics from other files untouched', () => {
+    const other: LanguageError = { ...diagnostic(1), location: { file: '/repo/other.go', line: 1, column: 1 } };
+
+    expect(applySuppressions([other], '// error-debugging:ignore\n', {}, '/repo/main.go')).toEqual([other]);
+  });
+
+  it('should ignore every directive when disabled', () => {
+    expect(applySuppressions([diagnostic(1)], 'x // error-debugging:ignore\n', { enabled: false })).toHaveLength(1);
+  });
+});
@@ -0,0 +1,75 @@
+/**
+ * Tests for the toolchain lookup cache
+ */
+
+import { describe, it, expect, afterEach, vi } from 'vitest';
+import { ToolchainCache, toolchainCache } from '../../../src/utils/toolchain-cache.js';
+import { GoHandler } from '../../../src/languages/go-handler.js';
+
+describe('ToolchainCache', () => {
+  const originalPath = process.env['PATH'];
+
+  afterEach(() => {
+    vi.restoreAllMocks();
+    vi.useRealTimers();
+    process.env['PATH'] = originalPath;
+    toolchainCache.invalidate();
+  });
+
+  it('should probe once and share the result, including with concurrent callers', async () => {
+    const cache = new ToolchainCache();
+    const probe = vi.fn(async () => '/usr/local/go/bin/go');
+
+    const results = await Promise.all([cache.get('which go', probe), cache.get('which go', probe)]);
+    await cache.get('which go', probe);
+
+    expect(results).toEqual(['/usr/local/go/bin/go', '/usr/local/go/bin/go']);
+    expect(probe).toHaveBeenCalledTimes(1);
+    expect(cache.getStats()).toEqual({ entries: 1, hits: 2, misses: 1 });
+  });
+
+  it('should not cache failed probes', async () => {
+    const cache = new ToolchainCache();
+    const probe = vi.fn()
+      .mockRejectedValueOnce(new Error('not found'))
+      .mockResolvedValueOnce('/usr/bin/gopls');
+
+    await expect(cache.get('which gopls', probe)).rejects.toThrow('not found');
+    await expect(cache.get('which gopls', probe)).resolves.toBe('/usr/bin/gopls');
+    expect(probe).toHaveBeenCalledTimes(2);
+  });
+
+  it('should probe again after the TTL, an invalidate or a PATH change', async () => {
+    vi.useFakeTimers();
+    const cache = new ToolchainCache(1000);
+    const probe = vi.fn(async () => 'go version go1.22.3');
+
+    await cache.get('version go', probe);
+    vi.advanceTimersByTime(1001);
+    await cache.get('version go', probe);
+    expect(probe).toHaveBeenCalledTimes(2);
+
+    cache.invalidate();
+    await cache.get('version go', probe);
+    expect(probe).toHaveBeenCalledTimes(3);
+
+    process.env['PATH'] = `/opt/go1.23/bin:${originalPath}`;
+    await cache.get('version go', probe);
+    expect(probe).toHaveBeenCalledTimes(4);
+  });
+
+  it('should probe the Go version once across handler instances', async () => {
+    const runCommand = vi.spyOn(GoHandler.prototype as any, 'runCommand').mockResolvedValue({
+      stdout: 'go version go1.22.3 linux/amd64\n',
+      stderr: '',
+      exitCode: 0
+    });
+
+    const first = await new GoHandler().getToolchainInfo();
+    const second = await new GoHandler().getToolchainInfo();
+
+    expect(first).toEqual(second);
+    expect(second.version).toBe('go version go1.22.3 linux/amd64');
+    expect(runCommand).toHaveBeenCalledTimes(1);
+  });
+});
@@ -0,0 +1,113 @@
+/**
+ * Tests for the bounded worker pool
+ */
+
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { EventEmitter } from 'events';
+import { promises as fs } from 'fs';
+import { tmpdir } from 'os';
+import { basename, join } from 'path';
+import { defaultConcurrency, mapWithConcurrency } from '../../../src/utils/worker-pool.js';
+import { LanguageHandlerManager } from '../../../src/languages/language-handler-manager.js';
+import type { DetectionOptions, LanguageError, LanguageHandler } from '../../../src/types/languages.js';
+
+const sleep = (ms: number) => new Promise(resolve => setTimeout(resolve, ms));
+
+describe('worker pool', () => {
+  it('should keep input order whatever order calls finish in', async () => {
+    const results = await mapWithConcurrency([30, 5, 20, 1], 4, async (ms, index) => {
+      await sleep(ms);
+      return index;
+    });
+
+    expect(results).toEqual([0, 1, 2, 3]);
+  });
+
+  it('should never run more than the pool size at once', async () => {
+    let running = 0;
+    let peak = 0;
+
+    await mapWithConcurrency(Array.from({ length: 12 }, (_, i) => i), 3, async () => {
+      running++;
+      peak = Math.max(peak, running);
+      await sleep(2);
+      running--;
+    });
+
+    expect(peak).toBe(3);
+  });
+
+  it('should stop starting items after a failure', async () => {
+    const started: number[] = [];
+
+    await expect(mapWithConcurrency([0, 1, 2, 3, 4], 1, async item => {
+      started.push(item);
+      if (item === 1) {
+        throw new Error('boom');
+      }
+    })).rejects.toThrow('boom');
+    expect(started).toEqual([0, 1]);
+  });
+
+  it('should default to at least one worker', () => {
+    expect(defaultConcurrency()).toBeGreaterThanOrEqual(1);
+  });
+
+  describe('LanguageHandlerManager.analyzePath', () => {
+    let directory: string;
+
+    beforeEach(async () => {
+      directory = await fs.mkdtemp(join(tmpdir(), 'worker-pool-'));
+      for (const name of ['a.c', 'b.c', 'c.c', 'd.c']) {
+        await fs.writeFile(join(directory, name), 'int main(void) { return 0; }\n');
+      }
+    });
+
+    afterEach(async () => {
+      await fs.rm(directory, { recursive: true, force: true });
+    });
+
+    it('should analyze files in parallel and turn a crash into a diagnostic for that file', async () => {
+      const manager = new LanguageHandlerManager({ enabledLanguages: [], concurrency: 4 });
+      let running = 0;
+      let peak = 0;
+      const handler = Object.assign(new EventEmitter() as unknown as LanguageHandler, {
+        language: 'c',
+        initialize: vi.fn(async () => {}),
+        dispose: vi.fn(async () => {}),
+        isAvailable: vi.fn(async () => true),
+        isFileSupported: (filePath: string) => filePath.endsWith('.c'),
+        getFileExtensions: () => ['.c'],
+        getConfigFiles: () => [],
+        detectErrors: vi.fn(async (_source: string, options?: DetectionOptions): Promise<LanguageError[]> => {
+          const name = basename(options!.filePath!);
+          running++;
+          peak = Math.max(peak, running);
+          // Later files finish first
+          await sleep(name === 'a.c' ? 40 : 5);
+          running--;
+          if (name === 'b.c') {
+            throw new TypeError("Cannot read properties of undefined (reading 'line')");
+          }
+          return [{ message: `finding in ${name}`, severity: 'warning', location: { file: options!.filePath!, line: 1, column: 1 }, source: 'c' }];
+        })
+      });
+
+      try {
+        await manager.registerHandler(handler);
+        const errors = await manager.analyzePath(directory);
+
+        expect(peak).toBeGreaterThan(1);
+        expect(errors.map(error => [basename(error.location.file), error.source])).toEqual([
+          ['a.c', 'c'],
+          ['b.c', 'toolchain'],
+          ['c.c', 'c'],
+          ['d.c', 'c']
+        ]);
+        expect(errors[1]!.message).toBe("c detector failed: Cannot read properties of undefined (reading 'line')");
+      } finally {
+        await manager.dispose();
+      }
+    });
+  });
+});
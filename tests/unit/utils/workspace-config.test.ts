@@ -0,0 +1,244 @@
+/**
+ * Tests for per-workspace config files
+ */
+
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { EventEmitter } from 'events';
+import { promises as fs } from 'fs';
+import { tmpdir } from 'os';
+import { join } from 'path';
+import {
+  WorkspaceConfigLoader,
+  applyWorkspaceConfig,
+  currentDetectorOptions,
+  mergeDetectorOptions,
+  parseWorkspaceConfig,
+  runWithDetectorOptions,
+  withoutServerOnlyOptions
+} from '../../../src/utils/workspace-config.js';
+import { LanguageHandlerManager } from '../../../src/languages/language-handler-manager.js';
+import { GoHandler } from '../../../src/languages/go-handler.js';
+import type { LanguageError, LanguageHandler } from '../../../src/types/languages.js';
+
+function fakeHandler(language: string, errors: LanguageError[]): LanguageHandler {
+  return Object.assign(new EventEmitter() as unknown as LanguageHandler, {
+    language,
+    initialize: vi.fn(async () => {}),
+    dispose: vi.fn(async () => {}),
+    isAvailable: vi.fn(async () => true),
+    isFileSupported: (filePath: string) => filePath.endsWith('.go'),
+    getFileExtensions: () => ['.go'],
+    getConfigFiles: () => [],
+    detectErrors: vi.fn(async () => errors)
+  });
+}
+
+describe('workspace config', () => {
+  let directory: string;
+
+  beforeEach(async () => {
+    directory = await fs.realpath(await fs.mkdtemp(join(tmpdir(), 'workspace-config-test-')));
+    await fs.mkdir(join(directory, 'service', 'pkg'), { recursive: true });
+    await fs.writeFile(join(directory, 'service', 'pkg', 'main.go'), 'package main\n');
+  });
+
+  afterEach(async () => {
+    vi.restoreAllMocks();
+    await fs.rm(directory, { recursive: true, force: true });
+  });
+
+  describe('parseWorkspaceConfig', () => {
+    it('should read block YAML', () => {
+      const config = parseWorkspaceConfig([
+        '# shared settings',
+        'enabledLanguages: [go, typescript]',
+        'severity: warning',
+        'severityOverrides:',
+        '  SA1019: info',
+        'suppressCodes:',
+        '  - ST1000',
+        '  - 6133',
+        'detectors:',
+        '  go:',
+        '    vet:',
+        '      enabled: false',
+        ''
+      ].join('\n'), 'yaml');
+
+      expect(config).toEqual({
+        enabledLanguages: ['go', 'typescript'],
+        severity: 'warning',
+        severityOverrides: { SA1019: 'info' },
+        suppressCodes: ['ST1000', '6133'],
+        detectors: { go: { vet: { enabled: false } } }
+      });
+    });
+
+    it('should reject unknown keys and bad values', () => {
+      expect(() => parseWorkspaceConfig('{"severity": "fatal"}', 'json')).toThrow(/severity/);
+      expect(() => parseWorkspaceConfig('{"languages": ["go"]}', 'json')).toThrow(/languages/);
+    });
+  });
+
+  describe('WorkspaceConfigLoader', () => {
+    it('should use the closest file below the stop directory', async () => {
+      await fs.writeFile(join(directory, '.errordebug.json'), '{"maxResults": 5}');
+      await fs.writeFile(join(directory, 'service', '.errordebug.yml'), 'maxResults: 10\n');
+      const loader = new WorkspaceConfigLoader();
+
+      const nearest = await loader.load(join(directory, 'service', 'pkg', 'main.go'), directory);
+      expect(nearest).toEqual({ file: join(directory, 'service', '.errordebug.yml'), config: { maxResults: 10 } });
+
+      await fs.rm(join(directory, 'service', '.errordebug.yml'));
+      const root = await loader.load(join(directory, 'service', 'pkg'), directory);
+      expect(root.config).toEqual({ maxResults: 5 });
+
+      const stopped = await loader.load(join(directory, 'service', 'pkg'), join(directory, 'service'));
+      expect(stopped).toEqual({ config: {} });
+    });
+
+    it('should report the line of a syntax error instead of throwing', async () => {
+      await fs.writeFile(join(directory, '.errordebug.yaml'), 'severity: error\nseverity: warning\n');
+
+      const loaded = await new WorkspaceConfigLoader().load(directory, directory);
+
+      expect(loaded.config).toEqual({});
+      expect(loaded.error).toEqual({ message: 'duplicate key "severity"', line: 2 });
+    });
+  });
+
+  it('should drop suppressed codes and override severities', () => {
+    const errors: LanguageError[] = [
+      { message: 'deprecated', severity: 'warning', location: { file: 'a.go', line: 1, column: 1 }, source: 'go', code: 'SA1019' },
+      { message: 'package comment', severity: 'info', location: { file: 'a.go', line: 1, column: 1 }, source: 'go', analyzer: 'ST1000' },
+      { message: 'undefined: x', severity: 'error', location: { file: 'a.go', line: 2, column: 1 }, source: 'go' }
+    ];
+
+    const result = applyWorkspaceConfig(errors, { suppressCodes: ['st1000'], severityOverrides: { sa1019: 'hint' } });
+
+    expect(result.map(error => [error.message, error.severity])).toEqual([
+      ['deprecated', 'hint'],
+      ['undefined: x', 'error']
+    ]);
+  });
+
+  it('should layer detector options over the handler options', async () => {
+    const handler = new GoHandler({ vet: { enabled: true, analyzers: ['shadow'] }, retry: { retries: 0 } });
+
+    const options = await runWithDetectorOptions({ vet: { enabled: false } }, async () => (handler as any).options);
+
+    expect(options).toEqual({ vet: { enabled: false, analyzers: ['shadow'] }, retry: { retries: 0 } });
+    expect((handler as any).options).toEqual({ vet: { enabled: true, analyzers: ['shadow'] }, retry: { retries: 0 } });
+  });
+
+  it('should not let a config file set options only the server may set', async () => {
+    const file = join(directory, 'service', 'pkg', 'main.go');
+    const manager = new LanguageHandlerManager({ enabledLanguages: [], workspaceRoots: [directory] });
+    const seen: unknown[] = [];
+    const go = Object.assign(fakeHandler('go', []), {
+      detectErrors: vi.fn(async () => {
+        seen.push(currentDetectorOptions());
+        return [];
+      })
+    });
+
+    try {
+      await manager.registerHandler(go);
+      await fs.writeFile(join(directory, '.errordebug.json'), JSON.stringify({
+        detectors: { go: { generate: { enabled: true, allow: ['sh'] }, vet: { enabled: false } } }
+      }));
+      await manager.analyzeFile(file);
+
+      expect(seen).toEqual([{ vet: { enabled: false } }]);
+      expect(mergeDetectorOptions({ generate: { enabled: false } }, { generate: { enabled: true, allow: ['sh'] } }))
+        .toEqual({ generate: { enabled: false } });
+    } finally {
+      await manager.dispose();
+    }
+  });
+
+  it('should not let a config file set env that makes the tools run a program', async () => {
+    const env = { GOFLAGS: '-toolexec=./x -mod=mod', PATH: '.', LD_PRELOAD: './evil.so', GOPRIVATE: 'example.com/*' };
+    const handler = new GoHandler({ env: { GONOSUMDB: 'example.com/*' } });
+
+    expect(mergeDetectorOptions({}, { env })).toEqual({ env: { GOFLAGS: '-mod=mod', GOPRIVATE: 'example.com/*' } });
+
+    const result = await runWithDetectorOptions({ env }, () =>
+      (handler as any).runCommand('/bin/sh', ['-c', 'echo "$GOFLAGS|$LD_PRELOAD|$GOPRIVATE|$GONOSUMDB"; echo "$PATH"'])
+    );
+    expect(result.stdout).toBe(`-mod=mod||example.com/*|example.com/*\n${process.env['PATH']}\n`);
+  });
+
+  it('should strip the programs a config file names for vet and gopls', () => {
+    const server = { vet: { vettool: '/usr/local/bin/myvet' }, gopls: { enabled: true } };
+
+    expect(mergeDetectorOptions(server, {
+      vet: { vettool: './tools/vet.sh', analyzers: ['printf'] },
+      gopls: { enabled: false, path: './bin/gopls', args: ['-rpc.trace'], settleMs: 100 }
+    })).toEqual({
+      vet: { vettool: '/usr/local/bin/myvet', analyzers: ['printf'] },
+      gopls: { enabled: true, settleMs: 100 }
+    });
+    expect(withoutServerOnlyOptions({ vet: { vettool: './x' }, gopls: { path: './x', args: ['serve'] } }))
+      .toEqual({ vet: {}, gopls: {} });
+  });
+
+  it('should apply the file in the manager and report broken files as diagnostics', async () => {
+    const file = join(directory, 'service', 'pkg', 'main.go');
+    const manager = new LanguageHandlerManager({ enabledLanguages: [], workspaceRoots: [directory] });
+    const go = fakeHandler('go', [
+      { message: 'should have comment', severity: 'warning', location: { file, line: 1, column: 1 }, source: 'go', code: 'ST1000' },
+      { message: 'undefined: x', severity: 'error', location: { file, line: 1, column: 1 }, source: 'go' }
+    ]);
+    const other = fakeHandler('golangci', []);
+
+    try {
+      await manager.registerHandler(go);
+      await manager.registerHandler(other);
+      await fs.writeFile(join(directory, '.errordebug.json'), '{"enabledLanguages": ["go"], "suppressCodes": ["ST1000"]}');
+
+      expect((await manager.analyzeFile(file)).map(error => error.message)).toEqual(['undefined: x']);
+      expect(other.detectErrors).not.toHaveBeenCalled();
+
+      await fs.writeFile(join(directory, '.errordebug.json'), '{"enabledLanguages": ["go"],\n  "suppressCodes": ST1000}');
+      manager.clearCache();
+      const errors = await manager.analyzeFile(file);
+
+      expect(errors).toHaveLength(3);
+      expect(errors[2]).toMatchObject({
+        severity: 'error',
+        source: 'config',
+        code: 'invalid-config',
+        location: { file: join(directory, '.errordebug.json'), line: 2 }
+      });
+      expect(other.detectErrors).toHaveBeenCalled();
+    } finally {
+      await manager.dispose();
+    }
+  });
+
+  it('should resolve offline mode from the call, then the file, then the server', async () => {
+    const file = join(directory, 'service', 'pkg', 'main.go');
+    const manager = new LanguageHandlerManager({ enabledLanguages: [], workspaceRoots: [directory], offline: true });
+    const seen: unknown[] = [];
+    const go = Object.assign(fakeHandler('go', []), {
+      detectErrors: vi.fn(async () => {
+        seen.push(currentDetectorOptions()?.['offline']);
+        return [];
+      })
+    });
+
+    try {
+      await manager.registerHandler(go);
+      await manager.analyzeFile(file);
+      await fs.writeFile(join(directory, '.errordebug.json'), '{"offline": false, "detectors": {"go": {"vet": {"enabled": false}}}}');
+      manager.clearCache();
+      await manager.analyzeFile(file);
+      await manager.analyzeFile(file, undefined, { offline: true });
+
+      expect(seen).toEqual([true, false, true]);
+    } finally {
+      await manager.dispose();
+    }
+  });
+});
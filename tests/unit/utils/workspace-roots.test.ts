@@ -0,0 +1,107 @@
+/**
+ * Tests for workspace root resolution and scoped manifest lookup
+ */
+
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { EventEmitter } from 'events';
+import { promises as fs } from 'fs';
+import { tmpdir } from 'os';
+import { join } from 'path';
+import { WorkspaceRoots, findUpwards } from '../../../src/utils/workspace-roots.js';
+import { OutsideWorkspaceError } from '../../../src/utils/errors.js';
+import { LanguageHandlerManager } from '../../../src/languages/language-handler-manager.js';
+import type { LanguageError, LanguageHandler } from '../../../src/types/languages.js';
+
+describe('workspace roots', () => {
+  describe('WorkspaceRoots', () => {
+    const roots = new WorkspaceRoots(['/work/api', '/work/web', '/work/api/tools']);
+
+    it('should pick the deepest root containing a path', () => {
+      expect(roots.findRoot('/work/api/cmd/main.go')).toBe('/work/api');
+      expect(roots.findRoot('/work/api/tools/gen.go')).toBe('/work/api/tools');
+      expect(roots.findRoot('/work/webapp/index.ts')).toBeUndefined();
+    });
+
+    it('should describe paths relative to their root', () => {
+      expect(roots.locate('/work/web/src/app.ts')).toEqual({ root: '/work/web', relativePath: 'src/app.ts' });
+      expect(roots.locate('/work/web')).toEqual({ root: '/work/web', relativePath: '.' });
+    });
+
+    it('should reject paths outside every root and name the roots', () => {
+      expect(() => roots.requireRoot('/tmp/other.go')).toThrow(OutsideWorkspaceError);
+      expect(() => roots.requireRoot('/tmp/other.go'))
+        .toThrow('/tmp/other.go is outside the configured workspace roots: /work/api, /work/web, /work/api/tools');
+    });
+
+    it('should allow any path when no roots are configured', () => {
+      const unrestricted = new WorkspaceRoots();
+
+      expect(unrestricted.isConfigured).toBe(false);
+      expect(unrestricted.requireRoot('/anywhere/file.go')).toBeUndefined();
+      expect(unrestricted.locate('/anywhere/file.go')).toBeUndefined();
+    });
+  });
+
+  describe('findUpwards', () => {
+    let directory: string;
+
+    beforeEach(async () => {
+      directory = await fs.mkdtemp(join(tmpdir(), 'workspace-roots-'));
+      await fs.mkdir(join(directory, 'repo', 'svc', 'pkg'), { recursive: true });
+      await fs.writeFile(join(directory, 'go.mod'), 'module outer\n');
+      await fs.writeFile(join(directory, 'repo', 'svc', 'go.mod'), 'module svc\n');
+    });
+
+    afterEach(async () => {
+      await fs.rm(directory, { recursive: true, force: true });
+    });
+
+    it('should find the nearest module file', async () => {
+      const file = join(directory, 'repo', 'svc', 'pkg', 'x.go');
+
+      expect(await findUpwards(file, ['go.mod'])).toBe(join(directory, 'repo', 'svc', 'go.mod'));
+    });
+
+    it('should not search above the workspace root', async () => {
+      const file = join(directory, 'repo', 'main.go');
+
+      expect(await findUpwards(file, ['go.mod'])).toBe(join(directory, 'go.mod'));
+      expect(await findUpwards(file, ['go.mod'], join(directory, 'repo'))).toBeUndefined();
+    });
+  });
+
+  describe('LanguageHandlerManager', () => {
+    it('should pass the owning root to handlers and reject files outside the roots', async () => {
+      const directory = await fs.mkdtemp(join(tmpdir(), 'workspace-manager-'));
+      const manager = new LanguageHandlerManager({ enabledLanguages: [], workspaceRoots: [join(directory, 'api')] });
+      const detectErrors = vi.fn(async (): Promise<LanguageError[]> => []);
+      const handler = Object.assign(new EventEmitter() as unknown as LanguageHandler, {
+        language: 'go',
+        initialize: vi.fn(async () => {}),
+        dispose: vi.fn(async () => {}),
+        isAvailable: vi.fn(async () => true),
+        isFileSupported: (filePath: string) => filePath.endsWith('.go'),
+        getFileExtensions: () => ['.go'],
+        getConfigFiles: () => [],
+        detectErrors
+      });
+
+      try {
+        await fs.mkdir(join(directory, 'api'));
+        await fs.writeFile(join(directory, 'api', 'main.go'), 'package main\n');
+        await fs.writeFile(join(directory, 'other.go'), 'package main\n');
+        await manager.registerHandler(handler);
+
+        await manager.analyzeFile(join(directory, 'api', 'main.go'));
+        expect(detectErrors).toHaveBeenCalledWith('package main\n', expect.objectContaining({
+          workspaceRoot: join(directory, 'api')
+        }));
+
+        await expect(manager.analyzeFile(join(directory, 'other.go'))).rejects.toBeInstanceOf(OutsideWorkspaceError);
+      } finally {
+        await manager.dispose();
+        await fs.rm(directory, { recursive: true, force: true });
+      }
+    });
+  });
+});